@@ -225,6 +225,7 @@ STANDARD_BINARIES:=./istioctl/cmd/istioctl \
 # These are binaries that require Linux to build, and should
 # be skipped on other platforms. Notably this includes the current Linux-only Istio CNI plugin
 LINUX_AGENT_BINARIES:=./cni/cmd/istio-cni \
+  ./cni/cmd/istio-iptables-helper \
   ./cni/cmd/install-cni \
   $(AGENT_BINARIES)
 
@@ -0,0 +1,43 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// istio-iptables-helper programs the traffic capture of a pod for the istio-cni plugin, reading the request on
+// its stdin and writing the response on its stdout. It is the only binary of the plugin which needs CAP_NET_ADMIN.
+package main
+
+import (
+	"context"
+	"os"
+
+	"istio.io/istio/cni/pkg/iptableshelper"
+	"istio.io/istio/pkg/log"
+)
+
+func main() {
+	// The stdout of the helper is its response, so it logs on its stderr, which the plugin reports on failure.
+	options := log.DefaultOptions()
+	options.OutputPaths = []string{"stderr"}
+	if err := log.Configure(options); err != nil {
+		os.Exit(1)
+	}
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	if err := iptableshelper.Serve(context.Background(), os.Stdin, os.Stdout, iptableshelper.Program); err != nil {
+		log.Error(err)
+		_ = log.Sync()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ambient
+
+import (
+	"os"
+	"sync"
+
+	"istio.io/istio/cni/pkg/ambient/constants"
+	"istio.io/istio/tools/istio-iptables/pkg/hostrules"
+)
+
+// hostJumps create the ztunnel chains of the host and hook them into the built-in chains, in order.
+var hostJumps = []hostrules.Jump{
+	{Table: constants.TableNat, From: constants.ChainPrerouting, To: constants.ChainZTunnelPrerouting},
+	{Table: constants.TableNat, From: constants.ChainPostrouting, To: constants.ChainZTunnelPostrouting},
+	{Table: constants.TableMangle, From: constants.ChainPrerouting, To: constants.ChainZTunnelPrerouting},
+	{Table: constants.TableMangle, From: constants.ChainPostrouting, To: constants.ChainZTunnelPostrouting},
+	{Table: constants.TableMangle, From: constants.ChainOutput, To: constants.ChainZTunnelOutput},
+	{Table: constants.TableMangle, From: constants.ChainInput, To: constants.ChainZTunnelInput},
+	{Table: constants.TableMangle, From: constants.ChainForward, To: constants.ChainZTunnelForward},
+	{Table: constants.TableFilter, From: constants.ChainForward, To: constants.ChainZTunnelForward},
+}
+
+// hostState is the desired state of the host-level rules, written for 'istio-iptables ambient verify'.
+var hostState struct {
+	sync.Mutex
+	state *hostrules.State
+}
+
+// recordHostRules records the rules programmed on the host as desired. The enrolled pods are those of the
+// ipset, which the agent reconciles with the pods of the node.
+func recordHostRules(iptablesCmd string, rules []*iptablesRule) {
+	hostState.Lock()
+	defer hostState.Unlock()
+	state := &hostrules.State{
+		IptablesCommand: iptablesCmd,
+		Jumps:           hostJumps,
+		IPSet:           Ipset.Name,
+		Members:         map[string]string{},
+	}
+	for _, r := range rules {
+		state.Rules = append(state.Rules, hostrules.Rule{Table: r.Table, Chain: r.Chain, Spec: r.RuleSpec})
+	}
+	entries, err := Ipset.List()
+	if err != nil {
+		log.Warnf("unable to list IPSet: %v", err)
+	}
+	for _, e := range entries {
+		state.Members[e.IP.String()] = e.Comment
+	}
+	hostState.state = state
+	writeHostState()
+}
+
+// recordEnrolledPod records the pod IP as desired in the ipset.
+func recordEnrolledPod(ip, uid string) {
+	hostState.Lock()
+	defer hostState.Unlock()
+	if hostState.state == nil {
+		return
+	}
+	hostState.state.Members[ip] = uid
+	writeHostState()
+}
+
+// forgetEnrolledPod records the pod IP as no longer desired in the ipset.
+func forgetEnrolledPod(ip string) {
+	hostState.Lock()
+	defer hostState.Unlock()
+	if hostState.state == nil {
+		return
+	}
+	delete(hostState.state.Members, ip)
+	writeHostState()
+}
+
+// removeHostState removes the desired state once the rules of the host are cleaned up.
+func removeHostState() {
+	hostState.Lock()
+	defer hostState.Unlock()
+	hostState.state = nil
+	if err := os.Remove(hostrules.DefaultStatePath); err != nil && !os.IsNotExist(err) {
+		log.Warnf("unable to remove the desired state of the host rules: %v", err)
+	}
+}
+
+func writeHostState() {
+	if err := hostrules.WriteState(hostrules.DefaultStatePath, hostState.state); err != nil {
+		log.Warnf("unable to write the desired state of the host rules: %v", err)
+	}
+}
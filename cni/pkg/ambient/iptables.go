@@ -85,39 +85,14 @@ func (s *Server) IptablesCmd() string {
 func (s *Server) initializeLists() error {
 	var err error
 
-	list := []*ExecList{
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableNat, "-N", constants.ChainZTunnelPrerouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableNat, "-I", "PREROUTING", "-j", constants.ChainZTunnelPrerouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableNat, "-N", constants.ChainZTunnelPostrouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableNat, "-I", "POSTROUTING", "-j", constants.ChainZTunnelPostrouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-N", constants.ChainZTunnelPrerouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-I", "PREROUTING", "-j", constants.ChainZTunnelPrerouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-N", constants.ChainZTunnelPostrouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-I", "POSTROUTING", "-j", constants.ChainZTunnelPostrouting}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-N", constants.ChainZTunnelOutput}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-I", "OUTPUT", "-j", constants.ChainZTunnelOutput}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-N", constants.ChainZTunnelInput}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-I", "INPUT", "-j", constants.ChainZTunnelInput}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-N", constants.ChainZTunnelForward}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableMangle, "-I", "FORWARD", "-j", constants.ChainZTunnelForward}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableFilter, "-N", constants.ChainZTunnelForward}),
-		newExec(s.IptablesCmd(),
-			[]string{"-t", constants.TableFilter, "-I", "FORWARD", "-j", constants.ChainZTunnelForward}),
+	list := make([]*ExecList, 0, 2*len(hostJumps))
+	for _, j := range hostJumps {
+		list = append(list,
+			newExec(s.IptablesCmd(),
+				[]string{"-t", j.Table, "-N", j.To}),
+			newExec(s.IptablesCmd(),
+				[]string{"-t", j.Table, "-I", j.From, "-j", j.To}),
+		)
 	}
 
 	for _, l := range list {
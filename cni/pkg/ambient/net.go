@@ -85,6 +85,7 @@ func addPodToMeshWithIptables(pod *corev1.Pod, ip string) error {
 	} else {
 		log.Infof("Pod '%s/%s' (%s) is in ipset", pod.Name, pod.Namespace, string(pod.UID))
 	}
+	recordEnrolledPod(net.ParseIP(ip).To4().String(), string(pod.UID))
 
 	rte, err := buildRouteForPod(ip)
 	if err != nil {
@@ -137,6 +138,7 @@ func delIPsetAndRoute(ip string) {
 	if err := Ipset.DeleteIP(net.ParseIP(ip).To4()); err != nil {
 		log.Errorf("Failed to delete %s from ipset list: %v", ip, err)
 	}
+	forgetEnrolledPod(net.ParseIP(ip).To4().String())
 	rte, err := buildRouteForPod(ip)
 	if err != nil {
 		log.Errorf("Failed to build route for %s: %v", ip, err)
@@ -605,6 +605,7 @@ func (s *Server) CreateRulesOnNode(ztunnelVeth, ztunnelIP string, captureDNS boo
 	if err != nil {
 		log.Errorf("failed to append iptables rule: %v", err)
 	}
+	recordHostRules(s.IptablesCmd(), append(appendRules, appendRules2...))
 
 	// Need to do some work in procfs
 	// @TODO: This likely needs to be cleaned up, there are a lot of martians in AWS
@@ -1292,6 +1293,7 @@ func (s *Server) cleanupNode() {
 		if err != nil {
 			log.Warnf("unable to delete IPSet: %v", err)
 		}
+		removeHostState()
 	}
 }
 
@@ -19,7 +19,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
@@ -28,17 +30,21 @@ import (
 	"istio.io/istio/cni/pkg/ambient"
 	"istio.io/istio/cni/pkg/config"
 	"istio.io/istio/cni/pkg/constants"
+	"istio.io/istio/cni/pkg/egress"
 	"istio.io/istio/cni/pkg/install"
 	udsLog "istio.io/istio/cni/pkg/log"
 	"istio.io/istio/cni/pkg/monitoring"
+	"istio.io/istio/cni/pkg/probes"
 	"istio.io/istio/cni/pkg/repair"
 	"istio.io/istio/pkg/cmd"
 	"istio.io/istio/pkg/collateral"
 	"istio.io/istio/pkg/ctrlz"
 	"istio.io/istio/pkg/env"
+	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/version"
 	iptables "istio.io/istio/tools/istio-iptables/pkg/constants"
+	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
 )
 
 var (
@@ -99,9 +105,47 @@ var rootCmd = &cobra.Command{
 			defer server.Stop()
 		}
 
+		if cfg.InstallConfig.EgressExclusionsEnabled {
+			client, err := kube.NewDefaultClient()
+			if err != nil {
+				return fmt.Errorf("failed to create kube client for egress exclusions: %v", err)
+			}
+			controller := egress.NewController(client, filepath.Join(cfg.InstallConfig.MountedCNINetDir, egress.ExclusionsFilename))
+			go controller.Run(ctx.Done())
+		}
+
+		if cfg.InstallConfig.KubeletProbeExemptionEnabled {
+			client, err := kube.NewDefaultClient()
+			if err != nil {
+				return fmt.Errorf("failed to create kube client for the kubelet probe exemption: %v", err)
+			}
+			// The host rules are shared with kube-proxy and the network plugin, so take the lock of the host iptables.
+			ipv, err := dependencies.DetectIptablesVersion("")
+			if err != nil {
+				return fmt.Errorf("failed to detect the iptables version of the host: %v", err)
+			}
+			exemption, err := probes.NewExemption(ctx, client.Kube(), cfg.InstallConfig.K8sNodeName,
+				&dependencies.RealDependencies{IptablesVersion: ipv})
+			if err != nil {
+				return err
+			}
+			if err := exemption.Program(); err != nil {
+				return err
+			}
+			defer exemption.Cleanup()
+		}
+
 		isReady := install.StartServer()
 
 		installer := install.NewInstaller(&cfg.InstallConfig, isReady)
+		if cfg.InstallConfig.CNIBinVerifyInterval > 0 && ambient.PodName != "" {
+			// Binaries found corrupted are reported on the pod of the installer, on top of the logs and metrics.
+			if client, err := kube.NewDefaultClient(); err != nil {
+				log.Warnf("Failed to create kube client to record the events of the CNI binaries: %v", err)
+			} else {
+				installer.RecordEvents(client.Kube(), ambient.PodName, ambient.PodNamespace)
+			}
+		}
 
 		repair.StartRepair(ctx, cfg.RepairConfig)
 
@@ -149,6 +193,12 @@ func init() {
 	registerStringParameter(constants.CNINetDir, "/etc/cni/net.d", "Directory on the host where CNI network plugins are installed")
 	registerStringParameter(constants.CNIConfName, "", "Name of the CNI configuration file")
 	registerBooleanParameter(constants.ChainedCNIPlugin, true, "Whether to install CNI plugin as a chained or standalone")
+	registerIntegerParameter(constants.CNIConfBackups, 3,
+		"Number of versions of the primary CNI config file to keep as backups when installed as a chained plugin. "+
+			"The latest backup is restored on uninstall. Set to 0 to disable backups")
+	registerDurationParameter(constants.CNIBinVerifyInterval, time.Minute,
+		"Interval of the verification of the checksums of the installed CNI binaries, which are repaired if another process "+
+			"truncated or replaced them. Set to 0 to disable the periodic verification")
 	registerStringParameter(constants.CNINetworkConfig, "", "CNI configuration template as a string")
 	registerStringParameter(constants.LogLevel, "warn", "Fallback value for log level in CNI config file, if not specified in helm template")
 
@@ -164,6 +214,10 @@ func init() {
 	registerStringParameter(constants.LogUDSAddress, "/var/run/istio-cni/log.sock", "The UDS server address which CNI plugin will copy log ouptut to")
 	registerBooleanParameter(constants.AmbientEnabled, false, "Whether ambient controller is enabled")
 	registerBooleanParameter(constants.EbpfEnabled, false, "Whether ebpf redirection is enabled")
+	registerBooleanParameter(constants.EgressExclusionsEnabled, false,
+		"Whether to exclude the egress hosts of Sidecar resources annotated with "+egress.BypassEgressHostsAnnotation+" from traffic capture")
+	registerBooleanParameter(constants.KubeletProbeExemptionEnabled, false,
+		"Whether to exempt the connections of the host to the pods of the node, such as kubelet probes, from traffic capture")
 	// Repair
 	registerBooleanParameter(constants.RepairEnabled, true, "Whether to enable race condition repair or not")
 	registerBooleanParameter(constants.RepairDeletePods, false, "Controller will delete pods when detecting pod broken by race condition")
@@ -203,6 +257,11 @@ func registerBooleanParameter(name string, value bool, usage string) {
 	registerEnvironment(name, value, usage)
 }
 
+func registerDurationParameter(name string, value time.Duration, usage string) {
+	rootCmd.Flags().Duration(name, value, usage)
+	registerEnvironment(name, value, usage)
+}
+
 func registerEnvironment[T env.Parseable](name string, defaultValue T, usage string) {
 	envName := strings.Replace(strings.ToUpper(name), "-", "_", -1)
 	// Note: we do not rely on istio env package to retrieve configuration. We relies on viper.
@@ -224,6 +283,7 @@ func constructConfig() (*config.Config, error) {
 		MountedCNINetDir: viper.GetString(constants.MountedCNINetDir),
 		CNIConfName:      viper.GetString(constants.CNIConfName),
 		ChainedCNIPlugin: viper.GetBool(constants.ChainedCNIPlugin),
+		CNIConfBackups:   viper.GetInt(constants.CNIConfBackups),
 
 		CNINetworkConfigFile: viper.GetString(constants.CNINetworkConfigFile),
 		CNINetworkConfig:     viper.GetString(constants.CNINetworkConfig),
@@ -243,8 +303,13 @@ func constructConfig() (*config.Config, error) {
 		MonitoringPort:   viper.GetInt(constants.MonitoringPort),
 		LogUDSAddress:    viper.GetString(constants.LogUDSAddress),
 
+		CNIBinVerifyInterval: viper.GetDuration(constants.CNIBinVerifyInterval),
+
 		AmbientEnabled: viper.GetBool(constants.AmbientEnabled),
 		EbpfEnabled:    viper.GetBool(constants.EbpfEnabled),
+
+		EgressExclusionsEnabled:      viper.GetBool(constants.EgressExclusionsEnabled),
+		KubeletProbeExemptionEnabled: viper.GetBool(constants.KubeletProbeExemptionEnabled),
 	}
 
 	if len(installCfg.K8sNodeName) == 0 {
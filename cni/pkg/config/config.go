@@ -17,6 +17,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -34,6 +35,9 @@ type InstallConfig struct {
 	CNIConfName string
 	// Whether to install CNI plugin as a chained or standalone
 	ChainedCNIPlugin bool
+	// Number of versions of the primary CNI config file to keep as backups when chained.
+	// Zero disables backups and restoring them on cleanup.
+	CNIConfBackups int
 
 	// CNI config template file
 	CNINetworkConfigFile string
@@ -64,6 +68,9 @@ type InstallConfig struct {
 	CNIBinSourceDir string
 	// Directories into which to copy the CNI binaries
 	CNIBinTargetDirs []string
+	// Interval of the verification of the checksums of the installed CNI binaries, which are repaired if they do
+	// not match. Zero disables the periodic verification.
+	CNIBinVerifyInterval time.Duration
 
 	// The HTTP port for monitoring
 	MonitoringPort int
@@ -76,6 +83,12 @@ type InstallConfig struct {
 
 	// Whether ebpf is enabled
 	EbpfEnabled bool
+
+	// Whether the egress hosts of Sidecar resources are excluded from traffic capture
+	EgressExclusionsEnabled bool
+
+	// Whether the connections of the host to the pods of the node, such as kubelet probes, are exempted from capture
+	KubeletProbeExemptionEnabled bool
 }
 
 // RepairConfig struct defines the Istio CNI race repair configuration
@@ -117,6 +130,7 @@ func (c InstallConfig) String() string {
 	b.WriteString("MountedCNINetDir: " + c.MountedCNINetDir + "\n")
 	b.WriteString("CNIConfName: " + c.CNIConfName + "\n")
 	b.WriteString("ChainedCNIPlugin: " + fmt.Sprint(c.ChainedCNIPlugin) + "\n")
+	b.WriteString("CNIConfBackups: " + fmt.Sprint(c.CNIConfBackups) + "\n")
 	b.WriteString("CNINetworkConfigFile: " + c.CNINetworkConfigFile + "\n")
 	b.WriteString("CNINetworkConfig: " + c.CNINetworkConfig + "\n")
 
@@ -130,10 +144,13 @@ func (c InstallConfig) String() string {
 	b.WriteString("K8sServiceHost: " + c.K8sServiceHost + "\n")
 	b.WriteString("K8sServicePort: " + fmt.Sprint(c.K8sServicePort) + "\n")
 	b.WriteString("K8sNodeName: " + c.K8sNodeName + "\n")
+	b.WriteString("CNIBinVerifyInterval: " + c.CNIBinVerifyInterval.String() + "\n")
 	b.WriteString("MonitoringPort: " + fmt.Sprint(c.MonitoringPort) + "\n")
 	b.WriteString("LogUDSAddress: " + fmt.Sprint(c.LogUDSAddress) + "\n")
 
 	b.WriteString("AmbientEnabled: " + fmt.Sprint(c.AmbientEnabled) + "\n")
+	b.WriteString("EgressExclusionsEnabled: " + fmt.Sprint(c.EgressExclusionsEnabled) + "\n")
+	b.WriteString("KubeletProbeExemptionEnabled: " + fmt.Sprint(c.KubeletProbeExemptionEnabled) + "\n")
 
 	return b.String()
 }
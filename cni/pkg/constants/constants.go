@@ -21,6 +21,8 @@ const (
 	CNINetDir            = "cni-net-dir"
 	CNIConfName          = "cni-conf-name"
 	ChainedCNIPlugin     = "chained-cni-plugin"
+	CNIConfBackups       = "cni-conf-backups"
+	CNIBinVerifyInterval = "cni-bin-verify-interval"
 	CNINetworkConfigFile = "cni-network-config-file"
 	CNINetworkConfig     = "cni-network-config"
 	LogLevel             = "log-level"
@@ -33,6 +35,9 @@ const (
 	AmbientEnabled       = "ambient-enabled"
 	EbpfEnabled          = "ebpf-enabled"
 
+	EgressExclusionsEnabled      = "egress-exclusions-enabled"
+	KubeletProbeExemptionEnabled = "kubelet-probe-exemption-enabled"
+
 	// Repair
 	RepairEnabled            = "repair-enabled"
 	RepairDeletePods         = "repair-delete-pods"
@@ -56,6 +61,9 @@ const (
 	UDSLogPath      = "/log"
 	SecondaryBinDir = "/host/secondary-bin-dir"
 
+	// UDSCaptureDecisionPath receives the traffic capture decisions of the CNI plugin, for metrics.
+	UDSCaptureDecisionPath = "/capture-decision"
+
 	// K8s liveness and readiness endpoints
 	LivenessEndpoint  = "/healthz"
 	ReadinessEndpoint = "/readyz"
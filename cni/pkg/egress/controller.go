@@ -0,0 +1,80 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/controllers"
+	"istio.io/istio/pkg/kube/kclient"
+	"istio.io/istio/pkg/log"
+)
+
+var egressLog = log.RegisterScope("egress", "CNI egress exclusions")
+
+// recomputeKey is the only key of the queue: any change recomputes all exclusions.
+const recomputeKey = "exclusions"
+
+// Controller runs in the node agent, and writes the exclusions to a file whenever Sidecars or ServiceEntries
+// change. Pods only read the exclusions when they start, so changes apply to new pods.
+type Controller struct {
+	client         kube.Client
+	path           string
+	queue          controllers.Queue
+	sidecars       kclient.Client[*clientnetworking.Sidecar]
+	serviceEntries kclient.Client[*clientnetworking.ServiceEntry]
+}
+
+// NewController returns a controller writing the exclusions to the file at path.
+func NewController(client kube.Client, path string) *Controller {
+	c := &Controller{client: client, path: path}
+	c.queue = controllers.NewQueue("egress exclusions",
+		controllers.WithGenericReconciler(func(any) error {
+			return c.write()
+		}),
+		controllers.WithMaxAttempts(5),
+	)
+	enqueue := controllers.ObjectHandler(func(controllers.Object) {
+		c.queue.Add(recomputeKey)
+	})
+	c.sidecars = kclient.New[*clientnetworking.Sidecar](client)
+	c.sidecars.AddEventHandler(enqueue)
+	c.serviceEntries = kclient.New[*clientnetworking.ServiceEntry](client)
+	c.serviceEntries.AddEventHandler(enqueue)
+	return c
+}
+
+// Run writes the exclusions until stop is closed, then removes the file so that pods started while the node
+// agent is not running are not excluded based on stale exclusions.
+func (c *Controller) Run(stop <-chan struct{}) {
+	c.client.RunAndWait(stop)
+	c.queue.Add(recomputeKey)
+	c.queue.Run(stop)
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		egressLog.Warnf("failed to remove egress exclusions file %s: %v", c.path, err)
+	}
+}
+
+func (c *Controller) write() error {
+	e := Compute(c.sidecars.List(metav1.NamespaceAll, klabels.Everything()),
+		c.serviceEntries.List(metav1.NamespaceAll, klabels.Everything()))
+	egressLog.Debugf("writing egress exclusions of %d namespaces to %s", len(e.Namespaces), c.path)
+	return e.Write(c.path)
+}
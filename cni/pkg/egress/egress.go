@@ -0,0 +1,191 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package egress excludes the egress hosts of Sidecar resources from the traffic capture of the pods they
+// apply to, for destinations which should bypass the mesh. The node agent resolves the hosts to the
+// addresses of the matching ServiceEntries and writes them to a file, which the CNI plugin reads when it
+// sets up the capture of a pod.
+package egress
+
+import (
+	"encoding/json"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/file"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// BypassEgressHostsAnnotation opts a Sidecar resource in: the addresses of the ServiceEntries matching
+	// its egress hosts are excluded from the capture of the pods it applies to.
+	BypassEgressHostsAnnotation = "cni.istio.io/bypass-egress-hosts"
+
+	// ExclusionsFilename is the name of the exclusions file in the CNI network config directory, next to the
+	// kubeconfig of the plugin. It has no extension so that container runtimes do not load it as a CNI config.
+	ExclusionsFilename = "ZZZ-istio-cni-egress-exclusions"
+)
+
+// Exclusion holds the addresses excluded for the pods a Sidecar resource applies to.
+type Exclusion struct {
+	Sidecar string `json:"sidecar"`
+	// Selector is the workload selector of the Sidecar. Sidecars without selector apply to the whole namespace.
+	Selector map[string]string `json:"selector,omitempty"`
+	// CIDRs are empty for Sidecars which do not bypass their egress hosts. They are kept nonetheless, as
+	// they take precedence over the Sidecars of the namespace for the pods they select.
+	CIDRs []string `json:"cidrs,omitempty"`
+}
+
+// Exclusions holds the exclusions of the Sidecars of each namespace, ordered by precedence.
+type Exclusions struct {
+	Namespaces map[string][]Exclusion `json:"namespaces"`
+}
+
+// Compute resolves the egress hosts of the Sidecars annotated with BypassEgressHostsAnnotation to the
+// addresses of the matching ServiceEntries. Only the addresses known without DNS resolution are used: the
+// addresses of the ServiceEntries and the endpoints of those with STATIC resolution.
+func Compute(sidecars []*clientnetworking.Sidecar, serviceEntries []*clientnetworking.ServiceEntry) *Exclusions {
+	// Istio applies the oldest Sidecar when several match a workload.
+	sort.SliceStable(sidecars, func(i, j int) bool {
+		if !sidecars[i].CreationTimestamp.Equal(&sidecars[j].CreationTimestamp) {
+			return sidecars[i].CreationTimestamp.Before(&sidecars[j].CreationTimestamp)
+		}
+		return sidecars[i].Name < sidecars[j].Name
+	})
+	e := &Exclusions{Namespaces: map[string][]Exclusion{}}
+	for _, sc := range sidecars {
+		ex := Exclusion{Sidecar: sc.Name, Selector: sc.Spec.GetWorkloadSelector().GetLabels()}
+		if sc.Annotations[BypassEgressHostsAnnotation] == "true" {
+			cidrs := sets.New[string]()
+			for _, listener := range sc.Spec.GetEgress() {
+				for _, h := range listener.GetHosts() {
+					cidrs.InsertAll(hostAddresses(sc.Namespace, h, serviceEntries)...)
+				}
+			}
+			ex.CIDRs = sets.SortedList(cidrs)
+		}
+		e.Namespaces[sc.Namespace] = append(e.Namespaces[sc.Namespace], ex)
+	}
+	return e
+}
+
+// hostAddresses returns the addresses of the ServiceEntries matching an egress host of a Sidecar, which has
+// the form namespace/dnsName.
+func hostAddresses(sidecarNamespace, egressHost string, serviceEntries []*clientnetworking.ServiceEntry) []string {
+	namespace, hostname, ok := strings.Cut(egressHost, "/")
+	if !ok {
+		return nil
+	}
+	switch namespace {
+	case "~":
+		return nil
+	case ".":
+		namespace = sidecarNamespace
+	}
+	var addresses []string
+	for _, se := range serviceEntries {
+		if namespace != "*" && se.Namespace != namespace {
+			continue
+		}
+		matched := false
+		for _, h := range se.Spec.GetHosts() {
+			if host.Name(hostname).Matches(host.Name(h)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, a := range se.Spec.GetAddresses() {
+			if cidr, ok := toCIDR(a); ok {
+				addresses = append(addresses, cidr)
+			}
+		}
+		if se.Spec.GetResolution() == networking.ServiceEntry_STATIC {
+			for _, we := range se.Spec.GetEndpoints() {
+				if cidr, ok := toCIDR(we.GetAddress()); ok {
+					addresses = append(addresses, cidr)
+				}
+			}
+		}
+	}
+	return addresses
+}
+
+// toCIDR converts an IP or CIDR to a CIDR. Other addresses, such as Unix domain sockets, are rejected.
+func toCIDR(address string) (string, bool) {
+	if p, err := netip.ParsePrefix(address); err == nil {
+		return p.Masked().String(), true
+	}
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return "", false
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()).String(), true
+}
+
+// For returns the CIDRs to exclude from the capture of a pod with the labels in the namespace. Sidecars
+// selecting the pod take precedence over those of the whole namespace.
+func (e *Exclusions) For(namespace string, labels map[string]string) []string {
+	var namespaceWide *Exclusion
+	for i, ex := range e.Namespaces[namespace] {
+		if len(ex.Selector) == 0 {
+			if namespaceWide == nil {
+				namespaceWide = &e.Namespaces[namespace][i]
+			}
+			continue
+		}
+		if klabels.SelectorFromSet(ex.Selector).Matches(klabels.Set(labels)) {
+			return ex.CIDRs
+		}
+	}
+	if namespaceWide != nil {
+		return namespaceWide.CIDRs
+	}
+	return nil
+}
+
+// Write writes the exclusions to the file atomically, so that the plugin never reads a partial file.
+func (e *Exclusions) Write(path string) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return file.AtomicWrite(path, data, os.FileMode(0o644))
+}
+
+// Read reads the exclusions from the file. Without file, which is the case when the node agent does not
+// compute exclusions, nothing is excluded.
+func Read(path string) (*Exclusions, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Exclusions{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e := &Exclusions{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,91 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package egress
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func sidecar(name, namespace string, created time.Time, bypass bool, selector map[string]string, hosts ...string) *clientnetworking.Sidecar {
+	sc := &clientnetworking.Sidecar{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(created)},
+		Spec: networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{{Hosts: hosts}},
+		},
+	}
+	if bypass {
+		sc.Annotations = map[string]string{BypassEgressHostsAnnotation: "true"}
+	}
+	if selector != nil {
+		sc.Spec.WorkloadSelector = &networking.WorkloadSelector{Labels: selector}
+	}
+	return sc
+}
+
+func serviceEntry(name, namespace string, host string, addresses []string, resolution networking.ServiceEntry_Resolution,
+	endpoints ...string,
+) *clientnetworking.ServiceEntry {
+	se := &clientnetworking.ServiceEntry{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: networking.ServiceEntry{
+			Hosts:      []string{host},
+			Addresses:  addresses,
+			Resolution: resolution,
+		},
+	}
+	for _, ep := range endpoints {
+		se.Spec.Endpoints = append(se.Spec.Endpoints, &networking.WorkloadEntry{Address: ep})
+	}
+	return se
+}
+
+func TestExclusions(t *testing.T) {
+	now := time.Now()
+	serviceEntries := []*clientnetworking.ServiceEntry{
+		serviceEntry("db", "default", "db.example.com", []string{"10.1.0.0/16"}, networking.ServiceEntry_STATIC,
+			"192.168.1.10", "unix:///var/run/db.sock"),
+		serviceEntry("api", "external", "api.example.com", []string{"2001:db8::1"}, networking.ServiceEntry_DNS, "192.168.2.10"),
+		serviceEntry("other", "default", "other.example.org", []string{"10.2.0.1"}, networking.ServiceEntry_NONE),
+	}
+	sidecars := []*clientnetworking.Sidecar{
+		sidecar("selected", "default", now, false, map[string]string{"app": "captured"}, "./*"),
+		sidecar("bypass", "default", now.Add(-time.Hour), true, nil, "./*.example.com", "external/api.example.com", "~/*"),
+		sidecar("newer", "default", now, true, nil, "*/*"),
+		sidecar("ignored", "other", now, false, nil, "*/*"),
+	}
+	e := Compute(sidecars, serviceEntries)
+
+	path := filepath.Join(t.TempDir(), ExclusionsFilename)
+	assert.NoError(t, e.Write(path))
+	e, err := Read(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, e.For("default", map[string]string{"app": "reviews"}), []string{"10.1.0.0/16", "192.168.1.10/32", "2001:db8::1/128"})
+	assert.Equal(t, e.For("default", map[string]string{"app": "captured"}), nil)
+	assert.Equal(t, e.For("other", nil), nil)
+	assert.Equal(t, e.For("missing", nil), nil)
+
+	e, err = Read(filepath.Join(t.TempDir(), ExclusionsFilename))
+	assert.NoError(t, err)
+	assert.Equal(t, e.For("default", nil), nil)
+}
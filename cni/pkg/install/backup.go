@@ -0,0 +1,235 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"istio.io/istio/pkg/file"
+)
+
+// cniConfigBackupDir is the directory in the CNI net dir holding backups of the primary CNI config file.
+// Container runtimes only load CNI config files from the net dir itself, so they never pick up backups.
+const cniConfigBackupDir = ".istio-cni-backup"
+
+// writtenRecordSuffix is the suffix of the file recording which config file Istio CNI last wrote.
+const writtenRecordSuffix = ".written"
+
+// configBackups keeps versioned backups of the primary CNI config file, as it was before Istio CNI
+// inserted itself. Each time the primary CNI (re)writes its config, a new version is kept, so that the
+// config can be restored verbatim on uninstall instead of being rewritten from a parsed copy.
+//
+// Backups are named <config file name>.<version>.<sha256 of the content>, so their integrity can be
+// checked before they are restored.
+type configBackups struct {
+	dir  string
+	keep int
+}
+
+// writtenRecord records the config file Istio CNI wrote, and the primary config file it was derived from.
+type writtenRecord struct {
+	// Source is the name of the primary config file, which differs from the written file when a
+	// .conf file was converted to a .conflist.
+	Source   string `json:"source"`
+	Checksum string `json:"checksum"`
+}
+
+// configBackup is a single backup version of a primary CNI config file.
+type configBackup struct {
+	path     string
+	version  int
+	checksum string
+}
+
+func newConfigBackups(cniNetDir string, keep int) configBackups {
+	return configBackups{dir: filepath.Join(cniNetDir, cniConfigBackupDir), keep: keep}
+}
+
+func (b configBackups) enabled() bool {
+	return b.keep > 0
+}
+
+// save keeps a backup of the content of the primary config file, unless it is identical to the latest backup.
+// Versions beyond the configured number of backups are pruned, oldest first.
+func (b configBackups) save(filename string, content []byte) error {
+	if !b.enabled() {
+		return nil
+	}
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return err
+	}
+	backups, err := b.list(filename)
+	if err != nil {
+		return err
+	}
+	sum := checksum(content)
+	version := 1
+	if len(backups) > 0 {
+		latest := backups[len(backups)-1]
+		if latest.checksum == sum {
+			return nil
+		}
+		version = latest.version + 1
+	}
+	path := filepath.Join(b.dir, fmt.Sprintf("%s.%d.%s", filename, version, sum))
+	if err := file.AtomicWrite(path, content, os.FileMode(0o644)); err != nil {
+		return err
+	}
+	installLog.Infof("Backed up CNI config file %s to %s", filename, path)
+
+	backups = append(backups, configBackup{path: path, version: version, checksum: sum})
+	for len(backups) > b.keep {
+		if err := os.Remove(backups[0].path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// latest returns the content of the latest backup of the primary config file, or nil if there is none.
+// It fails if the content no longer matches the checksum it was saved with.
+func (b configBackups) latest(filename string) ([]byte, error) {
+	backups, err := b.list(filename)
+	if err != nil || len(backups) == 0 {
+		return nil, err
+	}
+	latest := backups[len(backups)-1]
+	content, err := os.ReadFile(latest.path)
+	if err != nil {
+		return nil, err
+	}
+	if checksum(content) != latest.checksum {
+		return nil, fmt.Errorf("backup %s is corrupted: checksum mismatch", latest.path)
+	}
+	return content, nil
+}
+
+// list returns the backups of the primary config file, sorted by version.
+func (b configBackups) list(filename string) ([]configBackup, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var backups []configBackup
+	for _, e := range entries {
+		rest, found := strings.CutPrefix(e.Name(), filename+".")
+		if !found || e.IsDir() {
+			continue
+		}
+		v, sum, found := strings.Cut(rest, ".")
+		version, err := strconv.Atoi(v)
+		if !found || err != nil || len(sum) != sha256.Size*2 {
+			continue
+		}
+		backups = append(backups, configBackup{path: filepath.Join(b.dir, e.Name()), version: version, checksum: sum})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].version < backups[j].version
+	})
+	return backups, nil
+}
+
+// recordWritten records that Istio CNI wrote content to the config file at path, derived from the
+// primary config file named source.
+func (b configBackups) recordWritten(path, source string, content []byte) error {
+	if !b.enabled() {
+		return nil
+	}
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return err
+	}
+	record, err := json.Marshal(writtenRecord{Source: source, Checksum: checksum(content)})
+	if err != nil {
+		return err
+	}
+	return file.AtomicWrite(b.writtenRecordPath(path), record, os.FileMode(0o644))
+}
+
+// written returns what Istio CNI last wrote to the config file at path, or nil if nothing was recorded.
+func (b configBackups) written(path string) (*writtenRecord, error) {
+	by, err := os.ReadFile(b.writtenRecordPath(path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	record := &writtenRecord{}
+	if err := json.Unmarshal(by, record); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.writtenRecordPath(path), err)
+	}
+	return record, nil
+}
+
+func (b configBackups) writtenRecordPath(path string) string {
+	return filepath.Join(b.dir, filepath.Base(path)+writtenRecordSuffix)
+}
+
+// restore writes the latest backup of the primary config back in place of the config file at path.
+// The backup is only restored if the file still holds what Istio CNI wrote to it; if the primary CNI
+// rewrote its config since, the file is left alone and restore returns false.
+func (b configBackups) restore(path string) (bool, error) {
+	if !b.enabled() {
+		return false, nil
+	}
+	record, err := b.written(path)
+	if err != nil || record == nil {
+		return false, err
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	if checksum(current) != record.Checksum {
+		installLog.Infof("CNI config file %s was modified since Istio CNI wrote it, not restoring backup", path)
+		return false, nil
+	}
+	original, err := b.latest(record.Source)
+	if err != nil || original == nil {
+		return false, err
+	}
+
+	sourcePath := filepath.Join(filepath.Dir(path), record.Source)
+	installLog.Infof("Restoring CNI config file %s from backup", sourcePath)
+	if err := file.AtomicWrite(sourcePath, original, os.FileMode(0o644)); err != nil {
+		return false, err
+	}
+	if sourcePath != path {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+	if err := os.Remove(b.writtenRecordPath(path)); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"istio.io/istio/cni/pkg/config"
+	testutils "istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/file"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestConfigBackups(t *testing.T) {
+	backups := newConfigBackups(t.TempDir(), 2)
+	versions := [][]byte{[]byte(`{"v":1}`), []byte(`{"v":2}`), []byte(`{"v":3}`)}
+	for _, v := range versions {
+		assert.NoError(t, backups.save("10-net.conflist", v))
+		// Saving identical content again must not add a version.
+		assert.NoError(t, backups.save("10-net.conflist", v))
+	}
+	assert.NoError(t, backups.save("20-other.conflist", []byte(`{}`)))
+
+	list, err := backups.list("10-net.conflist")
+	assert.NoError(t, err)
+	assert.Equal(t, len(list), 2)
+	assert.Equal(t, list[0].version, 2)
+	assert.Equal(t, list[1].version, 3)
+
+	latest, err := backups.latest("10-net.conflist")
+	assert.NoError(t, err)
+	assert.Equal(t, latest, versions[2])
+
+	// A backup whose content no longer matches its checksum is never restored.
+	assert.NoError(t, os.WriteFile(list[1].path, []byte(`{"v":"corrupted"}`), 0o644))
+	if _, err := backups.latest("10-net.conflist"); err == nil {
+		t.Fatal("expected checksum mismatch error")
+	}
+
+	latest, err = backups.latest("missing.conflist")
+	assert.NoError(t, err)
+	assert.Equal(t, latest, nil)
+}
+
+func TestConfigBackupsDisabled(t *testing.T) {
+	dir := t.TempDir()
+	backups := newConfigBackups(dir, 0)
+	assert.NoError(t, backups.save("10-net.conflist", []byte(`{}`)))
+	assert.NoError(t, backups.recordWritten(filepath.Join(dir, "10-net.conflist"), "10-net.conflist", []byte(`{}`)))
+	if file.Exists(filepath.Join(dir, cniConfigBackupDir)) {
+		t.Fatal("backup directory created with backups disabled")
+	}
+}
+
+func TestCleanupRestoresBackup(t *testing.T) {
+	cases := []struct {
+		name             string
+		primaryRewritten bool
+		expectedConfig   string
+	}{
+		{
+			name:           "restores original config",
+			expectedConfig: "bridge.conf",
+		},
+		{
+			name:             "primary CNI rewrote its config",
+			primaryRewritten: true,
+			expectedConfig:   "list-no-istio.conflist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cniNetDir := t.TempDir()
+			if err := file.AtomicCopy(filepath.Join("testdata", "bridge.conf"), cniNetDir, "bridge.conf"); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.InstallConfig{
+				MountedCNINetDir:     cniNetDir,
+				ChainedCNIPlugin:     true,
+				CNIConfBackups:       3,
+				CNINetworkConfigFile: filepath.Join("testdata", "istio-cni.conf.template"),
+				KubeconfigFilename:   "kubeconfig",
+			}
+			cniConfigFilepath, err := createCNIConfigFile(context.Background(), cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, cniConfigFilepath, filepath.Join(cniNetDir, "bridge.conflist"))
+
+			if c.primaryRewritten {
+				if err := file.AtomicCopy(filepath.Join("testdata", "list-with-istio.conflist"), cniNetDir, "bridge.conflist"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			isReady := &atomic.Value{}
+			isReady.Store(false)
+			installer := NewInstaller(cfg, isReady)
+			installer.cniConfigFilepath = cniConfigFilepath
+			assert.NoError(t, installer.Cleanup())
+
+			resultFilepath := cniConfigFilepath
+			if !c.primaryRewritten {
+				resultFilepath = filepath.Join(cniNetDir, "bridge.conf")
+				if file.Exists(cniConfigFilepath) {
+					t.Fatalf("%s was not removed after restoring bridge.conf", cniConfigFilepath)
+				}
+			}
+			expectedFilepath := filepath.Join("testdata", c.expectedConfig)
+			testutils.CompareBytes(t, testutils.ReadFile(t, resultFilepath), testutils.ReadFile(t, expectedFilepath), expectedFilepath)
+		})
+	}
+}
+
+func TestWriteChainedCNIConfig(t *testing.T) {
+	cniNetDir := t.TempDir()
+	cniConfigFilepath := filepath.Join(cniNetDir, "list.conflist")
+	if err := file.AtomicCopy(filepath.Join("testdata", "list.conflist"), cniNetDir, "list.conflist"); err != nil {
+		t.Fatal(err)
+	}
+	istioConfig := testutils.ReadFile(t, filepath.Join("testdata", "istio-cni.conf"))
+
+	written, err := writeChainedCNIConfig(cniConfigFilepath, istioConfig, newConfigBackups(cniNetDir, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, testutils.ReadFile(t, cniConfigFilepath), written)
+
+	hasIstio, err := hasIstioCNIPlugin(written)
+	assert.NoError(t, err)
+	assert.Equal(t, hasIstio, true)
+
+	// Only the original config, without istio-cni, is backed up.
+	backup, err := newConfigBackups(cniNetDir, 1).latest("list.conflist")
+	assert.NoError(t, err)
+	assert.Equal(t, backup, testutils.ReadFile(t, filepath.Join("testdata", "list.conflist")))
+}
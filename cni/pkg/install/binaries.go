@@ -15,28 +15,81 @@
 package install
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"istio.io/istio/pkg/file"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/util/sets"
 )
 
-// Copies/mirrors any files present in a single source dir to N number of target dirs
-// and returns a set of the filenames copied.
-func copyBinaries(srcDir string, targetDirs []string) (sets.Set[string], error) {
-	copiedFilenames := sets.Set[string]{}
+// binaryManifestFilename is the name of the manifest of the checksums of the binaries, in the format of sha256sum,
+// which the image may ship along with the binaries. It is not copied.
+const binaryManifestFilename = "SHA256SUMS"
+
+// binaryManifest maps the filenames of the binaries to their SHA-256 checksums.
+type binaryManifest map[string]string
+
+// loadBinaryManifest returns the checksums of the binaries of the source dir. If the dir has a manifest, every
+// binary it lists must match its checksum, to not install binaries corrupted in the image.
+func loadBinaryManifest(srcDir string) (binaryManifest, error) {
 	srcFiles, err := os.ReadDir(srcDir)
 	if err != nil {
-		return copiedFilenames, err
+		return nil, err
 	}
-
+	manifest := binaryManifest{}
 	for _, f := range srcFiles {
-		if f.IsDir() {
+		if f.IsDir() || f.Name() == binaryManifestFilename {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(srcDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		manifest[f.Name()] = checksum(content)
+	}
+
+	expected, err := os.ReadFile(filepath.Join(srcDir, binaryManifestFilename))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	} else if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(expected))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
 			continue
 		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line in %s: %q", binaryManifestFilename, line)
+		}
+		// sha256sum marks the files checksummed in binary mode with a '*'.
+		sum, filename := fields[0], strings.TrimPrefix(fields[1], "*")
+		actual, f := manifest[filename]
+		if !f {
+			return nil, fmt.Errorf("binary %s listed in %s is missing", filename, binaryManifestFilename)
+		}
+		if actual != sum {
+			return nil, fmt.Errorf("binary %s does not match its checksum in %s: expected %s, got %s",
+				filename, binaryManifestFilename, sum, actual)
+		}
+	}
+	return manifest, scanner.Err()
+}
 
-		filename := f.Name()
+// Copies/mirrors the binaries of the manifest present in a single source dir to N number of target dirs
+// and returns a set of the filenames copied.
+func copyBinaries(srcDir string, targetDirs []string, manifest binaryManifest) (sets.Set[string], error) {
+	copiedFilenames := sets.Set[string]{}
+	for _, filename := range manifest.filenames() {
 		srcFilepath := filepath.Join(srcDir, filename)
 
 		for _, targetDir := range targetDirs {
@@ -45,8 +98,7 @@ func copyBinaries(srcDir string, targetDirs []string) (sets.Set[string], error)
 				continue
 			}
 
-			err := file.AtomicCopy(srcFilepath, targetDir, filename)
-			if err != nil {
+			if err := copyBinary(srcFilepath, targetDir, filename, manifest[filename]); err != nil {
 				return copiedFilenames, err
 			}
 			installLog.Infof("Copied %s to %s.", filename, targetDir)
@@ -57,3 +109,84 @@ func copyBinaries(srcDir string, targetDirs []string) (sets.Set[string], error)
 
 	return copiedFilenames, nil
 }
+
+// copyBinary writes the binary to a temporary file of the target dir, verifies its checksum, then renames it,
+// so that the CNI runtime never executes a partially written or corrupted binary.
+func copyBinary(srcFilepath, targetDir, filename, sum string) error {
+	info, err := os.Stat(srcFilepath)
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(srcFilepath)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(targetDir, "."+filename+".tmp-")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// The temporary file is gone once renamed.
+		_ = os.Remove(tmpPath)
+	}()
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode()); err != nil {
+		return err
+	}
+	written, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return err
+	}
+	if actual := checksum(written); actual != sum {
+		return fmt.Errorf("copy of %s to %s does not match its checksum: expected %s, got %s", filename, targetDir, sum, actual)
+	}
+	return os.Rename(tmpPath, filepath.Join(targetDir, filename))
+}
+
+// binaryMismatch is an installed binary which does not match its checksum.
+type binaryMismatch struct {
+	path   string
+	reason string
+}
+
+// verifyBinaries returns the installed binaries of the writable target dirs which are missing or do not match
+// their checksums, for instance because another process truncated or replaced them.
+func verifyBinaries(targetDirs []string, manifest binaryManifest) []binaryMismatch {
+	var mismatches []binaryMismatch
+	for _, targetDir := range targetDirs {
+		if err := file.IsDirWriteable(targetDir); err != nil {
+			continue
+		}
+		for _, filename := range manifest.filenames() {
+			path := filepath.Join(targetDir, filename)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				mismatches = append(mismatches, binaryMismatch{path: path, reason: err.Error()})
+				continue
+			}
+			if actual := checksum(content); actual != manifest[filename] {
+				mismatches = append(mismatches, binaryMismatch{
+					path:   path,
+					reason: fmt.Sprintf("expected checksum %s, got %s", manifest[filename], actual),
+				})
+			}
+		}
+	}
+	return mismatches
+}
+
+// filenames returns the sorted filenames of the binaries.
+func (m binaryManifest) filenames() []string {
+	return slices.Sort(maps.Keys(m))
+}
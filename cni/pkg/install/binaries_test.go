@@ -15,9 +15,19 @@
 package install
 
 import (
+	"context"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/cni/pkg/config"
+	"istio.io/istio/cni/pkg/util"
 	"istio.io/istio/pkg/test/util/assert"
 	"istio.io/istio/pkg/test/util/file"
 )
@@ -54,7 +64,11 @@ func TestCopyBinaries(t *testing.T) {
 				file.WriteOrFail(t, filepath.Join(targetDir, filename), []byte(contents))
 			}
 
-			binariesCopied, err := copyBinaries(srcDir, []string{targetDir})
+			manifest, err := loadBinaryManifest(srcDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			binariesCopied, err := copyBinaries(srcDir, []string{targetDir}, manifest)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -74,3 +88,111 @@ func TestCopyBinaries(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadBinaryManifest(t *testing.T) {
+	cases := []struct {
+		name        string
+		manifest    string
+		expectedErr string
+	}{
+		{
+			name: "no manifest",
+		},
+		{
+			name:     "matching manifest",
+			manifest: checksum([]byte("cni111")) + "  istio-cni\n" + checksum([]byte("iptables111")) + " *istio-iptables\n",
+		},
+		{
+			name:        "mismatching manifest",
+			manifest:    checksum([]byte("cni000")) + "  istio-cni\n",
+			expectedErr: "binary istio-cni does not match its checksum",
+		},
+		{
+			name:        "missing binary",
+			manifest:    checksum([]byte("cni111")) + "  istio-cni-repair\n",
+			expectedErr: "binary istio-cni-repair listed in SHA256SUMS is missing",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srcDir := t.TempDir()
+			file.WriteOrFail(t, filepath.Join(srcDir, "istio-cni"), []byte("cni111"))
+			file.WriteOrFail(t, filepath.Join(srcDir, "istio-iptables"), []byte("iptables111"))
+			if c.manifest != "" {
+				file.WriteOrFail(t, filepath.Join(srcDir, binaryManifestFilename), []byte(c.manifest))
+			}
+
+			manifest, err := loadBinaryManifest(srcDir)
+			if c.expectedErr != "" {
+				assert.Error(t, err)
+				if !strings.Contains(err.Error(), c.expectedErr) {
+					t.Fatalf("expected error %q, got %v", c.expectedErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, manifest, binaryManifest{
+				"istio-cni":      checksum([]byte("cni111")),
+				"istio-iptables": checksum([]byte("iptables111")),
+			})
+		})
+	}
+}
+
+func TestVerifyBinaries(t *testing.T) {
+	targetDir := t.TempDir()
+	manifest := binaryManifest{
+		"istio-cni":      checksum([]byte("cni111")),
+		"istio-iptables": checksum([]byte("iptables111")),
+	}
+	file.WriteOrFail(t, filepath.Join(targetDir, "istio-cni"), []byte("cni111"))
+	file.WriteOrFail(t, filepath.Join(targetDir, "istio-iptables"), []byte("iptables111"))
+	assert.Equal(t, len(verifyBinaries([]string{targetDir}, manifest)), 0)
+
+	// Truncated in place, and removed.
+	file.WriteOrFail(t, filepath.Join(targetDir, "istio-cni"), []byte("cni"))
+	if err := os.Remove(filepath.Join(targetDir, "istio-iptables")); err != nil {
+		t.Fatal(err)
+	}
+	mismatches := verifyBinaries([]string{targetDir}, manifest)
+	assert.Equal(t, len(mismatches), 2)
+	assert.Equal(t, mismatches[0].path, filepath.Join(targetDir, "istio-cni"))
+	assert.Equal(t, mismatches[1].path, filepath.Join(targetDir, "istio-iptables"))
+}
+
+func TestWaitForChangesRepairsBinaries(t *testing.T) {
+	srcDir := t.TempDir()
+	targetDir := t.TempDir()
+	file.WriteOrFail(t, filepath.Join(srcDir, "istio-cni"), []byte("cni111"))
+
+	in := NewInstaller(&config.InstallConfig{
+		CNIBinSourceDir:      srcDir,
+		CNIBinTargetDirs:     []string{targetDir},
+		CNIBinVerifyInterval: 10 * time.Millisecond,
+		K8sNodeName:          "node",
+	}, &atomic.Value{})
+	client := fake.NewSimpleClientset()
+	in.RecordEvents(client, "istio-cni-node-abcde", "istio-system")
+	manifest, err := loadBinaryManifest(srcDir)
+	assert.NoError(t, err)
+	in.binaries = manifest
+	_, err = copyBinaries(srcDir, []string{targetDir}, manifest)
+	assert.NoError(t, err)
+
+	// A watcher of another directory, as the mismatch is to be found by the periodic verification.
+	watcher, err := util.CreateFileWatcher(t.TempDir())
+	assert.NoError(t, err)
+	defer watcher.Close()
+	file.WriteOrFail(t, filepath.Join(targetDir, "istio-cni"), []byte("cni"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, in.waitForChanges(ctx, watcher))
+
+	events, err := client.CoreV1().Events("istio-system").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(events.Items), 1)
+	assert.Equal(t, events.Items[0].Reason, "BinaryChecksumMismatch")
+	assert.Equal(t, events.Items[0].InvolvedObject.Name, "istio-cni-node-abcde")
+}
@@ -17,6 +17,7 @@ package install
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -34,6 +35,7 @@ type pluginConfig struct {
 	mountedCNINetDir string
 	cniConfName      string
 	chainedCNIPlugin bool
+	cniConfBackups   int
 }
 
 type cniConfigTemplate struct {
@@ -56,6 +58,7 @@ func getPluginConfig(cfg *config.InstallConfig) pluginConfig {
 		mountedCNINetDir: cfg.MountedCNINetDir,
 		cniConfName:      cfg.CNIConfName,
 		chainedCNIPlugin: cfg.ChainedCNIPlugin,
+		cniConfBackups:   cfg.CNIConfBackups,
 	}
 }
 
@@ -123,33 +126,41 @@ func replaceCNIConfigVars(cniConfig []byte, vars cniConfigVars) []byte {
 	return []byte(cniConfigStr)
 }
 
+// maxCNIConfigWriteAttempts is how many times writing the chained CNI config is attempted when the
+// primary CNI rewrites its config file while Istio CNI is modifying it.
+const maxCNIConfigWriteAttempts = 3
+
 func writeCNIConfig(ctx context.Context, cniConfig []byte, cfg pluginConfig) (string, error) {
 	cniConfigFilepath, err := getCNIConfigFilepath(ctx, cfg)
 	if err != nil {
 		return "", err
 	}
 
-	if cfg.chainedCNIPlugin {
-		if !file.Exists(cniConfigFilepath) {
-			return "", fmt.Errorf("CNI config file %s removed during configuration", cniConfigFilepath)
-		}
-		// This section overwrites an existing plugins list entry for istio-cni
-		existingCNIConfig, err := os.ReadFile(cniConfigFilepath)
-		if err != nil {
-			return "", err
-		}
-		cniConfig, err = insertCNIConfig(cniConfig, existingCNIConfig)
-		if err != nil {
-			return "", err
+	if !cfg.chainedCNIPlugin {
+		if err = file.AtomicWrite(cniConfigFilepath, cniConfig, os.FileMode(0o644)); err != nil {
+			installLog.Errorf("Failed to write CNI config file %v: %v", cniConfigFilepath, err)
+			return cniConfigFilepath, err
 		}
+		installLog.Infof("Created CNI config %s", cniConfigFilepath)
+		return cniConfigFilepath, nil
 	}
 
-	if err = file.AtomicWrite(cniConfigFilepath, cniConfig, os.FileMode(0o644)); err != nil {
+	backups := newConfigBackups(cfg.mountedCNINetDir, cfg.cniConfBackups)
+	var written []byte
+	for attempt := 1; ; attempt++ {
+		written, err = writeChainedCNIConfig(cniConfigFilepath, cniConfig, backups)
+		if !errors.Is(err, errCNIConfigChanged) || attempt == maxCNIConfigWriteAttempts {
+			break
+		}
+		installLog.Warnf("%v, retrying", err)
+	}
+	if err != nil {
 		installLog.Errorf("Failed to write CNI config file %v: %v", cniConfigFilepath, err)
 		return cniConfigFilepath, err
 	}
 
-	if cfg.chainedCNIPlugin && strings.HasSuffix(cniConfigFilepath, ".conf") {
+	source := filepath.Base(cniConfigFilepath)
+	if strings.HasSuffix(cniConfigFilepath, ".conf") {
 		// If the old CNI config filename ends with .conf, rename it to .conflist, because it has to be changed to a list
 		installLog.Infof("Renaming %s extension to .conflist", cniConfigFilepath)
 		err = os.Rename(cniConfigFilepath, cniConfigFilepath+"list")
@@ -159,11 +170,78 @@ func writeCNIConfig(ctx context.Context, cniConfig []byte, cfg pluginConfig) (st
 		}
 		cniConfigFilepath += "list"
 	}
+	if err := backups.recordWritten(cniConfigFilepath, source, written); err != nil {
+		installLog.Warnf("Failed to record checksum of CNI config file %v: %v", cniConfigFilepath, err)
+	}
 
 	installLog.Infof("Created CNI config %s", cniConfigFilepath)
 	return cniConfigFilepath, nil
 }
 
+// errCNIConfigChanged is returned when the primary CNI config file changed while it was being modified.
+var errCNIConfigChanged = errors.New("CNI config file changed during configuration")
+
+// writeChainedCNIConfig inserts the istio-cni config into the primary CNI config file. The original
+// content is backed up first, and the file is only overwritten if its checksum still matches the
+// content the new config was derived from. It returns the content written.
+func writeChainedCNIConfig(cniConfigFilepath string, cniConfig []byte, backups configBackups) ([]byte, error) {
+	if !file.Exists(cniConfigFilepath) {
+		return nil, fmt.Errorf("CNI config file %s removed during configuration", cniConfigFilepath)
+	}
+	// This section overwrites an existing plugins list entry for istio-cni
+	existingCNIConfig, err := os.ReadFile(cniConfigFilepath)
+	if err != nil {
+		return nil, err
+	}
+	newCNIConfig, err := insertCNIConfig(cniConfig, existingCNIConfig)
+	if err != nil {
+		return nil, err
+	}
+	if hasIstio, err := hasIstioCNIPlugin(existingCNIConfig); err == nil && !hasIstio {
+		// The file is the config as written by the primary CNI, keep it so it can be restored later.
+		if err := backups.save(filepath.Base(cniConfigFilepath), existingCNIConfig); err != nil {
+			return nil, fmt.Errorf("failed to back up CNI config file %s: %v", cniConfigFilepath, err)
+		}
+	}
+
+	currentCNIConfig, err := os.ReadFile(cniConfigFilepath)
+	if err != nil {
+		return nil, err
+	}
+	if checksum(currentCNIConfig) != checksum(existingCNIConfig) {
+		return nil, fmt.Errorf("%w: %s", errCNIConfigChanged, cniConfigFilepath)
+	}
+	if err := file.AtomicWrite(cniConfigFilepath, newCNIConfig, os.FileMode(0o644)); err != nil {
+		return nil, err
+	}
+	return newCNIConfig, nil
+}
+
+// hasIstioCNIPlugin returns whether the CNI config already contains the istio-cni plugin.
+func hasIstioCNIPlugin(cniConfig []byte) (bool, error) {
+	var cniConfigMap map[string]any
+	if err := json.Unmarshal(cniConfig, &cniConfigMap); err != nil {
+		return false, err
+	}
+	if _, ok := cniConfigMap["type"]; ok {
+		return cniConfigMap["type"] == "istio-cni", nil
+	}
+	plugins, err := util.GetPlugins(cniConfigMap)
+	if err != nil {
+		return false, err
+	}
+	for _, rawPlugin := range plugins {
+		plugin, err := util.GetPlugin(rawPlugin)
+		if err != nil {
+			return false, err
+		}
+		if plugin["type"] == "istio-cni" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // If configured as chained CNI plugin, waits indefinitely for a main CNI config file to exist before returning
 // Or until cancelled by parent context
 func getCNIConfigFilepath(ctx context.Context, cfg pluginConfig) (string, error) {
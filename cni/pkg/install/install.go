@@ -20,6 +20,11 @@ import (
 	"os"
 	"path/filepath"
 	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	"istio.io/istio/cni/pkg/config"
 	"istio.io/istio/cni/pkg/constants"
@@ -36,6 +41,13 @@ type Installer struct {
 	isReady            *atomic.Value
 	kubeconfigFilepath string
 	cniConfigFilepath  string
+	// binaries are the checksums of the installed binaries.
+	binaries binaryManifest
+
+	// events records the Events about the installed binaries on the pod of the installer, if set.
+	events       kubernetes.Interface
+	podName      string
+	podNamespace string
 }
 
 // NewInstaller returns an instance of Installer with the given config
@@ -47,8 +59,21 @@ func NewInstaller(cfg *config.InstallConfig, isReady *atomic.Value) *Installer {
 	}
 }
 
+// RecordEvents makes the installer record an Event on its pod when installed binaries do not match their checksums.
+func (in *Installer) RecordEvents(client kubernetes.Interface, podName, podNamespace string) {
+	in.events = client
+	in.podName = podName
+	in.podNamespace = podNamespace
+}
+
 func (in *Installer) install(ctx context.Context) (sets.Set[string], error) {
-	copiedFiles, err := copyBinaries(in.cfg.CNIBinSourceDir, in.cfg.CNIBinTargetDirs)
+	manifest, err := loadBinaryManifest(in.cfg.CNIBinSourceDir)
+	if err != nil {
+		cniInstalls.With(resultLabel.Value(resultCopyBinariesFailure)).Increment()
+		return nil, fmt.Errorf("load binaries: %v", err)
+	}
+	in.binaries = manifest
+	copiedFiles, err := copyBinaries(in.cfg.CNIBinSourceDir, in.cfg.CNIBinTargetDirs, manifest)
 	if err != nil {
 		cniInstalls.With(resultLabel.Value(resultCopyBinariesFailure)).Increment()
 		return copiedFiles, fmt.Errorf("copy binaries: %v", err)
@@ -98,34 +123,7 @@ func (in *Installer) Cleanup() error {
 	installLog.Info("Cleaning up.")
 	if len(in.cniConfigFilepath) > 0 && file.Exists(in.cniConfigFilepath) {
 		if in.cfg.ChainedCNIPlugin {
-			installLog.Infof("Removing Istio CNI config from CNI config file: %s", in.cniConfigFilepath)
-
-			// Read JSON from CNI config file
-			cniConfigMap, err := util.ReadCNIConfigMap(in.cniConfigFilepath)
-			if err != nil {
-				return err
-			}
-			// Find Istio CNI and remove from plugin list
-			plugins, err := util.GetPlugins(cniConfigMap)
-			if err != nil {
-				return fmt.Errorf("%s: %w", in.cniConfigFilepath, err)
-			}
-			for i, rawPlugin := range plugins {
-				plugin, err := util.GetPlugin(rawPlugin)
-				if err != nil {
-					return fmt.Errorf("%s: %w", in.cniConfigFilepath, err)
-				}
-				if plugin["type"] == "istio-cni" {
-					cniConfigMap["plugins"] = append(plugins[:i], plugins[i+1:]...)
-					break
-				}
-			}
-
-			cniConfig, err := util.MarshalCNIConfig(cniConfigMap)
-			if err != nil {
-				return err
-			}
-			if err = file.AtomicWrite(in.cniConfigFilepath, cniConfig, os.FileMode(0o644)); err != nil {
+			if err := in.cleanupChainedCNIConfig(); err != nil {
 				return err
 			}
 		} else {
@@ -154,6 +152,47 @@ func (in *Installer) Cleanup() error {
 	return nil
 }
 
+// cleanupChainedCNIConfig restores the primary CNI config file from its latest backup, if the file was not
+// rewritten since Istio CNI modified it. Otherwise, the istio-cni plugin is removed from the plugin list.
+func (in *Installer) cleanupChainedCNIConfig() error {
+	restored, err := newConfigBackups(in.cfg.MountedCNINetDir, in.cfg.CNIConfBackups).restore(in.cniConfigFilepath)
+	if err != nil {
+		installLog.Warnf("Failed to restore CNI config file %s from backup: %v", in.cniConfigFilepath, err)
+	}
+	if restored {
+		return nil
+	}
+
+	installLog.Infof("Removing Istio CNI config from CNI config file: %s", in.cniConfigFilepath)
+
+	// Read JSON from CNI config file
+	cniConfigMap, err := util.ReadCNIConfigMap(in.cniConfigFilepath)
+	if err != nil {
+		return err
+	}
+	// Find Istio CNI and remove from plugin list
+	plugins, err := util.GetPlugins(cniConfigMap)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in.cniConfigFilepath, err)
+	}
+	for i, rawPlugin := range plugins {
+		plugin, err := util.GetPlugin(rawPlugin)
+		if err != nil {
+			return fmt.Errorf("%s: %w", in.cniConfigFilepath, err)
+		}
+		if plugin["type"] == "istio-cni" {
+			cniConfigMap["plugins"] = append(plugins[:i], plugins[i+1:]...)
+			break
+		}
+	}
+
+	cniConfig, err := util.MarshalCNIConfig(cniConfigMap)
+	if err != nil {
+		return err
+	}
+	return file.AtomicWrite(in.cniConfigFilepath, cniConfig, os.FileMode(0o644))
+}
+
 // sleepCheckInstall verifies the configuration then blocks until an invalid configuration is detected, and return nil.
 // If an error occurs or context is canceled, the function will return the error.
 // Returning from this function will set the pod to "NotReady".
@@ -207,11 +246,73 @@ func (in *Installer) sleepCheckInstall(ctx context.Context, installedBinFiles se
 			SetReady(in.isReady)
 			cniInstalls.With(resultLabel.Value(resultSuccess)).Increment()
 			// Pod set to "NotReady" before termination
-			return watcher.Wait(ctx)
+			return in.waitForChanges(ctx, watcher)
 		}
 	}
 }
 
+// waitForChanges blocks until a watched file is modified. The checksums of the installed binaries are also verified
+// periodically, as the watches miss binaries replaced through another mount of the host directory, and returning
+// on a mismatch reinstalls them.
+func (in *Installer) waitForChanges(ctx context.Context, watcher *util.Watcher) error {
+	if in.cfg.CNIBinVerifyInterval <= 0 || len(in.binaries) == 0 {
+		return watcher.Wait(ctx)
+	}
+	ticker := time.NewTicker(in.cfg.CNIBinVerifyInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watcher.Events:
+			return nil
+		case err := <-watcher.Errors:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			mismatches := verifyBinaries(in.cfg.CNIBinTargetDirs, in.binaries)
+			for _, m := range mismatches {
+				in.recordBinaryMismatch(ctx, m)
+			}
+			if len(mismatches) > 0 {
+				return nil
+			}
+		}
+	}
+}
+
+// recordBinaryMismatch reports an installed binary which does not match its checksum.
+func (in *Installer) recordBinaryMismatch(ctx context.Context, m binaryMismatch) {
+	installLog.Warnf("Installed binary %s does not match its checksum (%s), repairing it", m.path, m.reason)
+	binaryMismatches.Increment()
+	if in.events == nil {
+		return
+	}
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: in.podName + ".",
+			Namespace:    in.podNamespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Name:       in.podName,
+			Namespace:  in.podNamespace,
+		},
+		Reason:              "BinaryChecksumMismatch",
+		Message:             fmt.Sprintf("Installed CNI binary %s does not match its checksum (%s), repairing it", m.path, m.reason),
+		Type:                corev1.EventTypeWarning,
+		Source:              corev1.EventSource{Component: "istio-cni", Host: in.cfg.K8sNodeName},
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+		ReportingController: "istio.io/cni",
+	}
+	if _, err := in.events.CoreV1().Events(in.podNamespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		installLog.Warnf("Failed to record the event of binary %s: %v", m.path, err)
+	}
+}
+
 // checkInstall returns an error if an invalid CNI configuration is detected
 func checkInstall(cfg *config.InstallConfig, cniConfigFilepath string) error {
 	defaultCNIConfigFilename, err := getDefaultCNINetwork(cfg.MountedCNINetDir)
@@ -28,6 +28,11 @@ var (
 		"Total number of CNI plugins installed by the Istio CNI installer",
 	)
 
+	binaryMismatches = monitoring.NewSum(
+		"istio_cni_binary_mismatches_total",
+		"Total number of installed CNI binaries found not to match their checksums, and repaired",
+	)
+
 	installReady = monitoring.NewGauge(
 		"istio_cni_install_ready",
 		"Whether the CNI plugin installation is ready or not",
@@ -0,0 +1,56 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iptableshelper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containernetworking/plugins/pkg/ns"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+	"istio.io/istio/tools/istio-iptables/pkg/cmd"
+	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
+// Program programs the rules of the request in the network namespace of the pod. It is the ProgramFunc of the
+// helper binary.
+func Program(ctx context.Context, req Request) (*capture.Result, error) {
+	netNs, err := ns.GetNS(req.Netns)
+	if err != nil {
+		err = fmt.Errorf("failed to open netns %q: %s", req.Netns, err)
+		if hint := dependencies.SELinuxHint(dependencies.DeniedOperation{
+			Description: "open the network namespace of the pod",
+			Path:        req.Netns,
+			Class:       "file",
+			Permission:  "read",
+		}, err); hint != "" {
+			log.Warn(hint)
+		}
+		return nil, err
+	}
+	defer netNs.Close()
+
+	var result *capture.Result
+	err = netNs.Do(func(_ ns.NetNS) error {
+		log.Infof("============= Start iptables configuration for %v =============", req.Pod)
+		defer log.Infof("============= End iptables configuration for %v =============", req.Pod)
+		var err error
+		result, err = cmd.ProgramIptables(ctx, req.Config)
+		return err
+	})
+	return result, err
+}
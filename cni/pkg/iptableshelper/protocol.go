@@ -0,0 +1,123 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package iptableshelper implements istio-iptables-helper, the small privileged binary programming the traffic
+// capture of pods, and the protocol the istio-cni plugin runs it with. The plugin writes a Request as JSON on the
+// stdin of the helper, which writes a Response as JSON on its stdout and logs on its stderr, so that only the
+// helper needs CAP_NET_ADMIN and can be audited on its own.
+package iptableshelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+)
+
+const (
+	// ProtocolVersion is the version of the protocol between the plugin and the helper. The helper refuses the
+	// requests of other versions, so that a plugin and a helper of incompatible releases fail loudly.
+	ProtocolVersion = "v1"
+	// BinaryName is the name of the helper, installed next to the istio-cni plugin.
+	BinaryName = "istio-iptables-helper"
+)
+
+// Request asks the helper to program the traffic capture of a pod.
+type Request struct {
+	Version string `json:"version"`
+	// Pod is the name of the pod, for the logs.
+	Pod string `json:"pod"`
+	// Netns is the path of the network namespace of the pod.
+	Netns string `json:"netns"`
+	// Config is the complete configuration of the rules, which the helper does not complete from its environment.
+	Config *config.Config `json:"config"`
+}
+
+// Response is the outcome of a Request.
+type Response struct {
+	Version string          `json:"version"`
+	Result  *capture.Result `json:"result,omitempty"`
+	// Error is why the rules could not be programmed, empty if they were.
+	Error string `json:"error,omitempty"`
+}
+
+// Run runs the helper at path with the request and returns what it programmed.
+func Run(ctx context.Context, path string, req Request) (*capture.Result, error) {
+	req.Version = ProtocolVersion
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("%s failed: %v: %s", path, runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("invalid response of %s: %v", path, err)
+	}
+	if resp.Version != ProtocolVersion {
+		return nil, fmt.Errorf("%s answered with protocol version %q, expected %q", path, resp.Version, ProtocolVersion)
+	}
+	if resp.Error != "" {
+		return resp.Result, errors.New(resp.Error)
+	}
+	if runErr != nil {
+		return resp.Result, fmt.Errorf("%s failed: %v", path, runErr)
+	}
+	return resp.Result, nil
+}
+
+// ProgramFunc programs the traffic capture of a Request.
+type ProgramFunc func(ctx context.Context, req Request) (*capture.Result, error)
+
+// Serve answers the Request read from in on out, programming it with program. It returns the error of the
+// Response, if any, for the exit code of the helper.
+func Serve(ctx context.Context, in io.Reader, out io.Writer, program ProgramFunc) error {
+	resp := Response{Version: ProtocolVersion}
+	var req Request
+	if err := json.NewDecoder(in).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else if req.Version != ProtocolVersion {
+		resp.Error = fmt.Sprintf("unsupported protocol version %q, expected %q", req.Version, ProtocolVersion)
+	} else if req.Config == nil {
+		resp.Error = "invalid request: no config"
+	} else {
+		result, err := program(ctx, req)
+		resp.Result = result
+		if err != nil {
+			resp.Error = err.Error()
+		}
+	}
+	if err := json.NewEncoder(out).Encode(resp); err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
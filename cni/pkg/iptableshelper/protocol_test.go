@@ -0,0 +1,103 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iptableshelper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+)
+
+func TestServe(t *testing.T) {
+	program := func(_ context.Context, req Request) (*capture.Result, error) {
+		if req.Config.ProxyPort != "15001" {
+			return nil, errors.New("unexpected proxy port " + req.Config.ProxyPort)
+		}
+		return &capture.Result{RulesV4: 3, Families: []string{"ipv4"}}, nil
+	}
+	cfg := config.DefaultConfig()
+	cfg.ProxyPort = "15001"
+	otherCfg := config.DefaultConfig()
+	otherCfg.ProxyPort = "15002"
+	cases := []struct {
+		name    string
+		request any
+		want    Response
+	}{
+		{
+			name:    "programmed",
+			request: Request{Version: ProtocolVersion, Pod: "pod", Netns: "/var/run/netns/pod", Config: cfg},
+			want:    Response{Version: ProtocolVersion, Result: &capture.Result{RulesV4: 3, Families: []string{"ipv4"}}},
+		},
+		{
+			name:    "failed",
+			request: Request{Version: ProtocolVersion, Config: otherCfg},
+			want:    Response{Version: ProtocolVersion, Error: "unexpected proxy port 15002"},
+		},
+		{
+			name:    "other version",
+			request: Request{Version: "v0", Config: cfg},
+			want:    Response{Version: ProtocolVersion, Error: `unsupported protocol version "v0", expected "v1"`},
+		},
+		{
+			name:    "no config",
+			request: Request{Version: ProtocolVersion},
+			want:    Response{Version: ProtocolVersion, Error: "invalid request: no config"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := json.Marshal(tt.request)
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			err = Serve(context.Background(), bytes.NewReader(in), &out, program)
+			assert.Equal(t, err != nil, tt.want.Error != "")
+			var got Response
+			assert.NoError(t, json.Unmarshal(out.Bytes(), &got))
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	helper := func(script string) string {
+		path := filepath.Join(t.TempDir(), BinaryName)
+		assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\ncat > /dev/null\n"+script), 0o755))
+		return path
+	}
+	req := Request{Pod: "pod", Netns: "/var/run/netns/pod", Config: config.DefaultConfig()}
+
+	result, err := Run(context.Background(), helper(`echo '{"version":"v1","result":{"RulesV4":3}}'`), req)
+	assert.NoError(t, err)
+	assert.Equal(t, result.Rules(), 3)
+
+	_, err = Run(context.Background(), helper(`echo '{"version":"v1","error":"iptables-restore failed"}'; exit 1`), req)
+	assert.Error(t, err)
+	assert.Equal(t, err.Error(), "iptables-restore failed")
+
+	_, err = Run(context.Background(), helper(`echo '{"version":"v2"}'`), req)
+	assert.Error(t, err)
+
+	_, err = Run(context.Background(), helper(`echo 'permission denied' >&2; exit 1`), req)
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"istio.io/istio/cni/pkg/constants"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/monitoring"
+)
+
+// Capture decisions of the CNI plugin for a pod.
+const (
+	// DecisionCaptured pods had their traffic capture set up.
+	DecisionCaptured = "captured"
+	// DecisionFailedClosed pods failed to start as their traffic capture could not be set up.
+	DecisionFailedClosed = "failed-closed"
+	// DecisionFailedOpen pods were started without traffic capture, as it could not be set up.
+	DecisionFailedOpen = "failed-open"
+)
+
+// Reasons of the capture decisions of the CNI plugin.
+const (
+	ReasonSuccess = "success"
+	// ReasonLatencyBudget decisions were taken because the latency budget of the capture was exceeded.
+	ReasonLatencyBudget = "latency-budget-exceeded"
+	// ReasonError decisions were taken because every attempt to set up the capture failed.
+	ReasonError = "error"
+)
+
+// CaptureDecision is reported by the CNI plugin to the node agent, which runs long enough to export
+// it as a metric, for each pod it sets up the traffic capture of.
+type CaptureDecision struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+	Attempts int    `json:"attempts"`
+	// Pod is the namespace/name of the pod.
+	Pod string `json:"pod,omitempty"`
+	// Rules, Families, Warnings and DurationSeconds describe what the successful attempt programmed.
+	Rules           int      `json:"rules,omitempty"`
+	Families        []string `json:"families,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+	DurationSeconds float64  `json:"durationSeconds,omitempty"`
+}
+
+var (
+	decisionLabel = monitoring.CreateLabel("decision")
+	reasonLabel   = monitoring.CreateLabel("reason")
+
+	captureDecisions = monitoring.NewSum(
+		"istio_cni_capture_decisions_total",
+		"Total number of traffic capture decisions of the CNI plugin, by decision and reason.",
+	)
+)
+
+// ReportCaptureDecision sends the decision to the UDS server of the node agent. Failing to report it is
+// logged but does not fail the setup of the pod.
+func ReportCaptureDecision(udsAddress string, d CaptureDecision) {
+	if udsAddress == "" {
+		return
+	}
+	c := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", udsAddress)
+			},
+		},
+		Timeout: 100 * time.Millisecond,
+	}
+	body, err := json.Marshal(d)
+	if err != nil {
+		log.Warnf("failed to marshal capture decision: %v", err)
+		return
+	}
+	resp, err := c.Post("http://unix"+constants.UDSCaptureDecisionPath, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Warnf("failed to report capture decision to the node agent: %v", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+func (l *UDSLogger) handleCaptureDecision(w http.ResponseWriter, req *http.Request) {
+	if req.Body == nil {
+		return
+	}
+	defer req.Body.Close()
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		log.Errorf("Failed to read capture decision from cni plugin: %v", err)
+		return
+	}
+	var d CaptureDecision
+	if err := json.Unmarshal(data, &d); err != nil {
+		log.Errorf("Failed to unmarshal capture decision from cni plugin: %v", err)
+		return
+	}
+	captureDecisions.With(decisionLabel.Value(d.Decision), reasonLabel.Value(d.Reason)).Increment()
+	for _, w := range d.Warnings {
+		log.Warnf("traffic capture of pod %s: %s", d.Pod, w)
+	}
+}
@@ -44,6 +44,7 @@ func NewUDSLogger() *UDSLogger {
 	l := &UDSLogger{}
 	mux := http.NewServeMux()
 	mux.HandleFunc(constants.UDSLogPath, l.handleLog)
+	mux.HandleFunc(constants.UDSCaptureDecisionPath, l.handleCaptureDecision)
 	loggingServer := &http.Server{
 		Handler: mux,
 	}
@@ -23,6 +23,7 @@ import (
 
 	"istio.io/istio/cni/pkg/constants"
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/monitoring/monitortest"
 )
 
 func TestUDSLog(t *testing.T) {
@@ -73,3 +74,20 @@ func TestUDSLog(t *testing.T) {
 		}
 	}
 }
+
+func TestUDSCaptureDecision(t *testing.T) {
+	mt := monitortest.New(t)
+	udsSock := filepath.Join(t.TempDir(), "cni.sock")
+	logger := NewUDSLogger()
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := logger.StartUDSLogServer(udsSock, stop); err != nil {
+		t.Fatal(err)
+	}
+
+	ReportCaptureDecision(udsSock, CaptureDecision{Decision: DecisionFailedOpen, Reason: ReasonLatencyBudget, Attempts: 2})
+	mt.Assert(captureDecisions.Name(), map[string]string{
+		"decision": DecisionFailedOpen,
+		"reason":   ReasonLatencyBudget,
+	}, monitortest.Exactly(1))
+}
@@ -0,0 +1,136 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cnilog "istio.io/istio/cni/pkg/log"
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+)
+
+// Failure policies of the traffic capture.
+const (
+	// FailClosed fails the CNI ADD of pods whose traffic capture can not be set up, so that no pod runs
+	// without capture. The container runtime retries creating the pod sandbox.
+	FailClosed = "fail-closed"
+	// FailOpen starts pods whose traffic capture can not be set up without capture, favoring their
+	// availability. The pods are annotated with CaptureFailedAnnotation.
+	FailOpen = "fail-open"
+)
+
+// CaptureFailedAnnotation is set on the pods started without traffic capture by the fail-open policy, with
+// the reason the capture could not be set up.
+const CaptureFailedAnnotation = "cni.istio.io/capture-failed"
+
+// CapturePolicy decides what happens to a pod whose traffic capture can not be set up, either because
+// programming its rules keeps failing or because it takes longer than the latency budget.
+type CapturePolicy struct {
+	// FailurePolicy is fail-closed, the default, or fail-open.
+	FailurePolicy string `json:"failure_policy"`
+	// LatencyBudget bounds the time spent programming the rules of a pod, as a duration such as "2s".
+	// It is not bounded if empty.
+	LatencyBudget string `json:"latency_budget"`
+	// MaxAttempts is the number of times programming the rules is attempted within the budget, 1 if unset.
+	MaxAttempts int `json:"max_attempts"`
+}
+
+// captureOutcome is the outcome of programming the rules of a pod under the capture policy.
+type captureOutcome struct {
+	decision string
+	reason   string
+	attempts int
+	err      error
+	// result is what the successful attempt programmed.
+	result *capture.Result
+}
+
+func (p CapturePolicy) validate() (time.Duration, error) {
+	if p.FailurePolicy != "" && p.FailurePolicy != FailClosed && p.FailurePolicy != FailOpen {
+		return 0, fmt.Errorf("invalid capture failure policy %q, expected %s or %s", p.FailurePolicy, FailClosed, FailOpen)
+	}
+	if p.MaxAttempts < 0 {
+		return 0, fmt.Errorf("invalid capture max attempts %d", p.MaxAttempts)
+	}
+	if p.LatencyBudget == "" {
+		return 0, nil
+	}
+	budget, err := time.ParseDuration(p.LatencyBudget)
+	if err != nil || budget <= 0 {
+		return 0, fmt.Errorf("invalid capture latency budget %q", p.LatencyBudget)
+	}
+	return budget, nil
+}
+
+// program programs the rules of the pod under the policy. The attempts are made until one succeeds, they are
+// exhausted or the latency budget is exceeded.
+func (p CapturePolicy) program(ctx context.Context, rulesMgr InterceptRuleMgr, podName, netns string, redirect *Redirect) captureOutcome {
+	budget, err := p.validate()
+	if err != nil {
+		return captureOutcome{decision: cnilog.DecisionFailedClosed, reason: cnilog.ReasonError, err: err}
+	}
+	maxAttempts := p.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = 1
+	}
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	type attempt struct {
+		n      int
+		result *capture.Result
+		err    error
+	}
+	// Programming the rules can not be interrupted, so it runs aside and is abandoned past the budget.
+	done := make(chan attempt, 1)
+	go func() {
+		var err error
+		for n := 1; n <= maxAttempts; n++ {
+			var result *capture.Result
+			if result, err = rulesMgr.Program(ctx, podName, netns, redirect); err == nil || ctx.Err() != nil {
+				done <- attempt{n, result, err}
+				return
+			}
+			log.Warnf("attempt %d of %d to program the traffic capture failed: %v", n, maxAttempts, err)
+		}
+		done <- attempt{maxAttempts, nil, err}
+	}()
+
+	var outcome captureOutcome
+	select {
+	case a := <-done:
+		if a.err == nil {
+			return captureOutcome{decision: cnilog.DecisionCaptured, reason: cnilog.ReasonSuccess, attempts: a.n, result: a.result}
+		}
+		outcome = captureOutcome{reason: cnilog.ReasonError, attempts: a.n, err: a.err}
+	case <-ctx.Done():
+		outcome = captureOutcome{
+			reason: cnilog.ReasonLatencyBudget,
+			err:    fmt.Errorf("programming the traffic capture exceeded the latency budget of %v", budget),
+		}
+	}
+	if p.FailurePolicy == FailOpen {
+		outcome.decision = cnilog.DecisionFailedOpen
+	} else {
+		outcome.decision = cnilog.DecisionFailedClosed
+	}
+	return outcome
+}
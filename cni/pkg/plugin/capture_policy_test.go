@@ -0,0 +1,150 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	cnilog "istio.io/istio/cni/pkg/log"
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+)
+
+// flakyInterceptRuleMgr fails the first failures attempts, each taking delay.
+type flakyInterceptRuleMgr struct {
+	failures int
+	delay    time.Duration
+	attempts int
+}
+
+func (f *flakyInterceptRuleMgr) Program(ctx context.Context, podName, netns string, redirect *Redirect) (*capture.Result, error) {
+	f.attempts++
+	time.Sleep(f.delay)
+	if f.attempts <= f.failures {
+		return nil, fmt.Errorf("attempt %d failed", f.attempts)
+	}
+	return &capture.Result{RulesV4: 10, Families: []string{capture.FamilyIPv4}}, nil
+}
+
+func TestCapturePolicyProgram(t *testing.T) {
+	cases := []struct {
+		name         string
+		policy       CapturePolicy
+		mgr          *flakyInterceptRuleMgr
+		wantDecision string
+		wantReason   string
+		wantAttempts int
+	}{
+		{
+			name:         "captured",
+			mgr:          &flakyInterceptRuleMgr{},
+			wantDecision: cnilog.DecisionCaptured,
+			wantReason:   cnilog.ReasonSuccess,
+			wantAttempts: 1,
+		},
+		{
+			name:         "fails closed by default",
+			mgr:          &flakyInterceptRuleMgr{failures: 1},
+			wantDecision: cnilog.DecisionFailedClosed,
+			wantReason:   cnilog.ReasonError,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retried",
+			policy:       CapturePolicy{MaxAttempts: 3},
+			mgr:          &flakyInterceptRuleMgr{failures: 2},
+			wantDecision: cnilog.DecisionCaptured,
+			wantReason:   cnilog.ReasonSuccess,
+			wantAttempts: 3,
+		},
+		{
+			name:         "attempts exhausted fail open",
+			policy:       CapturePolicy{FailurePolicy: FailOpen, MaxAttempts: 2},
+			mgr:          &flakyInterceptRuleMgr{failures: 2},
+			wantDecision: cnilog.DecisionFailedOpen,
+			wantReason:   cnilog.ReasonError,
+			wantAttempts: 2,
+		},
+		{
+			name:         "latency budget exceeded",
+			policy:       CapturePolicy{FailurePolicy: FailOpen, LatencyBudget: "10ms"},
+			mgr:          &flakyInterceptRuleMgr{delay: time.Second},
+			wantDecision: cnilog.DecisionFailedOpen,
+			wantReason:   cnilog.ReasonLatencyBudget,
+		},
+		{
+			name:         "invalid policy fails closed",
+			policy:       CapturePolicy{FailurePolicy: FailOpen, LatencyBudget: "soon"},
+			mgr:          &flakyInterceptRuleMgr{},
+			wantDecision: cnilog.DecisionFailedClosed,
+			wantReason:   cnilog.ReasonError,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.program(context.Background(), tt.mgr, "pod", "/tmp", &Redirect{})
+			if got.decision != tt.wantDecision || got.reason != tt.wantReason || got.attempts != tt.wantAttempts {
+				t.Fatalf("got decision %q reason %q after %d attempts (%v), want %q %q after %d attempts",
+					got.decision, got.reason, got.attempts, got.err, tt.wantDecision, tt.wantReason, tt.wantAttempts)
+			}
+			if (got.err == nil) != (tt.wantDecision == cnilog.DecisionCaptured) {
+				t.Fatalf("unexpected error %v", got.err)
+			}
+			if (got.result != nil) != (tt.wantDecision == cnilog.DecisionCaptured) {
+				t.Fatalf("unexpected result %v", got.result)
+			}
+		})
+	}
+}
+
+func TestCmdAddCaptureFailurePolicy(t *testing.T) {
+	defer resetGlobalTestVariables()
+	defer func() { recordCaptureFailure = recordK8sCaptureFailure }()
+	testContainers = sets.New("mockContainer", "istio-proxy")
+	InterceptRuleMgrTypes["failing"] = func() InterceptRuleMgr { return &flakyInterceptRuleMgr{failures: 10} }
+	defer delete(InterceptRuleMgrTypes, "failing")
+
+	var recorded []captureOutcome
+	recordCaptureFailure = func(_ context.Context, _ *kubernetes.Clientset, _, _ string, outcome captureOutcome) error {
+		recorded = append(recorded, outcome)
+		return nil
+	}
+	newKubeClient = mocknewK8sClient
+	getKubePodInfo = mockgetK8sPodInfo
+	cniConf := fmt.Sprintf(conf, currentVersion, currentVersion, ifname, sandboxDirectory, "failing")
+
+	if err := CmdAdd(testSetArgs(cniConf)); err == nil {
+		t.Fatal("expected the CNI ADD to fail closed")
+	}
+	failOpen := strings.Replace(cniConf, `"log_level": "debug",`,
+		`"log_level": "debug", "capture_policy": {"failure_policy": "fail-open", "max_attempts": 2},`, 1)
+	testCmdAddWithStdinData(t, failOpen)
+
+	if len(recorded) != 2 {
+		t.Fatalf("expected 2 recorded failures, got %d", len(recorded))
+	}
+	if recorded[0].decision != cnilog.DecisionFailedClosed || recorded[1].decision != cnilog.DecisionFailedOpen {
+		t.Fatalf("unexpected decisions %q and %q", recorded[0].decision, recorded[1].decision)
+	}
+	if recorded[1].attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", recorded[1].attempts)
+	}
+}
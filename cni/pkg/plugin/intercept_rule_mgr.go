@@ -14,6 +14,15 @@
 
 package plugin
 
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"istio.io/istio/cni/pkg/iptableshelper"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+)
+
 const (
 	defInterceptRuleMgrType = "iptables"
 )
@@ -21,13 +30,16 @@ const (
 // InterceptRuleMgr configures networking tables (e.g. iptables or nftables) for
 // redirecting traffic to an Istio proxy.
 type InterceptRuleMgr interface {
-	Program(podName, netns string, redirect *Redirect) error
+	// Program configures the redirection of the pod and returns what was programmed, for the logs of the plugin.
+	// Its steps are traced as children of the context.
+	Program(ctx context.Context, podName, netns string, redirect *Redirect) (*capture.Result, error)
 }
 
 type InterceptRuleMgrCtor func() InterceptRuleMgr
 
 var InterceptRuleMgrTypes = map[string]InterceptRuleMgrCtor{
 	"iptables": IptablesInterceptRuleMgrCtor,
+	"helper":   HelperInterceptRuleMgrCtor,
 }
 
 // Constructor factory for known types of InterceptRuleMgr's
@@ -39,3 +51,26 @@ func GetInterceptRuleMgrCtor(interceptType string) InterceptRuleMgrCtor {
 func IptablesInterceptRuleMgrCtor() InterceptRuleMgr {
 	return newIPTables()
 }
+
+// helperInterceptRuleMgr programs iptables with istio-iptables-helper, so that the plugin itself does not need
+// CAP_NET_ADMIN.
+type helperInterceptRuleMgr struct {
+	path string
+}
+
+// Constructor for the InterceptRuleMgr running the helper installed next to the plugin
+func HelperInterceptRuleMgrCtor() InterceptRuleMgr {
+	dir := ""
+	if exe, err := os.Executable(); err == nil {
+		dir = filepath.Dir(exe)
+	}
+	return &helperInterceptRuleMgr{path: filepath.Join(dir, iptableshelper.BinaryName)}
+}
+
+func (h *helperInterceptRuleMgr) Program(ctx context.Context, podName, netns string, rdrct *Redirect) (*capture.Result, error) {
+	return iptableshelper.Run(ctx, h.path, iptableshelper.Request{
+		Pod:    podName,
+		Netns:  netns,
+		Config: redirectConfig(netns, rdrct),
+	})
+}
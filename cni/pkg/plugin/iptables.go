@@ -16,8 +16,50 @@
 // parses prevResult according to the cniVersion
 package plugin
 
+import (
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
 type iptables struct{}
 
 func newIPTables() InterceptRuleMgr {
 	return &iptables{}
 }
+
+// redirectConfig returns the configuration of the rules redirecting the traffic of the pod in netns.
+func redirectConfig(netns string, rdrct *Redirect) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.CNIMode = true
+	cfg.NetworkNamespace = netns
+	cfg.ProxyPort = rdrct.targetPort
+	cfg.ProxyUID = rdrct.noRedirectUID
+	cfg.ProxyGID = rdrct.noRedirectGID
+	cfg.InboundInterceptionMode = rdrct.redirectMode
+	cfg.OutboundIPRangesInclude = rdrct.includeIPCidrs
+	cfg.InboundPortsExclude = rdrct.excludeInboundPorts
+	cfg.InboundPortsInclude = rdrct.includeInboundPorts
+	cfg.ExcludeInterfaces = rdrct.excludeInterfaces
+	cfg.OutboundPortsExclude = rdrct.excludeOutboundPorts
+	cfg.OutboundPortsInclude = rdrct.includeOutboundPorts
+	cfg.OutboundIPRangesExclude = rdrct.excludeIPCidrs
+	cfg.KubeVirtInterfaces = rdrct.kubevirtInterfaces
+	cfg.CapturePreset = rdrct.capturePreset
+	cfg.OutboundCapturePercent = rdrct.capturePercent
+	cfg.OutboundUDPPortsInclude = rdrct.outboundUDPPorts
+	cfg.ProxyUDPPort = rdrct.proxyUDPPort
+	cfg.DryRun = dependencies.DryRunFilePath.Get() != ""
+	cfg.RedirectDNS = rdrct.dnsRedirect
+	cfg.CaptureAllDNS = rdrct.dnsRedirect
+	cfg.DropInvalid = rdrct.invalidDrop
+	cfg.DualStack = rdrct.dualStack
+	cfg.ExemptKubeletProbes = rdrct.exemptKubeletProbes
+	for key, s := range rdrct.sources {
+		cfg.SetSource(key, s.Source, s.Name)
+		for _, a := range s.Additions {
+			cfg.AddSourceValues(key, a.By, a.Values)
+		}
+	}
+	cfg.FillConfigFromEnvironment()
+	return cfg
+}
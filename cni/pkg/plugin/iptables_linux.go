@@ -17,13 +17,14 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/containernetworking/plugins/pkg/ns"
 
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
 	"istio.io/istio/tools/istio-iptables/pkg/cmd"
-	"istio.io/istio/tools/istio-iptables/pkg/config"
 	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
 )
 
@@ -32,39 +33,31 @@ var getNs = ns.GetNS
 
 // Program defines a method which programs iptables based on the parameters
 // provided in Redirect.
-func (ipt *iptables) Program(podName, netns string, rdrct *Redirect) error {
-	cfg := config.DefaultConfig()
-	cfg.CNIMode = true
-	cfg.NetworkNamespace = netns
-	cfg.ProxyPort = rdrct.targetPort
-	cfg.ProxyUID = rdrct.noRedirectUID
-	cfg.ProxyGID = rdrct.noRedirectGID
-	cfg.InboundInterceptionMode = rdrct.redirectMode
-	cfg.OutboundIPRangesInclude = rdrct.includeIPCidrs
-	cfg.InboundPortsExclude = rdrct.excludeInboundPorts
-	cfg.InboundPortsInclude = rdrct.includeInboundPorts
-	cfg.ExcludeInterfaces = rdrct.excludeInterfaces
-	cfg.OutboundPortsExclude = rdrct.excludeOutboundPorts
-	cfg.OutboundPortsInclude = rdrct.includeOutboundPorts
-	cfg.OutboundIPRangesExclude = rdrct.excludeIPCidrs
-	cfg.KubeVirtInterfaces = rdrct.kubevirtInterfaces
-	cfg.DryRun = dependencies.DryRunFilePath.Get() != ""
-	cfg.RedirectDNS = rdrct.dnsRedirect
-	cfg.CaptureAllDNS = rdrct.dnsRedirect
-	cfg.DropInvalid = rdrct.invalidDrop
-	cfg.DualStack = rdrct.dualStack
-	cfg.FillConfigFromEnvironment()
+func (ipt *iptables) Program(ctx context.Context, podName, netns string, rdrct *Redirect) (*capture.Result, error) {
+	cfg := redirectConfig(netns, rdrct)
 
 	netNs, err := getNs(netns)
 	if err != nil {
 		err = fmt.Errorf("failed to open netns %q: %s", netns, err)
-		return err
+		if hint := dependencies.SELinuxHint(dependencies.DeniedOperation{
+			Description: "open the network namespace of the pod",
+			Path:        netns,
+			Class:       "file",
+			Permission:  "read",
+		}, err); hint != "" {
+			log.Warn(hint)
+		}
+		return nil, err
 	}
 	defer netNs.Close()
 
-	return netNs.Do(func(_ ns.NetNS) error {
+	var result *capture.Result
+	err = netNs.Do(func(_ ns.NetNS) error {
 		log.Infof("============= Start iptables configuration for %v =============", podName)
 		defer log.Infof("============= End iptables configuration for %v =============", podName)
-		return cmd.ProgramIptables(cfg)
+		var err error
+		result, err = cmd.ProgramIptables(ctx, cfg)
+		return err
 	})
+	return result, err
 }
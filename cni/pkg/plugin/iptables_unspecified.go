@@ -19,13 +19,18 @@
 // parses prevResult according to the cniVersion
 package plugin
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+)
 
 // ErrNotImplemented is returned when a requested feature is not implemented.
 var ErrNotImplemented = errors.New("not implemented")
 
 // Program defines a method which programs iptables based on the parameters
 // provided in Redirect.
-func (ipt *iptables) Program(podName, netns string, rdrct *Redirect) error {
-	return ErrNotImplemented
+func (ipt *iptables) Program(ctx context.Context, podName, netns string, rdrct *Redirect) (*capture.Result, error) {
+	return nil, ErrNotImplemented
 }
@@ -16,13 +16,16 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
+	cnilog "istio.io/istio/cni/pkg/log"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/log"
 	"istio.io/istio/pkg/util/sets"
@@ -34,6 +37,9 @@ var newKubeClient = newK8sClient
 // getKubePodInfo is a unit test override variable for interface create.
 var getKubePodInfo = getK8sPodInfo
 
+// recordCaptureFailure is a unit test override variable for interface create.
+var recordCaptureFailure = recordK8sCaptureFailure
+
 type PodInfo struct {
 	Containers        sets.String
 	Labels            map[string]string
@@ -74,6 +80,59 @@ func getK8sPodInfo(client *kubernetes.Clientset, podName, podNamespace string) (
 	return pi, nil
 }
 
+// recordK8sCaptureFailure records an Event on a pod whose traffic capture could not be set up. Pods started
+// without capture are also annotated, as they would otherwise look like any other pod of the mesh.
+func recordK8sCaptureFailure(ctx context.Context, client *kubernetes.Clientset, podName, podNamespace string,
+	outcome captureOutcome,
+) error {
+	message := fmt.Sprintf("Traffic capture could not be set up (%s), the pod is %s: %v", outcome.reason, outcome.decision, outcome.err)
+	now := metav1.Now()
+	event := &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: podName + ".",
+			Namespace:    podNamespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			Kind:       "Pod",
+			APIVersion: "v1",
+			Name:       podName,
+			Namespace:  podNamespace,
+		},
+		Reason:              "CaptureFailed",
+		Message:             message,
+		Type:                v1.EventTypeWarning,
+		Source:              v1.EventSource{Component: "istio-cni"},
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+		ReportingController: "istio.io/cni",
+	}
+	eventCtx, cancel := context.WithTimeout(ctx, kubeRequestTimeout)
+	defer cancel()
+	if _, err := client.CoreV1().Events(podNamespace).Create(eventCtx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to record event: %v", err)
+	}
+	if outcome.decision != cnilog.DecisionFailedOpen {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{CaptureFailedAnnotation: outcome.reason},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// The status subresource allows updating the metadata of pods, with a narrower permission than the pod itself.
+	patchCtx, cancel := context.WithTimeout(ctx, kubeRequestTimeout)
+	defer cancel()
+	if _, err := client.CoreV1().Pods(podNamespace).Patch(patchCtx, podName, types.MergePatchType, patch,
+		metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("failed to annotate pod: %v", err)
+	}
+	return nil
+}
+
 func ExtractPodInfo(pod *v1.Pod) *PodInfo {
 	pi := &PodInfo{
 		Containers:        sets.New[string](),
@@ -17,9 +17,11 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"runtime/debug"
 	"strconv"
 	"time"
@@ -33,7 +35,10 @@ import (
 	"istio.io/api/label"
 	"istio.io/istio/cni/pkg/ambient"
 	"istio.io/istio/cni/pkg/constants"
+	"istio.io/istio/cni/pkg/egress"
+	cnilog "istio.io/istio/cni/pkg/log"
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/tracing"
 	"istio.io/istio/pkg/util/sets"
 )
 
@@ -43,6 +48,9 @@ var (
 
 	podRetrievalMaxRetries = 30
 	podRetrievalInterval   = 1 * time.Second
+	// kubeRequestTimeout bounds the requests recording a capture failure, so that a slow API server does not
+	// hold up the setup of the network of the pod.
+	kubeRequestTimeout = 5 * time.Second
 )
 
 const (
@@ -83,6 +91,11 @@ type Config struct {
 	LogUDSAddress  string     `json:"log_uds_address"`
 	AmbientEnabled bool       `json:"ambient_enabled"`
 	Kubernetes     Kubernetes `json:"kubernetes"`
+	// CapturePolicy decides what happens to pods whose traffic capture can not be set up.
+	CapturePolicy CapturePolicy `json:"capture_policy"`
+	// ExemptKubeletProbes skips the inbound capture of the connections the node agent translated to the kubelet
+	// probe source addresses, so that plain HTTP probes reach the application.
+	ExemptKubeletProbes bool `json:"exempt_kubelet_probes"`
 }
 
 // K8sArgs is the valid CNI_ARGS used for Kubernetes
@@ -172,13 +185,20 @@ func CmdAdd(args *skel.CmdArgs) (err error) {
 		log.Errorf("istio-cni cmdAdd failed to parse config %v %v", string(args.StdinData), err)
 		return err
 	}
-	if err := doRun(args, conf); err != nil {
+	// Tracing is only exported when configured through the standard OTEL_* environment variables.
+	ctx, shutdown, terr := tracing.InitializeFullBinary("istio-cni.CmdAdd")
+	if terr != nil {
+		log.Warnf("failed to initialize tracing: %v", terr)
+		ctx, shutdown = context.Background(), func() {}
+	}
+	defer shutdown()
+	if err := doRun(ctx, args, conf); err != nil {
 		return err
 	}
 	return pluginResponse(conf)
 }
 
-func doRun(args *skel.CmdArgs, conf *Config) error {
+func doRun(ctx context.Context, args *skel.CmdArgs, conf *Config) error {
 	setupLogging(conf)
 
 	var loggedPrevResult any
@@ -314,6 +334,19 @@ func doRun(args *skel.CmdArgs, conf *Config) error {
 		log.Errorf("redirect failed due to bad params: %v", err)
 		return err
 	}
+	redirect.exemptKubeletProbes = conf.ExemptKubeletProbes
+
+	// The node agent writes the egress exclusions next to the kubeconfig of the plugin.
+	if conf.Kubernetes.Kubeconfig != "" {
+		exclusionsFile := filepath.Join(filepath.Dir(conf.Kubernetes.Kubeconfig), egress.ExclusionsFilename)
+		exclusions, err := egress.Read(exclusionsFile)
+		if err != nil {
+			log.Warnf("failed to read egress exclusions from %s, the egress hosts of Sidecars are captured: %v", exclusionsFile, err)
+		} else if cidrs := exclusions.For(podNamespace, pi.Labels); len(cidrs) > 0 {
+			log.Infof("excluding the egress hosts of the Sidecar from capture: %v", cidrs)
+			redirect.excludeCIDRs(cidrs)
+		}
+	}
 
 	// Get the constructor for the configured type of InterceptRuleMgr
 	interceptMgrCtor := GetInterceptRuleMgrCtor(interceptRuleMgrType)
@@ -323,11 +356,32 @@ func doRun(args *skel.CmdArgs, conf *Config) error {
 	}
 
 	rulesMgr := interceptMgrCtor()
-	if err := rulesMgr.Program(podName, args.Netns, redirect); err != nil {
-		return err
+	outcome := conf.CapturePolicy.program(ctx, rulesMgr, podName, args.Netns, redirect)
+	decision := cnilog.CaptureDecision{
+		Decision: outcome.decision,
+		Reason:   outcome.reason,
+		Attempts: outcome.attempts,
+		Pod:      podNamespace + "/" + podName,
 	}
-
-	return nil
+	if r := outcome.result; r != nil {
+		log.Infof("programmed the traffic capture of pod %s/%s: %v", podNamespace, podName, r)
+		decision.Rules = r.Rules()
+		decision.Families = r.Families
+		decision.Warnings = r.Warnings
+		decision.DurationSeconds = r.Duration.Seconds()
+	}
+	cnilog.ReportCaptureDecision(conf.LogUDSAddress, decision)
+	if outcome.decision == cnilog.DecisionCaptured {
+		return nil
+	}
+	if err := recordCaptureFailure(ctx, client, podName, podNamespace, outcome); err != nil {
+		log.Warnf("failed to record the capture failure on the pod: %v", err)
+	}
+	if outcome.decision == cnilog.DecisionFailedOpen {
+		log.Errorf("starting the pod without traffic capture as the capture failure policy is %s: %v", FailOpen, outcome.err)
+		return nil
+	}
+	return outcome.err
 }
 
 func setupLogging(conf *Config) {
@@ -15,8 +15,10 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -28,7 +30,11 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"istio.io/api/label"
+	"istio.io/istio/cni/pkg/egress"
 	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+	"istio.io/istio/tools/istio-iptables/pkg/cmd"
+	"istio.io/istio/tools/istio-iptables/pkg/config"
 )
 
 var (
@@ -103,10 +109,10 @@ func init() {
 	testAnnotations[sidecarStatusKey] = "true"
 }
 
-func (mrdir *mockInterceptRuleMgr) Program(podName, netns string, redirect *Redirect) error {
+func (mrdir *mockInterceptRuleMgr) Program(ctx context.Context, podName, netns string, redirect *Redirect) (*capture.Result, error) {
 	nsenterFuncCalled = true
 	mrdir.lastRedirect = append(mrdir.lastRedirect, redirect)
-	return nil
+	return &capture.Result{}, nil
 }
 
 func NewMockInterceptRuleMgr() InterceptRuleMgr {
@@ -492,6 +498,32 @@ func TestCmdAddEnableDualStack(t *testing.T) {
 	}
 }
 
+func TestCmdAddEgressExclusions(t *testing.T) {
+	defer resetGlobalTestVariables()
+	testContainers = sets.New("mockContainer", "istio-proxy")
+	testLabels["app"] = "ratings"
+	testAnnotations[excludeIPCidrsKey] = "10.0.0.0/8"
+
+	dir := t.TempDir()
+	exclusions := &egress.Exclusions{Namespaces: map[string][]egress.Exclusion{
+		"istio-system": {{Sidecar: "bypass", CIDRs: []string{"192.168.1.10/32", "2001:db8::/64"}}},
+	}}
+	if err := exclusions.Write(filepath.Join(dir, egress.ExclusionsFilename)); err != nil {
+		t.Fatal(err)
+	}
+	cniConf := fmt.Sprintf(conf, currentVersion, currentVersion, ifname, sandboxDirectory, "mock")
+	testCmdAddWithStdinData(t, strings.Replace(cniConf, "testK8sConfig", filepath.Join(dir, "ZZZ-istio-cni-kubeconfig"), 1))
+
+	mockIntercept, ok := GetInterceptRuleMgrCtor("mock")().(*mockInterceptRuleMgr)
+	if !ok {
+		t.Fatalf("expect using mockInterceptRuleMgr, actual %v", InterceptRuleMgrTypes["mock"]())
+	}
+	r := mockIntercept.lastRedirect[len(mockIntercept.lastRedirect)-1]
+	if want := "10.0.0.0/8,192.168.1.10/32,2001:db8::/64"; r.excludeIPCidrs != want {
+		t.Fatalf("expect excludeIPCidrs %q, actual %q", want, r.excludeIPCidrs)
+	}
+}
+
 func MockInterceptRuleMgrCtor() InterceptRuleMgr {
 	return NewMockInterceptRuleMgr()
 }
@@ -547,3 +579,135 @@ func Test_dedupPorts(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRedirectWithCapturePreset(t *testing.T) {
+	redirect, err := NewRedirect(&PodInfo{Annotations: map[string]string{capturePresetKey: "outbound-only"}})
+	if err != nil {
+		t.Fatalf("expected the outbound-only capture preset to be valid: %v", err)
+	}
+	if redirect.capturePreset != "outbound-only" {
+		t.Fatalf("expected the capture preset outbound-only, got %q", redirect.capturePreset)
+	}
+
+	if _, err := NewRedirect(&PodInfo{Annotations: map[string]string{capturePresetKey: "outbound"}}); err == nil {
+		t.Fatalf("expected an unknown capture preset to be rejected")
+	}
+}
+
+func TestNewRedirectWithCapturePercent(t *testing.T) {
+	cases := []struct {
+		name    string
+		pi      *PodInfo
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", pi: &PodInfo{}, want: ""},
+		{name: "none", pi: &PodInfo{Annotations: map[string]string{outboundCapturePercentKey: "0"}}, want: "0"},
+		{name: "annotation", pi: &PodInfo{Annotations: map[string]string{outboundCapturePercentKey: "25"}}, want: "25"},
+		{name: "namespace", pi: &PodInfo{ProxyEnvironments: map[string]string{outboundCapturePercentEnv: "50"}}, want: "50"},
+		{
+			name: "annotation overrides namespace",
+			pi: &PodInfo{
+				Annotations:       map[string]string{outboundCapturePercentKey: "25"},
+				ProxyEnvironments: map[string]string{outboundCapturePercentEnv: "50"},
+			},
+			want: "25",
+		},
+		{name: "invalid namespace", pi: &PodInfo{ProxyEnvironments: map[string]string{outboundCapturePercentEnv: "150"}}, wantErr: true},
+		{name: "invalid annotation", pi: &PodInfo{Annotations: map[string]string{outboundCapturePercentKey: "150"}}, wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			redirect, err := NewRedirect(tt.pi)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an invalid percentage to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := redirectConfig("/var/run/netns/test", redirect).OutboundCapturePercent; got != tt.want {
+				t.Fatalf("outbound capture percent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRedirectWithOutboundUDPPorts(t *testing.T) {
+	cases := []struct {
+		name      string
+		pi        *PodInfo
+		wantPorts string
+		wantProxy string
+		wantErr   bool
+	}{
+		{name: "unset", pi: &PodInfo{ProxyEnvironments: map[string]string{cmd.ProxyUDPPortMetadata: "15009"}}},
+		{
+			name: "proxy with a UDP listener",
+			pi: &PodInfo{
+				Annotations:       map[string]string{includeOutboundUDPPortsKey: "5060,9000"},
+				ProxyEnvironments: map[string]string{cmd.ProxyUDPPortMetadata: "15009"},
+			},
+			wantPorts: "5060,9000",
+			wantProxy: "15009",
+		},
+		{name: "proxy without a UDP listener", pi: &PodInfo{Annotations: map[string]string{includeOutboundUDPPortsKey: "5060"}}},
+		{name: "invalid annotation", pi: &PodInfo{Annotations: map[string]string{includeOutboundUDPPortsKey: "sip"}}, wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			redirect, err := NewRedirect(tt.pi)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected invalid ports to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := redirectConfig("/var/run/netns/test", redirect)
+			if cfg.OutboundUDPPortsInclude != tt.wantPorts || cfg.ProxyUDPPort != tt.wantProxy {
+				t.Fatalf("outbound UDP ports = %q to %q, want %q to %q",
+					cfg.OutboundUDPPortsInclude, cfg.ProxyUDPPort, tt.wantPorts, tt.wantProxy)
+			}
+		})
+	}
+}
+
+func TestRedirectConfigSources(t *testing.T) {
+	redirect, err := NewRedirect(&PodInfo{
+		Annotations:       map[string]string{excludeInboundPortsKey: "8080,15021", capturePresetKey: "outbound-only"},
+		ProxyEnvironments: map[string]string{"ISTIO_META_DNS_CAPTURE": "true"},
+	})
+	if err != nil {
+		t.Fatalf("expected the annotations to be valid: %v", err)
+	}
+	redirect.excludeCIDRs([]string{"10.1.0.0/16"})
+	cfg := redirectConfig("/var/run/netns/test", redirect)
+
+	want := map[string]config.ValueSource{
+		config.KeyInboundPortsExclude: {
+			Source:    config.SourceAnnotation,
+			Name:      excludeInboundPortsKey,
+			Additions: []config.Addition{{Values: []string{"15020", "15090"}, By: "the CNI plugin"}},
+		},
+		config.KeyCapturePreset: {Source: config.SourceAnnotation, Name: capturePresetKey},
+		config.KeyOutboundIPRangesExclude: {
+			Source:    config.SourceDefault,
+			Additions: []config.Addition{{Values: []string{"10.1.0.0/16"}, By: "egress exclusions"}},
+		},
+		config.KeyRedirectDNS:   {Source: config.SourceEnv, Name: "ISTIO_META_DNS_CAPTURE"},
+		config.KeyCaptureAllDNS: {Source: config.SourceEnv, Name: "ISTIO_META_DNS_CAPTURE"},
+	}
+	for key, source := range want {
+		if got := cfg.SourceOf(key); !reflect.DeepEqual(got, source) {
+			t.Errorf("source of %s = %v, want %v", key, got, source)
+		}
+	}
+	if got := cfg.SourceOf(config.KeyInboundPortsInclude); got.Source != config.SourceDefault {
+		t.Errorf("source of %s = %v, want the default", config.KeyInboundPortsInclude, got)
+	}
+}
@@ -23,7 +23,9 @@ import (
 
 	"istio.io/api/annotation"
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/util/sets"
 	"istio.io/istio/tools/istio-iptables/pkg/cmd"
+	"istio.io/istio/tools/istio-iptables/pkg/config"
 )
 
 const (
@@ -41,6 +43,9 @@ const (
 	defaultIncludeInboundPorts   = "*"
 	defaultIncludeOutboundPorts  = ""
 	defaultExcludeInterfaces     = ""
+
+	// mandatoryExcludeInboundPorts are the ports of the sidecar always excluded from inbound redirection.
+	mandatoryExcludeInboundPorts = "15020,15021,15090"
 )
 
 var (
@@ -57,6 +62,20 @@ var (
 
 	kubevirtInterfacesKey = annotation.SidecarTrafficKubevirtInterfaces.Name
 
+	// capturePresetKey selects a capture preset of istio-iptables, such as outbound-only, instead of combining the
+	// include and exclude annotations. The DNS queries are only answered if the DNS proxy of the agent is enabled.
+	capturePresetKey = "traffic.sidecar.istio.io/capturePreset"
+
+	// outboundCapturePercentKey redirects only a percentage of the new outbound connections of the pod to Envoy, to
+	// move a workload into the mesh gradually, 0 redirecting none of them to roll it back. A percentage for a whole namespace is set with the
+	// ISTIO_OUTBOUND_CAPTURE_PERCENT environment variable of a ProxyConfig, which the annotation overrides.
+	outboundCapturePercentKey = "traffic.sidecar.istio.io/outboundCapturePercent"
+	outboundCapturePercentEnv = "ISTIO_OUTBOUND_CAPTURE_PERCENT"
+
+	// includeOutboundUDPPortsKey redirects the outbound UDP traffic of the pod to the listed ports to the UDP
+	// listener of the proxy. It is ignored unless the proxy advertises the port of such a listener in its metadata.
+	includeOutboundUDPPortsKey = "traffic.sidecar.istio.io/includeOutboundUDPPorts"
+
 	annotationRegistry = map[string]*annotationParam{
 		"inject":               {injectAnnotationKey, "", alwaysValidFunc},
 		"status":               {sidecarStatusKey, "", alwaysValidFunc},
@@ -70,6 +89,25 @@ var (
 		"includeOutboundPorts": {includeOutboundPortsKey, defaultIncludeOutboundPorts, validatePortListWithWildcard},
 		"kubevirtInterfaces":   {kubevirtInterfacesKey, defaultKubevirtInterfaces, alwaysValidFunc},
 		"excludeInterfaces":    {excludeInterfacesKey, defaultExcludeInterfaces, alwaysValidFunc},
+		"capturePreset":        {capturePresetKey, "", config.ValidateCapturePreset},
+		"capturePercent":       {outboundCapturePercentKey, "", config.ValidateOutboundCapturePercent},
+		"udpPorts":             {includeOutboundUDPPortsKey, "", validatePortList},
+	}
+
+	// annotationConfigKeys are the keys of the values of the istio-iptables config set from the annotations, by
+	// name in the annotation registry.
+	annotationConfigKeys = map[string]string{
+		"redirectMode":         config.KeyInboundInterceptionMode,
+		"includeIPCidrs":       config.KeyOutboundIPRangesInclude,
+		"excludeIPCidrs":       config.KeyOutboundIPRangesExclude,
+		"excludeInboundPorts":  config.KeyInboundPortsExclude,
+		"includeInboundPorts":  config.KeyInboundPortsInclude,
+		"excludeOutboundPorts": config.KeyOutboundPortsExclude,
+		"includeOutboundPorts": config.KeyOutboundPortsInclude,
+		"kubevirtInterfaces":   config.KeyKubeVirtInterfaces,
+		"excludeInterfaces":    config.KeyExcludeInterfaces,
+		"capturePreset":        config.KeyCapturePreset,
+		"capturePercent":       config.KeyOutboundCapturePercent,
 	}
 )
 
@@ -87,9 +125,52 @@ type Redirect struct {
 	includeOutboundPorts string
 	kubevirtInterfaces   string
 	excludeInterfaces    string
+	capturePreset        string
+	capturePercent       string
+	outboundUDPPorts     string
+	proxyUDPPort         string
 	dnsRedirect          bool
 	dualStack            bool
 	invalidDrop          bool
+	exemptKubeletProbes  bool
+	// sources are where the values of the istio-iptables config were set, by key.
+	sources map[string]config.ValueSource
+}
+
+// setSource records where the value of the key of the istio-iptables config was set.
+func (rd *Redirect) setSource(key string, source config.Source, name string) {
+	if rd.sources == nil {
+		rd.sources = map[string]config.ValueSource{}
+	}
+	rd.sources[key] = config.ValueSource{Source: source, Name: name}
+}
+
+// addSourceValues records the values added to the list of the key of the istio-iptables config.
+func (rd *Redirect) addSourceValues(key, by string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	if rd.sources == nil {
+		rd.sources = map[string]config.ValueSource{}
+	}
+	s, f := rd.sources[key]
+	if !f {
+		s = config.ValueSource{Source: config.SourceDefault}
+	}
+	s.Additions = append(s.Additions, config.Addition{Values: values, By: by})
+	rd.sources[key] = s
+}
+
+// excludeCIDRs excludes the CIDRs from redirection, in addition to those excluded by annotation.
+func (rd *Redirect) excludeCIDRs(cidrs []string) {
+	if len(cidrs) == 0 {
+		return
+	}
+	rd.addSourceValues(config.KeyOutboundIPRangesExclude, "egress exclusions", cidrs)
+	if rd.excludeIPCidrs != "" {
+		cidrs = append([]string{rd.excludeIPCidrs}, cidrs...)
+	}
+	rd.excludeIPCidrs = strings.Join(cidrs, ",")
 }
 
 type annotationValidationFunc func(value string) error
@@ -263,10 +344,17 @@ func NewRedirect(pi *PodInfo) (*Redirect, error) {
 	// Add 15090 to sync with non-cni injection template
 	// TODO: Revert below once https://github.com/istio/istio/pull/23037 or its follow up is merged.
 	redir.excludeInboundPorts = strings.TrimSpace(redir.excludeInboundPorts)
+	excluded := sets.New(splitPorts(redir.excludeInboundPorts)...)
+	var mandatory []string
+	for _, port := range splitPorts(mandatoryExcludeInboundPorts) {
+		if !excluded.Contains(port) {
+			mandatory = append(mandatory, port)
+		}
+	}
 	if len(redir.excludeInboundPorts) > 0 && redir.excludeInboundPorts[len(redir.excludeInboundPorts)-1] != ',' {
 		redir.excludeInboundPorts += ","
 	}
-	redir.excludeInboundPorts += "15020,15021,15090"
+	redir.excludeInboundPorts += mandatoryExcludeInboundPorts
 	redir.excludeInboundPorts = strings.Join(dedupPorts(splitPorts(redir.excludeInboundPorts)), ",")
 	isFound, redir.excludeInterfaces, valErr = getAnnotationOrDefault("excludeInterfaces", pi.Annotations)
 	if valErr != nil {
@@ -278,11 +366,31 @@ func NewRedirect(pi *PodInfo) (*Redirect, error) {
 		return nil, fmt.Errorf("annotation value error for value %s; annotationFound = %t: %v",
 			"kubevirtInterfaces", isFound, valErr)
 	}
+	isFound, redir.capturePreset, valErr = getAnnotationOrDefault("capturePreset", pi.Annotations)
+	if valErr != nil {
+		return nil, fmt.Errorf("annotation value error for value %s; annotationFound = %t: %v",
+			"capturePreset", isFound, valErr)
+	}
+	isFound, redir.capturePercent, valErr = getAnnotationOrDefault("capturePercent", pi.Annotations)
+	if valErr != nil {
+		return nil, fmt.Errorf("annotation value error for value %s; annotationFound = %t: %v",
+			"capturePercent", isFound, valErr)
+	}
+	for name, key := range annotationConfigKeys {
+		a := annotationRegistry[name].key
+		if _, found := pi.Annotations[a]; found {
+			redir.setSource(key, config.SourceAnnotation, a)
+		}
+	}
+	redir.addSourceValues(config.KeyInboundPortsExclude, "the CNI plugin", mandatory)
 	if v, found := pi.ProxyEnvironments["ISTIO_META_DNS_CAPTURE"]; found {
 		// parse and set the bool value of dnsRedirect
 		redir.dnsRedirect, valErr = strconv.ParseBool(v)
 		if valErr != nil {
 			log.Warnf("cannot parse DNS capture environment variable %v", valErr)
+		} else {
+			redir.setSource(config.KeyRedirectDNS, config.SourceEnv, "ISTIO_META_DNS_CAPTURE")
+			redir.setSource(config.KeyCaptureAllDNS, config.SourceEnv, "ISTIO_META_DNS_CAPTURE")
 		}
 	}
 	if v, found := pi.ProxyEnvironments["ISTIO_DUAL_STACK"]; found {
@@ -290,6 +398,33 @@ func NewRedirect(pi *PodInfo) (*Redirect, error) {
 		redir.dualStack, valErr = strconv.ParseBool(v)
 		if valErr != nil {
 			log.Warnf("cannot parse dual stack environment variable %v", valErr)
+		} else {
+			redir.setSource(config.KeyDualStack, config.SourceEnv, "ISTIO_DUAL_STACK")
+		}
+	}
+	if v, found := pi.ProxyEnvironments[outboundCapturePercentEnv]; found && !isFound {
+		// the annotation of the pod overrides the percentage of its namespace
+		if valErr = config.ValidateOutboundCapturePercent(v); valErr != nil {
+			return nil, fmt.Errorf("environment variable value error for value %s: %v", outboundCapturePercentEnv, valErr)
+		}
+		redir.capturePercent = v
+		redir.setSource(config.KeyOutboundCapturePercent, config.SourceEnv, outboundCapturePercentEnv)
+	}
+	var udpPorts string
+	isFound, udpPorts, valErr = getAnnotationOrDefault("udpPorts", pi.Annotations)
+	if valErr != nil {
+		return nil, fmt.Errorf("annotation value error for value %s; annotationFound = %t: %v",
+			"udpPorts", isFound, valErr)
+	}
+	if isFound {
+		// UDP traffic redirected to a proxy without a UDP listener would be dropped
+		if v, found := pi.ProxyEnvironments[cmd.ProxyUDPPortMetadata]; found {
+			redir.outboundUDPPorts, redir.proxyUDPPort = udpPorts, v
+			redir.setSource(config.KeyOutboundUDPPortsInclude, config.SourceAnnotation, includeOutboundUDPPortsKey)
+			redir.setSource(config.KeyProxyUDPPort, config.SourceEnv, cmd.ProxyUDPPortMetadata)
+		} else {
+			log.Warnf("ignoring annotation %s, the proxy does not advertise a UDP listener in %s",
+				includeOutboundUDPPortsKey, cmd.ProxyUDPPortMetadata)
 		}
 	}
 	if v, found := pi.ProxyEnvironments[cmd.InvalidDropByIptables]; found {
@@ -297,6 +432,8 @@ func NewRedirect(pi *PodInfo) (*Redirect, error) {
 		redir.invalidDrop, valErr = strconv.ParseBool(v)
 		if valErr != nil {
 			log.Warnf("cannot parse invalid drop environment variable %v", valErr)
+		} else {
+			redir.setSource(config.KeyDropInvalid, config.SourceEnv, cmd.InvalidDropByIptables)
 		}
 	}
 	return redir, nil
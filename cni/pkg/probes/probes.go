@@ -0,0 +1,159 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package probes exempts the health probes of the kubelet from the inbound capture of pods, as an alternative
+// to the injector rewriting the probes to go through the agent. The node agent connmarks the connections the
+// host opens to the pods of the node and, since marks do not cross into the network namespace of the pod,
+// translates their source to the kubelet probe source addresses, which the capture rules of the pods let
+// through.
+//
+// Every connection of the host to a pod of its node is exempted, not only the probes of the kubelet: it is not
+// possible to tell the sockets of the kubelet apart from those of other host processes.
+package probes
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
+var probesLog = log.RegisterScope("probes", "CNI kubelet probe exemption")
+
+const (
+	// ConnMark marks the connections the host opens to the pods of the node. It does not overlap the marks of
+	// the sidecar or ambient captures.
+	ConnMark = "0x4000/0x4000"
+
+	// markChain connmarks new connections of the host to the pods of the node, from mangle OUTPUT.
+	markChain = "ISTIO_PROBE_MARK"
+	// snatChain translates the source of the marked connections, from nat POSTROUTING. It is placed first so
+	// that the masquerading of the network plugin does not translate them first.
+	snatChain = "ISTIO_PROBE_SNAT"
+)
+
+// Exemption programs the host rules exempting the connections of the host to the pods of the node.
+type Exemption struct {
+	deps dependencies.Dependencies
+	// podCIDRs are the pod CIDRs of the node, which the addresses of its pods are allocated from.
+	podCIDRs []netip.Prefix
+}
+
+// NewExemption returns the exemption for the pod CIDRs of the node. It fails if the node has none, as is the
+// case with network plugins which do not allocate the addresses of pods from the pod CIDRs of nodes.
+func NewExemption(ctx context.Context, client kubernetes.Interface, nodeName string, deps dependencies.Dependencies) (*Exemption, error) {
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+	cidrs := node.Spec.PodCIDRs
+	if len(cidrs) == 0 && node.Spec.PodCIDR != "" {
+		cidrs = []string{node.Spec.PodCIDR}
+	}
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("node %s has no pod CIDR, the connections of the host to its pods can not be told apart", nodeName)
+	}
+	e := &Exemption{deps: deps}
+	for _, c := range cidrs {
+		prefix, err := netip.ParsePrefix(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod CIDR %q of node %s: %v", c, nodeName, err)
+		}
+		e.podCIDRs = append(e.podCIDRs, prefix)
+	}
+	return e, nil
+}
+
+// Program replaces the rules of the exemption, so that it can run again when the node agent restarts.
+func (e *Exemption) Program() error {
+	e.Cleanup()
+	for _, args := range e.rules() {
+		cmd, args := args[0], args[1:]
+		if err := e.deps.Run(cmd, nil, args...); err != nil {
+			return fmt.Errorf("failed to program the kubelet probe exemption: %v", err)
+		}
+	}
+	probesLog.Infof("exempting the connections of the host to the pods of %v from capture", e.podCIDRs)
+	return nil
+}
+
+// Cleanup removes the rules of the exemption, ignoring those which do not exist.
+func (e *Exemption) Cleanup() {
+	for _, cmd := range e.commands() {
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.MANGLE, "-D", constants.OUTPUT, "-j", markChain)
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.MANGLE, "-F", markChain)
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.MANGLE, "-X", markChain)
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.NAT, "-D", constants.POSTROUTING, "-j", snatChain)
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.NAT, "-F", snatChain)
+		e.deps.RunQuietlyAndIgnore(cmd, nil, "-t", constants.NAT, "-X", snatChain)
+	}
+}
+
+// commands returns the xtables commands of the families of the pod CIDRs.
+func (e *Exemption) commands() []string {
+	var v4, v6 bool
+	for _, c := range e.podCIDRs {
+		if c.Addr().Is4() {
+			v4 = true
+		} else {
+			v6 = true
+		}
+	}
+	var cmds []string
+	if v4 {
+		cmds = append(cmds, constants.IPTABLES)
+	}
+	if v6 {
+		cmds = append(cmds, constants.IP6TABLES)
+	}
+	return cmds
+}
+
+// rules returns the commands programming the exemption, each starting with its xtables command.
+func (e *Exemption) rules() [][]string {
+	var rules [][]string
+	for _, cmd := range e.commands() {
+		rules = append(rules,
+			[]string{cmd, "-t", constants.MANGLE, "-N", markChain},
+			[]string{cmd, "-t", constants.MANGLE, "-A", constants.OUTPUT, "-j", markChain},
+			[]string{cmd, "-t", constants.NAT, "-N", snatChain},
+			[]string{cmd, "-t", constants.NAT, "-I", constants.POSTROUTING, "1", "-j", snatChain},
+		)
+	}
+	for _, c := range e.podCIDRs {
+		cmd, source := constants.IPTABLES, constants.KubeletProbeSourceV4
+		if c.Addr().Is6() {
+			cmd, source = constants.IP6TABLES, constants.KubeletProbeSourceV6
+		}
+		rules = append(rules,
+			// Only connections opened by a socket of the host, and not those forwarded by it.
+			[]string{
+				cmd, "-t", constants.MANGLE, "-A", markChain, "-p", constants.TCP, "-d", c.String(),
+				"-m", "owner", "--socket-exists", "-m", "conntrack", "--ctstate", "NEW",
+				"-j", "CONNMARK", "--set-xmark", ConnMark,
+			},
+			[]string{
+				cmd, "-t", constants.NAT, "-A", snatChain, "-p", constants.TCP, "-d", c.String(),
+				"-m", "connmark", "--mark", ConnMark, "-j", "SNAT", "--to-source", source,
+			},
+		)
+	}
+	return rules
+}
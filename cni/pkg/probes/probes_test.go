@@ -0,0 +1,95 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package probes
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// recordingDependencies records the commands run, quiet ones prefixed with "?".
+type recordingDependencies struct {
+	commands []string
+}
+
+func (r *recordingDependencies) Run(cmd string, _ io.ReadSeeker, args ...string) error {
+	r.commands = append(r.commands, cmd+" "+strings.Join(args, " "))
+	return nil
+}
+
+func (r *recordingDependencies) RunQuietlyAndIgnore(cmd string, _ io.ReadSeeker, args ...string) {
+	r.commands = append(r.commands, "? "+cmd+" "+strings.Join(args, " "))
+}
+
+func (r *recordingDependencies) RunWithOutput(cmd string, stdin io.ReadSeeker, args ...string) (string, error) {
+	return "", r.Run(cmd, stdin, args...)
+}
+
+func node(podCIDRs ...string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{PodCIDRs: podCIDRs},
+	}
+}
+
+func TestExemptionProgram(t *testing.T) {
+	deps := &recordingDependencies{}
+	client := fake.NewSimpleClientset(node("10.244.1.0/24", "fd00:10:244:1::/64"))
+	e, err := NewExemption(context.Background(), client, "node-1", deps)
+	assert.NoError(t, err)
+	assert.NoError(t, e.Program())
+
+	var programmed []string
+	for _, c := range deps.commands {
+		if !strings.HasPrefix(c, "?") {
+			programmed = append(programmed, c)
+		}
+	}
+	assert.Equal(t, programmed, []string{
+		"iptables -t mangle -N ISTIO_PROBE_MARK",
+		"iptables -t mangle -A OUTPUT -j ISTIO_PROBE_MARK",
+		"iptables -t nat -N ISTIO_PROBE_SNAT",
+		"iptables -t nat -I POSTROUTING 1 -j ISTIO_PROBE_SNAT",
+		"ip6tables -t mangle -N ISTIO_PROBE_MARK",
+		"ip6tables -t mangle -A OUTPUT -j ISTIO_PROBE_MARK",
+		"ip6tables -t nat -N ISTIO_PROBE_SNAT",
+		"ip6tables -t nat -I POSTROUTING 1 -j ISTIO_PROBE_SNAT",
+		"iptables -t mangle -A ISTIO_PROBE_MARK -p tcp -d 10.244.1.0/24 -m owner --socket-exists " +
+			"-m conntrack --ctstate NEW -j CONNMARK --set-xmark 0x4000/0x4000",
+		"iptables -t nat -A ISTIO_PROBE_SNAT -p tcp -d 10.244.1.0/24 -m connmark --mark 0x4000/0x4000 " +
+			"-j SNAT --to-source 169.254.7.127",
+		"ip6tables -t mangle -A ISTIO_PROBE_MARK -p tcp -d fd00:10:244:1::/64 -m owner --socket-exists " +
+			"-m conntrack --ctstate NEW -j CONNMARK --set-xmark 0x4000/0x4000",
+		"ip6tables -t nat -A ISTIO_PROBE_SNAT -p tcp -d fd00:10:244:1::/64 -m connmark --mark 0x4000/0x4000 " +
+			"-j SNAT --to-source fd16:9254:7127:1337:ffff:ffff:ffff:ffff",
+	})
+	// Rules left by a previous run are removed first.
+	assert.Equal(t, deps.commands[0], "? iptables -t mangle -D OUTPUT -j ISTIO_PROBE_MARK")
+}
+
+func TestNewExemptionWithoutPodCIDR(t *testing.T) {
+	client := fake.NewSimpleClientset(node())
+	if _, err := NewExemption(context.Background(), client, "node-1", &recordingDependencies{}); err == nil {
+		t.Fatal("expected an error for a node without pod CIDR")
+	}
+}
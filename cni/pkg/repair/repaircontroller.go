@@ -149,7 +149,7 @@ func redirectRunningPod(pod *corev1.Pod, netns string) error {
 		return fmt.Errorf("setup redirect: %v", err)
 	}
 	rulesMgr := plugin.IptablesInterceptRuleMgrCtor()
-	if err := rulesMgr.Program(pod.Name, netns, redirect); err != nil {
+	if _, err := rulesMgr.Program(context.Background(), pod.Name, netns, redirect); err != nil {
 		return fmt.Errorf("program redirection: %v", err)
 	}
 	return nil
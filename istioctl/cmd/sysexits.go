@@ -15,10 +15,12 @@
 package cmd
 
 import (
+	"errors"
 	"strings"
 
 	"istio.io/istio/istioctl/pkg/analyze"
 	"istio.io/istio/istioctl/pkg/util"
+	"istio.io/istio/istioctl/pkg/verifier"
 )
 
 // Values should try to use sendmail-style values as in <sysexits.h>
@@ -39,13 +41,33 @@ const (
 
 	// below here are non-zero exit codes that don't indicate an error with istioctl itself
 	ExitAnalyzerFoundIssues = 79 // istioctl analyze found issues, for CI/CD
+
+	// istioctl verify-install failed, by class of failure
+	ExitVerifyUnhealthy        = 80 // resources are present but not healthy, or a check failed
+	ExitVerifyResourcesMissing = 81 // resources of the installation are missing
+	ExitVerifyNoInstallation   = 82 // no Istio installation found
+	ExitVerifyAPIAccess        = 83 // the API server could not be reached or denied access
+	ExitVerifyTimeout          = 84 // the verification or the check of a resource timed out
 )
 
+var verifyExitCodes = map[verifier.FailureClass]int{
+	verifier.FailureUnhealthy:        ExitVerifyUnhealthy,
+	verifier.FailureResourcesMissing: ExitVerifyResourcesMissing,
+	verifier.FailureNoInstallation:   ExitVerifyNoInstallation,
+	verifier.FailureAPIAccess:        ExitVerifyAPIAccess,
+	verifier.FailureTimeout:          ExitVerifyTimeout,
+}
+
 func GetExitCode(e error) int {
 	if strings.Contains(e.Error(), "unknown command") {
 		e = util.CommandParseError{Err: e}
 	}
 
+	var verr *verifier.VerificationError
+	if errors.As(e, &verr) {
+		return verifyExitCodes[verr.Class]
+	}
+
 	switch e.(type) {
 	case util.CommandParseError:
 		return ExitIncorrectUsage
@@ -20,14 +20,20 @@ import (
 
 	"istio.io/istio/istioctl/pkg/analyze"
 	"istio.io/istio/istioctl/pkg/util"
+	"istio.io/istio/istioctl/pkg/verifier"
 )
 
 var KnownErrorCode = map[error]int{
-	errors.New("unknown command"):                                  ExitIncorrectUsage,
-	errors.New("unexpected error"):                                 ExitUnknownError,
-	util.CommandParseError{Err: errors.New("command parse error")}: ExitIncorrectUsage,
-	analyze.FileParseError{}:                                       ExitDataError,
-	analyze.AnalyzerFoundIssuesError{}:                             ExitAnalyzerFoundIssues,
+	errors.New("unknown command"):                                        ExitIncorrectUsage,
+	errors.New("unexpected error"):                                       ExitUnknownError,
+	util.CommandParseError{Err: errors.New("command parse error")}:       ExitIncorrectUsage,
+	analyze.FileParseError{}:                                             ExitDataError,
+	analyze.AnalyzerFoundIssuesError{}:                                   ExitAnalyzerFoundIssues,
+	&verifier.VerificationError{Class: verifier.FailureUnhealthy}:        ExitVerifyUnhealthy,
+	&verifier.VerificationError{Class: verifier.FailureResourcesMissing}: ExitVerifyResourcesMissing,
+	&verifier.VerificationError{Class: verifier.FailureNoInstallation}:   ExitVerifyNoInstallation,
+	&verifier.VerificationError{Class: verifier.FailureAPIAccess}:        ExitVerifyAPIAccess,
+	&verifier.VerificationError{Class: verifier.FailureTimeout}:          ExitVerifyTimeout,
 }
 
 func TestKnownExitStrings(t *testing.T) {
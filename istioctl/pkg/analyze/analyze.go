@@ -109,6 +109,9 @@ func Analyze(ctx cli.Context) *cobra.Command {
   # and suppress MisplacedAnnotation on deployment foobar in namespace default.
   istioctl analyze -S "IST0103=Pod *.testing" -S "IST0107=Deployment foobar.default"
 
+  # Analyze a config repository in CI and upload the findings to a code scanning UI
+  istioctl analyze --use-kube=false --recursive -o sarif config/ > istio.sarif
+
   # List available analyzers
   istioctl analyze -L`,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -191,7 +194,8 @@ func Analyze(ctx cli.Context) *cobra.Command {
 				if err != nil {
 					return err
 				}
-				k := kube.EnableCrdWatcher(clik.(kube.Client))
+				// The crd watcher is set on the underlying client, not on the cache of the debug endpoints of istiod.
+				k := kube.EnableCrdWatcher(cli.WithoutDebugCache(clik))
 				sa.AddRunningKubeSourceWithRevision(k, revisionSpecified)
 			}
 
@@ -471,5 +475,5 @@ func analyzeTargetAsString() string {
 
 // TODO: Refactor output writer so that it is smart enough to know when to output what.
 func isJSONorYAMLOutputFormat() bool {
-	return msgOutputFormat == formatting.JSONFormat || msgOutputFormat == formatting.YAMLFormat
+	return msgOutputFormat == formatting.JSONFormat || msgOutputFormat == formatting.YAMLFormat || msgOutputFormat == formatting.SARIFFormat
 }
@@ -0,0 +1,408 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle creates and opens offline installation bundles: a single archive holding the charts and
+// profiles of an Istio release, the list of its images, and a manifest of the digests of both, so that
+// Istio can be installed and verified without network access.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// ManifestFile is the name of the bundle manifest at the root of the archive.
+	ManifestFile = "bundle.yaml"
+	// ImagesFile lists the images of the release, one per line, for mirroring them to a private registry.
+	ImagesFile = "images.txt"
+	// ManifestsDir holds the charts and profiles of the release, in the layout expected by --manifests.
+	ManifestsDir = "manifests"
+
+	// DefaultHub is the hub of release images, used when the profiles do not set one.
+	DefaultHub = "docker.io/istio"
+
+	// releaseURLFormat is the URL of the release archive, the charts and profiles do not depend on
+	// the platform.
+	releaseURLFormat = "https://github.com/istio/istio/releases/download/%[1]s/istio-%[1]s-linux-amd64.tar.gz"
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	// Version is the Istio version the bundle was created for.
+	Version string    `json:"version"`
+	Created time.Time `json:"created"`
+	// Source is the release archive the charts and profiles were taken from.
+	Source string  `json:"source"`
+	Files  []File  `json:"files"`
+	Images []Image `json:"images"`
+}
+
+// File is a file of the bundle with the hex encoded SHA-256 digest of its content.
+type File struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Image is an image of the release, with its digest if it was resolved when the bundle was created.
+type Image struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// Reference returns the image reference pinned to its digest, if known.
+func (i Image) Reference() string {
+	if i.Digest == "" {
+		return i.Name
+	}
+	return i.Name + "@" + i.Digest
+}
+
+// DigestResolver returns the digest of an image reference.
+type DigestResolver func(ref string) (string, error)
+
+// CreateOptions configures the creation of a bundle.
+type CreateOptions struct {
+	// Version is the Istio release to bundle.
+	Version string
+	// ReleaseURL overrides the URL of the release archive.
+	ReleaseURL string
+	// HTTPClient downloads the release archive, http.DefaultClient if unset.
+	HTTPClient *http.Client
+	// ResolveDigest, if set, pins the images of the bundle to their digests.
+	ResolveDigest DigestResolver
+}
+
+// DefaultReleaseURL returns the URL of the release archive of the version.
+func DefaultReleaseURL(version string) string {
+	return fmt.Sprintf(releaseURLFormat, version)
+}
+
+// Create downloads the release of the options and writes its bundle as a gzipped tar archive to w.
+func Create(opts CreateOptions, w io.Writer) (*Manifest, error) {
+	if opts.Version == "" {
+		return nil, fmt.Errorf("a version is required")
+	}
+	url := opts.ReleaseURL
+	if url == "" {
+		url = DefaultReleaseURL(opts.Version)
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	m := &Manifest{Version: opts.Version, Created: time.Now().UTC(), Source: url}
+	bw := newBundleWriter(w)
+	images, err := copyReleaseManifests(resp.Body, bw, m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release archive %s: %v", url, err)
+	}
+	if len(m.Files) == 0 {
+		return nil, fmt.Errorf("release archive %s contains no charts or profiles", url)
+	}
+
+	for _, ref := range images.names(opts.Version) {
+		img := Image{Name: ref}
+		if opts.ResolveDigest != nil {
+			if img.Digest, err = opts.ResolveDigest(ref); err != nil {
+				return nil, fmt.Errorf("failed to resolve the digest of %s: %v", ref, err)
+			}
+		}
+		m.Images = append(m.Images, img)
+	}
+	var list strings.Builder
+	for _, img := range m.Images {
+		list.WriteString(img.Reference() + "\n")
+	}
+	if err := bw.add(ImagesFile, []byte(list.String()), m); err != nil {
+		return nil, err
+	}
+
+	by, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// The manifest is not part of its own file list.
+	if err := bw.write(ManifestFile, by); err != nil {
+		return nil, err
+	}
+	return m, bw.Close()
+}
+
+// releaseImages collects the hub and image names found in the charts and profiles of a release.
+type releaseImages struct {
+	hub    string
+	images sets.String
+}
+
+// names returns the sorted references of the images with the tag.
+func (r releaseImages) names(tag string) []string {
+	hub := r.hub
+	if hub == "" {
+		hub = DefaultHub
+	}
+	var refs []string
+	for _, image := range sets.SortedList(r.images) {
+		// Images may be given as full references, these do not use the hub and tag of the release.
+		if strings.ContainsAny(image, "/:@") {
+			refs = append(refs, image)
+			continue
+		}
+		refs = append(refs, fmt.Sprintf("%s/%s:%s", hub, image, tag))
+	}
+	return refs
+}
+
+// copyReleaseManifests copies the charts and profiles of the release archive r into the bundle, and
+// returns the images referenced by them.
+func copyReleaseManifests(r io.Reader, bw *bundleWriter, m *Manifest) (releaseImages, error) {
+	images := releaseImages{images: sets.New[string]()}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return images, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return images, nil
+		}
+		if err != nil {
+			return images, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// Release archives have a single istio-<version> top level directory.
+		parts := strings.SplitN(path.Clean(hdr.Name), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		rel := parts[1]
+		if !strings.HasPrefix(rel, ManifestsDir+"/charts/") && !strings.HasPrefix(rel, ManifestsDir+"/profiles/") {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return images, err
+		}
+		if err := bw.add(rel, content, m); err != nil {
+			return images, err
+		}
+		switch {
+		case path.Base(rel) == "values.yaml":
+			collectImages(content, &images, false)
+		case rel == ManifestsDir+"/profiles/default.yaml":
+			collectImages(content, &images, true)
+		}
+	}
+}
+
+// collectImages walks YAML content for image names, and for the hub if useHub is set.
+func collectImages(content []byte, images *releaseImages, useHub bool) {
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		// Charts may contain templated values files, these are not needed to list the images.
+		return
+	}
+	var walk func(any)
+	walk = func(node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			for k, v := range n {
+				s, isString := v.(string)
+				switch {
+				case k == "image" && isString && s != "":
+					images.images.Insert(s)
+				case k == "hub" && isString && s != "" && useHub && images.hub == "":
+					images.hub = s
+				default:
+					walk(v)
+				}
+			}
+		case []any:
+			for _, v := range n {
+				walk(v)
+			}
+		}
+	}
+	walk(doc)
+}
+
+// bundleWriter writes the files of a bundle, recording their digests.
+type bundleWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newBundleWriter(w io.Writer) *bundleWriter {
+	gz := gzip.NewWriter(w)
+	return &bundleWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+// add writes the file and records its digest in the manifest.
+func (b *bundleWriter) add(name string, content []byte, m *Manifest) error {
+	if err := b.write(name, content); err != nil {
+		return err
+	}
+	m.Files = append(m.Files, File{Path: name, SHA256: digest(content)})
+	return nil
+}
+
+func (b *bundleWriter) write(name string, content []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(content)
+	return err
+}
+
+func (b *bundleWriter) Close() error {
+	if err := b.tw.Close(); err != nil {
+		return err
+	}
+	return b.gz.Close()
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Extract extracts the bundle at bundlePath into dir, and verifies its files against the digests of
+// its manifest.
+func Extract(bundlePath, dir string) (*Manifest, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a bundle: %v", bundlePath, err)
+	}
+	tr := tar.NewReader(gz)
+	digests := map[string]string{}
+	var manifest []byte
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle %s: %v", bundlePath, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("bundle %s contains the invalid path %q", bundlePath, hdr.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from bundle %s: %v", name, bundlePath, err)
+		}
+		if name == ManifestFile {
+			manifest = content
+			continue
+		}
+		digests[name] = digest(content)
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("bundle %s has no %s", bundlePath, ManifestFile)
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(manifest, m); err != nil {
+		return nil, fmt.Errorf("invalid %s in bundle %s: %v", ManifestFile, bundlePath, err)
+	}
+	if err := m.verify(digests); err != nil {
+		return nil, fmt.Errorf("bundle %s failed verification: %v", bundlePath, err)
+	}
+	return m, nil
+}
+
+// verify checks that the files found, keyed by path, are exactly the files of the manifest.
+func (m *Manifest) verify(found map[string]string) error {
+	var problems []string
+	listed := sets.New[string]()
+	for _, f := range m.Files {
+		listed.Insert(f.Path)
+		d, ok := found[f.Path]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s is missing", f.Path))
+		case d != f.SHA256:
+			problems = append(problems, fmt.Sprintf("%s has digest %s, expected %s", f.Path, d, f.SHA256))
+		}
+	}
+	for p := range found {
+		if !listed.Contains(p) {
+			problems = append(problems, fmt.Sprintf("%s is not listed in the manifest", p))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Open extracts the bundle to a temporary directory and returns the path of its charts and profiles,
+// to be used as the manifests path of install and verify. The returned function removes the directory.
+func Open(bundlePath string) (string, *Manifest, func(), error) {
+	dir, err := os.MkdirTemp("", "istio-bundle-")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	m, err := Extract(bundlePath, dir)
+	if err != nil {
+		cleanup()
+		return "", nil, nil, err
+	}
+	return filepath.Join(dir, ManifestsDir), m, cleanup, nil
+}
@@ -0,0 +1,166 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// releaseArchive returns a gzipped tar archive laid out like an Istio release.
+func releaseArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func releaseServer(t *testing.T) *httptest.Server {
+	archive := releaseArchive(t, map[string]string{
+		"istio-1.20.1/bin/istioctl":                                               "binary",
+		"istio-1.20.1/samples/bookinfo/bookinfo.yaml":                             "kind: Service",
+		"istio-1.20.1/manifests/profiles/default.yaml":                            "spec:\n  hub: docker.io/istio\n  tag: 1.20.1\n",
+		"istio-1.20.1/manifests/charts/istio-control/istio-discovery/values.yaml": "pilot:\n  image: pilot\nglobal:\n  hub: \"\"\n  proxy:\n    image: proxyv2\n",
+		"istio-1.20.1/manifests/charts/istio-cni/values.yaml":                     "cni:\n  image: install-cni\n",
+		"istio-1.20.1/manifests/charts/gateway/values.yaml":                       "image: auto\nextra:\n- image: registry.example.com/tools:1.0\n",
+		"istio-1.20.1/manifests/charts/base/templates/crds.yaml":                  "{{ .Values.base }}",
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/istio-1.20.1.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func createBundle(t *testing.T, srv *httptest.Server) (string, *Manifest) {
+	t.Helper()
+	var buf bytes.Buffer
+	m, err := Create(CreateOptions{
+		Version:    "1.20.1",
+		ReleaseURL: srv.URL + "/istio-1.20.1.tar.gz",
+		ResolveDigest: func(ref string) (string, error) {
+			return "sha256:" + strings.Repeat("a", 64), nil
+		},
+	}, &buf)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	return path, m
+}
+
+func TestCreateAndOpen(t *testing.T) {
+	path, m := createBundle(t, releaseServer(t))
+
+	var images []string
+	for _, img := range m.Images {
+		images = append(images, img.Name)
+	}
+	assert.Equal(t, images, []string{
+		"docker.io/istio/auto:1.20.1",
+		"docker.io/istio/install-cni:1.20.1",
+		"docker.io/istio/pilot:1.20.1",
+		"docker.io/istio/proxyv2:1.20.1",
+		"registry.example.com/tools:1.0",
+	})
+	// The five charts and profiles files and the images list, but nothing else of the release.
+	assert.Equal(t, len(m.Files), 6)
+
+	manifestsPath, opened, cleanup, err := Open(path)
+	assert.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, opened.Version, "1.20.1")
+	profile, err := os.ReadFile(filepath.Join(manifestsPath, "profiles", "default.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Contains(string(profile), "hub: docker.io/istio"), true)
+	list, err := os.ReadFile(filepath.Join(filepath.Dir(manifestsPath), ImagesFile))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Contains(string(list), "docker.io/istio/pilot:1.20.1@sha256:aaaa"), true)
+}
+
+func TestCreateMissingRelease(t *testing.T) {
+	srv := releaseServer(t)
+	_, err := Create(CreateOptions{Version: "1.20.1", ReleaseURL: srv.URL + "/missing.tar.gz"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected a download error, got %v", err)
+	}
+}
+
+func TestExtractTampered(t *testing.T) {
+	path, _ := createBundle(t, releaseServer(t))
+	dir := t.TempDir()
+	_, err := Extract(path, dir)
+	assert.NoError(t, err)
+
+	// Rewrite the bundle with a modified profile and an extra file.
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	gz, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	tr := tar.NewReader(gz)
+	files := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		var content bytes.Buffer
+		_, _ = content.ReadFrom(tr)
+		files[hdr.Name] = content.String()
+	}
+	_ = f.Close()
+	files["manifests/profiles/default.yaml"] = "spec:\n  hub: evil.example.com\n"
+	files["manifests/profiles/extra.yaml"] = "spec: {}\n"
+	tampered := filepath.Join(t.TempDir(), "tampered.tar.gz")
+	assert.NoError(t, os.WriteFile(tampered, releaseArchive(t, files), 0o644))
+
+	_, err = Extract(tampered, t.TempDir())
+	if err == nil {
+		t.Fatal("expected verification to fail")
+	}
+	for _, want := range []string{"manifests/profiles/default.yaml has digest", "manifests/profiles/extra.yaml is not listed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestExtractRejectsPathTraversal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "evil.tar.gz")
+	assert.NoError(t, os.WriteFile(path, releaseArchive(t, map[string]string{"../evil": "x"}), 0o644))
+	if _, err := Extract(path, t.TempDir()); err == nil || !strings.Contains(err.Error(), "invalid path") {
+		t.Fatalf("expected invalid path error, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+)
+
+// Cmd returns the bundle command.
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Create bundles for offline installations",
+		Long: `A bundle is a single archive holding the charts and profiles of an Istio release, the list
+of its images and a manifest with the digests of both. Pass it to 'istioctl install --bundle'
+and 'istioctl verify-install --bundle' to install and verify Istio without network access.`,
+	}
+	cmd.AddCommand(createCmd())
+	return cmd
+}
+
+func createCmd() *cobra.Command {
+	var (
+		opts           CreateOptions
+		output         string
+		resolveDigests bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create --version <version>",
+		Short: "Downloads an Istio release into a bundle for offline installations",
+		Example: `  # Create istio-1.20.1-bundle.tar.gz
+  istioctl x bundle create --version 1.20.1
+
+  # Mirror the images listed in the bundle, then install from it without network access
+  tar -xzf istio-1.20.1-bundle.tar.gz images.txt
+  istioctl install --bundle istio-1.20.1-bundle.tar.gz --set hub=registry.example.com/istio`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.Version == "" {
+				return fmt.Errorf("--version is required")
+			}
+			if output == "" {
+				output = fmt.Sprintf("istio-%s-bundle.tar.gz", opts.Version)
+			}
+			if resolveDigests {
+				opts.ResolveDigest = func(ref string) (string, error) {
+					return crane.Digest(ref)
+				}
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			m, err := Create(opts, f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				_ = os.Remove(output)
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created bundle %s of Istio %s with %d files and %d images.\n",
+				output, m.Version, len(m.Files), len(m.Images))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Istio version to bundle, e.g. 1.20.1")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Path of the bundle, istio-<version>-bundle.tar.gz by default")
+	cmd.Flags().StringVar(&opts.ReleaseURL, "release-url", "",
+		"URL of the release archive to bundle, the GitHub release of the version by default")
+	cmd.Flags().BoolVar(&resolveDigests, "resolve-digests", true,
+		"Resolve the digests of the images from their registry and pin the images list to them")
+	return cmd
+}
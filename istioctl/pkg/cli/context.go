@@ -19,6 +19,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/istioctl/pkg/writer/output"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/ptr"
 )
@@ -41,11 +42,15 @@ type Context interface {
 	// ConfigureDefaultNamespace sets the default namespace to use for commands that don't specify a namespace.
 	// This should be called before NamespaceOrDefault is called.
 	ConfigureDefaultNamespace()
+	// OutputFormat returns the output format specified by the user
+	OutputFormat() string
 }
 
 type instance struct {
 	// clients are cached clients for each revision
 	clients map[string]kube.CLIClient
+	// debugCache is shared by the clients of all revisions
+	debugCache *DebugCache
 	RootFlags
 }
 
@@ -69,6 +74,7 @@ func NewCLIContext(rootFlags *RootFlags) Context {
 			configContext:    ptr.Of[string](""),
 			namespace:        ptr.Of[string](""),
 			istioNamespace:   ptr.Of[string](""),
+			outputFormat:     ptr.Of[string](output.TableFormat),
 			defaultNamespace: "",
 		}
 	}
@@ -86,6 +92,12 @@ func (i *instance) CLIClientWithRevision(rev string) (kube.CLIClient, error) {
 		if err != nil {
 			return nil, err
 		}
+		if !i.NoCache() {
+			if i.debugCache == nil {
+				i.debugCache = NewDebugCache(DebugCacheTTL)
+			}
+			client = NewDebugCachingClient(client, i.debugCache)
+		}
 		i.clients[rev] = client
 	}
 	return i.clients[rev], nil
@@ -188,10 +200,16 @@ func (f *fakeInstance) IstioNamespace() string {
 func (f *fakeInstance) ConfigureDefaultNamespace() {
 }
 
+func (f *fakeInstance) OutputFormat() string {
+	return f.rootFlags.OutputFormat()
+}
+
 type NewFakeContextOption struct {
 	Namespace      string
 	IstioNamespace string
-	Results        map[string][]byte
+	// OutputFormat defaults to the table format.
+	OutputFormat string
+	Results      map[string][]byte
 }
 
 func NewFakeContext(opts *NewFakeContextOption) Context {
@@ -200,6 +218,10 @@ func NewFakeContext(opts *NewFakeContextOption) Context {
 	}
 	ns := opts.Namespace
 	ins := opts.IstioNamespace
+	format := opts.OutputFormat
+	if format == "" {
+		format = output.TableFormat
+	}
 	return &fakeInstance{
 		clients: map[string]kube.CLIClient{},
 		rootFlags: &RootFlags{
@@ -207,6 +229,7 @@ func NewFakeContext(opts *NewFakeContextOption) Context {
 			configContext:    ptr.Of[string](""),
 			namespace:        &ns,
 			istioNamespace:   &ins,
+			outputFormat:     &format,
 			defaultNamespace: "",
 		},
 		results: opts.Results,
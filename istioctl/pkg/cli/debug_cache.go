@@ -0,0 +1,123 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/kube"
+)
+
+// DebugCacheTTL is how long the responses of the debug endpoints of istiod are reused within an invocation of
+// istioctl. It is short, as the commands show the state of the mesh when they are run.
+const DebugCacheTTL = 10 * time.Second
+
+// DebugCache holds the responses of the debug endpoints of istiod, so that the commands of an invocation which
+// query the same endpoint, such as describe and analyze, port-forward to and scrape every istiod only once.
+// Failed queries are not cached.
+type DebugCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]debugCacheEntry
+}
+
+type debugCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// NewDebugCache returns a cache reusing the responses for ttl.
+func NewDebugCache(ttl time.Duration) *DebugCache {
+	return &DebugCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]debugCacheEntry{},
+	}
+}
+
+func (c *DebugCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *DebugCache) set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = debugCacheEntry{value: value, expires: c.now().Add(c.ttl)}
+}
+
+// Cached returns the cached response for key, or the response of fetch, which is cached if fetch succeeds. A nil
+// cache always calls fetch.
+func Cached[T any](c *DebugCache, key string, fetch func() (T, error)) (T, error) {
+	if c == nil {
+		return fetch()
+	}
+	if value, ok := c.get(key); ok {
+		return value.(T), nil
+	}
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	c.set(key, value)
+	return value, nil
+}
+
+// debugCachingClient caches the responses of the debug endpoints of istiod queried through the client.
+type debugCachingClient struct {
+	kube.CLIClient
+	cache *DebugCache
+}
+
+// NewDebugCachingClient wraps client so that the responses of the debug endpoints of istiod are cached.
+func NewDebugCachingClient(client kube.CLIClient, cache *DebugCache) kube.CLIClient {
+	return debugCachingClient{CLIClient: client, cache: cache}
+}
+
+func (c debugCachingClient) AllDiscoveryDo(ctx context.Context, istiodNamespace, path string) (map[string][]byte, error) {
+	return Cached(c.cache, "discovery/"+istiodNamespace+"/"+path, func() (map[string][]byte, error) {
+		return c.CLIClient.AllDiscoveryDo(ctx, istiodNamespace, path)
+	})
+}
+
+// DebugCacheOf returns the cache of the debug endpoints of istiod of client, or nil if its responses are not cached.
+func DebugCacheOf(client kube.CLIClient) *DebugCache {
+	if c, ok := client.(debugCachingClient); ok {
+		return c.cache
+	}
+	return nil
+}
+
+// WithoutDebugCache returns the client queried by client without caching, for the commands polling istiod until
+// it reaches some state.
+func WithoutDebugCache(client kube.CLIClient) kube.CLIClient {
+	if c, ok := client.(debugCachingClient); ok {
+		return c.CLIClient
+	}
+	return client
+}
@@ -0,0 +1,74 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+type countingClient struct {
+	kube.CLIClient
+	queries *int
+}
+
+func (c countingClient) AllDiscoveryDo(_ context.Context, _, path string) (map[string][]byte, error) {
+	*c.queries++
+	if path == "debug/broken" {
+		return nil, errors.New("broken")
+	}
+	return map[string][]byte{"istiod": []byte(path)}, nil
+}
+
+func TestDebugCachingClient(t *testing.T) {
+	now := time.Now()
+	cache := NewDebugCache(time.Second)
+	cache.now = func() time.Time { return now }
+	queries := 0
+	client := NewDebugCachingClient(countingClient{CLIClient: kube.NewFakeClient(), queries: &queries}, cache)
+
+	for i := 0; i < 3; i++ {
+		got, err := client.AllDiscoveryDo(context.Background(), "istio-system", "debug/syncz")
+		assert.NoError(t, err)
+		assert.Equal(t, string(got["istiod"]), "debug/syncz")
+	}
+	assert.Equal(t, queries, 1)
+
+	_, _ = client.AllDiscoveryDo(context.Background(), "istio-system", "debug/configz")
+	_, _ = client.AllDiscoveryDo(context.Background(), "istio-other", "debug/syncz")
+	assert.Equal(t, queries, 3)
+
+	// Failures are queried again.
+	for i := 0; i < 2; i++ {
+		_, err := client.AllDiscoveryDo(context.Background(), "istio-system", "debug/broken")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, queries, 5)
+
+	now = now.Add(time.Second)
+	_, _ = client.AllDiscoveryDo(context.Background(), "istio-system", "debug/syncz")
+	assert.Equal(t, queries, 6)
+
+	// Without the cache, every query reaches istiod.
+	_, _ = WithoutDebugCache(client).AllDiscoveryDo(context.Background(), "istio-system", "debug/syncz")
+	assert.Equal(t, queries, 7)
+	assert.Equal(t, DebugCacheOf(client) == cache, true)
+	assert.Equal(t, DebugCacheOf(WithoutDebugCache(client)) == nil, true)
+}
@@ -15,11 +15,14 @@
 package cli
 
 import (
+	"fmt"
+
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"istio.io/istio/istioctl/pkg/writer/output"
 	"istio.io/istio/pkg/ptr"
 )
 
@@ -28,6 +31,8 @@ const (
 	FlagContext        = "context"
 	FlagNamespace      = "namespace"
 	FlagIstioNamespace = "istioNamespace"
+	FlagOutputFormat   = "output-format"
+	FlagNoCache        = "no-cache"
 )
 
 type RootFlags struct {
@@ -35,6 +40,8 @@ type RootFlags struct {
 	configContext  *string
 	namespace      *string
 	istioNamespace *string
+	outputFormat   *string
+	noCache        *bool
 
 	defaultNamespace string
 }
@@ -45,6 +52,8 @@ func AddRootFlags(flags *pflag.FlagSet) *RootFlags {
 		configContext:  ptr.Of[string](""),
 		namespace:      ptr.Of[string](""),
 		istioNamespace: ptr.Of[string](""),
+		outputFormat:   ptr.Of[string](""),
+		noCache:        ptr.Of(false),
 	}
 	flags.StringVarP(r.kubeconfig, FlagKubeConfig, "c", "",
 		"Kubernetes configuration file")
@@ -54,6 +63,11 @@ func AddRootFlags(flags *pflag.FlagSet) *RootFlags {
 		"Kubernetes namespace")
 	flags.StringVarP(r.istioNamespace, FlagIstioNamespace, "i", viper.GetString(FlagIstioNamespace),
 		"Istio system namespace")
+	flags.StringVar(r.outputFormat, FlagOutputFormat, output.TableFormat,
+		fmt.Sprintf("Output format of the commands supporting machine-readable output, one of %v. "+
+			"The json and yaml formats wrap the results in a list with apiVersion, kind and items.", output.Formats))
+	flags.BoolVar(r.noCache, FlagNoCache, false,
+		"Query the debug endpoints of istiod every time, rather than reusing their responses for a few seconds within the command")
 	return r
 }
 
@@ -75,6 +89,16 @@ func (r *RootFlags) IstioNamespace() string {
 	return *r.istioNamespace
 }
 
+// OutputFormat returns the output-format flag value.
+func (r *RootFlags) OutputFormat() string {
+	return *r.outputFormat
+}
+
+// NoCache returns the no-cache flag value.
+func (r *RootFlags) NoCache() bool {
+	return r.noCache != nil && *r.noCache
+}
+
 // DefaultNamespace returns the default namespace to use.
 func (r *RootFlags) DefaultNamespace() string {
 	return r.defaultNamespace
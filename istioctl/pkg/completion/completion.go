@@ -22,8 +22,20 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"istio.io/api/label"
 	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// tagLabel labels the injection webhooks of revision tags. It is defined by the tag package, which
+	// completes its arguments with this package.
+	tagLabel = "istio.io/tag"
+	// defaultRevision is the name of the revision installed without revision.
+	defaultRevision = "default"
+	// istiodSelector selects the istiod Deployments of all revisions.
+	istiodSelector = "app=istiod"
 )
 
 func getPodsNameInDefaultNamespace(ctx cli.Context, toComplete string) ([]string, error) {
@@ -131,3 +143,88 @@ func ValidNamespaceArgs(_ *cobra.Command, ctx cli.Context, args []string, toComp
 	}
 	return nsName, cobra.ShellCompDirectiveNoFileComp
 }
+
+// getRevisionsAndTags returns the sorted revisions of the control planes installed in the cluster, from their
+// injection webhooks and istiod Deployments, and the sorted revision tags.
+func getRevisionsAndTags(ctx cli.Context) ([]string, []string, error) {
+	client, err := ctx.CLIClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	webhooks, err := client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(),
+		metav1.ListOptions{LabelSelector: label.IoIstioRev.Name})
+	if err != nil {
+		return nil, nil, err
+	}
+	revisions, tags := sets.New[string](), sets.New[string]()
+	for _, wh := range webhooks.Items {
+		if t, f := wh.Labels[tagLabel]; f {
+			tags.Insert(t)
+			continue
+		}
+		revisions.Insert(normalizeRevision(wh.Labels[label.IoIstioRev.Name]))
+	}
+	deployments, err := client.Kube().AppsV1().Deployments(ctx.IstioNamespace()).List(context.Background(),
+		metav1.ListOptions{LabelSelector: istiodSelector})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range deployments.Items {
+		revisions.Insert(normalizeRevision(d.Labels[label.IoIstioRev.Name]))
+	}
+	return sets.SortedList(revisions), sets.SortedList(tags), nil
+}
+
+func normalizeRevision(revision string) string {
+	if revision == "" {
+		return defaultRevision
+	}
+	return revision
+}
+
+// withPrefix returns the names starting with prefix.
+func withPrefix(names []string, prefix string) []string {
+	var matching []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matching = append(matching, name)
+		}
+	}
+	return matching
+}
+
+// ValidRevisionArgs completes the revisions of the control planes installed in the cluster, such as the value of
+// a --revision flag.
+func ValidRevisionArgs(_ *cobra.Command, ctx cli.Context, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	revisions, _, err := getRevisionsAndTags(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return withPrefix(revisions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// ValidTagArgs completes the revision tag argument of a command with the revision tags of the cluster.
+func ValidTagArgs(_ *cobra.Command, ctx cli.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	_, tags, err := getRevisionsAndTags(ctx)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return withPrefix(tags, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// RegisterRevisionFlag completes the --revision flag of the command with the revisions of the cluster.
+func RegisterRevisionFlag(cmd *cobra.Command, ctx cli.Context) {
+	_ = cmd.RegisterFlagCompletionFunc("revision", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ValidRevisionArgs(cmd, ctx, args, toComplete)
+	})
+}
+
+// RegisterNamespaceFlag completes a namespace flag of the command with the namespaces of the cluster.
+func RegisterNamespaceFlag(cmd *cobra.Command, ctx cli.Context, flag string) {
+	_ = cmd.RegisterFlagCompletionFunc(flag, func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ValidNamespaceArgs(cmd, ctx, nil, toComplete)
+	})
+}
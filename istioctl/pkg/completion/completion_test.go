@@ -0,0 +1,62 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package completion
+
+import (
+	"context"
+	"testing"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestValidRevisionAndTagArgs(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{IstioNamespace: "istio-system"})
+	client, err := ctx.CLIClient()
+	assert.NoError(t, err)
+	webhooks := []*admitv1.MutatingWebhookConfiguration{
+		{ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector", Labels: map[string]string{"istio.io/rev": ""}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "istio-revision-tag-prod", Labels: map[string]string{"istio.io/rev": "canary", tagLabel: "prod"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "istio-revision-tag-stable", Labels: map[string]string{"istio.io/rev": "canary", tagLabel: "stable"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}},
+	}
+	for _, wh := range webhooks {
+		_, err := client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().Create(context.Background(), wh, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+	istiod := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "istiod-canary",
+		Namespace: "istio-system",
+		Labels:    map[string]string{"app": "istiod", "istio.io/rev": "canary"},
+	}}
+	_, err = client.Kube().AppsV1().Deployments("istio-system").Create(context.Background(), istiod, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	revisions, _ := ValidRevisionArgs(nil, ctx, nil, "")
+	assert.Equal(t, revisions, []string{"canary", "default"})
+	revisions, _ = ValidRevisionArgs(nil, ctx, nil, "ca")
+	assert.Equal(t, revisions, []string{"canary"})
+
+	tags, _ := ValidTagArgs(nil, ctx, nil, "")
+	assert.Equal(t, tags, []string{"prod", "stable"})
+	tags, _ = ValidTagArgs(nil, ctx, nil, "st")
+	assert.Equal(t, tags, []string{"stable"})
+	tags, _ = ValidTagArgs(nil, ctx, []string{"prod"}, "")
+	assert.Equal(t, len(tags), 0)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/root"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/env"
@@ -41,15 +42,21 @@ var settableFlags = map[string]env.VariableInfo{
 }
 
 // Cmd represents the config subcommand command
-func Cmd() *cobra.Command {
+func Cmd(ctx cli.Context) *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config SUBCOMMAND",
-		Short: "Configure istioctl defaults",
+		Short: "Configure istioctl defaults and manage the history of Istio resources",
 		Args:  cobra.NoArgs,
 		Example: `  # list configuration parameters
-  istioctl experimental config list`,
+  istioctl experimental config list
+
+  # roll the bookinfo virtual service back to its previously recorded version
+  istioctl experimental config rollback virtualservice bookinfo.default --to -1`,
 	}
 	configCmd.AddCommand(listCommand())
+	configCmd.AddCommand(recordCommand(ctx))
+	configCmd.AddCommand(historyCommand(ctx))
+	configCmd.AddCommand(rollbackCommand(ctx))
 	return configCmd
 }
 
@@ -0,0 +1,385 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/resource"
+	"istio.io/istio/pkg/kube"
+)
+
+const (
+	// historyConfigMapPrefix prefixes the name of the ConfigMaps journaling the versions of a resource.
+	historyConfigMapPrefix = "istio-config-history-"
+	// historyLabel marks journal ConfigMaps, its value is the kind of the journaled resource.
+	historyLabel = "istio.io/config-history"
+	// historyKeyPrefix prefixes the ConfigMap keys holding each recorded version.
+	historyKeyPrefix = "v"
+
+	defaultMaxHistory = 10
+)
+
+// historyEntry is a recorded version of an Istio resource.
+type historyEntry struct {
+	Version         int               `json:"version"`
+	Recorded        metav1.Time       `json:"recorded"`
+	Generation      int64             `json:"generation"`
+	ResourceVersion string            `json:"resourceVersion"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Spec            map[string]any    `json:"spec"`
+}
+
+func (e historyEntry) matches(obj *unstructured.Unstructured) bool {
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	return reflect.DeepEqual(e.Spec, spec) && reflect.DeepEqual(e.Labels, nonEmpty(obj.GetLabels()))
+}
+
+// journal records the versions of a single Istio resource in a ConfigMap next to it.
+type journal struct {
+	client    kube.CLIClient
+	schema    resource.Schema
+	name      string
+	namespace string
+}
+
+func newJournal(client kube.CLIClient, kind, name, namespace string) (*journal, error) {
+	s, err := findSchema(kind)
+	if err != nil {
+		return nil, err
+	}
+	if s.IsClusterScoped() {
+		return nil, fmt.Errorf("history is not supported for cluster scoped type %s", s.Kind())
+	}
+	return &journal{client: client, schema: s, name: name, namespace: namespace}, nil
+}
+
+// findSchema returns the schema of the Istio config type, which is matched case insensitively
+// and ignoring dashes, so that both "virtualservice" and "virtual-service" are accepted.
+func findSchema(kind string) (resource.Schema, error) {
+	k := strings.ReplaceAll(kind, "-", "")
+	for _, s := range collections.Pilot.All() {
+		if strings.EqualFold(k, s.Kind()) || strings.EqualFold(k, s.Plural()) {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s is not recognized", kind)
+}
+
+func (j *journal) configMapName() string {
+	return historyConfigMapPrefix + strings.ToLower(j.schema.Kind()) + "-" + j.name
+}
+
+func (j *journal) get(ctx context.Context) (*unstructured.Unstructured, error) {
+	return j.client.Dynamic().Resource(j.schema.GroupVersionResource()).Namespace(j.namespace).Get(ctx, j.name, metav1.GetOptions{})
+}
+
+// entries returns the recorded versions, oldest first, with the ConfigMap holding them, which is
+// nil if nothing was recorded yet.
+func (j *journal) entries(ctx context.Context) ([]historyEntry, *corev1.ConfigMap, error) {
+	cm, err := j.client.Kube().CoreV1().ConfigMaps(j.namespace).Get(ctx, j.configMapName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]historyEntry, 0, len(cm.Data))
+	for key, value := range cm.Data {
+		if !strings.HasPrefix(key, historyKeyPrefix) {
+			continue
+		}
+		var e historyEntry
+		if err := yaml.Unmarshal([]byte(value), &e); err != nil {
+			return nil, nil, fmt.Errorf("invalid history entry %s in configmap %s/%s: %v", key, cm.Namespace, cm.Name, err)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool {
+		return entries[i].Version < entries[k].Version
+	})
+	return entries, cm, nil
+}
+
+// record journals the current version of the resource, unless it matches the latest recorded
+// version. Versions beyond maxHistory are pruned, oldest first. It returns the latest version and
+// whether it was recorded by this call.
+func (j *journal) record(ctx context.Context, obj *unstructured.Unstructured, maxHistory int) (historyEntry, bool, error) {
+	entries, cm, err := j.entries(ctx)
+	if err != nil {
+		return historyEntry{}, false, err
+	}
+	version := 1
+	if len(entries) > 0 {
+		latest := entries[len(entries)-1]
+		if latest.matches(obj) {
+			return latest, false, nil
+		}
+		version = latest.Version + 1
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	entry := historyEntry{
+		Version:         version,
+		Recorded:        metav1.NewTime(time.Now()),
+		Generation:      obj.GetGeneration(),
+		ResourceVersion: obj.GetResourceVersion(),
+		Labels:          nonEmpty(obj.GetLabels()),
+		Spec:            spec,
+	}
+	by, err := yaml.Marshal(entry)
+	if err != nil {
+		return historyEntry{}, false, err
+	}
+
+	create := cm == nil
+	if create {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      j.configMapName(),
+				Namespace: j.namespace,
+				Labels:    map[string]string{historyLabel: strings.ToLower(j.schema.Kind())},
+			},
+		}
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[historyKey(version)] = string(by)
+	entries = append(entries, entry)
+	for maxHistory > 0 && len(entries) > maxHistory {
+		delete(cm.Data, historyKey(entries[0].Version))
+		entries = entries[1:]
+	}
+
+	if create {
+		_, err = j.client.Kube().CoreV1().ConfigMaps(j.namespace).Create(ctx, cm, metav1.CreateOptions{})
+	} else {
+		_, err = j.client.Kube().CoreV1().ConfigMaps(j.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return historyEntry{}, false, fmt.Errorf("failed to write history configmap %s/%s: %v", j.namespace, cm.Name, err)
+	}
+	return entry, true, nil
+}
+
+// rollback restores the resource to a recorded version. A negative target is relative to the
+// current version, so -1 restores the version before it. The current version is recorded before
+// rolling back and the restored version after, so that a rollback can itself be rolled back.
+func (j *journal) rollback(ctx context.Context, to, maxHistory int) (historyEntry, error) {
+	obj, err := j.get(ctx)
+	if err != nil {
+		return historyEntry{}, err
+	}
+	current, _, err := j.record(ctx, obj, maxHistory)
+	if err != nil {
+		return historyEntry{}, err
+	}
+	entries, _, err := j.entries(ctx)
+	if err != nil {
+		return historyEntry{}, err
+	}
+	target, err := findEntry(entries, current.Version, to)
+	if err != nil {
+		return historyEntry{}, err
+	}
+
+	if err := unstructured.SetNestedMap(obj.Object, target.Spec, "spec"); err != nil {
+		return historyEntry{}, err
+	}
+	obj.SetLabels(target.Labels)
+	updated, err := j.client.Dynamic().Resource(j.schema.GroupVersionResource()).Namespace(j.namespace).Update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return historyEntry{}, fmt.Errorf("failed to restore %s %s/%s: %v", j.schema.Kind(), j.namespace, j.name, err)
+	}
+	if _, _, err := j.record(ctx, updated, maxHistory); err != nil {
+		return historyEntry{}, err
+	}
+	return target, nil
+}
+
+// findEntry resolves the rollback target, either an absolute version or, if negative, relative to
+// the current version.
+func findEntry(entries []historyEntry, current, to int) (historyEntry, error) {
+	if to == 0 {
+		return historyEntry{}, fmt.Errorf("--to must be a recorded version, or negative to go back from the current version")
+	}
+	if to < 0 {
+		idx := -1
+		for i, e := range entries {
+			if e.Version == current {
+				idx = i
+			}
+		}
+		if idx+to < 0 {
+			return historyEntry{}, fmt.Errorf("only %d versions recorded before the current version %d", idx, current)
+		}
+		return entries[idx+to], nil
+	}
+	for _, e := range entries {
+		if e.Version == to {
+			return e, nil
+		}
+	}
+	return historyEntry{}, fmt.Errorf("version %d is not recorded", to)
+}
+
+func historyKey(version int) string {
+	return historyKeyPrefix + strconv.Itoa(version)
+}
+
+func nonEmpty(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// journalForArgs builds the journal of the resource named by the <type> <name>[.<namespace>] arguments.
+func journalForArgs(ctx cli.Context, args []string) (*journal, error) {
+	client, err := ctx.CLIClient()
+	if err != nil {
+		return nil, err
+	}
+	name, namespace := handlers.InferPodInfo(args[1], ctx.NamespaceOrDefault(ctx.Namespace()))
+	return newJournal(client, args[0], name, namespace)
+}
+
+func recordCommand(ctx cli.Context) *cobra.Command {
+	maxHistory := defaultMaxHistory
+	cmd := &cobra.Command{
+		Use:   "record <type> <name>[.<namespace>]",
+		Short: "Record the current version of an Istio resource in its history",
+		Long: `Records the current version of an Istio resource in a ConfigMap next to it, so that it can be
+restored later with 'istioctl experimental config rollback'. Run it after each change to the resource.`,
+		Example: `  # Record the current version of the bookinfo virtual service
+  kubectl apply -f bookinfo-vs.yaml && istioctl experimental config record virtualservice bookinfo.default`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			j, err := journalForArgs(ctx, args)
+			if err != nil {
+				return err
+			}
+			obj, err := j.get(c.Context())
+			if err != nil {
+				return err
+			}
+			entry, recorded, err := j.record(c.Context(), obj, maxHistory)
+			if err != nil {
+				return err
+			}
+			if !recorded {
+				fmt.Fprintf(c.OutOrStdout(), "%s %s/%s unchanged since version %d\n", j.schema.Kind(), j.namespace, j.name, entry.Version)
+				return nil
+			}
+			fmt.Fprintf(c.OutOrStdout(), "Recorded %s %s/%s as version %d\n", j.schema.Kind(), j.namespace, j.name, entry.Version)
+			return nil
+		},
+	}
+	cmd.PersistentFlags().IntVar(&maxHistory, "max-history", maxHistory, "Number of versions to keep, 0 keeps all versions.")
+	return cmd
+}
+
+func historyCommand(ctx cli.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <type> <name>[.<namespace>]",
+		Short: "List the recorded versions of an Istio resource",
+		Example: `  # List the recorded versions of the bookinfo virtual service
+  istioctl experimental config history virtualservice bookinfo.default`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			j, err := journalForArgs(ctx, args)
+			if err != nil {
+				return err
+			}
+			entries, _, err := j.entries(c.Context())
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(c.OutOrStdout(), "No history recorded for %s %s/%s\n", j.schema.Kind(), j.namespace, j.name)
+				return nil
+			}
+			obj, err := j.get(c.Context())
+			if err != nil && !kerrors.IsNotFound(err) {
+				return err
+			}
+			return printHistory(c.OutOrStdout(), entries, obj)
+		},
+	}
+}
+
+func printHistory(writer io.Writer, entries []historyEntry, current *unstructured.Unstructured) error {
+	w := new(tabwriter.Writer).Init(writer, 0, 8, 5, ' ', 0)
+	fmt.Fprintf(w, "VERSION\tRECORDED\tGENERATION\tRESOURCE VERSION\tCURRENT\n")
+	for _, e := range entries {
+		marker := ""
+		if current != nil && e.matches(current) {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", e.Version, e.Recorded.UTC().Format(time.RFC3339), e.Generation, e.ResourceVersion, marker)
+	}
+	return w.Flush()
+}
+
+func rollbackCommand(ctx cli.Context) *cobra.Command {
+	to := -1
+	maxHistory := defaultMaxHistory
+	cmd := &cobra.Command{
+		Use:   "rollback <type> <name>[.<namespace>]",
+		Short: "Roll an Istio resource back to a recorded version",
+		Long: `Restores the spec and labels of an Istio resource from a version recorded with
+'istioctl experimental config record'. The restored version is recorded as a new version.`,
+		Example: `  # Undo the last change to the bookinfo virtual service
+  istioctl experimental config rollback virtualservice bookinfo.default --to -1
+
+  # Restore version 3 of the bookinfo virtual service
+  istioctl experimental config rollback virtualservice bookinfo.default --to 3`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			j, err := journalForArgs(ctx, args)
+			if err != nil {
+				return err
+			}
+			target, err := j.rollback(c.Context(), to, maxHistory)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(c.OutOrStdout(), "Rolled back %s %s/%s to version %d\n", j.schema.Kind(), j.namespace, j.name, target.Version)
+			return nil
+		},
+	}
+	cmd.PersistentFlags().IntVar(&to, "to", to,
+		"Version to roll back to. Negative values are relative to the current version, -1 being the version before it.")
+	cmd.PersistentFlags().IntVar(&maxHistory, "max-history", maxHistory, "Number of versions to keep, 0 keeps all versions.")
+	return cmd
+}
@@ -0,0 +1,141 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func setHosts(t *testing.T, j *journal, hosts ...any) *unstructured.Unstructured {
+	t.Helper()
+	ctx := context.Background()
+	vs := j.client.Dynamic().Resource(j.schema.GroupVersionResource()).Namespace(j.namespace)
+	obj, err := j.get(ctx)
+	if err != nil {
+		obj = &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": j.schema.APIVersion(),
+			"kind":       j.schema.Kind(),
+			"metadata":   map[string]any{"name": j.name, "namespace": j.namespace},
+		}}
+		assert.NoError(t, unstructured.SetNestedSlice(obj.Object, hosts, "spec", "hosts"))
+		obj, err = vs.Create(ctx, obj, metav1.CreateOptions{})
+	} else {
+		assert.NoError(t, unstructured.SetNestedSlice(obj.Object, hosts, "spec", "hosts"))
+		obj, err = vs.Update(ctx, obj, metav1.UpdateOptions{})
+	}
+	assert.NoError(t, err)
+	return obj
+}
+
+func hosts(t *testing.T, j *journal) []any {
+	t.Helper()
+	obj, err := j.get(context.Background())
+	assert.NoError(t, err)
+	h, _, _ := unstructured.NestedSlice(obj.Object, "spec", "hosts")
+	return h
+}
+
+func TestHistory(t *testing.T) {
+	ctx := context.Background()
+	j, err := newJournal(kube.NewFakeClient(), "virtual-service", "bookinfo", "default")
+	assert.NoError(t, err)
+
+	for _, h := range []string{"a", "b", "c"} {
+		obj := setHosts(t, j, h)
+		_, recorded, err := j.record(ctx, obj, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, recorded, true)
+		// Recording an unchanged resource keeps the version.
+		_, recorded, err = j.record(ctx, obj, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, recorded, false)
+	}
+
+	// The change to "d" was never recorded, rolling back records it first.
+	setHosts(t, j, "d")
+	target, err := j.rollback(ctx, -1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, target.Version, 3)
+	assert.Equal(t, hosts(t, j), []any{"c"})
+
+	target, err = j.rollback(ctx, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, target.Version, 1)
+	assert.Equal(t, hosts(t, j), []any{"a"})
+
+	entries, _, err := j.entries(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), 6)
+
+	// Rolling back a rollback restores the version before it.
+	_, err = j.rollback(ctx, -1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, hosts(t, j), []any{"c"})
+
+	if _, err := j.rollback(ctx, 42, 0); err == nil || !strings.Contains(err.Error(), "version 42 is not recorded") {
+		t.Fatalf("expected unrecorded version error, got %v", err)
+	}
+
+	obj, err := j.get(ctx)
+	assert.NoError(t, err)
+	entries, _, err = j.entries(ctx)
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, printHistory(&out, entries, obj))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), len(entries)+1)
+	if !strings.HasSuffix(lines[len(lines)-1], "*") {
+		t.Fatalf("latest version not marked current:\n%s", out.String())
+	}
+}
+
+func TestHistoryPruning(t *testing.T) {
+	ctx := context.Background()
+	j, err := newJournal(kube.NewFakeClient(), "VirtualService", "bookinfo", "default")
+	assert.NoError(t, err)
+	for _, h := range []string{"a", "b", "c", "d"} {
+		_, _, err := j.record(ctx, setHosts(t, j, h), 2)
+		assert.NoError(t, err)
+	}
+	entries, cm, err := j.entries(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), 2)
+	assert.Equal(t, entries[0].Version, 3)
+	assert.Equal(t, cm.Name, "istio-config-history-virtualservice-bookinfo")
+
+	if _, err := j.rollback(ctx, -2, 2); err == nil {
+		t.Fatal("expected error rolling back past the pruned versions")
+	}
+}
+
+func TestFindSchema(t *testing.T) {
+	for _, kind := range []string{"virtualservice", "virtual-service", "VirtualService", "virtualservices"} {
+		s, err := findSchema(kind)
+		assert.NoError(t, err)
+		assert.Equal(t, s.Kind(), "VirtualService")
+	}
+	if _, err := findSchema("deployment"); err == nil {
+		t.Fatal("expected unknown type error")
+	}
+}
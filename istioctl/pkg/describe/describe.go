@@ -1022,13 +1022,27 @@ func getIngressIP(service corev1.Service, pod corev1.Pod) string {
 
 func svcDescribeCmd(ctx cli.Context) *cobra.Command {
 	var opts clioptions.ControlPlaneOptions
+	var (
+		showPath   bool
+		pathOutput string
+	)
 	cmd := &cobra.Command{
 		Use:     "service <svc>",
 		Aliases: []string{"svc"},
 		Short:   "Describe services and their Istio configuration [kube-only]",
 		Long: `Analyzes service, pods, DestinationRules, and VirtualServices and reports
-the configuration objects that affect that service.`,
-		Example: `  istioctl experimental describe service productpage`,
+the configuration objects that affect that service.
+
+With --path it explains the full path of requests to the service instead: from each
+Gateway and from the mesh, through the waypoint in ambient mode, the VirtualServices and
+their routes, to the subsets and endpoints, with the policies applying at each hop.`,
+		Example: `  istioctl experimental describe service productpage
+
+  # Explain the path of requests to productpage as a tree
+  istioctl experimental describe service productpage --path
+
+  # Explain the path of requests to productpage as JSON
+  istioctl experimental describe service productpage --path -o json`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				cmd.Println(cmd.UsageString())
@@ -1051,6 +1065,19 @@ the configuration objects that affect that service.`,
 
 			writer := cmd.OutOrStdout()
 
+			if showPath {
+				meshConfig, err := getMeshConfig(client, ctx.IstioNamespace())
+				rootNamespace := ctx.IstioNamespace()
+				if err == nil && meshConfig.GetRootNamespace() != "" {
+					rootNamespace = meshConfig.GetRootNamespace()
+				}
+				path, err := describeTrafficPath(cmd.Context(), client, svc, rootNamespace)
+				if err != nil {
+					return err
+				}
+				return printTrafficPath(writer, path, pathOutput)
+			}
+
 			labels := make([]string, 0)
 			for k, v := range svc.Spec.Selector {
 				labels = append(labels, fmt.Sprintf("%s=%s", k, v))
@@ -1130,6 +1157,9 @@ the configuration objects that affect that service.`,
 
 	cmd.PersistentFlags().BoolVar(&ignoreUnmeshed, "ignoreUnmeshed", false,
 		"Suppress warnings for unmeshed pods")
+	cmd.Flags().BoolVar(&showPath, "path", false,
+		"Explain the path of requests to the service, with the policies applying at each hop")
+	cmd.Flags().StringVarP(&pathOutput, "output", "o", "tree", "Output format of --path, one of tree|json")
 	cmd.Long += "\n\n" + istioctlutil.ExperimentalMsg
 	return cmd
 }
@@ -0,0 +1,512 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/slices"
+)
+
+// meshGateway is the gateway name VirtualServices use to apply to sidecars and waypoints.
+const meshGateway = "mesh"
+
+// pathNode is a hop of the path of requests to a service, with the policies applying at that hop.
+type pathNode struct {
+	Kind      string      `json:"kind"`
+	Name      string      `json:"name,omitempty"`
+	Namespace string      `json:"namespace,omitempty"`
+	Detail    string      `json:"detail,omitempty"`
+	Policies  []string    `json:"policies,omitempty"`
+	Next      []*pathNode `json:"next,omitempty"`
+}
+
+func (n *pathNode) label() string {
+	label := n.Kind
+	if n.Name != "" {
+		label += " " + n.Name
+		if n.Namespace != "" {
+			label += "." + n.Namespace
+		}
+	}
+	if n.Detail != "" {
+		label += " (" + n.Detail + ")"
+	}
+	return label
+}
+
+// pathConfig is the configuration relevant to the path of requests to a service.
+type pathConfig struct {
+	svc              *corev1.Service
+	svcHost          host.Name
+	rootNamespace    string
+	pods             []corev1.Pod
+	virtualServices  []*clientnetworking.VirtualService
+	destinationRule  *clientnetworking.DestinationRule
+	gateways         map[string]*clientnetworking.Gateway
+	authzPolicies    []*clientsecurity.AuthorizationPolicy
+	peerAuthns       []*clientsecurity.PeerAuthentication
+	waypoint         string
+	ambientNamespace bool
+}
+
+// describeTrafficPath explains the path of requests to the service: from the gateways and the mesh, through
+// the waypoint in ambient mode and the VirtualServices, to the subsets and endpoints, with the policies that
+// apply at each hop.
+func describeTrafficPath(ctx context.Context, client kube.CLIClient, svc *corev1.Service, rootNamespace string) (*pathNode, error) {
+	pc, err := loadPathConfig(ctx, client, svc, rootNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return pc.build(), nil
+}
+
+func loadPathConfig(ctx context.Context, client kube.CLIClient, svc *corev1.Service, rootNamespace string) (*pathConfig, error) {
+	pc := &pathConfig{
+		svc:           svc,
+		svcHost:       host.Name(fmt.Sprintf("%s.%s.svc.%s", svc.Name, svc.Namespace, constants.DefaultClusterLocalDomain)),
+		rootNamespace: rootNamespace,
+		gateways:      map[string]*clientnetworking.Gateway{},
+	}
+	if len(svc.Spec.Selector) > 0 {
+		pods, err := client.Kube().CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: klabels.SelectorFromSet(svc.Spec.Selector).String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods of service %s: %v", kname(svc.ObjectMeta), err)
+		}
+		pc.pods = pods.Items
+	}
+
+	vsList, err := client.Istio().NetworkingV1alpha3().VirtualServices(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VirtualServices: %v", err)
+	}
+	for _, vs := range vsList.Items {
+		for _, h := range vs.Spec.Hosts {
+			if pc.resolve(h, vs.Namespace).Matches(pc.svcHost) {
+				pc.virtualServices = append(pc.virtualServices, vs)
+				break
+			}
+		}
+	}
+	sort.Slice(pc.virtualServices, func(i, j int) bool {
+		return qualifiedName(pc.virtualServices[i].ObjectMeta) < qualifiedName(pc.virtualServices[j].ObjectMeta)
+	})
+
+	drList, err := client.Istio().NetworkingV1alpha3().DestinationRules(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DestinationRules: %v", err)
+	}
+	for _, dr := range drList.Items {
+		if pc.resolve(dr.Spec.Host, dr.Namespace) != pc.svcHost {
+			continue
+		}
+		// Like istiod, prefer the DestinationRule in the namespace of the service.
+		if pc.destinationRule == nil || dr.Namespace == svc.Namespace {
+			pc.destinationRule = dr
+		}
+	}
+
+	for _, vs := range pc.virtualServices {
+		for _, gw := range vs.Spec.Gateways {
+			if gw == meshGateway {
+				continue
+			}
+			name, namespace := gatewayRef(gw, vs.Namespace)
+			key := namespace + "/" + name
+			if _, f := pc.gateways[key]; f {
+				continue
+			}
+			g, err := client.Istio().NetworkingV1alpha3().Gateways(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				// Keep the reference, the path shows that the Gateway is missing.
+				g = nil
+			}
+			pc.gateways[key] = g
+		}
+	}
+
+	authz, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AuthorizationPolicies: %v", err)
+	}
+	pc.authzPolicies = authz.Items
+	pas, err := client.Istio().SecurityV1beta1().PeerAuthentications(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PeerAuthentications: %v", err)
+	}
+	pc.peerAuthns = pas.Items
+
+	ns, err := client.Kube().CoreV1().Namespaces().Get(ctx, svc.Namespace, metav1.GetOptions{})
+	if err == nil && ns.Labels[constants.DataplaneMode] == constants.DataplaneModeAmbient {
+		pc.ambientNamespace = true
+		pc.waypoint, err = findWaypoint(ctx, client, svc.Namespace, pc.pods)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// findWaypoint returns the name of the waypoint serving the pods: the waypoint for their service account,
+// or the waypoint of the namespace.
+func findWaypoint(ctx context.Context, client kube.CLIClient, namespace string, pods []corev1.Pod) (string, error) {
+	gws, err := client.GatewayAPI().GatewayV1beta1().Gateways(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list waypoints: %v", err)
+	}
+	accounts := map[string]bool{}
+	for _, pod := range pods {
+		accounts[pod.Spec.ServiceAccountName] = true
+	}
+	namespaceWaypoint := ""
+	for _, gw := range gws.Items {
+		if string(gw.Spec.GatewayClassName) != constants.WaypointGatewayClassName {
+			continue
+		}
+		sa, f := gw.Annotations[constants.WaypointServiceAccount]
+		if !f {
+			namespaceWaypoint = gw.Name
+			continue
+		}
+		if accounts[sa] {
+			return gw.Name, nil
+		}
+	}
+	return namespaceWaypoint, nil
+}
+
+// qualifiedName returns name.namespace, unlike kname the namespace is always included as the path crosses
+// namespaces.
+func qualifiedName(meta metav1.ObjectMeta) string {
+	return meta.Name + "." + meta.Namespace
+}
+
+// gatewayRef splits a reference to a Gateway of a VirtualService into name and namespace.
+func gatewayRef(ref, namespace string) (string, string) {
+	if ns, name, f := strings.Cut(ref, "/"); f {
+		return name, ns
+	}
+	return ref, namespace
+}
+
+func (pc *pathConfig) resolve(h, namespace string) host.Name {
+	return model.ResolveShortnameToFQDN(h, config.Meta{Namespace: namespace, Domain: constants.DefaultClusterLocalDomain})
+}
+
+func (pc *pathConfig) build() *pathNode {
+	root := &pathNode{Kind: "Service", Name: pc.svc.Name, Namespace: pc.svc.Namespace, Detail: servicePorts(pc.svc)}
+
+	gatewayKeys := make([]string, 0, len(pc.gateways))
+	for key := range pc.gateways {
+		gatewayKeys = append(gatewayKeys, key)
+	}
+	sort.Strings(gatewayKeys)
+	for _, key := range gatewayKeys {
+		namespace, name, _ := strings.Cut(key, "/")
+		node := &pathNode{Kind: "Gateway", Name: name, Namespace: namespace}
+		if gw := pc.gateways[key]; gw == nil {
+			node.Detail = "not found"
+		} else {
+			node.Detail = gatewayDetail(gw)
+			node.Policies = pc.workloadPolicies(namespace, []klabels.Set{gw.Spec.Selector})
+		}
+		for _, vs := range pc.virtualServices {
+			if boundTo(vs, name, namespace) {
+				node.Next = append(node.Next, pc.virtualServiceNode(vs))
+			}
+		}
+		root.Next = append(root.Next, node)
+	}
+
+	mesh := &pathNode{Kind: "Mesh", Detail: "requests from sidecars"}
+	if pc.ambientNamespace {
+		mesh.Detail = "requests from ambient and sidecar workloads"
+	}
+	parent := mesh
+	if pc.waypoint != "" {
+		waypoint := &pathNode{
+			Kind:      "Waypoint",
+			Name:      pc.waypoint,
+			Namespace: pc.svc.Namespace,
+			Policies:  pc.workloadPolicies(pc.svc.Namespace, []klabels.Set{{constants.GatewayNameLabel: pc.waypoint}}),
+		}
+		mesh.Next = append(mesh.Next, waypoint)
+		parent = waypoint
+	}
+	for _, vs := range pc.virtualServices {
+		if len(vs.Spec.Gateways) == 0 || slices.Contains(vs.Spec.Gateways, meshGateway) {
+			parent.Next = append(parent.Next, pc.virtualServiceNode(vs))
+		}
+	}
+	if len(parent.Next) == 0 {
+		// Without VirtualService requests are load balanced over all endpoints.
+		parent.Next = append(parent.Next, pc.endpointsNode("", nil))
+	}
+	root.Next = append(root.Next, mesh)
+	return root
+}
+
+func boundTo(vs *clientnetworking.VirtualService, name, namespace string) bool {
+	for _, ref := range vs.Spec.Gateways {
+		if n, ns := gatewayRef(ref, vs.Namespace); n == name && ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func (pc *pathConfig) virtualServiceNode(vs *clientnetworking.VirtualService) *pathNode {
+	node := &pathNode{Kind: "VirtualService", Name: vs.Name, Namespace: vs.Namespace}
+	for i, r := range vs.Spec.Http {
+		route := &pathNode{Kind: "Route", Name: fmt.Sprintf("http[%d]", i), Detail: renderMatches(r.Match)}
+		for _, d := range r.Route {
+			route.Next = append(route.Next, pc.destinationNode(vs, d.Destination, d.Weight))
+		}
+		node.Next = append(node.Next, route)
+	}
+	for i, r := range vs.Spec.Tls {
+		route := &pathNode{Kind: "Route", Name: fmt.Sprintf("tls[%d]", i)}
+		for _, d := range r.Route {
+			route.Next = append(route.Next, pc.destinationNode(vs, d.Destination, d.Weight))
+		}
+		node.Next = append(node.Next, route)
+	}
+	for i, r := range vs.Spec.Tcp {
+		route := &pathNode{Kind: "Route", Name: fmt.Sprintf("tcp[%d]", i)}
+		for _, d := range r.Route {
+			route.Next = append(route.Next, pc.destinationNode(vs, d.Destination, d.Weight))
+		}
+		node.Next = append(node.Next, route)
+	}
+	return node
+}
+
+func (pc *pathConfig) destinationNode(vs *clientnetworking.VirtualService, dest *v1alpha3.Destination, weight int32) *pathNode {
+	if dest == nil {
+		return &pathNode{Kind: "Destination", Detail: "missing"}
+	}
+	var details []string
+	if dest.Subset != "" {
+		details = append(details, "subset "+dest.Subset)
+	}
+	if dest.Port != nil {
+		details = append(details, fmt.Sprintf("port %d", dest.Port.Number))
+	}
+	if weight > 0 {
+		details = append(details, fmt.Sprintf("weight %d%%", weight))
+	}
+	if pc.resolve(dest.Host, vs.Namespace) != pc.svcHost {
+		details = append(details, "another service")
+		return &pathNode{Kind: "Destination", Name: dest.Host, Detail: strings.Join(details, ", ")}
+	}
+	node := &pathNode{Kind: "Destination", Name: dest.Host, Detail: strings.Join(details, ", ")}
+	if dest.Subset == "" {
+		node.Next = append(node.Next, pc.endpointsNode("", nil))
+		return node
+	}
+	var subset *v1alpha3.Subset
+	if pc.destinationRule != nil {
+		for _, s := range pc.destinationRule.Spec.Subsets {
+			if s.Name == dest.Subset {
+				subset = s
+			}
+		}
+	}
+	if subset == nil {
+		node.Next = append(node.Next, &pathNode{Kind: "Subset", Name: dest.Subset, Detail: "not defined by any DestinationRule, requests fail"})
+		return node
+	}
+	node.Next = append(node.Next, pc.endpointsNode(subset.Name, subset.Labels))
+	return node
+}
+
+// endpointsNode lists the endpoints of the service, or of one of its subsets, with the DestinationRule and the
+// policies applying to them.
+func (pc *pathConfig) endpointsNode(subset string, subsetLabels map[string]string) *pathNode {
+	selector := klabels.SelectorFromSet(subsetLabels)
+	var names []string
+	var podsLabels []klabels.Set
+	for _, pod := range pc.pods {
+		if selector.Matches(klabels.Set(pod.Labels)) {
+			names = append(names, pod.Name)
+			podsLabels = append(podsLabels, pod.Labels)
+		}
+	}
+	sort.Strings(names)
+	node := &pathNode{Kind: "Endpoints", Detail: fmt.Sprintf("%d of %d pods", len(names), len(pc.pods))}
+	if len(names) > 0 {
+		node.Name = strings.Join(names, ", ")
+	} else {
+		node.Detail += ", requests fail"
+	}
+	node.Policies = pc.workloadPolicies(pc.svc.Namespace, podsLabels)
+	if dr := pc.destinationRule; dr != nil {
+		policy := fmt.Sprintf("DestinationRule %s", qualifiedName(dr.ObjectMeta))
+		if subset != "" {
+			policy += " subset " + subset
+		}
+		if tp := trafficPolicyDetail(dr, subset); tp != "" {
+			policy += " (" + tp + ")"
+		}
+		node.Policies = append([]string{policy}, node.Policies...)
+	}
+	return node
+}
+
+// workloadPolicies lists the AuthorizationPolicies and PeerAuthentications selecting any of the workloads.
+func (pc *pathConfig) workloadPolicies(namespace string, workloads []klabels.Set) []string {
+	applies := func(ns string, selector map[string]string) bool {
+		if ns != namespace && ns != pc.rootNamespace {
+			return false
+		}
+		if len(selector) == 0 {
+			return true
+		}
+		for _, w := range workloads {
+			if klabels.SelectorFromSet(selector).Matches(w) {
+				return true
+			}
+		}
+		return false
+	}
+	var policies []string
+	for _, p := range pc.authzPolicies {
+		if applies(p.Namespace, p.Spec.GetSelector().GetMatchLabels()) {
+			policies = append(policies, fmt.Sprintf("AuthorizationPolicy %s (%s)", qualifiedName(p.ObjectMeta), p.Spec.Action))
+		}
+	}
+	for _, p := range pc.peerAuthns {
+		if applies(p.Namespace, p.Spec.GetSelector().GetMatchLabels()) {
+			mode := "UNSET"
+			if p.Spec.Mtls != nil {
+				mode = p.Spec.Mtls.Mode.String()
+			}
+			policies = append(policies, fmt.Sprintf("PeerAuthentication %s (mTLS %s)", qualifiedName(p.ObjectMeta), mode))
+		}
+	}
+	sort.Strings(policies)
+	return policies
+}
+
+func trafficPolicyDetail(dr *clientnetworking.DestinationRule, subset string) string {
+	tp := dr.Spec.TrafficPolicy
+	for _, s := range dr.Spec.Subsets {
+		if s.Name == subset && s.TrafficPolicy != nil {
+			tp = s.TrafficPolicy
+		}
+	}
+	if tp == nil {
+		return ""
+	}
+	var details []string
+	if tp.Tls != nil {
+		details = append(details, "TLS "+tp.Tls.Mode.String())
+	}
+	if tp.LoadBalancer != nil {
+		details = append(details, "load balancer")
+	}
+	if tp.ConnectionPool != nil {
+		details = append(details, "connection pool")
+	}
+	if tp.OutlierDetection != nil {
+		details = append(details, "outlier detection")
+	}
+	return strings.Join(details, ", ")
+}
+
+func gatewayDetail(gw *clientnetworking.Gateway) string {
+	var hosts []string
+	for _, s := range gw.Spec.Servers {
+		hosts = append(hosts, s.Hosts...)
+	}
+	selector := klabels.Set(gw.Spec.Selector).String()
+	return fmt.Sprintf("selector %s, hosts %s", selector, strings.Join(hosts, ","))
+}
+
+func servicePorts(svc *corev1.Service) string {
+	ports := make([]string, 0, len(svc.Spec.Ports))
+	for _, p := range svc.Spec.Ports {
+		port := fmt.Sprint(p.Port)
+		if p.Name != "" {
+			port += "/" + p.Name
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		return ""
+	}
+	return "ports " + strings.Join(ports, ", ")
+}
+
+// printTrafficPath prints the path as a tree, or as JSON.
+func printTrafficPath(writer io.Writer, path *pathNode, output string) error {
+	switch output {
+	case "json":
+		out, err := json.MarshalIndent(path, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(out))
+		return err
+	case "", "tree":
+		fmt.Fprintln(writer, path.label())
+		printPolicies(writer, path, "", len(path.Next) > 0)
+		printTree(writer, path.Next, "")
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q, expected tree or json", output)
+	}
+}
+
+func printTree(writer io.Writer, nodes []*pathNode, prefix string) {
+	for i, n := range nodes {
+		connector, childPrefix := "├── ", prefix+"│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", prefix+"    "
+		}
+		fmt.Fprintf(writer, "%s%s%s\n", prefix, connector, n.label())
+		printPolicies(writer, n, childPrefix, len(n.Next) > 0)
+		printTree(writer, n.Next, childPrefix)
+	}
+}
+
+func printPolicies(writer io.Writer, n *pathNode, prefix string, hasNext bool) {
+	bar := "    "
+	if hasNext {
+		bar = "│   "
+	}
+	for _, p := range n.Policies {
+		fmt.Fprintf(writer, "%s%spolicy: %s\n", prefix, bar, p)
+	}
+}
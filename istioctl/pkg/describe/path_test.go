@@ -0,0 +1,183 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gateway "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	v1alpha32 "istio.io/api/networking/v1alpha3"
+	securityapi "istio.io/api/security/v1beta1"
+	typev1beta1 "istio.io/api/type/v1beta1"
+	"istio.io/client-go/pkg/apis/networking/v1alpha3"
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func productPagePod(name, version string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "productpage", "version": version},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "bookinfo-productpage"},
+	}
+}
+
+func pathTestClient(t *testing.T, ambient bool) (kube.CLIClient, *corev1.Service) {
+	t.Helper()
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "productpage", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "productpage"},
+			Ports:    []corev1.ServicePort{{Name: "http", Port: 9080}},
+		},
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	if ambient {
+		ns.Labels = map[string]string{constants.DataplaneMode: constants.DataplaneModeAmbient}
+	}
+	client := kube.NewFakeClient(svc, ns,
+		productPagePod("productpage-v1-a", "v1"), productPagePod("productpage-v1-b", "v1"), productPagePod("productpage-v2-a", "v2"))
+	ctx := context.Background()
+	istio := client.Istio()
+	_, err := istio.NetworkingV1alpha3().Gateways("default").Create(ctx, &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookinfo-gateway", Namespace: "default"},
+		Spec: v1alpha32.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers:  []*v1alpha32.Server{{Hosts: []string{"*"}}},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = istio.NetworkingV1alpha3().VirtualServices("default").Create(ctx, &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Namespace: "default"},
+		Spec: v1alpha32.VirtualService{
+			Hosts:    []string{"productpage"},
+			Gateways: []string{"bookinfo-gateway", "mesh"},
+			Http: []*v1alpha32.HTTPRoute{{
+				Match: []*v1alpha32.HTTPMatchRequest{{Uri: &v1alpha32.StringMatch{MatchType: &v1alpha32.StringMatch_Prefix{Prefix: "/productpage"}}}},
+				Route: []*v1alpha32.HTTPRouteDestination{
+					{Destination: &v1alpha32.Destination{Host: "productpage", Subset: "v1"}, Weight: 80},
+					{Destination: &v1alpha32.Destination{Host: "productpage", Subset: "v2"}, Weight: 20},
+				},
+			}},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = istio.NetworkingV1alpha3().DestinationRules("default").Create(ctx, &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "productpage", Namespace: "default"},
+		Spec: v1alpha32.DestinationRule{
+			Host: "productpage",
+			Subsets: []*v1alpha32.Subset{
+				{Name: "v1", Labels: map[string]string{"version": "v1"}},
+				{
+					Name:          "v2",
+					Labels:        map[string]string{"version": "v2"},
+					TrafficPolicy: &v1alpha32.TrafficPolicy{OutlierDetection: &v1alpha32.OutlierDetection{}},
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = istio.SecurityV1beta1().AuthorizationPolicies("istio-system").Create(ctx, &clientsecurity.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-deny", Namespace: "istio-system"},
+		Spec: securityapi.AuthorizationPolicy{
+			Selector: &typev1beta1.WorkloadSelector{MatchLabels: map[string]string{"istio": "ingressgateway"}},
+			Action:   securityapi.AuthorizationPolicy_DENY,
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = istio.SecurityV1beta1().PeerAuthentications("istio-system").Create(ctx, &clientsecurity.PeerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "istio-system"},
+		Spec: securityapi.PeerAuthentication{
+			Mtls: &securityapi.PeerAuthentication_MutualTLS{Mode: securityapi.PeerAuthentication_MutualTLS_STRICT},
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	return client, svc
+}
+
+func TestDescribeTrafficPath(t *testing.T) {
+	client, svc := pathTestClient(t, false)
+	path, err := describeTrafficPath(context.Background(), client, svc, "istio-system")
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.NoError(t, printTrafficPath(&out, path, "tree"))
+	expected := `Service productpage.default (ports 9080/http)
+├── Gateway bookinfo-gateway.default (selector istio=ingressgateway, hosts *)
+│   │   policy: AuthorizationPolicy ingress-deny.istio-system (DENY)
+│   │   policy: PeerAuthentication default.istio-system (mTLS STRICT)
+│   └── VirtualService bookinfo.default
+│       └── Route http[0] (Match: /productpage*)
+│           ├── Destination productpage (subset v1, weight 80%)
+│           │   └── Endpoints productpage-v1-a, productpage-v1-b (2 of 3 pods)
+│           │           policy: DestinationRule productpage.default subset v1
+│           │           policy: PeerAuthentication default.istio-system (mTLS STRICT)
+│           └── Destination productpage (subset v2, weight 20%)
+│               └── Endpoints productpage-v2-a (1 of 3 pods)
+│                       policy: DestinationRule productpage.default subset v2 (outlier detection)
+│                       policy: PeerAuthentication default.istio-system (mTLS STRICT)
+└── Mesh (requests from sidecars)
+    └── VirtualService bookinfo.default
+        └── Route http[0] (Match: /productpage*)
+            ├── Destination productpage (subset v1, weight 80%)
+            │   └── Endpoints productpage-v1-a, productpage-v1-b (2 of 3 pods)
+            │           policy: DestinationRule productpage.default subset v1
+            │           policy: PeerAuthentication default.istio-system (mTLS STRICT)
+            └── Destination productpage (subset v2, weight 20%)
+                └── Endpoints productpage-v2-a (1 of 3 pods)
+                        policy: DestinationRule productpage.default subset v2 (outlier detection)
+                        policy: PeerAuthentication default.istio-system (mTLS STRICT)
+`
+	assert.Equal(t, out.String(), expected)
+
+	out.Reset()
+	assert.NoError(t, printTrafficPath(&out, path, "json"))
+	decoded := &pathNode{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), decoded))
+	assert.Equal(t, decoded, path)
+}
+
+func TestDescribeTrafficPathWaypoint(t *testing.T) {
+	client, svc := pathTestClient(t, true)
+	_, err := client.GatewayAPI().GatewayV1beta1().Gateways("default").Create(context.Background(), &gateway.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "productpage-waypoint",
+			Namespace:   "default",
+			Annotations: map[string]string{constants.WaypointServiceAccount: "bookinfo-productpage"},
+		},
+		Spec: gateway.GatewaySpec{GatewayClassName: constants.WaypointGatewayClassName},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	path, err := describeTrafficPath(context.Background(), client, svc, "istio-system")
+	assert.NoError(t, err)
+	mesh := path.Next[len(path.Next)-1]
+	assert.Equal(t, mesh.Kind, "Mesh")
+	assert.Equal(t, len(mesh.Next), 1)
+	waypoint := mesh.Next[0]
+	assert.Equal(t, waypoint.label(), "Waypoint productpage-waypoint.default")
+	assert.Equal(t, waypoint.Next[0].Kind, "VirtualService")
+}
@@ -0,0 +1,114 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+)
+
+// Cmd returns the gateway-api command.
+func Cmd(ctx cli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gateway-api",
+		Short: "Helps migrating the ingress configuration to the Kubernetes Gateway API",
+	}
+	cmd.AddCommand(migrateCmd(ctx))
+	return cmd
+}
+
+func migrateCmd(ctx cli.Context) *cobra.Command {
+	var filenames []string
+	var allNamespaces bool
+	cmd := &cobra.Command{
+		Use:   "migrate [-f <file>...]",
+		Short: "Converts Istio Gateways and VirtualServices to Gateway API Gateways and HTTPRoutes",
+		Long: `Converts Istio Gateways, and the HTTP routes of the VirtualServices bound to them, to Gateway API
+Gateways and HTTPRoutes of the same names, printed to stdout to review and apply. The Istio configuration is read
+from the files, or from the namespace of the cluster without files.
+
+What cannot be converted automatically, such as retries, fault injection, the subsets of destination rules
+or the routes of the mesh, is reported on stderr to be migrated by hand. The Istio configuration is not modified,
+so both can serve traffic while the migration is tested, one gateway at a time.`,
+		Example: `  # Convert the Gateways and VirtualServices of a file
+  istioctl x gateway-api migrate -f ingress.yaml > gateway-api.yaml
+
+  # Convert the Gateways and VirtualServices of the bookinfo namespace
+  istioctl x gateway-api migrate -n bookinfo
+
+  # Convert those of all namespaces
+  istioctl x gateway-api migrate -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var in *Input
+			var err error
+			if len(filenames) > 0 {
+				in, err = ReadFiles(filenames)
+			} else {
+				in, err = readCluster(ctx, allNamespaces)
+			}
+			if err != nil {
+				return err
+			}
+			result := Migrate(in.Gateways, in.VirtualServices)
+			if err := result.WriteYAML(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+			if len(result.Findings) > 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d constructs could not be converted automatically:\n", len(result.Findings))
+				for _, f := range result.Findings {
+					fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", f)
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVarP(&filenames, "filename", "f", nil, "Files of the Istio Gateways and VirtualServices to convert")
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Convert the configuration of all namespaces of the cluster")
+	return cmd
+}
+
+// readCluster reads the Gateways and VirtualServices of the namespace of the command, or of all namespaces.
+func readCluster(ctx cli.Context, allNamespaces bool) (*Input, error) {
+	client, err := ctx.CLIClient()
+	if err != nil {
+		return nil, err
+	}
+	ns := ctx.NamespaceOrDefault(ctx.Namespace())
+	if allNamespaces {
+		ns = metav1.NamespaceAll
+	}
+	in := &Input{}
+	gateways, err := client.Istio().NetworkingV1alpha3().Gateways(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Gateways: %v", err)
+	}
+	for i := range gateways.Items {
+		in.Gateways = append(in.Gateways, gateways.Items[i])
+	}
+	virtualServices, err := client.Istio().NetworkingV1alpha3().VirtualServices(ns).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VirtualServices: %v", err)
+	}
+	for i := range virtualServices.Items {
+		in.VirtualServices = append(in.VirtualServices, virtualServices.Items[i])
+	}
+	return in, nil
+}
@@ -0,0 +1,136 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+// Input is the Istio configuration to convert.
+type Input struct {
+	Gateways        []*clientnetworking.Gateway
+	VirtualServices []*clientnetworking.VirtualService
+}
+
+// ReadFiles reads the Gateways and VirtualServices of YAML or JSON files, "-" being the standard input. The other
+// resources of the files are ignored.
+func ReadFiles(filenames []string) (*Input, error) {
+	in := &Input{}
+	for _, filename := range filenames {
+		var r io.Reader
+		if filename == "-" {
+			r = os.Stdin
+		} else {
+			f, err := os.Open(filename)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			r = f
+		}
+		if err := in.read(r); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", filename, err)
+		}
+	}
+	return in, nil
+}
+
+// read reads the Gateways and VirtualServices of a stream of YAML documents or JSON objects.
+func (in *Input) read(r io.Reader) error {
+	decoder := kubeyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var obj map[string]any
+		if err := decoder.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if obj == nil {
+			continue
+		}
+		apiVersion, _ := obj["apiVersion"].(string)
+		if !strings.HasPrefix(apiVersion, gvk.Gateway.Group+"/") {
+			continue
+		}
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		switch obj["kind"] {
+		case gvk.Gateway.Kind:
+			gw := &clientnetworking.Gateway{}
+			if err := json.Unmarshal(raw, gw); err != nil {
+				return err
+			}
+			in.Gateways = append(in.Gateways, gw)
+		case gvk.VirtualService.Kind:
+			vs := &clientnetworking.VirtualService{}
+			if err := json.Unmarshal(raw, vs); err != nil {
+				return err
+			}
+			in.VirtualServices = append(in.VirtualServices, vs)
+		}
+	}
+}
+
+// WriteYAML writes the converted resources as YAML documents, without their empty status.
+func (r *Result) WriteYAML(w io.Writer) error {
+	var objs []any
+	for _, gw := range r.Gateways {
+		objs = append(objs, gw)
+	}
+	for _, route := range r.HTTPRoutes {
+		objs = append(objs, route)
+	}
+	for i, obj := range objs {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return err
+		}
+		delete(doc, "status")
+		if meta, ok := doc["metadata"].(map[string]any); ok {
+			delete(meta, "creationTimestamp")
+		}
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
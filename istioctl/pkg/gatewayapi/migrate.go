@@ -0,0 +1,542 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// gatewayClassName is the GatewayClass of the gateways deployed by Istio.
+	gatewayClassName = "istio"
+	// meshGateway is the reserved gateway name of VirtualServices applying to the sidecars of the mesh.
+	meshGateway = "mesh"
+	// namespaceNameLabel is the label Kubernetes sets on every namespace with its name.
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+)
+
+// Finding is a construct of the Istio configuration which could not be converted automatically, and needs to be
+// migrated by hand.
+type Finding struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Message   string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s/%s: %s", f.Kind, f.Namespace, f.Name, f.Message)
+}
+
+// Result is the Gateway API configuration converted from the Istio configuration.
+type Result struct {
+	Gateways   []*k8s.Gateway
+	HTTPRoutes []*k8s.HTTPRoute
+	// Findings are what could not be converted automatically.
+	Findings []Finding
+}
+
+// converter converts the configuration of an Istio object, recording its findings.
+type converter struct {
+	kind      string
+	name      string
+	namespace string
+	findings  *[]Finding
+}
+
+func (c *converter) report(format string, args ...any) {
+	*c.findings = append(*c.findings, Finding{Kind: c.kind, Name: c.name, Namespace: c.namespace, Message: fmt.Sprintf(format, args...)})
+}
+
+// Migrate converts Istio Gateways and the VirtualServices bound to them to Gateway API Gateways and HTTPRoutes.
+// Routes of the mesh, and TCP and TLS routes, are not converted.
+func Migrate(gateways []*clientnetworking.Gateway, virtualServices []*clientnetworking.VirtualService) *Result {
+	r := &Result{}
+	gateways = append([]*clientnetworking.Gateway{}, gateways...)
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].Namespace+"/"+gateways[i].Name < gateways[j].Namespace+"/"+gateways[j].Name
+	})
+	for _, gw := range gateways {
+		c := &converter{kind: gvk.Gateway.Kind, name: gw.Name, namespace: gw.Namespace, findings: &r.Findings}
+		if converted := c.convertGateway(gw); converted != nil {
+			r.Gateways = append(r.Gateways, converted)
+		}
+	}
+	virtualServices = append([]*clientnetworking.VirtualService{}, virtualServices...)
+	sort.Slice(virtualServices, func(i, j int) bool {
+		return virtualServices[i].Namespace+"/"+virtualServices[i].Name < virtualServices[j].Namespace+"/"+virtualServices[j].Name
+	})
+	for _, vs := range virtualServices {
+		c := &converter{kind: gvk.VirtualService.Kind, name: vs.Name, namespace: vs.Namespace, findings: &r.Findings}
+		if converted := c.convertVirtualService(vs); converted != nil {
+			r.HTTPRoutes = append(r.HTTPRoutes, converted)
+		}
+	}
+	return r
+}
+
+func objectMeta(name, namespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: namespace}
+}
+
+func (c *converter) convertGateway(gw *clientnetworking.Gateway) *k8s.Gateway {
+	out := &k8s.Gateway{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gvk.KubernetesGateway.GroupVersion(),
+			Kind:       gvk.KubernetesGateway.Kind,
+		},
+		ObjectMeta: objectMeta(gw.Name, gw.Namespace),
+		Spec:       k8s.GatewaySpec{GatewayClassName: gatewayClassName},
+	}
+	if len(gw.Spec.Selector) > 0 {
+		c.report("a new gateway is deployed for the Gateway instead of the workloads selected by %v; to keep them, set the "+
+			"addresses of the Gateway to the hostname of their Service", gw.Spec.Selector)
+	}
+	names := sets.New[string]()
+	for i, server := range gw.Spec.Servers {
+		protocol, tls, ok := c.convertServerTLS(i, server)
+		if !ok {
+			continue
+		}
+		for j, host := range server.Hosts {
+			namespace, hostname := splitServerHost(host)
+			l := k8s.Listener{
+				Name:     k8s.SectionName(listenerName(names, server, i, j)),
+				Port:     k8s.PortNumber(server.GetPort().GetNumber()),
+				Protocol: protocol,
+				TLS:      tls,
+			}
+			if hostname != "*" {
+				l.Hostname = (*k8s.Hostname)(ptr.Of(hostname))
+			}
+			l.AllowedRoutes = allowedRoutes(namespace)
+			out.Spec.Listeners = append(out.Spec.Listeners, l)
+		}
+	}
+	if len(out.Spec.Listeners) == 0 {
+		c.report("no server could be converted, the Gateway is skipped")
+		return nil
+	}
+	return out
+}
+
+// convertServerTLS returns the protocol and TLS config of the listeners of a server, or false if it cannot be
+// converted.
+func (c *converter) convertServerTLS(i int, server *networking.Server) (k8s.ProtocolType, *k8s.GatewayTLSConfig, bool) {
+	tls := server.GetTls()
+	protocol := strings.ToUpper(server.GetPort().GetProtocol())
+	if tls == nil {
+		switch protocol {
+		case "HTTP", "HTTP2", "GRPC", "GRPC-WEB":
+			return k8s.HTTPProtocolType, nil, true
+		case "TCP", "MONGO", "REDIS", "MYSQL":
+			return k8s.TCPProtocolType, nil, true
+		case "TLS":
+			return k8s.TLSProtocolType, nil, true
+		}
+		c.report("server %d: protocol %q is not supported by Gateway API listeners", i, server.GetPort().GetProtocol())
+		return "", nil, false
+	}
+	if tls.HttpsRedirect {
+		c.report("server %d: HTTPS redirects are configured with a RequestRedirect filter of an HTTPRoute, with scheme https", i)
+	}
+	if protocol == "HTTP" {
+		// Plain HTTP servers only have TLS settings to redirect to HTTPS.
+		return k8s.HTTPProtocolType, nil, true
+	}
+	if len(tls.CipherSuites) > 0 || tls.MinProtocolVersion != networking.ServerTLSSettings_TLS_AUTO ||
+		tls.MaxProtocolVersion != networking.ServerTLSSettings_TLS_AUTO {
+		c.report("server %d: the TLS versions and cipher suites are not converted", i)
+	}
+	switch tls.Mode {
+	case networking.ServerTLSSettings_PASSTHROUGH:
+		return k8s.TLSProtocolType, &k8s.GatewayTLSConfig{Mode: ptr.Of(k8s.TLSModePassthrough)}, true
+	case networking.ServerTLSSettings_SIMPLE, networking.ServerTLSSettings_MUTUAL:
+		if tls.CredentialName == "" {
+			c.report("server %d: certificates mounted in the gateway are not supported, store them in a Secret of "+
+				"the namespace of the Gateway", i)
+			return "", nil, false
+		}
+		if tls.Mode == networking.ServerTLSSettings_MUTUAL {
+			c.report("server %d: the validation of client certificates of TLS mode MUTUAL is not converted, the "+
+				"listener terminates TLS without requiring client certificates", i)
+		}
+		p := k8s.HTTPSProtocolType
+		if protocol == "TLS" {
+			p = k8s.TLSProtocolType
+		}
+		return p, &k8s.GatewayTLSConfig{
+			Mode: ptr.Of(k8s.TLSModeTerminate),
+			CertificateRefs: []k8s.SecretObjectReference{{
+				Group: ptr.Of(k8s.Group("")),
+				Kind:  ptr.Of(k8s.Kind(gvk.Secret.Kind)),
+				Name:  k8s.ObjectName(tls.CredentialName),
+			}},
+		}, true
+	}
+	c.report("server %d: TLS mode %s is not supported by Gateway API listeners", i, tls.Mode)
+	return "", nil, false
+}
+
+// splitServerHost splits a host of a server into the namespace of the routes it allows and its hostname.
+func splitServerHost(host string) (string, string) {
+	if ns, hostname, f := strings.Cut(host, "/"); f {
+		return ns, hostname
+	}
+	return "*", host
+}
+
+// allowedRoutes returns the routes a listener accepts, from the namespace of the host of a server.
+func allowedRoutes(namespace string) *k8s.AllowedRoutes {
+	switch namespace {
+	case "*":
+		return &k8s.AllowedRoutes{Namespaces: &k8s.RouteNamespaces{From: ptr.Of(k8s.NamespacesFromAll)}}
+	case ".":
+		return &k8s.AllowedRoutes{Namespaces: &k8s.RouteNamespaces{From: ptr.Of(k8s.NamespacesFromSame)}}
+	}
+	return &k8s.AllowedRoutes{Namespaces: &k8s.RouteNamespaces{
+		From:     ptr.Of(k8s.NamespacesFromSelector),
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceNameLabel: namespace}},
+	}}
+}
+
+var invalidListenerChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// listenerName returns a unique name for the listener of the jth host of the ith server, from the name of its port.
+func listenerName(used sets.String, server *networking.Server, i, j int) string {
+	name := strings.Trim(invalidListenerChars.ReplaceAllString(strings.ToLower(server.GetPort().GetName()), "-"), "-")
+	if name == "" {
+		name = fmt.Sprintf("server-%d", i)
+	}
+	if len(server.Hosts) > 1 {
+		name = fmt.Sprintf("%s-%d", name, j)
+	}
+	unique := name
+	for n := 1; used.Contains(unique); n++ {
+		unique = fmt.Sprintf("%s-%d", name, n)
+	}
+	used.Insert(unique)
+	return unique
+}
+
+func (c *converter) convertVirtualService(vs *clientnetworking.VirtualService) *k8s.HTTPRoute {
+	out := &k8s.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gvk.HTTPRoute.GroupVersion(),
+			Kind:       gvk.HTTPRoute.Kind,
+		},
+		ObjectMeta: objectMeta(vs.Name, vs.Namespace),
+	}
+	for _, gw := range vs.Spec.Gateways {
+		if gw == meshGateway {
+			c.report("the routes of the mesh are not converted, only those of the gateways")
+			continue
+		}
+		ns, name, f := strings.Cut(gw, "/")
+		if !f {
+			ns, name = vs.Namespace, gw
+		}
+		ref := k8s.ParentReference{Name: k8s.ObjectName(name)}
+		if ns != vs.Namespace {
+			ref.Namespace = (*k8s.Namespace)(ptr.Of(ns))
+		}
+		out.Spec.ParentRefs = append(out.Spec.ParentRefs, ref)
+	}
+	if len(out.Spec.ParentRefs) == 0 {
+		if len(vs.Spec.Gateways) == 0 {
+			c.report("the VirtualService applies to the mesh only, it is skipped")
+		}
+		return nil
+	}
+	for _, h := range vs.Spec.Hosts {
+		if h != "*" {
+			out.Spec.Hostnames = append(out.Spec.Hostnames, k8s.Hostname(h))
+		}
+	}
+	if len(vs.Spec.Tcp) > 0 {
+		c.report("TCP routes are not converted, migrate them to TCPRoutes")
+	}
+	if len(vs.Spec.Tls) > 0 {
+		c.report("TLS routes are not converted, migrate them to TLSRoutes")
+	}
+	for i, route := range vs.Spec.Http {
+		if rule, ok := c.convertHTTPRoute(i, route, vs.Namespace); ok {
+			out.Spec.Rules = append(out.Spec.Rules, rule)
+		}
+	}
+	if len(out.Spec.Rules) == 0 {
+		c.report("no HTTP route could be converted, the VirtualService is skipped")
+		return nil
+	}
+	return out
+}
+
+// convertHTTPRoute converts the ith HTTP route of a VirtualService to a rule of an HTTPRoute.
+func (c *converter) convertHTTPRoute(i int, route *networking.HTTPRoute, namespace string) (k8s.HTTPRouteRule, bool) {
+	var rule k8s.HTTPRouteRule
+	if route.Delegate != nil {
+		c.report("http route %d: delegation is not supported, merge the delegate VirtualService into the route", i)
+		return rule, false
+	}
+	if route.DirectResponse != nil {
+		c.report("http route %d: direct responses are not supported", i)
+		return rule, false
+	}
+	for _, m := range route.Match {
+		rule.Matches = append(rule.Matches, c.convertMatch(i, m))
+	}
+	for _, d := range route.Route {
+		if ref, ok := c.backendRef(i, d.GetDestination(), namespace); ok {
+			if len(route.Route) > 1 {
+				ref.Weight = ptr.Of(d.Weight)
+			}
+			rule.BackendRefs = append(rule.BackendRefs, k8s.HTTPBackendRef{BackendRef: ref})
+		}
+	}
+	if rd := route.Redirect; rd != nil {
+		redirect := &k8s.HTTPRequestRedirectFilter{}
+		if rd.Uri != "" {
+			redirect.Path = &k8s.HTTPPathModifier{Type: k8s.FullPathHTTPPathModifier, ReplaceFullPath: ptr.Of(rd.Uri)}
+		}
+		if rd.Authority != "" {
+			redirect.Hostname = (*k8s.PreciseHostname)(ptr.Of(rd.Authority))
+		}
+		if rd.Scheme != "" {
+			redirect.Scheme = ptr.Of(rd.Scheme)
+		}
+		if port := rd.GetPort(); port != 0 {
+			redirect.Port = (*k8s.PortNumber)(ptr.Of(int32(port)))
+		}
+		if rd.RedirectCode != 0 {
+			redirect.StatusCode = ptr.Of(int(rd.RedirectCode))
+		}
+		rule.Filters = append(rule.Filters, k8s.HTTPRouteFilter{Type: k8s.HTTPRouteFilterRequestRedirect, RequestRedirect: redirect})
+	}
+	if rw := route.Rewrite; rw != nil {
+		if f, ok := c.convertRewrite(i, rw, route.Match); ok {
+			rule.Filters = append(rule.Filters, f)
+		}
+	}
+	if h := route.Headers; h != nil {
+		if req := headerModifier(h.Request); req != nil {
+			rule.Filters = append(rule.Filters, k8s.HTTPRouteFilter{Type: k8s.HTTPRouteFilterRequestHeaderModifier, RequestHeaderModifier: req})
+		}
+		if resp := headerModifier(h.Response); resp != nil {
+			rule.Filters = append(rule.Filters, k8s.HTTPRouteFilter{Type: k8s.HTTPRouteFilterResponseHeaderModifier, ResponseHeaderModifier: resp})
+		}
+	}
+	if route.Mirror != nil {
+		if ref, ok := c.backendRef(i, route.Mirror, namespace); ok {
+			rule.Filters = append(rule.Filters, k8s.HTTPRouteFilter{
+				Type:          k8s.HTTPRouteFilterRequestMirror,
+				RequestMirror: &k8s.HTTPRequestMirrorFilter{BackendRef: ref.BackendObjectReference},
+			})
+		}
+		if route.MirrorPercentage != nil || route.MirrorPercent != nil {
+			c.report("http route %d: the mirror percentage is not supported, all requests are mirrored", i)
+		}
+	}
+	var unsupported []string
+	for name, set := range map[string]bool{
+		"timeout":    route.Timeout != nil,
+		"retries":    route.Retries != nil,
+		"fault":      route.Fault != nil,
+		"corsPolicy": route.CorsPolicy != nil,
+	} {
+		if set {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		c.report("http route %d: %s not converted", i, strings.Join(unsupported, ", "))
+	}
+	return rule, true
+}
+
+func (c *converter) convertMatch(i int, m *networking.HTTPMatchRequest) k8s.HTTPRouteMatch {
+	var out k8s.HTTPRouteMatch
+	if uri := m.Uri; uri != nil {
+		switch u := uri.MatchType.(type) {
+		case *networking.StringMatch_Exact:
+			out.Path = &k8s.HTTPPathMatch{Type: ptr.Of(k8s.PathMatchExact), Value: ptr.Of(u.Exact)}
+		case *networking.StringMatch_Prefix:
+			out.Path = &k8s.HTTPPathMatch{Type: ptr.Of(k8s.PathMatchPathPrefix), Value: ptr.Of(u.Prefix)}
+		case *networking.StringMatch_Regex:
+			out.Path = &k8s.HTTPPathMatch{Type: ptr.Of(k8s.PathMatchRegularExpression), Value: ptr.Of(u.Regex)}
+		}
+	}
+	for _, name := range sortedKeys(m.Headers) {
+		t, v := stringMatch(m.Headers[name])
+		out.Headers = append(out.Headers, k8s.HTTPHeaderMatch{Type: ptr.Of(k8s.HeaderMatchType(t)), Name: k8s.HTTPHeaderName(name), Value: v})
+	}
+	for _, name := range sortedKeys(m.QueryParams) {
+		t, v := stringMatch(m.QueryParams[name])
+		out.QueryParams = append(out.QueryParams, k8s.HTTPQueryParamMatch{
+			Type: ptr.Of(k8s.QueryParamMatchType(t)), Name: k8s.HTTPHeaderName(name), Value: v,
+		})
+	}
+	if method := m.Method; method != nil {
+		if exact, ok := method.MatchType.(*networking.StringMatch_Exact); ok {
+			out.Method = ptr.Of(k8s.HTTPMethod(strings.ToUpper(exact.Exact)))
+		} else {
+			c.report("http route %d: only exact method matches are supported", i)
+		}
+	}
+	var unsupported []string
+	for name, set := range map[string]bool{
+		"authority":       m.Authority != nil,
+		"port":            m.Port != 0,
+		"sourceLabels":    len(m.SourceLabels) > 0,
+		"gateways":        len(m.Gateways) > 0,
+		"withoutHeaders":  len(m.WithoutHeaders) > 0,
+		"ignoreUriCase":   m.IgnoreUriCase,
+		"scheme":          m.Scheme != nil,
+		"sourceNamespace": m.SourceNamespace != "",
+	} {
+		if set {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		c.report("http route %d: match %s not converted", i, strings.Join(unsupported, ", "))
+	}
+	return out
+}
+
+// stringMatch returns the type and value of the Gateway API match of a string match, prefix matches being
+// converted to regular expressions.
+func stringMatch(m *networking.StringMatch) (string, string) {
+	switch v := m.GetMatchType().(type) {
+	case *networking.StringMatch_Regex:
+		return string(k8s.HeaderMatchRegularExpression), v.Regex
+	case *networking.StringMatch_Prefix:
+		return string(k8s.HeaderMatchRegularExpression), regexp.QuoteMeta(v.Prefix) + ".*"
+	}
+	return string(k8s.HeaderMatchExact), m.GetExact()
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// backendRef converts a destination to a reference to its Service, or to a host of the registry of Istio, such as
+// a ServiceEntry, when it is not a Service of the cluster.
+func (c *converter) backendRef(i int, d *networking.Destination, namespace string) (k8s.BackendRef, bool) {
+	var ref k8s.BackendRef
+	if d.GetSubset() != "" {
+		c.report("http route %d: subset %s of %s is not supported, route to a Service selecting its pods instead", i, d.Subset, d.Host)
+	}
+	port := d.GetPort().GetNumber()
+	if port == 0 {
+		c.report("http route %d: the destination %s has no port, which Gateway API requires", i, d.Host)
+		return ref, false
+	}
+	ref.Port = (*k8s.PortNumber)(ptr.Of(int32(port)))
+	name, ns, ok := serviceOfHost(d.Host, namespace)
+	if !ok {
+		ref.Group = (*k8s.Group)(ptr.Of(gvk.ServiceEntry.Group))
+		ref.Kind = (*k8s.Kind)(ptr.Of("Hostname"))
+		ref.Name = k8s.ObjectName(d.Host)
+		return ref, true
+	}
+	ref.Name = k8s.ObjectName(name)
+	if ns != namespace {
+		ref.Namespace = (*k8s.Namespace)(ptr.Of(ns))
+		c.report("http route %d: the Service %s/%s needs a ReferenceGrant allowing HTTPRoutes of namespace %s", i, ns, name, namespace)
+	}
+	return ref, true
+}
+
+// serviceOfHost returns the name and namespace of the Service of a host, if it is a short name or a name of the
+// cluster domain.
+func serviceOfHost(host, namespace string) (string, string, bool) {
+	parts := strings.Split(host, ".")
+	switch {
+	case len(parts) == 1:
+		return host, namespace, true
+	case len(parts) == 2, len(parts) == 3 && parts[2] == "svc", strings.HasSuffix(host, "."+constants.DefaultClusterLocalDomain):
+		return parts[0], parts[1], true
+	}
+	return "", "", false
+}
+
+// convertRewrite converts a rewrite to a URLRewrite filter. The URI of a rewrite replaces the matched prefix, or the
+// full path of exact matches.
+func (c *converter) convertRewrite(i int, rw *networking.HTTPRewrite, matches []*networking.HTTPMatchRequest) (k8s.HTTPRouteFilter, bool) {
+	f := &k8s.HTTPURLRewriteFilter{}
+	if rw.Authority != "" {
+		f.Hostname = (*k8s.PreciseHostname)(ptr.Of(rw.Authority))
+	}
+	if rw.Uri != "" {
+		prefix, exact := 0, 0
+		for _, m := range matches {
+			switch m.GetUri().GetMatchType().(type) {
+			case *networking.StringMatch_Prefix:
+				prefix++
+			case *networking.StringMatch_Exact:
+				exact++
+			}
+		}
+		switch {
+		case len(matches) > 0 && prefix == len(matches):
+			f.Path = &k8s.HTTPPathModifier{Type: k8s.PrefixMatchHTTPPathModifier, ReplacePrefixMatch: ptr.Of(rw.Uri)}
+		case len(matches) > 0 && exact == len(matches):
+			f.Path = &k8s.HTTPPathModifier{Type: k8s.FullPathHTTPPathModifier, ReplaceFullPath: ptr.Of(rw.Uri)}
+		default:
+			c.report("http route %d: URI rewrites are converted for routes matching only path prefixes or only exact paths", i)
+		}
+	}
+	if rw.UriRegexRewrite != nil {
+		c.report("http route %d: regular expression rewrites are not supported", i)
+	}
+	if f.Hostname == nil && f.Path == nil {
+		return k8s.HTTPRouteFilter{}, false
+	}
+	return k8s.HTTPRouteFilter{Type: k8s.HTTPRouteFilterURLRewrite, URLRewrite: f}, true
+}
+
+func headerModifier(ops *networking.Headers_HeaderOperations) *k8s.HTTPHeaderFilter {
+	if ops == nil || (len(ops.Set) == 0 && len(ops.Add) == 0 && len(ops.Remove) == 0) {
+		return nil
+	}
+	f := &k8s.HTTPHeaderFilter{Remove: ops.Remove}
+	for _, name := range sortedKeys(ops.Set) {
+		f.Set = append(f.Set, k8s.HTTPHeader{Name: k8s.HTTPHeaderName(name), Value: ops.Set[name]})
+	}
+	for _, name := range sortedKeys(ops.Add) {
+		f.Add = append(f.Add, k8s.HTTPHeader{Name: k8s.HTTPHeaderName(name), Value: ops.Add[name]})
+	}
+	return f
+}
@@ -0,0 +1,150 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gatewayapi
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestMigrateFile(t *testing.T) {
+	in, err := ReadFiles([]string{"testdata/bookinfo.yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, len(in.Gateways), 1)
+	assert.Equal(t, len(in.VirtualServices), 1)
+
+	result := Migrate(in.Gateways, in.VirtualServices)
+	var out bytes.Buffer
+	assert.NoError(t, result.WriteYAML(&out))
+	util.CompareContent(t, out.Bytes(), "testdata/bookinfo.golden.yaml")
+
+	var findings []string
+	for _, f := range result.Findings {
+		findings = append(findings, f.String())
+	}
+	for _, want := range []string{"selected by", "TCP routes", "subset v2", "ReferenceGrant", "retries"} {
+		if !strings.Contains(strings.Join(findings, "\n"), want) {
+			t.Errorf("expected a finding about %q, got %v", want, findings)
+		}
+	}
+}
+
+func gateway(name string, servers ...*networking.Server) *clientnetworking.Gateway {
+	return &clientnetworking.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-ingress"},
+		Spec:       networking.Gateway{Servers: servers},
+	}
+}
+
+func TestMigrateListeners(t *testing.T) {
+	cases := []struct {
+		name     string
+		server   *networking.Server
+		protocol k8s.ProtocolType
+		tlsMode  *k8s.TLSModeType
+		finding  string
+	}{
+		{
+			name:     "http",
+			server:   &networking.Server{Port: &networking.Port{Number: 80, Protocol: "HTTP"}, Hosts: []string{"*"}},
+			protocol: k8s.HTTPProtocolType,
+		},
+		{
+			name: "https redirect",
+			server: &networking.Server{
+				Port:  &networking.Port{Number: 80, Protocol: "HTTP"},
+				Hosts: []string{"*"},
+				Tls:   &networking.ServerTLSSettings{HttpsRedirect: true},
+			},
+			protocol: k8s.HTTPProtocolType,
+			finding:  "redirect",
+		},
+		{
+			name: "passthrough",
+			server: &networking.Server{
+				Port:  &networking.Port{Number: 443, Protocol: "TLS"},
+				Hosts: []string{"*"},
+				Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_PASSTHROUGH},
+			},
+			protocol: k8s.TLSProtocolType,
+			tlsMode:  ptr.Of(k8s.TLSModePassthrough),
+		},
+		{
+			name: "mutual",
+			server: &networking.Server{
+				Port:  &networking.Port{Number: 443, Protocol: "HTTPS"},
+				Hosts: []string{"*"},
+				Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_MUTUAL, CredentialName: "cert"},
+			},
+			protocol: k8s.HTTPSProtocolType,
+			tlsMode:  ptr.Of(k8s.TLSModeTerminate),
+			finding:  "MUTUAL",
+		},
+		{
+			name: "istio mutual",
+			server: &networking.Server{
+				Port:  &networking.Port{Number: 15443, Protocol: "TLS"},
+				Hosts: []string{"*"},
+				Tls:   &networking.ServerTLSSettings{Mode: networking.ServerTLSSettings_ISTIO_MUTUAL},
+			},
+			finding: "ISTIO_MUTUAL",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Migrate([]*clientnetworking.Gateway{gateway("gw", tt.server)}, nil)
+			if tt.protocol == "" {
+				assert.Equal(t, len(result.Gateways), 0)
+			} else {
+				listeners := result.Gateways[0].Spec.Listeners
+				assert.Equal(t, len(listeners), 1)
+				assert.Equal(t, listeners[0].Protocol, tt.protocol)
+				if tt.tlsMode != nil {
+					assert.Equal(t, *listeners[0].TLS.Mode, *tt.tlsMode)
+				}
+			}
+			found := false
+			for _, f := range result.Findings {
+				found = found || (tt.finding != "" && strings.Contains(f.Message, tt.finding))
+			}
+			if tt.finding != "" && !found {
+				t.Errorf("expected a finding about %q, got %v", tt.finding, result.Findings)
+			}
+		})
+	}
+}
+
+func TestMigrateMeshRoutes(t *testing.T) {
+	vs := &clientnetworking.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "bookinfo"},
+		Spec: networking.VirtualService{
+			Hosts: []string{"reviews"},
+			Http:  []*networking.HTTPRoute{{Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}}}}},
+		},
+	}
+	result := Migrate(nil, []*clientnetworking.VirtualService{vs})
+	assert.Equal(t, len(result.HTTPRoutes), 0)
+	assert.Equal(t, len(result.Findings), 1)
+}
@@ -15,21 +15,31 @@
 package install
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	"istio.io/istio/istioctl/pkg/bundle"
+	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/completion"
 	"istio.io/istio/istioctl/pkg/util"
 	"istio.io/istio/istioctl/pkg/util/formatting"
 	"istio.io/istio/istioctl/pkg/verifier"
+	"istio.io/istio/istioctl/pkg/writer/output"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/ptr"
 )
 
 // NewVerifyCommand creates a new command for verifying Istio Installation Status
-func NewVerifyCommand() *cobra.Command {
+func NewVerifyCommand(ctx cli.Context) *cobra.Command {
 	var (
 		kubeConfigFlags = &genericclioptions.ConfigFlags{
 			Context:    ptr.Of(""),
@@ -41,19 +51,119 @@ func NewVerifyCommand() *cobra.Command {
 		istioNamespace string
 		opts           clioptions.ControlPlaneOptions
 		manifestsPath  string
+		bundlePath     string
+		checks         []string
+		chartName      string
+		helmChart      verifier.HelmChart
+		valuesFiles    []string
+		setValues      []string
+		syncTimeout    time.Duration
+		probeGateways  bool
+		inspectImages  bool
+		storageTimeout time.Duration
+		smokeImage     string
+		smokeTimeout   time.Duration
+		timeout        time.Duration
+		resTimeout     time.Duration
+		retries        int
+		retryBackoff   time.Duration
+		concurrency    int
+		qps            float32
+		burst          int
+		namespaces     []string
+		outputFormat   string
+		reportSpecs    []string
+		reports        []verifier.Report
+		traceExporter  string
+		failOnName     string
+		failOn         verifier.Severity
+		outputFile     string
+		recordEvents   bool
+		recordHistory  bool
+		showHistory    bool
+		quiet          bool
+		verbose        int
 	)
 	verifyInstallCmd := &cobra.Command{
-		Use:   "verify-install [-f <deployment or istio operator file>] [--revision <revision>]",
+		Use:   "verify-install [-f <deployment or istio operator file>] [--values <helm values file>] [--revision <revision>]",
 		Short: "Verifies Istio Installation Status",
 		Long: `
 verify-install verifies Istio installation status against the installation file
 you specified when you installed Istio. It loops through all the installation
 resources defined in your installation file and reports whether all of them are
-in ready status. It will report failure when any of them are not ready.
+in ready status. It will report failure when any of them are not ready, or when
+the feature flag environment variables of istiod differ from the installation,
+for example because the istiod Deployment was edited manually. When the Istio CNI
+plugin is installed, it also fails when the plugin does not exclude the namespaces of
+the installation from traffic capture, or excludes namespaces of the mesh.
+
+Besides passing or failing, the verification reports a health score from 0 to 100,
+which weighs the result of each check by the importance of what it checked, so that
+installations can be ranked and their degradation followed over time.
+The checks are also counted by Istio component (istiod, gateways, CNI, ztunnel), so
+that a failing component stands out.
+Once Istio is found, the summary also reports how many namespaces and pods are in the
+mesh, by revision, so that it shows whether the data plane adopted the verified control
+plane.
+
+If you installed Istio with Helm, you can pass the Helm values of the base and istiod
+charts with --values and --set instead of an installation file. To verify a gateway
+installed with the gateway chart, pass its values with --chart gateway and set the name
+value to its release name. The gateway is then expected in the Istio namespace.
+
+With --output (or the global --output-format flag) set to json or yaml, every checked
+resource is printed to stdout as a machine-readable list with its kind, name, namespace,
+status and, for failed checks, the reason of the failure, a stable code of the problem
+such as IST-VER-DEPLOY-UNAVAILABLE, a remediation hint and a documentation URL. The
+progress of the verification is then printed to stderr. Otherwise, when stdout is a
+terminal, a progress bar shows how many of the installed resources were checked, and
+which is being checked.
+
+With --output-file <path>, the full report of the verification is also written to a
+file in the output format, with the time of the verification, the verified revision and
+the IstioOperators, files or Helm values the installation was verified against, so that
+verifications leave an audit trail. In the table format, the file holds the output of
+the verification.
+
+With --report junit=<path>, every check is also written to a JUnit XML report as a test
+case, so that CI systems show failed checks in their test dashboards. With --report
+html=<path>, the checks are written to a standalone HTML page, grouped by component, to
+attach to the change ticket of an install or upgrade. With --report sarif=<path>, the
+failed and warned checks are written as a SARIF log, with a rule per kind of checked
+resource, for code scanning and compliance dashboards.
+
+Problems which the mesh works without, such as a missing or unready autoscaler,
+PodDisruptionBudget or addon (Prometheus, Grafana, Kiali, Jaeger, Zipkin, Loki), are
+reported as warnings and do not fail the verification, unless --fail-on is set to
+warning.
+
+With --record-events, a Warning Event describing the failure is recorded on each
+Deployment, DaemonSet and Job which failed its check, and on the IstioOperators the
+installation was verified against, so that the failures show in kubectl describe.
+
+With --record-history, the result of the verification is appended to the history of the
+cluster, kept in the istio-verify-install-history ConfigMap of the Istio namespace with
+the revision, the outcome, the failed resources and the time of the verification.
+With --history, the recorded verifications are shown instead of verifying, with the
+resources which failed since the previous verification of their revision.
+
+With --quiet, only the problems found and the final status are printed. With -v, the
+optional checks which were not selected with --checks are printed too, and with -vv,
+every call to the API server, to debug a slow or failing verification. The results and the reports do not
+depend on the verbosity.
+The markers of the results are colored on terminals unless NO_COLOR is set, and are
+ASCII when TERM is dumb.
 
 If you do not specify an installation it will check for an IstioOperator resource
 and will verify if pods and services defined in it are present.
 
+When the verification fails, the exit code tells automation why:
+  80  resources of the installation are present but not healthy, or a check failed
+  81  resources of the installation are missing from the cluster
+  82  no Istio installation was found
+  83  the API server could not be reached, or denied access to the resources
+  84  the verification, or the check of a resource, did not complete within --timeout or --per-resource-timeout
+
 Note: For verifying whether your cluster is ready for Istio installation, see
 istioctl experimental precheck.
 `,
@@ -67,24 +177,283 @@ istioctl experimental precheck.
   istioctl verify-install --revision <canary>
 
   # Verify the installation of specific revision
-  istioctl verify-install -r 1-9-0`,
+  istioctl verify-install -r 1-9-0
+
+  # Verify a Helm installation against the Helm values it was installed with
+  istioctl verify-install --values istiod-values.yaml --set pilot.replicaCount=2
+
+  # Verify a gateway installed with the gateway chart, as the release istio-ingress
+  istioctl verify-install --chart gateway --values gateway-values.yaml --set name=istio-ingress -i istio-ingress
+
+  # Additionally validate the TLS credentials referenced by Gateways
+  istioctl verify-install --checks gateway-credentials
+
+  # After an upgrade, wait up to a minute for every gateway proxy to ACK the latest config
+  istioctl verify-install --checks gateway-config-sync --gateway-sync-timeout 1m
+
+  # Check that gateway LoadBalancers have an address and are reachable from this machine
+  istioctl verify-install --checks gateway-load-balancer --probe-gateways
+
+  # Check the Services, pods, listeners and autoscaling of every ingress and egress gateway
+  istioctl verify-install --checks gateways
+
+  # Check the istio GatewayClass, and that the Gateway API Gateways are programmed and deployed by istiod
+  istioctl verify-install --checks gateway-api
+
+  # After an upgrade, check the Istio CRDs can still be read in every version they serve
+  istioctl verify-install --checks crd-conversion
+
+  # Check that hostPort traffic of injected pods is captured by their sidecars
+  istioctl verify-install --checks hostport-hairpin
+
+  # Summarize which mesh namespaces deny requests by default with an AuthorizationPolicy
+  istioctl verify-install --checks authorization-posture
+
+  # Warn when istiod requests too little CPU or memory for the number of proxies, endpoints and routing resources
+  istioctl verify-install --checks capacity
+
+  # Check the ClusterRoles of the cluster still allow istiod to list and watch every resource it needs
+  istioctl verify-install --checks istiod-rbac
+
+  # Send a dry-run AdmissionReview to every sidecar injector webhook, through its Service and caBundle as the API
+  # server does, and check it injects a synthetic pod
+  istioctl verify-install --checks injector-webhook
+
+  # Check the validation webhook of istiod denies an invalid VirtualService created with a server-side dry-run,
+  # and report how long the validation takes
+  istioctl verify-install --checks validation-webhook
+
+  # Check the CNI node agent runs on every node, including the nodes its tolerations and node selector exclude
+  istioctl verify-install --checks cni-coverage
+
+  # Warn when the CNI node agent or ztunnel cannot run on the nodes of some architectures, reading the platforms
+  # of their images from the registry
+  istioctl verify-install --checks image-architectures --inspect-images
+
+  # Check ztunnel runs on every node, the CNI node agent redirects ambient pods and the waypoints are available
+  istioctl verify-install --checks ambient
+
+  # Check the tracing collectors, access log services and extension providers of the mesh config, and the HTTP
+  # servers of the modules of WasmPlugins, are Services or ServiceEntries exposing the configured ports
+  istioctl verify-install --checks integrations
+
+  # Warn about kube-proxy modes, such as Cilium's replacement, known to conflict with the interception or DNS capture of the mesh
+  istioctl verify-install --checks kube-proxy-mode
+
+  # Check the east-west gateways and network labels of a multi-network installation
+  istioctl verify-install --checks mesh-networks
+
+  # Check the mesh config, sidecar injector and CA of revision canary were not partially deleted
+  istioctl verify-install --revision canary --checks revision-config
+
+  # Deploy a client and a server into a temporary namespace and check they talk over mTLS
+  istioctl verify-install --checks smoke-test
+
+  # Give up on the verification after five minutes, and on any installed resource which cannot be checked within 30s
+  istioctl verify-install --timeout 5m --per-resource-timeout 30s
+
+  # Go easy on the API server of a shared cluster which throttles the verification
+  istioctl verify-install --qps 2 --burst 5
+
+  # Check the injection coverage and authorization posture of the namespaces of two teams only
+  istioctl verify-install --checks authorization-posture --namespaces team-a,team-b
+
+  # Give the PersistentVolumeClaims of installed addons five minutes to bind
+  istioctl verify-install -f addons.yaml --storage-bind-timeout 5m
+
+  # Print the result of every check as JSON, for scripts
+  istioctl verify-install -o json
+
+  # Keep a JSON report of the verification of an upgrade for the audit trail, in addition to the console output
+  istioctl verify-install -o json --output-file verify-install.json
+
+  # Write a JUnit report of the checks for the test dashboard of a CI pipeline
+  istioctl verify-install --report junit=verify-install.xml
+
+  # Write an HTML report of an upgrade to attach to its change ticket
+  istioctl verify-install --report html=verify-install.html
+
+  # List the reasons of the failed checks in CI
+  istioctl verify-install -o json | jq -r '.items[] | select(.status == "failed") | .reason'
+
+  # List the codes of the failed checks, to look up their runbooks
+  istioctl verify-install -o json | jq -r '.items[] | select(.status == "failed") | .code'
+
+  # Fail the verification on warnings too, such as an unready addon
+  istioctl verify-install --fail-on warning
+
+  # Record the failures as Events on the failed Deployments, DaemonSets, Jobs and IstioOperators
+  istioctl verify-install --record-events
+
+  # Record the result of a verification in the history of the cluster, then show the past results and regressions
+  istioctl verify-install --record-history
+  istioctl verify-install --history
+
+  # Only print the problems and the final status, for instance in a cron job
+  istioctl verify-install --quiet
+
+  # Print the skipped checks and every call to the API server
+  istioctl verify-install -vv
+
+  # Upload the failed checks to a code scanning dashboard
+  istioctl verify-install --report sarif=verify-install.sarif
+
+  # Record the traces of a slow verification in a file, to attach to a support case
+  istioctl verify-install --trace-exporter file=verify-install-trace.json
+
+  # Verify an air-gapped installation against the charts and profiles of an offline bundle
+  istioctl verify-install --bundle istio-1.20.1-bundle.tar.gz`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(filenames) > 0 && opts.Revision != "" {
 				cmd.Println(cmd.UsageString())
 				return fmt.Errorf("supply either a file or revision, but not both")
 			}
+			if len(filenames) > 0 && (len(valuesFiles) > 0 || len(setValues) > 0) {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("supply either a file or Helm values, but not both")
+			}
+			if bundlePath != "" && manifestsPath != "" {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("supply either a bundle or manifests, but not both")
+			}
+			chart, err := verifier.ParseHelmChart(chartName)
+			if err != nil {
+				return err
+			}
+			helmChart = chart
+			severity, err := verifier.ParseSeverity(failOnName)
+			if err != nil {
+				return err
+			}
+			failOn = severity
+			for _, spec := range reportSpecs {
+				r, err := verifier.ParseReport(spec)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, r)
+			}
+			if quiet && verbose > 0 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("supply either --quiet or --verbose, but not both")
+			}
+			if outputFormat != "" {
+				return output.Validate(outputFormat)
+			}
 			return nil
 		},
 		RunE: func(c *cobra.Command, args []string) error {
-			installationVerifier, err := verifier.NewStatusVerifier(istioNamespace, manifestsPath,
-				*kubeConfigFlags.KubeConfig, *kubeConfigFlags.Context, filenames, opts)
+			if traceExporter != "" {
+				exporter, err := verifier.NewTraceExporter(traceExporter)
+				if err != nil {
+					return err
+				}
+				stopTracing := verifier.StartTracing(exporter)
+				defer func() {
+					if err := stopTracing(); err != nil {
+						fmt.Fprintf(c.ErrOrStderr(), "failed to export the traces of the verification: %v\n", err)
+					}
+				}()
+			}
+			if bundlePath != "" {
+				path, _, cleanup, err := bundle.Open(bundlePath)
+				if err != nil {
+					return err
+				}
+				defer cleanup()
+				manifestsPath = path
+			}
+			verifierOpts := []verifier.StatusVerifierOptions{
+				verifier.WithIstioNamespace(istioNamespace),
+				verifier.WithManifestsPath(manifestsPath),
+				verifier.WithKubeConfig(*kubeConfigFlags.KubeConfig, *kubeConfigFlags.Context),
+				verifier.WithFilenames(filenames...),
+				verifier.WithRevision(opts.Revision),
+				verifier.WithChecks(checks...),
+				verifier.WithHelmValues(helmChart, valuesFiles, setValues),
+				verifier.WithGatewaySyncTimeout(syncTimeout),
+				verifier.WithStorageBindTimeout(storageTimeout),
+				verifier.WithSmokeTest(smokeImage, smokeTimeout),
+				verifier.WithTimeout(timeout),
+				verifier.WithPerResourceTimeout(resTimeout),
+				verifier.WithRetry(retries, retryBackoff),
+				verifier.WithConcurrency(concurrency),
+				verifier.WithClientRateLimit(qps, burst),
+				verifier.WithNamespaces(namespaces...),
+				verifier.WithReports(reports...),
+				verifier.WithFailOn(failOn),
+				verifier.WithVerbosity(verbosity(quiet, verbose)),
+			}
+			if recordEvents {
+				verifierOpts = append(verifierOpts, verifier.WithEvents())
+			}
+			if recordHistory {
+				verifierOpts = append(verifierOpts, verifier.WithHistory())
+			}
+			if probeGateways {
+				verifierOpts = append(verifierOpts, verifier.WithGatewayProber(verifier.NewDialProber(verifier.DefaultGatewayProbeTimeout)))
+			}
+			if inspectImages {
+				verifierOpts = append(verifierOpts, verifier.WithImageInspector(verifier.NewRegistryImageInspector()))
+			}
+			if outputFormat == "" {
+				outputFormat = ctx.OutputFormat()
+			}
+			machineReadable := output.IsMachineReadable(outputFormat)
+			var out io.Writer
+			if machineReadable {
+				// Keep stdout for the results.
+				out = c.ErrOrStderr()
+			}
+			var progressBar *verifier.ProgressBar
+			if !machineReadable && isTerminal(c.OutOrStdout()) {
+				progressBar = verifier.NewProgressBar(c.OutOrStdout())
+				out = progressBar
+				verifierOpts = append(verifierOpts, verifier.WithProgress(progressBar))
+			}
+			var consoleOutput bytes.Buffer
+			if outputFile != "" {
+				if out == nil {
+					out = c.OutOrStdout()
+				}
+				out = io.MultiWriter(out, &consoleOutput)
+			}
+			if out != nil {
+				verifierOpts = append(verifierOpts, verifier.WithOutput(out))
+			}
+			if !machineReadable {
+				verifierOpts = append(verifierOpts, verifier.WithTheme(verifier.TerminalTheme(formatting.IstioctlColorDefault(c.OutOrStdout()))))
+			}
+			installationVerifier, err := verifier.NewVerifier(verifierOpts...)
 			if err != nil {
 				return err
 			}
-			if formatting.IstioctlColorDefault(c.OutOrStdout()) {
-				installationVerifier.Colorize()
+			if showHistory {
+				records, err := installationVerifier.History(c.Context())
+				if err != nil {
+					return err
+				}
+				if machineReadable {
+					return output.Print(c.OutOrStdout(), outputFormat, "VerificationHistoryRecord", records)
+				}
+				return verifier.WriteHistory(c.OutOrStdout(), records)
+			}
+			result, verifyErr := installationVerifier.VerifyContext(c.Context())
+			if progressBar != nil {
+				progressBar.Done()
+			}
+			if outputFile != "" {
+				report := verifier.NewVerificationReport(result, verifyErr)
+				if err := report.WriteFile(outputFile, outputFormat, consoleOutput.String()); err != nil {
+					return err
+				}
 			}
-			return installationVerifier.Verify()
+			if machineReadable {
+				if err := output.Print(c.OutOrStdout(), outputFormat, "InstallationCheck", installationVerifier.Results()); err != nil {
+					return err
+				}
+			}
+			return verifyErr
 		},
 	}
 
@@ -94,6 +463,93 @@ istioctl experimental precheck.
 	kubeConfigFlags.AddFlags(flags)
 	flags.StringSliceVarP(&filenames, "filename", "f", filenames, "Istio YAML installation file.")
 	verifyInstallCmd.PersistentFlags().StringVarP(&manifestsPath, "manifests", "d", "", util.ManifestsFlagHelpStr)
+	flags.StringVar(&bundlePath, "bundle", "",
+		"Path of an offline installation bundle created with 'istioctl x bundle create' to verify against its charts and profiles.")
+	flags.StringSliceVar(&valuesFiles, "values", valuesFiles,
+		"Helm values file of the chart selected with --chart to verify the installation against. Can be repeated.")
+	flags.StringArrayVar(&setValues, "set", setValues,
+		"Helm value to verify the installation against, in Helm --set format (e.g. pilot.replicaCount=2). Can be repeated.")
+	flags.StringVar(&chartName, "chart", string(verifier.HelmChartIstiod),
+		fmt.Sprintf("Chart of the Helm values passed with --values and --set: one of %s. istiod stands for the base and istiod charts.",
+			strings.Join(verifier.HelmCharts, "|")))
+	flags.StringSliceVar(&checks, "checks", checks,
+		fmt.Sprintf("Additional checks to run against the cluster. Valid checks are %v", verifier.AvailableChecks()))
+	flags.DurationVar(&syncTimeout, "gateway-sync-timeout", verifier.DefaultGatewaySyncTimeout,
+		"How long the gateway-config-sync check waits for gateway proxies to ACK the latest config.")
+	flags.BoolVar(&probeGateways, "probe-gateways", false,
+		"Make the gateway-load-balancer check open a TCP connection (and TLS handshake on TLS ports) "+
+			"to every gateway LoadBalancer address from this machine.")
+	flags.BoolVar(&inspectImages, "inspect-images", false,
+		"Make the image-architectures check read the platforms of the images of the CNI node agent and ztunnel "+
+			"from their registry, with the credentials of this machine.")
+	flags.DurationVar(&storageTimeout, "storage-bind-timeout", verifier.DefaultStorageBindTimeout,
+		"How long PersistentVolumeClaims of installed components, including StatefulSet volume claims, are given to bind.")
+	flags.StringVar(&smokeImage, "smoke-test-image", verifier.DefaultSmokeTestImage,
+		"Image running the client and the server of the smoke-test check. It must provide fortio.")
+	flags.DurationVar(&smokeTimeout, "smoke-test-timeout", verifier.DefaultSmokeTestTimeout,
+		"How long the smoke-test check is given to deploy, run and report the test.")
+	flags.DurationVar(&timeout, "timeout", 0,
+		"How long the whole verification, including the additional checks, is given to complete. Zero means no limit. "+
+			"Timeouts are reported apart from the other failures.")
+	flags.DurationVar(&resTimeout, "per-resource-timeout", 0,
+		"How long the check of each installed resource, including the API calls reading it, is given to complete. Zero means no limit.")
+	flags.IntVar(&retries, "retries", verifier.DefaultRetries,
+		"How many times the read of an installed resource failing with a transient error, such as throttling, an etcd leader "+
+			"change or a failing webhook, is retried. Zero disables retries.")
+	flags.DurationVar(&retryBackoff, "retry-backoff", verifier.DefaultRetryBackoff,
+		"Delay before the first retry of a read failing with a transient error. It doubles on every retry.")
+	flags.IntVar(&concurrency, "concurrency", verifier.DefaultConcurrency,
+		"How many installed resources are read from the cluster at once. The results are reported in the same order regardless.")
+	flags.Float32Var(&qps, "qps", verifier.DefaultQPS,
+		"Average number of requests per second the verifier sends to the API server. A negative value disables the client-side "+
+			"rate limiting, leaving the API server as the only limit.")
+	flags.IntVar(&burst, "burst", verifier.DefaultBurst,
+		"Number of requests the verifier sends to the API server at once, above the rate of --qps.")
+	flags.StringSliceVar(&namespaces, "namespaces", namespaces,
+		"Namespaces the checks of the data plane, such as the injection coverage, the authorization posture, the hostPort capture "+
+			"and the injection of namespaces, are restricted to. The control plane is verified regardless. Defaults to all namespaces.")
+	flags.StringSliceVar(&reportSpecs, "report", reportSpecs,
+		fmt.Sprintf("Report of the checks to write once verified, as <format>=<path>. Valid formats are %v. Can be repeated.",
+			verifier.ReportFormats()))
+	flags.StringVar(&traceExporter, "trace-exporter", "",
+		fmt.Sprintf("Export OpenTelemetry traces of the verification, with a span per check and per API call. Valid exporters are %v. "+
+			"The otlp exporter is configured with the standard OTEL_EXPORTER_OTLP_* environment variables.", verifier.TraceExporterFormats))
+	flags.StringVar(&failOnName, "fail-on", verifier.SeverityError.String(),
+		fmt.Sprintf("Lowest severity of the problems failing the verification: one of %s.", strings.Join(verifier.Severities, "|")))
+	flags.StringVarP(&outputFormat, "output", "o", "",
+		fmt.Sprintf("Output format of the results: one of %s. Defaults to the global --output-format.", strings.Join(output.Formats, "|")))
+	flags.StringVar(&outputFile, "output-file", "",
+		"Also write the full report of the verification, in the output format, to this file, with the time of the verification "+
+			"and the revision and IstioOperators or files that were verified, for audit trails.")
+	flags.BoolVar(&recordHistory, "record-history", false,
+		fmt.Sprintf("Append the result of the verification to the history kept in the %s ConfigMap of the Istio namespace.", verifier.HistoryConfigMap))
+	flags.BoolVar(&showHistory, "history", false,
+		"Show the results of the past verifications recorded with --record-history and their regressions, instead of verifying.")
+	flags.BoolVar(&recordEvents, "record-events", false,
+		"Record a Warning Event on each Deployment, DaemonSet and Job which failed its check, and on the verified IstioOperators.")
+	flags.BoolVarP(&quiet, "quiet", "q", false, "Only print the problems found and the final status of the verification.")
+	flags.CountVarP(&verbose, "verbose", "v",
+		"Also print the optional checks which were skipped, and with -vv every call to the API server. Can be repeated.")
 	opts.AttachControlPlaneFlags(verifyInstallCmd)
+	completion.RegisterRevisionFlag(verifyInstallCmd, ctx)
+	completion.RegisterNamespaceFlag(verifyInstallCmd, ctx, "istioNamespace")
 	return verifyInstallCmd
 }
+
+// verbosity returns the verbosity of the verification selected by --quiet and -v.
+func verbosity(quiet bool, verbose int) verifier.Verbosity {
+	if quiet {
+		return verifier.VerbosityQuiet
+	}
+	level := verifier.VerbosityNormal + verifier.Verbosity(verbose)
+	if level > verifier.VerbosityDebug {
+		level = verifier.VerbosityDebug
+	}
+	return level
+}
+
+// isTerminal returns whether w is a terminal, to draw a progress bar on.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
+}
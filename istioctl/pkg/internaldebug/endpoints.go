@@ -0,0 +1,292 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldebug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/multixds"
+	"istio.io/istio/istioctl/pkg/util"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+)
+
+const yamlFormat = "yaml"
+
+// debugEndpoint describes an istiod debug endpoint exposed as a structured subcommand.
+type debugEndpoint struct {
+	name  string
+	short string
+	// columns are the dotted paths into each entry rendered by the table output.
+	columns []string
+	// needsProxy is true if the endpoint reports on a single proxy.
+	needsProxy bool
+}
+
+var debugEndpoints = []debugEndpoint{
+	{
+		name:    "configz",
+		short:   "Lists the configuration known to Istiod",
+		columns: []string{"kind", "metadata.namespace", "metadata.name", "metadata.resourceVersion"},
+	},
+	{
+		name:    "registryz",
+		short:   "Lists the services in the Istiod service registry",
+		columns: []string{"hostname", "Attributes.Namespace", "Attributes.ServiceRegistry", "ports"},
+	},
+	{
+		name:       "edsz",
+		short:      "Lists the endpoints Istiod computed for a proxy",
+		columns:    []string{"clusterName", "endpoints"},
+		needsProxy: true,
+	},
+}
+
+type debugEndpointOptions struct {
+	output string
+	fields []string
+	limit  int
+	offset int
+	proxy  string
+}
+
+func debugEndpointCommand(ctx cli.Context, ep debugEndpoint, opts *clioptions.ControlPlaneOptions,
+	centralOpts *clioptions.CentralControlPlaneOptions,
+) *cobra.Command {
+	var o debugEndpointOptions
+	cmd := &cobra.Command{
+		Use:   ep.name,
+		Short: ep.short,
+		Long: fmt.Sprintf(`%s.
+
+The response of the /debug/%s endpoint is fetched from Istiod over XDS and printed as is, as with
+"istioctl x internal-debug %s". With --output, it is optionally paginated and reduced to the selected
+fields, and rendered as a table, JSON or YAML.`, ep.short, ep.name, ep.name),
+		Example: fmt.Sprintf(`  # List the first 20 entries as a table
+  istioctl x internal-debug %[1]s -o table --limit 20
+
+  # Print only selected fields as JSON
+  istioctl x internal-debug %[1]s --fields %[2]s -o json`, ep.name, strings.Join(ep.columns[:2], ",")),
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			if o.output == "" && (len(o.fields) > 0 || o.limit != 0 || o.offset != 0) {
+				return util.CommandParseError{Err: fmt.Errorf("--fields, --limit and --offset require --output")}
+			}
+			if o.limit < 0 || o.offset < 0 {
+				return util.CommandParseError{Err: fmt.Errorf("--limit and --offset must not be negative")}
+			}
+			kubeClient, err := ctx.CLIClientWithRevision(opts.Revision)
+			if err != nil {
+				return err
+			}
+			resourceName := ep.name
+			if ep.needsProxy && o.proxy != "" {
+				resourceName += "?proxyID=" + o.proxy
+			}
+			xdsRequest := discovery.DiscoveryRequest{
+				ResourceNames: []string{resourceName},
+				Node: &core.Node{
+					Id: "debug~0.0.0.0~istioctl~cluster.local",
+				},
+				TypeUrl: v3.DebugType,
+			}
+			xdsResponses, err := multixds.MultiRequestAndProcessXds(internalDebugAllIstiod, &xdsRequest, *centralOpts, ctx.IstioNamespace(),
+				"", "", kubeClient, multixds.DefaultOptions)
+			if err != nil {
+				return err
+			}
+			newResponse, err := HandlerForDebugErrors(kubeClient, centralOpts, c.OutOrStdout(), ctx.IstioNamespace(), xdsResponses)
+			if err != nil {
+				return err
+			}
+			if newResponse != nil {
+				return printDebugResponses(c.OutOrStdout(), newResponse)
+			}
+			if o.output == "" {
+				return printDebugResponses(c.OutOrStdout(), xdsResponses)
+			}
+			entries, err := debugEntries(xdsResponses)
+			if err != nil {
+				return err
+			}
+			entries = paginate(entries, o.offset, o.limit)
+			return printEntries(c.OutOrStdout(), entries, ep.columns, o)
+		},
+	}
+	cmd.Flags().StringVarP(&o.output, "output", "o", "",
+		"Output format: one of table|json|yaml. The response of Istiod is printed as is by default")
+	cmd.Flags().StringSliceVar(&o.fields, "fields", nil,
+		"Comma separated list of dotted field paths to print, e.g. metadata.name. Defaults to all fields, or the summary columns for table output")
+	cmd.Flags().IntVar(&o.limit, "limit", 0, "Maximum number of entries to print. 0 prints all entries")
+	cmd.Flags().IntVar(&o.offset, "offset", 0, "Number of entries to skip before printing")
+	if ep.needsProxy {
+		cmd.Flags().StringVar(&o.proxy, "proxy", "", "The proxy ID to report on, in the form <pod-name>.<namespace>")
+	}
+	return cmd
+}
+
+// debugEntries decodes the JSON documents returned by each Istiod into a single list of entries,
+// ordered by Istiod.
+func debugEntries(responses map[string]*discovery.DiscoveryResponse) ([]map[string]any, error) {
+	istiods := make([]string, 0, len(responses))
+	for istiod := range responses {
+		istiods = append(istiods, istiod)
+	}
+	sort.Strings(istiods)
+	var entries []map[string]any
+	for _, istiod := range istiods {
+		for _, resource := range responses[istiod].Resources {
+			var decoded any
+			if err := json.Unmarshal(resource.Value, &decoded); err != nil {
+				return nil, fmt.Errorf("unexpected response from %s: %s", istiod, strings.TrimSpace(string(resource.Value)))
+			}
+			switch d := decoded.(type) {
+			case []any:
+				for _, e := range d {
+					if m, ok := e.(map[string]any); ok {
+						entries = append(entries, m)
+					}
+				}
+			case map[string]any:
+				entries = append(entries, d)
+			}
+		}
+	}
+	return entries, nil
+}
+
+func paginate(entries []map[string]any, offset, limit int) []map[string]any {
+	if offset >= len(entries) {
+		return nil
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// fieldValue looks up a dotted path in an entry.
+func fieldValue(entry map[string]any, path string) (any, bool) {
+	var cur any = entry
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// selectFields returns a copy of the entry holding only the given dotted paths.
+func selectFields(entry map[string]any, paths []string) map[string]any {
+	out := map[string]any{}
+	for _, path := range paths {
+		val, ok := fieldValue(entry, path)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(path, ".")
+		cur := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := cur[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				cur[part] = next
+			}
+			cur = next
+		}
+		cur[parts[len(parts)-1]] = val
+	}
+	return out
+}
+
+func printEntries(w io.Writer, entries []map[string]any, columns []string, o debugEndpointOptions) error {
+	if len(o.fields) > 0 {
+		columns = o.fields
+		filtered := make([]map[string]any, 0, len(entries))
+		for _, e := range entries {
+			filtered = append(filtered, selectFields(e, o.fields))
+		}
+		entries = filtered
+	}
+	if entries == nil {
+		entries = []map[string]any{}
+	}
+	switch o.output {
+	case util.JSONFormat:
+		out, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, string(out))
+	case yamlFormat:
+		out, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprint(w, string(out))
+	case util.TableFormat:
+		tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+		header := make([]string, 0, len(columns))
+		for _, c := range columns {
+			header = append(header, strings.ToUpper(c))
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, e := range entries {
+			row := make([]string, 0, len(columns))
+			for _, c := range columns {
+				val, _ := fieldValue(e, c)
+				row = append(row, renderCell(val))
+			}
+			_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q, valid formats are table|json|yaml", o.output)
+	}
+	return nil
+}
+
+// renderCell formats a value for table output. Lists are summarized by their length.
+func renderCell(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "-"
+	case string:
+		return v
+	case []any:
+		return fmt.Sprint(len(v))
+	case map[string]any:
+		out, _ := json.Marshal(v)
+		return string(out)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internaldebug
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const configz = `[
+  {"kind": "VirtualService", "metadata": {"name": "reviews", "namespace": "default", "resourceVersion": "10"}},
+  {"kind": "Gateway", "metadata": {"name": "ingress", "namespace": "istio-system", "resourceVersion": "11"}},
+  {"kind": "DestinationRule", "metadata": {"name": "reviews", "namespace": "default", "resourceVersion": "12"}}
+]`
+
+func TestDebugEntries(t *testing.T) {
+	responses := map[string]*discovery.DiscoveryResponse{
+		"istiod-1": {Resources: []*anypb.Any{{Value: []byte(configz)}}},
+	}
+	entries, err := debugEntries(responses)
+	assert.NoError(t, err)
+	assert.Equal(t, len(entries), 3)
+
+	responses["istiod-1"].Resources[0].Value = []byte("404 page not found")
+	_, err = debugEntries(responses)
+	assert.Error(t, err)
+}
+
+func TestPrintEntries(t *testing.T) {
+	entries, err := debugEntries(map[string]*discovery.DiscoveryResponse{
+		"istiod-1": {Resources: []*anypb.Any{{Value: []byte(configz)}}},
+	})
+	assert.NoError(t, err)
+	columns := debugEndpoints[0].columns
+
+	cases := []struct {
+		name string
+		opts debugEndpointOptions
+		want string
+	}{
+		{
+			name: "table",
+			opts: debugEndpointOptions{output: "table", offset: 1, limit: 1},
+			want: `KIND    METADATA.NAMESPACE METADATA.NAME METADATA.RESOURCEVERSION
+Gateway istio-system       ingress       11
+`,
+		},
+		{
+			name: "table with fields",
+			opts: debugEndpointOptions{output: "table", fields: []string{"metadata.name", "spec"}},
+			want: `METADATA.NAME SPEC
+reviews       -
+ingress       -
+reviews       -
+`,
+		},
+		{
+			name: "json with fields",
+			opts: debugEndpointOptions{output: "json", fields: []string{"metadata.name"}, limit: 1},
+			want: `[
+  {
+    "metadata": {
+      "name": "reviews"
+    }
+  }
+]
+`,
+		},
+		{
+			name: "yaml past the end",
+			opts: debugEndpointOptions{output: "yaml", offset: 5},
+			want: "[]\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			page := paginate(entries, c.opts.offset, c.opts.limit)
+			assert.NoError(t, printEntries(&out, page, columns, c.opts))
+			assert.Equal(t, out.String(), c.want)
+		})
+	}
+}
+
+func TestDebugEndpointCommands(t *testing.T) {
+	cmd := DebugCommand(cli.NewFakeContext(nil))
+	for _, ep := range debugEndpoints {
+		sub, _, err := cmd.Find([]string{ep.name})
+		assert.NoError(t, err)
+		// The raw response stays the default, as with the positional form of internal-debug.
+		assert.Equal(t, sub.Flags().Lookup("output").DefValue, "")
+
+		cmd.SetArgs([]string{ep.name, "--limit", "10"})
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "require --output") {
+			t.Fatalf("%s: expected --limit to require --output, got %v", ep.name, err)
+		}
+	}
+}
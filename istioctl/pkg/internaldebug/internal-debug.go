@@ -97,6 +97,9 @@ By default it will use the default serviceAccount from (istio-system) namespace
   # Retrieve sync diff for a single Envoy and Istiod
   istioctl x internal-debug syncz istio-egressgateway-59585c5b9c-ndc59.istio-system
 
+  # List the services in the Istiod registry as a table; see the subcommands for other structured endpoints
+  istioctl x internal-debug registryz -o table
+
   # SECURITY OPTIONS
 
   # Retrieve syncz debug information directly from the control plane, using token security
@@ -141,24 +144,23 @@ By default it will use the default serviceAccount from (istio-system) namespace
 			if err != nil {
 				return err
 			}
-			sw := pilot.XdsStatusWriter{
-				Writer:                 c.OutOrStdout(),
-				InternalDebugAllIstiod: internalDebugAllIstiod,
-			}
 			newResponse, err := HandlerForDebugErrors(kubeClient, &centralOpts, c.OutOrStdout(), ctx.IstioNamespace(), xdsResponses)
 			if err != nil {
 				return err
 			}
 			if newResponse != nil {
-				return sw.PrintAll(newResponse)
+				return printDebugResponses(c.OutOrStdout(), newResponse)
 			}
 
-			return sw.PrintAll(xdsResponses)
+			return printDebugResponses(c.OutOrStdout(), xdsResponses)
 		},
 	}
 
 	opts.AttachControlPlaneFlags(debugCommand)
 	centralOpts.AttachControlPlaneFlags(debugCommand)
+	for _, ep := range debugEndpoints {
+		debugCommand.AddCommand(debugEndpointCommand(ctx, ep, &opts, &centralOpts))
+	}
 	debugCommand.Long += "\n\n" + util.ExperimentalMsg
 	debugCommand.PersistentFlags().BoolVar(&internalDebugAllIstiod, "all", false,
 		"Send the same request to all instances of Istiod. Only applicable for in-cluster deployment.")
@@ -166,3 +168,12 @@ By default it will use the default serviceAccount from (istio-system) namespace
 }
 
 var internalDebugAllIstiod bool
+
+// printDebugResponses prints the responses of Istiod as they are.
+func printDebugResponses(w io.Writer, responses map[string]*discovery.DiscoveryResponse) error {
+	sw := pilot.XdsStatusWriter{
+		Writer:                 w,
+		InternalDebugAllIstiod: internalDebugAllIstiod,
+	}
+	return sw.PrintAll(responses)
+}
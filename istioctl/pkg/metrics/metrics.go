@@ -36,8 +36,9 @@ import (
 )
 
 var (
-	metricsOpts     clioptions.ControlPlaneOptions
-	metricsDuration time.Duration
+	metricsOpts        clioptions.ControlPlaneOptions
+	metricsDuration    time.Duration
+	metricsFromProxies bool
 )
 
 const (
@@ -64,6 +65,12 @@ All metrics returned are from server-side reports. This means that latencies
 and error rates are from the perspective of the service itself and not of an
 individual client (or aggregate set of clients). Rates and latencies are
 calculated over a time interval of 1 minute.
+
+With --from-proxies, the metrics are computed without Prometheus, for clusters
+without a metrics stack: the request metrics of the proxies of the pods of the
+workload are scraped at the start and at the end of the duration, and the
+rates, success rate and latency percentiles are computed from their
+difference. The command waits for the duration before printing them.
 `,
 		Example: `  # Retrieve workload metrics for productpage-v1 workload
   istioctl experimental metrics productpage-v1
@@ -72,7 +79,10 @@ calculated over a time interval of 1 minute.
   istioctl experimental metrics productpage-v1 -d 2m
 
   # Retrieve workload metrics for various services in the different namespaces
-  istioctl experimental metrics productpage-v1.foo reviews-v1.bar ratings-v1.baz`,
+  istioctl experimental metrics productpage-v1.foo reviews-v1.bar ratings-v1.baz
+
+  # Retrieve workload metrics for productpage-v1 from its proxies over 30 seconds, without Prometheus
+  istioctl experimental metrics productpage-v1 --from-proxies -d 30s`,
 		// nolint: goimports
 		Aliases: []string{"m"},
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -89,6 +99,8 @@ calculated over a time interval of 1 minute.
 	}
 
 	cmd.PersistentFlags().DurationVarP(&metricsDuration, "duration", "d", time.Minute, "Duration of query metrics, default value is 1m.")
+	cmd.PersistentFlags().BoolVar(&metricsFromProxies, "from-proxies", false,
+		"Compute the metrics from the proxies of the pods of the workloads over the duration, instead of querying Prometheus.")
 
 	return cmd
 }
@@ -107,6 +119,21 @@ func run(c *cobra.Command, ctx cli.Context, args []string) error {
 		return fmt.Errorf("failed to create k8s client: %v", err)
 	}
 
+	if metricsFromProxies {
+		if metricsDuration <= 0 {
+			return fmt.Errorf("the duration must be positive to compute the metrics from the proxies")
+		}
+		printProxyHeader(c.OutOrStdout())
+		for _, workload := range args {
+			sm, err := proxyMetrics(c.Context(), client, workload, ctx.NamespaceOrDefault(ctx.Namespace()), metricsDuration)
+			if err != nil {
+				return fmt.Errorf("could not build metrics for workload '%s': %v", workload, err)
+			}
+			printProxyMetrics(c.OutOrStdout(), sm)
+		}
+		return nil
+	}
+
 	pl, err := client.PodsForSelector(context.TODO(), ctx.IstioNamespace(), "app=prometheus")
 	if err != nil {
 		return fmt.Errorf("not able to locate Prometheus pod: %v", err)
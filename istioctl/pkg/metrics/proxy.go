@@ -0,0 +1,231 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/log"
+)
+
+// proxyStatsPath is the path of the Envoy admin endpoint serving the stats of the proxy in the Prometheus format,
+// filtered down to the standard request metrics.
+const proxyStatsPath = "stats/prometheus?filter=istio_request"
+
+// proxySample is the sum of the server-side request metrics of the proxies of a workload at a point in time.
+type proxySample struct {
+	requests, errors float64
+	// durations are the cumulative counts of the latency histogram by bucket upper bound, in milliseconds.
+	durations map[float64]float64
+	count     float64
+}
+
+// proxyMetrics computes the metrics of a workload from the proxies of its pods, without Prometheus: the request
+// metrics of every pod are scraped at the start and at the end of the duration, and the rates and latency
+// percentiles are computed from their difference.
+func proxyMetrics(ctx context.Context, client kube.CLIClient, workload, defaultNamespace string, duration time.Duration) (workloadMetrics, error) {
+	wname, wns, found := strings.Cut(workload, ".")
+	if !found {
+		wns = defaultNamespace
+	}
+	pods, err := workloadPods(ctx, client, wname, wns)
+	if err != nil {
+		return workloadMetrics{workload: workload}, err
+	}
+	before, err := scrapeProxies(ctx, client, pods)
+	if err != nil {
+		return workloadMetrics{workload: workload}, err
+	}
+	select {
+	case <-ctx.Done():
+		return workloadMetrics{workload: workload}, ctx.Err()
+	case <-time.After(duration):
+	}
+	after, err := scrapeProxies(ctx, client, pods)
+	if err != nil {
+		return workloadMetrics{workload: workload}, err
+	}
+	return rateMetrics(workload, before, after, duration), nil
+}
+
+// workloadPods returns the running pods of the workload in the namespace.
+func workloadPods(ctx context.Context, client kube.CLIClient, name, namespace string) ([]corev1.Pod, error) {
+	list, err := client.Kube().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of namespace %s: %v", namespace, err)
+	}
+	var pods []corev1.Pod
+	for i := range list.Items {
+		pod := &list.Items[i]
+		deployMeta, _ := kube.GetDeployMetaFromPod(pod)
+		if deployMeta.Name == name && pod.Status.Phase == corev1.PodRunning {
+			pods = append(pods, *pod)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no running pods of workload %s found in namespace %s", name, namespace)
+	}
+	return pods, nil
+}
+
+// scrapeProxies reads the request metrics of the proxy of every pod.
+func scrapeProxies(ctx context.Context, client kube.CLIClient, pods []corev1.Pod) (map[string]proxySample, error) {
+	samples := make(map[string]proxySample, len(pods))
+	for _, pod := range pods {
+		stats, err := client.EnvoyDo(ctx, pod.Name, pod.Namespace, "GET", proxyStatsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the stats of the proxy of pod %s.%s: %v", pod.Name, pod.Namespace, err)
+		}
+		s, err := parseProxyStats(stats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the stats of the proxy of pod %s.%s: %v", pod.Name, pod.Namespace, err)
+		}
+		log.Debugf("pod %s.%s: %d requests, %d errors", pod.Name, pod.Namespace, int(s.requests), int(s.errors))
+		samples[pod.Name] = s
+	}
+	return samples, nil
+}
+
+// parseProxyStats sums the server-side request counters and latency histograms of the stats of a proxy, in the
+// Prometheus text format.
+func parseProxyStats(stats []byte) (proxySample, error) {
+	s := proxySample{durations: map[float64]float64{}}
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(stats))
+	if err != nil {
+		return s, err
+	}
+	for _, m := range families[reqTot].GetMetric() {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if labels["reporter"] != "destination" {
+			continue
+		}
+		value := m.GetCounter().GetValue() + m.GetUntyped().GetValue()
+		s.requests += value
+		if code := labels["response_code"]; len(code) == 3 && (code[0] == '4' || code[0] == '5') {
+			s.errors += value
+		}
+	}
+	for _, m := range families[reqDur].GetMetric() {
+		reporter := ""
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "reporter" {
+				reporter = l.GetValue()
+			}
+		}
+		if reporter != "destination" {
+			continue
+		}
+		h := m.GetHistogram()
+		s.count += float64(h.GetSampleCount())
+		for _, b := range h.GetBucket() {
+			s.durations[b.GetUpperBound()] += float64(b.GetCumulativeCount())
+		}
+	}
+	return s, nil
+}
+
+// rateMetrics computes the metrics of a workload from the samples of its proxies at the start and at the end of
+// the duration. The counters of a proxy which restarted in between start over from zero.
+func rateMetrics(workload string, before, after map[string]proxySample, duration time.Duration) workloadMetrics {
+	delta := proxySample{durations: map[float64]float64{}}
+	for pod, end := range after {
+		start, f := before[pod]
+		if !f || end.requests < start.requests || end.count < start.count {
+			start = proxySample{}
+		}
+		delta.requests += end.requests - start.requests
+		delta.errors += end.errors - start.errors
+		delta.count += end.count - start.count
+		for le, c := range end.durations {
+			delta.durations[le] += c - start.durations[le]
+		}
+	}
+	seconds := duration.Seconds()
+	return workloadMetrics{
+		workload:   workload,
+		totalRPS:   delta.requests / seconds,
+		errorRPS:   delta.errors / seconds,
+		p50Latency: convertLatencyToDuration(histogramQuantile(0.5, delta.durations, delta.count)),
+		p90Latency: convertLatencyToDuration(histogramQuantile(0.9, delta.durations, delta.count)),
+		p99Latency: convertLatencyToDuration(histogramQuantile(0.99, delta.durations, delta.count)),
+	}
+}
+
+// histogramQuantile estimates the quantile of a histogram from its cumulative bucket counts, interpolating
+// linearly within the bucket of the quantile as the histogram_quantile function of Prometheus does.
+func histogramQuantile(q float64, buckets map[float64]float64, count float64) float64 {
+	if count <= 0 {
+		return 0
+	}
+	bounds := make([]float64, 0, len(buckets))
+	for le := range buckets {
+		if !math.IsInf(le, 1) {
+			bounds = append(bounds, le)
+		}
+	}
+	sort.Float64s(bounds)
+	rank := q * count
+	lower, below := 0.0, 0.0
+	for _, le := range bounds {
+		cumulative := buckets[le]
+		if cumulative >= rank {
+			if cumulative == below {
+				return le
+			}
+			return lower + (le-lower)*(rank-below)/(cumulative-below)
+		}
+		lower, below = le, cumulative
+	}
+	// The quantile is in the +Inf bucket, of which the upper bound of the highest finite bucket is the estimate.
+	return lower
+}
+
+func printProxyHeader(writer io.Writer) {
+	w := tabwriter.NewWriter(writer, 14, 1, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(w, "%40s\tTOTAL RPS\tSUCCESS RATE\tP50 LATENCY\tP90 LATENCY\tP99 LATENCY\t\n", "WORKLOAD")
+	_ = w.Flush()
+}
+
+func printProxyMetrics(writer io.Writer, wm workloadMetrics) {
+	successRate := "-"
+	if wm.totalRPS > 0 {
+		successRate = fmt.Sprintf("%.2f%%", 100*(wm.totalRPS-wm.errorRPS)/wm.totalRPS)
+	}
+	w := tabwriter.NewWriter(writer, 14, 1, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintf(w, "%40s\t", wm.workload)
+	_, _ = fmt.Fprintf(w, "%.3f\t", wm.totalRPS)
+	_, _ = fmt.Fprintf(w, "%s\t", successRate)
+	_, _ = fmt.Fprintf(w, "%s\t", wm.p50Latency)
+	_, _ = fmt.Fprintf(w, "%s\t", wm.p90Latency)
+	_, _ = fmt.Fprintf(w, "%s\t\n", wm.p99Latency)
+	_ = w.Flush()
+}
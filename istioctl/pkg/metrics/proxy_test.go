@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/util/testutil"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// proxyStats renders the request metrics of a proxy which served ok and failed requests, with the latencies of the
+// histogram buckets of 10ms, 100ms and 1s.
+func proxyStats(ok, failed int, buckets [3]int) string {
+	total := ok + failed
+	return fmt.Sprintf(`# TYPE istio_requests_total counter
+istio_requests_total{reporter="destination",response_code="200"} %d
+istio_requests_total{reporter="destination",response_code="503"} %d
+istio_requests_total{reporter="source",response_code="500"} 1000
+# TYPE istio_request_duration_milliseconds histogram
+istio_request_duration_milliseconds_bucket{reporter="destination",le="10"} %d
+istio_request_duration_milliseconds_bucket{reporter="destination",le="100"} %d
+istio_request_duration_milliseconds_bucket{reporter="destination",le="1000"} %d
+istio_request_duration_milliseconds_bucket{reporter="destination",le="+Inf"} %d
+istio_request_duration_milliseconds_sum{reporter="destination"} 0
+istio_request_duration_milliseconds_count{reporter="destination"} %d
+istio_request_duration_milliseconds_bucket{reporter="source",le="10"} 0
+istio_request_duration_milliseconds_bucket{reporter="source",le="+Inf"} 1000
+istio_request_duration_milliseconds_sum{reporter="source"} 0
+istio_request_duration_milliseconds_count{reporter="source"} 1000
+`, ok, failed, buckets[0], buckets[1], buckets[2], total, total)
+}
+
+func mustParseProxyStats(t *testing.T, stats string) proxySample {
+	s, err := parseProxyStats([]byte(stats))
+	assert.NoError(t, err)
+	return s
+}
+
+func TestRateMetrics(t *testing.T) {
+	before := map[string]proxySample{
+		"details-1": mustParseProxyStats(t, proxyStats(100, 0, [3]int{50, 100, 100})),
+		"details-2": mustParseProxyStats(t, proxyStats(500, 10, [3]int{500, 510, 510})),
+	}
+	after := map[string]proxySample{
+		// 100 requests since, 60 of them under 10ms and the others under 100ms.
+		"details-1": mustParseProxyStats(t, proxyStats(190, 10, [3]int{110, 200, 200})),
+		// Restarted in between, 100 requests since and 10 of them over 1s.
+		"details-2": mustParseProxyStats(t, proxyStats(90, 10, [3]int{40, 80, 90})),
+	}
+	wm := rateMetrics("details", before, after, 10*time.Second)
+	assert.Equal(t, wm.totalRPS, 20.0)
+	assert.Equal(t, wm.errorRPS, 2.0)
+	// Of the 200 requests, 100 are under 10ms, 180 under 100ms, 190 under 1s.
+	assert.Equal(t, wm.p50Latency, 10*time.Millisecond)
+	assert.Equal(t, wm.p90Latency, 100*time.Millisecond)
+	assert.Equal(t, wm.p99Latency, time.Second)
+
+	var out bytes.Buffer
+	printProxyHeader(&out)
+	printProxyMetrics(&out, wm)
+	expectedOutput := `                                  WORKLOAD     TOTAL RPS  SUCCESS RATE   P50 LATENCY   P90 LATENCY   P99 LATENCY
+                                   details        20.000        90.00%          10ms         100ms            1s
+`
+	assert.Equal(t, out.String(), expectedOutput)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := map[float64]float64{10: 40, 100: 80, 1000: 80}
+	assert.Equal(t, histogramQuantile(0.5, buckets, 0), 0.0)
+	assert.Equal(t, histogramQuantile(0.25, buckets, 80), 5.0)
+	assert.Equal(t, histogramQuantile(0.75, buckets, 80), 55.0)
+	assert.Equal(t, histogramQuantile(0.99, buckets, 100), 1000.0)
+}
+
+func TestMetricsFromProxies(t *testing.T) {
+	ctx := cli.NewFakeContext(&cli.NewFakeContextOption{
+		Namespace: "default",
+		Results: map[string][]byte{
+			"details-v1-5b9c8f7d4-abcde": []byte(proxyStats(10, 0, [3]int{10, 10, 10})),
+		},
+	})
+	client, err := ctx.CLIClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Kube().CoreV1().Pods("default").Create(context.TODO(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         "details-v1-5b9c8f7d4-abcde",
+			GenerateName: "details-v1-5b9c8f7d4-",
+			Namespace:    "default",
+			Labels:       map[string]string{"pod-template-hash": "5b9c8f7d4"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "details-v1-5b9c8f7d4", Controller: ptr.Of(true),
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	cases := []testutil.TestCase{
+		{
+			Args:           strings.Split("details-v1 --from-proxies -d 1ms", " "),
+			ExpectedRegexp: regexp.MustCompile(`details-v1 +0.000 +- +0s +0s +0s`),
+		},
+		{
+			Args:           strings.Split("ratings-v1 --from-proxies -d 1ms", " "),
+			ExpectedRegexp: regexp.MustCompile("no running pods of workload ratings-v1 found in namespace default"),
+			WantException:  true,
+		},
+	}
+	metricCmd := Cmd(ctx)
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.Args, " ")), func(t *testing.T) {
+			testutil.VerifyOutput(t, metricCmd, c)
+		})
+	}
+}
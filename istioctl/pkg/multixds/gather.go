@@ -30,10 +30,12 @@ import (
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
 	xdsstatus "github.com/envoyproxy/go-control-plane/envoy/service/status/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"istio.io/api/label"
+	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/clioptions"
 	"istio.io/istio/istioctl/pkg/xds"
 	pilotxds "istio.io/istio/pilot/pkg/xds"
@@ -323,9 +325,26 @@ func getXdsAddressFromWebhooks(client kube.CLIClient) (*xdsAddr, error) {
 	return nil, errors.New("xds address not found")
 }
 
+// MultiRequestAndProcessXds sends the request to the first or all istiods, reusing their responses if kubeClient
+// caches the debug endpoints of istiod.
 // nolint: lll
 func MultiRequestAndProcessXds(all bool, dr *discovery.DiscoveryRequest, centralOpts clioptions.CentralControlPlaneOptions, istioNamespace string,
 	ns string, serviceAccount string, kubeClient kube.CLIClient, options Options,
+) (map[string]*discovery.DiscoveryResponse, error) {
+	request, err := proto.MarshalOptions{Deterministic: true}.Marshal(dr)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("xds/%t/%s/%s/%s/%s/%t/%d/%s", all, istioNamespace, ns, serviceAccount, centralOpts.Xds,
+		options.XdsViaAgents, options.XdsViaAgentsLimit, request)
+	return cli.Cached(cli.DebugCacheOf(kubeClient), key, func() (map[string]*discovery.DiscoveryResponse, error) {
+		return multiRequestAndProcessXds(all, dr, centralOpts, istioNamespace, ns, serviceAccount, cli.WithoutDebugCache(kubeClient), options)
+	})
+}
+
+// nolint: lll
+func multiRequestAndProcessXds(all bool, dr *discovery.DiscoveryRequest, centralOpts clioptions.CentralControlPlaneOptions, istioNamespace string,
+	ns string, serviceAccount string, kubeClient kube.CLIClient, options Options,
 ) (map[string]*discovery.DiscoveryResponse, error) {
 	// If Central Istiod case, just call it
 	if ns == "" {
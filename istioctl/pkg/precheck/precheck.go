@@ -39,6 +39,7 @@ import (
 	"istio.io/istio/istioctl/pkg/clioptions"
 	"istio.io/istio/istioctl/pkg/install/k8sversion"
 	"istio.io/istio/istioctl/pkg/util/formatting"
+	"istio.io/istio/istioctl/pkg/writer/output"
 	pkgversion "istio.io/istio/operator/pkg/version"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/analysis"
@@ -67,7 +68,10 @@ func Cmd(ctx cli.Context) *cobra.Command {
   istioctl x precheck
 
   # Check only a single namespace
-  istioctl x precheck --namespace default`,
+  istioctl x precheck --namespace default
+
+  # Print the issues found as JSON, for scripts
+  istioctl x precheck --output-format json`,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			cli, err := ctx.CLIClientWithRevision(opts.Revision)
 			if err != nil {
@@ -88,7 +92,13 @@ func Cmd(ctx cli.Context) *cobra.Command {
 			msgs.Add(nsmsgs...)
 			// Print all the messages to stdout in the specified format
 			msgs = msgs.SortedDedupedCopy()
-			output, err := formatting.Print(msgs, formatting.LogFormat, false)
+			if output.IsMachineReadable(ctx.OutputFormat()) {
+				if err := output.Print(cmd.OutOrStdout(), ctx.OutputFormat(), "PrecheckMessage", msgs); err != nil {
+					return err
+				}
+				return precheckError(msgs)
+			}
+			out, err := formatting.Print(msgs, formatting.LogFormat, false)
 			if err != nil {
 				return err
 			}
@@ -96,16 +106,9 @@ func Cmd(ctx cli.Context) *cobra.Command {
 				fmt.Fprintf(cmd.ErrOrStderr(), color.New(color.FgGreen).Sprint("✔")+" No issues found when checking the cluster. Istio is safe to install or upgrade!\n"+
 					"  To get started, check out https://istio.io/latest/docs/setup/getting-started/\n")
 			} else {
-				fmt.Fprintln(cmd.OutOrStdout(), output)
-			}
-			for _, m := range msgs {
-				if m.Type.Level().IsWorseThanOrEqualTo(diag.Warning) {
-					e := fmt.Sprintf(`Issues found when checking the cluster. Istio may not be safe to install or upgrade.
-See %s for more information about causes and resolutions.`, url.ConfigAnalysis)
-					return errors.New(e)
-				}
+				fmt.Fprintln(cmd.OutOrStdout(), out)
 			}
-			return nil
+			return precheckError(msgs)
 		},
 	}
 	cmd.PersistentFlags().BoolVar(&skipControlPlane, "skip-controlplane", false, "skip checking the control plane")
@@ -113,6 +116,18 @@ See %s for more information about causes and resolutions.`, url.ConfigAnalysis)
 	return cmd
 }
 
+// precheckError returns an error if any of the messages is a warning or worse.
+func precheckError(msgs diag.Messages) error {
+	for _, m := range msgs {
+		if m.Type.Level().IsWorseThanOrEqualTo(diag.Warning) {
+			e := fmt.Sprintf(`Issues found when checking the cluster. Istio may not be safe to install or upgrade.
+See %s for more information about causes and resolutions.`, url.ConfigAnalysis)
+			return errors.New(e)
+		}
+	}
+	return nil
+}
+
 func checkControlPlane(ctx cli.Context) (diag.Messages, error) {
 	cli, err := ctx.CLIClient()
 	if err != nil {
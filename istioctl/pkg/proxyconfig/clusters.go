@@ -19,7 +19,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -34,6 +36,9 @@ func ClustersCommand(ctx cli.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remote-clusters",
 		Short: "Lists the remote clusters each istiod instance is connected to.",
+		Long: `Lists the remote clusters each istiod instance is connected to, along with the sync status of
+service discovery for each cluster: the number of endpoints istiod holds for it, when its informers
+completed their initial sync and how many attempts to sync it failed.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			kubeClient, err := ctx.CLIClientWithRevision(opts.Revision)
 			if err != nil {
@@ -55,11 +60,25 @@ func writeMulticlusterStatus(out io.Writer, input map[string][]byte) error {
 	if err != nil {
 		return err
 	}
+	istiods := make([]string, 0, len(statuses))
+	for istiod := range statuses {
+		istiods = append(istiods, istiod)
+	}
+	sort.Strings(istiods)
 	w := new(tabwriter.Writer).Init(out, 0, 8, 5, ' ', 0)
-	_, _ = fmt.Fprintln(w, "NAME\tSECRET\tSTATUS\tISTIOD")
-	for istiod, clusters := range statuses {
+	_, _ = fmt.Fprintln(w, "NAME\tSECRET\tSTATUS\tENDPOINTS\tLAST SYNC\tERRORS\tISTIOD")
+	for _, istiod := range istiods {
+		clusters := statuses[istiod]
+		sort.Slice(clusters, func(i, j int) bool {
+			return clusters[i].ID < clusters[j].ID
+		})
 		for _, c := range clusters {
-			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.ID, c.SecretName, c.SyncStatus, istiod)
+			lastSync := "-"
+			if c.LastSyncTime != nil {
+				lastSync = c.LastSyncTime.UTC().Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\n",
+				c.ID, c.SecretName, c.SyncStatus, c.Endpoints, lastSync, c.SyncErrors, istiod)
 		}
 	}
 	_ = w.Flush()
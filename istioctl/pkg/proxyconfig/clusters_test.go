@@ -0,0 +1,49 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxyconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWriteMulticlusterStatus(t *testing.T) {
+	input := map[string][]byte{
+		"istiod-b": []byte(`[{"id": "cluster-2", "secretName": "istio-system/istio-remote-secret-2", "syncStatus": "timeout",
+"endpoints": 0, "syncErrors": 1}]`),
+		"istiod-a": []byte(`[
+{"id": "cluster-3", "secretName": "istio-system/istio-remote-secret-3", "syncStatus": "syncing", "endpoints": 0, "syncErrors": 0},
+{"id": "cluster-1", "secretName": "istio-system/istio-remote-secret-1", "syncStatus": "synced", "endpoints": 42,
+"lastSyncTime": "2024-01-02T03:04:05Z", "syncErrors": 0}]`),
+	}
+	var out bytes.Buffer
+	assert.NoError(t, writeMulticlusterStatus(&out, input))
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		got = append(got, strings.Join(strings.Fields(line), " "))
+	}
+	assert.Equal(t, got, []string{
+		"NAME SECRET STATUS ENDPOINTS LAST SYNC ERRORS ISTIOD",
+		"cluster-1 istio-system/istio-remote-secret-1 synced 42 2024-01-02T03:04:05Z 0 istiod-a",
+		"cluster-3 istio-system/istio-remote-secret-3 syncing 0 - 0 istiod-a",
+		"cluster-2 istio-system/istio-remote-secret-2 timeout 0 - 1 istiod-b",
+	})
+
+	assert.Error(t, writeMulticlusterStatus(&out, map[string][]byte{"istiod-a": []byte("not json")}))
+}
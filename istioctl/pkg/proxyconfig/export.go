@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxyconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/kube"
+)
+
+const (
+	defaultExportConcurrency = 10
+	defaultExportRate        = 10
+)
+
+// configDumpExport is the export of the config dumps of many pods to a directory.
+type configDumpExport struct {
+	// dir is the directory the dumps are written to, one file per pod.
+	dir string
+	// format is the format of the dumps, json or yaml.
+	format string
+	// concurrency is how many dumps are fetched at once.
+	concurrency int
+	// rate is how many dumps are fetched per second at most, unlimited if not positive.
+	rate float64
+}
+
+// exportConfigDumps fetches the config dumps of the pods in parallel and writes them to the directory of the
+// export, as <name>.<namespace>.json or .yaml. The dumps of all the pods are attempted, so that a pod which
+// cannot be reached does not prevent the export of the others.
+func exportConfigDumps(kubeClient kube.CLIClient, pods []corev1.Pod, e configDumpExport, out io.Writer) error {
+	if err := os.MkdirAll(e.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	limit := rate.Inf
+	if e.rate > 0 {
+		limit = rate.Limit(e.rate)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+	g, ctx := errgroup.WithContext(context.Background())
+	if e.concurrency > 0 {
+		g.SetLimit(e.concurrency)
+	}
+
+	var mu sync.Mutex
+	var errs *multierror.Error
+	written := 0
+	for i := range pods {
+		pod := &pods[i]
+		g.Go(func() error {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			filename, err := e.export(kubeClient, pod)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				return nil
+			}
+			written++
+			fmt.Fprintf(out, "Wrote config dump of %s.%s to %s\n", pod.Name, pod.Namespace, filename)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Exported %d of %d config dumps to %s\n", written, len(pods), e.dir)
+	return errs.ErrorOrNil()
+}
+
+// export writes the config dump of a pod, and returns the file it was written to.
+func (e configDumpExport) export(kubeClient kube.CLIClient, pod *corev1.Pod) (string, error) {
+	var dump []byte
+	var err error
+	if pod.Labels["app"] == "ztunnel" {
+		dump, err = extractZtunnelConfigDump(kubeClient, pod.Name, pod.Namespace)
+	} else {
+		dump, err = extractConfigDump(kubeClient, pod.Name, pod.Namespace, true)
+	}
+	if err != nil {
+		return "", err
+	}
+	ext := jsonOutput
+	if e.format == yamlOutput {
+		if dump, err = yaml.JSONToYAML(dump); err != nil {
+			return "", fmt.Errorf("failed to convert the config dump of %s.%s to YAML: %v", pod.Name, pod.Namespace, err)
+		}
+		ext = yamlOutput
+	}
+	filename := filepath.Join(e.dir, fmt.Sprintf("%s.%s.%s", pod.Name, pod.Namespace, ext))
+	if err := os.WriteFile(filename, dump, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write the config dump of %s.%s: %v", pod.Name, pod.Namespace, err)
+	}
+	return filename, nil
+}
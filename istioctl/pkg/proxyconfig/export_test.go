@@ -0,0 +1,72 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxyconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func exportPod(name string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "foo"}}}
+}
+
+func TestExportConfigDumps(t *testing.T) {
+	client := cli.MockClient{
+		CLIClient: kube.NewFakeClient(),
+		Results: map[string][]byte{
+			"foo-1": []byte(`{"configs":[]}`),
+			"foo-2": []byte(`{"configs":[]}`),
+		},
+	}
+	pods := []corev1.Pod{exportPod("foo-1"), exportPod("foo-2"), exportPod("foo-3")}
+
+	for _, format := range []string{summaryOutput, yamlOutput} {
+		t.Run(format, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "dumps")
+			var out bytes.Buffer
+			err := exportConfigDumps(client, pods, configDumpExport{dir: dir, format: format, concurrency: 2}, &out)
+			if err == nil || !strings.Contains(err.Error(), `pods "foo-3" not found`) {
+				t.Fatalf("expected the error of foo-3, got %v", err)
+			}
+			if !strings.Contains(out.String(), "Exported 2 of 3 config dumps to "+dir) {
+				t.Fatalf("unexpected output:\n%s", out.String())
+			}
+
+			ext, want := jsonOutput, `{"configs":[]}`
+			if format == yamlOutput {
+				ext, want = yamlOutput, "configs: []\n"
+			}
+			for _, name := range []string{"foo-1", "foo-2"} {
+				dump, err := os.ReadFile(filepath.Join(dir, name+".default."+ext))
+				assert.NoError(t, err)
+				assert.Equal(t, string(dump), want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "foo-3.default."+ext)); !os.IsNotExist(err) {
+				t.Fatalf("unexpected dump of foo-3: %v", err)
+			}
+		})
+	}
+}
@@ -16,12 +16,14 @@ package proxyconfig
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
@@ -76,6 +78,10 @@ var (
 
 	labelSelector = ""
 	name          string
+
+	outputDir         string
+	exportConcurrency int
+	exportRate        float64
 )
 
 // Level is an enumeration of all supported log levels.
@@ -410,7 +416,11 @@ func allConfigCmd(ctx cli.Context) *cobra.Command {
 	allConfigCmd := &cobra.Command{
 		Use:   "all [<type>/]<name>[.<namespace>]",
 		Short: "Retrieves all configuration for the Envoy in the specified pod",
-		Long:  `Retrieve information about all configuration for the Envoy instance in the specified pod.`,
+		Long: `Retrieve information about all configuration for the Envoy instance in the specified pod.
+
+With --selector and --output-dir, the full config dumps of all the pods matching the selector are
+fetched in parallel, with at most --concurrency at once and --rate-limit per second, and written to
+one file per pod, to analyze the configuration of a fleet of proxies offline.`,
 		Example: `  # Retrieve summary about all configuration for a given pod from Envoy.
   istioctl proxy-config all <pod-name[.namespace]>
 
@@ -423,12 +433,28 @@ func allConfigCmd(ctx cli.Context) *cobra.Command {
   # Retrieve cluster summary without using Kubernetes API
   ssh <user@hostname> 'curl localhost:15000/config_dump' > envoy-config.json
   istioctl proxy-config all --file envoy-config.json
+
+  # Export the full config dumps of all the pods labeled app=foo to one file per pod, for offline analysis
+  istioctl proxy-config all --selector app=foo --output-dir dumps/
+
+  # Export the config dumps as YAML, fetching at most 2 per second
+  istioctl proxy-config all -l app=foo --output-dir dumps/ -o yaml --rate-limit 2
 `,
 		Aliases: []string{"a"},
 		Args: func(cmd *cobra.Command, args []string) error {
-			if (len(args) == 1) != (configDumpFile == "") {
+			sources := 0
+			for _, set := range []bool{len(args) == 1, configDumpFile != "", labelSelector != ""} {
+				if set {
+					sources++
+				}
+			}
+			if sources != 1 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("all requires pod name, --selector or --file parameter")
+			}
+			if (labelSelector != "") != (outputDir != "") {
 				cmd.Println(cmd.UsageString())
-				return fmt.Errorf("all requires pod name or --file parameter")
+				return fmt.Errorf("--selector and --output-dir must be set together")
 			}
 			return nil
 		},
@@ -437,6 +463,21 @@ func allConfigCmd(ctx cli.Context) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if labelSelector != "" {
+				pods, err := kubeClient.PodsForSelector(context.TODO(), ctx.NamespaceOrDefault(ctx.Namespace()), labelSelector)
+				if err != nil {
+					return fmt.Errorf("not able to locate pod with selector %s: %v", labelSelector, err)
+				}
+				if len(pods.Items) == 0 {
+					return errors.New("no pods found")
+				}
+				return exportConfigDumps(kubeClient, pods.Items, configDumpExport{
+					dir:         outputDir,
+					format:      outputFormat,
+					concurrency: exportConcurrency,
+					rate:        exportRate,
+				}, c.OutOrStdout())
+			}
 			switch outputFormat {
 			case jsonOutput, yamlOutput:
 				var dump []byte
@@ -542,6 +583,16 @@ func allConfigCmd(ctx cli.Context) *cobra.Command {
 		"Envoy config dump file")
 	allConfigCmd.PersistentFlags().BoolVar(&verboseProxyConfig, "verbose", true, "Output more information")
 
+	// bulk export
+	allConfigCmd.PersistentFlags().StringVarP(&labelSelector, "selector", "l", "",
+		"Label selector of the pods to export the config dumps of, requires --output-dir")
+	allConfigCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "",
+		"Directory to write the config dump of each pod selected by --selector to, as JSON unless -o yaml")
+	allConfigCmd.PersistentFlags().IntVar(&exportConcurrency, "concurrency", defaultExportConcurrency,
+		"Number of config dumps fetched in parallel with --selector")
+	allConfigCmd.PersistentFlags().Float64Var(&exportRate, "rate-limit", defaultExportRate,
+		"Maximum number of config dumps fetched per second with --selector, 0 for no limit")
+
 	// cluster
 	allConfigCmd.PersistentFlags().StringVar(&fqdn, "fqdn", "", "Filter clusters by substring of Service FQDN field")
 	allConfigCmd.PersistentFlags().StringVar(&direction, "direction", "", "Filter clusters by Direction field")
@@ -1289,6 +1340,8 @@ func bootstrapConfigCmd(ctx cli.Context) *cobra.Command {
 
 func secretConfigCmd(ctx cli.Context) *cobra.Command {
 	var podName, podNamespace string
+	var showChain bool
+	var warnExpiry time.Duration
 
 	secretConfigCmd := &cobra.Command{
 		Use:   "secret [<type>/]<name>[.<namespace>]",
@@ -1299,7 +1352,13 @@ func secretConfigCmd(ctx cli.Context) *cobra.Command {
 
   # Retrieve full bootstrap without using Kubernetes API
   ssh <user@hostname> 'curl localhost:15000/config_dump' > envoy-config.json
-  istioctl proxy-config secret --file envoy-config.json`,
+  istioctl proxy-config secret --file envoy-config.json
+
+  # Show every certificate of the chains with their SANs, trust domain and remaining lifetime.
+  istioctl proxy-config secret <pod-name[.namespace]> --chain
+
+  # Exit with an error if any certificate expires within 72 hours, e.g. from a cron job.
+  istioctl proxy-config secret <pod-name[.namespace]> --warn-expiry 72h`,
 		Aliases: []string{"secrets", "s"},
 		Args: func(cmd *cobra.Command, args []string) error {
 			if (len(args) == 1) != (configDumpFile == "") {
@@ -1339,14 +1398,22 @@ func secretConfigCmd(ctx cli.Context) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			switch outputFormat {
-			case summaryOutput:
-				return newWriter.PrintSecretSummary()
-			case jsonOutput, yamlOutput:
-				return newWriter.PrintSecretDump(outputFormat)
-			default:
-				return fmt.Errorf("output format %q not supported", outputFormat)
+			if showChain {
+				err = printSecretChains(newWriter, c.OutOrStdout())
+			} else {
+				switch outputFormat {
+				case summaryOutput:
+					err = newWriter.PrintSecretSummary()
+				case jsonOutput, yamlOutput:
+					err = newWriter.PrintSecretDump(outputFormat)
+				default:
+					err = fmt.Errorf("output format %q not supported", outputFormat)
+				}
+			}
+			if err != nil || warnExpiry <= 0 {
+				return err
 			}
+			return checkSecretExpiry(newWriter, c.ErrOrStderr(), warnExpiry)
 		},
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return completion.ValidPodsNameArgs(cmd, ctx, args, toComplete)
@@ -1356,9 +1423,61 @@ func secretConfigCmd(ctx cli.Context) *cobra.Command {
 	secretConfigCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|yaml|short")
 	secretConfigCmd.PersistentFlags().StringVarP(&configDumpFile, "file", "f", "",
 		"Envoy config dump JSON file")
+	secretConfigCmd.PersistentFlags().BoolVar(&showChain, "chain", false,
+		"Decode every certificate of the chain of each secret, showing SANs, trust domain and remaining lifetime")
+	secretConfigCmd.PersistentFlags().DurationVar(&warnExpiry, "warn-expiry", 0,
+		"Warn and exit with an error if any certificate expires within this duration, e.g. 72h")
 	return secretConfigCmd
 }
 
+// printSecretChains prints the decoded certificate chains of the secrets in the output format.
+func printSecretChains(w writer.ConfigDumpWriter, out io.Writer) error {
+	secrets, err := w.SecretItems()
+	if err != nil {
+		return err
+	}
+	switch outputFormat {
+	case summaryOutput:
+		return sdscompare.NewSDSWriter(out, sdscompare.TABULAR).PrintSecretChains(secrets, time.Now())
+	case jsonOutput, yamlOutput:
+		b, err := json.MarshalIndent(secrets, "", "    ")
+		if err != nil {
+			return err
+		}
+		if outputFormat == yamlOutput {
+			if b, err = yaml.JSONToYAML(b); err != nil {
+				return err
+			}
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	default:
+		return fmt.Errorf("output format %q not supported", outputFormat)
+	}
+}
+
+// checkSecretExpiry warns about the certificates of the secrets which expire within the duration,
+// returning an error if there are any.
+func checkSecretExpiry(w writer.ConfigDumpWriter, out io.Writer, within time.Duration) error {
+	secrets, err := w.SecretItems()
+	if err != nil {
+		return err
+	}
+	expiring := sdscompare.ExpiringCerts(secrets, time.Now(), within)
+	for _, c := range expiring {
+		subject := c.Subject
+		if len(c.SANs) > 0 {
+			subject = c.SANs[0]
+		}
+		fmt.Fprintf(out, "WARNING: secret %s: certificate %s (serial %s) expires at %s, remaining: %s\n",
+			c.Secret, subject, c.SerialNumber, c.NotAfter.Format(time.RFC3339), sdscompare.RemainingLifetime(c.Remaining))
+	}
+	if len(expiring) > 0 {
+		return fmt.Errorf("%d certificate(s) expire within %v", len(expiring), within)
+	}
+	return nil
+}
+
 func rootCACompareConfigCmd(ctx cli.Context) *cobra.Command {
 	var podName1, podName2, podNamespace1, podNamespace2 string
 
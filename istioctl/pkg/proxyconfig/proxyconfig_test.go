@@ -176,6 +176,16 @@ func TestProxyConfig(t *testing.T) {
 			expectedString:   `config dump has no configuration type`,
 			wantException:    true,
 		},
+		{ // bulk export without output directory
+			args:           strings.Split("all --selector app=httpbin", " "),
+			expectedString: "--selector and --output-dir must be set together",
+			wantException:  true,
+		},
+		{ // bulk export with a pod name
+			args:           strings.Split("all httpbin-794b576b6c-qx6pf --selector app=httpbin --output-dir dumps", " "),
+			expectedString: "all requires pod name, --selector or --file parameter",
+			wantException:  true,
+		},
 		{ // set ztunnel logging level
 			execClientConfig: loggingConfig,
 			args:             strings.Split("log ztunnel-9v7nw --level debug", " "),
@@ -25,6 +25,7 @@ import (
 
 	"istio.io/istio/istioctl/pkg/cli"
 	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/completion"
 	"istio.io/istio/istioctl/pkg/multixds"
 	"istio.io/istio/istioctl/pkg/util/ambient"
 	"istio.io/istio/istioctl/pkg/writer/compare"
@@ -48,6 +49,9 @@ Retrieves last sent and last acknowledged xDS sync from Istiod to each Envoy in
 		Example: `  # Retrieve sync status for all Envoys in a mesh
   istioctl proxy-status
 
+  # Retrieve sync status for all Envoys in a mesh as JSON
+  istioctl proxy-status --output-format json
+
   # Retrieve sync diff for a single Envoy and Istiod
   istioctl proxy-status istio-egressgateway-59585c5b9c-ndc59.istio-system
 
@@ -60,6 +64,9 @@ Retrieves last sent and last acknowledged xDS sync from Istiod to each Envoy in
   istioctl proxy-status istio-egressgateway-59585c5b9c-ndc59.istio-system --file cd.json
 `,
 		Aliases: []string{"ps"},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.ValidPodsNameArgs(cmd, ctx, args, toComplete)
+		},
 		Args: func(cmd *cobra.Command, args []string) error {
 			if (len(args) == 0) && (configDumpFile != "") {
 				cmd.Println(cmd.UsageString())
@@ -112,12 +119,13 @@ Retrieves last sent and last acknowledged xDS sync from Istiod to each Envoy in
 			if err != nil {
 				return err
 			}
-			sw := pilot.StatusWriter{Writer: c.OutOrStdout()}
+			sw := pilot.StatusWriter{Writer: c.OutOrStdout(), OutputFormat: ctx.OutputFormat()}
 			return sw.PrintAll(statuses)
 		},
 	}
 
 	opts.AttachControlPlaneFlags(statusCmd)
+	completion.RegisterRevisionFlag(statusCmd, ctx)
 	statusCmd.PersistentFlags().StringVarP(&configDumpFile, "file", "f", "",
 		"Envoy config dump JSON file")
 
@@ -231,8 +239,9 @@ Retrieves last sent and last acknowledged xDS sync from Istiod to each Envoy in
 				return err
 			}
 			sw := pilot.XdsStatusWriter{
-				Writer:    c.OutOrStdout(),
-				Namespace: ctx.Namespace(),
+				Writer:       c.OutOrStdout(),
+				Namespace:    ctx.Namespace(),
+				OutputFormat: ctx.OutputFormat(),
 			}
 			return sw.PrintAll(xdsResponses)
 		},
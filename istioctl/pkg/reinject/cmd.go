@@ -0,0 +1,74 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reinject
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/cli"
+)
+
+// Cmd returns the reinject command.
+func Cmd(ctx cli.Context) *cobra.Command {
+	var opts Options
+	cmd := &cobra.Command{
+		Use:   "reinject <kind>/<name>[.<namespace>]...",
+		Short: "Moves workloads between revisions or in and out of the mesh with a rolling restart",
+		Long: `Moves workloads to another control plane revision, into the mesh or out of it, by patching the
+injection labels of their pod templates, and waits for the rollout of each workload to be healthy before
+moving the next one. It stops at the first rollout which fails or times out, leaving the remaining
+workloads untouched.
+
+Deployments, StatefulSets and DaemonSets are supported. Workloads are restarted even when their labels
+already match, which re-injects them with the current sidecar of their revision.`,
+		Example: `  # Move the reviews and ratings deployments to the canary revision, one minute apart
+  istioctl x reinject deployment/reviews deployment/ratings -n bookinfo --revision canary --interval 1m
+
+  # Take a StatefulSet out of the mesh
+  istioctl x reinject sts/db.storage --uninject
+
+  # Print the patches re-injecting two deployments without applying them
+  istioctl x reinject deploy/productpage deploy/details -n bookinfo --inject --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workloads := make([]Workload, 0, len(args))
+			for _, arg := range args {
+				wl, err := ParseWorkload(arg, ctx.NamespaceOrDefault(ctx.Namespace()))
+				if err != nil {
+					return err
+				}
+				workloads = append(workloads, wl)
+			}
+			client, err := ctx.CLIClient()
+			if err != nil {
+				return err
+			}
+			return Roll(context.Background(), client.Kube(), workloads, opts, cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Revision, "revision", "r", "", "Control plane revision to move the workloads to")
+	cmd.Flags().BoolVar(&opts.Inject, "inject", false, "Move the workloads into the mesh, with the revision of their namespace")
+	cmd.Flags().BoolVar(&opts.Uninject, "uninject", false, "Move the workloads out of the mesh")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the patch of each workload without applying it")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 0, "Pause between the rollouts of two workloads")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 5*time.Minute, "How long the rollout of each workload is given to become healthy")
+	return cmd
+}
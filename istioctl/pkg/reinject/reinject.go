@@ -0,0 +1,275 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reinject
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+
+	"istio.io/api/label"
+)
+
+const (
+	// restartedAtAnnotation is the annotation set by 'kubectl rollout restart', so that workloads are restarted even
+	// when their labels already match.
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// injectionLabel is the namespace label selecting the default revision, which takes precedence over the
+	// revision labels of pods.
+	injectionLabel = "istio-injection"
+
+	pollInterval = time.Second
+)
+
+var (
+	deploymentKind  = schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	statefulSetKind = schema.GroupKind{Group: "apps", Kind: "StatefulSet"}
+	daemonSetKind   = schema.GroupKind{Group: "apps", Kind: "DaemonSet"}
+
+	// workloadKinds are the kinds of workloads which can be rolled, by the names and aliases kubectl accepts.
+	workloadKinds = map[string]schema.GroupKind{
+		"deployment":   deploymentKind,
+		"deployments":  deploymentKind,
+		"deploy":       deploymentKind,
+		"statefulset":  statefulSetKind,
+		"statefulsets": statefulSetKind,
+		"sts":          statefulSetKind,
+		"daemonset":    daemonSetKind,
+		"daemonsets":   daemonSetKind,
+		"ds":           daemonSetKind,
+	}
+)
+
+// Options select where the workloads are moved and how fast.
+type Options struct {
+	// Revision moves the workloads to the control plane revision.
+	Revision string
+	// Inject moves the workloads into the mesh, with the revision selected by their namespace.
+	Inject bool
+	// Uninject moves the workloads out of the mesh.
+	Uninject bool
+	// DryRun prints the patch of each workload without applying it.
+	DryRun bool
+	// Interval is the pause between the rollouts of two workloads.
+	Interval time.Duration
+	// Timeout bounds the time the rollout of each workload is given to become healthy.
+	Timeout time.Duration
+}
+
+// Validate checks that exactly one destination is selected.
+func (o Options) Validate() error {
+	selected := 0
+	for _, s := range []bool{o.Revision != "", o.Inject, o.Uninject} {
+		if s {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return fmt.Errorf("supply exactly one of --revision, --inject or --uninject")
+	}
+	return nil
+}
+
+// Workload is a workload to roll.
+type Workload struct {
+	Kind      schema.GroupKind
+	Name      string
+	Namespace string
+}
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s/%s.%s", strings.ToLower(w.Kind.Kind), w.Name, w.Namespace)
+}
+
+// ParseWorkload parses a workload of the form <kind>/<name>[.<namespace>].
+func ParseWorkload(arg, defaultNamespace string) (Workload, error) {
+	kind, name, ok := strings.Cut(arg, "/")
+	if !ok || name == "" {
+		return Workload{}, fmt.Errorf("invalid workload %q, expected <kind>/<name>[.<namespace>]", arg)
+	}
+	gk, ok := workloadKinds[strings.ToLower(kind)]
+	if !ok {
+		return Workload{}, fmt.Errorf("unsupported workload kind %q, expected a deployment, statefulset or daemonset", kind)
+	}
+	namespace := defaultNamespace
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name, namespace = name[:i], name[i+1:]
+	}
+	return Workload{Kind: gk, Name: name, Namespace: namespace}, nil
+}
+
+// templatePatch returns the merge patch of the pod template moving the pods to their destination and restarting
+// them. A null label is removed.
+func (o Options) templatePatch(now time.Time) ([]byte, error) {
+	labels := map[string]any{}
+	switch {
+	case o.Revision != "":
+		labels[label.IoIstioRev.Name] = o.Revision
+		labels[label.SidecarInject.Name] = nil
+	case o.Inject:
+		labels[label.SidecarInject.Name] = "true"
+	case o.Uninject:
+		labels[label.SidecarInject.Name] = "false"
+		labels[label.IoIstioRev.Name] = nil
+	}
+	return json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"labels":      labels,
+					"annotations": map[string]string{restartedAtAnnotation: now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+}
+
+// namespaceWarning returns why the labels of the namespace defeat the move, if they do.
+func (o Options) namespaceWarning(nsLabels map[string]string) string {
+	switch {
+	case o.Revision != "" && nsLabels[injectionLabel] != "":
+		return fmt.Sprintf("the %s label of the namespace takes precedence over the revision of the pods", injectionLabel)
+	case o.Inject && nsLabels[injectionLabel] == "disabled":
+		return fmt.Sprintf("injection is disabled by the %s label of the namespace", injectionLabel)
+	}
+	return ""
+}
+
+// Roll moves the workloads one at a time, waiting for the rollout of each to be healthy before the next one. It
+// stops at the first workload whose rollout fails or does not complete in time, leaving the others untouched.
+func Roll(ctx context.Context, client kubernetes.Interface, workloads []Workload, opts Options, w io.Writer) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	for i, wl := range workloads {
+		if i > 0 && !opts.DryRun && opts.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Interval):
+			}
+		}
+		if err := roll(ctx, client, wl, opts, w); err != nil {
+			return fmt.Errorf("%v: %v", wl, err)
+		}
+	}
+	return nil
+}
+
+func roll(ctx context.Context, client kubernetes.Interface, wl Workload, opts Options, w io.Writer) error {
+	if _, err := getWorkload(ctx, client, wl); err != nil {
+		return err
+	}
+	ns, err := client.CoreV1().Namespaces().Get(ctx, wl.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if warning := opts.namespaceWarning(ns.Labels); warning != "" {
+		fmt.Fprintf(w, "Warning: %v: %s\n", wl, warning)
+	}
+	patch, err := opts.templatePatch(time.Now())
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		fmt.Fprintf(w, "Would patch %v with %s\n", wl, patch)
+		return nil
+	}
+	if err := patchWorkload(ctx, client, wl, patch); err != nil {
+		return fmt.Errorf("failed to patch: %v", err)
+	}
+	fmt.Fprintf(w, "Patched %v, waiting for its rollout\n", wl)
+	return waitForRollout(ctx, client, wl, opts.Timeout, w)
+}
+
+func getWorkload(ctx context.Context, client kubernetes.Interface, wl Workload) (runtime.Object, error) {
+	apps := client.AppsV1()
+	switch wl.Kind {
+	case deploymentKind:
+		return apps.Deployments(wl.Namespace).Get(ctx, wl.Name, metav1.GetOptions{})
+	case statefulSetKind:
+		return apps.StatefulSets(wl.Namespace).Get(ctx, wl.Name, metav1.GetOptions{})
+	case daemonSetKind:
+		return apps.DaemonSets(wl.Namespace).Get(ctx, wl.Name, metav1.GetOptions{})
+	}
+	return nil, fmt.Errorf("unsupported workload kind %v", wl.Kind)
+}
+
+func patchWorkload(ctx context.Context, client kubernetes.Interface, wl Workload, patch []byte) error {
+	apps := client.AppsV1()
+	var err error
+	switch wl.Kind {
+	case deploymentKind:
+		_, err = apps.Deployments(wl.Namespace).Patch(ctx, wl.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case statefulSetKind:
+		_, err = apps.StatefulSets(wl.Namespace).Patch(ctx, wl.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case daemonSetKind:
+		_, err = apps.DaemonSets(wl.Namespace).Patch(ctx, wl.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return err
+}
+
+// waitForRollout polls the status of the workload, the way 'kubectl rollout status' does, until its rollout is
+// complete or the timeout expires.
+func waitForRollout(ctx context.Context, client kubernetes.Interface, wl Workload, timeout time.Duration, w io.Writer) error {
+	viewer, err := polymorphichelpers.StatusViewerFor(wl.Kind)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	last := ""
+	for {
+		obj, err := getWorkload(ctx, client, wl)
+		if err != nil {
+			return err
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+		status, done, err := viewer.Status(&unstructured.Unstructured{Object: content}, 0)
+		if err != nil {
+			return err
+		}
+		if done {
+			fmt.Fprintf(w, "Rolled out %v\n", wl)
+			return nil
+		}
+		if status != last {
+			fmt.Fprint(w, status)
+			last = status
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rollout did not complete in %v: %s", timeout, strings.TrimSpace(last))
+		case <-time.After(pollInterval):
+		}
+	}
+}
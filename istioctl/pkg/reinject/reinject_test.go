@@ -0,0 +1,154 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reinject
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/api/label"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestParseWorkload(t *testing.T) {
+	cases := []struct {
+		arg     string
+		want    Workload
+		wantErr bool
+	}{
+		{arg: "deployment/reviews", want: Workload{Kind: deploymentKind, Name: "reviews", Namespace: "default"}},
+		{arg: "sts/db.storage", want: Workload{Kind: statefulSetKind, Name: "db", Namespace: "storage"}},
+		{arg: "DaemonSet/agent", want: Workload{Kind: daemonSetKind, Name: "agent", Namespace: "default"}},
+		{arg: "reviews", wantErr: true},
+		{arg: "pod/reviews", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.arg, func(t *testing.T) {
+			got, err := ParseWorkload(c.arg, "default")
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.want)
+		})
+	}
+}
+
+func TestTemplatePatch(t *testing.T) {
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "revision",
+			opts: Options{Revision: "canary"},
+			want: `{"istio.io/rev":"canary","sidecar.istio.io/inject":null}`,
+		},
+		{
+			name: "inject",
+			opts: Options{Inject: true},
+			want: `{"sidecar.istio.io/inject":"true"}`,
+		},
+		{
+			name: "uninject",
+			opts: Options{Uninject: true},
+			want: `{"istio.io/rev":null,"sidecar.istio.io/inject":"false"}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			patch, err := c.opts.templatePatch(now)
+			assert.NoError(t, err)
+			assert.Equal(t, string(patch), `{"spec":{"template":{"metadata":{"annotations":`+
+				`{"kubectl.kubernetes.io/restartedAt":"2023-09-01T12:00:00Z"},"labels":`+c.want+`}}}}`)
+		})
+	}
+}
+
+func deployment(name string, ready bool) *appsv1.Deployment {
+	d := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bookinfo", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.Of(int32(1))},
+		Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	if !ready {
+		d.Status.AvailableReplicas = 0
+	}
+	return d
+}
+
+func TestRoll(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "bookinfo",
+		Labels: map[string]string{"istio-injection": "enabled"},
+	}}
+	workloads := []Workload{
+		{Kind: deploymentKind, Name: "reviews", Namespace: "bookinfo"},
+		{Kind: deploymentKind, Name: "ratings", Namespace: "bookinfo"},
+	}
+
+	t.Run("rolled", func(t *testing.T) {
+		client := fake.NewSimpleClientset(namespace, deployment("reviews", true), deployment("ratings", true))
+		var out bytes.Buffer
+		assert.NoError(t, Roll(context.Background(), client, workloads, Options{Revision: "canary"}, &out))
+		for _, name := range []string{"reviews", "ratings"} {
+			d, err := client.AppsV1().Deployments("bookinfo").Get(context.Background(), name, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, d.Spec.Template.Labels[label.IoIstioRev.Name], "canary")
+		}
+		if !strings.Contains(out.String(), "takes precedence over the revision of the pods") {
+			t.Errorf("expected a warning about the injection label of the namespace, got %q", out.String())
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		client := fake.NewSimpleClientset(namespace, deployment("reviews", true), deployment("ratings", true))
+		var out bytes.Buffer
+		assert.NoError(t, Roll(context.Background(), client, workloads, Options{Uninject: true, DryRun: true}, &out))
+		d, err := client.AppsV1().Deployments("bookinfo").Get(context.Background(), "reviews", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, len(d.Spec.Template.Labels), 0)
+		assert.Equal(t, strings.Count(out.String(), "Would patch"), 2)
+	})
+
+	t.Run("stops at the first unhealthy rollout", func(t *testing.T) {
+		client := fake.NewSimpleClientset(namespace, deployment("reviews", false), deployment("ratings", true))
+		var out bytes.Buffer
+		err := Roll(context.Background(), client, workloads, Options{Uninject: true, Timeout: 10 * time.Millisecond}, &out)
+		if err == nil || !strings.Contains(err.Error(), "deployment/reviews.bookinfo: rollout did not complete") {
+			t.Fatalf("expected the rollout of reviews to time out, got %v", err)
+		}
+		d, err := client.AppsV1().Deployments("bookinfo").Get(context.Background(), "ratings", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, len(d.Spec.Template.Labels), 0)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		assert.Error(t, Roll(context.Background(), client, workloads, Options{Inject: true, Uninject: true}, &bytes.Buffer{}))
+	})
+}
@@ -0,0 +1,210 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package revisions discovers the Istio control plane revisions installed in a cluster, together with
+// the tags, injection webhooks, istiod Deployments and IstioOperators which belong to each of them.
+package revisions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apimachinery_schema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"istio.io/api/label"
+	"istio.io/istio/istioctl/pkg/tag"
+	operator_istio "istio.io/istio/operator/pkg/apis/istio"
+	"istio.io/istio/operator/pkg/apis/istio/v1alpha1"
+	"istio.io/istio/operator/pkg/util"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// istiodSelector selects the istiod Deployments and pods of all revisions.
+const istiodSelector = "app=istiod"
+
+var istioOperatorGVR = apimachinery_schema.GroupVersionResource{
+	Group:    v1alpha1.SchemeGroupVersion.Group,
+	Version:  v1alpha1.SchemeGroupVersion.Version,
+	Resource: "istiooperators",
+}
+
+// Revision is an Istio control plane revision installed in the cluster.
+type Revision struct {
+	// Name is the name of the revision, "default" for the default revision.
+	Name string
+	// Tags are the revision tags pointing to the revision.
+	Tags []string
+	// Webhooks are the sidecar injection webhooks of the revision itself, without tag webhooks.
+	Webhooks []admitv1.MutatingWebhookConfiguration
+	// TagWebhooks are the sidecar injection webhooks of the tags pointing to the revision.
+	TagWebhooks []admitv1.MutatingWebhookConfiguration
+	// Deployments are the istiod Deployments of the revision in the Istio namespace.
+	Deployments []appsv1.Deployment
+	// Pods are the istiod pods of the revision in the Istio namespace.
+	Pods []corev1.Pod
+	// IstioOperators are the IstioOperators installing the revision.
+	IstioOperators []*v1alpha1.IstioOperator
+}
+
+// Revisions are the revisions installed in a cluster, sorted by name.
+type Revisions []*Revision
+
+// Get returns the revision with the given name, or nil if it is not installed. An empty name
+// refers to the default revision.
+func (r Revisions) Get(name string) *Revision {
+	name = Normalize(name)
+	for _, rev := range r {
+		if rev.Name == name {
+			return rev
+		}
+	}
+	return nil
+}
+
+// Names returns the sorted names of the revisions.
+func (r Revisions) Names() []string {
+	names := make([]string, 0, len(r))
+	for _, rev := range r {
+		names = append(names, rev.Name)
+	}
+	return names
+}
+
+// ForTag returns the revision a tag points to, or nil if no revision has the tag.
+func (r Revisions) ForTag(t string) *Revision {
+	for _, rev := range r {
+		for _, rt := range rev.Tags {
+			if rt == t {
+				return rev
+			}
+		}
+	}
+	return nil
+}
+
+// Normalize returns the name of a revision, mapping the empty revision to the default revision.
+func Normalize(revision string) string {
+	if revision == "" {
+		return tag.DefaultRevisionName
+	}
+	return revision
+}
+
+// Discover finds all control plane revisions in the cluster. Webhooks and IstioOperators are
+// discovered cluster wide, istiod Deployments and pods in the Istio namespace.
+func Discover(ctx context.Context, client kube.CLIClient, istioNamespace string) (Revisions, error) {
+	revisions := map[string]*Revision{}
+	get := func(name string) *Revision {
+		name = Normalize(name)
+		if _, f := revisions[name]; !f {
+			revisions[name] = &Revision{Name: name}
+		}
+		return revisions[name]
+	}
+
+	webhooks, err := client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhooks: %v", err)
+	}
+	for _, wh := range webhooks.Items {
+		rev, f := wh.Labels[label.IoIstioRev.Name]
+		if !f {
+			continue
+		}
+		if t := wh.Labels[tag.IstioTagLabel]; t != "" {
+			r := get(rev)
+			r.TagWebhooks = append(r.TagWebhooks, wh)
+			r.Tags = append(r.Tags, t)
+			continue
+		}
+		get(rev).Webhooks = append(get(rev).Webhooks, wh)
+	}
+
+	deployments, err := client.Kube().AppsV1().Deployments(istioNamespace).List(ctx, metav1.ListOptions{LabelSelector: istiodSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list istiod deployments: %v", err)
+	}
+	for _, d := range deployments.Items {
+		r := get(d.Labels[label.IoIstioRev.Name])
+		r.Deployments = append(r.Deployments, d)
+	}
+
+	pods, err := client.Kube().CoreV1().Pods(istioNamespace).List(ctx, metav1.ListOptions{LabelSelector: istiodSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list istiod pods: %v", err)
+	}
+	for _, p := range pods.Items {
+		r := get(p.Labels[label.IoIstioRev.Name])
+		r.Pods = append(r.Pods, p)
+	}
+
+	iops, err := IstioOperators(ctx, client.Dynamic())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IstioOperators: %v", err)
+	}
+	for _, iop := range iops {
+		r := get(iop.Spec.Revision)
+		r.IstioOperators = append(r.IstioOperators, iop)
+	}
+
+	result := make(Revisions, 0, len(revisions))
+	for _, rev := range revisions {
+		rev.Tags = sets.SortedList(sets.New(rev.Tags...))
+		result = append(result, rev)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// IstioOperators returns all IstioOperators in the cluster. A missing IstioOperator CRD is not an error.
+func IstioOperators(ctx context.Context, client dynamic.Interface) ([]*v1alpha1.IstioOperator, error) {
+	ul, err := client.Resource(istioOperatorGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	iops := make([]*v1alpha1.IstioOperator, 0, len(ul.Items))
+	for _, un := range ul.Items {
+		fixTimestampRelatedUnmarshalIssues(&un)
+		by := util.ToYAML(un.Object)
+		iop, err := operator_istio.UnmarshalIstioOperator(by, true)
+		if err != nil {
+			return nil, err
+		}
+		iops = append(iops, iop)
+	}
+	return iops, nil
+}
+
+func fixTimestampRelatedUnmarshalIssues(un *unstructured.Unstructured) {
+	un.SetCreationTimestamp(metav1.Time{}) // UnmarshalIstioOperator chokes on these
+
+	// UnmarshalIstioOperator fails because managedFields could contain time
+	// and gogo/protobuf/jsonpb(v1.3.1) tries to unmarshal it as struct (the type
+	// meta_v1.Time is really a struct) and fails.
+	un.SetManagedFields([]metav1.ManagedFieldsEntry{})
+}
@@ -0,0 +1,88 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package revisions
+
+import (
+	"context"
+	"testing"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/api/label"
+	"istio.io/istio/istioctl/pkg/tag"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func webhook(name, revision, revisionTag string) *admitv1.MutatingWebhookConfiguration {
+	labels := map[string]string{label.IoIstioRev.Name: revision}
+	if revisionTag != "" {
+		labels[tag.IstioTagLabel] = revisionTag
+	}
+	return &admitv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func istiodLabels(revision string) map[string]string {
+	return map[string]string{"app": "istiod", label.IoIstioRev.Name: revision}
+}
+
+func TestDiscover(t *testing.T) {
+	objects := []runtime.Object{
+		webhook("istio-sidecar-injector", "default", ""),
+		webhook("istio-sidecar-injector-canary", "canary", ""),
+		webhook("istio-revision-tag-prod", "canary", "prod"),
+		webhook("istio-revision-tag-default", "canary", "default"),
+		webhook("istio-revision-tag-stable", "default", "stable"),
+		// Webhooks of other projects are ignored.
+		&admitv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "cert-manager"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system", Labels: istiodLabels("default")}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istiod-canary", Namespace: "istio-system", Labels: istiodLabels("canary")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "istiod-canary-1", Namespace: "istio-system", Labels: istiodLabels("canary")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "istiod-canary-2", Namespace: "istio-system", Labels: istiodLabels("canary")}},
+		// Only the Istio namespace is searched for istiod.
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "istiod-1", Namespace: "other", Labels: istiodLabels("other")}},
+	}
+	revs, err := Discover(context.Background(), kube.NewFakeClient(objects...), "istio-system")
+	assert.NoError(t, err)
+	assert.Equal(t, revs.Names(), []string{"canary", "default"})
+
+	canary := revs.Get("canary")
+	assert.Equal(t, canary.Tags, []string{"default", "prod"})
+	assert.Equal(t, len(canary.Webhooks), 1)
+	assert.Equal(t, canary.Webhooks[0].Name, "istio-sidecar-injector-canary")
+	assert.Equal(t, len(canary.TagWebhooks), 2)
+	assert.Equal(t, len(canary.Deployments), 1)
+	assert.Equal(t, len(canary.Pods), 2)
+
+	def := revs.Get("")
+	assert.Equal(t, def.Name, "default")
+	assert.Equal(t, def.Tags, []string{"stable"})
+	assert.Equal(t, len(def.Webhooks), 1)
+	assert.Equal(t, len(def.Deployments), 1)
+	assert.Equal(t, len(def.Pods), 0)
+
+	assert.Equal(t, revs.ForTag("prod"), canary)
+	assert.Equal(t, revs.ForTag("stable"), def)
+	if revs.Get("other") != nil {
+		t.Fatal("revision in another namespace discovered")
+	}
+	if revs.ForTag("missing") != nil {
+		t.Fatal("revision found for missing tag")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/annotation"
+	"istio.io/api/label"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// tagPreview describes the impact of pointing a revision tag at a revision.
+type tagPreview struct {
+	Tag string
+	// CurrentRevision is the revision the tag points to, empty if the tag does not exist yet.
+	CurrentRevision   string
+	Revision          string
+	Namespaces        []string
+	Pods              []podRevision
+	Incompatibilities []string
+}
+
+// podRevision is a pod which would change revision on its next restart.
+type podRevision struct {
+	Namespace string
+	Name      string
+	// Revision is the revision the pod was injected by, empty if it was not injected.
+	Revision string
+}
+
+// previewTag computes the impact of pointing the tag at the revision, without modifying the cluster.
+func previewTag(ctx context.Context, kubeClient kube.CLIClient, tagName, revision, istioNS string) (*tagPreview, error) {
+	p := &tagPreview{Tag: tagName, Revision: revision}
+	whs, err := GetWebhooksWithTag(ctx, kubeClient.Kube(), tagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tag with name %s: %v", tagName, err)
+	}
+	if len(whs) > 0 {
+		if p.CurrentRevision, err = GetWebhookRevision(whs[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	namespaces, err := namespacesUsingTag(ctx, kubeClient, tagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve namespaces dependent on tag %q: %v", tagName, err)
+	}
+	p.Namespaces = namespaces
+	for _, ns := range namespaces {
+		pods, err := kubeClient.Kube().CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods of namespace %s: %v", ns, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Spec.HostNetwork || pod.Labels[annotation.SidecarInject.Name] == "false" ||
+				pod.Annotations[annotation.SidecarInject.Name] == "false" {
+				continue
+			}
+			current := ""
+			if _, injected := pod.Annotations[annotation.SidecarStatus.Name]; injected {
+				current = pod.Labels[label.IoIstioRev.Name]
+				if current == "" {
+					current = DefaultRevisionName
+				}
+			}
+			if current != revision {
+				p.Pods = append(p.Pods, podRevision{Namespace: pod.Namespace, Name: pod.Name, Revision: current})
+			}
+		}
+	}
+
+	// EnvoyFilters labeled with a revision are only applied by the control plane of that revision, so they stop
+	// applying to the workloads moved to another revision.
+	if p.CurrentRevision != "" && p.CurrentRevision != revision {
+		scope := sets.New(namespaces...).Insert(istioNS)
+		filters, err := kubeClient.Istio().NetworkingV1alpha3().EnvoyFilters(metav1.NamespaceAll).List(ctx,
+			metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", label.IoIstioRev.Name, p.CurrentRevision)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list envoy filters: %v", err)
+		}
+		for _, ef := range filters.Items {
+			if !scope.Contains(ef.Namespace) {
+				continue
+			}
+			p.Incompatibilities = append(p.Incompatibilities, fmt.Sprintf(
+				"EnvoyFilter %s/%s is pinned to revision %q and will not apply to workloads moved to revision %q",
+				ef.Namespace, ef.Name, p.CurrentRevision, revision))
+		}
+		sort.Strings(p.Incompatibilities)
+	}
+	return p, nil
+}
+
+// namespacesUsingTag returns the namespaces injected by the tag. The default tag also injects the namespaces
+// labeled with istio-injection=enabled.
+func namespacesUsingTag(ctx context.Context, kubeClient kube.CLIClient, tagName string) ([]string, error) {
+	namespaces, err := GetNamespacesWithTag(ctx, kubeClient.Kube(), tagName)
+	if err != nil {
+		return nil, err
+	}
+	if tagName != DefaultRevisionName {
+		return namespaces, nil
+	}
+	injected, err := kubeClient.Kube().CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: "istio-injection=enabled"})
+	if err != nil {
+		return nil, err
+	}
+	names := sets.New(namespaces...)
+	for _, ns := range injected.Items {
+		names.Insert(ns.Name)
+	}
+	return sets.SortedList(names), nil
+}
+
+// printTagPreview prints the impact of setting a tag.
+func printTagPreview(writer io.Writer, p *tagPreview) error {
+	if p.CurrentRevision == "" {
+		fmt.Fprintf(writer, "Revision tag %q does not exist and would reference revision %q.\n", p.Tag, p.Revision)
+	} else {
+		fmt.Fprintf(writer, "Revision tag %q references revision %q and would reference revision %q.\n",
+			p.Tag, p.CurrentRevision, p.Revision)
+	}
+	if len(p.Namespaces) == 0 {
+		fmt.Fprintf(writer, "No namespaces use the tag.\n")
+	} else {
+		fmt.Fprintf(writer, "Namespaces using the tag: %s\n", strings.Join(p.Namespaces, ","))
+	}
+	if len(p.Pods) == 0 {
+		fmt.Fprintf(writer, "No pods would change revision on their next restart.\n")
+	} else {
+		fmt.Fprintf(writer, "\nPods changing revision on their next restart:\n")
+		w := new(tabwriter.Writer).Init(writer, 0, 8, 1, ' ', 0)
+		fmt.Fprintln(w, "NAMESPACE\tPOD\tREVISION")
+		for _, pod := range p.Pods {
+			current := pod.Revision
+			if current == "" {
+				current = "<not injected>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s -> %s\n", pod.Namespace, pod.Name, current, p.Revision)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(p.Incompatibilities) > 0 {
+		fmt.Fprintf(writer, "\nIncompatibilities:\n")
+		for _, i := range p.Incompatibilities {
+			fmt.Fprintf(writer, "  %s\n", i)
+		}
+	}
+	fmt.Fprintf(writer, "\nNo changes applied, run the command without --preview to set the tag.\n")
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/completion"
 	"istio.io/istio/istioctl/pkg/util"
 	"istio.io/istio/istioctl/pkg/util/formatting"
 	"istio.io/istio/pkg/config/analysis"
@@ -50,6 +51,8 @@ revision tag, use 'kubectl label namespace <NAMESPACE> istio.io/rev=%s'
 `
 	webhookNameHelpStr          = "Name to use for a revision tag's mutating webhook configuration."
 	autoInjectNamespacesHelpStr = "If set to true, the sidecars should be automatically injected into all namespaces by default"
+	previewHelpStr              = `If true, list the namespaces using the revision tag, the pods which would change revision on
+their next restart and the detected incompatibilities, without modifying the revision tag.`
 )
 
 // options for CLI
@@ -61,6 +64,7 @@ var (
 	skipConfirmation     = false
 	webhookName          = ""
 	autoInjectNamespaces = false
+	preview              = false
 	outputFormat         = util.TableFormat
 )
 
@@ -117,6 +121,9 @@ injection labels.`,
   # Point namespace "test-ns" at the revision pointed to by the "prod" revision tag
   kubectl label ns test-ns istio.io/rev=prod
 
+  # Preview the impact of changing the revision tag to reference the "1-8-1" revision
+  istioctl tag set prod --revision 1-8-1 --preview
+
   # Change the revision tag to reference the "1-8-1" revision
   istioctl tag set prod --revision 1-8-1 --overwrite
 
@@ -153,7 +160,9 @@ injection labels.`,
 	cmd.PersistentFlags().StringVarP(&revision, "revision", "r", "", revisionHelpStr)
 	cmd.PersistentFlags().StringVarP(&webhookName, "webhook-name", "", "", webhookNameHelpStr)
 	cmd.PersistentFlags().BoolVar(&autoInjectNamespaces, "auto-inject-namespaces", false, autoInjectNamespacesHelpStr)
+	cmd.PersistentFlags().BoolVar(&preview, "preview", false, previewHelpStr)
 	_ = cmd.MarkPersistentFlagRequired("revision")
+	completion.RegisterRevisionFlag(cmd, ctx)
 
 	return cmd
 }
@@ -202,6 +211,7 @@ injection labels.`,
 	cmd.PersistentFlags().StringVarP(&webhookName, "webhook-name", "", "", webhookNameHelpStr)
 	cmd.PersistentFlags().BoolVar(&autoInjectNamespaces, "auto-inject-namespaces", false, autoInjectNamespacesHelpStr)
 	_ = cmd.MarkPersistentFlagRequired("revision")
+	completion.RegisterRevisionFlag(cmd, ctx)
 
 	return cmd
 }
@@ -246,6 +256,9 @@ revision tag before removing using the "istioctl tag list" command.
   istioctl tag remove prod
 `,
 		Aliases: []string{"delete"},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.ValidTagArgs(cmd, ctx, args, toComplete)
+		},
 		Args: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("must provide a tag for removal")
@@ -271,6 +284,14 @@ revision tag before removing using the "istioctl tag list" command.
 
 // setTag creates or modifies a revision tag.
 func setTag(ctx context.Context, kubeClient kube.CLIClient, tagName, revision, istioNS string, generate bool, w, stderr io.Writer) error {
+	if preview && !generate {
+		// Generate deactivates the default injector when setting the default tag, so preview before it.
+		p, err := previewTag(ctx, kubeClient, tagName, revision, istioNS)
+		if err != nil {
+			return err
+		}
+		return printTagPreview(w, p)
+	}
 	opts := &GenerateOptions{
 		Tag:                  tagName,
 		Revision:             revision,
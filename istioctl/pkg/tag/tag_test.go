@@ -25,7 +25,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
+	"istio.io/api/annotation"
 	"istio.io/api/label"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	"istio.io/istio/istioctl/pkg/util"
 	"istio.io/istio/pkg/kube"
 )
@@ -315,3 +317,80 @@ func TestSetTagErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestSetTagPreview(t *testing.T) {
+	client := kube.NewFakeClient(
+		&admitv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "istio-revision-tag-prod",
+				Labels: map[string]string{IstioTagLabel: "prod", label.IoIstioRev.Name: "old"},
+			},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "app", Labels: map[string]string{label.IoIstioRev.Name: "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{label.IoIstioRev.Name: "old"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "injected",
+			Namespace:   "app",
+			Labels:      map[string]string{label.IoIstioRev.Name: "old"},
+			Annotations: map[string]string{annotation.SidecarStatus.Name: "{}"},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "uninjected", Namespace: "app"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "opted-out",
+			Namespace:   "app",
+			Annotations: map[string]string{annotation.SidecarInject.Name: "false"},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:        "other-pod",
+			Namespace:   "other",
+			Labels:      map[string]string{label.IoIstioRev.Name: "old"},
+			Annotations: map[string]string{annotation.SidecarStatus.Name: "{}"},
+		}},
+	)
+	filters := []*clientnetworking.EnvoyFilter{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "app", Labels: map[string]string{label.IoIstioRev.Name: "old"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mesh", Namespace: "istio-system", Labels: map[string]string{label.IoIstioRev.Name: "old"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "other", Labels: map[string]string{label.IoIstioRev.Name: "old"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "unpinned", Namespace: "app"}},
+	}
+	for _, ef := range filters {
+		if _, err := client.Istio().NetworkingV1alpha3().EnvoyFilters(ef.Namespace).Create(context.Background(), ef, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	preview = true
+	defer func() { preview = false }()
+	if err := setTag(context.Background(), client, "prod", "new", "istio-system", false, &out, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	commandOutput := out.String()
+	for _, s := range []string{
+		`Revision tag "prod" references revision "old" and would reference revision "new"`,
+		"Namespaces using the tag: app\n",
+		"injected   old -> new",
+		"uninjected <not injected> -> new",
+		`EnvoyFilter app/pinned is pinned to revision "old"`,
+		`EnvoyFilter istio-system/mesh is pinned to revision "old"`,
+	} {
+		if !strings.Contains(commandOutput, s) {
+			t.Fatalf("expected %q in command output, got %s", s, commandOutput)
+		}
+	}
+	for _, s := range []string{"opted-out", "other-pod", "unrelated", "unpinned"} {
+		if strings.Contains(commandOutput, s) {
+			t.Fatalf("expected no %q in command output, got %s", s, commandOutput)
+		}
+	}
+
+	// the preview does not modify the tag
+	webhook, err := client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.Background(),
+		"istio-revision-tag-prod", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev := webhook.Labels[label.IoIstioRev.Name]; rev != "old" {
+		t.Fatalf("expected tag to reference revision old after preview, got %s", rev)
+	}
+}
@@ -24,8 +24,11 @@ import (
 	"github.com/mattn/go-isatty"
 	"sigs.k8s.io/yaml"
 
+	"istio.io/istio/istioctl/pkg/writer/sarif"
 	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/legacy/source/kube"
 	"istio.io/istio/pkg/env"
+	"istio.io/istio/pkg/url"
 )
 
 // Formatting options for Messages
@@ -33,10 +36,12 @@ const (
 	LogFormat  = "log"
 	JSONFormat = "json"
 	YAMLFormat = "yaml"
+	// SARIFFormat is for code scanning UIs, such as those of GitHub and GitLab.
+	SARIFFormat = "sarif"
 )
 
 var (
-	MsgOutputFormatKeys = []string{LogFormat, JSONFormat, YAMLFormat}
+	MsgOutputFormatKeys = []string{LogFormat, JSONFormat, YAMLFormat, SARIFFormat}
 	MsgOutputFormats    = make(map[string]bool)
 	termEnvVar          = env.Register("TERM", "", "Specifies terminal type.  Use 'dumb' to suppress color output")
 )
@@ -56,6 +61,8 @@ func Print(ms diag.Messages, format string, colorize bool) (string, error) {
 		return printJSON(ms)
 	case YAMLFormat:
 		return printYAML(ms)
+	case SARIFFormat:
+		return printSARIF(ms)
 	default:
 		return "", fmt.Errorf("invalid format, expected one of %v but got %q", MsgOutputFormatKeys, format)
 	}
@@ -79,6 +86,67 @@ func printYAML(ms diag.Messages) (string, error) {
 	return string(yamlOutput), err
 }
 
+var sarifLevels = map[diag.Level]string{
+	diag.Info:    sarif.LevelNote,
+	diag.Warning: sarif.LevelWarning,
+	diag.Error:   sarif.LevelError,
+}
+
+// printSARIF prints the messages as a SARIF log with a rule per message code. Messages about resources read from
+// files are located at their line in the file, so that code scanning UIs annotate the config repository.
+func printSARIF(ms diag.Messages) (string, error) {
+	driver := sarif.Driver{Name: "istioctl analyze", InformationURI: url.ConfigAnalysis}
+	rules := map[string]bool{}
+	results := make([]sarif.Result, 0, len(ms))
+	for _, m := range ms {
+		code := m.Type.Code()
+		level := sarifLevels[m.Type.Level()]
+		if !rules[code] {
+			rules[code] = true
+			driver.Rules = append(driver.Rules, sarif.Rule{
+				ID:                   code,
+				HelpURI:              fmt.Sprintf("%s/%s/", url.ConfigAnalysis, strings.ToLower(code)),
+				DefaultConfiguration: &sarif.Configuration{Level: level},
+			})
+		}
+		result := sarif.Result{
+			RuleID:  code,
+			Level:   level,
+			Message: sarif.Message{Text: fmt.Sprintf(m.Type.Template(), m.Parameters...)},
+		}
+		if loc := sarifLocation(m); loc != nil {
+			result.Locations = []sarif.Location{*loc}
+		}
+		results = append(results, result)
+	}
+	var b strings.Builder
+	if err := sarif.NewLog(driver, results).Write(&b); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// sarifLocation returns the location of the resource of a message, if it has one.
+func sarifLocation(m diag.Message) *sarif.Location {
+	if m.Resource == nil {
+		return nil
+	}
+	loc := &sarif.Location{
+		LogicalLocations: []sarif.LogicalLocation{{FullyQualifiedName: m.Resource.Origin.FriendlyName(), Kind: "resource"}},
+	}
+	if pos, ok := m.Resource.Origin.Reference().(*kube.Position); ok && pos != nil && pos.Filename != "" {
+		loc.PhysicalLocation = &sarif.PhysicalLocation{ArtifactLocation: sarif.ArtifactLocation{URI: pos.Filename}}
+		line := pos.Line
+		if m.Line != 0 {
+			line = m.Line
+		}
+		if line > 0 {
+			loc.PhysicalLocation.Region = &sarif.Region{StartLine: line}
+		}
+	}
+	return loc
+}
+
 // Formatting options for Message
 var (
 	colorPrefixes = map[diag.Level]string{
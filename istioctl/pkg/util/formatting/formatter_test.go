@@ -20,6 +20,9 @@ import (
 	. "github.com/onsi/gomega"
 
 	"istio.io/istio/pkg/config/analysis/diag"
+	"istio.io/istio/pkg/config/legacy/source/kube"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/url"
 )
 
@@ -152,3 +155,104 @@ func TestFormatter_PrintEmpty(t *testing.T) {
 	yamlOutput, _ := Print(msgs, YAMLFormat, false)
 	g.Expect(yamlOutput).To(Equal("[]\n"))
 }
+
+func TestFormatter_PrintSARIF(t *testing.T) {
+	g := NewWithT(t)
+
+	fileMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Error, "B1", "Explosion accident: %v"),
+		&resource.Instance{Origin: &kube.Origin{
+			Type:     gvk.VirtualService,
+			FullName: resource.NewFullName("default", "bubble"),
+			Ref:      &kube.Position{Filename: "config/bubble.yaml", Line: 3},
+		}},
+		"the bubble is too big",
+	)
+	fileMsg.Line = 7
+	clusterMsg := diag.NewMessage(
+		diag.NewMessageType(diag.Info, "C1", "Collapse danger: %v"),
+		diag.MockResource("GrandCastle"),
+		"the castle is too old",
+	)
+
+	msgs := diag.Messages{fileMsg, clusterMsg}
+	output, err := Print(msgs, SARIFFormat, false)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expectedOutput := `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "istioctl analyze",
+          "informationUri": "` + url.ConfigAnalysis + `",
+          "rules": [
+            {
+              "id": "B1",
+              "helpUri": "` + url.ConfigAnalysis + `/b1/",
+              "defaultConfiguration": {
+                "level": "error"
+              }
+            },
+            {
+              "id": "C1",
+              "helpUri": "` + url.ConfigAnalysis + `/c1/",
+              "defaultConfiguration": {
+                "level": "note"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "B1",
+          "level": "error",
+          "message": {
+            "text": "Explosion accident: the bubble is too big"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "config/bubble.yaml"
+                },
+                "region": {
+                  "startLine": 7
+                }
+              },
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "VirtualService default/bubble",
+                  "kind": "resource"
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "C1",
+          "level": "note",
+          "message": {
+            "text": "Collapse danger: the castle is too old"
+          },
+          "locations": [
+            {
+              "logicalLocations": [
+                {
+                  "fullyQualifiedName": "GrandCastle",
+                  "kind": "resource"
+                }
+              ]
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+	g.Expect(output).To(Equal(expectedOutput))
+}
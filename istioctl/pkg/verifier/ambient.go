@@ -0,0 +1,215 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	gateway "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	// ambientComponent is the component the results of the ambient check are summarized under.
+	ambientComponent     = "ambient"
+	ztunnelDaemonSetName = "ztunnel"
+	cniDaemonSetName     = "istio-cni-node"
+	cniContainer         = "install-cni"
+)
+
+// waypointCRDs are the CustomResourceDefinitions of the Gateway API the waypoints of ambient namespaces are
+// declared with.
+var waypointCRDs = []string{
+	"gatewayclasses.gateway.networking.k8s.io",
+	"gateways.gateway.networking.k8s.io",
+}
+
+// verifyAmbient checks an ambient installation: the ztunnel DaemonSet runs a ready pod on every schedulable node,
+// the CNI node agent redirects the traffic of ambient pods, the Gateway API CRDs of waypoints are installed and
+// the waypoints of the namespaces enrolled in ambient are available.
+func (v *StatusVerifier) verifyAmbient(ctx context.Context) error {
+	ztunnel, ztunnelErr := v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).Get(ctx, ztunnelDaemonSetName, metav1.GetOptions{})
+	if kerrors.IsNotFound(ztunnelErr) {
+		ztunnel = nil
+	} else if ztunnelErr != nil {
+		return fmt.Errorf("failed to get the ztunnel daemonset: %v", ztunnelErr)
+	}
+	cniAmbient, cniErr := v.cniAmbientEnabled(ctx)
+	if ztunnel == nil && !cniAmbient {
+		v.logf(VerbosityNormal, "Ambient mode is not installed, skipping the ambient checks")
+		return nil
+	}
+	defer v.attributeResults(len(v.results), ambientComponent)
+
+	multiErr := &multierror.Error{}
+	report := func(kind, name, namespace string, err error) {
+		if err != nil {
+			v.reportFailure(kind, name, namespace, withCode(CodeAmbientUnhealthy, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("%s %s/%s: %v", kind, namespace, name, err))
+			return
+		}
+		v.reportSuccess(kind, name, namespace)
+	}
+
+	if ztunnel == nil {
+		report("DaemonSet", ztunnelDaemonSetName, v.istioNamespace,
+			fmt.Errorf("the CNI node agent is configured for ambient but ztunnel is not installed: %w", ztunnelErr))
+	} else {
+		report("DaemonSet", ztunnelDaemonSetName, v.istioNamespace, v.verifyDaemonSetNodes(ctx, ztunnel))
+	}
+	if cniErr == nil && !cniAmbient {
+		cniErr = fmt.Errorf("ambient is not enabled, the traffic of ambient pods is not redirected to ztunnel")
+	}
+	report("DaemonSet", cniDaemonSetName, v.istioNamespace, cniErr)
+
+	for _, crd := range waypointCRDs {
+		_, err := v.client.Ext().ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crd, metav1.GetOptions{})
+		if err != nil {
+			err = fmt.Errorf("waypoints cannot be declared without the CRD: %w", err)
+		}
+		report("CustomResourceDefinition", crd, "", err)
+	}
+
+	if err := v.verifyWaypoints(ctx, report); err != nil {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// cniAmbientEnabled returns whether the CNI node agent redirects the traffic of ambient pods to ztunnel, as its
+// network config and environment enable.
+func (v *StatusVerifier) cniAmbientEnabled(ctx context.Context) (bool, error) {
+	cm, err := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace).Get(ctx, cniConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("the CNI node agent is not installed: %w", err)
+	}
+	var conf struct {
+		AmbientEnabled bool `json:"ambient_enabled"`
+	}
+	if err := json.Unmarshal([]byte(cm.Data[cniNetworkConfig]), &conf); err != nil {
+		return false, fmt.Errorf("failed to parse the CNI network config: %v", err)
+	}
+	if !conf.AmbientEnabled {
+		return false, nil
+	}
+	ds, err := v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).Get(ctx, cniDaemonSetName, metav1.GetOptions{})
+	if err != nil {
+		return true, fmt.Errorf("the CNI node agent is not installed: %w", err)
+	}
+	container := findContainer(ds.Spec.Template.Spec.Containers, cniContainer)
+	if container == nil || !envTrue(container.Env, "AMBIENT_ENABLED") {
+		return true, fmt.Errorf("the network config enables ambient but the node agent does not redirect ambient pods")
+	}
+	return true, verifyDaemonSetStatus(ds)
+}
+
+// envTrue returns whether the environment variable is set to true.
+func envTrue(env []corev1.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			enabled, err := strconv.ParseBool(e.Value)
+			return err == nil && enabled
+		}
+	}
+	return false
+}
+
+// verifyWaypoints checks the waypoints of the namespaces enrolled in ambient have their Deployment available.
+func (v *StatusVerifier) verifyWaypoints(ctx context.Context, report func(kind, name, namespace string, err error)) error {
+	namespaces, err := v.scopedNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	for _, ns := range namespaces {
+		if ns.Labels[constants.DataplaneMode] != constants.DataplaneModeAmbient {
+			continue
+		}
+		gateways, err := v.client.GatewayAPI().GatewayV1beta1().Gateways(ns.Name).List(ctx, metav1.ListOptions{})
+		if kerrors.IsNotFound(err) {
+			// The missing CRDs are reported already.
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list the gateways of namespace %s: %v", ns.Name, err)
+		}
+		for i := range gateways.Items {
+			gw := &gateways.Items[i]
+			if gw.Spec.GatewayClassName != constants.WaypointGatewayClassName {
+				continue
+			}
+			report("Waypoint", gw.Name, gw.Namespace, v.verifyWaypoint(ctx, gw))
+		}
+	}
+	return nil
+}
+
+func (v *StatusVerifier) verifyWaypoint(ctx context.Context, gw *gateway.Gateway) error {
+	opts := metav1.ListOptions{LabelSelector: klabels.SelectorFromSet(map[string]string{constants.GatewayNameLabel: gw.Name}).String()}
+	deployments, err := v.client.Kube().AppsV1().Deployments(gw.Namespace).List(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to list waypoint deployments: %v", err)
+	}
+	if len(deployments.Items) == 0 {
+		return fmt.Errorf("no deployment was generated for the waypoint")
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if err := verifyDeploymentStatus(d); err != nil {
+			return err
+		}
+		if d.Status.ReadyReplicas == 0 {
+			return fmt.Errorf("no waypoint pod is ready")
+		}
+	}
+	return nil
+}
+
+// verifyDaemonSetNodes checks that the DaemonSet runs a ready pod on every node it is scheduled on.
+func (v *StatusVerifier) verifyDaemonSetNodes(ctx context.Context, ds *appsv1.DaemonSet) error {
+	if err := verifyDaemonSetStatus(ds); err != nil {
+		return err
+	}
+	covered, err := v.daemonSetReadyNodes(ctx, ds)
+	if err != nil {
+		return err
+	}
+	nodes, err := v.client.Kube().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	var missing []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if !covered[node.Name] && daemonSetExcludes(ds, node) == "" {
+			missing = append(missing, node.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("no ready pod on nodes %v", missing)
+	}
+	return nil
+}
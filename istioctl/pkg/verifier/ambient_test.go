@@ -0,0 +1,204 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gateway "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// ambientObjects are the nodes, ztunnel and CNI node agent of a healthy ambient installation, and a namespace
+// enrolled in ambient with its waypoint.
+func ambientObjects() map[string]runtime.Object {
+	ns := "istio-system"
+	daemonSet := func(name string, containers ...corev1.Container) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, CurrentNumberScheduled: 2, NumberReady: 2},
+		}
+	}
+	ztunnelPod := func(node string) *corev1.Pod {
+		pod := readyPod("ztunnel-"+node, "")
+		pod.Labels = map[string]string{"app": ztunnelDaemonSetName}
+		pod.Spec.NodeName = node
+		return pod
+	}
+	return map[string]runtime.Object{
+		"node-1": &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		"node-2": &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		"tainted": &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+			{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		}}},
+		"ztunnel":        daemonSet(ztunnelDaemonSetName),
+		"ztunnel-node-1": ztunnelPod("node-1"),
+		"ztunnel-node-2": ztunnelPod("node-2"),
+		"cni-config": &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: cniConfigMapName, Namespace: ns},
+			Data:       map[string]string{cniNetworkConfig: `{"type": "istio-cni", "ambient_enabled": true}`},
+		},
+		"cni": daemonSet(cniDaemonSetName, corev1.Container{
+			Name: cniContainer,
+			Env:  []corev1.EnvVar{{Name: "AMBIENT_ENABLED", Value: "true"}},
+		}),
+		"namespace": &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{constants.DataplaneMode: constants.DataplaneModeAmbient},
+		}},
+		"waypoint": &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "waypoint", Namespace: "default", Labels: map[string]string{constants.GatewayNameLabel: "waypoint"}},
+			Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1, ReadyReplicas: 1},
+		},
+	}
+}
+
+func TestVerifyAmbient(t *testing.T) {
+	cases := []struct {
+		name    string
+		modify  func(objects map[string]runtime.Object)
+		noCRDs  bool
+		wantErr []string
+		want    []string
+	}{
+		{
+			name: "healthy",
+			want: []string{
+				"✔ DaemonSet: ztunnel.istio-system checked successfully",
+				"✔ DaemonSet: istio-cni-node.istio-system checked successfully",
+				"✔ Waypoint: waypoint.default checked successfully",
+			},
+		},
+		{
+			name: "not installed",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "ztunnel")
+				delete(objects, "cni-config")
+			},
+			want: []string{"Ambient mode is not installed"},
+		},
+		{
+			name: "ztunnel missing on a node",
+			modify: func(objects map[string]runtime.Object) {
+				objects["ztunnel-node-2"].(*corev1.Pod).Status.Conditions = nil
+			},
+			wantErr: []string{"DaemonSet istio-system/ztunnel: no ready pod on nodes [node-2]"},
+		},
+		{
+			name: "ztunnel not installed",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "ztunnel")
+			},
+			wantErr: []string{"the CNI node agent is configured for ambient but ztunnel is not installed"},
+		},
+		{
+			name: "CNI node agent not redirecting",
+			modify: func(objects map[string]runtime.Object) {
+				objects["cni"].(*appsv1.DaemonSet).Spec.Template.Spec.Containers[0].Env = nil
+			},
+			wantErr: []string{"the network config enables ambient but the node agent does not redirect ambient pods"},
+		},
+		{
+			name: "CNI ambient disabled",
+			modify: func(objects map[string]runtime.Object) {
+				objects["cni-config"].(*corev1.ConfigMap).Data[cniNetworkConfig] = `{"type": "istio-cni"}`
+			},
+			wantErr: []string{"ambient is not enabled, the traffic of ambient pods is not redirected to ztunnel"},
+		},
+		{
+			name: "waypoint not deployed",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "waypoint")
+			},
+			wantErr: []string{"Waypoint default/waypoint: no deployment was generated for the waypoint"},
+		},
+		{
+			name:    "gateway API CRDs missing",
+			noCRDs:  true,
+			wantErr: []string{"gateways.gateway.networking.k8s.io: waypoints cannot be declared without the CRD"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objects := ambientObjects()
+			if c.modify != nil {
+				c.modify(objects)
+			}
+			var objs []runtime.Object
+			for _, o := range objects {
+				objs = append(objs, o)
+			}
+			client := kube.NewFakeClient(objs...)
+			if !c.noCRDs {
+				for _, crd := range waypointCRDs {
+					_, err := client.Ext().ApiextensionsV1().CustomResourceDefinitions().Create(context.Background(),
+						&apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crd}}, metav1.CreateOptions{})
+					assert.NoError(t, err)
+				}
+			}
+			_, err := client.GatewayAPI().GatewayV1beta1().Gateways("default").Create(context.Background(), &gateway.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: "waypoint", Namespace: "default"},
+				Spec:       gateway.GatewaySpec{GatewayClassName: constants.WaypointGatewayClassName},
+			}, metav1.CreateOptions{})
+			assert.NoError(t, err)
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:         client,
+				istioNamespace: "istio-system",
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+			}
+			err = v.verifyAmbient(context.Background())
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+			} else if err == nil {
+				t.Fatalf("expected errors %v", c.wantErr)
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+			}
+			for _, want := range c.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			for _, r := range v.results {
+				assert.Equal(t, r.component, ambientComponent)
+				if r.status == checkFailed {
+					assert.Equal(t, r.code, CodeAmbientUnhealthy)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/api/label"
+	security "istio.io/api/security/v1beta1"
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/pkg/config/constants"
+)
+
+// authzPosture is the authorization posture of a namespace.
+type authzPosture int
+
+const (
+	// postureOpen namespaces have no AuthorizationPolicy applying to all their workloads: every workload
+	// without policy of its own accepts requests from anyone.
+	postureOpen authzPosture = iota
+	// postureAllowAll namespaces have a namespace wide policy allowing all requests.
+	postureAllowAll
+	// postureSelective namespaces have namespace wide policies allowing or denying some requests.
+	postureSelective
+	// postureDefaultDeny namespaces deny all requests which are not explicitly allowed, by a policy of their
+	// own or by a mesh wide policy of the root namespace.
+	postureDefaultDeny
+)
+
+func (p authzPosture) String() string {
+	switch p {
+	case postureAllowAll:
+		return "allows all requests"
+	case postureSelective:
+		return "has namespace wide policies but no default deny"
+	case postureDefaultDeny:
+		return "denies by default"
+	default:
+		return "is wide open, no namespace wide AuthorizationPolicy applies"
+	}
+}
+
+// verifyAuthorizationPosture reports which mesh namespaces deny requests by default with an AuthorizationPolicy,
+// which are wide open, and whether the Istio namespace is protected. It is a summary of the security posture,
+// so open namespaces are reported as warnings rather than failures.
+func (v *StatusVerifier) verifyAuthorizationPosture(ctx context.Context) error {
+	rootNamespace := v.istioNamespace
+	if mc, err := v.meshConfig(ctx); err != nil {
+		v.reportWarning("ConfigMap", "mesh config", v.istioNamespace, err)
+	} else if mc.GetRootNamespace() != "" {
+		rootNamespace = mc.GetRootNamespace()
+	}
+	policies, err := v.scopedAuthorizationPolicies(ctx, rootNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to list authorization policies: %v", err)
+	}
+	namespaces, err := v.scopedNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	byNamespace := map[string][]*clientsecurity.AuthorizationPolicy{}
+	for _, p := range policies {
+		byNamespace[p.Namespace] = append(byNamespace[p.Namespace], p)
+	}
+	meshWide := namespacePosture(byNamespace[rootNamespace])
+	if meshWide == postureDefaultDeny {
+		v.logf(VerbosityNormal, "%s AuthorizationPolicy: namespace %s denies by default across the mesh", v.successMarker, rootNamespace)
+	}
+
+	counts := map[authzPosture]int{}
+	for _, ns := range namespaces {
+		if ns.Name != v.istioNamespace && ns.Name != rootNamespace && !meshNamespace(ns) {
+			continue
+		}
+		posture := namespacePosture(byNamespace[ns.Name])
+		// Namespaces allowing all requests override a mesh wide ALLOW policy without rules, but not a DENY policy.
+		if meshWide == postureDefaultDeny && (posture != postureAllowAll || deniesAll(byNamespace[rootNamespace])) {
+			posture = postureDefaultDeny
+		}
+		counts[posture]++
+		switch {
+		case posture == postureDefaultDeny:
+			v.reportSuccess("Namespace", ns.Name, "")
+		case ns.Name == v.istioNamespace:
+			v.reportWarning("Namespace", ns.Name, "", withCode(CodeAuthzPermissive, fmt.Errorf("the Istio namespace is not protected, it %v", posture)))
+		default:
+			v.reportWarning("Namespace", ns.Name, "", withCode(CodeAuthzPermissive, fmt.Errorf("namespace %v", posture)))
+		}
+	}
+	v.logf(VerbosityNormal, "Authorization posture: %d namespaces deny by default, %d with selective policies, %d allow all, %d wide open",
+		counts[postureDefaultDeny], counts[postureSelective], counts[postureAllowAll], counts[postureOpen])
+	return nil
+}
+
+// meshNamespace returns whether the workloads of the namespace are part of the mesh, either with sidecars or
+// in ambient mode.
+func meshNamespace(ns corev1.Namespace) bool {
+	if ns.Labels["istio-injection"] == "enabled" || ns.Labels[constants.DataplaneMode] == constants.DataplaneModeAmbient {
+		return true
+	}
+	_, f := ns.Labels[label.IoIstioRev.Name]
+	return f
+}
+
+// namespacePosture computes the posture of a namespace from its policies. Only policies without selector are
+// considered, as they apply to all workloads of the namespace.
+func namespacePosture(policies []*clientsecurity.AuthorizationPolicy) authzPosture {
+	var denyAll, allowAll, allowNothing, selective bool
+	for _, p := range policies {
+		if len(p.Spec.GetSelector().GetMatchLabels()) > 0 {
+			continue
+		}
+		switch p.Spec.GetAction() {
+		case security.AuthorizationPolicy_ALLOW:
+			switch {
+			case len(p.Spec.GetRules()) == 0:
+				// An ALLOW policy without rules matches nothing, so it denies all requests not allowed otherwise.
+				allowNothing = true
+			case hasEmptyRule(p.Spec.GetRules()):
+				allowAll = true
+			default:
+				selective = true
+			}
+		case security.AuthorizationPolicy_DENY:
+			if hasEmptyRule(p.Spec.GetRules()) {
+				denyAll = true
+			} else {
+				selective = true
+			}
+		}
+	}
+	switch {
+	case denyAll:
+		// DENY policies are evaluated before ALLOW policies.
+		return postureDefaultDeny
+	case allowAll:
+		return postureAllowAll
+	case allowNothing:
+		return postureDefaultDeny
+	case selective:
+		return postureSelective
+	default:
+		return postureOpen
+	}
+}
+
+// deniesAll returns whether a namespace wide DENY policy matches all requests.
+func deniesAll(policies []*clientsecurity.AuthorizationPolicy) bool {
+	for _, p := range policies {
+		if len(p.Spec.GetSelector().GetMatchLabels()) == 0 && p.Spec.GetAction() == security.AuthorizationPolicy_DENY &&
+			hasEmptyRule(p.Spec.GetRules()) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEmptyRule returns whether a rule matches all requests.
+func hasEmptyRule(rules []*security.Rule) bool {
+	for _, r := range rules {
+		if len(r.GetFrom()) == 0 && len(r.GetTo()) == 0 && len(r.GetWhen()) == 0 {
+			return true
+		}
+	}
+	return false
+}
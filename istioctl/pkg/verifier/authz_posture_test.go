@@ -0,0 +1,116 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	security "istio.io/api/security/v1beta1"
+	"istio.io/api/type/v1beta1"
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func namespaceWithLabels(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestVerifyAuthorizationPosture(t *testing.T) {
+	client := kube.NewFakeClient(
+		namespaceWithLabels("istio-system", nil),
+		namespaceWithLabels("deny", map[string]string{"istio-injection": "enabled"}),
+		namespaceWithLabels("open", map[string]string{"istio-injection": "enabled"}),
+		namespaceWithLabels("selective", map[string]string{"istio.io/rev": "canary"}),
+		namespaceWithLabels("allow", map[string]string{"istio.io/dataplane-mode": "ambient"}),
+		namespaceWithLabels("unmeshed", nil),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": ""},
+		},
+	)
+	policies := []*clientsecurity.AuthorizationPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "deny"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "workload", Namespace: "open"},
+			Spec: security.AuthorizationPolicy{
+				Selector: &v1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "a"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "selective"},
+			Spec: security.AuthorizationPolicy{
+				Rules: []*security.Rule{{From: []*security.Rule_From{{Source: &security.Source{Namespaces: []string{"frontend"}}}}}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-all", Namespace: "allow"},
+			Spec: security.AuthorizationPolicy{
+				Rules: []*security.Rule{{}},
+			},
+		},
+	}
+	for _, p := range policies {
+		if _, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(p.Namespace).Create(context.TODO(), p, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+	}
+	assert.NoError(t, v.verifyAuthorizationPosture(context.Background()))
+	for _, want := range []string{
+		"✔ Namespace: deny. checked successfully",
+		"! Namespace: open.: namespace is wide open",
+		"! Namespace: selective.: namespace has namespace wide policies but no default deny",
+		"! Namespace: allow.: namespace allows all requests",
+		"! Namespace: istio-system.: the Istio namespace is not protected",
+		"Authorization posture: 1 namespaces deny by default, 1 with selective policies, 1 allow all, 2 wide open",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "unmeshed") {
+		t.Errorf("namespace outside of the mesh reported:\n%s", out.String())
+	}
+
+	// A mesh wide default deny protects all namespaces but those allowing all requests explicitly.
+	root := &clientsecurity.AuthorizationPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-nothing", Namespace: "istio-system"},
+	}
+	if _, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(root.Namespace).Create(context.TODO(), root, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	assert.NoError(t, v.verifyAuthorizationPosture(context.Background()))
+	if !strings.Contains(out.String(), "Authorization posture: 4 namespaces deny by default, 0 with selective policies, 1 allow all, 0 wide open") {
+		t.Errorf("unexpected output with mesh wide default deny:\n%s", out.String())
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The capacity heuristics are the resources an istiod replica needs for the scale of the configuration it pushes.
+// Every replica holds the whole configuration and every endpoint in memory, while the proxies, and so the push
+// work, are spread over the replicas.
+const (
+	istiodBaseMemory        = 256 << 20
+	istiodMemoryPerProxy    = 512 << 10
+	istiodMemoryPerEndpoint = 10 << 10
+	istiodMemoryPerConfig   = 20 << 10
+	// CPU is in millicores, and the ratios are of the items needing one millicore.
+	istiodBaseCPU         = 100
+	istiodProxiesPerCPU   = 2
+	istiodEndpointsPerCPU = 100
+	istiodConfigsPerCPU   = 20
+)
+
+// configScale is the scale of the configuration istiod pushes to the proxies of the mesh.
+type configScale struct {
+	VirtualServices int
+	ServiceEntries  int
+	Sidecars        int
+	Endpoints       int
+	Proxies         int
+}
+
+func (s configScale) String() string {
+	return fmt.Sprintf("%d VirtualServices, %d ServiceEntries, %d Sidecars, %d endpoints and %d proxies",
+		s.VirtualServices, s.ServiceEntries, s.Sidecars, s.Endpoints, s.Proxies)
+}
+
+// neededMemory returns the memory an istiod replica needs, in bytes.
+func (s configScale) neededMemory() int64 {
+	configs := s.VirtualServices + s.ServiceEntries + s.Sidecars
+	return istiodBaseMemory + int64(s.Proxies)*istiodMemoryPerProxy + int64(s.Endpoints)*istiodMemoryPerEndpoint +
+		int64(configs)*istiodMemoryPerConfig
+}
+
+// neededCPU returns the CPU all the istiod replicas need together, in millicores.
+func (s configScale) neededCPU() int64 {
+	configs := s.VirtualServices + s.ServiceEntries + s.Sidecars
+	return istiodBaseCPU + int64(s.Proxies/istiodProxiesPerCPU) + int64(s.Endpoints/istiodEndpointsPerCPU) +
+		int64(configs/istiodConfigsPerCPU)
+}
+
+// verifyCapacity compares the scale of the configuration of the mesh with the resources requested by istiod, and
+// warns when istiod is undersized for it: when a replica requests less memory than the configuration needs, or
+// the replicas together request less CPU than pushing it to the proxies needs.
+func (v *StatusVerifier) verifyCapacity(ctx context.Context) error {
+	scale, err := v.configScale(ctx)
+	if err != nil {
+		return err
+	}
+	name := v.istiodDeploymentName()
+	deployment, err := v.client.Kube().AppsV1().Deployments(v.istioNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the istiod deployment: %v", err)
+	}
+	container := findContainer(deployment.Spec.Template.Spec.Containers, istiodContainer)
+	if container == nil {
+		return fmt.Errorf("deployment %s/%s has no %s container", v.istioNamespace, name, istiodContainer)
+	}
+	replicas := int64(1)
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > 0 {
+		replicas = int64(*deployment.Spec.Replicas)
+	}
+
+	sized := true
+	memory, cpu := container.Resources.Requests.Memory(), container.Resources.Requests.Cpu()
+	if need := scale.neededMemory(); memory.IsZero() || memory.Value() < need {
+		sized = false
+		v.reportWarning("Deployment", name, v.istioNamespace, withCode(CodeCapacityLow, fmt.Errorf(
+			"istiod requests %s of memory per replica, but %s need about %s",
+			quantityOrNone(memory), scale, resource.NewQuantity(need, resource.BinarySI))))
+	}
+	if need := scale.neededCPU(); cpu.IsZero() || cpu.MilliValue()*replicas < need {
+		sized = false
+		v.reportWarning("Deployment", name, v.istioNamespace, withCode(CodeCapacityLow, fmt.Errorf(
+			"%d istiod replicas request %s of CPU each, but pushing %s needs about %s in total",
+			replicas, quantityOrNone(cpu), scale, resource.NewMilliQuantity(need, resource.DecimalSI))))
+	}
+	if sized {
+		v.logf(VerbosityNormal, "istiod is sized for %s", scale)
+		v.reportSuccess("Deployment", name, v.istioNamespace)
+	}
+	return nil
+}
+
+// configScale counts the configuration istiod pushes to the proxies of the mesh.
+func (v *StatusVerifier) configScale(ctx context.Context) (configScale, error) {
+	var s configScale
+	networking := v.client.Istio().NetworkingV1alpha3()
+	vs, err := networking.VirtualServices(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return s, fmt.Errorf("failed to list virtual services: %v", err)
+	}
+	ses, err := networking.ServiceEntries(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return s, fmt.Errorf("failed to list service entries: %v", err)
+	}
+	sidecars, err := networking.Sidecars(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return s, fmt.Errorf("failed to list sidecars: %v", err)
+	}
+	slices, err := v.client.Kube().DiscoveryV1().EndpointSlices(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return s, fmt.Errorf("failed to list endpoint slices: %v", err)
+	}
+	pods, err := v.client.Kube().CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return s, fmt.Errorf("failed to list pods: %v", err)
+	}
+	s.VirtualServices, s.ServiceEntries, s.Sidecars = len(vs.Items), len(ses.Items), len(sidecars.Items)
+	for _, slice := range slices.Items {
+		s.Endpoints += len(slice.Endpoints)
+	}
+	for _, se := range ses.Items {
+		s.Endpoints += len(se.Spec.Endpoints)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		// Pods captured by ztunnel do not connect to istiod.
+		if rev, ok := podRevision(pod); ok && rev != ambientRevision {
+			s.Proxies++
+		}
+	}
+	return s, nil
+}
+
+// quantityOrNone formats a requested quantity, which may not be set.
+func quantityOrNone(q *resource.Quantity) string {
+	if q.IsZero() {
+		return "no amount"
+	}
+	return q.String()
+}
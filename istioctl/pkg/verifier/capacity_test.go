@@ -0,0 +1,113 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func istiodWithResources(replicas int32, cpu, memory string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.Of(replicas),
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: istiodContainer,
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}}}},
+		},
+	}
+}
+
+func sidecarPods(n int) []runtime.Object {
+	var pods []runtime.Object
+	for i := 0; i < n; i++ {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%d", i), Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	return pods
+}
+
+func TestVerifyCapacity(t *testing.T) {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Endpoints:  make([]discoveryv1.Endpoint, 1000),
+	}
+	cases := []struct {
+		name     string
+		istiod   *appsv1.Deployment
+		warnings []string
+	}{
+		{
+			name:   "sized",
+			istiod: istiodWithResources(2, "500m", "2Gi"),
+		},
+		{
+			name:     "undersized memory",
+			istiod:   istiodWithResources(2, "500m", "128Mi"),
+			warnings: []string{"istiod requests 128Mi of memory per replica"},
+		},
+		{
+			name:     "undersized CPU",
+			istiod:   istiodWithResources(1, "100m", "2Gi"),
+			warnings: []string{"1 istiod replicas request 100m of CPU each, but pushing 0 VirtualServices"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := append(sidecarPods(200), tc.istiod, slice)
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         kube.NewFakeClient(objects...),
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+			}
+			assert.NoError(t, v.verifyCapacity(context.Background()))
+			for _, w := range tc.warnings {
+				if !strings.Contains(out.String(), w) {
+					t.Fatalf("missing warning %q in output:\n%s", w, out.String())
+				}
+			}
+			if len(tc.warnings) == 0 && !strings.Contains(out.String(), "istiod is sized for 0 VirtualServices, 0 ServiceEntries, "+
+				"0 Sidecars, 1000 endpoints and 200 proxies") {
+				t.Fatalf("unexpected output:\n%s", out.String())
+			}
+		})
+	}
+}
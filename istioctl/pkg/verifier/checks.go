@@ -0,0 +1,141 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	istioctlutil "istio.io/istio/istioctl/pkg/util"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+// checkFunc is an optional check run against the live cluster once the installed
+// resources have been verified.
+type checkFunc func(v *StatusVerifier, ctx context.Context) error
+
+// optionalChecks holds all checks which can be enabled with WithChecks, keyed by name.
+var optionalChecks = map[string]checkFunc{
+	"ambient":               (*StatusVerifier).verifyAmbient,
+	"authorization-posture": (*StatusVerifier).verifyAuthorizationPosture,
+	"capacity":              (*StatusVerifier).verifyCapacity,
+	"cni-coverage":          (*StatusVerifier).verifyCNICoverage,
+	"crd-conversion":        (*StatusVerifier).verifyCRDConversion,
+	"gateway-api":           (*StatusVerifier).verifyGatewayAPI,
+	"gateway-config-sync":   (*StatusVerifier).verifyGatewayConfigSync,
+	"gateway-credentials":   (*StatusVerifier).verifyGatewayCredentials,
+	"gateway-load-balancer": (*StatusVerifier).verifyGatewayLoadBalancers,
+	"gateways":              (*StatusVerifier).verifyGateways,
+	"hostport-hairpin":      (*StatusVerifier).verifyHostPortHairpin,
+	"image-architectures":   (*StatusVerifier).verifyImageArchitectures,
+	"injection-webhooks":    (*StatusVerifier).verifyInjectionWebhooks,
+	"injector-webhook":      (*StatusVerifier).verifyInjectorWebhook,
+	"integrations":          (*StatusVerifier).verifyIntegrations,
+	"istiod-rbac":           (*StatusVerifier).verifyIstiodRBAC,
+	"kube-proxy-mode":       (*StatusVerifier).verifyKubeProxyMode,
+	"locality":              (*StatusVerifier).verifyLocalityLoadBalancing,
+	"mesh-networks":         (*StatusVerifier).verifyMeshNetworks,
+	"revision-config":       (*StatusVerifier).verifyRevisionConfig,
+	"smoke-test":            (*StatusVerifier).verifySmokeTest,
+	"validation-webhook":    (*StatusVerifier).verifyValidationWebhook,
+}
+
+// AvailableChecks returns the sorted names of all optional checks.
+func AvailableChecks() []string {
+	names := make([]string, 0, len(optionalChecks))
+	for name := range optionalChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithChecks enables the named optional checks.
+func WithChecks(names ...string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.names = append(s.checks.names, names...)
+	}
+}
+
+func (v *StatusVerifier) runChecks(ctx context.Context) error {
+	multiErr := &multierror.Error{}
+	for _, name := range v.checks.names {
+		check, f := optionalChecks[name]
+		if !f {
+			multiErr = multierror.Append(multiErr, fmt.Errorf("unknown check %q, valid checks are %v", name, AvailableChecks()))
+			continue
+		}
+		start := time.Now()
+		endSpan := v.startSpan("verifier.Check", attribute.String("check", name))
+		if err := check(v, ctx); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+		endSpan()
+		if v.checkDurations == nil {
+			v.checkDurations = map[string]time.Duration{}
+		}
+		v.checkDurations[name] = time.Since(start)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// meshConfigMap reads the mesh config map of the revision under verification from the cluster.
+func (v *StatusVerifier) meshConfigMap(ctx context.Context) (*corev1.ConfigMap, error) {
+	name := istioctlutil.DefaultMeshConfigMapName
+	if rev := v.controlPlaneOpts.Revision; rev != "" && rev != "default" {
+		name = fmt.Sprintf("%s-%s", istioctlutil.DefaultMeshConfigMapName, rev)
+	}
+	cm, err := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read configmap %q from namespace %q: %v", name, v.istioNamespace, err)
+	}
+	return cm, nil
+}
+
+// meshConfig reads the mesh config of the revision under verification from the cluster.
+func (v *StatusVerifier) meshConfig(ctx context.Context) (*meshconfig.MeshConfig, error) {
+	cm, err := v.meshConfigMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configYaml, ok := cm.Data[istioctlutil.ConfigMapKey]
+	if !ok {
+		return nil, fmt.Errorf("missing config map key %q", istioctlutil.ConfigMapKey)
+	}
+	return mesh.ApplyMeshConfigDefaults(configYaml)
+}
+
+// meshNetworks reads the mesh networks of the revision under verification from the cluster. Installs
+// without meshNetworks have no networks.
+func (v *StatusVerifier) meshNetworks(ctx context.Context) (*meshconfig.MeshNetworks, error) {
+	cm, err := v.meshConfigMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	networksYaml, ok := cm.Data[meshNetworksKey]
+	if !ok || networksYaml == "" {
+		empty := mesh.EmptyMeshNetworks()
+		return &empty, nil
+	}
+	return mesh.ParseMeshNetworks(networksYaml)
+}
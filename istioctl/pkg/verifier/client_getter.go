@@ -0,0 +1,95 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/lazy"
+)
+
+// sharedClientGetter is the REST client getter of all the resource builders of a verification. The resource
+// builders ask for the REST config of every resource they visit, to build its REST client: the config is
+// loaded once and all the clients share a rate limiter. The discovery client and the REST mapper are also
+// loaded once, so that the builders of all the IstioOperators of a run share the discovery data and the REST
+// mappings they cache, even if the factory does not cache them.
+type sharedClientGetter struct {
+	kube.PartialFactory
+	config    lazy.Lazy[*rest.Config]
+	discovery lazy.Lazy[discovery.CachedDiscoveryInterface]
+	mapper    lazy.Lazy[meta.RESTMapper]
+}
+
+func newSharedClientGetter(factory kube.PartialFactory) *sharedClientGetter {
+	return &sharedClientGetter{
+		PartialFactory: factory,
+		config: lazy.NewWithRetry(func() (*rest.Config, error) {
+			config, err := factory.ToRESTConfig()
+			if err != nil {
+				return nil, err
+			}
+			if config.RateLimiter == nil && config.QPS > 0 {
+				config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(config.QPS, config.Burst)
+			}
+			return config, nil
+		}),
+		discovery: lazy.NewWithRetry(factory.ToDiscoveryClient),
+		mapper:    lazy.NewWithRetry(factory.ToRESTMapper),
+	}
+}
+
+// ToRESTConfig returns a copy of the shared config, as the builders modify the config of each client.
+func (g *sharedClientGetter) ToRESTConfig() (*rest.Config, error) {
+	config, err := g.config.Get()
+	if err != nil {
+		return nil, err
+	}
+	return rest.CopyConfig(config), nil
+}
+
+// ToDiscoveryClient returns the discovery client shared by the builders.
+func (g *sharedClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	return g.discovery.Get()
+}
+
+// ToRESTMapper returns the REST mapper shared by the builders.
+func (g *sharedClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	return g.mapper.Get()
+}
+
+// clientGetter returns the REST client getter shared by the resource builders of the verification, across the
+// recursion into the IstioOperators of manifests and across the IstioOperators of the cluster.
+func (v *StatusVerifier) clientGetter() *sharedClientGetter {
+	if v.sharedClients == nil {
+		v.sharedClients = newSharedClientGetter(v.client.UtilFactory())
+	}
+	return v.sharedClients
+}
+
+// resourceName returns the name of the resource of a rendered object, such as deployments. The builders map every
+// object they visit with the shared REST mapper, so the name is the one the cluster serves. The objects which were
+// not mapped fall back to the names of the Istio schemas, or to the pluralized kind.
+func resourceName(info *resource.Info, un *unstructured.Unstructured) string {
+	if info.Mapping != nil {
+		return info.Mapping.Resource.Resource
+	}
+	return resourceKinds(un)
+}
@@ -0,0 +1,127 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// countingFactory counts the loads of the REST config and of the REST mapper.
+type countingFactory struct {
+	kube.PartialFactory
+	loads       int
+	mapperLoads int
+}
+
+func (f *countingFactory) ToRESTMapper() (meta.RESTMapper, error) {
+	f.mapperLoads++
+	return meta.NewDefaultRESTMapper(nil), nil
+}
+
+func (f *countingFactory) ToRESTConfig() (*rest.Config, error) {
+	f.loads++
+	return &rest.Config{Host: "https://cluster.example.com", QPS: 50, Burst: 100}, nil
+}
+
+func TestSharedClientGetter(t *testing.T) {
+	factory := &countingFactory{}
+	v := &StatusVerifier{client: kube.NewFakeClient()}
+	v.client = fakeClientWithFactory{CLIClient: v.client, factory: factory}
+
+	first, err := v.clientGetter().ToRESTConfig()
+	assert.NoError(t, err)
+	// The builders set the group version of each client on their copy of the config.
+	first.GroupVersion = &schema.GroupVersion{Group: "apps", Version: "v1"}
+	second, err := v.clientGetter().ToRESTConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, factory.loads, 1)
+	assert.Equal(t, second.GroupVersion == nil, true)
+	assert.Equal(t, second.Host, "https://cluster.example.com")
+	if first.RateLimiter == nil || first.RateLimiter != second.RateLimiter {
+		t.Fatalf("expected the clients to share a rate limiter")
+	}
+}
+
+func TestSharedRESTMapper(t *testing.T) {
+	factory := &countingFactory{}
+	v := &StatusVerifier{client: fakeClientWithFactory{CLIClient: kube.NewFakeClient(), factory: factory}}
+
+	first, err := v.clientGetter().ToRESTMapper()
+	assert.NoError(t, err)
+	second, err := v.clientGetter().ToRESTMapper()
+	assert.NoError(t, err)
+
+	assert.Equal(t, factory.mapperLoads, 1)
+	if first != second {
+		t.Fatalf("expected the builders to share a REST mapper")
+	}
+}
+
+func TestFetchResourceWithMapping(t *testing.T) {
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+	}
+	namespace := &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-system"},
+	}
+	cluster := &fakeCluster{objects: map[string]runtime.Object{
+		"/namespaces/istio-system/networkpolicies/istiod": policy,
+		"/namespaces/istio-system":                        namespace,
+	}}
+	mapped := func(obj runtime.Object, resource string, scope meta.RESTScope) *resource.Info {
+		info := cluster.info(obj)
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		info.Mapping = &meta.RESTMapping{Resource: gvk.GroupVersion().WithResource(resource), GroupVersionKind: gvk, Scope: scope}
+		return info
+	}
+	v := &StatusVerifier{istioNamespace: "istio-system"}
+	for _, info := range []*resource.Info{
+		// Pluralizing the kind would read networkpolicys.
+		mapped(policy, "networkpolicies", meta.RESTScopeNamespace),
+		// The namespace is read once, as a cluster scoped resource.
+		mapped(namespace, "namespaces", meta.RESTScopeRoot),
+	} {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		assert.NoError(t, err)
+		fetched := v.fetchResource(context.Background(), info, &unstructured.Unstructured{Object: content})
+		assert.NoError(t, fetched.err)
+	}
+}
+
+type fakeClientWithFactory struct {
+	kube.CLIClient
+	factory kube.PartialFactory
+}
+
+func (c fakeClientWithFactory) UtilFactory() kube.PartialFactory {
+	return c.factory
+}
@@ -0,0 +1,222 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"istio.io/api/annotation"
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/pkg/config/constants"
+)
+
+// istioInitContainer is the init container programming the redirection of injected pods without the CNI node agent.
+const istioInitContainer = "istio-init"
+
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to the pods of every DaemonSet, which are
+// thus scheduled on nodes with these taints whatever their spec.
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// verifyCNICoverage checks that the CNI node agent runs a ready pod on every node: on the nodes it is scheduled
+// on, and on the nodes its node selector, node affinity or tolerations exclude, where the pods of the mesh are
+// started without their traffic being redirected. Excluded nodes are failures when pods of the mesh run there,
+// warnings otherwise.
+func (v *StatusVerifier) verifyCNICoverage(ctx context.Context) error {
+	ds, err := v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).Get(ctx, cniDaemonSetName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		v.logf(VerbosityNormal, "The CNI node agent is not installed, skipping the CNI coverage checks")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the CNI daemonset: %v", err)
+	}
+	defer v.attributeResults(len(v.results), string(name.CNIComponentName))
+	covered, err := v.daemonSetReadyNodes(ctx, ds)
+	if err != nil {
+		return err
+	}
+	nodes, err := v.client.Kube().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	var meshPods map[string][]string
+	multiErr := &multierror.Error{}
+	failed := false
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if covered[node.Name] {
+			continue
+		}
+		excluded := daemonSetExcludes(ds, node)
+		if excluded == "" {
+			err := withCode(CodeCNICoverage, fmt.Errorf("no ready %s pod, the traffic of the pods of the node is not redirected", cniDaemonSetName))
+			v.reportFailure("Node", node.Name, "", err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("node %s: %v", node.Name, err))
+			failed = true
+			continue
+		}
+		if meshPods == nil {
+			if meshPods, err = v.cniMeshPodsByNode(ctx); err != nil {
+				return multierror.Append(multiErr, fmt.Errorf("failed to list pods: %v", err))
+			}
+		}
+		if pods := meshPods[node.Name]; len(pods) > 0 {
+			err := withCode(CodeCNICoverage, fmt.Errorf("%s is not scheduled (%s), the traffic of pods %s is not redirected",
+				cniDaemonSetName, excluded, strings.Join(pods, ", ")))
+			v.reportFailure("Node", node.Name, "", err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("node %s: %v", node.Name, err))
+			failed = true
+			continue
+		}
+		v.reportWarning("Node", node.Name, "", withCode(CodeCNICoverage,
+			fmt.Errorf("%s is not scheduled (%s), the traffic of the pods of the mesh started on the node will not be redirected",
+				cniDaemonSetName, excluded)))
+	}
+	if !failed {
+		v.reportSuccess("DaemonSet", cniDaemonSetName, v.istioNamespace)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// cniMeshPodsByNode returns the pods of the mesh relying on the CNI node agent for the redirection of their
+// traffic, the injected pods without an istio-init container and the ambient pods, by node.
+func (v *StatusVerifier) cniMeshPodsByNode(ctx context.Context) (map[string][]string, error) {
+	pods, err := v.scopedPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byNode := map[string][]string{}
+	for i := range pods {
+		pod := &pods[i]
+		_, injected := pod.Annotations[annotation.SidecarStatus.Name]
+		sidecar := injected && findContainer(pod.Spec.InitContainers, istioInitContainer) == nil
+		ambient := pod.Annotations[constants.AmbientRedirection] == constants.AmbientRedirectionEnabled
+		if pod.Spec.NodeName != "" && (sidecar || ambient) {
+			byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod.Namespace+"/"+pod.Name)
+		}
+	}
+	for _, pods := range byNode {
+		sort.Strings(pods)
+	}
+	return byNode, nil
+}
+
+// daemonSetReadyNodes returns the nodes the DaemonSet runs a ready pod on.
+func (v *StatusVerifier) daemonSetReadyNodes(ctx context.Context, ds *appsv1.DaemonSet) (map[string]bool, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := v.client.Kube().CoreV1().Pods(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of daemonset %s: %v", ds.Name, err)
+	}
+	covered := map[string]bool{}
+	for i := range pods.Items {
+		if isPodReady(&pods.Items[i]) {
+			covered[pods.Items[i].Spec.NodeName] = true
+		}
+	}
+	return covered, nil
+}
+
+// daemonSetExcludes returns why the DaemonSet is not scheduled on the node: its node selector or required node
+// affinity does not match the labels of the node, or it does not tolerate a taint of the node. It returns an empty
+// string when the DaemonSet is scheduled on the node.
+func daemonSetExcludes(ds *appsv1.DaemonSet, node *corev1.Node) string {
+	spec := &ds.Spec.Template.Spec
+	if !klabels.SelectorFromSet(spec.NodeSelector).Matches(klabels.Set(node.Labels)) {
+		return "node selector does not match"
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		if required := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil && !nodeSelectorMatches(required, node) {
+			return "node affinity does not match"
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !tolerates(spec.Tolerations, taint) && !tolerates(daemonSetTolerations, taint) {
+			return fmt.Sprintf("taint %s:%s is not tolerated", taint.Key, taint.Effect)
+		}
+	}
+	return ""
+}
+
+func tolerates(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeSelectorMatches returns whether the node matches one of the terms of the node selector.
+func nodeSelectorMatches(ns *corev1.NodeSelector, node *corev1.Node) bool {
+	fields := klabels.Set{"metadata.name": node.Name}
+	for _, term := range ns.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if requirementsMatch(term.MatchExpressions, klabels.Set(node.Labels)) && requirementsMatch(term.MatchFields, fields) {
+			return true
+		}
+	}
+	return false
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func requirementsMatch(requirements []corev1.NodeSelectorRequirement, set klabels.Set) bool {
+	for _, req := range requirements {
+		op, f := nodeSelectorOperators[req.Operator]
+		if !f {
+			return false
+		}
+		r, err := klabels.NewRequirement(req.Key, op, req.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/api/annotation"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// cniCoverageObjects are the CNI node agent running on node-1 and node-2, and node-3, dedicated to GPU workloads
+// with a taint.
+func cniCoverageObjects() map[string]runtime.Object {
+	cniPod := func(node string) *corev1.Pod {
+		pod := readyPod("istio-cni-node-"+node, "")
+		pod.Labels = map[string]string{"k8s-app": cniDaemonSetName}
+		pod.Spec.NodeName = node
+		return pod
+	}
+	return map[string]runtime.Object{
+		"node-1": &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: map[string]string{"kubernetes.io/arch": "amd64"}}},
+		"node-2": &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2", Labels: map[string]string{"kubernetes.io/arch": "amd64"}}},
+		"node-3": &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-3", Labels: map[string]string{"kubernetes.io/arch": "arm64"}},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+			}},
+		},
+		"cni": &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: cniDaemonSetName, Namespace: "istio-system"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": cniDaemonSetName}},
+			},
+		},
+		"pod-1": cniPod("node-1"),
+		"pod-2": cniPod("node-2"),
+	}
+}
+
+func TestVerifyCNICoverage(t *testing.T) {
+	injectedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "trainer", Namespace: "default", Annotations: map[string]string{annotation.SidecarStatus.Name: "{}"}},
+		Spec:       corev1.PodSpec{NodeName: "node-3"},
+	}
+	cases := []struct {
+		name    string
+		modify  func(objects map[string]runtime.Object)
+		wantErr []string
+		want    []string
+	}{
+		{
+			name: "tainted node without pods of the mesh",
+			want: []string{
+				"✔ DaemonSet: istio-cni-node.istio-system checked successfully",
+				"! Node: node-3.: istio-cni-node is not scheduled (taint dedicated:NoSchedule is not tolerated)",
+			},
+		},
+		{
+			name: "not installed",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "cni")
+			},
+			want: []string{"The CNI node agent is not installed"},
+		},
+		{
+			name: "pod not ready",
+			modify: func(objects map[string]runtime.Object) {
+				objects["pod-2"].(*corev1.Pod).Status.Conditions = nil
+			},
+			wantErr: []string{"node node-2: no ready istio-cni-node pod, the traffic of the pods of the node is not redirected"},
+		},
+		{
+			name: "tainted node with injected pods",
+			modify: func(objects map[string]runtime.Object) {
+				objects["injected"] = injectedPod
+			},
+			wantErr: []string{"node node-3: istio-cni-node is not scheduled (taint dedicated:NoSchedule is not tolerated), " +
+				"the traffic of pods default/trainer is not redirected"},
+		},
+		{
+			name: "tainted node with pods redirected by istio-init",
+			modify: func(objects map[string]runtime.Object) {
+				pod := injectedPod.DeepCopy()
+				pod.Spec.InitContainers = []corev1.Container{{Name: istioInitContainer}}
+				objects["injected"] = pod
+			},
+			want: []string{"! Node: node-3."},
+		},
+		{
+			name: "tolerated taint",
+			modify: func(objects map[string]runtime.Object) {
+				objects["cni"].(*appsv1.DaemonSet).Spec.Template.Spec.Tolerations = []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpExists},
+				}
+			},
+			wantErr: []string{"node node-3: no ready istio-cni-node pod"},
+		},
+		{
+			name: "node affinity",
+			modify: func(objects map[string]runtime.Object) {
+				objects["cni"].(*appsv1.DaemonSet).Spec.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"arm64"}},
+						},
+					}}},
+				}}
+				objects["injected"] = injectedPod
+			},
+			wantErr: []string{"node node-3: istio-cni-node is not scheduled (node affinity does not match)"},
+		},
+		{
+			name: "node selector",
+			modify: func(objects map[string]runtime.Object) {
+				objects["cni"].(*appsv1.DaemonSet).Spec.Template.Spec.NodeSelector = map[string]string{"kubernetes.io/arch": "amd64"}
+			},
+			want: []string{"! Node: node-3.: istio-cni-node is not scheduled (node selector does not match)"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objects := cniCoverageObjects()
+			if c.modify != nil {
+				c.modify(objects)
+			}
+			var objs []runtime.Object
+			for _, o := range objects {
+				objs = append(objs, o)
+			}
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:         kube.NewFakeClient(objs...),
+				istioNamespace: "istio-system",
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+				warningMarker:  "!",
+			}
+			err := v.verifyCNICoverage(context.Background())
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+			} else if err == nil {
+				t.Fatalf("expected errors %v", c.wantErr)
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+			}
+			for _, want := range c.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			for _, r := range v.results {
+				assert.Equal(t, componentName(r.component), "CNI")
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// cniConfigMapName is the ConfigMap holding the network config the CNI node agent installs on each node.
+	cniConfigMapName = "istio-cni-config"
+	cniNetworkConfig = "cni_network_config"
+)
+
+// cniExclusions returns the namespaces excluded from the capture of the CNI plugin by the network config of
+// the ConfigMap.
+func cniExclusions(cm *corev1.ConfigMap) ([]string, error) {
+	raw, ok := cm.Data[cniNetworkConfig]
+	if !ok {
+		return nil, fmt.Errorf("missing config map key %q", cniNetworkConfig)
+	}
+	var conf struct {
+		Kubernetes struct {
+			ExcludeNamespaces []string `json:"exclude_namespaces"`
+		} `json:"kubernetes"`
+	}
+	if err := json.Unmarshal([]byte(raw), &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse the CNI network config: %v", err)
+	}
+	return conf.Kubernetes.ExcludeNamespaces, nil
+}
+
+// verifyCNIExclusions checks that the CNI ConfigMap of the cluster excludes the namespaces the installation
+// excludes, such as istio-system and kube-system, and that it excludes no namespace of the mesh: the pods of
+// excluded namespaces are started without traffic capture, so their sidecars are silently bypassed.
+func (v *StatusVerifier) verifyCNIExclusions(ctx context.Context, rendered *unstructured.Unstructured, namespace string) error {
+	live, err := v.client.Kube().CoreV1().ConfigMaps(namespace).Get(ctx, cniConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	namespaces, err := v.client.Kube().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	return compareCNIExclusions(rendered, live, namespaces.Items)
+}
+
+func compareCNIExclusions(rendered *unstructured.Unstructured, live *corev1.ConfigMap, namespaces []corev1.Namespace) error {
+	expectedConfigMap := &corev1.ConfigMap{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rendered.Object, expectedConfigMap); err != nil {
+		return fmt.Errorf("failed to read rendered config map: %v", err)
+	}
+	want, err := cniExclusions(expectedConfigMap)
+	if err != nil {
+		return fmt.Errorf("rendered config map: %v", err)
+	}
+	got, err := cniExclusions(live)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	if missing := sets.New(want...).Difference(sets.New(got...)); missing.Len() > 0 {
+		problems = append(problems, fmt.Sprintf("namespaces %s are excluded by the installation but not by the CNI plugin",
+			strings.Join(sets.SortedList(missing), ", ")))
+	}
+	excluded := sets.New(got...)
+	var meshExcluded []string
+	for _, ns := range namespaces {
+		if excluded.Contains(ns.Name) && meshNamespace(ns) {
+			meshExcluded = append(meshExcluded, ns.Name)
+		}
+	}
+	if len(meshExcluded) > 0 {
+		problems = append(problems, fmt.Sprintf("namespaces %s are part of the mesh but excluded by the CNI plugin, "+
+			"their pods are started without traffic capture", strings.Join(meshExcluded, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("exclusions of the CNI plugin are misconfigured: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func cniConfigMap(excluded ...string) *corev1.ConfigMap {
+	quoted := make([]string, 0, len(excluded))
+	for _, ns := range excluded {
+		quoted = append(quoted, fmt.Sprintf("%q", ns))
+	}
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: cniConfigMapName, Namespace: "istio-system"},
+		Data: map[string]string{
+			cniNetworkConfig: fmt.Sprintf(`{"type": "istio-cni", "kubernetes": {"exclude_namespaces": [%s]}}`,
+				strings.Join(quoted, ", ")),
+		},
+	}
+}
+
+func TestCompareCNIExclusions(t *testing.T) {
+	namespaces := []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "istio-system"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"istio-injection": "enabled"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "canary", Labels: map[string]string{"istio.io/rev": "canary"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+	}
+	cases := []struct {
+		name     string
+		rendered *corev1.ConfigMap
+		live     *corev1.ConfigMap
+		wantErr  []string
+	}{
+		{
+			name:     "unchanged",
+			rendered: cniConfigMap("istio-system", "kube-system"),
+			live:     cniConfigMap("kube-system", "istio-system"),
+		},
+		{
+			name:     "namespaces outside the mesh may be excluded",
+			rendered: cniConfigMap("istio-system", "kube-system"),
+			live:     cniConfigMap("istio-system", "kube-system", "legacy"),
+		},
+		{
+			name:     "missing exclusions",
+			rendered: cniConfigMap("istio-system", "kube-system"),
+			live:     cniConfigMap("legacy"),
+			wantErr:  []string{"namespaces istio-system, kube-system are excluded by the installation but not by the CNI plugin"},
+		},
+		{
+			name:     "mesh namespaces excluded",
+			rendered: cniConfigMap("istio-system", "kube-system"),
+			live:     cniConfigMap("istio-system", "kube-system", "default", "canary"),
+			wantErr:  []string{"namespaces default, canary are part of the mesh but excluded by the CNI plugin"},
+		},
+		{
+			name:     "invalid network config",
+			rendered: cniConfigMap("istio-system"),
+			live: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: cniConfigMapName, Namespace: "istio-system"},
+				Data:       map[string]string{cniNetworkConfig: "{"},
+			},
+			wantErr: []string{"failed to parse the CNI network config"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := compareCNIExclusions(toUnstructured(t, c.rendered), c.live, namespaces)
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"errors"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"istio.io/istio/pkg/slices"
+	"istio.io/istio/pkg/url"
+)
+
+// FailureCode identifies the problem a check failed or warned with. Codes are stable across releases, so that
+// support tooling can map them to runbooks.
+type FailureCode string
+
+const (
+	CodeAPIAccess               FailureCode = "IST-VER-API-ACCESS"
+	CodeResourceMissing         FailureCode = "IST-VER-RESOURCE-MISSING"
+	CodeDeploymentUnavailable   FailureCode = "IST-VER-DEPLOY-UNAVAILABLE"
+	CodeDeploymentEnvDrift      FailureCode = "IST-VER-DEPLOY-ENV-DRIFT"
+	CodeDaemonSetUnavailable    FailureCode = "IST-VER-DAEMONSET-UNAVAILABLE"
+	CodeAmbientUnhealthy        FailureCode = "IST-VER-AMBIENT-UNHEALTHY"
+	CodeCNICoverage             FailureCode = "IST-VER-CNI-COVERAGE"
+	CodeJobFailed               FailureCode = "IST-VER-JOB-FAILED"
+	CodeStorageUnbound          FailureCode = "IST-VER-STORAGE-UNBOUND"
+	CodeCNIExclusions           FailureCode = "IST-VER-CNI-EXCLUSIONS"
+	CodeCRDConversion           FailureCode = "IST-VER-CRD-CONVERSION"
+	CodeCapacityLow             FailureCode = "IST-VER-CAPACITY-LOW"
+	CodeIstiodRBAC              FailureCode = "IST-VER-ISTIOD-RBAC"
+	CodeAuthzPermissive         FailureCode = "IST-VER-AUTHZ-PERMISSIVE"
+	CodeInjectionConflict       FailureCode = "IST-VER-INJECTION-CONFLICT"
+	CodeInjectorUnreachable     FailureCode = "IST-VER-INJECTOR-UNREACHABLE"
+	CodeValidationWebhook       FailureCode = "IST-VER-VALIDATION-WEBHOOK"
+	CodeVersionSkew             FailureCode = "IST-VER-VERSION-SKEW"
+	CodeRevisionInconsistent    FailureCode = "IST-VER-REVISION-INCONSISTENT"
+	CodeLocalityUnmatched       FailureCode = "IST-VER-LOCALITY-UNMATCHED"
+	CodeLocalityFailover        FailureCode = "IST-VER-LOCALITY-FAILOVER"
+	CodeNetworkMisconfigured    FailureCode = "IST-VER-NETWORK-MISCONFIGURED"
+	CodeGatewayCredential       FailureCode = "IST-VER-GATEWAY-CREDENTIAL"
+	CodeGatewayLoadBalancer     FailureCode = "IST-VER-GATEWAY-LB"
+	CodeGatewayNotSynced        FailureCode = "IST-VER-GATEWAY-NOT-SYNCED"
+	CodeGatewayUnhealthy        FailureCode = "IST-VER-GATEWAY-UNHEALTHY"
+	CodeGatewayAPI              FailureCode = "IST-VER-GATEWAY-API"
+	CodeHostPortBypass          FailureCode = "IST-VER-HOSTPORT-BYPASS"
+	CodeNodeDataPlane           FailureCode = "IST-VER-NODE-DATAPLANE"
+	CodeImageArchitecture       FailureCode = "IST-VER-IMAGE-ARCH"
+	CodeIntegrationUnresolvable FailureCode = "IST-VER-INTEGRATION-UNRESOLVABLE"
+	CodeSmokeTestFailed         FailureCode = "IST-VER-SMOKE-TEST-FAILED"
+	CodeTimeout                 FailureCode = "IST-VER-TIMEOUT"
+	// CodeCheckFailed is the code of the problems no other code describes.
+	CodeCheckFailed FailureCode = "IST-VER-CHECK-FAILED"
+)
+
+// remediation is what to do about the problems of a code.
+type remediation struct {
+	hint   string
+	docURL string
+}
+
+var remediations = map[FailureCode]remediation{
+	CodeAPIAccess: {
+		hint:   "Check the connectivity to the API server and that your credentials may read the Istio resources.",
+		docURL: url.OpsURL + "diagnostic-tools/istioctl/",
+	},
+	CodeResourceMissing: {
+		hint:   "Reinstall with the same profile and values, or verify against the manifest actually installed.",
+		docURL: url.SetupURL + "install/istioctl/",
+	},
+	CodeDeploymentUnavailable: {
+		hint:   "Describe the pods of the Deployment and check their events and logs for scheduling or startup failures.",
+		docURL: url.OpsURL + "diagnostic-tools/component-logging/",
+	},
+	CodeDeploymentEnvDrift: {
+		hint:   "The environment of istiod was changed outside of the installation, reapply the install to restore it.",
+		docURL: url.SetupURL + "install/istioctl/",
+	},
+	CodeDaemonSetUnavailable: {
+		hint:   "Check the pods of the DaemonSet on the nodes where they are not ready, and their logs.",
+		docURL: url.SetupURL + "additional-setup/cni/",
+	},
+	CodeAmbientUnhealthy: {
+		hint:   "Install ztunnel and the CNI node agent with ambient enabled, and check the pods of ztunnel and of the waypoints.",
+		docURL: url.OpsURL + "ambient/",
+	},
+	CodeCNICoverage: {
+		hint:   "Add the tolerations of the taints of the nodes to the CNI node agent, or widen its node selector, so that it runs on every node of the mesh.",
+		docURL: url.SetupURL + "additional-setup/cni/",
+	},
+	CodeJobFailed: {
+		hint:   "Check the logs of the pods of the Job, then delete the Job and reinstall to rerun it.",
+		docURL: url.OpsURL + "diagnostic-tools/component-logging/",
+	},
+	CodeStorageUnbound: {
+		hint:   "Check that a StorageClass can provision the claims of the component and that the claims are bound.",
+		docURL: url.SetupURL + "platform-setup/",
+	},
+	CodeCNIExclusions: {
+		hint:   "Add the namespaces of the control plane to the excluded namespaces of the Istio CNI plugin.",
+		docURL: url.SetupURL + "additional-setup/cni/",
+	},
+	CodeCRDConversion: {
+		hint:   "Check that the conversion webhook of the CustomResourceDefinition is reachable and serves its versions.",
+		docURL: url.OpsURL + "common-problems/validation/",
+	},
+	CodeCapacityLow: {
+		hint:   "Raise the resource requests or the replicas of istiod for the size of the mesh.",
+		docURL: url.OpsURL + "deployment/performance-and-scalability/",
+	},
+	CodeIstiodRBAC: {
+		hint:   "Restore the rules of the ClusterRole of istiod from the chart, or bind the service account of istiod to them.",
+		docURL: url.OpsURL + "best-practices/security/",
+	},
+	CodeAuthzPermissive: {
+		hint:   "Apply a default deny AuthorizationPolicy and require mutual TLS in the namespace.",
+		docURL: url.TasksURL + "security/authorization/",
+	},
+	CodeInjectionConflict: {
+		hint:   "Label the namespace for a single revision, and remove the labels selecting other injectors.",
+		docURL: url.OpsURL + "common-problems/injection/",
+	},
+	CodeInjectorUnreachable: {
+		hint:   "Check the Service, port and caBundle of the webhook against the istiod Service and its serving certificate, and the logs of istiod.",
+		docURL: url.OpsURL + "common-problems/injection/",
+	},
+	CodeValidationWebhook: {
+		hint:   "Check the Service and caBundle of the istiod validating webhook, its failure policy, and the logs of istiod for validation errors.",
+		docURL: url.OpsURL + "common-problems/validation/",
+	},
+	CodeVersionSkew: {
+		hint:   "Complete the upgrade of the revision so that all its components run the same version.",
+		docURL: url.SetupURL + "upgrade/",
+	},
+	CodeRevisionInconsistent: {
+		hint:   "Reinstall the revision to restore the ConfigMaps and Secrets that were deleted or edited.",
+		docURL: url.SetupURL + "upgrade/canary/",
+	},
+	CodeLocalityUnmatched: {
+		hint:   "Fix the locality to match the region, zone and subzone labels of the nodes.",
+		docURL: url.TasksURL + "traffic-management/locality-load-balancing/",
+	},
+	CodeLocalityFailover: {
+		hint:   "Add an outlierDetection to the traffic policy, failover only happens when endpoints are ejected.",
+		docURL: url.TasksURL + "traffic-management/locality-load-balancing/failover/",
+	},
+	CodeNetworkMisconfigured: {
+		hint:   "Fix the mesh networks, the network labels or the east-west gateway of the network as described in the reason.",
+		docURL: url.SetupURL + "install/multicluster/",
+	},
+	CodeGatewayCredential: {
+		hint:   "Create the TLS Secret of the credential in the namespace of the gateway, with a certificate for its hosts.",
+		docURL: url.TasksURL + "traffic-management/ingress/secure-ingress/",
+	},
+	CodeGatewayLoadBalancer: {
+		hint:   "Check the events of the Service and that the cluster can provision load balancers.",
+		docURL: url.TasksURL + "traffic-management/ingress/ingress-control/",
+	},
+	CodeGatewayNotSynced: {
+		hint:   "Check the connection of the gateway to istiod with istioctl proxy-status.",
+		docURL: url.OpsURL + "diagnostic-tools/proxy-cmd/",
+	},
+	CodeGatewayUnhealthy: {
+		hint:   "Describe the gateway pods and Services, and check the Gateways selecting the gateway with istioctl analyze.",
+		docURL: url.TasksURL + "traffic-management/ingress/ingress-control/",
+	},
+	CodeGatewayAPI: {
+		hint:   "Check the status conditions of the Gateway and GatewayClass, and the logs of istiod for the deployment of the gateway.",
+		docURL: url.TasksURL + "traffic-management/ingress/gateway-api/",
+	},
+	CodeHostPortBypass: {
+		hint:   "Stop excluding the hostPort container ports from the inbound capture of the pod.",
+		docURL: url.OpsURL + "common-problems/network-issues/",
+	},
+	CodeNodeDataPlane: {
+		hint:   "Change the configuration of the node data plane as described in the reason.",
+		docURL: url.OpsURL + "common-problems/network-issues/",
+	},
+	CodeImageArchitecture: {
+		hint:   "Use multi-arch images for every architecture of the nodes, or keep the pods of the mesh off the node pools the images do not support.",
+		docURL: url.SetupURL + "platform-setup/",
+	},
+	CodeIntegrationUnresolvable: {
+		hint:   "Fix the host and port of the integration to a Service or ServiceEntry that the proxies can reach.",
+		docURL: url.TasksURL + "observability/",
+	},
+	CodeSmokeTestFailed: {
+		hint:   "Check the logs of istiod and of the sidecar of the smoke test pod for the failing step.",
+		docURL: url.OpsURL + "diagnostic-tools/component-logging/",
+	},
+	CodeTimeout: {
+		hint:   "Check the responsiveness of the API server and of the API groups of the resource, or raise the timeouts.",
+		docURL: url.OpsURL + "diagnostic-tools/istioctl/",
+	},
+	CodeCheckFailed: {
+		hint:   "See the reason of the check.",
+		docURL: url.OpsURL + "diagnostic-tools/istioctl/",
+	},
+}
+
+// kindCodes are the codes of the problems of the kinds only checked by a single check.
+var kindCodes = map[string]FailureCode{
+	"Gateway proxy":      CodeGatewayNotSynced,
+	"Gateway service":    CodeGatewayLoadBalancer,
+	gatewayKind:          CodeGatewayUnhealthy,
+	"Node data plane":    CodeNodeDataPlane,
+	"Smoke test":         CodeSmokeTestFailed,
+	"Extension provider": CodeIntegrationUnresolvable,
+	"Proxy integration":  CodeIntegrationUnresolvable,
+	"WasmPlugin":         CodeIntegrationUnresolvable,
+	"MeshConfig":         CodeLocalityUnmatched,
+	"Revision":           CodeVersionSkew,
+	istiodPermissionKind: CodeIstiodRBAC,
+}
+
+// FailureCodes returns all the codes problems are reported with, sorted.
+func FailureCodes() []FailureCode {
+	codes := make([]FailureCode, 0, len(remediations))
+	for code := range remediations {
+		codes = append(codes, code)
+	}
+	return slices.Sort(codes)
+}
+
+// Hint returns a short description of what to do about the problems of the code.
+func (c FailureCode) Hint() string {
+	return remediations[c].hint
+}
+
+// DocURL returns the documentation of the problems of the code.
+func (c FailureCode) DocURL() string {
+	return remediations[c].docURL
+}
+
+// codedError is an error of a check with the code of its problem.
+type codedError struct {
+	code FailureCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// withCode attaches the code of its problem to the error of a check.
+func withCode(code FailureCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// failureCode returns the code of a problem found by a check of kind. Timeouts and failures to access the API
+// server take precedence, as they are the cause of whatever the check was after, then the code attached to err,
+// then missing resources and the code of the kind.
+func failureCode(kind string, err error) FailureCode {
+	if err != nil {
+		if isTimeout(err) {
+			return CodeTimeout
+		}
+		if isAPIAccessError(err) {
+			return CodeAPIAccess
+		}
+		var cerr *codedError
+		if errors.As(err, &cerr) {
+			return cerr.code
+		}
+		if kerrors.IsNotFound(err) {
+			return CodeResourceMissing
+		}
+	}
+	if code, f := kindCodes[kind]; f {
+		return code
+	}
+	return CodeCheckFailed
+}
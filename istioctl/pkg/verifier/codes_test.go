@@ -0,0 +1,109 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestFailureCode(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	notFound := kerrors.NewNotFound(deployments, "istiod")
+	cases := []struct {
+		name string
+		kind string
+		err  error
+		want FailureCode
+	}{
+		{
+			name: "coded",
+			kind: "Deployment",
+			err:  istioVerificationFailureError("istio.yaml", withCode(CodeDeploymentUnavailable, errors.New("not ready"))),
+			want: CodeDeploymentUnavailable,
+		},
+		{
+			name: "missing",
+			kind: "Service",
+			err:  istioVerificationFailureError("istio.yaml", fmt.Errorf("the required Service:istiod is not ready due to: %w", notFound)),
+			want: CodeResourceMissing,
+		},
+		{
+			name: "access error over code",
+			kind: "Deployment",
+			err:  withCode(CodeDeploymentUnavailable, kerrors.NewForbidden(deployments, "istiod", errors.New("denied"))),
+			want: CodeAPIAccess,
+		},
+		{
+			name: "timeout over code",
+			kind: "PersistentVolumeClaim",
+			err:  withCode(CodeStorageUnbound, timeoutError(&url.Error{Op: "Get", URL: "https://cluster", Err: context.DeadlineExceeded})),
+			want: CodeTimeout,
+		},
+		{name: "kind", kind: "Gateway proxy", err: errors.New("not synced"), want: CodeGatewayNotSynced},
+		{name: "kind without error", kind: "Smoke test", want: CodeSmokeTestFailed},
+		{name: "other", kind: "ClusterRole", err: errors.New("invalid"), want: CodeCheckFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, failureCode(tc.kind, tc.err), tc.want)
+		})
+	}
+}
+
+func TestFailureCodesRemediations(t *testing.T) {
+	for _, code := range FailureCodes() {
+		if !strings.HasPrefix(string(code), "IST-VER-") {
+			t.Errorf("code %s does not start with IST-VER-", code)
+		}
+		if code.Hint() == "" || !strings.HasPrefix(code.DocURL(), "https://istio.io/") {
+			t.Errorf("code %s has no remediation: hint %q, doc %q", code, code.Hint(), code.DocURL())
+		}
+	}
+}
+
+func TestResultsFailureCodes(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{}
+	WithOutput(&out)(v)
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportFailure("Deployment", "istio-ingressgateway", "istio-system",
+		istioVerificationFailureError("istio.yaml", withCode(CodeDeploymentUnavailable, errors.New("not ready"))))
+	v.record("Smoke test", "", "", checkFailed, "no response")
+
+	results := v.Results()
+	assert.Equal(t, results[0].Code, "")
+	assert.Equal(t, results[0].Hint, "")
+	assert.Equal(t, results[1].Code, "IST-VER-DEPLOY-UNAVAILABLE")
+	assert.Equal(t, results[1].Hint, CodeDeploymentUnavailable.Hint())
+	assert.Equal(t, results[1].DocURL, CodeDeploymentUnavailable.DocURL())
+	assert.Equal(t, results[2].Code, "IST-VER-SMOKE-TEST-FAILED")
+}
+
+// coded returns r with the code of its problem and its remediation.
+func coded(r CheckResult, code FailureCode) CheckResult {
+	r.Code, r.Hint, r.DocURL = string(code), code.Hint(), code.DocURL()
+	return r
+}
@@ -0,0 +1,221 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/api/label"
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	componentLabel = name.OperatorAPINamespace + "/component"
+	versionLabel   = name.OperatorAPINamespace + "/version"
+)
+
+// componentImage describes the image a container of an Istio component runs.
+type componentImage struct {
+	component string
+	kind      string
+	name      string
+	revision  string
+	container string
+	image     string
+	// digest is the digest of the image the running pods resolved, if any pod is running.
+	digest  string
+	version string
+}
+
+// reportComponentImages prints the image, digest and version of every Istio component in the
+// Istio namespace, and warns about revisions whose components run different versions.
+func (v *StatusVerifier) reportComponentImages(ctx context.Context) {
+	images, err := v.componentImages(ctx)
+	if err != nil {
+		v.logger.LogAndPrintf("%s Could not list Istio component images: %v", v.warningMark(), err)
+		return
+	}
+	if len(images) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 8, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COMPONENT\tWORKLOAD\tREVISION\tCONTAINER\tIMAGE\tDIGEST\tVERSION")
+	for _, ci := range images {
+		_, _ = fmt.Fprintf(w, "%s\t%s/%s\t%s\t%s\t%s\t%s\t%s\n", ci.component, ci.kind, ci.name, ci.revision,
+			ci.container, ci.image, valueOrNone(ci.digest), valueOrNone(ci.version))
+	}
+	_ = w.Flush()
+	v.logf(VerbosityNormal, "%s", strings.TrimSuffix(b.String(), "\n"))
+
+	for _, revision := range mixedVersionRevisions(images) {
+		v.reportWarning("Revision", revision, v.istioNamespace,
+			fmt.Errorf("components run different versions %v, a partial upgrade may not have completed", revisionVersions(images, revision)))
+	}
+}
+
+// componentImages returns the images of all Deployments and DaemonSets in the Istio namespace
+// which belong to an Istio component, sorted by component, workload and container.
+func (v *StatusVerifier) componentImages(ctx context.Context) ([]componentImage, error) {
+	opts := metav1.ListOptions{LabelSelector: componentLabel}
+	var images []componentImage
+	deployments, err := v.client.Kube().AppsV1().Deployments(v.istioNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deployments.Items {
+		cis, err := v.workloadImages(ctx, "Deployment", d.ObjectMeta, d.Spec.Selector, d.Spec.Template.Spec)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, cis...)
+	}
+	daemonSets, err := v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, ds := range daemonSets.Items {
+		cis, err := v.workloadImages(ctx, "DaemonSet", ds.ObjectMeta, ds.Spec.Selector, ds.Spec.Template.Spec)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, cis...)
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		a, b := images[i], images[j]
+		if a.component != b.component {
+			return a.component < b.component
+		}
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.container < b.container
+	})
+	return images, nil
+}
+
+func (v *StatusVerifier) workloadImages(ctx context.Context, kind string, meta metav1.ObjectMeta, selector *metav1.LabelSelector,
+	spec corev1.PodSpec,
+) ([]componentImage, error) {
+	digests := map[string]string{}
+	if selector != nil {
+		s, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		digests, err = v.runningImageDigests(ctx, meta.Namespace, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	revision := meta.Labels[label.IoIstioRev.Name]
+	if revision == "" {
+		revision = "default"
+	}
+	images := make([]componentImage, 0, len(spec.Containers))
+	for _, c := range spec.Containers {
+		version := meta.Labels[versionLabel]
+		if version == "" {
+			version = imageTag(c.Image)
+		}
+		images = append(images, componentImage{
+			component: meta.Labels[componentLabel],
+			kind:      kind,
+			name:      meta.Name,
+			revision:  revision,
+			container: c.Name,
+			image:     c.Image,
+			digest:    digests[c.Name],
+			version:   version,
+		})
+	}
+	return images, nil
+}
+
+// runningImageDigests returns the image digest of each container of the first running pod
+// matching the selector, keyed by container name.
+func (v *StatusVerifier) runningImageDigests(ctx context.Context, namespace string, selector klabels.Selector) (map[string]string, error) {
+	pods, err := v.client.Kube().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if _, digest, found := strings.Cut(cs.ImageID, "@"); found {
+				digests[cs.Name] = digest
+			}
+		}
+		break
+	}
+	return digests, nil
+}
+
+// imageTag returns the tag of an image reference, or an empty string if it is untagged.
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// mixedVersionRevisions returns the sorted revisions whose components run more than one version.
+func mixedVersionRevisions(images []componentImage) []string {
+	var revisions []string
+	seen := sets.New[string]()
+	for _, ci := range images {
+		if seen.InsertContains(ci.revision) {
+			continue
+		}
+		if len(revisionVersions(images, ci.revision)) > 1 {
+			revisions = append(revisions, ci.revision)
+		}
+	}
+	sort.Strings(revisions)
+	return revisions
+}
+
+// revisionVersions returns the sorted, distinct known versions of the components of a revision.
+func revisionVersions(images []componentImage, revision string) []string {
+	versions := sets.New[string]()
+	for _, ci := range images {
+		if ci.revision == revision && ci.version != "" {
+			versions.Insert(ci.version)
+		}
+	}
+	return sets.SortedList(versions)
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func componentDeployment(name, component, image string, labels map[string]string) *appsv1.Deployment {
+	l := map[string]string{componentLabel: component, "app": name}
+	for k, v := range labels {
+		l[k] = v
+	}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system", Labels: l},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: image}}},
+			},
+		},
+	}
+}
+
+func TestReportComponentImages(t *testing.T) {
+	client := kube.NewFakeClient(
+		componentDeployment("istiod", "Pilot", "docker.io/istio/pilot:1.20.1", map[string]string{versionLabel: "1.20.1"}),
+		componentDeployment("istio-ingressgateway", "IngressGateways", "docker.io/istio/proxyv2:1.19.3", nil),
+		componentDeployment("istiod-canary", "Pilot", "docker.io/istio/pilot:1.20.1", map[string]string{"istio.io/rev": "canary"}),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "istiod-abc", Namespace: "istio-system", Labels: map[string]string{"app": "istiod"}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:    "main",
+					ImageID: "docker.io/istio/pilot@sha256:0123",
+				}},
+			},
+		},
+	)
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+	}
+	v.reportComponentImages(context.Background())
+
+	// Collapse the table padding so rows can be matched regardless of column widths.
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	got := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"Pilot Deployment/istiod default main docker.io/istio/pilot:1.20.1 sha256:0123 1.20.1",
+		"IngressGateways Deployment/istio-ingressgateway default main docker.io/istio/proxyv2:1.19.3 - 1.19.3",
+		"Pilot Deployment/istiod-canary canary main docker.io/istio/pilot:1.20.1 - 1.20.1",
+		"! Revision: default.istio-system: components run different versions [1.19.3 1.20.1]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(out.String(), "Revision: canary") {
+		t.Errorf("unexpected mixed version warning for canary revision:\n%s", out.String())
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	for image, want := range map[string]string{
+		"docker.io/istio/pilot:1.20.1":             "1.20.1",
+		"localhost:5000/istio/pilot":               "",
+		"localhost:5000/istio/pilot:latest":        "latest",
+		"docker.io/istio/pilot:1.20.1@sha256:0123": "1.20.1",
+		"pilot": "",
+	} {
+		assert.Equal(t, imageTag(image), want, image)
+	}
+}
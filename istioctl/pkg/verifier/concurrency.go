@@ -0,0 +1,187 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-multierror"
+	"golang.org/x/sync/errgroup"
+	appsv1 "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultConcurrency is how many installed resources are read from the cluster at once by default.
+const DefaultConcurrency = 10
+
+// WithConcurrency reads up to concurrency installed resources from the cluster at once, DefaultConcurrency by
+// default, which speeds up the verification of installations with many resources on slow API servers. The
+// resources are still checked, and their results recorded, in the order of the manifests. One reads them one
+// after the other.
+func WithConcurrency(concurrency int) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.concurrency = concurrency
+	}
+}
+
+// fetchedResource is the outcome of reading an installed resource from the cluster.
+type fetchedResource struct {
+	// obj is the resource read, typed for the kinds whose status is checked.
+	obj runtime.Object
+	err error
+}
+
+// typedKinds are the kinds whose status is checked, with the type they are read into.
+var typedKinds = map[string]func() runtime.Object{
+	"Deployment":  func() runtime.Object { return &appsv1.Deployment{} },
+	"Job":         func() runtime.Object { return &v1batch.Job{} },
+	"StatefulSet": func() runtime.Object { return &appsv1.StatefulSet{} },
+	"DaemonSet":   func() runtime.Object { return &appsv1.DaemonSet{} },
+}
+
+// fetchResource reads the installed resource of a rendered one from the cluster. The resources of the kinds whose
+// check does not read them, IstioOperators and PersistentVolumeClaims, are not read.
+func (v *StatusVerifier) fetchResource(ctx context.Context, info *resource.Info, un *unstructured.Unstructured) fetchedResource {
+	kind, name, namespace := un.GetKind(), un.GetName(), un.GetNamespace()
+	if namespace == "" {
+		namespace = v.istioNamespace
+	}
+	kinds := resourceName(info, un)
+	ref := resourceRef(kind, name, namespace)
+	if newObj, f := typedKinds[kind]; f {
+		obj := newObj()
+		err := v.withRetry(ctx, ref, func() error {
+			return info.Client.
+				Get().
+				Resource(kinds).
+				Namespace(namespace).
+				Name(name).
+				VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
+				Do(ctx).
+				Into(obj)
+		})
+		return fetchedResource{obj: obj, err: err}
+	}
+	if kind == "IstioOperator" || kind == "PersistentVolumeClaim" {
+		return fetchedResource{}
+	}
+	var result rest.Result
+	if info.Mapping != nil {
+		// The REST mapping tells the scope of the resource, which is read once.
+		_ = v.withRetry(ctx, ref, func() error {
+			result = info.Client.
+				Get().
+				Resource(kinds).
+				NamespaceIfScoped(namespace, info.Namespaced()).
+				Name(name).
+				Do(ctx)
+			return result.Error()
+		})
+		return fetchedResource{err: result.Error()}
+	}
+	// The scope of the resource is not known, so it is read as a cluster scoped resource first.
+	_ = v.withRetry(ctx, ref, func() error {
+		result = info.Client.
+			Get().
+			Resource(kinds).
+			Name(name).
+			Do(ctx)
+		return result.Error()
+	})
+	if result.Error() != nil {
+		_ = v.withRetry(ctx, ref, func() error {
+			result = info.Client.
+				Get().
+				Resource(kinds).
+				Namespace(namespace).
+				Name(name).
+				Do(ctx)
+			return result.Error()
+		})
+	}
+	return fetchedResource{err: result.Error()}
+}
+
+// checkResourcesConcurrently reads the installed resources of the visitor from the cluster in a pool of up to
+// concurrency reads at once, fed as the visitor visits them, and checks them in the order of the visitor as their
+// reads complete, so that the results and the output are the same as those of a sequential verification
+// continuing on errors. The resources visited before the visitor failed, if it continues on errors, are still
+// checked, and the errors of the visitor and of all the checks are returned.
+func (v *StatusVerifier) checkResourcesConcurrently(ctx context.Context, visitor resource.Visitor, filename string,
+	counts *installedCounts,
+) error {
+	type pendingResource struct {
+		info    *resource.Info
+		fetched *fetchedResource
+		// read is closed once the resource was read, or is not to be read.
+		read chan struct{}
+	}
+	// The resources are checked by a single goroutine, which holds up the visit once concurrency resources wait.
+	pending := make(chan *pendingResource, v.concurrency)
+	var checkErrs []error
+	checked := make(chan struct{})
+	go func() {
+		defer close(checked)
+		for p := range pending {
+			<-p.read
+			if err := v.checkResource(ctx, p.info, p.fetched, filename, counts); err != nil {
+				checkErrs = append(checkErrs, err)
+			}
+		}
+	}()
+
+	g := errgroup.Group{}
+	g.SetLimit(v.concurrency)
+	visitErr := visitor.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		p := &pendingResource{info: info, read: make(chan struct{})}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		if err != nil {
+			// The check reports the error.
+			close(p.read)
+		} else {
+			// Go waits for a free worker, so that no more than concurrency reads are in flight.
+			g.Go(func() error {
+				defer close(p.read)
+				un := &unstructured.Unstructured{Object: content}
+				ctx, cancel := v.resourceContext(ctx, un.GetKind())
+				defer cancel()
+				f := v.fetchResource(ctx, info, un)
+				p.fetched = &f
+				return nil
+			})
+		}
+		pending <- p
+		return nil
+	})
+	close(pending)
+	_ = g.Wait()
+	<-checked
+
+	multiErr := &multierror.Error{}
+	if visitErr != nil {
+		multiErr = multierror.Append(multiErr, visitErr)
+	}
+	multiErr = multierror.Append(multiErr, checkErrs...)
+	return multiErr.ErrorOrNil()
+}
@@ -0,0 +1,147 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// fakeCluster serves the installed resources by path, counting the reads in flight.
+type fakeCluster struct {
+	objects  map[string]runtime.Object
+	inFlight atomic.Int32
+	maxReads atomic.Int32
+}
+
+func (c *fakeCluster) info(obj runtime.Object) *resource.Info {
+	client := &restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		GroupVersion:         obj.GetObjectKind().GroupVersionKind().GroupVersion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			n := c.inFlight.Add(1)
+			defer c.inFlight.Add(-1)
+			for {
+				max := c.maxReads.Load()
+				if n <= max || c.maxReads.CompareAndSwap(max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			served, f := c.objects[req.URL.Path]
+			if !f {
+				return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			body, err := json.Marshal(served)
+			if err != nil {
+				return nil, err
+			}
+			header := http.Header{"Content-Type": []string{"application/json"}}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(bytes.NewReader(body))}, nil
+		}),
+	}
+	return &resource.Info{Client: client, Object: obj}
+}
+
+func TestCheckResourcesConcurrently(t *testing.T) {
+	deployment := func(name string, available int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.Of(int32(1))},
+			Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: available},
+		}
+	}
+	configMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+		}
+	}
+	cluster := &fakeCluster{objects: map[string]runtime.Object{
+		"/namespaces/istio-system/deployments/istiod":               deployment("istiod", 1),
+		"/namespaces/istio-system/deployments/istio-ingressgateway": deployment("istio-ingressgateway", 0),
+		"/namespaces/istio-system/deployments/istio-eastwest":       deployment("istio-eastwest", 1),
+		"/namespaces/istio-system/configmaps/istio":                 configMap("istio"),
+	}}
+	rendered := []runtime.Object{
+		deployment("istiod", 1),
+		configMap("istio"),
+		deployment("istio-ingressgateway", 1),
+		configMap("istio-sidecar-injector"),
+		deployment("istio-eastwest", 1),
+	}
+
+	verify := func(concurrency int) ([]CheckResult, []int, error) {
+		var infos resource.InfoListVisitor
+		for _, obj := range rendered {
+			infos = append(infos, cluster.info(obj))
+		}
+		var out bytes.Buffer
+		v := &StatusVerifier{
+			istioNamespace: "istio-system",
+			logger:         clog.NewConsoleLogger(&out, &out, nil),
+		}
+		WithConcurrency(concurrency)(v)
+		// The verification of the manifests rendered from IstioOperators continues on errors.
+		visitor := resource.ContinueOnErrorVisitor{Visitor: infos}
+		crds, deployments, daemonSets, err := v.verifyPostInstall(context.Background(), visitor, "istio.yaml")
+		return v.Results(), []int{crds, deployments, daemonSets}, err
+	}
+
+	sequential, sequentialCounts, sequentialErr := verify(1)
+	assert.Equal(t, cluster.maxReads.Load(), int32(1))
+	concurrent, concurrentCounts, concurrentErr := verify(4)
+	if n := cluster.maxReads.Load(); n < 2 || n > 4 {
+		t.Errorf("got up to %d reads at once, want between 2 and 4", n)
+	}
+
+	assert.Equal(t, concurrent, sequential)
+	assert.Equal(t, concurrentCounts, sequentialCounts)
+	assert.Equal(t, concurrentCounts, []int{0, 2, 0})
+	if sequentialErr == nil || concurrentErr == nil {
+		t.Fatalf("expected the unavailable deployment and the missing configmap to fail the verification")
+	}
+	var got []string
+	for _, r := range concurrent {
+		got = append(got, r.Kind+"/"+r.Name+": "+r.Status)
+	}
+	assert.Equal(t, got, []string{
+		"Deployment/istiod: passed",
+		"ConfigMap/istio: passed",
+		"Deployment/istio-ingressgateway: failed",
+		"ConfigMap/istio-sidecar-injector: failed",
+		"Deployment/istio-eastwest: passed",
+	})
+}
@@ -0,0 +1,186 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/api/label"
+	"istio.io/istio/istioctl/pkg/revisions"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// ambientRevision is the revision under which namespaces and pods in ambient mode are counted, as they do not
+// run a proxy of their own.
+const ambientRevision = "ambient"
+
+// coverageExcludedNamespaces are the namespaces of the cluster itself, which are not expected in the mesh.
+var coverageExcludedNamespaces = sets.New("kube-system", "kube-public", "kube-node-lease")
+
+// InjectionCoverage is how much of the data plane is in the mesh, that is has adopted the control plane.
+type InjectionCoverage struct {
+	// Namespaces and Pods are the number of application namespaces and running pods, leaving out those of
+	// Kubernetes and Istio.
+	Namespaces int `json:"namespaces"`
+	Pods       int `json:"pods"`
+	// MeshNamespaces and MeshPods are those in the mesh, with sidecars or in ambient mode.
+	MeshNamespaces int `json:"meshNamespaces"`
+	MeshPods       int `json:"meshPods"`
+	// Revisions are the namespaces and pods in the mesh by revision, ambient mode counting as the "ambient"
+	// revision.
+	Revisions []RevisionCoverage `json:"revisions,omitempty"`
+}
+
+// RevisionCoverage is the number of namespaces enabled for, and pods running, a revision.
+type RevisionCoverage struct {
+	Revision   string `json:"revision"`
+	Namespaces int    `json:"namespaces"`
+	Pods       int    `json:"pods"`
+}
+
+// injectionCoverage counts the namespaces and pods in the mesh. Host network pods and pods which are done are
+// left out, as they cannot be injected.
+func injectionCoverage(namespaces []corev1.Namespace, pods []corev1.Pod, istioNamespace string) *InjectionCoverage {
+	c := &InjectionCoverage{}
+	byRevision := map[string]*RevisionCoverage{}
+	revision := func(name string) *RevisionCoverage {
+		if byRevision[name] == nil {
+			byRevision[name] = &RevisionCoverage{Revision: name}
+		}
+		return byRevision[name]
+	}
+	counted := sets.New[string]()
+	for _, ns := range namespaces {
+		if coverageExcludedNamespaces.Contains(ns.Name) || ns.Name == istioNamespace {
+			continue
+		}
+		counted.Insert(ns.Name)
+		c.Namespaces++
+		if rev, ok := namespaceRevision(ns); ok {
+			c.MeshNamespaces++
+			revision(rev).Namespaces++
+		}
+	}
+	for _, pod := range pods {
+		if !counted.Contains(pod.Namespace) || pod.Spec.HostNetwork ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		c.Pods++
+		if rev, ok := podRevision(pod); ok {
+			c.MeshPods++
+			revision(rev).Pods++
+		}
+	}
+	for _, r := range byRevision {
+		c.Revisions = append(c.Revisions, *r)
+	}
+	sort.Slice(c.Revisions, func(i, j int) bool {
+		return c.Revisions[i].Revision < c.Revisions[j].Revision
+	})
+	return c
+}
+
+// namespaceRevision returns the revision injecting the pods of a namespace, if it is in the mesh.
+func namespaceRevision(ns corev1.Namespace) (string, bool) {
+	if ns.Labels[constants.DataplaneMode] == constants.DataplaneModeAmbient {
+		return ambientRevision, true
+	}
+	if rev, f := ns.Labels[label.IoIstioRev.Name]; f {
+		return revisions.Normalize(rev), true
+	}
+	if ns.Labels["istio-injection"] == "enabled" {
+		return revisions.Normalize(""), true
+	}
+	return "", false
+}
+
+// podRevision returns the revision whose proxy a pod runs, if it is in the mesh.
+func podRevision(pod corev1.Pod) (string, bool) {
+	for _, containers := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, c := range containers {
+			if c.Name == "istio-proxy" {
+				return revisions.Normalize(pod.Labels[label.IoIstioRev.Name]), true
+			}
+		}
+	}
+	if pod.Annotations[constants.AmbientRedirection] == constants.AmbientRedirectionEnabled {
+		return ambientRevision, true
+	}
+	return "", false
+}
+
+// percent returns n as a percentage of total, rounded down.
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return 100 * n / total
+}
+
+// reportInjectionCoverage prints how many namespaces and pods are in the mesh, by revision, so that it is
+// clear whether the data plane adopted the verified control plane. It informs rather than checks, a partly
+// adopted mesh being expected during a canary upgrade.
+func (v *StatusVerifier) reportInjectionCoverage(ctx context.Context) {
+	namespaces, err := v.scopedNamespaces(ctx)
+	if err != nil {
+		v.logger.LogAndPrintf("%s Injection coverage not computed, failed to list namespaces: %v", v.warningMark(), err)
+		return
+	}
+	pods, err := v.scopedPods(ctx)
+	if err != nil {
+		v.logger.LogAndPrintf("%s Injection coverage not computed, failed to list pods: %v", v.warningMark(), err)
+		return
+	}
+	c := injectionCoverage(namespaces, pods, v.istioNamespace)
+	v.coverage = c
+	v.logf(VerbosityNormal, "Injection coverage: %d/%d namespaces (%d%%) and %d/%d pods (%d%%) in the mesh",
+		c.MeshNamespaces, c.Namespaces, percent(c.MeshNamespaces, c.Namespaces), c.MeshPods, c.Pods, percent(c.MeshPods, c.Pods))
+	if len(c.Revisions) == 0 {
+		return
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 8, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "REVISION\tNAMESPACES\tPODS\tPODS %")
+	for _, r := range c.Revisions {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d%%\n", r.Revision, r.Namespaces, r.Pods, percent(r.Pods, c.Pods))
+	}
+	_ = w.Flush()
+	v.logf(VerbosityNormal, "%s", strings.TrimSuffix(b.String(), "\n"))
+
+	verified := revisions.Normalize(v.controlPlaneOpts.Revision)
+	onVerified := byRevisionPods(c, verified)
+	if sidecars := c.MeshPods - byRevisionPods(c, ambientRevision); sidecars > 0 && onVerified < sidecars {
+		v.logger.LogAndPrintf("%s %d/%d pods with sidecars (%d%%) run the proxy of the verified revision %q", v.warningMark(),
+			onVerified, sidecars, percent(onVerified, sidecars), verified)
+	}
+}
+
+// byRevisionPods returns the number of pods of a revision.
+func byRevisionPods(c *InjectionCoverage, revision string) int {
+	for _, r := range c.Revisions {
+		if r.Revision == revision {
+			return r.Pods
+		}
+	}
+	return 0
+}
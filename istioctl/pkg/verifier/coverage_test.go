@@ -0,0 +1,100 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func coverageNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func coveragePod(name, namespace, revision string, sidecar bool) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if sidecar {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "istio-proxy"})
+	}
+	if revision != "" {
+		pod.Labels["istio.io/rev"] = revision
+	}
+	return pod
+}
+
+func TestReportInjectionCoverage(t *testing.T) {
+	ambientPod := coveragePod("ambient", "shop", "", false)
+	ambientPod.Annotations = map[string]string{"ambient.istio.io/redirection": "enabled"}
+	donePod := coveragePod("job", "legacy", "", false)
+	donePod.Status.Phase = corev1.PodSucceeded
+	client := kube.NewFakeClient(
+		coverageNamespace("istio-system", nil),
+		coverageNamespace("kube-system", nil),
+		coverageNamespace("default", map[string]string{"istio-injection": "enabled"}),
+		coverageNamespace("payments", map[string]string{"istio.io/rev": "canary"}),
+		coverageNamespace("shop", map[string]string{"istio.io/dataplane-mode": "ambient"}),
+		coverageNamespace("legacy", nil),
+		coveragePod("istiod", "istio-system", "", false),
+		coveragePod("coredns", "kube-system", "", false),
+		coveragePod("web", "default", "", true),
+		coveragePod("api", "default", "default", true),
+		coveragePod("pay", "payments", "canary", true),
+		ambientPod,
+		coveragePod("old", "legacy", "", false),
+		donePod,
+	)
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace:   "istio-system",
+		client:           client,
+		logger:           clog.NewConsoleLogger(&out, &out, nil),
+		controlPlaneOpts: clioptions.ControlPlaneOptions{Revision: "canary"},
+	}
+	v.reportInjectionCoverage(context.Background())
+	assert.Equal(t, v.coverage, &InjectionCoverage{
+		Namespaces:     4,
+		Pods:           5,
+		MeshNamespaces: 3,
+		MeshPods:       4,
+		Revisions: []RevisionCoverage{
+			{Revision: "ambient", Namespaces: 1, Pods: 1},
+			{Revision: "canary", Namespaces: 1, Pods: 1},
+			{Revision: "default", Namespaces: 1, Pods: 2},
+		},
+	})
+	for _, want := range []string{
+		"Injection coverage: 3/4 namespaces (75%) and 4/5 pods (80%) in the mesh",
+		"default  1          2    40%",
+		"! 1/3 pods with sidecars (33%) run the proxy of the verified revision \"canary\"",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+}
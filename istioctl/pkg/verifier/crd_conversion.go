@@ -0,0 +1,115 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// defaultConversionServicePort is the port of a conversion webhook service which does not set one.
+const defaultConversionServicePort = 443
+
+// verifyCRDConversion checks that the Istio CRDs serving several versions can be read in each of them. The
+// service of a conversion webhook must have ready endpoints, and listing the resources in every served version
+// other than the storage version, which converts the stored resources, must succeed. Otherwise clients of the
+// other versions, typically those of an older release after an upgrade, fail to list the resources.
+func (v *StatusVerifier) verifyCRDConversion(ctx context.Context) error {
+	crds, err := v.client.Ext().ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list custom resource definitions: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	checked := 0
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if !isIstioGroup(crd.Spec.Group) {
+			continue
+		}
+		versions := conversionVersions(crd)
+		if len(versions) == 0 && conversionService(crd) == nil {
+			continue
+		}
+		checked++
+		if err := v.checkCRDConversion(ctx, crd, versions); err != nil {
+			v.reportFailure("CustomResourceDefinition", crd.Name, "", withCode(CodeCRDConversion, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("custom resource definition %s: %v", crd.Name, err))
+			continue
+		}
+		v.reportSuccess("CustomResourceDefinition", crd.Name, "")
+	}
+	if checked == 0 {
+		v.logf(VerbosityNormal, "No Istio custom resource definition serves several versions, skipping conversion checks")
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// checkCRDConversion checks the conversion webhook of the CRD, if any, then lists its resources in each of
+// the versions.
+func (v *StatusVerifier) checkCRDConversion(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition, versions []string) error {
+	if svc := conversionService(crd); svc != nil {
+		port := int32(defaultConversionServicePort)
+		if svc.Port != nil {
+			port = *svc.Port
+		}
+		service, err := v.client.Kube().CoreV1().Services(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("conversion webhook service %s/%s: %v", svc.Namespace, svc.Name, err)
+		}
+		if err := v.checkIntegrationService(ctx, service, uint32(port)); err != nil {
+			return fmt.Errorf("conversion webhook: %v", err)
+		}
+	}
+	for _, version := range versions {
+		gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: version, Resource: crd.Spec.Names.Plural}
+		if _, err := v.client.Dynamic().Resource(gvr).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+			return fmt.Errorf("failed to list %s in version %s: %v", crd.Spec.Names.Plural, version, err)
+		}
+	}
+	return nil
+}
+
+// isIstioGroup returns whether the API group belongs to Istio.
+func isIstioGroup(group string) bool {
+	return group == "istio.io" || strings.HasSuffix(group, ".istio.io")
+}
+
+// conversionService returns the service of the conversion webhook of the CRD, nil if it has none or calls
+// the webhook by URL.
+func conversionService(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.ServiceReference {
+	c := crd.Spec.Conversion
+	if c == nil || c.Strategy != apiextensionsv1.WebhookConverter || c.Webhook == nil || c.Webhook.ClientConfig == nil {
+		return nil
+	}
+	return c.Webhook.ClientConfig.Service
+}
+
+// conversionVersions returns the served versions of the CRD other than its storage version, which reading
+// resources in converts them to.
+func conversionVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var versions []string
+	for _, ver := range crd.Spec.Versions {
+		if ver.Served && !ver.Storage {
+			versions = append(versions, ver.Name)
+		}
+	}
+	return versions
+}
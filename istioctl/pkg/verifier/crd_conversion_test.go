@@ -0,0 +1,104 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func conversionCRD(group, plural string, service *apiextensionsv1.ServiceReference, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: plural + "." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group:    group,
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: plural},
+			Versions: versions,
+		},
+	}
+	if service != nil {
+		crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{Service: service},
+			},
+		}
+	}
+	return crd
+}
+
+var (
+	servedVersion  = apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha3", Served: true}
+	storageVersion = apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1beta1", Served: true, Storage: true}
+)
+
+func TestConversionVersions(t *testing.T) {
+	removed := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1"}
+	crd := conversionCRD("networking.istio.io", "virtualservices", nil, removed, servedVersion, storageVersion)
+	assert.Equal(t, conversionVersions(crd), []string{"v1alpha3"})
+	assert.Equal(t, len(conversionVersions(conversionCRD("networking.istio.io", "sidecars", nil, storageVersion))), 0)
+}
+
+func TestVerifyCRDConversion(t *testing.T) {
+	client := kube.NewFakeClient(
+		integrationService("istio-conversion", "istio-system", 443),
+		integrationEndpointSlice("istio-conversion", "istio-system", false),
+	)
+	for _, crd := range []*apiextensionsv1.CustomResourceDefinition{
+		conversionCRD("networking.istio.io", "virtualservices", nil, servedVersion, storageVersion),
+		conversionCRD("networking.istio.io", "gateways",
+			&apiextensionsv1.ServiceReference{Name: "istio-conversion", Namespace: "istio-system"}, servedVersion, storageVersion),
+		conversionCRD("security.istio.io", "peerauthentications", nil, storageVersion),
+		// The conversion of CRDs of other projects is not checked.
+		conversionCRD("example.com", "widgets",
+			&apiextensionsv1.ServiceReference{Name: "missing", Namespace: "default"}, servedVersion, storageVersion),
+	} {
+		if _, err := client.Ext().ApiextensionsV1().CustomResourceDefinitions().Create(context.TODO(), crd, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		client:        client,
+		logger:        clog.NewConsoleLogger(&out, &out, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+	}
+	err := v.verifyCRDConversion(context.Background())
+	assert.Error(t, err)
+	for _, want := range []string{
+		"✔ CustomResourceDefinition: virtualservices.networking.istio.io. checked successfully",
+		"✘ CustomResourceDefinition: gateways.networking.istio.io.: conversion webhook: " +
+			"service istio-system/istio-conversion has no ready endpoints",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+	for _, unwanted := range []string{"peerauthentications", "widgets"} {
+		if strings.Contains(out.String(), unwanted) {
+			t.Errorf("unexpected check of %q in output:\n%s", unwanted, out.String())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verifier verifies that an Istio installation is complete and healthy. It implements istioctl
+// verify-install, and is a supported Go API for programs managing the lifecycle of clusters, such as Cluster
+// API addons or Terraform providers, to verify the installations they make.
+//
+// A verifier is configured with options only, and defaults to the IstioOperators of the istio-system
+// namespace of the cluster of the current kubeconfig context:
+//
+//	v, err := verifier.NewVerifier(
+//		verifier.WithKubeConfig("/path/to/kubeconfig", ""),
+//		verifier.WithRevision("canary"),
+//		verifier.WithChecks("gateway-config-sync"),
+//		verifier.WithOutput(io.Discard),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	result, err := v.Verify()
+//
+// Controllers running in the cluster, and tests with fake clients, pass their own client with WithClient
+// instead of a kubeconfig.
+//
+// The result lists every check, whether the verification passed or not, so that callers can report the
+// failures their own way rather than parsing the output. To show the checks as they are made, pass a Renderer
+// with WithRenderer:
+//
+//	verifier.WithRenderer(verifier.RendererFunc(func(r verifier.CheckResult) {
+//		ui.AddRow(r.Kind, r.Name, r.Status)
+//	}))
+//
+// Failed and warned checks carry a stable FailureCode, such as IST-VER-DEPLOY-UNAVAILABLE, with a remediation
+// hint and the URL of its documentation, so that support tooling can map failures to runbooks. FailureCodes
+// lists them all.
+//
+// WithHooks notifies callers, such as controllers, as each installed resource is checked and of the result.
+// WithProgress reports how many installed resources were discovered and checked, which ProgressBar draws on a
+// terminal. WithEvents records the failures as Events on the failed resources, for kubectl describe.
+// WithVerbosity sets how much of the verification is printed, from the problems and the final status only with
+// VerbosityQuiet to every call to the API server with VerbosityDebug.
+// WithTheme, WithMarkers and WithASCII set the markers of the results of the checks, so that the output matches
+// the styling of the CLI embedding the verifier.
+//
+// Programs verifying the installation repeatedly, such as in a reconcile loop, can record Prometheus metrics of
+// the checks, failures, durations and last success of the verifications with WithMetrics:
+//
+//	metrics, err := verifier.NewMetrics(prometheus.DefaultRegisterer)
+//	if err != nil {
+//		return err
+//	}
+//	v, err := verifier.NewVerifier(verifier.WithMetrics(metrics))
+//
+// Problems which the mesh works without, such as an unready addon, are warnings which do not fail the
+// verification, unless WithFailOn(SeverityWarning) is passed.
+//
+// VerifyContext bounds the verification with the deadline of a context, and stops it once the context is
+// canceled. WithTimeout bounds the whole verification, and WithPerResourceTimeout the check of every installed
+// resource. Timeouts are reported with the IST-VER-TIMEOUT code, and fail the verification with FailureTimeout.
+//
+// WithRetry retries the reads of the installed resources which fail with a transient error of the API server, such
+// as throttling, with an exponential backoff.
+//
+// WithConcurrency reads the installed resources from the cluster concurrently. They are still checked one after
+// the other in the order of the manifests, so that the results do not depend on the concurrency.
+//
+// WithClientRateLimit tunes the client-side rate limit of the requests of the verifier to the API server, or
+// disables it, for clusters whose API priority and fairness throttles the verification.
+//
+// WithNamespaces restricts the checks of the data plane, such as the injection coverage and the authorization
+// posture, to some namespaces, so that large shared clusters are verified without listing all their pods.
+package verifier
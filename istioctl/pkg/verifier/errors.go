@@ -0,0 +1,126 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"errors"
+	"net"
+
+	"github.com/hashicorp/go-multierror"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FailureClass classifies why a verification failed, so that automation can branch on it.
+type FailureClass int
+
+const (
+	// FailureUnhealthy means the resources of the installation are present but not healthy, or an
+	// additional check failed.
+	FailureUnhealthy FailureClass = iota
+	// FailureResourcesMissing means resources of the installation are missing from the cluster.
+	FailureResourcesMissing
+	// FailureNoInstallation means no Istio installation was found in the cluster.
+	FailureNoInstallation
+	// FailureAPIAccess means the API server could not be reached, or denied access to the resources to verify.
+	FailureAPIAccess
+	// FailureTimeout means the verification, or the check of a resource, did not complete within its timeout.
+	FailureTimeout
+)
+
+func (c FailureClass) String() string {
+	switch c {
+	case FailureResourcesMissing:
+		return "resources missing"
+	case FailureNoInstallation:
+		return "no installation"
+	case FailureAPIAccess:
+		return "API server access"
+	case FailureTimeout:
+		return "timeout"
+	default:
+		return "unhealthy"
+	}
+}
+
+// VerificationError is the error returned by Verify when the installation fails verification.
+type VerificationError struct {
+	Class FailureClass
+	msg   string
+	err   error
+}
+
+func (e *VerificationError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the errors the verification failed with.
+func (e *VerificationError) Unwrap() error {
+	return e.err
+}
+
+// failureSeverity ranks the classes of failures, the higher the more severe, to pick the class of an aggregate.
+var failureSeverity = map[FailureClass]int{
+	FailureUnhealthy:        0,
+	FailureResourcesMissing: 1,
+	FailureNoInstallation:   2,
+	FailureTimeout:          3,
+	FailureAPIAccess:        4,
+}
+
+// classifyFailure returns the class of the error a verification failed with. As an aggregate mixes errors
+// of several classes, the class of an aggregate is its most severe one, as ranked by failureSeverity: API
+// server access errors first, as they leave the rest of the verification in doubt, then timeouts, which leave
+// the resources they interrupted unchecked, then a missing installation, then missing resources, and unhealthy
+// resources last.
+func classifyFailure(err error) FailureClass {
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		class := FailureUnhealthy
+		for _, e := range merr.Errors {
+			if c := classifyFailure(e); failureSeverity[c] > failureSeverity[class] {
+				class = c
+			}
+		}
+		return class
+	}
+	var verr *VerificationError
+	if errors.As(err, &verr) {
+		return verr.Class
+	}
+	if isTimeout(err) {
+		return FailureTimeout
+	}
+	if isAPIAccessError(err) {
+		return FailureAPIAccess
+	}
+	if kerrors.IsNotFound(err) {
+		return FailureResourcesMissing
+	}
+	return FailureUnhealthy
+}
+
+// isAPIAccessError reports whether err is a failure to reach the API server or to be allowed to read from it. The
+// expiry of the timeouts of the verification is not, even though the API calls fail with it.
+func isAPIAccessError(err error) bool {
+	if isTimeout(err) {
+		return false
+	}
+	if kerrors.IsUnauthorized(err) || kerrors.IsForbidden(err) || kerrors.IsServerTimeout(err) ||
+		kerrors.IsTimeout(err) || kerrors.IsTooManyRequests(err) || kerrors.IsServiceUnavailable(err) {
+		return true
+	}
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
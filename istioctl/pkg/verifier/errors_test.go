@@ -0,0 +1,139 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	notFound := kerrors.NewNotFound(deployments, "istiod")
+	forbidden := kerrors.NewForbidden(deployments, "istiod", errors.New("denied"))
+	unhealthy := istioVerificationFailureError("istio.yaml", errors.New("deployment istiod is not ready"))
+	cases := []struct {
+		name string
+		err  error
+		want FailureClass
+	}{
+		{name: "unhealthy", err: unhealthy, want: FailureUnhealthy},
+		{name: "missing", err: notFound, want: FailureResourcesMissing},
+		{
+			name: "wrapped missing",
+			err:  istioVerificationFailureError("istio.yaml", fmt.Errorf("the required Service:istiod is not ready due to: %w", notFound)),
+			want: FailureResourcesMissing,
+		},
+		{name: "forbidden", err: forbidden, want: FailureAPIAccess},
+		{name: "unreachable", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: FailureAPIAccess},
+		{name: "aggregate", err: multierror.Append(unhealthy, notFound), want: FailureResourcesMissing},
+		{name: "aggregate with access error", err: multierror.Append(notFound, forbidden, unhealthy), want: FailureAPIAccess},
+		{
+			name: "timeout",
+			err:  &url.Error{Op: "Get", URL: "https://cluster/apis/apps/v1", Err: context.DeadlineExceeded},
+			want: FailureTimeout,
+		},
+		{name: "aggregate with timeout", err: multierror.Append(notFound, timeoutError(context.DeadlineExceeded)), want: FailureTimeout},
+		{name: "aggregate with timeout and access error", err: multierror.Append(timeoutError(context.DeadlineExceeded), forbidden), want: FailureAPIAccess},
+		{
+			name: "aggregate with no installation",
+			err:  multierror.Append(notFound, &VerificationError{Class: FailureNoInstallation, msg: "no Istio installation found"}, unhealthy),
+			want: FailureNoInstallation,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, classifyFailure(tc.err), tc.want)
+		})
+	}
+}
+
+func TestClassifyFailureMixedAggregate(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	byClass := map[FailureClass]error{
+		FailureUnhealthy:        istioVerificationFailureError("istio.yaml", errors.New("deployment istiod is not ready")),
+		FailureResourcesMissing: kerrors.NewNotFound(deployments, "istiod"),
+		FailureNoInstallation:   &VerificationError{Class: FailureNoInstallation, msg: "no Istio installation found"},
+		FailureTimeout:          timeoutError(context.DeadlineExceeded),
+		FailureAPIAccess:        kerrors.NewForbidden(deployments, "istiod", errors.New("denied")),
+	}
+	// From the most severe class to the least, each class wins over the less severe ones in any order.
+	order := []FailureClass{FailureAPIAccess, FailureTimeout, FailureNoInstallation, FailureResourcesMissing, FailureUnhealthy}
+	for i, want := range order {
+		var forward, backward *multierror.Error
+		for _, c := range order[i:] {
+			forward = multierror.Append(forward, byClass[c])
+		}
+		for j := len(order) - 1; j >= i; j-- {
+			backward = multierror.Append(backward, byClass[order[j]])
+		}
+		t.Run(want.String(), func(t *testing.T) {
+			assert.Equal(t, classifyFailure(forward), want)
+			assert.Equal(t, classifyFailure(backward), want)
+			// Nested aggregates are ranked the same way.
+			assert.Equal(t, classifyFailure(multierror.Append(byClass[FailureUnhealthy], forward)), want)
+		})
+	}
+}
+
+func TestReportStatusFailureClass(t *testing.T) {
+	notFound := kerrors.NewNotFound(schema.GroupResource{Resource: "services"}, "istiod")
+	cases := []struct {
+		name        string
+		deployments int
+		err         error
+		want        FailureClass
+		msg         string
+	}{
+		{name: "no installation", want: FailureNoInstallation, msg: "no Istio installation found"},
+		{
+			name: "no installation reachable",
+			err:  kerrors.NewUnauthorized("invalid token"),
+			want: FailureAPIAccess,
+			msg:  "no Istio installation found",
+		},
+		{name: "missing", deployments: 1, err: notFound, want: FailureResourcesMissing, msg: "Istio installation failed"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         kube.NewFakeClient(),
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+			}
+			err := v.reportStatus(context.Background(), 0, tc.deployments, 0, tc.err)
+			var verr *VerificationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a VerificationError, got %v", err)
+			}
+			assert.Equal(t, verr.Class, tc.want)
+			assert.Equal(t, verr.Error(), tc.msg)
+		})
+	}
+}
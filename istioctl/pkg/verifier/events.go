@@ -0,0 +1,137 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// eventReason is the reason of the Events recorded for failed checks.
+	eventReason = "VerificationFailed"
+	// eventComponent is the source of the Events recorded for failed checks.
+	eventComponent = "istioctl-verify-install"
+	// maxEventFailures bounds the number of failures listed in the Event of an IstioOperator.
+	maxEventFailures = 10
+)
+
+// WithEvents records a Warning Event on each installed Deployment, DaemonSet and Job which failed its check, and on
+// the IstioOperators of the cluster the installation was verified against, so that the failures show in kubectl
+// describe without verifying again.
+func WithEvents() StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.events = true
+	}
+}
+
+// recordEvents records the Events of the failed checks of the last verification. Events which cannot be recorded,
+// for instance because the failed resource does not exist, are logged without failing the verification.
+func (v *StatusVerifier) recordEvents(ctx context.Context) {
+	var failures []checkResult
+	for _, r := range v.results {
+		if r.status != checkFailed {
+			continue
+		}
+		failures = append(failures, r)
+		uid, apiVersion, err := v.failedObject(ctx, r.kind, r.name, r.namespace)
+		if err != nil {
+			v.logger.LogAndErrorf("failed to record the event of %s %s/%s: %v", r.kind, r.namespace, r.name, err)
+			continue
+		}
+		if uid == "" {
+			continue
+		}
+		v.recordEvent(ctx, corev1.ObjectReference{
+			Kind: r.kind, APIVersion: apiVersion, Name: r.name, Namespace: r.namespace, UID: uid,
+		}, fmt.Sprintf("Istio installation verification failed: %s", r.reason))
+	}
+	if len(failures) == 0 {
+		return
+	}
+	for _, iop := range v.operators {
+		v.recordEvent(ctx, corev1.ObjectReference{
+			Kind: "IstioOperator", APIVersion: "install.istio.io/v1alpha1", Name: iop.Name, Namespace: iop.Namespace, UID: iop.UID,
+		}, operatorEventMessage(failures))
+	}
+}
+
+// failedObject returns the UID and API version of a failed resource, to attach its Event to it, or an empty UID
+// if no Event is recorded for its kind.
+func (v *StatusVerifier) failedObject(ctx context.Context, kind, name, namespace string) (types.UID, string, error) {
+	var meta metav1.Object
+	var err error
+	switch kind {
+	case "Deployment":
+		meta, err = v.client.Kube().AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		return uidOf(meta, err), "apps/v1", err
+	case "DaemonSet":
+		meta, err = v.client.Kube().AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		return uidOf(meta, err), "apps/v1", err
+	case "Job":
+		meta, err = v.client.Kube().BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		return uidOf(meta, err), "batch/v1", err
+	}
+	return "", "", nil
+}
+
+func uidOf(meta metav1.Object, err error) types.UID {
+	if err != nil {
+		return ""
+	}
+	return meta.GetUID()
+}
+
+// operatorEventMessage summarizes the failed checks for the Event of an IstioOperator.
+func operatorEventMessage(failures []checkResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Istio installation verification failed, %d checks failed:", len(failures))
+	for i, r := range failures {
+		if i == maxEventFailures {
+			fmt.Fprintf(&b, " and %d more", len(failures)-maxEventFailures)
+			break
+		}
+		fmt.Fprintf(&b, " %s %s/%s: %s;", r.kind, r.namespace, r.name, r.reason)
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// recordEvent records a Warning Event on the object.
+func (v *StatusVerifier) recordEvent(ctx context.Context, obj corev1.ObjectReference, message string) {
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: obj.Name + ".",
+			Namespace:    obj.Namespace,
+		},
+		InvolvedObject:      obj,
+		Reason:              eventReason,
+		Message:             message,
+		Type:                corev1.EventTypeWarning,
+		Source:              corev1.EventSource{Component: eventComponent},
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+		ReportingController: "istio.io/istioctl",
+	}
+	if _, err := v.client.Kube().CoreV1().Events(obj.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		v.logger.LogAndErrorf("failed to record the event of %s %s/%s: %v", obj.Kind, obj.Namespace, obj.Name, err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/operator/pkg/apis/istio/v1alpha1"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/util/sets"
+)
+
+func TestRecordEvents(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client: kube.NewFakeClient(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system", UID: "istiod-uid"}},
+			&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "istio-cni-node", Namespace: "kube-system", UID: "cni-uid"}},
+		),
+		logger:        clog.NewConsoleLogger(&out, &out, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+		operators: []*v1alpha1.IstioOperator{
+			{ObjectMeta: metav1.ObjectMeta{Name: "installed-state", Namespace: "istio-system", UID: "iop-uid"}},
+		},
+	}
+	WithEvents()(v)
+	v.reportSuccess("Deployment", "istio-ingressgateway", "istio-system")
+	v.reportFailure("Deployment", "istiod", "istio-system", errors.New("0/1 replicas ready"))
+	v.reportFailure("DaemonSet", "istio-cni-node", "kube-system", errors.New("0/3 pods ready"))
+	// Resources which are missing from the cluster have nothing to attach an Event to.
+	v.reportFailure("Job", "istio-init", "istio-system", errors.New("not found"))
+	v.reportFailure("Service", "istiod", "istio-system", errors.New("no endpoints"))
+	v.recordEvents(context.Background())
+
+	events, err := v.client.Kube().CoreV1().Events("istio-system").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	uids := sets.New[string]()
+	for _, e := range events.Items {
+		assert.Equal(t, e.Reason, eventReason)
+		assert.Equal(t, e.Type, "Warning")
+		uids.Insert(string(e.InvolvedObject.UID))
+		switch e.InvolvedObject.Kind {
+		case "Deployment":
+			assert.Equal(t, e.Message, "Istio installation verification failed: 0/1 replicas ready")
+		case "IstioOperator":
+			if !strings.Contains(e.Message, "4 checks failed") || !strings.Contains(e.Message, "Deployment istio-system/istiod: 0/1 replicas ready") {
+				t.Errorf("unexpected message of the IstioOperator event: %s", e.Message)
+			}
+		}
+	}
+	assert.Equal(t, sets.SortedList(uids), []string{"iop-uid", "istiod-uid"})
+
+	events, err = v.client.Kube().CoreV1().Events("kube-system").List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(events.Items), 1)
+	assert.Equal(t, string(events.Items[0].InvolvedObject.UID), "cni-uid")
+	if !strings.Contains(out.String(), "failed to record the event of Job istio-system/istio-init") {
+		t.Errorf("expected the missing Job to be logged, got %s", out.String())
+	}
+}
+
+func TestOperatorEventMessage(t *testing.T) {
+	var failures []checkResult
+	for i := 0; i < maxEventFailures+2; i++ {
+		failures = append(failures, checkResult{kind: "Deployment", name: "gw", namespace: "istio-system", status: checkFailed, reason: "not ready"})
+	}
+	msg := operatorEventMessage(failures)
+	assert.Equal(t, strings.Count(msg, "Deployment istio-system/gw"), maxEventFailures)
+	if !strings.HasSuffix(msg, "and 2 more") {
+		t.Errorf("expected the truncated failures to be counted, got %s", msg)
+	}
+}
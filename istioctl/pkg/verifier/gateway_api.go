@@ -0,0 +1,198 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/go-multierror"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	gateway "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	kubegateway "istio.io/istio/pilot/pkg/config/kube/gateway"
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	// gatewayAPIEnv and gatewayAPIDeploymentControllerEnv are the environment variables of istiod enabling the
+	// Gateway API, and the deployment of the gateways of Gateway resources.
+	gatewayAPIEnv                     = "PILOT_ENABLE_GATEWAY_API"
+	gatewayAPIDeploymentControllerEnv = "PILOT_ENABLE_GATEWAY_API_DEPLOYMENT_CONTROLLER"
+	// defaultGatewayClass is the GatewayClass istiod registers for the gateways it deploys.
+	defaultGatewayClass = "istio"
+)
+
+// verifyGatewayAPI checks the Kubernetes Gateway API resources of the installation, when istiod deploys the
+// gateways of Gateway resources: the istio GatewayClass is registered and accepted, and every Gateway of an Istio
+// GatewayClass is programmed and has the Deployment and Service istiod generates for it.
+func (v *StatusVerifier) verifyGatewayAPI(ctx context.Context) error {
+	enabled, err := v.gatewayDeploymentControllerEnabled(ctx)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		v.logf(VerbosityNormal, "The Gateway API deployment controller of istiod is disabled, skipping the Gateway API checks")
+		return nil
+	}
+	classes, err := v.client.GatewayAPI().GatewayV1beta1().GatewayClasses().List(ctx, metav1.ListOptions{})
+	if kerrors.IsNotFound(err) {
+		v.logf(VerbosityNormal, "The Gateway API CRDs are not installed, skipping the Gateway API checks")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list gateway classes: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	istioClasses := map[string]bool{}
+	registered := false
+	for _, gc := range classes.Items {
+		if gc.Spec.ControllerName != constants.ManagedGatewayController {
+			continue
+		}
+		istioClasses[gc.Name] = true
+		registered = registered || gc.Name == defaultGatewayClass
+		if !conditionTrue(gc.Status.Conditions, string(gateway.GatewayClassConditionStatusAccepted)) {
+			err := withCode(CodeGatewayAPI, fmt.Errorf("not accepted by %s", constants.ManagedGatewayController))
+			v.reportFailure("GatewayClass", gc.Name, "", err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("gateway class %s: %v", gc.Name, err))
+			continue
+		}
+		v.reportSuccess("GatewayClass", gc.Name, "")
+	}
+	if !registered {
+		err := withCode(CodeGatewayAPI, fmt.Errorf("not registered by istiod"))
+		v.reportFailure("GatewayClass", defaultGatewayClass, "", err)
+		multiErr = multierror.Append(multiErr, fmt.Errorf("gateway class %s: %v", defaultGatewayClass, err))
+	}
+
+	gateways, err := v.scopedGateways(ctx)
+	if err != nil {
+		return multierror.Append(multiErr, fmt.Errorf("failed to list gateways: %v", err))
+	}
+	for i := range gateways {
+		gw := &gateways[i]
+		if !istioClasses[string(gw.Spec.GatewayClassName)] {
+			continue
+		}
+		if err := v.verifyGatewayAPIGateway(ctx, gw); err != nil {
+			v.reportFailure("Gateway", gw.Name, gw.Namespace, withCode(CodeGatewayAPI, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("gateway %s/%s: %v", gw.Namespace, gw.Name, err))
+			continue
+		}
+		v.reportSuccess("Gateway", gw.Name, gw.Namespace)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// verifyGatewayAPIGateway checks that a Gateway is programmed, and that the Deployment and Service istiod
+// generates for a managed Gateway exist and are available.
+func (v *StatusVerifier) verifyGatewayAPIGateway(ctx context.Context, gw *gateway.Gateway) error {
+	multiErr := &multierror.Error{}
+	if c := findCondition(gw.Status.Conditions, string(gateway.GatewayConditionProgrammed)); c == nil {
+		multiErr = multierror.Append(multiErr, fmt.Errorf("not programmed yet"))
+	} else if c.Status != metav1.ConditionTrue {
+		multiErr = multierror.Append(multiErr, fmt.Errorf("not programmed: %s: %s", c.Reason, c.Message))
+	}
+	// Gateways with an address other than an IP point to an existing gateway, istiod generates nothing for them.
+	if !kubegateway.IsManaged(&gw.Spec) {
+		return multiErr.ErrorOrNil()
+	}
+	opts := metav1.ListOptions{LabelSelector: klabels.SelectorFromSet(map[string]string{constants.GatewayNameLabel: gw.Name}).String()}
+	deployments, err := v.client.Kube().AppsV1().Deployments(gw.Namespace).List(ctx, opts)
+	if err != nil {
+		return multierror.Append(multiErr, fmt.Errorf("failed to list gateway deployments: %v", err))
+	}
+	if len(deployments.Items) == 0 {
+		multiErr = multierror.Append(multiErr, fmt.Errorf("no deployment was generated"))
+	}
+	for i := range deployments.Items {
+		if err := verifyDeploymentStatus(&deployments.Items[i]); err != nil {
+			multiErr = multierror.Append(multiErr, err)
+		}
+	}
+	services, err := v.client.Kube().CoreV1().Services(gw.Namespace).List(ctx, opts)
+	if err != nil {
+		return multierror.Append(multiErr, fmt.Errorf("failed to list gateway services: %v", err))
+	}
+	if len(services.Items) == 0 {
+		multiErr = multierror.Append(multiErr, fmt.Errorf("no service was generated"))
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// scopedGateways lists the Gateway API Gateways of the selected namespaces, or all the Gateways of the cluster.
+func (v *StatusVerifier) scopedGateways(ctx context.Context) ([]gateway.Gateway, error) {
+	gateways := v.client.GatewayAPI().GatewayV1beta1()
+	if len(v.checks.namespaces) == 0 {
+		list, err := gateways.Gateways(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+	var all []gateway.Gateway
+	for _, ns := range v.checks.namespaces {
+		list, err := gateways.Gateways(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %v", ns, err)
+		}
+		all = append(all, list.Items...)
+	}
+	return all, nil
+}
+
+// gatewayDeploymentControllerEnabled returns whether the istiod of the revision under verification deploys the
+// gateways of Gateway resources, as it does unless its environment disables it.
+func (v *StatusVerifier) gatewayDeploymentControllerEnabled(ctx context.Context) (bool, error) {
+	deployment, err := v.client.Kube().AppsV1().Deployments(v.istioNamespace).Get(ctx, v.istiodDeploymentName(), metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		// Remote clusters have no istiod, the istiod of the primary cluster deploys their gateways.
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get the istiod deployment: %v", err)
+	}
+	container := findContainer(deployment.Spec.Template.Spec.Containers, istiodContainer)
+	if container == nil {
+		return true, nil
+	}
+	for _, env := range container.Env {
+		if env.Name != gatewayAPIEnv && env.Name != gatewayAPIDeploymentControllerEnv {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(env.Value); err == nil && !enabled {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func conditionTrue(conditions []metav1.Condition, conditionType string) bool {
+	c := findCondition(conditions, conditionType)
+	return c != nil && c.Status == metav1.ConditionTrue
+}
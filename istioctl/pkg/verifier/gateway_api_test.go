@@ -0,0 +1,164 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	gateway "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func gatewayClass(name string, accepted bool) *gateway.GatewayClass {
+	status := metav1.ConditionTrue
+	if !accepted {
+		status = metav1.ConditionUnknown
+	}
+	return &gateway.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       gateway.GatewayClassSpec{ControllerName: constants.ManagedGatewayController},
+		Status: gateway.GatewayClassStatus{Conditions: []metav1.Condition{
+			{Type: string(gateway.GatewayClassConditionStatusAccepted), Status: status},
+		}},
+	}
+}
+
+func apiGateway(name string, programmed metav1.ConditionStatus) *gateway.Gateway {
+	gw := &gateway.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       gateway.GatewaySpec{GatewayClassName: defaultGatewayClass},
+	}
+	if programmed != "" {
+		gw.Status.Conditions = []metav1.Condition{{
+			Type: string(gateway.GatewayConditionProgrammed), Status: programmed, Reason: "AddressNotAssigned", Message: "pending",
+		}}
+	}
+	return gw
+}
+
+func generatedGatewayObjects(name string) []runtime.Object {
+	labels := map[string]string{constants.GatewayNameLabel: name}
+	return []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name + "-istio", Namespace: "default", Labels: labels}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name + "-istio", Namespace: "default", Labels: labels}},
+	}
+}
+
+func TestVerifyGatewayAPI(t *testing.T) {
+	disabledIstiod := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: istiodContainer,
+			Env:  []corev1.EnvVar{{Name: gatewayAPIDeploymentControllerEnv, Value: "false"}},
+		}}}}},
+	}
+	hostnameType := gateway.HostnameAddressType
+	unmanaged := apiGateway("external", metav1.ConditionTrue)
+	unmanaged.Spec.Addresses = []gateway.GatewayAddress{{Type: &hostnameType, Value: "gateway.example.com"}}
+	cases := []struct {
+		name     string
+		objects  []runtime.Object
+		classes  []*gateway.GatewayClass
+		gateways []*gateway.Gateway
+		wantErr  []string
+		want     []string
+	}{
+		{
+			name:     "programmed",
+			objects:  generatedGatewayObjects("web"),
+			classes:  []*gateway.GatewayClass{gatewayClass(defaultGatewayClass, true)},
+			gateways: []*gateway.Gateway{apiGateway("web", metav1.ConditionTrue), unmanaged},
+			want: []string{
+				"✔ GatewayClass: istio. checked successfully",
+				"✔ Gateway: web.default checked successfully",
+				"✔ Gateway: external.default checked successfully",
+			},
+		},
+		{
+			name:     "not programmed and not deployed",
+			classes:  []*gateway.GatewayClass{gatewayClass(defaultGatewayClass, true)},
+			gateways: []*gateway.Gateway{apiGateway("web", metav1.ConditionFalse), apiGateway("api", "")},
+			wantErr: []string{
+				"gateway default/web: 3 errors occurred",
+				"not programmed: AddressNotAssigned: pending",
+				"no deployment was generated",
+				"no service was generated",
+				"gateway default/api: 3 errors occurred",
+				"not programmed yet",
+			},
+		},
+		{
+			name:    "class not registered",
+			classes: []*gateway.GatewayClass{gatewayClass("istio-remote", false)},
+			wantErr: []string{
+				"gateway class istio-remote: not accepted by istio.io/gateway-controller",
+				"gateway class istio: not registered by istiod",
+			},
+		},
+		{
+			name:    "deployment controller disabled",
+			objects: []runtime.Object{disabledIstiod},
+			want:    []string{"The Gateway API deployment controller of istiod is disabled"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := kube.NewFakeClient(c.objects...)
+			for _, gc := range c.classes {
+				_, err := client.GatewayAPI().GatewayV1beta1().GatewayClasses().Create(context.Background(), gc, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			for _, gw := range c.gateways {
+				_, err := client.GatewayAPI().GatewayV1beta1().Gateways(gw.Namespace).Create(context.Background(), gw, metav1.CreateOptions{})
+				assert.NoError(t, err)
+			}
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:         client,
+				istioNamespace: "istio-system",
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+			}
+			err := v.verifyGatewayAPI(context.Background())
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+			} else if err == nil {
+				t.Fatalf("expected errors %v", c.wantErr)
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+			}
+			for _, want := range c.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	networking "istio.io/api/networking/v1beta1"
+	credkube "istio.io/istio/pilot/pkg/credentials/kube"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// verifyGatewayCredentials checks that the TLS credential referenced by every Gateway server
+// exists in the namespace of the gateway workload, holds a valid certificate/key pair covering
+// the server hosts and is not expired.
+func (v *StatusVerifier) verifyGatewayCredentials(ctx context.Context) error {
+	gateways, err := v.client.Istio().NetworkingV1beta1().Gateways(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list gateways: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	for _, gw := range gateways.Items {
+		namespaces, err := v.gatewayWorkloadNamespaces(ctx, gw.Namespace, gw.Spec.Selector)
+		if err != nil {
+			multiErr = multierror.Append(multiErr, err)
+			continue
+		}
+		for i, server := range gw.Spec.Servers {
+			credentialName := server.GetTls().GetCredentialName()
+			if credentialName == "" || !terminatesTLS(server.GetTls().GetMode()) {
+				continue
+			}
+			resource := fmt.Sprintf("%s server[%d] credential %s", gw.Name, i, credentialName)
+			for _, ns := range namespaces {
+				if err := v.verifyGatewayCredential(ctx, credentialName, ns, server.Hosts); err != nil {
+					v.reportFailure("Gateway", resource, ns, withCode(CodeGatewayCredential, err))
+					multiErr = multierror.Append(multiErr, fmt.Errorf("gateway %s/%s server[%d]: %v", gw.Namespace, gw.Name, i, err))
+					continue
+				}
+				v.reportSuccess("Gateway", resource, ns)
+			}
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// gatewayWorkloadNamespaces returns the namespaces of the pods selected by a Gateway. Credentials are
+// read from the namespace of the gateway workload, not the Gateway resource.
+func (v *StatusVerifier) gatewayWorkloadNamespaces(ctx context.Context, gatewayNamespace string, selector map[string]string) ([]string, error) {
+	if len(selector) == 0 {
+		return []string{gatewayNamespace}, nil
+	}
+	pods, err := v.client.PodsForSelector(ctx, metav1.NamespaceAll, klabels.SelectorFromSet(selector).String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gateway pods: %v", err)
+	}
+	namespaces := sets.New[string]()
+	for _, pod := range pods.Items {
+		namespaces.Insert(pod.Namespace)
+	}
+	if namespaces.IsEmpty() {
+		return []string{gatewayNamespace}, nil
+	}
+	return sets.SortedList(namespaces), nil
+}
+
+func (v *StatusVerifier) verifyGatewayCredential(ctx context.Context, name, namespace string, hosts []string) error {
+	secret, err := v.client.Kube().CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	return verifyCredentialSecret(secret, hosts, time.Now())
+}
+
+// verifyCredentialSecret checks that the secret holds a valid certificate and key whose SANs
+// cover all hosts and which is valid at the given time.
+func verifyCredentialSecret(secret *corev1.Secret, hosts []string, now time.Time) error {
+	certInfo, err := credkube.ExtractCertInfo(secret)
+	if err != nil {
+		return err
+	}
+	pair, err := tls.X509KeyPair(certInfo.Cert, certInfo.Key)
+	if err != nil {
+		return fmt.Errorf("invalid certificate/key pair: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %v", err)
+	}
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("certificate is not valid before %v", leaf.NotBefore.UTC())
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate expired at %v", leaf.NotAfter.UTC())
+	}
+	var uncovered []string
+	for _, h := range hosts {
+		// Gateway hosts may be in the form namespace/host
+		if idx := strings.Index(h, "/"); idx >= 0 {
+			h = h[idx+1:]
+		}
+		if h == "*" || sanCovers(leaf.DNSNames, h) {
+			continue
+		}
+		uncovered = append(uncovered, h)
+	}
+	if len(uncovered) > 0 {
+		return fmt.Errorf("certificate SANs %v do not match hosts %v", leaf.DNSNames, uncovered)
+	}
+	return nil
+}
+
+func sanCovers(sans []string, h string) bool {
+	for _, san := range sans {
+		if host.Name(h).SubsetOf(host.Name(san)) {
+			return true
+		}
+	}
+	return false
+}
+
+func terminatesTLS(mode networking.ServerTLSSettings_TLSmode) bool {
+	switch mode {
+	case networking.ServerTLSSettings_SIMPLE, networking.ServerTLSSettings_MUTUAL, networking.ServerTLSSettings_OPTIONAL_MUTUAL:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func TestVerifyCredentialSecret(t *testing.T) {
+	now := time.Now()
+	cert, key, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         "*.example.com,example.org",
+		NotBefore:    now.Add(-time.Hour),
+		TTL:          24 * time.Hour,
+		IsSelfSigned: true,
+		IsServer:     true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsSecret := &corev1.Secret{
+		Data: map[string][]byte{
+			"tls.crt": cert,
+			"tls.key": key,
+		},
+	}
+	cases := []struct {
+		name    string
+		secret  *corev1.Secret
+		hosts   []string
+		now     time.Time
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			secret: tlsSecret,
+			hosts:  []string{"foo.example.com", "ns/example.org", "*"},
+			now:    now,
+		},
+		{
+			name:    "host not covered",
+			secret:  tlsSecret,
+			hosts:   []string{"foo.example.net"},
+			now:     now,
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			secret:  tlsSecret,
+			hosts:   []string{"example.org"},
+			now:     now.Add(48 * time.Hour),
+			wantErr: true,
+		},
+		{
+			name:    "not yet valid",
+			secret:  tlsSecret,
+			hosts:   []string{"example.org"},
+			now:     now.Add(-48 * time.Hour),
+			wantErr: true,
+		},
+		{
+			name: "mismatched key",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"tls.crt": cert,
+					"tls.key": []byte("bogus"),
+				},
+			},
+			now:     now,
+			wantErr: true,
+		},
+		{
+			name: "missing keys",
+			secret: &corev1.Secret{
+				Data: map[string][]byte{
+					"foo": cert,
+				},
+			},
+			now:     now,
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := verifyCredentialSecret(c.secret, c.hosts, c.now)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("verifyCredentialSecret() got err %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	// gatewaysComponent is the component the results of the gateways check are summarized under.
+	gatewaysComponent = "gateways"
+	gatewayKind       = "Gateway workload"
+)
+
+// gatewayStaticListenerPorts are the ports of the listeners of the bootstrap of every proxy, which serve its
+// metrics and health rather than traffic.
+var gatewayStaticListenerPorts = map[uint32]bool{15090: true, 15021: true}
+
+// envoyListeners is the part of the response of the listeners endpoint of the Envoy admin the gateways check reads.
+type envoyListeners struct {
+	ListenerStatuses []struct {
+		Name         string `json:"name"`
+		LocalAddress struct {
+			SocketAddress struct {
+				PortValue uint32 `json:"port_value"`
+			} `json:"socket_address"`
+		} `json:"local_address"`
+	} `json:"listener_statuses"`
+}
+
+// verifyGateways checks the health of every ingress and egress gateway Deployment of the cluster: its Services
+// have their external IPs and node ports allocated, its pods are ready and programmed with listeners, and it
+// runs the minimum replicas of its HorizontalPodAutoscalers.
+func (v *StatusVerifier) verifyGateways(ctx context.Context) error {
+	defer v.attributeResults(len(v.results), gatewaysComponent)
+	deployments, err := v.client.Kube().AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	checked := 0
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if !isGatewayWorkload(d.Spec.Template.Labels) {
+			continue
+		}
+		checked++
+		unprogrammed, err := v.verifyGateway(ctx, d)
+		if err != nil {
+			v.reportFailure(gatewayKind, d.Name, d.Namespace, withCode(CodeGatewayUnhealthy, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("gateway %s/%s: %v", d.Namespace, d.Name, err))
+			continue
+		}
+		if len(unprogrammed) > 0 {
+			v.reportWarning(gatewayKind, d.Name, d.Namespace, withCode(CodeGatewayUnhealthy,
+				fmt.Errorf("pods %v have no listeners, no Gateway selects them", unprogrammed)))
+			continue
+		}
+		v.reportSuccess(gatewayKind, d.Name, d.Namespace)
+	}
+	if checked == 0 {
+		v.logf(VerbosityNormal, "No gateway deployments found")
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// verifyGateway checks the health of a gateway Deployment. It returns the ready pods which have no listener,
+// which is not a problem for a gateway no Gateway selects yet.
+func (v *StatusVerifier) verifyGateway(ctx context.Context, d *appsv1.Deployment) ([]string, error) {
+	multiErr := &multierror.Error{}
+	if err := v.verifyGatewayServices(ctx, d); err != nil {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	if err := v.verifyGatewayAutoscaling(ctx, d); err != nil {
+		multiErr = multierror.Append(multiErr, err)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return nil, multierror.Append(multiErr, err)
+	}
+	pods, err := v.client.Kube().CoreV1().Pods(d.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, multierror.Append(multiErr, fmt.Errorf("failed to list gateway pods: %v", err))
+	}
+	var notReady, unprogrammed []string
+	ready := 0
+	for _, pod := range pods.Items {
+		if !isPodReady(&pod) {
+			notReady = append(notReady, pod.Name)
+			continue
+		}
+		ready++
+		listeners, err := v.gatewayListeners(ctx, &pod)
+		if err != nil {
+			multiErr = multierror.Append(multiErr, fmt.Errorf("could not read the listeners of pod %s: %v", pod.Name, err))
+			continue
+		}
+		if listeners == 0 {
+			unprogrammed = append(unprogrammed, pod.Name)
+		}
+	}
+	sort.Strings(notReady)
+	sort.Strings(unprogrammed)
+	switch {
+	case ready == 0:
+		multiErr = multierror.Append(multiErr, fmt.Errorf("no gateway pod is ready"))
+	case len(notReady) > 0:
+		multiErr = multierror.Append(multiErr, fmt.Errorf("pods %v are not ready", notReady))
+	}
+	return unprogrammed, multiErr.ErrorOrNil()
+}
+
+// verifyGatewayServices checks that the gateway pods are selected by a Service, and that the Services have their
+// external addresses and node ports allocated.
+func (v *StatusVerifier) verifyGatewayServices(ctx context.Context, d *appsv1.Deployment) error {
+	services, err := v.client.Kube().CoreV1().Services(d.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list gateway services: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	selected := 0
+	for _, svc := range services.Items {
+		if len(svc.Spec.Selector) == 0 || !klabels.SelectorFromSet(svc.Spec.Selector).Matches(klabels.Set(d.Spec.Template.Labels)) {
+			continue
+		}
+		selected++
+		if svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(loadBalancerAddresses(&svc)) == 0 {
+			multiErr = multierror.Append(multiErr, fmt.Errorf("service %s has no external IP assigned", svc.Name))
+		}
+		if !allocatesNodePorts(&svc) {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort == 0 {
+				multiErr = multierror.Append(multiErr, fmt.Errorf("service %s has no node port allocated for port %d", svc.Name, port.Port))
+			}
+		}
+	}
+	if selected == 0 {
+		return fmt.Errorf("no service selects the gateway pods")
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// allocatesNodePorts returns whether the Service should have a node port allocated for each of its ports.
+func allocatesNodePorts(svc *corev1.Service) bool {
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeNodePort:
+		return true
+	case corev1.ServiceTypeLoadBalancer:
+		return svc.Spec.AllocateLoadBalancerNodePorts == nil || *svc.Spec.AllocateLoadBalancerNodePorts
+	}
+	return false
+}
+
+// verifyGatewayAutoscaling checks that the gateway runs at least the minimum replicas of the
+// HorizontalPodAutoscalers scaling it.
+func (v *StatusVerifier) verifyGatewayAutoscaling(ctx context.Context, d *appsv1.Deployment) error {
+	hpas, err := v.client.Kube().AutoscalingV2().HorizontalPodAutoscalers(d.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list horizontal pod autoscalers: %v", err)
+	}
+	for _, hpa := range hpas.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind != "Deployment" || ref.Name != d.Name {
+			continue
+		}
+		minReplicas := int32(1)
+		if hpa.Spec.MinReplicas != nil {
+			minReplicas = *hpa.Spec.MinReplicas
+		}
+		if d.Status.ReadyReplicas < minReplicas {
+			return fmt.Errorf("%d replicas are ready, HorizontalPodAutoscaler %s requires at least %d",
+				d.Status.ReadyReplicas, hpa.Name, minReplicas)
+		}
+	}
+	return nil
+}
+
+// gatewayListeners returns the number of listeners of the gateway pod serving traffic.
+func (v *StatusVerifier) gatewayListeners(ctx context.Context, pod *corev1.Pod) (int, error) {
+	body, err := v.client.EnvoyDo(ctx, pod.Name, pod.Namespace, http.MethodGet, "listeners?format=json")
+	if err != nil {
+		return 0, err
+	}
+	var listeners envoyListeners
+	if err := json.Unmarshal(body, &listeners); err != nil {
+		return 0, fmt.Errorf("invalid listeners: %v", err)
+	}
+	count := 0
+	for _, l := range listeners.ListenerStatuses {
+		if !gatewayStaticListenerPorts[l.LocalAddress.SocketAddress.PortValue] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// isGatewayWorkload returns whether the pods of the labels are Istio gateways, either deployed by the installer
+// or charts, which are labeled with the istio label, or by the Gateway API controller.
+func isGatewayWorkload(labels map[string]string) bool {
+	if _, f := labels[constants.GatewayNameLabel]; f {
+		return true
+	}
+	istio, f := labels["istio"]
+	// istiod is labeled istio=pilot.
+	return f && istio != "pilot"
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const (
+	gatewayListenersJSON = `{"listener_statuses": [
+  {"name": "0.0.0.0_8080", "local_address": {"socket_address": {"address": "0.0.0.0", "port_value": 8080}}},
+  {"name": "stats", "local_address": {"socket_address": {"address": "0.0.0.0", "port_value": 15090}}}
+]}`
+	staticListenersJSON = `{"listener_statuses": [
+  {"name": "stats", "local_address": {"socket_address": {"address": "0.0.0.0", "port_value": 15090}}},
+  {"name": "health", "local_address": {"socket_address": {"address": "0.0.0.0", "port_value": 15021}}}
+]}`
+)
+
+func gatewayDeployment(name string, readyReplicas int32) *appsv1.Deployment {
+	labels := map[string]string{"istio": name}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: readyReplicas},
+	}
+}
+
+func gatewayHPA(target string, minReplicas int32) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: target, Namespace: "istio-system"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: target},
+			MinReplicas:    &minReplicas,
+		},
+	}
+}
+
+func TestVerifyGateways(t *testing.T) {
+	allocated := gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyCluster, 0, "1.2.3.4")
+	for i := range allocated.Spec.Ports {
+		allocated.Spec.Ports[i].NodePort = 30000 + int32(i)
+	}
+	unallocated := gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyCluster, 0)
+	istiod := gatewayDeployment("istiod", 1)
+	istiod.Spec.Template.Labels = map[string]string{"app": "istiod", "istio": "pilot"}
+	notReady := readyPod("ingressgateway-def", "ingressgateway")
+	notReady.Status.Conditions[0].Status = corev1.ConditionFalse
+	cases := []struct {
+		name      string
+		objects   []runtime.Object
+		listeners map[string][]byte
+		wantErr   []string
+		want      string
+	}{
+		{
+			name: "healthy",
+			objects: []runtime.Object{
+				gatewayDeployment("ingressgateway", 2), allocated, gatewayHPA("ingressgateway", 2), istiod,
+				readyPod("ingressgateway-abc", "ingressgateway"),
+			},
+			listeners: map[string][]byte{"ingressgateway-abc": []byte(gatewayListenersJSON)},
+			want:      "✔ Gateway workload: ingressgateway.istio-system checked successfully",
+		},
+		{
+			name: "no listeners",
+			objects: []runtime.Object{
+				gatewayDeployment("ingressgateway", 1), allocated, readyPod("ingressgateway-abc", "ingressgateway"),
+			},
+			listeners: map[string][]byte{"ingressgateway-abc": []byte(staticListenersJSON)},
+			want:      "! Gateway workload: ingressgateway.istio-system: pods [ingressgateway-abc] have no listeners",
+		},
+		{
+			name:      "no service",
+			objects:   []runtime.Object{gatewayDeployment("ingressgateway", 1), readyPod("ingressgateway-abc", "ingressgateway")},
+			listeners: map[string][]byte{"ingressgateway-abc": []byte(gatewayListenersJSON)},
+			wantErr:   []string{"no service selects the gateway pods"},
+		},
+		{
+			name: "unallocated service and unready pods",
+			objects: []runtime.Object{
+				gatewayDeployment("ingressgateway", 1), unallocated, gatewayHPA("ingressgateway", 3),
+				readyPod("ingressgateway-abc", "ingressgateway"), notReady,
+			},
+			listeners: map[string][]byte{"ingressgateway-abc": []byte(gatewayListenersJSON)},
+			wantErr: []string{
+				"service ingressgateway has no external IP assigned",
+				"service ingressgateway has no node port allocated for port 80",
+				"1 replicas are ready, HorizontalPodAutoscaler ingressgateway requires at least 3",
+				"pods [ingressgateway-def] are not ready",
+			},
+		},
+		{
+			name:    "no ready pod",
+			objects: []runtime.Object{gatewayDeployment("ingressgateway", 0), allocated, notReady},
+			wantErr: []string{"no gateway pod is ready"},
+		},
+		{
+			name:    "unreadable listeners",
+			objects: []runtime.Object{gatewayDeployment("ingressgateway", 1), allocated, readyPod("ingressgateway-abc", "ingressgateway")},
+			wantErr: []string{"could not read the listeners of pod ingressgateway-abc"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:        cli.MockClient{CLIClient: kube.NewFakeClient(c.objects...), Results: c.listeners},
+				logger:        clog.NewConsoleLogger(&out, &out, nil),
+				successMarker: "✔",
+				failureMarker: "✘",
+				warningMarker: "!",
+			}
+			err := v.verifyGateways(context.Background())
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+			} else if err == nil {
+				t.Fatalf("expected errors %v", c.wantErr)
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+			}
+			if !strings.Contains(out.String(), c.want) {
+				t.Fatalf("output missing %q:\n%s", c.want, out.String())
+			}
+			assert.Equal(t, len(v.results), 1)
+			assert.Equal(t, componentName(v.results[0].component), "gateways")
+		})
+	}
+}
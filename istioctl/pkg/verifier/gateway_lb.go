@@ -0,0 +1,204 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+// DefaultGatewayProbeTimeout is how long the default prober waits for a gateway port to respond.
+const DefaultGatewayProbeTimeout = 5 * time.Second
+
+// GatewayProbe is a single port of a gateway LoadBalancer address to probe.
+type GatewayProbe struct {
+	// Address is the IP or hostname assigned to the LoadBalancer.
+	Address string
+	Port    int32
+	// TLS is set for ports which serve TLS, according to the port naming convention.
+	TLS bool
+}
+
+// GatewayProber checks that a gateway LoadBalancer address is reachable from where the verifier runs,
+// which is typically outside the cluster.
+type GatewayProber interface {
+	Probe(ctx context.Context, probe GatewayProbe) error
+}
+
+// WithGatewayProber makes the gateway-load-balancer check probe the address of every gateway
+// LoadBalancer with the given prober.
+func WithGatewayProber(prober GatewayProber) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.gatewayProber = prober
+	}
+}
+
+// NewDialProber returns a GatewayProber which opens a TCP connection to the probed port, and
+// completes a TLS handshake for TLS ports. The certificate presented is not verified.
+func NewDialProber(timeout time.Duration) GatewayProber {
+	return dialProber{timeout: timeout}
+}
+
+type dialProber struct {
+	timeout time.Duration
+}
+
+func (p dialProber) Probe(ctx context.Context, probe GatewayProbe) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	address := net.JoinHostPort(probe.Address, strconv.Itoa(int(probe.Port)))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if !probe.TLS {
+		return nil
+	}
+	// nolint: gosec // reachability is checked, not the certificate the gateway presents
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return fmt.Errorf("TLS handshake failed: %v", err)
+	}
+	return nil
+}
+
+// verifyGatewayLoadBalancers checks that every gateway LoadBalancer Service has an address assigned and
+// can pass load balancer health checks, and probes the address when a prober is configured.
+func (v *StatusVerifier) verifyGatewayLoadBalancers(ctx context.Context) error {
+	services, err := v.client.Kube().CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list services: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	checked := 0
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || !isGatewayService(svc) {
+			continue
+		}
+		checked++
+		if err := v.verifyGatewayLoadBalancer(ctx, svc); err != nil {
+			v.reportFailure("Gateway service", svc.Name, svc.Namespace, err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("gateway service %s/%s: %v", svc.Namespace, svc.Name, err))
+			continue
+		}
+		v.reportSuccess("Gateway service", svc.Name, svc.Namespace)
+	}
+	if checked == 0 {
+		v.logf(VerbosityNormal, "No gateway LoadBalancer services found")
+	}
+	return multiErr.ErrorOrNil()
+}
+
+func (v *StatusVerifier) verifyGatewayLoadBalancer(ctx context.Context, svc *corev1.Service) error {
+	addresses := loadBalancerAddresses(svc)
+	if len(addresses) == 0 {
+		return fmt.Errorf("no LoadBalancer address assigned")
+	}
+	if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal {
+		if svc.Spec.HealthCheckNodePort == 0 {
+			return fmt.Errorf("externalTrafficPolicy is Local but no health check node port is allocated, " +
+				"the load balancer cannot tell which nodes run the gateway")
+		}
+		ready, err := v.readyPods(ctx, svc.Namespace, svc.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		if ready == 0 {
+			return fmt.Errorf("externalTrafficPolicy is Local but no gateway pod is ready, "+
+				"health checks on node port %d fail on every node", svc.Spec.HealthCheckNodePort)
+		}
+	}
+	if v.checks.gatewayProber == nil {
+		return nil
+	}
+	multiErr := &multierror.Error{}
+	for _, address := range addresses {
+		for _, port := range svc.Spec.Ports {
+			if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+				continue
+			}
+			probe := GatewayProbe{Address: address, Port: port.Port, TLS: isTLSPort(port)}
+			if err := v.checks.gatewayProber.Probe(ctx, probe); err != nil {
+				multiErr = multierror.Append(multiErr, fmt.Errorf("%s port %d unreachable: %v", address, port.Port, err))
+			}
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// readyPods returns the number of ready pods selected by the selector in the namespace.
+func (v *StatusVerifier) readyPods(ctx context.Context, namespace string, selector map[string]string) (int, error) {
+	pods, err := v.client.Kube().CoreV1().Pods(namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: klabels.SelectorFromSet(selector).String()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list gateway pods: %v", err)
+	}
+	ready := 0
+	for _, pod := range pods.Items {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready, nil
+}
+
+// isGatewayService returns whether the Service fronts Istio gateway pods, either deployed by the
+// installer or charts, which are selected with the istio label, or by the Gateway API controller.
+func isGatewayService(svc *corev1.Service) bool {
+	if _, f := svc.Spec.Selector["istio"]; f {
+		return true
+	}
+	_, f := svc.Spec.Selector[constants.GatewayNameLabel]
+	return f
+}
+
+func loadBalancerAddresses(svc *corev1.Service) []string {
+	var addresses []string
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		switch {
+		case ingress.IP != "":
+			addresses = append(addresses, ingress.IP)
+		case ingress.Hostname != "":
+			addresses = append(addresses, ingress.Hostname)
+		}
+	}
+	return addresses
+}
+
+// isTLSPort follows the Istio port naming convention for protocol selection.
+func isTLSPort(port corev1.ServicePort) bool {
+	if port.AppProtocol != nil {
+		return *port.AppProtocol == "https" || *port.AppProtocol == "tls"
+	}
+	return strings.HasPrefix(port.Name, "https") || strings.HasPrefix(port.Name, "tls")
+}
@@ -0,0 +1,146 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+type fakeGatewayProber struct {
+	probes      []GatewayProbe
+	unreachable map[int32]bool
+}
+
+func (p *fakeGatewayProber) Probe(_ context.Context, probe GatewayProbe) error {
+	p.probes = append(p.probes, probe)
+	if p.unreachable[probe.Port] {
+		return fmt.Errorf("connection refused")
+	}
+	return nil
+}
+
+func gatewayService(name string, policy corev1.ServiceExternalTrafficPolicyType, healthCheckNodePort int32, ingress ...string) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+		Spec: corev1.ServiceSpec{
+			Type:                  corev1.ServiceTypeLoadBalancer,
+			Selector:              map[string]string{"istio": name},
+			ExternalTrafficPolicy: policy,
+			HealthCheckNodePort:   healthCheckNodePort,
+			Ports: []corev1.ServicePort{
+				{Name: "http2", Port: 80},
+				{Name: "https", Port: 443},
+				{Name: "udp-dns", Port: 53, Protocol: corev1.ProtocolUDP},
+			},
+		},
+	}
+	for _, ip := range ingress {
+		svc.Status.LoadBalancer.Ingress = append(svc.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+	}
+	return svc
+}
+
+func readyPod(name, app string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system", Labels: map[string]string{"istio": app}},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestVerifyGatewayLoadBalancers(t *testing.T) {
+	cases := []struct {
+		name        string
+		objects     []runtime.Object
+		unreachable map[int32]bool
+		wantErr     string
+		wantProbes  int
+	}{
+		{
+			name:       "reachable cluster policy gateway",
+			objects:    []runtime.Object{gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyCluster, 0, "1.2.3.4")},
+			wantProbes: 2,
+		},
+		{
+			name:    "no address assigned",
+			objects: []runtime.Object{gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyCluster, 0)},
+			wantErr: "no LoadBalancer address assigned",
+		},
+		{
+			name:    "local policy without health check node port",
+			objects: []runtime.Object{gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyLocal, 0, "1.2.3.4")},
+			wantErr: "no health check node port is allocated",
+		},
+		{
+			name:    "local policy without ready pods",
+			objects: []runtime.Object{gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyLocal, 31000, "1.2.3.4")},
+			wantErr: "no gateway pod is ready",
+		},
+		{
+			name: "local policy with ready pods",
+			objects: []runtime.Object{
+				gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyLocal, 31000, "1.2.3.4"),
+				readyPod("ingressgateway-abc", "ingressgateway"),
+			},
+			wantProbes: 2,
+		},
+		{
+			name:        "unreachable TLS port",
+			objects:     []runtime.Object{gatewayService("ingressgateway", corev1.ServiceExternalTrafficPolicyCluster, 0, "1.2.3.4")},
+			unreachable: map[int32]bool{443: true},
+			wantErr:     "1.2.3.4 port 443 unreachable: connection refused",
+			wantProbes:  2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			prober := &fakeGatewayProber{unreachable: c.unreachable}
+			v := &StatusVerifier{
+				client:        kube.NewFakeClient(c.objects...),
+				logger:        clog.NewConsoleLogger(&out, &out, nil),
+				successMarker: "✔",
+				failureMarker: "✘",
+				checks:        checkSettings{gatewayProber: prober},
+			}
+			err := v.verifyGatewayLoadBalancers(context.Background())
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+				if !strings.Contains(out.String(), "✔ Gateway service: ingressgateway.istio-system checked successfully") {
+					t.Fatalf("missing success output:\n%s", out.String())
+				}
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			assert.Equal(t, len(prober.probes), c.wantProbes)
+			for _, probe := range prober.probes {
+				assert.Equal(t, probe.TLS, probe.Port == 443)
+			}
+		})
+	}
+}
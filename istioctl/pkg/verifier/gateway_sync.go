@@ -0,0 +1,149 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/xds"
+)
+
+const (
+	// DefaultGatewaySyncTimeout is how long gateway proxies are given to ACK the latest config by default.
+	DefaultGatewaySyncTimeout = 30 * time.Second
+
+	gatewaySyncPollInterval = time.Second
+)
+
+// WithGatewaySyncTimeout sets how long the gateway-config-sync check waits for gateway proxies
+// to ACK the latest config pushed by istiod.
+func WithGatewaySyncTimeout(timeout time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.gatewaySyncTimeout = timeout
+	}
+}
+
+// gatewaySync is the xDS sync state of a gateway proxy.
+type gatewaySync struct {
+	proxyID string
+	istiod  string
+	// stale lists the xDS types for which the proxy has not ACKed the last config sent to it.
+	stale []string
+}
+
+// verifyGatewayConfigSync checks that every gateway proxy has ACKed the latest config version
+// istiod sent it, waiting up to the configured timeout for the proxies to catch up.
+func (v *StatusVerifier) verifyGatewayConfigSync(ctx context.Context) error {
+	timeout := v.checks.gatewaySyncTimeout
+	if timeout == 0 {
+		timeout = DefaultGatewaySyncTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var gateways []gatewaySync
+	for {
+		statuses, err := v.client.AllDiscoveryDo(waitCtx, v.istioNamespace, "debug/syncz")
+		if err == nil {
+			gateways, err = gatewaySyncStatus(statuses)
+		}
+		if err != nil {
+			v.record("Gateway proxy", "", v.istioNamespace, checkFailed, fmt.Sprintf("could not read xDS sync status from istiod: %v", err))
+			v.logger.LogAndPrintf("%s Could not read xDS sync status from istiod: %v", v.failureMarker, err)
+			return fmt.Errorf("failed to read xDS sync status: %v", err)
+		}
+		if len(unsyncedGateways(gateways)) == 0 {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return v.reportUnsyncedGateways(unsyncedGateways(gateways), timeout)
+		case <-time.After(gatewaySyncPollInterval):
+		}
+	}
+	for _, gw := range gateways {
+		v.record("Gateway proxy", gw.proxyID, "", checkPassed, "")
+	}
+	v.logf(VerbosityNormal, "%s %d gateway proxies ACKed the latest config", v.successMarker, len(gateways))
+	return nil
+}
+
+func (v *StatusVerifier) reportUnsyncedGateways(unsynced []gatewaySync, timeout time.Duration) error {
+	for _, gw := range unsynced {
+		name, namespace := gw.proxyID, ""
+		if i := strings.LastIndex(gw.proxyID, "."); i >= 0 {
+			name, namespace = gw.proxyID[:i], gw.proxyID[i+1:]
+		}
+		v.reportFailure("Gateway proxy", name, namespace,
+			fmt.Errorf("%s config from %s not ACKed within %v", strings.Join(gw.stale, ", "), gw.istiod, timeout))
+	}
+	return fmt.Errorf("%d gateway proxies did not ACK the latest config within %v", len(unsynced), timeout)
+}
+
+// gatewaySyncStatus extracts the sync state of the gateway proxies from the debug/syncz
+// responses of each istiod, keyed by istiod.
+func gatewaySyncStatus(statuses map[string][]byte) ([]gatewaySync, error) {
+	var gateways []gatewaySync
+	for istiod, body := range statuses {
+		var ss []xds.SyncStatus
+		if err := json.Unmarshal(body, &ss); err != nil {
+			return nil, fmt.Errorf("%s: %v", istiod, err)
+		}
+		for _, s := range ss {
+			if s.ProxyType != model.Router {
+				continue
+			}
+			gw := gatewaySync{proxyID: s.ProxyID, istiod: istiod}
+			for _, t := range []struct {
+				name        string
+				sent, acked string
+			}{
+				{"CDS", s.ClusterSent, s.ClusterAcked},
+				{"LDS", s.ListenerSent, s.ListenerAcked},
+				{"EDS", s.EndpointSent, s.EndpointAcked},
+				{"RDS", s.RouteSent, s.RouteAcked},
+				{"ECDS", s.ExtensionConfigSent, s.ExtensionConfigAcked},
+			} {
+				if t.sent != "" && t.sent != t.acked {
+					gw.stale = append(gw.stale, t.name)
+				}
+			}
+			gateways = append(gateways, gw)
+		}
+	}
+	sort.Slice(gateways, func(i, j int) bool {
+		return gateways[i].proxyID < gateways[j].proxyID
+	})
+	return gateways, nil
+}
+
+func unsyncedGateways(gateways []gatewaySync) []gatewaySync {
+	var unsynced []gatewaySync
+	for _, gw := range gateways {
+		if len(gw.stale) > 0 {
+			unsynced = append(unsynced, gw)
+		}
+	}
+	return unsynced
+}
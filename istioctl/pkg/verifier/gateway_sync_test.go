@@ -0,0 +1,62 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestGatewaySyncStatus(t *testing.T) {
+	statuses := map[string][]byte{
+		"istiod-1": []byte(`[
+  {"proxy": "istio-ingressgateway-abc.istio-system", "proxy_type": "router",
+   "cluster_sent": "n1", "cluster_acked": "n1", "listener_sent": "n2", "listener_acked": "n1",
+   "route_sent": "n3", "endpoint_sent": "n4", "endpoint_acked": "n4"},
+  {"proxy": "productpage-v1-xyz.default", "proxy_type": "sidecar",
+   "cluster_sent": "n1", "cluster_acked": "n0"}
+]`),
+		"istiod-2": []byte(`[
+  {"proxy": "istio-egressgateway-def.istio-system", "proxy_type": "router",
+   "cluster_sent": "n5", "cluster_acked": "n5", "listener_sent": "n6", "listener_acked": "n6"}
+]`),
+	}
+	gateways, err := gatewaySyncStatus(statuses)
+	assert.NoError(t, err)
+	assert.Equal(t, len(gateways), 2)
+	assert.Equal(t, gateways[0].proxyID, "istio-egressgateway-def.istio-system")
+	assert.Equal(t, len(gateways[0].stale), 0)
+	assert.Equal(t, gateways[1].proxyID, "istio-ingressgateway-abc.istio-system")
+	assert.Equal(t, gateways[1].istiod, "istiod-1")
+	assert.Equal(t, gateways[1].stale, []string{"LDS", "RDS"})
+	unsynced := unsyncedGateways(gateways)
+	assert.Equal(t, len(unsynced), 1)
+
+	var out bytes.Buffer
+	v := &StatusVerifier{logger: clog.NewConsoleLogger(&out, &out, nil), failureMarker: "✘"}
+	assert.Error(t, v.reportUnsyncedGateways(unsynced, 30*time.Second))
+	want := "✘ Gateway proxy: istio-ingressgateway-abc.istio-system: LDS, RDS config from istiod-1 not ACKed within 30s"
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("output missing %q:\n%s", want, out.String())
+	}
+
+	_, err = gatewaySyncStatus(map[string][]byte{"istiod-1": []byte("not json")})
+	assert.Error(t, err)
+}
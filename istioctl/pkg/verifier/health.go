@@ -0,0 +1,194 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"istio.io/istio/istioctl/pkg/revisions"
+	"istio.io/istio/pkg/monitoring"
+)
+
+type checkStatus int
+
+const (
+	checkPassed checkStatus = iota
+	checkWarning
+	checkFailed
+)
+
+func (s checkStatus) String() string {
+	switch s {
+	case checkPassed:
+		return "passed"
+	case checkWarning:
+		return "warning"
+	default:
+		return "failed"
+	}
+}
+
+// checkResult is the outcome of checking a single resource or aspect of the installation.
+type checkResult struct {
+	kind      string
+	name      string
+	namespace string
+	status    checkStatus
+	// reason is why the check failed or warned, empty for passed checks.
+	reason string
+	// component is the Istio component the checked resource belongs to, if known.
+	component string
+	// code identifies the problem of failed and warned checks.
+	code FailureCode
+}
+
+// CheckResult is the result of checking a resource of the installation, as printed by the machine-readable
+// output formats of verify-install.
+type CheckResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Status is one of passed, warning or failed.
+	Status string `json:"status"`
+	// Reason is why the check failed or warned.
+	Reason string `json:"reason,omitempty"`
+	// Component is the Istio component the checked resource belongs to, if known.
+	Component string `json:"component,omitempty"`
+	// Code identifies the problem of failed and warned checks, such as IST-VER-DEPLOY-UNAVAILABLE. Codes are
+	// stable, so that support tooling can map them to runbooks.
+	Code string `json:"code,omitempty"`
+	// Hint is a short remediation of the problem of failed and warned checks.
+	Hint string `json:"hint,omitempty"`
+	// DocURL is the documentation of the problem of failed and warned checks.
+	DocURL string `json:"docURL,omitempty"`
+}
+
+// Results returns the results of the checks of the last verification, in the order they were checked.
+func (v *StatusVerifier) Results() []CheckResult {
+	results := make([]CheckResult, 0, len(v.results))
+	for _, r := range v.results {
+		results = append(results, r.export())
+	}
+	return results
+}
+
+func (r checkResult) export() CheckResult {
+	return CheckResult{
+		Kind:      r.kind,
+		Name:      r.name,
+		Namespace: r.namespace,
+		Status:    r.status.String(),
+		Reason:    r.reason,
+		Component: r.component,
+		Code:      string(r.code),
+		Hint:      r.code.Hint(),
+		DocURL:    r.code.DocURL(),
+	}
+}
+
+// defaultCheckWeight is the weight of results of kinds not listed in checkWeights.
+const defaultCheckWeight = 1
+
+// checkWeights weigh the results of each kind in the health score, so that a control plane that is down
+// counts more than, say, a missing cluster role.
+var checkWeights = map[string]int{
+	"Deployment":               10,
+	"DaemonSet":                10,
+	"Smoke test":               10,
+	"StatefulSet":              5,
+	"Gateway proxy":            5,
+	"Gateway service":          5,
+	gatewayKind:                5,
+	"East-west gateway":        5,
+	"Job":                      3,
+	"PersistentVolumeClaim":    3,
+	"Gateway":                  3,
+	"CustomResourceDefinition": 2,
+}
+
+var (
+	revisionLabel = monitoring.CreateLabel("revision")
+
+	healthScoreGauge = monitoring.NewGauge(
+		"istioctl_verify_install_health_score",
+		"Health score of the installation, from 0 to 100, computed from the weighted results of the last verification.",
+	)
+)
+
+// record records the result of a check for the health score and the machine-readable output, with the reason
+// of failed and warned checks, and notifies the hooks.
+func (v *StatusVerifier) record(kind, name, namespace string, status checkStatus, reason string) {
+	v.recordCode(kind, name, namespace, status, reason, failureCode(kind, nil))
+}
+
+// recordError records a failed or warned check with the error it found.
+func (v *StatusVerifier) recordError(kind, name, namespace string, status checkStatus, err error) {
+	v.recordCode(kind, name, namespace, status, err.Error(), failureCode(kind, err))
+}
+
+func (v *StatusVerifier) recordCode(kind, name, namespace string, status checkStatus, reason string, code FailureCode) {
+	if status == checkPassed {
+		code = ""
+	}
+	v.results = append(v.results, checkResult{kind: kind, name: name, namespace: namespace, status: status, reason: reason, code: code})
+	v.checkDone()
+}
+
+// healthScore computes a score from 0 to 100 from the weighted results. Warnings count half. Without
+// results the score is 0, as nothing could be verified.
+func healthScore(results []checkResult) int {
+	total, earned := 0, 0
+	for _, r := range results {
+		weight, f := checkWeights[r.kind]
+		if !f {
+			weight = defaultCheckWeight
+		}
+		total += 2 * weight
+		switch r.status {
+		case checkPassed:
+			earned += 2 * weight
+		case checkWarning:
+			earned += weight
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * earned / total
+}
+
+// HealthScore returns the health score of the last verification, from 0 to 100. Unlike the error returned
+// by Verify it grades the installation, so that installations can be ranked and their degradation followed
+// over time.
+func (v *StatusVerifier) HealthScore() int {
+	return healthScore(v.results)
+}
+
+// reportHealthScore prints the health score and records it as a metric, for processes embedding the
+// verifier that export metrics.
+func (v *StatusVerifier) reportHealthScore() {
+	var passed, warnings, failed int
+	for _, r := range v.results {
+		switch r.status {
+		case checkPassed:
+			passed++
+		case checkWarning:
+			warnings++
+		case checkFailed:
+			failed++
+		}
+	}
+	score := v.HealthScore()
+	v.logf(VerbosityNormal, "Health score: %d/100 (%d passed, %d warnings, %d failed)", score, passed, warnings, failed)
+	healthScoreGauge.With(revisionLabel.Value(revisions.Normalize(v.controlPlaneOpts.Revision))).Record(float64(score))
+}
@@ -0,0 +1,105 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestHealthScore(t *testing.T) {
+	cases := []struct {
+		name    string
+		results []checkResult
+		want    int
+	}{
+		{name: "nothing checked", want: 0},
+		{
+			name: "all passed",
+			results: []checkResult{
+				{kind: "Deployment", status: checkPassed},
+				{kind: "ClusterRole", status: checkPassed},
+			},
+			want: 100,
+		},
+		{
+			name: "failed deployment weighs more than a failed cluster role",
+			results: []checkResult{
+				{kind: "Deployment", status: checkFailed},
+				{kind: "ClusterRole", status: checkPassed},
+			},
+			want: 9,
+		},
+		{
+			name: "failed cluster role",
+			results: []checkResult{
+				{kind: "Deployment", status: checkPassed},
+				{kind: "ClusterRole", status: checkFailed},
+			},
+			want: 90,
+		},
+		{
+			name: "warnings count half",
+			results: []checkResult{
+				{kind: "PersistentVolumeClaim", status: checkWarning},
+				{kind: "PersistentVolumeClaim", status: checkPassed},
+			},
+			want: 75,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, healthScore(c.results), c.want)
+		})
+	}
+}
+
+func TestReportHealthScore(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		logger:        clog.NewConsoleLogger(&out, &out, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+	}
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("PersistentVolumeClaim", "data", "istio-system", fmt.Errorf("pending"))
+	v.reportFailure("ClusterRole", "istiod", "", fmt.Errorf("missing"))
+	v.reportHealthScore()
+	if want := "Health score: 82/100 (1 passed, 1 warnings, 1 failed)"; !strings.Contains(out.String(), want) {
+		t.Fatalf("expected output to contain %q, got:\n%s", want, out.String())
+	}
+}
+
+func TestResults(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		logger:        clog.NewConsoleLogger(&out, &out, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+	}
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("Namespace", "default", "", fmt.Errorf("namespace is wide open"))
+	v.reportFailure("Job", "setup", "istio-system", fmt.Errorf("not complete"))
+	assert.Equal(t, v.Results(), []CheckResult{
+		{Kind: "Deployment", Name: "istiod", Namespace: "istio-system", Status: "passed"},
+		coded(CheckResult{Kind: "Namespace", Name: "default", Status: "warning", Reason: "namespace is wide open"}, CodeCheckFailed),
+		coded(CheckResult{Kind: "Job", Name: "setup", Namespace: "istio-system", Status: "failed", Reason: "not complete"}, CodeCheckFailed),
+	})
+}
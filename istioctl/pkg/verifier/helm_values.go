@@ -0,0 +1,223 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/strvals"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/operator/pkg/manifest"
+)
+
+// helmValuesProfile is the profile the Helm values of the istiod chart are layered on. It installs the same
+// components as the base and istiod charts, so components that Helm users install separately are not expected.
+const helmValuesProfile = "minimal"
+
+// gatewayValuesProfile is the profile the Helm values of the gateway chart are layered on. The gateway chart only
+// installs a gateway, so no other component is expected.
+const gatewayValuesProfile = "empty"
+
+// HelmChart is a chart whose Helm values an installation can be verified against.
+type HelmChart string
+
+const (
+	// HelmChartIstiod is the istiod chart, along with the base chart.
+	HelmChartIstiod HelmChart = "istiod"
+	// HelmChartGateway is the gateway chart, which installs a single gateway.
+	HelmChartGateway HelmChart = "gateway"
+)
+
+// HelmCharts are the names of the charts, as accepted by ParseHelmChart.
+var HelmCharts = []string{string(HelmChartIstiod), string(HelmChartGateway)}
+
+// ParseHelmChart parses the name of a chart.
+func ParseHelmChart(name string) (HelmChart, error) {
+	switch HelmChart(name) {
+	case HelmChartIstiod, HelmChartGateway:
+		return HelmChart(name), nil
+	}
+	return "", fmt.Errorf("invalid chart %q, expected one of %v", name, HelmCharts)
+}
+
+// WithHelmValues makes the verifier compare the cluster against the Helm values of a chart instead of an
+// IstioOperator. Values files are merged in order, followed by values in Helm --set format.
+func WithHelmValues(chart HelmChart, valuesFiles, setValues []string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.helmChart = chart
+		s.checks.valuesFiles = valuesFiles
+		s.checks.setValues = setValues
+	}
+}
+
+func (v *StatusVerifier) verifyHelmValues(ctx context.Context) error {
+	values, err := mergeHelmValues(v.checks.valuesFiles, v.checks.setValues)
+	if err != nil {
+		return err
+	}
+	profile := helmValuesProfile
+	var iopYAML string
+	if v.checks.helmChart == HelmChartGateway {
+		profile = gatewayValuesProfile
+		iopYAML, err = gatewayValuesToIOP(values, v.istioNamespace)
+	} else {
+		iopYAML, err = helmValuesToIOP(values)
+	}
+	if err != nil {
+		return err
+	}
+	revision := v.controlPlaneOpts.Revision
+	if r, ok := values["revision"].(string); ok && revision == "" {
+		revision = r
+	}
+	iop, err := manifest.GetMergedIOP(iopYAML, profile, v.manifestsPath, revision, v.client, v.logger)
+	if err != nil {
+		return fmt.Errorf("could not convert Helm values to an IstioOperator: %v", err)
+	}
+	crdCount, istioDeploymentCount, daemonSetCount, err := v.verifyPostInstallIstioOperator(
+		ctx, iop, fmt.Sprintf("Helm values %s", helmValuesSource(v.checks.valuesFiles, v.checks.setValues)))
+	return v.reportStatus(ctx, crdCount, istioDeploymentCount, daemonSetCount, err)
+}
+
+// mergeHelmValues merges values files and --set values the way Helm does: later files override
+// earlier ones, and --set values override all files.
+func mergeHelmValues(valuesFiles, setValues []string) (map[string]any, error) {
+	base := map[string]any{}
+	for _, f := range valuesFiles {
+		by, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		current := map[string]any{}
+		if err := yaml.Unmarshal(by, &current); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", f, err)
+		}
+		base = mergeValues(base, current)
+	}
+	for _, s := range setValues {
+		if err := strvals.ParseInto(s, base); err != nil {
+			return nil, fmt.Errorf("failed parsing --set data %q: %v", s, err)
+		}
+	}
+	return base, nil
+}
+
+// mergeValues deep merges b into a, with values from b taking precedence.
+func mergeValues(a, b map[string]any) map[string]any {
+	out := make(map[string]any, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if bm, ok := v.(map[string]any); ok {
+			if am, ok := out[k].(map[string]any); ok {
+				out[k] = mergeValues(am, bm)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// helmValuesToIOP wraps Helm values in an IstioOperator. The Helm values of the Istio charts are
+// the same values the IstioOperator passes to those charts, so they map to spec.values as is.
+func helmValuesToIOP(values map[string]any) (string, error) {
+	iop := map[string]any{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"spec": map[string]any{
+			"values": values,
+		},
+	}
+	by, err := yaml.Marshal(iop)
+	if err != nil {
+		return "", err
+	}
+	return string(by), nil
+}
+
+// gatewayValuesToIOP maps the Helm values of the gateway chart onto an ingress gateway of an IstioOperator, in the
+// given namespace. The gateway chart names the gateway after its Helm release, which is not one of its values, so
+// the name value must be set to the release name. Only the replicas, autoscaling, resources and the type and ports
+// of the service are mapped, other values of the gateway chart are not verified.
+func gatewayValuesToIOP(values map[string]any, namespace string) (string, error) {
+	name, _ := values["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("the gateway chart names the gateway after its Helm release, set the name value " +
+			"to the release name, e.g. --set name=istio-ingressgateway")
+	}
+	k8s := map[string]any{}
+	if resources, ok := values["resources"]; ok {
+		k8s["resources"] = resources
+	}
+	if service, ok := values["service"].(map[string]any); ok {
+		s := map[string]any{}
+		for _, k := range []string{"type", "ports"} {
+			if v, ok := service[k]; ok {
+				s[k] = v
+			}
+		}
+		k8s["service"] = s
+	}
+	// The gateway chart lets the gateway bind privileged ports with a sysctl rather than by running as root, which
+	// the validation of IstioOperator gateways does not know about.
+	gatewayValues := map[string]any{"autoscaleEnabled": false, "runAsRoot": true}
+	if autoscaling, ok := values["autoscaling"].(map[string]any); ok && autoscaling["enabled"] == true {
+		gatewayValues["autoscaleEnabled"] = true
+		gatewayValues["autoscaleMin"] = autoscaling["minReplicas"]
+		gatewayValues["autoscaleMax"] = autoscaling["maxReplicas"]
+	} else if replicas, ok := values["replicaCount"]; ok && replicas != nil {
+		k8s["replicaCount"] = replicas
+	}
+	iopValues := map[string]any{
+		"gateways": map[string]any{"istio-ingressgateway": gatewayValues},
+	}
+	if revision, ok := values["revision"].(string); ok && revision != "" {
+		iopValues["revision"] = revision
+	}
+	iop := map[string]any{
+		"apiVersion": "install.istio.io/v1alpha1",
+		"kind":       "IstioOperator",
+		"spec": map[string]any{
+			"components": map[string]any{
+				"ingressGateways": []any{map[string]any{
+					"name":      name,
+					"namespace": namespace,
+					"enabled":   true,
+					"k8s":       k8s,
+				}},
+			},
+			"values": iopValues,
+		},
+	}
+	by, err := yaml.Marshal(iop)
+	if err != nil {
+		return "", err
+	}
+	return string(by), nil
+}
+
+func helmValuesSource(valuesFiles, setValues []string) string {
+	sources := append([]string{}, valuesFiles...)
+	if len(setValues) > 0 {
+		sources = append(sources, "--set "+strings.Join(setValues, ","))
+	}
+	return strings.Join(sources, ",")
+}
@@ -0,0 +1,127 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/operator/pkg/controlplane"
+	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/operator/pkg/translate"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/env"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestMergeHelmValues(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.yaml")
+	second := filepath.Join(dir, "second.yaml")
+	assert.NoError(t, os.WriteFile(first, []byte("pilot:\n  replicaCount: 1\n  autoscaleEnabled: true\nrevision: canary\n"), 0o644))
+	assert.NoError(t, os.WriteFile(second, []byte("pilot:\n  replicaCount: 3\n"), 0o644))
+
+	values, err := mergeHelmValues([]string{first, second}, []string{"pilot.autoscaleEnabled=false,global.hub=docker.io/istio"})
+	assert.NoError(t, err)
+	assert.Equal(t, values, map[string]any{
+		"pilot": map[string]any{
+			"replicaCount":     float64(3),
+			"autoscaleEnabled": false,
+		},
+		"global": map[string]any{
+			"hub": "docker.io/istio",
+		},
+		"revision": "canary",
+	})
+
+	_, err = mergeHelmValues([]string{filepath.Join(dir, "missing.yaml")}, nil)
+	assert.Error(t, err)
+}
+
+func TestHelmValuesToIOP(t *testing.T) {
+	manifests := filepath.Join(env.IstioSrc, "manifests")
+	values, err := mergeHelmValues(
+		[]string{filepath.Join(manifests, "charts/istio-control/istio-discovery/values.yaml")},
+		[]string{"pilot.replicaCount=2"})
+	assert.NoError(t, err)
+	iopYAML, err := helmValuesToIOP(values)
+	assert.NoError(t, err)
+	iop, err := manifest.GetMergedIOP(iopYAML, helmValuesProfile, manifests, "", nil, clog.NewDefaultLogger())
+	assert.NoError(t, err)
+	assert.Equal(t, iop.Spec.Values.AsMap()["pilot"].(map[string]any)["replicaCount"], any(float64(2)))
+}
+
+func TestGatewayValuesToIOP(t *testing.T) {
+	manifests := filepath.Join(env.IstioSrc, "manifests")
+	gatewayValues := filepath.Join(manifests, "charts/gateway/values.yaml")
+	values, err := mergeHelmValues([]string{gatewayValues}, []string{"name=istio-ingress,service.type=ClusterIP"})
+	assert.NoError(t, err)
+	iopYAML, err := gatewayValuesToIOP(values, "istio-ingress")
+	assert.NoError(t, err)
+	iop, err := manifest.GetMergedIOP(iopYAML, gatewayValuesProfile, manifests, "", nil, clog.NewDefaultLogger())
+	assert.NoError(t, err)
+	assert.Equal(t, iop.Spec.Components.Pilot.Enabled.GetValue(), false)
+	// The disabled default gateway of the profile is kept along with the gateway of the chart.
+	var enabled []string
+	for _, g := range iop.Spec.Components.IngressGateways {
+		if g.Enabled.GetValue() {
+			enabled = append(enabled, g.Name)
+			assert.Equal(t, g.Namespace, "istio-ingress")
+			assert.Equal(t, g.K8S.Service.Type, "ClusterIP")
+			assert.Equal(t, len(g.K8S.Service.Ports), 3)
+		}
+	}
+	assert.Equal(t, enabled, []string{"istio-ingress"})
+	gateway := iop.Spec.Values.AsMap()["gateways"].(map[string]any)["istio-ingressgateway"].(map[string]any)
+	assert.Equal(t, gateway["autoscaleEnabled"], any(true))
+	assert.Equal(t, gateway["autoscaleMax"], any(float64(5)))
+
+	// The gateway is rendered under the name of the release, as the gateway chart installs it.
+	cp, err := controlplane.NewIstioControlPlane(iop.Spec, translate.NewTranslator(), nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.Run())
+	componentManifests, errs := cp.RenderManifest()
+	assert.NoError(t, errs.ToError())
+	rendered := strings.Join(componentManifests[name.IngressComponentName], "\n---\n")
+	for _, kind := range []string{"Deployment", "Service", "HorizontalPodAutoscaler"} {
+		if !strings.Contains(rendered, "kind: "+kind+"\n") {
+			t.Fatalf("expected a %s to be rendered, got %s", kind, rendered)
+		}
+	}
+	if !strings.Contains(rendered, "name: istio-ingress\n  namespace: istio-ingress") {
+		t.Fatalf("expected the gateway istio-ingress to be rendered in its namespace, got %s", rendered)
+	}
+
+	// The gateway chart names the gateway after its release, which is not one of its values.
+	values, err = mergeHelmValues([]string{gatewayValues}, nil)
+	assert.NoError(t, err)
+	_, err = gatewayValuesToIOP(values, "istio-ingress")
+	if err == nil || !strings.Contains(err.Error(), "release name") {
+		t.Fatalf("expected the release name to be required, got %v", err)
+	}
+}
+
+func TestParseHelmChart(t *testing.T) {
+	for _, name := range HelmCharts {
+		chart, err := ParseHelmChart(name)
+		assert.NoError(t, err)
+		assert.Equal(t, string(chart), name)
+	}
+	_, err := ParseHelmChart("base")
+	assert.Error(t, err)
+}
@@ -0,0 +1,178 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// HistoryConfigMap is the ConfigMap of the Istio namespace which holds the history of the verifications.
+	HistoryConfigMap = "istio-verify-install-history"
+	// historyKey is the key of the records in the ConfigMap, as a JSON list from the oldest to the latest.
+	historyKey = "history"
+	// maxHistory is the number of records kept, the oldest ones being dropped, to stay far below the size limit
+	// of ConfigMaps.
+	maxHistory = 100
+)
+
+// HistoryRecord is the compact result of a verification kept in the history.
+type HistoryRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Revision    string    `json:"revision"`
+	Passed      bool      `json:"passed"`
+	HealthScore int       `json:"healthScore"`
+	// Failed are the resources which failed their checks, as <kind>/<namespace>/<name>.
+	Failed []string `json:"failed,omitempty"`
+	// Regressions are the failed resources which passed in the previous verification of the revision. They are
+	// not kept in the history, but computed when it is read.
+	Regressions []string `json:"regressions,omitempty"`
+}
+
+// WithHistory appends the result of every verification to the history kept in the HistoryConfigMap.
+func WithHistory() StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.history = true
+	}
+}
+
+// newHistoryRecord returns the record of a verification.
+func newHistoryRecord(r *VerificationResult, verifyErr error) HistoryRecord {
+	record := HistoryRecord{
+		Timestamp:   r.Start.UTC().Truncate(time.Second),
+		Revision:    r.Revision,
+		Passed:      verifyErr == nil,
+		HealthScore: r.HealthScore,
+	}
+	for _, c := range r.Failures {
+		record.Failed = append(record.Failed, c.Kind+"/"+c.Namespace+"/"+c.Name)
+	}
+	return record
+}
+
+// recordHistory appends the record of a verification to the history, creating its ConfigMap if needed.
+func (v *StatusVerifier) recordHistory(ctx context.Context, record HistoryRecord) error {
+	cms := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := cms.Get(ctx, HistoryConfigMap, metav1.GetOptions{})
+		create := kerrors.IsNotFound(err)
+		if create {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: HistoryConfigMap, Namespace: v.istioNamespace}}
+		} else if err != nil {
+			return err
+		}
+		records, err := parseHistory(cm)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+		if len(records) > maxHistory {
+			records = records[len(records)-maxHistory:]
+		}
+		out, err := json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[historyKey] = string(out)
+		if create {
+			_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+			return err
+		}
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+func parseHistory(cm *corev1.ConfigMap) ([]HistoryRecord, error) {
+	data := cm.Data[historyKey]
+	if data == "" {
+		return nil, nil
+	}
+	var records []HistoryRecord
+	if err := json.Unmarshal([]byte(data), &records); err != nil {
+		return nil, fmt.Errorf("invalid history in configmap %s/%s: %v", cm.Namespace, cm.Name, err)
+	}
+	return records, nil
+}
+
+// History returns the records of the past verifications, from the oldest to the latest, with their regressions.
+// It is empty if no verification was recorded.
+func (v *StatusVerifier) History(ctx context.Context) ([]HistoryRecord, error) {
+	cm, err := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace).Get(ctx, HistoryConfigMap, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the history of the verifications: %v", err)
+	}
+	records, err := parseHistory(cm)
+	if err != nil {
+		return nil, err
+	}
+	previous := map[string]HistoryRecord{}
+	for i, r := range records {
+		if prev, f := previous[r.Revision]; f {
+			failedBefore := sets.New(prev.Failed...)
+			for _, failed := range r.Failed {
+				if !failedBefore.Contains(failed) {
+					records[i].Regressions = append(records[i].Regressions, failed)
+				}
+			}
+		}
+		previous[r.Revision] = r
+	}
+	return records, nil
+}
+
+// WriteHistory writes the records as a table.
+func WriteHistory(w io.Writer, records []HistoryRecord) error {
+	if len(records) == 0 {
+		_, err := fmt.Fprintln(w, "No verification was recorded, verify the installation with --record-history.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tREVISION\tRESULT\tSCORE\tFAILED\tREGRESSIONS")
+	for _, r := range records {
+		result := "passed"
+		if !r.Passed {
+			result = "failed"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", r.Timestamp.Format(time.RFC3339), r.Revision, result, r.HealthScore,
+			len(r.Failed), orNone(r.Regressions))
+	}
+	return tw.Flush()
+}
+
+func orNone(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
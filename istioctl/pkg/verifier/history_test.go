@@ -0,0 +1,85 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestHistory(t *testing.T) {
+	v := &StatusVerifier{istioNamespace: "istio-system", client: kube.NewFakeClient()}
+	WithOutput(io.Discard)(v)
+	WithHistory()(v)
+
+	records, err := v.History(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(records), 0)
+
+	start := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	verified := func(revision string, failed ...string) {
+		v.results = nil
+		v.controlPlaneOpts.Revision = revision
+		v.reportSuccess("Deployment", "istiod", "istio-system")
+		var err error
+		for _, name := range failed {
+			v.reportFailure("Deployment", name, "istio-system", errors.New("not ready"))
+			err = errors.New("verification failed")
+		}
+		start = start.Add(time.Hour)
+		assert.NoError(t, v.recordHistory(context.Background(), newHistoryRecord(v.result(start), err)))
+	}
+	verified("")
+	verified("canary", "istio-ingressgateway")
+	verified("", "istio-eastwestgateway")
+	verified("canary", "istio-ingressgateway", "istio-egressgateway")
+
+	records, err = v.History(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(records), 4)
+	assert.Equal(t, records[0].Passed, true)
+	assert.Equal(t, records[0].Revision, "default")
+	assert.Equal(t, records[1].Regressions, nil)
+	assert.Equal(t, records[2].Failed, []string{"Deployment/istio-system/istio-eastwestgateway"})
+	assert.Equal(t, records[2].Regressions, []string{"Deployment/istio-system/istio-eastwestgateway"})
+	assert.Equal(t, records[3].Regressions, []string{"Deployment/istio-system/istio-egressgateway"})
+
+	var out bytes.Buffer
+	assert.NoError(t, WriteHistory(&out, records))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), 5)
+	if !strings.Contains(lines[4], "2023-10-01T16:00:00Z  canary") || !strings.HasSuffix(lines[4], "Deployment/istio-system/istio-egressgateway") {
+		t.Errorf("unexpected history line %q", lines[4])
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	v := &StatusVerifier{istioNamespace: "istio-system", client: kube.NewFakeClient()}
+	for i := 0; i < maxHistory+5; i++ {
+		assert.NoError(t, v.recordHistory(context.Background(), HistoryRecord{Revision: "default", HealthScore: i}))
+	}
+	records, err := v.History(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(records), maxHistory)
+	assert.Equal(t, records[0].HealthScore, 5)
+}
@@ -0,0 +1,55 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+// Hooks are notified of the progress of a verification, for callers such as controllers or terminal UIs which
+// follow the checks as they are made rather than waiting for the result of Verify. Both hooks are optional.
+type Hooks struct {
+	// OnCheckStart is called before an installed resource is checked. The optional checks, which check
+	// resources they discover, only call OnCheckResult.
+	OnCheckStart func(kind, name, namespace string)
+	// OnCheckResult is called with the result of every check as it is made. The component of the result is
+	// not known yet; it is in the results of Verify.
+	OnCheckResult func(r CheckResult)
+}
+
+// WithHooks adds hooks notified of the progress of the verification. It can be passed several times.
+func WithHooks(h Hooks) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.hooks = append(s.hooks, h)
+	}
+}
+
+// checkStarted calls the OnCheckStart hooks.
+func (v *StatusVerifier) checkStarted(kind, name, namespace string) {
+	for _, h := range v.hooks {
+		if h.OnCheckStart != nil {
+			h.OnCheckStart(kind, name, namespace)
+		}
+	}
+}
+
+// checkDone calls the OnCheckResult hooks with the result of the check recorded last.
+func (v *StatusVerifier) checkDone() {
+	if len(v.results) == 0 {
+		return
+	}
+	r := v.results[len(v.results)-1].export()
+	for _, h := range v.hooks {
+		if h.OnCheckResult != nil {
+			h.OnCheckResult(r)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWithHooks(t *testing.T) {
+	var events []string
+	v := &StatusVerifier{}
+	WithOutput(io.Discard)(v)
+	WithHooks(Hooks{
+		OnCheckStart: func(kind, name, namespace string) {
+			events = append(events, "start "+kind+" "+namespace+"/"+name)
+		},
+		OnCheckResult: func(r CheckResult) {
+			events = append(events, r.Status+" "+r.Kind+" "+r.Namespace+"/"+r.Name)
+		},
+	})(v)
+	// Hooks without callbacks are ignored.
+	WithHooks(Hooks{})(v)
+
+	v.checkStarted("Deployment", "istiod", "istio-system")
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.checkStarted("DaemonSet", "istio-cni-node", "kube-system")
+	v.reportFailure("DaemonSet", "istio-cni-node", "kube-system", errors.New("not ready"))
+	v.reportWarning("Namespace", "default", "", errors.New("namespace is wide open"))
+
+	assert.Equal(t, events, []string{
+		"start Deployment istio-system/istiod",
+		"passed Deployment istio-system/istiod",
+		"start DaemonSet kube-system/istio-cni-node",
+		"failed DaemonSet kube-system/istio-cni-node",
+		"warning Namespace /default",
+	})
+}
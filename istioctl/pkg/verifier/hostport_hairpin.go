@@ -0,0 +1,193 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"istio.io/api/annotation"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	kubeProxyConfigMap = "kube-proxy"
+	ciliumConfigMap    = "cilium-config"
+)
+
+// nodeDataPlane describes how the nodes of the cluster forward Service and hostPort traffic.
+type nodeDataPlane struct {
+	// kubeProxyMode is the kube-proxy proxy mode, empty if kube-proxy's config could not be found.
+	kubeProxyMode string
+	// ciliumKubeProxyReplacement is set when Cilium replaces kube-proxy.
+	ciliumKubeProxyReplacement bool
+	// ciliumSocketLBInPods is set when Cilium translates Service and hostPort addresses at connect()
+	// time inside pod network namespaces too, not only in the host network namespace.
+	ciliumSocketLBInPods bool
+}
+
+// hairpinBypass is a node data plane combination known to make hostPort traffic of injected pods
+// bypass the sidecar.
+type hairpinBypass struct {
+	name        string
+	matches     func(nodeDataPlane) bool
+	remediation string
+}
+
+var hairpinBypasses = []hairpinBypass{
+	{
+		name: "Cilium kube-proxy replacement with socket load balancing in pod namespaces",
+		matches: func(dp nodeDataPlane) bool {
+			return dp.ciliumKubeProxyReplacement && dp.ciliumSocketLBInPods
+		},
+		// Connections to hostPorts and Services are rewritten to the backend pod address before a packet
+		// is sent, so the sidecar sees pod to pod traffic and a pod reaching its own hostPort goes over lo.
+		remediation: "connections from injected pods are translated to backend addresses before the sidecar " +
+			"captures them; set bpf-lb-sock-hostns-only: \"true\" (Helm socketLB.hostNamespaceOnly=true) in the Cilium config",
+	},
+}
+
+// verifyHostPortHairpin checks that traffic to the hostPorts of sidecar injected pods is captured: the
+// ports must not be excluded from inbound capture, and the node data plane must not be one known to
+// bypass capture.
+func (v *StatusVerifier) verifyHostPortHairpin(ctx context.Context) error {
+	pods, err := v.scopedPods(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	checked := 0
+	for i := range pods {
+		pod := &pods[i]
+		if _, f := pod.Annotations[annotation.SidecarStatus.Name]; !f {
+			continue
+		}
+		ports := hostPortContainerPorts(pod)
+		if len(ports) == 0 {
+			continue
+		}
+		checked++
+		if excluded := uncapturedInboundPorts(pod, ports); len(excluded) > 0 {
+			err := fmt.Errorf("hostPort traffic to container ports %v bypasses the sidecar, the ports are excluded from inbound capture", excluded)
+			v.reportFailure("Pod", pod.Name, pod.Namespace, withCode(CodeHostPortBypass, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("pod %s/%s: %v", pod.Namespace, pod.Name, err))
+			continue
+		}
+		v.record("Pod", pod.Name, pod.Namespace, checkPassed, "")
+		v.logf(VerbosityNormal, "%s Pod: %s.%s hostPorts checked successfully", v.successMarker, pod.Name, pod.Namespace)
+	}
+	if checked == 0 {
+		v.logf(VerbosityNormal, "No sidecar injected pods use hostPorts")
+		return multiErr.ErrorOrNil()
+	}
+
+	dp, err := v.nodeDataPlane(ctx)
+	if err != nil {
+		return multierror.Append(multiErr, err).ErrorOrNil()
+	}
+	if dp.kubeProxyMode != "" {
+		v.logf(VerbosityNormal, "kube-proxy mode: %s", dp.kubeProxyMode)
+	}
+	for _, b := range hairpinBypasses {
+		if b.matches(dp) {
+			v.record("Node data plane", b.name, "", checkFailed, b.remediation)
+			v.logger.LogAndPrintf("%s Node data plane: %s: %s", v.failureMarker, b.name, b.remediation)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("%s bypasses capture of hostPort traffic of %d pods", b.name, checked))
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// hostPortContainerPorts returns the application container ports exposed with a hostPort.
+func hostPortContainerPorts(pod *corev1.Pod) []int32 {
+	var ports []int32
+	for _, c := range pod.Spec.Containers {
+		if c.Name == "istio-proxy" {
+			continue
+		}
+		for _, p := range c.Ports {
+			if p.HostPort != 0 && (p.Protocol == "" || p.Protocol == corev1.ProtocolTCP) {
+				ports = append(ports, p.ContainerPort)
+			}
+		}
+	}
+	return ports
+}
+
+// uncapturedInboundPorts returns the ports which the inbound port annotations of the pod exclude from capture.
+func uncapturedInboundPorts(pod *corev1.Pod, ports []int32) []int32 {
+	excluded := portSet(pod.Annotations[annotation.SidecarTrafficExcludeInboundPorts.Name])
+	include, includeSet := pod.Annotations[annotation.SidecarTrafficIncludeInboundPorts.Name]
+	included := portSet(include)
+	var uncaptured []int32
+	for _, p := range ports {
+		port := strconv.Itoa(int(p))
+		if excluded.Contains(port) || (includeSet && include != "*" && !included.Contains(port)) {
+			uncaptured = append(uncaptured, p)
+		}
+	}
+	return uncaptured
+}
+
+func portSet(ports string) sets.String {
+	s := sets.New[string]()
+	for _, p := range strings.Split(ports, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			s.Insert(p)
+		}
+	}
+	return s
+}
+
+// nodeDataPlane reads the kube-proxy and Cilium configuration from kube-system.
+func (v *StatusVerifier) nodeDataPlane(ctx context.Context) (nodeDataPlane, error) {
+	var dp nodeDataPlane
+	cms := v.client.Kube().CoreV1().ConfigMaps(metav1.NamespaceSystem)
+	kp, err := cms.Get(ctx, kubeProxyConfigMap, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		var cfg struct {
+			Mode string `json:"mode"`
+		}
+		if err := yaml.Unmarshal([]byte(kp.Data["config.conf"]), &cfg); err != nil {
+			return dp, fmt.Errorf("invalid kube-proxy config: %v", err)
+		}
+		dp.kubeProxyMode = cfg.Mode
+		if dp.kubeProxyMode == "" {
+			dp.kubeProxyMode = "iptables"
+		}
+	case !kerrors.IsNotFound(err):
+		return dp, fmt.Errorf("failed to read kube-proxy config: %v", err)
+	}
+
+	cilium, err := cms.Get(ctx, ciliumConfigMap, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		kpr := cilium.Data["kube-proxy-replacement"]
+		dp.ciliumKubeProxyReplacement = kpr == "true" || kpr == "strict"
+		dp.ciliumSocketLBInPods = cilium.Data["bpf-lb-sock-hostns-only"] != "true"
+	case !kerrors.IsNotFound(err):
+		return dp, fmt.Errorf("failed to read Cilium config: %v", err)
+	}
+	return dp, nil
+}
@@ -0,0 +1,120 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/api/annotation"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func hostPortPod(name string, annotations map[string]string) *corev1.Pod {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotation.SidecarStatus.Name] = "{}"
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Ports: []corev1.ContainerPort{{ContainerPort: 8080, HostPort: 80}, {ContainerPort: 9090}}},
+				{Name: "istio-proxy", Ports: []corev1.ContainerPort{{ContainerPort: 15090, HostPort: 15090}}},
+			},
+		},
+	}
+}
+
+func kubeSystemConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceSystem}, Data: data}
+}
+
+func TestVerifyHostPortHairpin(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr string
+		wantOut string
+	}{
+		{
+			name:    "no hostPort pods",
+			objects: []runtime.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "plain", Namespace: "default"}}},
+			wantOut: "No sidecar injected pods use hostPorts",
+		},
+		{
+			name: "captured with kube-proxy",
+			objects: []runtime.Object{
+				hostPortPod("web", nil),
+				kubeSystemConfigMap(kubeProxyConfigMap, map[string]string{"config.conf": "mode: ipvs\n"}),
+			},
+			wantOut: "kube-proxy mode: ipvs",
+		},
+		{
+			name:    "excluded port",
+			objects: []runtime.Object{hostPortPod("web", map[string]string{annotation.SidecarTrafficExcludeInboundPorts.Name: "8080"})},
+			wantErr: "container ports [8080] bypasses the sidecar",
+		},
+		{
+			name:    "port not included",
+			objects: []runtime.Object{hostPortPod("web", map[string]string{annotation.SidecarTrafficIncludeInboundPorts.Name: "9090"})},
+			wantErr: "container ports [8080] bypasses the sidecar",
+		},
+		{
+			name: "cilium socket load balancing in pods",
+			objects: []runtime.Object{
+				hostPortPod("web", nil),
+				kubeSystemConfigMap(ciliumConfigMap, map[string]string{"kube-proxy-replacement": "true"}),
+			},
+			wantErr: "Cilium kube-proxy replacement with socket load balancing in pod namespaces bypasses capture",
+		},
+		{
+			name: "cilium socket load balancing in host namespace only",
+			objects: []runtime.Object{
+				hostPortPod("web", nil),
+				kubeSystemConfigMap(ciliumConfigMap, map[string]string{"kube-proxy-replacement": "true", "bpf-lb-sock-hostns-only": "true"}),
+			},
+			wantOut: "✔ Pod: web.default hostPorts checked successfully",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:        kube.NewFakeClient(c.objects...),
+				logger:        clog.NewConsoleLogger(&out, &out, nil),
+				successMarker: "✔",
+				failureMarker: "✘",
+			}
+			err := v.verifyHostPortHairpin(context.Background())
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			if !strings.Contains(out.String(), c.wantOut) {
+				t.Fatalf("expected output containing %q, got:\n%s", c.wantOut, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	ggcrname "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// nodeDaemonSets are the DaemonSets of the node data plane, which must run on every node of the mesh, by the
+// component they belong to.
+var nodeDaemonSets = []struct {
+	name      string
+	component name.ComponentName
+}{
+	{name: cniDaemonSetName, component: name.CNIComponentName},
+	{name: ztunnelDaemonSetName, component: name.ZtunnelComponentName},
+}
+
+// ImageInspector returns the platforms, such as linux/arm64, an image is available for.
+type ImageInspector interface {
+	Platforms(ctx context.Context, image string) ([]string, error)
+}
+
+// WithImageInspector makes the image-architectures check compare the platforms the images of the node data plane
+// are available for with the architectures of the nodes, in addition to the pods failing on some architectures.
+func WithImageInspector(inspector ImageInspector) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.imageInspector = inspector
+	}
+}
+
+// NewRegistryImageInspector returns an ImageInspector reading the manifest list of images from their registry,
+// with the credentials of the default keychain, such as those of docker login.
+func NewRegistryImageInspector() ImageInspector {
+	return registryInspector{}
+}
+
+type registryInspector struct{}
+
+func (registryInspector) Platforms(ctx context.Context, image string) ([]string, error) {
+	ref, err := ggcrname.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", image, err)
+	}
+	desc, err := remote.Get(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, err
+	}
+	if desc.MediaType.IsIndex() {
+		index, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		manifest, err := index.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		var platforms []string
+		for _, m := range manifest.Manifests {
+			// The attestations of the images are listed with an unknown platform.
+			if m.Platform != nil && m.Platform.OS != "unknown" {
+				platforms = append(platforms, m.Platform.OS+"/"+m.Platform.Architecture)
+			}
+		}
+		return platforms, nil
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	config, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	return []string{config.OS + "/" + config.Architecture}, nil
+}
+
+// verifyImageArchitectures checks that the DaemonSets of the node data plane, the CNI node agent and ztunnel, can
+// run on the nodes of every architecture of the cluster. They must not be kept off the nodes of an architecture by
+// their node selector or affinity, their pods must not fail to pull their images or to execute them there, and,
+// with an image inspector, their images must be available for the architecture. Problems are warnings, as the
+// pods of the mesh may not run on every node pool.
+func (v *StatusVerifier) verifyImageArchitectures(ctx context.Context) error {
+	nodes, err := v.client.Kube().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	pools := nodePools(nodes.Items)
+	found := 0
+	for _, nds := range nodeDaemonSets {
+		ds, err := v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).Get(ctx, nds.name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get daemonset %s: %v", nds.name, err)
+		}
+		found++
+		start := len(v.results)
+		problems, err := v.daemonSetArchProblems(ctx, ds, pools)
+		if err != nil {
+			return err
+		}
+		for _, p := range problems {
+			v.reportWarning("DaemonSet", ds.Name, ds.Namespace, withCode(CodeImageArchitecture, fmt.Errorf("%s", p)))
+		}
+		if len(problems) == 0 {
+			v.reportSuccess("DaemonSet", ds.Name, ds.Namespace)
+		}
+		v.attributeResults(start, string(nds.component))
+	}
+	if found == 0 {
+		v.logf(VerbosityNormal, "Neither the CNI node agent nor ztunnel is installed, skipping the image architecture checks")
+	}
+	return nil
+}
+
+// nodePools returns the names of the nodes by architecture.
+func nodePools(nodes []corev1.Node) map[string][]string {
+	pools := map[string][]string{}
+	for i := range nodes {
+		node := &nodes[i]
+		arch := node.Status.NodeInfo.Architecture
+		if arch == "" {
+			arch = node.Labels[corev1.LabelArchStable]
+		}
+		if arch != "" {
+			pools[arch] = append(pools[arch], node.Name)
+		}
+	}
+	for _, names := range pools {
+		sort.Strings(names)
+	}
+	return pools
+}
+
+// daemonSetArchProblems returns why the DaemonSet cannot run on the nodes of some architectures.
+func (v *StatusVerifier) daemonSetArchProblems(ctx context.Context, ds *appsv1.DaemonSet, pools map[string][]string) ([]string, error) {
+	archs := make([]string, 0, len(pools))
+	for arch := range pools {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+
+	var problems []string
+	var scheduled []string
+	for _, arch := range archs {
+		if archExcluded(ds, arch) {
+			problems = append(problems, fmt.Sprintf("not scheduled on the %s nodes %s by its node selector or affinity, "+
+				"the pods of the mesh there are left without it", arch, strings.Join(pools[arch], ", ")))
+			continue
+		}
+		scheduled = append(scheduled, arch)
+	}
+
+	if v.checks.imageInspector != nil {
+		for _, image := range daemonSetImages(ds) {
+			platforms, err := v.checks.imageInspector.Platforms(ctx, image)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("failed to inspect the platforms of image %s: %v", image, err))
+				continue
+			}
+			available := sets.New(platforms...)
+			for _, arch := range scheduled {
+				if !available.Contains("linux/" + arch) {
+					problems = append(problems, fmt.Sprintf("image %s is not available for linux/%s (only %s), needed by the %s nodes %s",
+						image, arch, strings.Join(sets.SortedList(available), ", "), arch, strings.Join(pools[arch], ", ")))
+				}
+			}
+		}
+	}
+
+	archOf := map[string]string{}
+	for arch, names := range pools {
+		for _, n := range names {
+			archOf[n] = arch
+		}
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := v.client.Kube().CoreV1().Pods(ds.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of daemonset %s: %v", ds.Name, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if reason := archFailure(pod); reason != "" {
+			problems = append(problems, fmt.Sprintf("pod %s on the %s node %s: %s", pod.Name, archOf[pod.Spec.NodeName], pod.Spec.NodeName, reason))
+		}
+	}
+	return problems, nil
+}
+
+// archExcluded returns whether the node selector or the required node affinity of the DaemonSet keeps it off the
+// nodes of the architecture, whatever their other labels.
+func archExcluded(ds *appsv1.DaemonSet, arch string) bool {
+	spec := &ds.Spec.Template.Spec
+	if want, f := spec.NodeSelector[corev1.LabelArchStable]; f && want != arch {
+		return true
+	}
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return false
+	}
+	labels := klabels.Set{corev1.LabelArchStable: arch}
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		var archRequirements []corev1.NodeSelectorRequirement
+		for _, req := range term.MatchExpressions {
+			if req.Key == corev1.LabelArchStable {
+				archRequirements = append(archRequirements, req)
+			}
+		}
+		if requirementsMatch(archRequirements, labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// daemonSetImages returns the images of the containers and init containers of the DaemonSet.
+func daemonSetImages(ds *appsv1.DaemonSet) []string {
+	images := sets.New[string]()
+	for _, containers := range [][]corev1.Container{ds.Spec.Template.Spec.InitContainers, ds.Spec.Template.Spec.Containers} {
+		for _, c := range containers {
+			images.Insert(c.Image)
+		}
+	}
+	return sets.SortedList(images)
+}
+
+// archFailure returns why a container of the pod fails on the architecture of its node: its image has no variant
+// for the architecture, or its binary is built for another one. It returns an empty string otherwise.
+func archFailure(pod *corev1.Pod) string {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, s := range statuses {
+			if w := s.State.Waiting; w != nil && (w.Reason == "ErrImagePull" || w.Reason == "ImagePullBackOff") {
+				msg := strings.ToLower(w.Message)
+				if strings.Contains(msg, "no match for platform") || strings.Contains(msg, "no matching manifest") {
+					return fmt.Sprintf("image %s of container %s has no variant for the architecture of the node", s.Image, s.Name)
+				}
+			}
+			for _, t := range []*corev1.ContainerStateTerminated{s.State.Terminated, s.LastTerminationState.Terminated} {
+				if t != nil && strings.Contains(strings.ToLower(t.Message), "exec format error") {
+					return fmt.Sprintf("container %s fails with an exec format error, image %s is built for another architecture", s.Name, s.Image)
+				}
+			}
+		}
+	}
+	return ""
+}
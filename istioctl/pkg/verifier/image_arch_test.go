@@ -0,0 +1,212 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+type fakeImageInspector map[string][]string
+
+func (f fakeImageInspector) Platforms(_ context.Context, image string) ([]string, error) {
+	platforms, ok := f[image]
+	if !ok {
+		return nil, fmt.Errorf("MANIFEST_UNKNOWN")
+	}
+	return platforms, nil
+}
+
+func archNode(name, arch string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelArchStable: arch}},
+		Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{Architecture: arch}},
+	}
+}
+
+func nodeDaemonSet(name, image string, nodeSelector map[string]string) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": name}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeSelector: nodeSelector,
+					Containers:   []corev1.Container{{Name: name, Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func nodeDaemonSetPod(ds, node string, status corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: ds + "-" + node, Namespace: "istio-system", Labels: map[string]string{"k8s-app": ds}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{status}},
+	}
+}
+
+func TestVerifyImageArchitectures(t *testing.T) {
+	nodes := []runtime.Object{archNode("node-a", "amd64"), archNode("node-b", "arm64")}
+	cases := []struct {
+		name      string
+		objects   []runtime.Object
+		inspector ImageInspector
+		want      []string
+		warnings  int
+	}{
+		{
+			name: "multi-arch images",
+			objects: []runtime.Object{
+				nodeDaemonSet(cniDaemonSetName, "istio/install-cni:1.20", nil),
+				nodeDaemonSet(ztunnelDaemonSetName, "istio/ztunnel:1.20", nil),
+			},
+			inspector: fakeImageInspector{
+				"istio/install-cni:1.20": {"linux/amd64", "linux/arm64"},
+				"istio/ztunnel:1.20":     {"linux/amd64", "linux/arm64"},
+			},
+			want: []string{
+				"✔ DaemonSet: istio-cni-node.istio-system checked successfully",
+				"✔ DaemonSet: ztunnel.istio-system checked successfully",
+			},
+		},
+		{
+			name:    "pinned to amd64 nodes",
+			objects: []runtime.Object{nodeDaemonSet(cniDaemonSetName, "istio/install-cni:1.20", map[string]string{corev1.LabelArchStable: "amd64"})},
+			want: []string{
+				"! DaemonSet: istio-cni-node.istio-system: not scheduled on the arm64 nodes node-b by its node selector or affinity",
+			},
+			warnings: 1,
+		},
+		{
+			name:      "amd64-only custom image",
+			objects:   []runtime.Object{nodeDaemonSet(ztunnelDaemonSetName, "registry.example.com/ztunnel:custom", nil)},
+			inspector: fakeImageInspector{"registry.example.com/ztunnel:custom": {"linux/amd64"}},
+			want: []string{
+				"image registry.example.com/ztunnel:custom is not available for linux/arm64 (only linux/amd64), needed by the arm64 nodes node-b",
+			},
+			warnings: 1,
+		},
+		{
+			name: "pods failing on arm64",
+			objects: []runtime.Object{
+				nodeDaemonSet(ztunnelDaemonSetName, "registry.example.com/ztunnel:custom", nil),
+				nodeDaemonSetPod(ztunnelDaemonSetName, "node-a", corev1.ContainerStatus{Name: "istio-proxy", Ready: true}),
+				nodeDaemonSetPod(ztunnelDaemonSetName, "node-b", corev1.ContainerStatus{
+					Name:  "istio-proxy",
+					Image: "registry.example.com/ztunnel:custom",
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason:  "ErrImagePull",
+						Message: "no match for platform in manifest: not found",
+					}},
+				}),
+			},
+			want: []string{
+				"pod ztunnel-node-b on the arm64 node node-b: image registry.example.com/ztunnel:custom of container istio-proxy has no variant",
+			},
+			warnings: 1,
+		},
+		{
+			name: "binary of another architecture",
+			objects: []runtime.Object{
+				nodeDaemonSet(cniDaemonSetName, "istio/install-cni:1.20", nil),
+				nodeDaemonSetPod(cniDaemonSetName, "node-b", corev1.ContainerStatus{
+					Name:                 "install-cni",
+					Image:                "istio/install-cni:1.20",
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: "exec /usr/local/bin/install-cni: exec format error"}},
+				}),
+			},
+			want:     []string{"container install-cni fails with an exec format error"},
+			warnings: 1,
+		},
+		{
+			name: "registry unreachable",
+			objects: []runtime.Object{
+				nodeDaemonSet(cniDaemonSetName, "istio/install-cni:1.20", nil),
+			},
+			inspector: fakeImageInspector{},
+			want:      []string{"failed to inspect the platforms of image istio/install-cni:1.20: MANIFEST_UNKNOWN"},
+			warnings:  1,
+		},
+		{
+			name: "no node data plane",
+			want: []string{"Neither the CNI node agent nor ztunnel is installed"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         kube.NewFakeClient(append(append([]runtime.Object{}, nodes...), c.objects...)...),
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+				warningMarker:  "!",
+				checks:         checkSettings{imageInspector: c.inspector},
+			}
+			assert.NoError(t, v.verifyImageArchitectures(context.Background()))
+			for _, want := range c.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			warnings := 0
+			for _, r := range v.results {
+				if r.status == checkWarning {
+					warnings++
+					assert.Equal(t, r.code, CodeImageArchitecture)
+				}
+			}
+			assert.Equal(t, warnings, c.warnings)
+		})
+	}
+}
+
+func TestArchExcluded(t *testing.T) {
+	ds := nodeDaemonSet(cniDaemonSetName, "istio/install-cni:1.20", nil)
+	assert.Equal(t, archExcluded(ds, "arm64"), false)
+
+	ds.Spec.Template.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: corev1.LabelArchStable, Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}},
+				{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"mesh"}},
+			}},
+		}},
+	}}
+	assert.Equal(t, archExcluded(ds, "amd64"), false)
+	assert.Equal(t, archExcluded(ds, "arm64"), true)
+
+	// A term without an architecture requirement lets the DaemonSet on nodes of any architecture.
+	terms := &ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	*terms = append(*terms, corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"edge"}},
+	}})
+	assert.Equal(t, archExcluded(ds, "arm64"), false)
+}
@@ -0,0 +1,191 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/go-multierror"
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/api/label"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const istioTagLabel = "istio.io/tag"
+
+// injectionDecision describes which injection webhook configurations match a set of namespace labels.
+type injectionDecision struct {
+	// labels is a human readable form of the namespace labels the decision was made for.
+	labels string
+	// webhooks are the names of the matching MutatingWebhookConfigurations.
+	webhooks []string
+	// wantsInjection is true if the labels explicitly request injection.
+	wantsInjection bool
+}
+
+func (d injectionDecision) problem() string {
+	switch {
+	case len(d.webhooks) > 1:
+		return "double injection"
+	case len(d.webhooks) == 0 && d.wantsInjection:
+		return "no injection"
+	}
+	return ""
+}
+
+// verifyInjectionWebhooks checks the injection webhooks of all revisions and tags for overlapping
+// namespace selectors. Which webhook handles each namespace label is printed, with the namespaces carrying
+// it, but only the namespaces of the cluster which get no or double injection fail the check: an overlap on
+// labels no namespace has is harmless until a namespace is labeled so.
+func (v *StatusVerifier) verifyInjectionWebhooks(ctx context.Context) error {
+	hooks, err := v.client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list mutating webhook configurations: %v", err)
+	}
+	istioHooks := make([]admitv1.MutatingWebhookConfiguration, 0, len(hooks.Items))
+	for _, hook := range hooks.Items {
+		if _, f := hook.Labels[label.IoIstioRev.Name]; f {
+			istioHooks = append(istioHooks, hook)
+		}
+	}
+	namespaces, err := v.scopedNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %v", err)
+	}
+	labeled := map[string][]string{}
+	for _, ns := range namespaces {
+		key := renderLabels(injectionLabels(ns.Labels))
+		labeled[key] = append(labeled[key], ns.Name)
+	}
+
+	decisions := injectionDecisions(istioHooks, candidateNamespaceLabels(istioHooks))
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 8, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE LABELS\tWEBHOOK\tNAMESPACES\tPROBLEM")
+	for _, d := range decisions {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.labels, renderNames(d.webhooks), renderNames(labeled[d.labels]), d.problem())
+	}
+	_ = w.Flush()
+	v.logf(VerbosityNormal, "%s", strings.TrimSuffix(b.String(), "\n"))
+
+	multiErr := &multierror.Error{}
+	for _, ns := range namespaces {
+		d := decide(istioHooks, ns.Labels)
+		if p := d.problem(); p != "" {
+			err := fmt.Errorf("%s from %s", p, renderNames(d.webhooks))
+			v.reportFailure("Namespace", ns.Name, ns.Name, withCode(CodeInjectionConflict, err))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("namespace %s: %v", ns.Name, err))
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// injectionLabels returns the labels of a namespace which select its injection webhook.
+func injectionLabels(l map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, k := range []string{"istio-injection", label.IoIstioRev.Name} {
+		if v, f := l[k]; f {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// candidateNamespaceLabels returns the namespace label sets relevant to injection: no labels, the
+// legacy istio-injection label and one istio.io/rev label per revision or tag.
+func candidateNamespaceLabels(hooks []admitv1.MutatingWebhookConfiguration) []map[string]string {
+	candidates := []map[string]string{
+		{},
+		{"istio-injection": "enabled"},
+		{"istio-injection": "disabled"},
+	}
+	revs := sets.New[string]()
+	for _, hook := range hooks {
+		revs.Insert(hook.Labels[label.IoIstioRev.Name])
+		if tag := hook.Labels[istioTagLabel]; tag != "" {
+			revs.Insert(tag)
+		}
+	}
+	revs.Delete("")
+	for _, rev := range sets.SortedList(revs) {
+		candidates = append(candidates, map[string]string{label.IoIstioRev.Name: rev})
+	}
+	return candidates
+}
+
+func injectionDecisions(hooks []admitv1.MutatingWebhookConfiguration, candidates []map[string]string) []injectionDecision {
+	decisions := make([]injectionDecision, 0, len(candidates))
+	for _, c := range candidates {
+		decisions = append(decisions, decide(hooks, c))
+	}
+	return decisions
+}
+
+// decide evaluates which webhook configurations would inject a pod without labels created
+// in a namespace with the given labels.
+func decide(hooks []admitv1.MutatingWebhookConfiguration, nsLabels map[string]string) injectionDecision {
+	d := injectionDecision{
+		labels:         renderLabels(nsLabels),
+		wantsInjection: nsLabels["istio-injection"] == "enabled" || nsLabels[label.IoIstioRev.Name] != "",
+	}
+	for _, hook := range hooks {
+		for _, wh := range hook.Webhooks {
+			if selectorMatches(wh.NamespaceSelector, nsLabels) && selectorMatches(wh.ObjectSelector, nil) {
+				d.webhooks = append(d.webhooks, hook.Name)
+				break
+			}
+		}
+	}
+	return d
+}
+
+// selectorMatches evaluates a webhook selector; a nil selector matches everything.
+func selectorMatches(selector *metav1.LabelSelector, l map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(klabels.Set(l))
+}
+
+func renderLabels(l map[string]string) string {
+	if len(l) == 0 {
+		return "<none>"
+	}
+	parts := make([]string, 0, len(l))
+	for k, v := range l {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func renderNames(names []string) string {
+	if len(names) == 0 {
+		return "<none>"
+	}
+	return strings.Join(names, ",")
+}
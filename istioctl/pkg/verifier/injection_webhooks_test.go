@@ -0,0 +1,134 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func injectionWebhook(name, rev, tag string, selector *metav1.LabelSelector) admitv1.MutatingWebhookConfiguration {
+	labels := map[string]string{"istio.io/rev": rev}
+	if tag != "" {
+		labels[istioTagLabel] = tag
+	}
+	return admitv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Webhooks: []admitv1.MutatingWebhook{
+			{
+				Name:              "namespace.sidecar-injector.istio.io",
+				NamespaceSelector: selector,
+			},
+			{
+				Name:              "object.sidecar-injector.istio.io",
+				NamespaceSelector: selector,
+				ObjectSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"sidecar.istio.io/inject": "true"},
+				},
+			},
+		},
+	}
+}
+
+func TestInjectionDecisions(t *testing.T) {
+	legacy := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"istio-injection": "enabled"},
+	}
+	canary := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"istio.io/rev": "canary"},
+	}
+	hooks := []admitv1.MutatingWebhookConfiguration{
+		injectionWebhook("istio-sidecar-injector", "default", "", legacy),
+		injectionWebhook("istio-sidecar-injector-canary", "canary", "", canary),
+		injectionWebhook("istio-revision-tag-default", "canary", "default", legacy),
+	}
+	decisions := injectionDecisions(hooks, candidateNamespaceLabels(hooks))
+	got := map[string][]string{}
+	problems := map[string]string{}
+	for _, d := range decisions {
+		got[d.labels] = d.webhooks
+		problems[d.labels] = d.problem()
+	}
+	assert.Equal(t, got["<none>"], nil)
+	assert.Equal(t, problems["<none>"], "")
+	assert.Equal(t, got["istio-injection=enabled"], []string{"istio-sidecar-injector", "istio-revision-tag-default"})
+	assert.Equal(t, problems["istio-injection=enabled"], "double injection")
+	assert.Equal(t, got["istio.io/rev=canary"], []string{"istio-sidecar-injector-canary"})
+	assert.Equal(t, problems["istio.io/rev=canary"], "")
+	assert.Equal(t, got["istio.io/rev=default"], nil)
+	assert.Equal(t, problems["istio.io/rev=default"], "no injection")
+}
+
+func TestVerifyInjectionWebhooks(t *testing.T) {
+	legacy := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"istio-injection": "enabled"},
+	}
+	canary := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"istio.io/rev": "canary"},
+	}
+	sidecarInjector := injectionWebhook("istio-sidecar-injector", "default", "", legacy)
+	canaryInjector := injectionWebhook("istio-sidecar-injector-canary", "canary", "", canary)
+	defaultTag := injectionWebhook("istio-revision-tag-default", "canary", "default", legacy)
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	cases := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{
+			// The revision and the tag only overlap on istio-injection=enabled, which no namespace has.
+			name:   "overlap on an unused label",
+			labels: map[string]string{"istio.io/rev": "canary"},
+		},
+		{
+			name:    "overlap on a used label",
+			labels:  map[string]string{"istio-injection": "enabled"},
+			wantErr: "namespace apps: double injection from istio-revision-tag-default,istio-sidecar-injector",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client: kube.NewFakeClient(&sidecarInjector, &canaryInjector, &defaultTag,
+					namespace("apps", c.labels), namespace("kube-system", nil)),
+				logger:        clog.NewConsoleLogger(&out, &out, nil),
+				failureMarker: "✘",
+			}
+			err := v.verifyInjectionWebhooks(context.Background())
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			// The overlap is printed in either case.
+			if !strings.Contains(out.String(), "double injection") {
+				t.Fatalf("expected the overlap in the output:\n%s", out.String())
+			}
+		})
+	}
+}
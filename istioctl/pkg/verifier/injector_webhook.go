@@ -0,0 +1,351 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/hashicorp/go-multierror"
+	admissionv1 "k8s.io/api/admission/v1"
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	"istio.io/api/label"
+	"istio.io/istio/pkg/kube"
+)
+
+// DefaultWebhookCallTimeout is how long a webhook is given to answer, as the API server does for the webhooks
+// which do not set their own timeout.
+const DefaultWebhookCallTimeout = 10 * time.Second
+
+// injectorProbePod is the name of the synthetic pod the injector webhooks are asked to inject.
+const injectorProbePod = "istioctl-verify-injection"
+
+// WebhookCaller sends an AdmissionReview to the webhook a client config points to, as the API server does, and
+// returns the AdmissionReview of the response.
+type WebhookCaller interface {
+	Call(ctx context.Context, cc admitv1.WebhookClientConfig, review []byte, timeout time.Duration) ([]byte, error)
+}
+
+// WithWebhookCaller makes the injector-webhook check call the injector webhooks with the given caller, instead of
+// port-forwarding to a pod of their Service.
+func WithWebhookCaller(caller WebhookCaller) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.webhookCaller = caller
+	}
+}
+
+// NewPortForwardWebhookCaller returns a WebhookCaller which calls the webhooks served by a Service through a
+// port-forward to a ready pod of the Service, and the webhooks served at a URL directly. The certificate presented
+// is verified against the caBundle of the client config, for the name of the Service as the API server does.
+func NewPortForwardWebhookCaller(client kube.CLIClient) WebhookCaller {
+	return portForwardCaller{client: client}
+}
+
+type portForwardCaller struct {
+	client kube.CLIClient
+}
+
+func (c portForwardCaller) Call(ctx context.Context, cc admitv1.WebhookClientConfig, review []byte, timeout time.Duration) ([]byte, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(cc.CABundle) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(cc.CABundle) {
+			return nil, fmt.Errorf("the caBundle holds no PEM certificate")
+		}
+	}
+	var address string
+	switch {
+	case cc.Service != nil:
+		if len(cc.CABundle) == 0 {
+			return nil, fmt.Errorf("the caBundle is empty, the API server cannot verify the certificate of the webhook")
+		}
+		svc := cc.Service
+		pod, podPort, err := c.servicePod(ctx, svc)
+		if err != nil {
+			return nil, err
+		}
+		fw, err := c.client.NewPortForwarder(pod.Name, pod.Namespace, "", 0, podPort)
+		if err != nil {
+			return nil, err
+		}
+		if err := fw.Start(); err != nil {
+			return nil, fmt.Errorf("failed to port-forward to pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+		defer fw.Close()
+		path := ""
+		if svc.Path != nil {
+			path = *svc.Path
+		}
+		address = "https://" + fw.Address() + path
+		tlsConfig.ServerName = fmt.Sprintf("%s.%s.svc", svc.Name, svc.Namespace)
+	case cc.URL != nil:
+		address = *cc.URL
+	default:
+		return nil, fmt.Errorf("the client config sets neither a service nor a URL")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(review))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Do(req)
+	if err != nil {
+		var unknownAuthority x509.UnknownAuthorityError
+		var hostname x509.HostnameError
+		if errors.As(err, &unknownAuthority) || errors.As(err, &hostname) {
+			return nil, fmt.Errorf("the certificate of the webhook does not match its caBundle: %v", err)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the webhook answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// servicePod returns a ready pod the Service of the webhook sends its port to, and the port of the pod.
+func (c portForwardCaller) servicePod(ctx context.Context, ref *admitv1.ServiceReference) (*corev1.Pod, int, error) {
+	svc, err := c.client.Kube().CoreV1().Services(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get the service of the webhook: %v", err)
+	}
+	port := int32(443)
+	if ref.Port != nil {
+		port = *ref.Port
+	}
+	var target *intstr.IntOrString
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == port {
+			target = &svc.Spec.Ports[i].TargetPort
+			break
+		}
+	}
+	if target == nil {
+		return nil, 0, fmt.Errorf("service %s/%s has no port %d", svc.Namespace, svc.Name, port)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, 0, fmt.Errorf("service %s/%s has no selector", svc.Namespace, svc.Name)
+	}
+	pods, err := c.client.Kube().CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list the pods of service %s/%s: %v", svc.Namespace, svc.Name, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !isPodReady(pod) {
+			continue
+		}
+		podPort, err := targetPort(pod, *target, port)
+		if err != nil {
+			return nil, 0, fmt.Errorf("service %s/%s: %v", svc.Namespace, svc.Name, err)
+		}
+		return pod, podPort, nil
+	}
+	return nil, 0, fmt.Errorf("service %s/%s has no ready pod", svc.Namespace, svc.Name)
+}
+
+// targetPort resolves the target port of a Service port on the pod, named after a container port or defaulting
+// to the port of the Service.
+func targetPort(pod *corev1.Pod, target intstr.IntOrString, port int32) (int, error) {
+	if target.Type == intstr.Int {
+		if target.IntVal == 0 {
+			return int(port), nil
+		}
+		return int(target.IntVal), nil
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == target.StrVal {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %s/%s has no port named %s", pod.Namespace, pod.Name, target.StrVal)
+}
+
+// verifyInjectorWebhook checks the sidecar injector webhooks of every revision and tag are reachable as the API
+// server calls them: a dry-run AdmissionReview of a synthetic pod is sent to every webhook, which must allow it
+// with a JSON patch adding the istio-proxy container. This catches the caBundle, Service and port mismatches
+// which only show once pods are created.
+func (v *StatusVerifier) verifyInjectorWebhook(ctx context.Context) error {
+	hooks, err := v.client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list mutating webhook configurations: %v", err)
+	}
+	caller := v.checks.webhookCaller
+	if caller == nil {
+		caller = NewPortForwardWebhookCaller(v.client)
+	}
+	multiErr := &multierror.Error{}
+	checked := 0
+	// The webhooks of a configuration usually share their client config, which is only called once.
+	called := map[string]error{}
+	for i := range hooks.Items {
+		hook := &hooks.Items[i]
+		if _, f := hook.Labels[label.IoIstioRev.Name]; !f {
+			continue
+		}
+		checked++
+		var hookErr error
+		for _, wh := range hook.Webhooks {
+			key, err := json.Marshal(wh.ClientConfig)
+			if err != nil {
+				return err
+			}
+			callErr, f := called[string(key)]
+			if !f {
+				timeout := DefaultWebhookCallTimeout
+				if wh.TimeoutSeconds != nil {
+					timeout = time.Duration(*wh.TimeoutSeconds) * time.Second
+				}
+				callErr = callInjector(ctx, caller, wh.ClientConfig, timeout)
+				called[string(key)] = callErr
+			}
+			if callErr != nil {
+				hookErr = fmt.Errorf("webhook %s: %v", wh.Name, callErr)
+				break
+			}
+		}
+		if hookErr != nil {
+			v.reportFailure("MutatingWebhookConfiguration", hook.Name, "", withCode(CodeInjectorUnreachable, hookErr))
+			multiErr = multierror.Append(multiErr, fmt.Errorf("mutating webhook configuration %s: %v", hook.Name, hookErr))
+			continue
+		}
+		v.reportSuccess("MutatingWebhookConfiguration", hook.Name, "")
+	}
+	if checked == 0 {
+		v.logf(VerbosityNormal, "No sidecar injector webhooks found")
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// callInjector sends the dry-run AdmissionReview of the synthetic pod to the injector webhook, and checks its
+// response injects the sidecar.
+func callInjector(ctx context.Context, caller WebhookCaller, cc admitv1.WebhookClientConfig, timeout time.Duration) error {
+	pod := injectorProbe()
+	podJSON, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	dryRun := true
+	uid := uuid.NewUUID()
+	review, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       uid,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: podJSON},
+			DryRun:    &dryRun,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	body, err := caller.Call(ctx, cc, review, timeout)
+	if err != nil {
+		return err
+	}
+	var resp admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("the webhook did not answer an AdmissionReview: %v", err)
+	}
+	return verifyInjectionResponse(resp.Response, uid, podJSON)
+}
+
+// verifyInjectionResponse checks the response of the injector allows the pod with a JSON patch adding the
+// istio-proxy container.
+func verifyInjectionResponse(resp *admissionv1.AdmissionResponse, uid types.UID, podJSON []byte) error {
+	switch {
+	case resp == nil:
+		return fmt.Errorf("the webhook answered an AdmissionReview without a response")
+	case resp.UID != uid:
+		return fmt.Errorf("the webhook answered the review %q instead of %q", resp.UID, uid)
+	case !resp.Allowed:
+		msg := ""
+		if resp.Result != nil {
+			msg = ": " + resp.Result.Message
+		}
+		return fmt.Errorf("the webhook denied the pod%s", msg)
+	case len(resp.Patch) == 0:
+		return fmt.Errorf("the webhook allowed the pod without injecting it")
+	case resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch:
+		return fmt.Errorf("the webhook answered a patch which is not a JSON patch")
+	}
+	patch, err := jsonpatch.DecodePatch(resp.Patch)
+	if err != nil {
+		return fmt.Errorf("the webhook answered an invalid JSON patch: %v", err)
+	}
+	patched, err := patch.Apply(podJSON)
+	if err != nil {
+		return fmt.Errorf("the patch of the webhook does not apply to the pod: %v", err)
+	}
+	var pod corev1.Pod
+	if err := json.Unmarshal(patched, &pod); err != nil {
+		return fmt.Errorf("the patch of the webhook does not result in a pod: %v", err)
+	}
+	if !hasSidecar(&pod) {
+		return fmt.Errorf("the patch of the webhook does not add the istio-proxy container")
+	}
+	return nil
+}
+
+// injectorProbe returns the synthetic pod the injector webhooks are asked to inject. The injection is requested by
+// label, for the webhooks whose selectors only match the revision label or tag of their namespaces.
+func injectorProbe() *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      injectorProbePod,
+			Namespace: metav1.NamespaceDefault,
+			Labels:    map[string]string{"app": injectorProbePod, label.SidecarInject.Name: "true"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "registry.k8s.io/pause:3.9"}},
+		},
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"istio.io/api/label"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const sidecarPatch = `[{"op":"add","path":"/spec/containers/-","value":{"name":"istio-proxy","image":"proxyv2"}}]`
+
+// fakeInjector answers the AdmissionReviews of the injector webhooks by path.
+type fakeInjector struct {
+	calls int
+	// patches are the patches answered by path, the request failing for the paths without one.
+	patches map[string]string
+	// denied are the paths denying the pod.
+	denied map[string]bool
+}
+
+func (f *fakeInjector) Call(_ context.Context, cc admitv1.WebhookClientConfig, review []byte, _ time.Duration) ([]byte, error) {
+	f.calls++
+	var req admissionv1.AdmissionReview
+	if err := json.Unmarshal(review, &req); err != nil {
+		return nil, err
+	}
+	if req.Request == nil || req.Request.DryRun == nil || !*req.Request.DryRun {
+		return nil, fmt.Errorf("not a dry-run review")
+	}
+	path := *cc.Service.Path
+	resp := &admissionv1.AdmissionResponse{UID: req.Request.UID, Allowed: !f.denied[path]}
+	if !resp.Allowed {
+		resp.Result = &metav1.Status{Message: "injection template not found"}
+	} else if patch, ok := f.patches[path]; ok {
+		patchType := admissionv1.PatchTypeJSONPatch
+		resp.Patch, resp.PatchType = []byte(patch), &patchType
+	} else {
+		return nil, fmt.Errorf("dial tcp 10.96.0.10:443: connection refused")
+	}
+	return json.Marshal(&admissionv1.AdmissionReview{TypeMeta: req.TypeMeta, Response: resp})
+}
+
+func injectorHook(name, rev string, paths ...string) *admitv1.MutatingWebhookConfiguration {
+	hook := &admitv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if rev != "" {
+		hook.Labels = map[string]string{label.IoIstioRev.Name: rev}
+	}
+	for i, path := range paths {
+		path := path
+		hook.Webhooks = append(hook.Webhooks, admitv1.MutatingWebhook{
+			Name: fmt.Sprintf("%d.sidecar-injector.istio.io", i),
+			ClientConfig: admitv1.WebhookClientConfig{
+				Service:  &admitv1.ServiceReference{Name: "istiod", Namespace: "istio-system", Path: &path},
+				CABundle: []byte("ca"),
+			},
+		})
+	}
+	return hook
+}
+
+func TestVerifyInjectorWebhook(t *testing.T) {
+	cases := []struct {
+		name      string
+		objects   []runtime.Object
+		injector  *fakeInjector
+		wantErr   string
+		wantCalls int
+		wantOut   []string
+	}{
+		{
+			name: "injecting webhooks sharing a client config",
+			objects: []runtime.Object{
+				injectorHook("istio-sidecar-injector", "default", "/inject", "/inject"),
+				injectorHook("other-webhook", "", "/other"),
+			},
+			injector:  &fakeInjector{patches: map[string]string{"/inject": sidecarPatch}},
+			wantCalls: 1,
+			wantOut:   []string{"✔ MutatingWebhookConfiguration: istio-sidecar-injector. checked successfully"},
+		},
+		{
+			name:      "unreachable webhook",
+			objects:   []runtime.Object{injectorHook("istio-sidecar-injector-canary", "canary", "/inject")},
+			injector:  &fakeInjector{},
+			wantErr:   "webhook 0.sidecar-injector.istio.io: dial tcp 10.96.0.10:443: connection refused",
+			wantCalls: 1,
+			wantOut:   []string{"✘ MutatingWebhookConfiguration: istio-sidecar-injector-canary"},
+		},
+		{
+			name:      "denied pod",
+			objects:   []runtime.Object{injectorHook("istio-sidecar-injector", "default", "/inject")},
+			injector:  &fakeInjector{denied: map[string]bool{"/inject": true}},
+			wantErr:   "the webhook denied the pod: injection template not found",
+			wantCalls: 1,
+		},
+		{
+			name:      "patch without the sidecar",
+			objects:   []runtime.Object{injectorHook("istio-sidecar-injector", "default", "/inject")},
+			injector:  &fakeInjector{patches: map[string]string{"/inject": `[{"op":"add","path":"/metadata/labels/a","value":"b"}]`}},
+			wantErr:   "the patch of the webhook does not add the istio-proxy container",
+			wantCalls: 1,
+		},
+		{
+			name:      "invalid patch",
+			objects:   []runtime.Object{injectorHook("istio-sidecar-injector", "default", "/inject")},
+			injector:  &fakeInjector{patches: map[string]string{"/inject": `{"op":"add"}`}},
+			wantErr:   "the webhook answered an invalid JSON patch",
+			wantCalls: 1,
+		},
+		{
+			name:     "no injector webhooks",
+			injector: &fakeInjector{},
+			wantOut:  []string{"No sidecar injector webhooks found"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:        kube.NewFakeClient(c.objects...),
+				logger:        clog.NewConsoleLogger(&out, &out, nil),
+				successMarker: "✔",
+				failureMarker: "✘",
+				checks:        checkSettings{webhookCaller: c.injector},
+			}
+			err := v.verifyInjectorWebhook(context.Background())
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			assert.Equal(t, c.injector.calls, c.wantCalls)
+			for _, r := range v.results {
+				if r.status == checkFailed {
+					assert.Equal(t, r.code, CodeInjectorUnreachable)
+				}
+			}
+			for _, want := range c.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("missing %q in output:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPortForwardWebhookCallerURL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"response":{"allowed":true}}`))
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caller := NewPortForwardWebhookCaller(kube.NewFakeClient())
+
+	url := server.URL + "/inject"
+	body, err := caller.Call(context.Background(), admitv1.WebhookClientConfig{URL: &url, CABundle: caBundle}, []byte("{}"), time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, string(body), `{"response":{"allowed":true}}`)
+
+	otherBundle := selfSignedCertificate(t)
+	_, err = caller.Call(context.Background(), admitv1.WebhookClientConfig{URL: &url, CABundle: otherBundle}, []byte("{}"), time.Second)
+	if err == nil || !strings.Contains(err.Error(), "does not match its caBundle") {
+		t.Fatalf("expected a caBundle mismatch, got %v", err)
+	}
+}
+
+// selfSignedCertificate returns the PEM of a self-signed certificate, of another CA than the test servers.
+func selfSignedCertificate(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestWebhookServicePod(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"istio": "istiod"},
+			Ports:    []corev1.ServicePort{{Name: "https-webhook", Port: 443, TargetPort: intstr.FromString("webhook")}},
+		},
+	}
+	pod := readyPod("istiod-abc", "istiod")
+	pod.Spec.Containers = []corev1.Container{{Name: "discovery", Ports: []corev1.ContainerPort{{Name: "webhook", ContainerPort: 15017}}}}
+	caller := portForwardCaller{client: kube.NewFakeClient(svc, pod)}
+
+	got, port, err := caller.servicePod(context.Background(), &admitv1.ServiceReference{Name: "istiod", Namespace: "istio-system"})
+	assert.NoError(t, err)
+	assert.Equal(t, got.Name, "istiod-abc")
+	assert.Equal(t, port, 15017)
+
+	wrongPort := int32(15017)
+	_, _, err = caller.servicePod(context.Background(), &admitv1.ServiceReference{Name: "istiod", Namespace: "istio-system", Port: &wrongPort})
+	if err == nil || !strings.Contains(err.Error(), "has no port 15017") {
+		t.Fatalf("expected a port mismatch, got %v", err)
+	}
+
+	_, _, err = caller.servicePod(context.Background(), &admitv1.ServiceReference{Name: "istiod-canary", Namespace: "istio-system"})
+	if err == nil || !strings.Contains(err.Error(), "failed to get the service of the webhook") {
+		t.Fatalf("expected a missing service, got %v", err)
+	}
+}
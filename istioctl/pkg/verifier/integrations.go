@@ -0,0 +1,269 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	clientextensions "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+// integrationEndpoint is an endpoint of an integration referenced by the mesh config, such as a tracing
+// collector or an access log service, or by a WasmPlugin fetching its module over HTTP.
+type integrationEndpoint struct {
+	// kind, name and namespace identify the reference, namespace being empty in the mesh config.
+	kind      string
+	name      string
+	namespace string
+	host      string
+	port      uint32
+	// hostNamespace, if set, is the namespace of the Service or ServiceEntry the host must be defined by.
+	hostNamespace string
+}
+
+// serviceProvider is implemented by the extension providers sending to a service, which is all of them but
+// those writing locally such as the file access log or Prometheus.
+type serviceProvider interface {
+	GetService() string
+	GetPort() uint32
+}
+
+// verifyIntegrations checks that the endpoints of the integrations referenced by the mesh config, that is
+// its extension providers and the tracers, access log and metrics services of the default proxy config, and
+// the HTTP servers WasmPlugins fetch their modules from, are Services of the cluster exposing the configured
+// port with ready endpoints, or hosts of ServiceEntries exposing it. Otherwise telemetry sent to them, requests
+// authorized by them or the Wasm modules served by them are silently lost right after install.
+func (v *StatusVerifier) verifyIntegrations(ctx context.Context) error {
+	mc, err := v.meshConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read mesh config: %v", err)
+	}
+	endpoints, err := integrationEndpoints(mc)
+	if err != nil {
+		return err
+	}
+	plugins, err := v.client.Istio().ExtensionsV1alpha1().WasmPlugins(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list wasm plugins: %v", err)
+	}
+	endpoints = append(endpoints, wasmEndpoints(plugins.Items)...)
+	if len(endpoints) == 0 {
+		v.logf(VerbosityNormal, "No integrations referenced by the mesh config, skipping integration checks")
+		return nil
+	}
+	services, err := v.client.Kube().CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list services: %v", err)
+	}
+	serviceEntries, err := v.client.Istio().NetworkingV1alpha3().ServiceEntries(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list service entries: %v", err)
+	}
+
+	multiErr := &multierror.Error{}
+	for _, ep := range endpoints {
+		if err := v.checkIntegrationEndpoint(ctx, ep, services.Items, serviceEntries.Items); err != nil {
+			v.reportFailure(ep.kind, ep.name, ep.namespace, err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("%s %s: %v", strings.ToLower(ep.kind), ep.name, err))
+			continue
+		}
+		v.reportSuccess(ep.kind, ep.name, ep.namespace)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// checkIntegrationEndpoint checks that the endpoint is a Service of the cluster or the host of a ServiceEntry,
+// exposing its port.
+func (v *StatusVerifier) checkIntegrationEndpoint(ctx context.Context, ep integrationEndpoint, services []corev1.Service,
+	serviceEntries []*clientnetworking.ServiceEntry,
+) error {
+	if ep.port == 0 {
+		return fmt.Errorf("no port is configured for %s", ep.host)
+	}
+	svcName, svcNamespace, ok := clusterServiceName(ep.host)
+	if svc := findService(services, svcName, svcNamespace); ok && svc != nil && (ep.hostNamespace == "" || ep.hostNamespace == svcNamespace) {
+		return v.checkIntegrationService(ctx, svc, ep.port)
+	}
+	se := serviceEntryHost(serviceEntries, ep.host, ep.hostNamespace)
+	if se == nil {
+		if ep.hostNamespace != "" {
+			return fmt.Errorf("%s does not resolve to a Service or ServiceEntry of namespace %s", ep.host, ep.hostNamespace)
+		}
+		return fmt.Errorf("%s does not resolve to a Service or ServiceEntry of the cluster", ep.host)
+	}
+	if ports := se.Spec.GetPorts(); len(ports) > 0 {
+		for _, p := range ports {
+			if p.GetNumber() == ep.port {
+				return nil
+			}
+		}
+		return fmt.Errorf("service entry %s/%s of %s does not expose port %d", se.Namespace, se.Name, ep.host, ep.port)
+	}
+	return nil
+}
+
+// serviceEntryHost returns the ServiceEntry defining the host, restricted to a namespace if set.
+func serviceEntryHost(serviceEntries []*clientnetworking.ServiceEntry, h, namespace string) *clientnetworking.ServiceEntry {
+	for _, se := range serviceEntries {
+		if namespace != "" && se.Namespace != namespace {
+			continue
+		}
+		for _, seHost := range se.Spec.GetHosts() {
+			if host.Name(seHost).Matches(host.Name(h)) {
+				return se
+			}
+		}
+	}
+	return nil
+}
+
+// checkIntegrationService checks that the Service exposes the port and has ready endpoints.
+func (v *StatusVerifier) checkIntegrationService(ctx context.Context, svc *corev1.Service, port uint32) error {
+	if !hasServicePort(svc, port) {
+		exposed := make([]int32, 0, len(svc.Spec.Ports))
+		for _, p := range svc.Spec.Ports {
+			exposed = append(exposed, p.Port)
+		}
+		return fmt.Errorf("service %s/%s does not expose port %d, only %v", svc.Namespace, svc.Name, port, exposed)
+	}
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		return nil
+	}
+	slices, err := v.client.Kube().DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + svc.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints of service %s/%s: %v", svc.Namespace, svc.Name, err)
+	}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("service %s/%s has no ready endpoints", svc.Namespace, svc.Name)
+}
+
+// integrationEndpoints returns the endpoints of the integrations referenced by the mesh config.
+func integrationEndpoints(mc *meshconfig.MeshConfig) ([]integrationEndpoint, error) {
+	var endpoints []integrationEndpoint
+	for _, p := range mc.GetExtensionProviders() {
+		sp, ok := providerService(p)
+		if !ok || sp.GetService() == "" {
+			continue
+		}
+		ep := integrationEndpoint{kind: "Extension provider", name: p.GetName(), host: sp.GetService(), port: sp.GetPort()}
+		// The service of extension providers may be qualified with the namespace of the ServiceEntry defining it.
+		if namespace, hostname, ok := strings.Cut(ep.host, "/"); ok {
+			ep.hostNamespace, ep.host = namespace, hostname
+		}
+		endpoints = append(endpoints, ep)
+	}
+
+	pc := mc.GetDefaultConfig()
+	// The built-in default tracer is not an integration referenced by the install, it is only used when deployed.
+	defaultTracer := mesh.DefaultProxyConfig().GetTracing().GetZipkin().GetAddress()
+	addresses := []struct {
+		name    string
+		address string
+	}{
+		{"zipkin", pc.GetTracing().GetZipkin().GetAddress()},
+		{"lightstep", pc.GetTracing().GetLightstep().GetAddress()},
+		{"datadog", pc.GetTracing().GetDatadog().GetAddress()},
+		{"envoyAccessLogService", pc.GetEnvoyAccessLogService().GetAddress()},
+		{"envoyMetricsService", pc.GetEnvoyMetricsService().GetAddress()},
+	}
+	for _, a := range addresses {
+		if a.address == "" || a.address == defaultTracer {
+			continue
+		}
+		h, p, err := net.SplitHostPort(a.address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q of %s in the default proxy config: %v", a.address, a.name, err)
+		}
+		port, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port of address %q of %s in the default proxy config: %v", a.address, a.name, err)
+		}
+		endpoints = append(endpoints, integrationEndpoint{kind: "Proxy integration", name: a.name, host: h, port: uint32(port)})
+	}
+	return endpoints, nil
+}
+
+// wasmEndpoints returns the HTTP servers the WasmPlugins fetch their modules from. Modules of OCI registries and
+// local files are not served by the cluster.
+func wasmEndpoints(plugins []*clientextensions.WasmPlugin) []integrationEndpoint {
+	var endpoints []integrationEndpoint
+	for _, wp := range plugins {
+		u, err := url.Parse(wp.Spec.GetUrl())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		port := uint32(80)
+		if u.Scheme == "https" {
+			port = 443
+		}
+		if p := u.Port(); p != "" {
+			parsed, err := strconv.ParseUint(p, 10, 32)
+			if err != nil {
+				continue
+			}
+			port = uint32(parsed)
+		}
+		endpoints = append(endpoints, integrationEndpoint{kind: "WasmPlugin", name: wp.Name, namespace: wp.Namespace, host: u.Hostname(), port: port})
+	}
+	return endpoints
+}
+
+// providerService returns the provider set in the oneof of the extension provider, if it sends to a service.
+func providerService(p *meshconfig.MeshConfig_ExtensionProvider) (serviceProvider, bool) {
+	m := p.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("provider"))
+	if fd == nil || fd.Message() == nil {
+		return nil, false
+	}
+	sp, ok := m.Get(fd).Message().Interface().(serviceProvider)
+	return sp, ok
+}
+
+// clusterServiceName returns the name and namespace of the Kubernetes Service a host refers to, for hosts of
+// the form name.namespace, name.namespace.svc or name.namespace.svc.<cluster domain>.
+func clusterServiceName(h string) (string, string, bool) {
+	h = strings.TrimSuffix(h, ".")
+	if i := strings.Index(h, ".svc."); i >= 0 {
+		h = h[:i]
+	}
+	h = strings.TrimSuffix(h, ".svc")
+	parts := strings.Split(h, ".")
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
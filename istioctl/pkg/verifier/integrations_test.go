@@ -0,0 +1,232 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	extensions "istio.io/api/extensions/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+	clientextensions "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const integrationsMeshConfig = `
+defaultConfig:
+  tracing:
+    zipkin:
+      address: zipkin.istio-system:9411
+  envoyAccessLogService:
+    address: als.telemetry.svc.cluster.local:9000
+extensionProviders:
+- name: otel
+  opentelemetry:
+    service: otel-collector.observability.svc.cluster.local
+    port: 4317
+- name: authz
+  envoyExtAuthzGrpc:
+    service: ext-authz/authz.example.com
+    port: 9000
+- name: dead
+  zipkin:
+    service: jaeger.tracing.svc.cluster.local
+    port: 9411
+- name: log
+  envoyFileAccessLog:
+    path: /dev/stdout
+`
+
+func integrationService(name, namespace string, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: port}}},
+	}
+}
+
+func integrationEndpointSlice(service, namespace string, ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service + "-abcde",
+			Namespace: namespace,
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.0.0.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+		}},
+	}
+}
+
+func TestVerifyIntegrations(t *testing.T) {
+	client := kube.NewFakeClient(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": integrationsMeshConfig},
+		},
+		integrationService("otel-collector", "observability", 4317),
+		integrationEndpointSlice("otel-collector", "observability", true),
+		// The access log service does not expose the configured port.
+		integrationService("als", "telemetry", 9001),
+		integrationEndpointSlice("als", "telemetry", true),
+		// The collector of the dead provider has no ready endpoints.
+		integrationService("jaeger", "tracing", 9411),
+		integrationEndpointSlice("jaeger", "tracing", false),
+	)
+	se := &clientnetworking.ServiceEntry{
+		ObjectMeta: metav1.ObjectMeta{Name: "authz", Namespace: "ext-authz"},
+		Spec:       networking.ServiceEntry{Hosts: []string{"authz.example.com"}},
+	}
+	if _, err := client.Istio().NetworkingV1alpha3().ServiceEntries(se.Namespace).Create(context.TODO(), se, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+	}
+	err := v.verifyIntegrations(context.Background())
+	assert.Error(t, err)
+	for _, want := range []string{
+		"✔ Extension provider: otel. checked successfully",
+		"✔ Extension provider: authz. checked successfully",
+		"✘ Extension provider: dead.: service tracing/jaeger has no ready endpoints",
+		"✘ Proxy integration: envoyAccessLogService.: service telemetry/als does not expose port 9000",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+	// The built-in default tracer and providers writing locally are not checked.
+	for _, unwanted := range []string{"zipkin", "log"} {
+		if strings.Contains(out.String(), ": "+unwanted+".") {
+			t.Errorf("unexpected check of %q in output:\n%s", unwanted, out.String())
+		}
+	}
+	if !strings.Contains(err.Error(), "extension provider dead") || !strings.Contains(err.Error(), "proxy integration envoyAccessLogService") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+const serviceEntryMeshConfig = `
+extensionProviders:
+- name: authz
+  envoyExtAuthzHttp:
+    service: ext-authz/authz.example.com
+    port: 8443
+- name: other-namespace
+  envoyExtAuthzGrpc:
+    service: other/authz.example.com
+    port: 9000
+- name: no-port
+  opentelemetry:
+    service: otel-collector.observability.svc.cluster.local
+`
+
+func TestVerifyIntegrationsServiceEntriesAndWasm(t *testing.T) {
+	client := kube.NewFakeClient(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": serviceEntryMeshConfig},
+		},
+		integrationService("otel-collector", "observability", 4317),
+		integrationService("wasm-files", "wasm", 8080),
+		integrationEndpointSlice("wasm-files", "wasm", true),
+	)
+	se := &clientnetworking.ServiceEntry{
+		ObjectMeta: metav1.ObjectMeta{Name: "authz", Namespace: "ext-authz"},
+		Spec: networking.ServiceEntry{
+			Hosts: []string{"authz.example.com"},
+			Ports: []*networking.ServicePort{{Number: 9000, Name: "grpc", Protocol: "GRPC"}},
+		},
+	}
+	if _, err := client.Istio().NetworkingV1alpha3().ServiceEntries(se.Namespace).Create(context.TODO(), se, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, wp := range []*clientextensions.WasmPlugin{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "served", Namespace: "default"},
+			Spec:       extensions.WasmPlugin{Url: "http://wasm-files.wasm.svc.cluster.local:8080/filter.wasm"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "wrong-port", Namespace: "default"},
+			Spec:       extensions.WasmPlugin{Url: "http://wasm-files.wasm:80/filter.wasm"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "oci", Namespace: "default"},
+			Spec:       extensions.WasmPlugin{Url: "oci://ghcr.io/istio-ecosystem/wasm-extensions/basic_auth:1.12.0"},
+		},
+	} {
+		if _, err := client.Istio().ExtensionsV1alpha1().WasmPlugins(wp.Namespace).Create(context.TODO(), wp, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+	}
+	assert.Error(t, v.verifyIntegrations(context.Background()))
+	for _, want := range []string{
+		"✘ Extension provider: authz.: service entry ext-authz/authz of authz.example.com does not expose port 8443",
+		"✘ Extension provider: other-namespace.: authz.example.com does not resolve to a Service or ServiceEntry of namespace other",
+		"✘ Extension provider: no-port.: no port is configured for otel-collector.observability.svc.cluster.local",
+		"✔ WasmPlugin: served.default checked successfully",
+		"✘ WasmPlugin: wrong-port.default: service wasm/wasm-files does not expose port 80, only [8080]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "oci.default") {
+		t.Errorf("unexpected check of a WasmPlugin of an OCI registry in output:\n%s", out.String())
+	}
+}
+
+func TestClusterServiceName(t *testing.T) {
+	cases := []struct {
+		host      string
+		name      string
+		namespace string
+		ok        bool
+	}{
+		{host: "zipkin.istio-system", name: "zipkin", namespace: "istio-system", ok: true},
+		{host: "zipkin.istio-system.svc", name: "zipkin", namespace: "istio-system", ok: true},
+		{host: "zipkin.istio-system.svc.cluster.local", name: "zipkin", namespace: "istio-system", ok: true},
+		{host: "zipkin"},
+		{host: "collector.example.com"},
+	}
+	for _, tc := range cases {
+		name, namespace, ok := clusterServiceName(tc.host)
+		assert.Equal(t, ok, tc.ok)
+		assert.Equal(t, name, tc.name)
+		assert.Equal(t, namespace, tc.namespace)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// istiodContainer is the name of the istiod container in the istiod Deployment.
+const istiodContainer = "discovery"
+
+var (
+	// criticalEnvPrefixes select the istiod environment variables holding feature flags, which are
+	// rendered from values.pilot.env and the other settings of the installation.
+	criticalEnvPrefixes = []string{"PILOT_", "ISTIOD_", "ENABLE_"}
+	// criticalEnvNames are further istiod environment variables changing its behavior.
+	criticalEnvNames = sets.New("CLUSTER_ID", "REVISION", "EXTERNAL_ISTIOD", "SHARED_MESH_CONFIG")
+)
+
+func isCriticalEnv(name string) bool {
+	if criticalEnvNames.Contains(name) {
+		return true
+	}
+	for _, p := range criticalEnvPrefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyIstiodEnv compares the critical environment variables of the istiod container of the
+// deployment with those of the rendered one, to detect manual edits of the Deployment. Other
+// Deployments are not checked.
+func verifyIstiodEnv(rendered *unstructured.Unstructured, deployment *appsv1.Deployment) error {
+	expectedDeployment := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rendered.Object, expectedDeployment); err != nil {
+		return fmt.Errorf("failed to read rendered deployment: %v", err)
+	}
+	expected := findContainer(expectedDeployment.Spec.Template.Spec.Containers, istiodContainer)
+	if expected == nil {
+		return nil
+	}
+	actual := findContainer(deployment.Spec.Template.Spec.Containers, istiodContainer)
+	if actual == nil {
+		return fmt.Errorf("container %q is missing", istiodContainer)
+	}
+
+	want, got := criticalEnv(expected.Env), criticalEnv(actual.Env)
+	var diffs []string
+	for _, name := range sets.SortedList(sets.New(maps.Keys(want)...).Union(sets.New(maps.Keys(got)...))) {
+		w, wf := want[name]
+		g, gf := got[name]
+		switch {
+		case !gf:
+			diffs = append(diffs, fmt.Sprintf("%s is not set, expected %s", name, w))
+		case !wf:
+			diffs = append(diffs, fmt.Sprintf("%s=%s is set but not part of the installation", name, g))
+		case w != g:
+			diffs = append(diffs, fmt.Sprintf("%s is %s, expected %s", name, g, w))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("environment of container %q differs from the installation, it may have been edited manually: %s",
+			istiodContainer, strings.Join(diffs, "; "))
+	}
+	return nil
+}
+
+func findContainer(containers []corev1.Container, name string) *corev1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// criticalEnv returns the critical variables of env with their quoted values. Variables set from a
+// source only record that, as the API server defaults fields of the source.
+func criticalEnv(env []corev1.EnvVar) map[string]string {
+	out := map[string]string{}
+	for _, e := range env {
+		if !isCriticalEnv(e.Name) {
+			continue
+		}
+		if e.ValueFrom != nil {
+			out[e.Name] = "<set from a source>"
+			continue
+		}
+		out[e.Name] = fmt.Sprintf("%q", e.Value)
+	}
+	return out
+}
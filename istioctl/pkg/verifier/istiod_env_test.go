@@ -0,0 +1,109 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func istiodDeployment(container string, env ...corev1.EnvVar) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: container, Env: env}}},
+			},
+		},
+	}
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object) *unstructured.Unstructured {
+	t.Helper()
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	assert.NoError(t, err)
+	return &unstructured.Unstructured{Object: content}
+}
+
+func TestVerifyIstiodEnv(t *testing.T) {
+	rendered := []corev1.EnvVar{
+		{Name: "PILOT_ENABLE_ALPHA_GATEWAY_API", Value: "true"},
+		{Name: "REVISION", Value: "default"},
+		{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+		{Name: "GOMEMLIMIT", Value: "1Gi"},
+	}
+	cases := []struct {
+		name     string
+		rendered *appsv1.Deployment
+		live     []corev1.EnvVar
+		wantErr  []string
+	}{
+		{
+			name:     "unchanged",
+			rendered: istiodDeployment("discovery", rendered...),
+			live: append([]corev1.EnvVar{{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.name"},
+			}}}, rendered[:2]...),
+		},
+		{
+			name:     "non critical variables are ignored",
+			rendered: istiodDeployment("discovery", rendered...),
+			live:     append([]corev1.EnvVar{{Name: "GOMEMLIMIT", Value: "2Gi"}}, rendered[:3]...),
+		},
+		{
+			name:     "not istiod",
+			rendered: istiodDeployment("istio-proxy", rendered...),
+		},
+		{
+			name:     "edited",
+			rendered: istiodDeployment("discovery", rendered...),
+			live: []corev1.EnvVar{
+				{Name: "PILOT_ENABLE_ALPHA_GATEWAY_API", Value: "false"},
+				{Name: "PILOT_TRACE_SAMPLING", Value: "100"},
+				{Name: "POD_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+			},
+			wantErr: []string{
+				`PILOT_ENABLE_ALPHA_GATEWAY_API is "false", expected "true"`,
+				`PILOT_TRACE_SAMPLING="100" is set but not part of the installation`,
+				`REVISION is not set, expected "default"`,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := verifyIstiodEnv(toUnstructured(t, c.rendered), istiodDeployment("discovery", c.live...))
+			if len(c.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range c.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+// istiodPermissionKind is the kind of the results of the istiod-rbac check.
+const istiodPermissionKind = "Istiod permission"
+
+// istiodWatchVerbs are the verbs the informers of istiod need on the resources they watch.
+var istiodWatchVerbs = []string{"list", "watch"}
+
+// istiodWatchedResources are the resources istiod watches in all namespaces, as granted by the ClusterRole of the
+// istio-discovery chart.
+var istiodWatchedResources = []schema.GroupResource{
+	{Resource: "pods"},
+	{Resource: "services"},
+	{Resource: "endpoints"},
+	{Resource: "nodes"},
+	{Resource: "namespaces"},
+	{Resource: "configmaps"},
+	{Resource: "secrets"},
+	{Group: "discovery.k8s.io", Resource: "endpointslices"},
+	{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+	{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations"},
+	{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations"},
+	{Group: "networking.k8s.io", Resource: "ingresses"},
+	{Group: "networking.istio.io", Resource: "destinationrules"},
+	{Group: "networking.istio.io", Resource: "gateways"},
+	{Group: "networking.istio.io", Resource: "serviceentries"},
+	{Group: "networking.istio.io", Resource: "sidecars"},
+	{Group: "networking.istio.io", Resource: "virtualservices"},
+	{Group: "networking.istio.io", Resource: "workloadentries"},
+	{Group: "networking.istio.io", Resource: "workloadgroups"},
+	{Group: "security.istio.io", Resource: "authorizationpolicies"},
+	{Group: "security.istio.io", Resource: "peerauthentications"},
+	{Group: "security.istio.io", Resource: "requestauthentications"},
+	{Group: "telemetry.istio.io", Resource: "telemetries"},
+	{Group: "extensions.istio.io", Resource: "wasmplugins"},
+	{Group: "gateway.networking.k8s.io", Resource: "gateways"},
+	{Group: "gateway.networking.k8s.io", Resource: "httproutes"},
+}
+
+// istiodDeploymentName returns the name of the istiod Deployment of the revision under verification.
+func (v *StatusVerifier) istiodDeploymentName() string {
+	if rev := v.controlPlaneOpts.Revision; rev != "" && rev != "default" {
+		return "istiod-" + rev
+	}
+	return "istiod"
+}
+
+// verifyIstiodRBAC checks with SubjectAccessReviews that the service account of istiod is allowed to list and
+// watch all the resources its informers need in all namespaces, catching clusters whose ClusterRoles were trimmed
+// and where istiod never syncs.
+func (v *StatusVerifier) verifyIstiodRBAC(ctx context.Context) error {
+	name := v.istiodDeploymentName()
+	deployment, err := v.client.Kube().AppsV1().Deployments(v.istioNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get the istiod deployment: %v", err)
+	}
+	sa := deployment.Spec.Template.Spec.ServiceAccountName
+	if sa == "" {
+		sa = "default"
+	}
+	username := serviceaccount.MakeUsername(v.istioNamespace, sa)
+	groups := append(serviceaccount.MakeGroupNames(v.istioNamespace), user.AllAuthenticated)
+
+	denied := 0
+	for _, gr := range istiodWatchedResources {
+		var deniedVerbs []string
+		for _, verb := range istiodWatchVerbs {
+			review, err := v.client.Kube().AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+				Spec: authorizationv1.SubjectAccessReviewSpec{
+					ResourceAttributes: &authorizationv1.ResourceAttributes{
+						Verb:     verb,
+						Group:    gr.Group,
+						Resource: gr.Resource,
+					},
+					User:   username,
+					Groups: groups,
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to review the access of %s to %s: %v", username, gr, err)
+			}
+			if !review.Status.Allowed {
+				deniedVerbs = append(deniedVerbs, verb)
+			}
+		}
+		if len(deniedVerbs) > 0 {
+			denied++
+			v.reportFailure(istiodPermissionKind, gr.String(), "", withCode(CodeIstiodRBAC, fmt.Errorf(
+				"service account %s/%s of istiod is not allowed to %s %s in all namespaces",
+				v.istioNamespace, sa, strings.Join(deniedVerbs, " or "), gr)))
+		}
+	}
+	if denied > 0 {
+		return fmt.Errorf("istiod is not allowed to watch %d of the resources it needs", denied)
+	}
+	v.logf(VerbosityNormal, "istiod is allowed to watch the %d resources it needs", len(istiodWatchedResources))
+	v.reportSuccess(istiodPermissionKind, sa, v.istioNamespace)
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestVerifyIstiodRBAC(t *testing.T) {
+	istiod := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "istiod", Namespace: "istio-system"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			ServiceAccountName: "istiod",
+		}}},
+	}
+	cases := []struct {
+		name string
+		// denied are the verbs and resources denied to istiod, as "<verb> <resource>".
+		denied  []string
+		wantErr bool
+		want    []string
+	}{
+		{
+			name: "allowed",
+			want: []string{"istiod is allowed to watch the 26 resources it needs"},
+		},
+		{
+			name:    "trimmed ClusterRole",
+			denied:  []string{"watch endpointslices", "list secrets", "watch secrets"},
+			wantErr: true,
+			want: []string{
+				"service account istio-system/istiod of istiod is not allowed to watch endpointslices.discovery.k8s.io in all namespaces",
+				"service account istio-system/istiod of istiod is not allowed to list or watch secrets in all namespaces",
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := kube.NewFakeClient(istiod)
+			var reviewed []string
+			client.Kube().(*fake.Clientset).PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+					reviewed = append(reviewed, review.Spec.User)
+					attrs := review.Spec.ResourceAttributes
+					review.Status.Allowed = true
+					for _, d := range tc.denied {
+						if d == attrs.Verb+" "+attrs.Resource {
+							review.Status.Allowed = false
+						}
+					}
+					return true, review, nil
+				})
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         client,
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+			}
+			err := v.verifyIstiodRBAC(context.Background())
+			assert.Equal(t, err != nil, tc.wantErr)
+			assert.Equal(t, reviewed[0], "system:serviceaccount:istio-system:istiod")
+			for _, w := range tc.want {
+				if !strings.Contains(out.String(), w) {
+					t.Fatalf("missing %q in output:\n%s", w, out.String())
+				}
+			}
+			if tc.wantErr {
+				for _, r := range v.Results() {
+					if r.Status != checkPassed.String() {
+						assert.Equal(t, r.Code, string(CodeIstiodRBAC))
+					}
+				}
+			}
+		})
+	}
+}
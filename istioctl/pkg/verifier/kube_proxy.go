@@ -0,0 +1,158 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+)
+
+const (
+	// ciliumKubeProxyReplacementMode is the proxy mode reported when Cilium replaces kube-proxy.
+	ciliumKubeProxyReplacementMode = "cilium-replacement"
+	// unknownProxyMode is the proxy mode reported when neither kube-proxy nor Cilium config was found.
+	unknownProxyMode = "unknown"
+	// ztunnelDaemonSet is the name of the DaemonSet of ztunnel, installed in the Istio namespace with ambient.
+	ztunnelDaemonSet = "ztunnel"
+)
+
+// proxyMode returns how Services are implemented on the nodes: the kube-proxy mode, or cilium-replacement.
+func (dp nodeDataPlane) proxyMode() string {
+	switch {
+	case dp.ciliumKubeProxyReplacement:
+		return ciliumKubeProxyReplacementMode
+	case dp.kubeProxyMode != "":
+		return dp.kubeProxyMode
+	}
+	return unknownProxyMode
+}
+
+// meshInterception describes how the mesh captures traffic.
+type meshInterception struct {
+	// mode is the inbound interception mode of sidecars.
+	mode meshconfig.ProxyConfig_InboundInterceptionMode
+	// dnsCapture is set when sidecars capture DNS queries.
+	dnsCapture bool
+	// ambient is set when ztunnel is installed.
+	ambient bool
+}
+
+// kubeProxyConflict is a combination of proxy mode and interception known to conflict. Its ID identifies the
+// finding in the output, with the link explaining it.
+type kubeProxyConflict struct {
+	id          string
+	url         string
+	matches     func(nodeDataPlane, meshInterception) bool
+	description string
+}
+
+var kubeProxyConflicts = []kubeProxyConflict{
+	{
+		id:  "KP001",
+		url: "https://docs.cilium.io/en/stable/network/servicemesh/istio/",
+		matches: func(dp nodeDataPlane, _ meshInterception) bool {
+			return dp.ciliumKubeProxyReplacement && dp.ciliumSocketLBInPods
+		},
+		description: "Cilium translates Service addresses to backend addresses at connect() time inside pods, so the proxies " +
+			"see pod addresses and the routing rules of Services do not apply; set bpf-lb-sock-hostns-only: \"true\" " +
+			"(Helm socketLB.hostNamespaceOnly=true) in the Cilium config",
+	},
+	{
+		id:  "KP002",
+		url: "https://istio.io/latest/docs/ops/configuration/traffic-management/dns-proxy/",
+		matches: func(dp nodeDataPlane, mi meshInterception) bool {
+			return dp.ciliumKubeProxyReplacement && dp.ciliumSocketLBInPods && mi.dnsCapture
+		},
+		description: "DNS queries to the kube-dns Service are translated to a DNS pod address by Cilium before the sidecars " +
+			"capture them, so DNS capture is bypassed",
+	},
+	{
+		id:  "KP003",
+		url: "https://istio.io/latest/docs/reference/config/istio.mesh.v1alpha1/#ProxyConfig-InboundInterceptionMode",
+		matches: func(dp nodeDataPlane, mi meshInterception) bool {
+			return dp.kubeProxyMode == "ipvs" && mi.mode == meshconfig.ProxyConfig_TPROXY
+		},
+		description: "TPROXY interception routes inbound traffic with packet marks and a local routing table, which conflicts " +
+			"with the connection tracking of kube-proxy in IPVS mode; use the REDIRECT interception mode",
+	},
+	{
+		id:  "KP004",
+		url: "https://kubernetes.io/docs/reference/networking/virtual-ips/#proxy-mode-nftables",
+		matches: func(dp nodeDataPlane, mi meshInterception) bool {
+			return dp.kubeProxyMode == "nftables" && mi.ambient
+		},
+		description: "kube-proxy programs nftables while ztunnel redirection is programmed with iptables on the nodes; make " +
+			"sure the nodes use the nft backend of iptables, or the rules of the legacy backend are evaluated out of order",
+	},
+}
+
+// verifyKubeProxyMode detects how Services are implemented on the nodes of the cluster, and warns about the
+// combinations known to conflict with the interception mode or DNS capture of the mesh.
+func (v *StatusVerifier) verifyKubeProxyMode(ctx context.Context) error {
+	dp, err := v.nodeDataPlane(ctx)
+	if err != nil {
+		return err
+	}
+	mi, err := v.meshInterception(ctx)
+	if err != nil {
+		return err
+	}
+	mode := dp.proxyMode()
+	v.logf(VerbosityNormal, "kube-proxy mode: %s, interception mode: %s, DNS capture: %t, ambient: %t",
+		mode, mi.mode, mi.dnsCapture, mi.ambient)
+	if mode == unknownProxyMode {
+		v.reportWarning("Node data plane", mode, "", fmt.Errorf("neither the kube-proxy nor the Cilium config was found in %s",
+			metav1.NamespaceSystem))
+		return nil
+	}
+	conflicts := 0
+	for _, c := range kubeProxyConflicts {
+		if c.matches(dp, mi) {
+			conflicts++
+			v.reportWarning("Node data plane", c.id, "", fmt.Errorf("%s: %s (see %s)", mode, c.description, c.url))
+		}
+	}
+	if conflicts == 0 {
+		v.reportSuccess("Node data plane", mode, "")
+	}
+	return nil
+}
+
+// meshInterception reads how the mesh captures traffic from the mesh config and the installed components.
+func (v *StatusVerifier) meshInterception(ctx context.Context) (meshInterception, error) {
+	var mi meshInterception
+	mc, err := v.meshConfig(ctx)
+	if err != nil {
+		return mi, err
+	}
+	if pc := mc.GetDefaultConfig(); pc != nil {
+		mi.mode = pc.GetInterceptionMode()
+		mi.dnsCapture = strings.EqualFold(pc.GetProxyMetadata()["ISTIO_META_DNS_CAPTURE"], "true")
+	}
+	_, err = v.client.Kube().AppsV1().DaemonSets(v.istioNamespace).Get(ctx, ztunnelDaemonSet, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		mi.ambient = true
+	case !kerrors.IsNotFound(err):
+		return mi, fmt.Errorf("failed to read the ztunnel DaemonSet: %v", err)
+	}
+	return mi, nil
+}
@@ -0,0 +1,118 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func meshConfigMapWith(mesh string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+		Data:       map[string]string{"mesh": mesh},
+	}
+}
+
+func TestVerifyKubeProxyMode(t *testing.T) {
+	cases := []struct {
+		name     string
+		objects  []runtime.Object
+		findings []string
+	}{
+		{
+			name: "iptables",
+			objects: []runtime.Object{
+				meshConfigMapWith(""),
+				kubeSystemConfigMap(kubeProxyConfigMap, map[string]string{"config.conf": "mode: \"\""}),
+			},
+			findings: []string{"passed iptables"},
+		},
+		{
+			name:     "unknown",
+			objects:  []runtime.Object{meshConfigMapWith("")},
+			findings: []string{"warning unknown"},
+		},
+		{
+			name: "cilium socket load balancing with DNS capture",
+			objects: []runtime.Object{
+				meshConfigMapWith("defaultConfig:\n  proxyMetadata:\n    ISTIO_META_DNS_CAPTURE: \"true\""),
+				kubeSystemConfigMap(ciliumConfigMap, map[string]string{"kube-proxy-replacement": "true"}),
+			},
+			findings: []string{"warning KP001", "warning KP002"},
+		},
+		{
+			name: "cilium socket load balancing in the host namespace only",
+			objects: []runtime.Object{
+				meshConfigMapWith("defaultConfig:\n  proxyMetadata:\n    ISTIO_META_DNS_CAPTURE: \"true\""),
+				kubeSystemConfigMap(ciliumConfigMap, map[string]string{"kube-proxy-replacement": "true", "bpf-lb-sock-hostns-only": "true"}),
+			},
+			findings: []string{"passed cilium-replacement"},
+		},
+		{
+			name: "ipvs with tproxy",
+			objects: []runtime.Object{
+				meshConfigMapWith("defaultConfig:\n  interceptionMode: TPROXY"),
+				kubeSystemConfigMap(kubeProxyConfigMap, map[string]string{"config.conf": "mode: ipvs"}),
+			},
+			findings: []string{"warning KP003"},
+		},
+		{
+			name: "ipvs with redirect",
+			objects: []runtime.Object{
+				meshConfigMapWith(""),
+				kubeSystemConfigMap(kubeProxyConfigMap, map[string]string{"config.conf": "mode: ipvs"}),
+			},
+			findings: []string{"passed ipvs"},
+		},
+		{
+			name: "nftables with ambient",
+			objects: []runtime.Object{
+				meshConfigMapWith(""),
+				kubeSystemConfigMap(kubeProxyConfigMap, map[string]string{"config.conf": "mode: nftables"}),
+				&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: ztunnelDaemonSet, Namespace: "istio-system"}},
+			},
+			findings: []string{"warning KP004"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         kube.NewFakeClient(tc.objects...),
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+			}
+			assert.NoError(t, v.verifyKubeProxyMode(context.Background()))
+			var findings []string
+			for _, r := range v.results {
+				findings = append(findings, r.status.String()+" "+r.name)
+			}
+			assert.Equal(t, findings, tc.findings)
+		})
+	}
+}
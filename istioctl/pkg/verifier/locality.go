@@ -0,0 +1,194 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/label"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// verifyLocalityLoadBalancing checks the locality load balancing settings of the mesh config and all
+// DestinationRules. Failover requires outlier detection to take effect, which is reported as a failure;
+// localities which match none of the cluster nodes are reported as warnings.
+func (v *StatusVerifier) verifyLocalityLoadBalancing(ctx context.Context) error {
+	nodes, err := v.client.Kube().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	localities := nodeLocalities(nodes.Items)
+
+	if mc, err := v.meshConfig(ctx); err != nil {
+		v.reportWarning("ConfigMap", "mesh config", v.istioNamespace, err)
+	} else {
+		// Outlier detection is configured per DestinationRule, so only the localities can be checked mesh wide.
+		for _, l := range unmatchedLocalities(mc.GetLocalityLbSetting(), localities) {
+			v.reportWarning("MeshConfig", "localityLbSetting", v.istioNamespace, fmt.Errorf("locality %q matches no nodes", l))
+		}
+	}
+
+	drs, err := v.client.Istio().NetworkingV1alpha3().DestinationRules(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list destination rules: %v", err)
+	}
+	multiErr := &multierror.Error{}
+	for _, dr := range drs.Items {
+		for _, policy := range trafficPolicies(&dr.Spec) {
+			lb := policy.GetLoadBalancer().GetLocalityLbSetting()
+			if !localityLbEnabled(lb) {
+				continue
+			}
+			for _, l := range unmatchedLocalities(lb, localities) {
+				err := withCode(CodeLocalityUnmatched, fmt.Errorf("locality %q matches no nodes", l))
+				v.reportWarning("DestinationRule", dr.Name, dr.Namespace, err)
+			}
+			if (len(lb.GetFailover()) > 0 || len(lb.GetFailoverPriority()) > 0) && policy.GetOutlierDetection() == nil {
+				err := fmt.Errorf("locality failover is configured without outlierDetection, failover will never happen")
+				v.reportFailure("DestinationRule", dr.Name, dr.Namespace, withCode(CodeLocalityFailover, err))
+				multiErr = multierror.Append(multiErr, fmt.Errorf("destination rule %s/%s: %v", dr.Namespace, dr.Name, err))
+			}
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// trafficPolicies returns the effective traffic policies of a DestinationRule: its own policy, its port level
+// settings, and the policies of its subsets merged with those as istiod does, a subset overriding the load balancer
+// and the outlier detection it sets. Port level settings inherit outlier detection from the enclosing policy.
+// Policies with the same load balancer and outlier detection are only returned once.
+func trafficPolicies(dr *networking.DestinationRule) []*networking.TrafficPolicy {
+	type effective struct {
+		lb      *networking.LoadBalancerSettings
+		outlier *networking.OutlierDetection
+	}
+	seen := sets.New[effective]()
+	var policies []*networking.TrafficPolicy
+	add := func(lb *networking.LoadBalancerSettings, outlier *networking.OutlierDetection) {
+		if seen.InsertContains(effective{lb, outlier}) {
+			return
+		}
+		policies = append(policies, &networking.TrafficPolicy{LoadBalancer: lb, OutlierDetection: outlier})
+	}
+
+	top := dr.GetTrafficPolicy()
+	if top != nil {
+		add(top.GetLoadBalancer(), top.GetOutlierDetection())
+	}
+	for _, pl := range top.GetPortLevelSettings() {
+		add(pl.GetLoadBalancer(), outlierOr(pl.GetOutlierDetection(), top.GetOutlierDetection()))
+	}
+	for _, ss := range dr.GetSubsets() {
+		sp := ss.GetTrafficPolicy()
+		if sp == nil {
+			// The subset uses the policies of the DestinationRule.
+			continue
+		}
+		outlier := outlierOr(sp.GetOutlierDetection(), top.GetOutlierDetection())
+		add(loadBalancerOr(sp.GetLoadBalancer(), top.GetLoadBalancer()), outlier)
+		overridden := sets.New[uint32]()
+		for _, pl := range sp.GetPortLevelSettings() {
+			overridden.Insert(pl.GetPort().GetNumber())
+			add(pl.GetLoadBalancer(), outlierOr(pl.GetOutlierDetection(), outlier))
+		}
+		// On the other ports, the settings of the subset override the port level settings of the DestinationRule.
+		for _, pl := range top.GetPortLevelSettings() {
+			if overridden.Contains(pl.GetPort().GetNumber()) {
+				continue
+			}
+			add(loadBalancerOr(sp.GetLoadBalancer(), pl.GetLoadBalancer()),
+				outlierOr(sp.GetOutlierDetection(), outlierOr(pl.GetOutlierDetection(), top.GetOutlierDetection())))
+		}
+	}
+	return policies
+}
+
+// loadBalancerOr returns the load balancer settings, or the inherited ones if they are not set.
+func loadBalancerOr(lb, inherited *networking.LoadBalancerSettings) *networking.LoadBalancerSettings {
+	if lb != nil {
+		return lb
+	}
+	return inherited
+}
+
+// outlierOr returns the outlier detection, or the inherited one if it is not set.
+func outlierOr(outlier, inherited *networking.OutlierDetection) *networking.OutlierDetection {
+	if outlier != nil {
+		return outlier
+	}
+	return inherited
+}
+
+func localityLbEnabled(lb *networking.LocalityLoadBalancerSetting) bool {
+	return lb != nil && (lb.GetEnabled() == nil || lb.GetEnabled().GetValue())
+}
+
+func nodeLocalities(nodes []corev1.Node) []*core.Locality {
+	seen := sets.New[string]()
+	var localities []*core.Locality
+	for _, n := range nodes {
+		l := &core.Locality{
+			Region:  n.Labels[corev1.LabelTopologyRegion],
+			Zone:    n.Labels[corev1.LabelTopologyZone],
+			SubZone: n.Labels[label.TopologySubzone.Name],
+		}
+		key := l.Region + "/" + l.Zone + "/" + l.SubZone
+		if seen.InsertContains(key) {
+			continue
+		}
+		localities = append(localities, l)
+	}
+	return localities
+}
+
+// unmatchedLocalities returns the localities referenced by the setting which match none of the given localities.
+func unmatchedLocalities(lb *networking.LocalityLoadBalancerSetting, localities []*core.Locality) []string {
+	if !localityLbEnabled(lb) {
+		return nil
+	}
+	referenced := sets.New[string]()
+	for _, d := range lb.GetDistribute() {
+		referenced.Insert(d.GetFrom())
+		for to := range d.GetTo() {
+			referenced.Insert(to)
+		}
+	}
+	for _, f := range lb.GetFailover() {
+		referenced.InsertAll(f.GetFrom(), f.GetTo())
+	}
+	referenced.Delete("")
+	var unmatched []string
+	for _, r := range sets.SortedList(referenced) {
+		matched := false
+		for _, l := range localities {
+			if util.LocalityMatch(l, r) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, r)
+		}
+	}
+	return unmatched
+}
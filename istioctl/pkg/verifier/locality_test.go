@@ -0,0 +1,163 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func zonedNode(name, region, zone string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				corev1.LabelTopologyRegion: region,
+				corev1.LabelTopologyZone:   zone,
+			},
+		},
+	}
+}
+
+func TestVerifyLocalityLoadBalancing(t *testing.T) {
+	client := kube.NewFakeClient(
+		zonedNode("node-1", "us-west", "us-west-1a"),
+		zonedNode("node-2", "us-east", "us-east-1a"),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": ""},
+		},
+	)
+	drs := []*clientnetworking.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "distribute", Namespace: "default"},
+			Spec: networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					LoadBalancer: &networking.LoadBalancerSettings{
+						LocalityLbSetting: &networking.LocalityLoadBalancerSetting{
+							Distribute: []*networking.LocalityLoadBalancerSetting_Distribute{{
+								From: "us-west/us-west-1a/*",
+								To:   map[string]uint32{"us-west/us-west-1a/*": 80, "eu-west/*": 20},
+							}},
+						},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "failover", Namespace: "default"},
+			Spec: networking.DestinationRule{
+				Host: "ratings",
+				Subsets: []*networking.Subset{{
+					Name: "v1",
+					TrafficPolicy: &networking.TrafficPolicy{
+						LoadBalancer: &networking.LoadBalancerSettings{
+							LocalityLbSetting: &networking.LocalityLoadBalancerSetting{
+								Failover: []*networking.LocalityLoadBalancerSetting_Failover{{From: "us-west", To: "us-east"}},
+							},
+						},
+					},
+				}},
+			},
+		},
+	}
+	for _, dr := range drs {
+		if _, err := client.Istio().NetworkingV1alpha3().DestinationRules(dr.Namespace).Create(context.TODO(), dr, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+	}
+	err := v.verifyLocalityLoadBalancing(context.Background())
+	assert.Error(t, err)
+	if !strings.Contains(err.Error(), "default/failover") || strings.Contains(err.Error(), "default/distribute") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `! DestinationRule: distribute.default: locality "eu-west/*" matches no nodes`) {
+		t.Fatalf("missing warning for unmatched locality in output:\n%s", out.String())
+	}
+}
+
+func TestTrafficPoliciesMergeSubsets(t *testing.T) {
+	failover := &networking.LoadBalancerSettings{
+		LocalityLbSetting: &networking.LocalityLoadBalancerSetting{
+			Failover: []*networking.LocalityLoadBalancerSetting_Failover{{From: "us-west", To: "us-east"}},
+		},
+	}
+	outlier := &networking.OutlierDetection{}
+	portOutlier := func(port uint32) []*networking.TrafficPolicy_PortTrafficPolicy {
+		return []*networking.TrafficPolicy_PortTrafficPolicy{{Port: &networking.PortSelector{Number: port}, OutlierDetection: outlier}}
+	}
+	cases := []struct {
+		name string
+		dr   *networking.DestinationRule
+		// withoutOutlier is whether a policy fails over without outlier detection.
+		withoutOutlier bool
+	}{
+		{
+			name: "subset inherits top level outlier detection",
+			dr: &networking.DestinationRule{
+				TrafficPolicy: &networking.TrafficPolicy{OutlierDetection: outlier},
+				Subsets:       []*networking.Subset{{Name: "v1", TrafficPolicy: &networking.TrafficPolicy{LoadBalancer: failover}}},
+			},
+		},
+		{
+			name: "subset fails over on the ports without outlier detection",
+			dr: &networking.DestinationRule{
+				TrafficPolicy: &networking.TrafficPolicy{PortLevelSettings: portOutlier(80)},
+				Subsets:       []*networking.Subset{{Name: "v1", TrafficPolicy: &networking.TrafficPolicy{LoadBalancer: failover}}},
+			},
+			withoutOutlier: true,
+		},
+		{
+			name: "subset port overrides the port of the destination rule",
+			dr: &networking.DestinationRule{
+				TrafficPolicy: &networking.TrafficPolicy{OutlierDetection: outlier},
+				Subsets: []*networking.Subset{{Name: "v1", TrafficPolicy: &networking.TrafficPolicy{
+					PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{Port: &networking.PortSelector{Number: 80}, LoadBalancer: failover}},
+				}}},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			withoutOutlier := false
+			for _, p := range trafficPolicies(c.dr) {
+				if len(p.GetLoadBalancer().GetLocalityLbSetting().GetFailover()) > 0 && p.GetOutlierDetection() == nil {
+					withoutOutlier = true
+				}
+			}
+			assert.Equal(t, withoutOutlier, c.withoutOutlier)
+		})
+	}
+}
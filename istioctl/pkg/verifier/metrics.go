@@ -0,0 +1,87 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics are the Prometheus metrics of verifications, for programs verifying the installation repeatedly, such
+// as operators verifying it in their reconcile loop. They are shared by all the verifiers of a program.
+type Metrics struct {
+	checks      *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	runs        *prometheus.CounterVec
+	duration    prometheus.Histogram
+	lastSuccess prometheus.Gauge
+}
+
+// NewMetrics creates the metrics of verifications and registers them with the registerer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "istio_verify_checks_total",
+			Help: "Total number of checks made by the verifications of the installation, by kind and status.",
+		}, []string{"kind", "status"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "istio_verify_failures_total",
+			Help: "Total number of checks which failed in the verifications of the installation, by kind.",
+		}, []string{"kind"}),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "istio_verify_runs_total",
+			Help: "Total number of verifications of the installation, by result.",
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "istio_verify_duration_seconds",
+			Help:    "Duration of the verifications of the installation.",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "istio_verify_last_success_timestamp_seconds",
+			Help: "Unix time of the last verification of the installation which passed.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.checks, m.failures, m.runs, m.duration, m.lastSuccess} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// WithMetrics records the outcome of every verification in the metrics.
+func WithMetrics(m *Metrics) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.metrics = m
+	}
+}
+
+// observe records the outcome of a verification. A verification passes when it returns no error, as checks
+// which failed without failing it, such as those of addons, are warnings.
+func (m *Metrics) observe(r *VerificationResult, err error) {
+	for _, c := range r.Checks {
+		m.checks.WithLabelValues(c.Kind, c.Status).Inc()
+	}
+	for _, c := range r.Failures {
+		m.failures.WithLabelValues(c.Kind).Inc()
+	}
+	m.duration.Observe(r.Duration.Seconds())
+	if err != nil {
+		m.runs.WithLabelValues("failed").Inc()
+		return
+	}
+	m.runs.WithLabelValues("passed").Inc()
+	m.lastSuccess.Set(float64(r.Start.Add(r.Duration).Unix()))
+}
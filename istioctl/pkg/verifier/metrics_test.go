@@ -0,0 +1,63 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewMetrics(reg)
+	assert.NoError(t, err)
+	// Metrics are registered once per program.
+	_, err = NewMetrics(reg)
+	assert.Error(t, err)
+
+	v := &StatusVerifier{}
+	WithOutput(io.Discard)(v)
+	WithMetrics(m)(v)
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("Deployment", "kiali", "istio-system", errors.New("not ready"))
+	v.reportFailure("DaemonSet", "istio-cni-node", "kube-system", errors.New("not ready"))
+	start := time.Unix(1700000000, 0)
+	r := v.result(start)
+	r.Duration = 3 * time.Second
+	m.observe(r, errors.New("verification failed"))
+
+	assert.Equal(t, testutil.ToFloat64(m.checks.WithLabelValues("Deployment", "passed")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.checks.WithLabelValues("Deployment", "warning")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.checks.WithLabelValues("DaemonSet", "failed")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.failures.WithLabelValues("DaemonSet")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.runs.WithLabelValues("failed")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.lastSuccess), 0.0)
+
+	v.results = nil
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	r = v.result(start)
+	r.Duration = 3 * time.Second
+	m.observe(r, nil)
+	assert.Equal(t, testutil.ToFloat64(m.runs.WithLabelValues("passed")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(m.lastSuccess), 1700000003.0)
+	assert.Equal(t, testutil.CollectAndCount(m.duration), 1)
+}
@@ -0,0 +1,105 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// WithNamespaces restricts the checks of the data plane, such as the injection coverage, the authorization
+// posture, the hostPort capture and the injection of namespaces, to the given namespaces, so that the
+// verification of very large shared clusters lists their namespaces rather than the whole cluster. The control
+// plane is verified regardless. No namespace, the default, checks the data plane of all namespaces.
+func WithNamespaces(namespaces ...string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.namespaces = namespaces
+	}
+}
+
+// scopedNamespaces lists the namespaces whose data plane is checked: the selected namespaces which exist, or all
+// the namespaces of the cluster.
+func (v *StatusVerifier) scopedNamespaces(ctx context.Context) ([]corev1.Namespace, error) {
+	if len(v.checks.namespaces) == 0 {
+		namespaces, err := v.client.Kube().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return namespaces.Items, nil
+	}
+	namespaces := make([]corev1.Namespace, 0, len(v.checks.namespaces))
+	for _, name := range v.checks.namespaces {
+		ns, err := v.client.Kube().CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			v.logf(VerbosityNormal, "%s Namespace %s selected for verification does not exist", v.warningMark(), name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, *ns)
+	}
+	return namespaces, nil
+}
+
+// scopedPods lists the pods whose data plane is checked: the pods of the selected namespaces, or all the pods of
+// the cluster.
+func (v *StatusVerifier) scopedPods(ctx context.Context) ([]corev1.Pod, error) {
+	if len(v.checks.namespaces) == 0 {
+		pods, err := v.client.Kube().CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return pods.Items, nil
+	}
+	var all []corev1.Pod
+	for _, ns := range v.checks.namespaces {
+		pods, err := v.client.Kube().CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %v", ns, err)
+		}
+		all = append(all, pods.Items...)
+	}
+	return all, nil
+}
+
+// scopedAuthorizationPolicies lists the AuthorizationPolicies of the selected namespaces and of the root namespace,
+// whose policies apply to all namespaces, or all the AuthorizationPolicies of the cluster.
+func (v *StatusVerifier) scopedAuthorizationPolicies(ctx context.Context, rootNamespace string) ([]*clientsecurity.AuthorizationPolicy, error) {
+	security := v.client.Istio().SecurityV1beta1()
+	if len(v.checks.namespaces) == 0 {
+		policies, err := security.AuthorizationPolicies(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return policies.Items, nil
+	}
+	var all []*clientsecurity.AuthorizationPolicy
+	for _, ns := range sets.SortedList(sets.New(v.checks.namespaces...).Insert(rootNamespace)) {
+		policies, err := security.AuthorizationPolicies(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %v", ns, err)
+		}
+		all = append(all, policies.Items...)
+	}
+	return all, nil
+}
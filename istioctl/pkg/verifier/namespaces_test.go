@@ -0,0 +1,105 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWithNamespaces(t *testing.T) {
+	pod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	client := kube.NewFakeClient(
+		namespaceWithLabels("istio-system", nil),
+		namespaceWithLabels("team-a", map[string]string{"istio-injection": "enabled"}),
+		namespaceWithLabels("team-b", map[string]string{"istio-injection": "enabled"}),
+		namespaceWithLabels("team-c", map[string]string{"istio-injection": "enabled"}),
+		pod("a", "team-a"),
+		pod("b", "team-b"),
+		pod("c", "team-c"),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": ""},
+		},
+	)
+	for _, p := range []*clientsecurity.AuthorizationPolicy{
+		{ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "team-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "deny-all", Namespace: "team-c"}},
+	} {
+		if _, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(p.Namespace).Create(context.TODO(), p, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         client,
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+	}
+
+	names := func(namespaces []corev1.Namespace) []string {
+		var got []string
+		for _, ns := range namespaces {
+			got = append(got, ns.Name)
+		}
+		return got
+	}
+	namespaces, err := v.scopedNamespaces(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, names(namespaces), []string{"istio-system", "team-a", "team-b", "team-c"})
+	pods, err := v.scopedPods(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(pods), 3)
+
+	WithNamespaces("team-a", "team-b", "gone")(v)
+	namespaces, err = v.scopedNamespaces(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, names(namespaces), []string{"team-a", "team-b"})
+	if !strings.Contains(out.String(), "Namespace gone selected for verification does not exist") {
+		t.Errorf("missing namespace not reported:\n%s", out.String())
+	}
+	pods, err = v.scopedPods(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, len(pods), 2)
+
+	// The policies of the root namespace still apply to the selected namespaces.
+	root := &clientsecurity.AuthorizationPolicy{ObjectMeta: metav1.ObjectMeta{Name: "allow-nothing", Namespace: "istio-system"}}
+	if _, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(root.Namespace).Create(context.TODO(), root, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := v.scopedAuthorizationPolicies(context.Background(), "istio-system")
+	assert.NoError(t, err)
+	assert.Equal(t, len(policies), 2)
+	out.Reset()
+	assert.NoError(t, v.verifyAuthorizationPosture(context.Background()))
+	if !strings.Contains(out.String(), "Authorization posture: 2 namespaces deny by default, 0 with selective policies, 0 allow all, 0 wide open") {
+		t.Errorf("unexpected output for the selected namespaces:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "team-c") {
+		t.Errorf("namespace which was not selected reported:\n%s", out.String())
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/label"
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/util/sets"
+)
+
+const (
+	// meshNetworksKey is the key of the mesh networks in the mesh config map.
+	meshNetworksKey = "meshNetworks"
+	// eastWestGatewayPort is the port east-west gateways pass mTLS traffic from other networks through on.
+	eastWestGatewayPort = 15443
+)
+
+// verifyMeshNetworks checks the wiring of multi-network installs: the gateways of meshNetworks must be
+// existing services exposing the configured port, east-west gateways must expose port 15443, and the
+// network labels of namespaces and nodes must name configured networks. The networks are those of
+// meshNetworks and those of the east-west gateways, which istiod discovers from their network label.
+func (v *StatusVerifier) verifyMeshNetworks(ctx context.Context) error {
+	networks, err := v.meshNetworks(ctx)
+	if err != nil {
+		v.reportWarning("ConfigMap", "mesh networks", v.istioNamespace, err)
+		networks = &meshconfig.MeshNetworks{}
+	}
+	services, err := v.client.Kube().CoreV1().Services(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list services: %v", err)
+	}
+	var eastWestGateways []*corev1.Service
+	configured := sets.New(maps.Keys(networks.GetNetworks())...)
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if network := svc.Labels[label.TopologyNetwork.Name]; network != "" && isGatewayService(svc) {
+			eastWestGateways = append(eastWestGateways, svc)
+			configured.Insert(network)
+		}
+	}
+	if configured.IsEmpty() {
+		v.logf(VerbosityNormal, "No networks configured, skipping multi-network checks")
+		return nil
+	}
+
+	multiErr := &multierror.Error{}
+	fail := func(kind, name, namespace string, err error) {
+		v.reportFailure(kind, name, namespace, withCode(CodeNetworkMisconfigured, err))
+		if namespace != "" {
+			name = namespace + "/" + name
+		}
+		multiErr = multierror.Append(multiErr, fmt.Errorf("%s %s: %v", strings.ToLower(kind), name, err))
+	}
+
+	localNetwork := ""
+	if ns, err := v.client.Kube().CoreV1().Namespaces().Get(ctx, v.istioNamespace, metav1.GetOptions{}); err == nil {
+		localNetwork = ns.Labels[label.TopologyNetwork.Name]
+	}
+	for _, name := range sets.SortedList(sets.New(maps.Keys(networks.GetNetworks())...)) {
+		for _, gw := range networks.GetNetworks()[name].GetGateways() {
+			host := gw.GetRegistryServiceName()
+			if host == "" {
+				// Gateways configured with an address cannot be matched to a service.
+				continue
+			}
+			svcName, svcNamespace, _ := strings.Cut(host, ".")
+			svcNamespace, _, _ = strings.Cut(svcNamespace, ".")
+			svc := findService(services.Items, svcName, svcNamespace)
+			if svc == nil {
+				err := fmt.Errorf("gateway %s of network %q not found", host, name)
+				if name == localNetwork {
+					fail("Service", svcName, svcNamespace, err)
+				} else {
+					// The gateways of other networks typically run in other clusters.
+					v.reportWarning("Service", svcName, svcNamespace, withCode(CodeNetworkMisconfigured, fmt.Errorf("%v, it may run in another cluster", err)))
+				}
+				continue
+			}
+			if network := svc.Labels[label.TopologyNetwork.Name]; network != "" && network != name {
+				fail("Service", svc.Name, svc.Namespace,
+					fmt.Errorf("gateway of network %q is labeled with network %q", name, network))
+				continue
+			}
+			if !hasServicePort(svc, gw.GetPort()) {
+				fail("Service", svc.Name, svc.Namespace,
+					fmt.Errorf("gateway of network %q does not expose the configured port %d", name, gw.GetPort()))
+				continue
+			}
+			v.reportSuccess("Service", svc.Name, svc.Namespace)
+		}
+	}
+
+	for _, svc := range eastWestGateways {
+		if !hasServicePort(svc, eastWestGatewayPort) {
+			fail("East-west gateway", svc.Name, svc.Namespace,
+				fmt.Errorf("port %d is not exposed, traffic from other networks cannot reach network %q",
+					eastWestGatewayPort, svc.Labels[label.TopologyNetwork.Name]))
+			continue
+		}
+		v.reportSuccess("East-west gateway", svc.Name, svc.Namespace)
+	}
+
+	namespaces, err := v.client.Kube().CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return multierror.Append(multiErr, fmt.Errorf("failed to list namespaces: %v", err))
+	}
+	for _, ns := range namespaces.Items {
+		if network := ns.Labels[label.TopologyNetwork.Name]; network != "" && !configured.Contains(network) {
+			fail("Namespace", ns.Name, "", fmt.Errorf("network %q is not configured, configured networks are %v",
+				network, sets.SortedList(configured)))
+		}
+	}
+	nodes, err := v.client.Kube().CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return multierror.Append(multiErr, fmt.Errorf("failed to list nodes: %v", err))
+	}
+	for _, node := range nodes.Items {
+		if network := node.Labels[label.TopologyNetwork.Name]; network != "" && !configured.Contains(network) {
+			fail("Node", node.Name, "", fmt.Errorf("network %q is not configured, configured networks are %v",
+				network, sets.SortedList(configured)))
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+func findService(services []corev1.Service, name, namespace string) *corev1.Service {
+	for i := range services {
+		if services[i].Name == name && services[i].Namespace == namespace {
+			return &services[i]
+		}
+	}
+	return nil
+}
+
+func hasServicePort(svc *corev1.Service, port uint32) bool {
+	for _, p := range svc.Spec.Ports {
+		if uint32(p.Port) == port {
+			return true
+		}
+	}
+	return false
+}
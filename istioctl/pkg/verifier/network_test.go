@@ -0,0 +1,128 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/label"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func networkGateway(name, network string, ports ...int32) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "istio-system",
+			Labels:    map[string]string{label.TopologyNetwork.Name: network},
+		},
+		Spec: corev1.ServiceSpec{Selector: map[string]string{"istio": "eastwestgateway"}},
+	}
+	for _, p := range ports {
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{Port: p})
+	}
+	return svc
+}
+
+func networkLabeled(network string) map[string]string {
+	return map[string]string{label.TopologyNetwork.Name: network}
+}
+
+func TestVerifyMeshNetworks(t *testing.T) {
+	meshNetworks := `
+networks:
+  network1:
+    endpoints:
+    - fromRegistry: cluster1
+    gateways:
+    - registryServiceName: istio-eastwestgateway.istio-system.svc.cluster.local
+      port: 15443
+    - registryServiceName: istio-missinggateway.istio-system.svc.cluster.local
+      port: 15443
+  network2:
+    endpoints:
+    - fromRegistry: cluster2
+    gateways:
+    - registryServiceName: istio-eastwestgateway.remote.svc.cluster.local
+      port: 15443
+`
+	client := kube.NewFakeClient(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+			Data:       map[string]string{"mesh": "", "meshNetworks": meshNetworks},
+		},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "istio-system", Labels: networkLabeled("network1")}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: networkLabeled("network1")}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "typo", Labels: networkLabeled("netwrok1")}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: networkLabeled("network3")}},
+		networkGateway("istio-eastwestgateway", "network1", 15021, 15443),
+		networkGateway("istio-eastwestgateway-3", "network3", 15021),
+	)
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		client:         client,
+		istioNamespace: "istio-system",
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+	}
+	err := v.verifyMeshNetworks(context.Background())
+	assert.Error(t, err)
+	for _, want := range []string{
+		"service istio-system/istio-missinggateway: gateway istio-missinggateway.istio-system.svc.cluster.local of network \"network1\" not found",
+		"east-west gateway istio-system/istio-eastwestgateway-3: port 15443 is not exposed",
+		"namespace typo: network \"netwrok1\" is not configured, configured networks are [network1 network2 network3]",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "node-1") {
+		t.Errorf("node of the network of an east-west gateway reported: %v", err)
+	}
+	for _, want := range []string{
+		"✔ Service: istio-eastwestgateway.istio-system checked successfully",
+		"✔ East-west gateway: istio-eastwestgateway.istio-system checked successfully",
+		"! Service: istio-eastwestgateway.remote: gateway istio-eastwestgateway.remote.svc.cluster.local of network \"network2\" " +
+			"not found, it may run in another cluster",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestVerifyMeshNetworksSingleNetwork(t *testing.T) {
+	client := kube.NewFakeClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+		Data:       map[string]string{"mesh": ""},
+	})
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		client:         client,
+		istioNamespace: "istio-system",
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+	}
+	assert.NoError(t, v.verifyMeshNetworks(context.Background()))
+	assert.Equal(t, strings.TrimSpace(out.String()), "No networks configured, skipping multi-network checks")
+}
@@ -0,0 +1,60 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"io"
+
+	"istio.io/istio/operator/pkg/util/clog"
+)
+
+// Renderer renders the result of each check as it is made, for programs showing the progress of a verification
+// in their own UI. The component of the result is not known yet; it is in the results of Verify.
+type Renderer interface {
+	RenderCheck(r CheckResult)
+}
+
+// RendererFunc adapts a function to a Renderer.
+type RendererFunc func(r CheckResult)
+
+// RenderCheck calls f(r).
+func (f RendererFunc) RenderCheck(r CheckResult) {
+	f(r)
+}
+
+// WithOutput writes the output of the verification to w rather than to stdout and stderr. Passing io.Discard
+// suppresses it.
+func WithOutput(w io.Writer) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.logger = clog.NewConsoleLogger(w, w, nil)
+	}
+}
+
+// WithRenderer renders the result of every check with r, instead of printing a line per check to the output.
+// The other lines of the output, such as the summary, are still printed.
+func WithRenderer(r Renderer) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.renderer = r
+	}
+}
+
+// renderCheck renders the result of the check recorded last with the renderer, if one is set.
+func (v *StatusVerifier) renderCheck() bool {
+	if v.renderer == nil || len(v.results) == 0 {
+		return false
+	}
+	v.renderer.RenderCheck(v.results[len(v.results)-1].export())
+	return true
+}
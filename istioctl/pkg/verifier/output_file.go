@@ -0,0 +1,94 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/istioctl/pkg/writer/output"
+	"istio.io/istio/pkg/file"
+)
+
+// VerificationReport is the full report of a verification, written to a file for audit trails.
+type VerificationReport struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Timestamp is when the verification started.
+	Timestamp time.Time `json:"timestamp"`
+	// Passed is whether the verification passed, and Error why it did not.
+	Passed bool                `json:"passed"`
+	Error  string              `json:"error,omitempty"`
+	Result *VerificationResult `json:"result"`
+}
+
+// NewVerificationReport returns the report of a verification from its result and error.
+func NewVerificationReport(result *VerificationResult, verifyErr error) *VerificationReport {
+	r := &VerificationReport{
+		APIVersion: output.APIVersion,
+		Kind:       "VerificationReport",
+		Timestamp:  result.Start,
+		Passed:     verifyErr == nil,
+		Result:     result,
+	}
+	if verifyErr != nil {
+		r.Error = verifyErr.Error()
+	}
+	return r
+}
+
+// WriteFile writes the report to a file in one of the output formats. In the table format, the output of the
+// verification printed to the console is written, under a header with the timestamp and what was verified.
+func (r *VerificationReport) WriteFile(path, format, consoleOutput string) error {
+	var out []byte
+	var err error
+	switch format {
+	case output.JSONFormat:
+		out, err = json.MarshalIndent(r, "", "  ")
+		out = append(out, '\n')
+	case output.YAMLFormat:
+		out, err = yaml.Marshal(r)
+	default:
+		out = r.text(consoleOutput)
+	}
+	if err != nil {
+		return err
+	}
+	if err := file.AtomicWrite(path, out, 0o644); err != nil {
+		return fmt.Errorf("failed to write the verification report %s: %v", path, err)
+	}
+	return nil
+}
+
+func (r *VerificationReport) text(consoleOutput string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Verified at %s\n", r.Timestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "# Revision: %s\n", r.Result.Revision)
+	if len(r.Result.Sources) > 0 {
+		fmt.Fprintf(&b, "# Verified against: %s\n", strings.Join(r.Result.Sources, ", "))
+	}
+	if r.Passed {
+		b.WriteString("# Result: passed\n")
+	} else {
+		fmt.Fprintf(&b, "# Result: failed: %s\n", r.Error)
+	}
+	b.WriteString(consoleOutput)
+	return b.Bytes()
+}
@@ -0,0 +1,62 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestVerificationReportWriteFile(t *testing.T) {
+	result := &VerificationResult{
+		Start:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Revision: "canary",
+		Sources:  []string{"in cluster operator installed-state-canary"},
+		Checks:   []CheckResult{{Kind: "Deployment", Name: "istiod-canary", Namespace: "istio-system", Status: "failed"}},
+	}
+	report := NewVerificationReport(result, errors.New("Istio installation failed"))
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "report.json")
+	assert.NoError(t, report.WriteFile(path, "json", "ignored"))
+	out, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var got VerificationReport
+	assert.NoError(t, json.Unmarshal(out, &got))
+	assert.Equal(t, got.Kind, "VerificationReport")
+	assert.Equal(t, got.Timestamp.Equal(result.Start), true)
+	assert.Equal(t, got.Passed, false)
+	assert.Equal(t, got.Error, "Istio installation failed")
+	assert.Equal(t, got.Result.Revision, "canary")
+	assert.Equal(t, got.Result.Sources, result.Sources)
+	assert.Equal(t, len(got.Result.Checks), 1)
+
+	path = filepath.Join(dir, "report.txt")
+	assert.NoError(t, report.WriteFile(path, "table", "✘ Deployment: istiod-canary.istio-system failed\n"))
+	out, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(out), `# Verified at 2024-01-02T03:04:05Z
+# Revision: canary
+# Verified against: in cluster operator installed-state-canary
+# Result: failed: Istio installation failed
+✘ Deployment: istiod-canary.istio-system failed
+`)
+}
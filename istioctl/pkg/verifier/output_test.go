@@ -0,0 +1,61 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWithOutput(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{successMarker: "✔", failureMarker: "✘"}
+	WithOutput(&out)(v)
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportFailure("Deployment", "istio-ingressgateway", "istio-system", errors.New("not ready"))
+	for _, want := range []string{
+		"✔ Deployment: istiod.istio-system checked successfully",
+		"✘ Deployment: istio-ingressgateway.istio-system: not ready",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWithRenderer(t *testing.T) {
+	var out bytes.Buffer
+	var rendered []CheckResult
+	v := &StatusVerifier{successMarker: "✔", failureMarker: "✘"}
+	WithOutput(&out)(v)
+	WithRenderer(RendererFunc(func(r CheckResult) {
+		rendered = append(rendered, r)
+	}))(v)
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("Namespace", "default", "", errors.New("namespace is wide open"))
+	v.reportFailure("DaemonSet", "istio-cni-node", "kube-system", errors.New("not ready"))
+	assert.Equal(t, rendered, []CheckResult{
+		{Kind: "Deployment", Name: "istiod", Namespace: "istio-system", Status: "passed"},
+		coded(CheckResult{Kind: "Namespace", Name: "default", Status: "warning", Reason: "namespace is wide open"}, CodeCheckFailed),
+		coded(CheckResult{Kind: "DaemonSet", Name: "istio-cni-node", Namespace: "kube-system", Status: "failed", Reason: "not ready"}, CodeCheckFailed),
+	})
+	// The checks are rendered instead of printed.
+	assert.Equal(t, out.String(), "")
+	assert.Equal(t, len(v.Results()), 3)
+}
@@ -0,0 +1,196 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"istio.io/istio/operator/pkg/name"
+)
+
+// Progress is the progress of the verification of the installed resources.
+type Progress struct {
+	// Discovered is the number of installed resources found so far. The resources rendered from an
+	// IstioOperator or Helm values are all discovered before the first is checked, while the resources of
+	// installation files are discovered as they are read.
+	Discovered int
+	// Checked is the number of installed resources checked so far.
+	Checked int
+	// Kind, Name and Namespace are of the resource being checked, if any.
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// ProgressReporter is notified of the progress of a verification, for callers showing that long verifications,
+// such as those of installations with many CRDs, are not hung.
+type ProgressReporter interface {
+	ReportProgress(p Progress)
+}
+
+// ProgressReporterFunc adapts a function to a ProgressReporter.
+type ProgressReporterFunc func(p Progress)
+
+// ReportProgress calls f(p).
+func (f ProgressReporterFunc) ReportProgress(p Progress) {
+	f(p)
+}
+
+// WithProgress reports the progress of the verification to p.
+func WithProgress(p ProgressReporter) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.progress = p
+	}
+}
+
+// resourcesDiscovered records that n installed resources were found.
+func (v *StatusVerifier) resourcesDiscovered(n int) {
+	v.progressState.Discovered += n
+	v.reportProgress()
+}
+
+// manifestResourceCount counts the resources of the rendered manifests, by splitting the manifests into documents
+// without decoding them. The documents holding only comments, such as those of the templates which render nothing,
+// hold no resource.
+func manifestResourceCount(manifests name.ManifestMap) int {
+	count := 0
+	for _, ms := range manifests {
+		for _, m := range ms {
+			reader := yaml.NewYAMLReader(bufio.NewReader(strings.NewReader(m)))
+			for {
+				doc, err := reader.Read()
+				if err != nil {
+					// The builder reports the manifests which do not parse.
+					break
+				}
+				if hasYAMLContent(doc) {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
+// hasYAMLContent returns whether a YAML document holds more than blank lines and comments.
+func hasYAMLContent(doc []byte) bool {
+	for _, line := range bytes.Split(doc, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' && !bytes.Equal(line, []byte("---")) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceStarted records that an installed resource is being checked, and discovered unless it was already.
+func (v *StatusVerifier) resourceStarted(kind, name, namespace string) {
+	if v.progressState.Discovered <= v.progressState.Checked {
+		v.progressState.Discovered = v.progressState.Checked + 1
+	}
+	v.progressState.Kind, v.progressState.Name, v.progressState.Namespace = kind, name, namespace
+	v.reportProgress()
+}
+
+// resourceChecked records that the installed resource being checked was checked.
+func (v *StatusVerifier) resourceChecked() {
+	v.progressState.Checked++
+	v.progressState.Kind, v.progressState.Name, v.progressState.Namespace = "", "", ""
+	v.reportProgress()
+}
+
+func (v *StatusVerifier) reportProgress() {
+	if v.progress != nil {
+		v.progress.ReportProgress(v.progressState)
+	}
+}
+
+const progressBarWidth = 30
+
+// ProgressBar renders the progress of a verification as a bar on the last line of a terminal. It is also the
+// writer of the output of the verification, passed to WithOutput, so that the bar stays below the output.
+type ProgressBar struct {
+	mu       sync.Mutex
+	w        io.Writer
+	progress Progress
+	drawn    bool
+}
+
+var _ ProgressReporter = &ProgressBar{}
+
+// NewProgressBar returns a progress bar drawn on w, which must be a terminal.
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{w: w}
+}
+
+// ReportProgress redraws the bar.
+func (b *ProgressBar) ReportProgress(p Progress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.progress = p
+	b.clear()
+	b.draw()
+}
+
+// Write writes output above the bar.
+func (b *ProgressBar) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clear()
+	n, err := b.w.Write(p)
+	if err == nil && bytes.HasSuffix(p, []byte("\n")) {
+		b.draw()
+	}
+	return n, err
+}
+
+// Done removes the bar, once the verification is done.
+func (b *ProgressBar) Done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clear()
+	b.progress = Progress{}
+}
+
+// clear erases the bar, if drawn.
+func (b *ProgressBar) clear() {
+	if b.drawn {
+		fmt.Fprint(b.w, "\r\033[K")
+		b.drawn = false
+	}
+}
+
+// draw draws the bar, once resources were discovered.
+func (b *ProgressBar) draw() {
+	p := b.progress
+	if p.Discovered == 0 {
+		return
+	}
+	filled := progressBarWidth * p.Checked / p.Discovered
+	line := fmt.Sprintf("[%s%s] %d/%d resources checked",
+		strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), p.Checked, p.Discovered)
+	if p.Kind != "" {
+		line += fmt.Sprintf(", checking %s %s/%s", p.Kind, p.Namespace, p.Name)
+	}
+	fmt.Fprint(b.w, line)
+	b.drawn = true
+}
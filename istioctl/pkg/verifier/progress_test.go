@@ -0,0 +1,73 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWithProgress(t *testing.T) {
+	var reported []Progress
+	v := &StatusVerifier{}
+	WithOutput(io.Discard)(v)
+	WithProgress(ProgressReporterFunc(func(p Progress) {
+		reported = append(reported, p)
+	}))(v)
+
+	// Rendered manifests are discovered at once.
+	v.resourcesDiscovered(2)
+	v.resourceStarted("Deployment", "istiod", "istio-system")
+	v.resourceChecked()
+	v.resourceStarted("Service", "istiod", "istio-system")
+	v.resourceChecked()
+	// Installation files are discovered as they are read.
+	v.resourceStarted("ConfigMap", "istio", "istio-system")
+	v.resourceChecked()
+
+	assert.Equal(t, reported, []Progress{
+		{Discovered: 2},
+		{Discovered: 2, Kind: "Deployment", Name: "istiod", Namespace: "istio-system"},
+		{Discovered: 2, Checked: 1},
+		{Discovered: 2, Checked: 1, Kind: "Service", Name: "istiod", Namespace: "istio-system"},
+		{Discovered: 2, Checked: 2},
+		{Discovered: 3, Checked: 2, Kind: "ConfigMap", Name: "istio", Namespace: "istio-system"},
+		{Discovered: 3, Checked: 3},
+	})
+}
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	b := NewProgressBar(&out)
+	// Nothing is drawn until resources are discovered.
+	b.ReportProgress(Progress{})
+	assert.Equal(t, out.String(), "")
+
+	b.ReportProgress(Progress{Discovered: 3, Checked: 1, Kind: "Deployment", Name: "istiod", Namespace: "istio-system"})
+	bar := "[==========                    ] 1/3 resources checked, checking Deployment istio-system/istiod"
+	assert.Equal(t, out.String(), bar)
+
+	out.Reset()
+	_, err := b.Write([]byte("✔ Deployment: istiod.istio-system checked successfully\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, out.String(), "\r\033[K✔ Deployment: istiod.istio-system checked successfully\n"+bar)
+
+	out.Reset()
+	b.Done()
+	assert.Equal(t, out.String(), "\r\033[K")
+}
@@ -0,0 +1,79 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// DefaultQPS and DefaultBurst are the client-side rate limit of the requests of the verifier by default, that of
+	// client-go.
+	DefaultQPS   = rest.DefaultQPS
+	DefaultBurst = rest.DefaultBurst
+)
+
+// WithClientRateLimit limits the requests of the verifier to the API server to qps per second on average, with
+// bursts of up to burst requests, so that it can be slowed down on shared clusters whose priority and fairness
+// throttles it, or sped up on dedicated ones. A negative qps disables the client-side rate limiting, leaving the
+// API server as the only limit. Zero keeps the rate limit of the kubeconfig client.
+func WithClientRateLimit(qps float32, burst int) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.qps = qps
+		s.burst = burst
+	}
+}
+
+// rateLimitedClientConfig sets the client-side rate limit of the verifier on the config of its clients.
+type rateLimitedClientConfig struct {
+	config clientcmd.ClientConfig
+	qps    float32
+	burst  int
+}
+
+var _ clientcmd.ClientConfig = &rateLimitedClientConfig{}
+
+// newRateLimitedClientConfig wraps the client config of the verifier.
+func newRateLimitedClientConfig(config clientcmd.ClientConfig, v *StatusVerifier) clientcmd.ClientConfig {
+	return &rateLimitedClientConfig{config: config, qps: v.qps, burst: v.burst}
+}
+
+func (c *rateLimitedClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.config.RawConfig()
+}
+
+func (c *rateLimitedClientConfig) Namespace() (string, bool, error) {
+	return c.config.Namespace()
+}
+
+func (c *rateLimitedClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.config.ConfigAccess()
+}
+
+func (c *rateLimitedClientConfig) ClientConfig() (*rest.Config, error) {
+	config, err := c.config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.qps != 0 {
+		config.QPS = c.qps
+	}
+	if c.burst > 0 {
+		config.Burst = c.burst
+	}
+	return config, nil
+}
@@ -0,0 +1,52 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestWithClientRateLimit(t *testing.T) {
+	raw := clientcmdapi.NewConfig()
+	raw.Clusters["cluster"] = &clientcmdapi.Cluster{Server: "https://cluster.example.com"}
+	raw.Contexts["context"] = &clientcmdapi.Context{Cluster: "cluster"}
+	raw.CurrentContext = "context"
+	kubeconfig := clientcmd.NewDefaultClientConfig(*raw, &clientcmd.ConfigOverrides{})
+	cases := []struct {
+		name  string
+		qps   float32
+		burst int
+		want  []float32
+	}{
+		// client-go applies its default rate limit to the clients of configs without any.
+		{name: "client-go limit", want: []float32{0, 0}},
+		{name: "tuned", qps: 2, burst: 5, want: []float32{2, 5}},
+		{name: "disabled", qps: -1, want: []float32{-1, 0}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &StatusVerifier{}
+			WithClientRateLimit(tt.qps, tt.burst)(v)
+			config, err := newRateLimitedClientConfig(kubeconfig, v).ClientConfig()
+			assert.NoError(t, err)
+			assert.Equal(t, []float32{config.QPS, float32(config.Burst)}, tt.want)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Report is a report of the results of the verification written to a file, as requested with --report
+// <format>=<path>.
+type Report struct {
+	Format string
+	Path   string
+}
+
+// reportWriters write the results of the verification in each report format, keyed by format. The error of
+// the verification, if any, is reported alongside the results.
+var reportWriters = map[string]func(w io.Writer, results []CheckResult, verifyErr error) error{
+	"html":  WriteHTML,
+	"junit": WriteJUnit,
+	"sarif": WriteSARIF,
+}
+
+// ReportFormats returns the sorted names of the report formats.
+func ReportFormats() []string {
+	formats := make([]string, 0, len(reportWriters))
+	for f := range reportWriters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ParseReport parses a report of the form <format>=<path>.
+func ParseReport(spec string) (Report, error) {
+	format, path, ok := strings.Cut(spec, "=")
+	if !ok || path == "" {
+		return Report{}, fmt.Errorf("invalid report %q, expected <format>=<path>", spec)
+	}
+	if _, f := reportWriters[format]; !f {
+		return Report{}, fmt.Errorf("unknown report format %q, valid formats are %v", format, ReportFormats())
+	}
+	return Report{Format: format, Path: path}, nil
+}
+
+// WithReports writes the reports once the verification is done, whether it passed or not.
+func WithReports(reports ...Report) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.reports = append(s.reports, reports...)
+	}
+}
+
+func (v *StatusVerifier) writeReports(verifyErr error) error {
+	for _, r := range v.reports {
+		write, f := reportWriters[r.Format]
+		if !f {
+			return fmt.Errorf("unknown report format %q, valid formats are %v", r.Format, ReportFormats())
+		}
+		out, err := os.Create(r.Path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s report: %v", r.Format, err)
+		}
+		err = write(out, v.Results(), verifyErr)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s report %s: %v", r.Format, r.Path, err)
+		}
+		v.logf(VerbosityNormal, "Wrote %s report to %s", r.Format, r.Path)
+	}
+	return nil
+}
+
+// junitSuiteName is the name of the test suite of the JUnit report.
+const junitSuiteName = "istioctl verify-install"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report, with a test case per check named after the checked
+// resource and classed by its kind. Warnings pass, with their reason as the output of the test case. An error
+// of the verification not attributed to any check, such as a manifest which could not be read, fails an
+// additional test case so that CI dashboards do not show a failed verification as green.
+func WriteJUnit(w io.Writer, results []CheckResult, verifyErr error) error {
+	suite := junitTestSuite{Name: junitSuiteName}
+	for _, r := range results {
+		name := r.Name
+		if r.Namespace != "" {
+			name = fmt.Sprintf("%s.%s", r.Name, r.Namespace)
+		}
+		tc := junitTestCase{ClassName: r.Kind, Name: name}
+		switch r.Status {
+		case checkFailed.String():
+			tc.Failure = &junitFailure{Message: r.Reason, Type: r.Status, Text: r.Reason}
+			suite.Failures++
+		case checkWarning.String():
+			tc.SystemOut = "warning: " + r.Reason
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	if verifyErr != nil && suite.Failures == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			ClassName: "Verification",
+			Name:      "verify-install",
+			Failure:   &junitFailure{Message: verifyErr.Error(), Type: checkFailed.String(), Text: verifyErr.Error()},
+		})
+		suite.Failures++
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{
+		Name:     junitSuiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
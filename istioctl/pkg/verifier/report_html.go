@@ -0,0 +1,114 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"html/template"
+	"io"
+	"sort"
+)
+
+// htmlReportTemplate renders a standalone report, with no external stylesheet or script, so that it can be
+// attached to a change ticket as a single file. Components with failed checks are expanded.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>istioctl verify-install report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+details { margin: 0.5em 0; border: 1px solid #ccc; border-radius: 4px; padding: 0.5em; }
+summary { cursor: pointer; font-weight: bold; }
+table { border-collapse: collapse; width: 100%; margin-top: 0.5em; }
+th, td { text-align: left; padding: 0.25em 0.5em; border-bottom: 1px solid #eee; vertical-align: top; }
+pre { margin: 0; white-space: pre-wrap; }
+.passed { color: #2e7d32; }
+.warning { color: #ef6c00; }
+.failed { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>istioctl verify-install report</h1>
+<p>{{.Checked}} checks: <span class="passed">{{.Passed}} passed</span>, <span class="warning">{{.Warnings}} warnings</span>, <span class="failed">{{.Failed}} failed</span></p>
+{{- if .Error}}
+<p class="failed">Verification failed: {{.Error}}</p>
+{{- end}}
+{{- range .Components}}
+<details{{if .Failed}} open{{end}}>
+<summary>{{.Component}}: {{.Checked}} checked, <span class="passed">{{.Passed}} passed</span>, <span class="warning">{{.Warnings}} warnings</span>, <span class="failed">{{.Failed}} failed</span></summary>
+<table>
+<tr><th>Status</th><th>Kind</th><th>Name</th><th>Namespace</th><th>Reason</th></tr>
+{{- range .Checks}}
+<tr class="{{.Status}}"><td>{{.Status}}</td><td>{{.Kind}}</td><td>{{.Name}}</td><td>{{.Namespace}}</td><td><pre>{{.Reason}}</pre></td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlReport struct {
+	ComponentSummary
+	Error      string
+	Components []htmlComponent
+}
+
+type htmlComponent struct {
+	ComponentSummary
+	Checks []CheckResult
+}
+
+// WriteHTML writes the results as a standalone HTML report, with a collapsible section per Istio component
+// listing its checks and the reasons of the failed and warned ones, such as the differences found between
+// the installation and the cluster.
+func WriteHTML(w io.Writer, results []CheckResult, verifyErr error) error {
+	report := htmlReport{}
+	byName := map[string]*htmlComponent{}
+	for _, r := range results {
+		n := componentName(r.Component)
+		c, f := byName[n]
+		if !f {
+			c = &htmlComponent{ComponentSummary: ComponentSummary{Component: n}}
+			byName[n] = c
+		}
+		c.Checks = append(c.Checks, r)
+		for _, s := range []*ComponentSummary{&c.ComponentSummary, &report.ComponentSummary} {
+			s.Checked++
+			switch r.Status {
+			case checkPassed.String():
+				s.Passed++
+			case checkWarning.String():
+				s.Warnings++
+			case checkFailed.String():
+				s.Failed++
+			}
+		}
+	}
+	for _, c := range byName {
+		report.Components = append(report.Components, *c)
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		a, b := report.Components[i].Component, report.Components[j].Component
+		if oa, ob := componentOrder(a), componentOrder(b); oa != ob {
+			return oa < ob
+		}
+		return a < b
+	})
+	if verifyErr != nil {
+		report.Error = verifyErr.Error()
+	}
+	return htmlReportTemplate.Execute(w, report)
+}
@@ -0,0 +1,92 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"istio.io/istio/istioctl/pkg/writer/sarif"
+	"istio.io/istio/pkg/url"
+)
+
+// sarifRulePrefix prefixes the ids of the SARIF rules of the checks, to keep them apart from those of istioctl
+// analyze in the dashboards ingesting both.
+const sarifRulePrefix = "verify-install/"
+
+// sarifLevels are the SARIF levels of the results of the checks which found a problem. Passed checks are not
+// findings.
+var sarifLevels = map[string]string{
+	checkFailed.String():  sarif.LevelError,
+	checkWarning.String(): sarif.LevelWarning,
+}
+
+// WriteSARIF writes the failed and warned checks as a SARIF log, so that verification failures flow into code
+// scanning dashboards. Each kind of checked resource is a rule, and the location of a result is the checked
+// resource of the cluster. An error of the verification not attributed to any check is reported against an
+// additional rule, as in the JUnit report.
+func WriteSARIF(w io.Writer, results []CheckResult, verifyErr error) error {
+	driver := sarif.Driver{Name: "istioctl verify-install", InformationURI: url.DocsURL}
+	rules := map[string]bool{}
+	addRule := func(id, description string) {
+		if rules[id] {
+			return
+		}
+		rules[id] = true
+		driver.Rules = append(driver.Rules, sarif.Rule{
+			ID:                   id,
+			ShortDescription:     &sarif.Message{Text: description},
+			DefaultConfiguration: &sarif.Configuration{Level: sarif.LevelError},
+		})
+	}
+	var findings []sarif.Result
+	failed := false
+	for _, r := range results {
+		level, f := sarifLevels[r.Status]
+		if !f {
+			continue
+		}
+		failed = failed || r.Status == checkFailed.String()
+		id := sarifRuleID(r.Kind)
+		addRule(id, fmt.Sprintf("%s of the Istio installation is healthy", r.Kind))
+		findings = append(findings, sarif.Result{
+			RuleID:    id,
+			Level:     level,
+			Message:   sarif.Message{Text: r.Reason},
+			Locations: []sarif.Location{{LogicalLocations: []sarif.LogicalLocation{sarifResource(r)}}},
+		})
+	}
+	if verifyErr != nil && !failed {
+		id := sarifRulePrefix + "verification"
+		addRule(id, "The Istio installation can be verified")
+		findings = append(findings, sarif.Result{RuleID: id, Level: sarif.LevelError, Message: sarif.Message{Text: verifyErr.Error()}})
+	}
+	return sarif.NewLog(driver, findings).Write(w)
+}
+
+// sarifRuleID returns the id of the rule of the checks of a kind, such as verify-install/gateway-proxy.
+func sarifRuleID(kind string) string {
+	return sarifRulePrefix + strings.ToLower(strings.ReplaceAll(kind, " ", "-"))
+}
+
+// sarifResource returns the location of the resource checked by a check.
+func sarifResource(r CheckResult) sarif.LogicalLocation {
+	name := r.Kind + "/" + r.Name
+	if r.Namespace != "" {
+		name = r.Kind + "/" + r.Namespace + "/" + r.Name
+	}
+	return sarif.LogicalLocation{Name: r.Name, FullyQualifiedName: name, Kind: "resource"}
+}
@@ -0,0 +1,159 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/istioctl/pkg/writer/sarif"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestParseReport(t *testing.T) {
+	r, err := ParseReport("junit=out/report.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, r, Report{Format: "junit", Path: "out/report.xml"})
+
+	for _, spec := range []string{"junit", "junit=", "pdf=report.pdf"} {
+		if _, err := ParseReport(spec); err == nil {
+			t.Errorf("expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	results := []CheckResult{
+		{Kind: "Deployment", Name: "istiod", Namespace: "istio-system", Status: "passed"},
+		{Kind: "Namespace", Name: "default", Status: "warning", Reason: "namespace is wide open"},
+		{Kind: "Job", Name: "setup", Namespace: "istio-system", Status: "failed", Reason: "job is not complete"},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteJUnit(&out, results, fmt.Errorf("1 error occurred")))
+	assert.Equal(t, out.String(), `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="istioctl verify-install" tests="3" failures="1">
+  <testsuite name="istioctl verify-install" tests="3" failures="1">
+    <testcase classname="Deployment" name="istiod.istio-system"></testcase>
+    <testcase classname="Namespace" name="default">
+      <system-out>warning: namespace is wide open</system-out>
+    </testcase>
+    <testcase classname="Job" name="setup.istio-system">
+      <failure message="job is not complete" type="failed">job is not complete</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`)
+}
+
+func TestWriteJUnitUnattributedError(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteJUnit(&out, nil, fmt.Errorf("could not load IstioOperator from cluster")))
+	if !strings.Contains(out.String(), `<failure message="could not load IstioOperator from cluster" type="failed">`) {
+		t.Errorf("expected the error of the verification to fail a test case, got %s", out.String())
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	var logs bytes.Buffer
+	path := filepath.Join(t.TempDir(), "report.xml")
+	v := &StatusVerifier{
+		logger:        clog.NewConsoleLogger(&logs, &logs, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+		reports:       []Report{{Format: "junit", Path: path}},
+	}
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	assert.NoError(t, v.writeReports(nil))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	if !strings.Contains(string(content), `<testcase classname="Deployment" name="istiod.istio-system">`) {
+		t.Errorf("unexpected report %s", content)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	results := []CheckResult{
+		{Kind: "Deployment", Name: "istiod", Namespace: "istio-system", Status: "passed", Component: "Pilot"},
+		{Kind: "Namespace", Name: "default", Status: "warning", Reason: "namespace is wide open"},
+		{
+			Kind: "Deployment", Name: "istio-ingressgateway", Namespace: "istio-system", Status: "failed",
+			Reason: "env PILOT_ENABLE_<X> differs", Component: "IngressGateways",
+		},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteHTML(&out, results, fmt.Errorf("Istio installation failed")))
+	report := out.String()
+	for _, want := range []string{
+		"3 checks: <span class=\"passed\">1 passed</span>",
+		"<p class=\"failed\">Verification failed: Istio installation failed</p>",
+		"<details>\n<summary>istiod: 1 checked",
+		"<details open>\n<summary>ingress gateways: 1 checked",
+		"<details>\n<summary>other: 1 checked",
+		// Reasons are escaped.
+		"<pre>env PILOT_ENABLE_&lt;X&gt; differs</pre>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("missing %q in report:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "istiod:") > strings.Index(report, "ingress gateways:") ||
+		strings.Index(report, "ingress gateways:") > strings.Index(report, "other:") {
+		t.Errorf("expected the components in summary order:\n%s", report)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	results := []CheckResult{
+		{Kind: "Deployment", Name: "istiod", Namespace: "istio-system", Status: "passed"},
+		{Kind: "Gateway proxy", Name: "istio-ingressgateway", Namespace: "istio-system", Status: "warning", Reason: "config is stale"},
+		{Kind: "Job", Name: "setup", Namespace: "istio-system", Status: "failed", Reason: "job is not complete"},
+		{Kind: "Job", Name: "cleanup", Namespace: "istio-system", Status: "failed", Reason: "job is not complete"},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteSARIF(&out, results, fmt.Errorf("Istio installation failed")))
+	var log sarif.Log
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &log))
+	assert.Equal(t, log.Version, sarif.Version)
+	run := log.Runs[0]
+	var rules []string
+	for _, r := range run.Tool.Driver.Rules {
+		rules = append(rules, r.ID)
+	}
+	assert.Equal(t, rules, []string{"verify-install/gateway-proxy", "verify-install/job"})
+	assert.Equal(t, len(run.Results), 3)
+	assert.Equal(t, run.Results[0].Level, sarif.LevelWarning)
+	assert.Equal(t, run.Results[1], sarif.Result{
+		RuleID:  "verify-install/job",
+		Level:   sarif.LevelError,
+		Message: sarif.Message{Text: "job is not complete"},
+		Locations: []sarif.Location{{LogicalLocations: []sarif.LogicalLocation{{
+			Name: "setup", FullyQualifiedName: "Job/istio-system/setup", Kind: "resource",
+		}}}},
+	})
+}
+
+func TestWriteSARIFUnattributedError(t *testing.T) {
+	var out bytes.Buffer
+	assert.NoError(t, WriteSARIF(&out, nil, fmt.Errorf("could not load IstioOperator from cluster")))
+	if !strings.Contains(out.String(), `"ruleId": "verify-install/verification"`) {
+		t.Errorf("expected the error of the verification to be a result, got %s", out.String())
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"time"
+
+	"istio.io/istio/istioctl/pkg/revisions"
+)
+
+// VerificationResult is the outcome of a verification, for programs embedding the verifier to build their own
+// reporting from.
+type VerificationResult struct {
+	// Checks are the results of the checks, in the order they were checked.
+	Checks []CheckResult `json:"checks"`
+	// Counts are the number of checks of each kind.
+	Counts map[string]int `json:"counts"`
+	// Warnings are the checks which warned, failing the verification only in strict setups.
+	Warnings []CheckResult `json:"warnings,omitempty"`
+	// Failures are the checks which failed.
+	Failures []CheckResult `json:"failures,omitempty"`
+	// CustomResourceDefinitions, IstioDeployments and DaemonSets are the number of installed resources of each
+	// type found in the cluster, as printed at the end of the verification.
+	CustomResourceDefinitions int `json:"customResourceDefinitions"`
+	IstioDeployments          int `json:"istioDeployments"`
+	DaemonSets                int `json:"daemonSets"`
+	// Components are the counts of the checks of each Istio component.
+	Components []ComponentSummary `json:"components"`
+	// InjectionCoverage is how much of the data plane is in the mesh, unless no installation was found.
+	InjectionCoverage *InjectionCoverage `json:"injectionCoverage,omitempty"`
+	// Revision is the revision of the control plane which was verified.
+	Revision string `json:"revision"`
+	// Sources are what the installation was verified against: IstioOperators, installation files or Helm values.
+	Sources []string `json:"sources,omitempty"`
+	// HealthScore is the health score of the installation, from 0 to 100.
+	HealthScore int `json:"healthScore"`
+	// Start is when the verification started.
+	Start time.Time `json:"start"`
+	// Duration is how long the verification took.
+	Duration time.Duration `json:"duration"`
+	// CheckDurations are how long each optional check took, keyed by the name of the check.
+	CheckDurations map[string]time.Duration `json:"checkDurations,omitempty"`
+}
+
+// Passed returns whether no check failed.
+func (r *VerificationResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// result builds the result of the last verification.
+func (v *StatusVerifier) result(start time.Time) *VerificationResult {
+	r := &VerificationResult{
+		Checks:                    v.Results(),
+		Counts:                    map[string]int{},
+		CustomResourceDefinitions: v.crdCount,
+		IstioDeployments:          v.istioDeploymentCount,
+		DaemonSets:                v.daemonSetCount,
+		Components:                componentSummaries(v.results),
+		HealthScore:               v.HealthScore(),
+		InjectionCoverage:         v.coverage,
+		Revision:                  revisions.Normalize(v.controlPlaneOpts.Revision),
+		Sources:                   v.sources,
+		Start:                     start,
+		Duration:                  time.Since(start),
+	}
+	for _, c := range r.Checks {
+		r.Counts[c.Kind]++
+		switch c.Status {
+		case checkWarning.String():
+			r.Warnings = append(r.Warnings, c)
+		case checkFailed.String():
+			r.Failures = append(r.Failures, c)
+		}
+	}
+	if len(v.checkDurations) > 0 {
+		r.CheckDurations = make(map[string]time.Duration, len(v.checkDurations))
+		for name, d := range v.checkDurations {
+			r.CheckDurations[name] = d
+		}
+	}
+	return r
+}
@@ -0,0 +1,60 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestResult(t *testing.T) {
+	var logs bytes.Buffer
+	v := &StatusVerifier{
+		logger:         clog.NewConsoleLogger(&logs, &logs, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+		checkDurations: map[string]time.Duration{"locality": time.Second},
+	}
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportSuccess("Deployment", "istio-ingressgateway", "istio-system")
+	v.reportWarning("Namespace", "default", "", fmt.Errorf("namespace is wide open"))
+	v.reportFailure("Job", "setup", "istio-system", fmt.Errorf("job is not complete"))
+	v.crdCount, v.istioDeploymentCount = 12, 2
+
+	start := time.Now().Add(-time.Minute)
+	r := v.result(start)
+	assert.Equal(t, len(r.Checks), 4)
+	assert.Equal(t, r.Counts, map[string]int{"Deployment": 2, "Namespace": 1, "Job": 1})
+	assert.Equal(t, r.Warnings, []CheckResult{
+		coded(CheckResult{Kind: "Namespace", Name: "default", Status: "warning", Reason: "namespace is wide open"}, CodeCheckFailed),
+	})
+	assert.Equal(t, r.Failures, []CheckResult{
+		coded(CheckResult{Kind: "Job", Name: "setup", Namespace: "istio-system", Status: "failed", Reason: "job is not complete"}, CodeCheckFailed),
+	})
+	assert.Equal(t, r.CustomResourceDefinitions, 12)
+	assert.Equal(t, r.IstioDeployments, 2)
+	assert.Equal(t, r.HealthScore, v.HealthScore())
+	assert.Equal(t, r.CheckDurations, map[string]time.Duration{"locality": time.Second})
+	assert.Equal(t, r.Start, start)
+	assert.Equal(t, r.Passed(), false)
+	if r.Duration < time.Minute {
+		t.Errorf("expected the duration to run from the start, got %v", r.Duration)
+	}
+}
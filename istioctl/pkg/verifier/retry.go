@@ -0,0 +1,101 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultRetries is how many times a read of an installed resource failing with a transient error is retried
+	// by default.
+	DefaultRetries = 3
+	// DefaultRetryBackoff is the delay before the first retry by default. It doubles on every retry.
+	DefaultRetryBackoff = 500 * time.Millisecond
+
+	// maxRetryBackoff caps the delay between two retries.
+	maxRetryBackoff = 10 * time.Second
+)
+
+// transientInternalErrors are the messages of the internal errors of the API server which are transient.
+var transientInternalErrors = []string{
+	"etcdserver: leader changed",
+	"etcdserver: request timed out",
+	"etcdserver: too many requests",
+	"failed calling webhook",
+}
+
+// WithRetry retries the reads of the installed resources which fail with a transient error, such as throttling,
+// an etcd leader change or a failing webhook, up to retries times with an exponential backoff starting at
+// backoff. Zero retries, the default, fails the check of a resource on its first error.
+func WithRetry(retries int, backoff time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.retries = retries
+		s.retryBackoff = backoff
+	}
+}
+
+// isRetryable reports whether err is a transient error of the API server, which a retry of the call may not get.
+// Denied access, missing resources and the expiry of the timeouts of the verification are not.
+func isRetryable(err error) bool {
+	if err == nil || isTimeout(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if kerrors.IsTooManyRequests(err) || kerrors.IsServerTimeout(err) || kerrors.IsTimeout(err) ||
+		kerrors.IsServiceUnavailable(err) {
+		return true
+	}
+	if kerrors.IsInternalError(err) {
+		for _, msg := range transientInternalErrors {
+			if strings.Contains(err.Error(), msg) {
+				return true
+			}
+		}
+		return false
+	}
+	return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err)
+}
+
+// withRetry calls call until it succeeds, fails with an error which is not retryable or the retries of the
+// verifier are exhausted, and returns its last error. It stops waiting for the next retry once ctx is done.
+func (v *StatusVerifier) withRetry(ctx context.Context, what string, call func() error) error {
+	backoff := wait.Backoff{Duration: v.retryBackoff, Factor: 2, Jitter: 0.1, Steps: v.retries, Cap: maxRetryBackoff}
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if attempt >= v.retries || !isRetryable(err) {
+			return err
+		}
+		delay := backoff.Step()
+		v.logf(VerbosityVerbose, "Retrying the read of %s in %v after a transient error: %v", what, delay.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// resourceRef names a resource in the messages of the retries, as the results of its check do.
+func resourceRef(kind, name, namespace string) string {
+	return fmt.Sprintf("%s: %s.%s", kind, name, namespace)
+}
@@ -0,0 +1,87 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"throttled", kerrors.NewTooManyRequests("slow down", 1), true},
+		{"server timeout", kerrors.NewServerTimeout(deployments, "get", 1), true},
+		{"unavailable", kerrors.NewServiceUnavailable("unavailable"), true},
+		{"etcd leader change", kerrors.NewInternalError(errors.New("etcdserver: leader changed")), true},
+		{"webhook", kerrors.NewInternalError(errors.New(`failed calling webhook "validation.istio.io"`)), true},
+		{"connection reset", fmt.Errorf("get: %w", syscall.ECONNRESET), true},
+		{"other internal error", kerrors.NewInternalError(errors.New("boom")), false},
+		{"not found", kerrors.NewNotFound(deployments, "istiod"), false},
+		{"forbidden", kerrors.NewForbidden(deployments, "istiod", errors.New("denied")), false},
+		{"timed out", context.DeadlineExceeded, false},
+		{"canceled", context.Canceled, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, isRetryable(tt.err), tt.want)
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	throttled := kerrors.NewTooManyRequests("slow down", 1)
+	cases := []struct {
+		name      string
+		retries   int
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"succeeds after transient errors", 3, []error{throttled, throttled, nil}, 3, nil},
+		{"gives up after the retries", 2, []error{throttled, throttled, throttled, nil}, 3, throttled},
+		{"does not retry permanent errors", 3, []error{errNotRetryable, nil}, 1, errNotRetryable},
+		{"does not retry by default", 0, []error{throttled, nil}, 1, throttled},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &StatusVerifier{}
+			WithRetry(tt.retries, time.Millisecond)(v)
+			calls := 0
+			err := v.withRetry(context.Background(), "Deployment: istiod.istio-system", func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			assert.Equal(t, calls, tt.wantCalls)
+			assert.Equal(t, err, tt.wantErr)
+		})
+	}
+}
+
+var errNotRetryable = errors.New("not retryable")
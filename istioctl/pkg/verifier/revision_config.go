@@ -0,0 +1,186 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/label"
+	"istio.io/istio/istioctl/pkg/revisions"
+	istioctlutil "istio.io/istio/istioctl/pkg/util"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/security/pkg/pki/ca"
+)
+
+const (
+	// injectorConfigMapName is the name of the sidecar injector ConfigMap of the default revision.
+	injectorConfigMapName = "istio-sidecar-injector"
+	// rootCertConfigMapName is the ConfigMap istiod publishes the root certificate of the mesh in.
+	rootCertConfigMapName = "istio-ca-root-cert"
+)
+
+// verifyRevisionConfig checks that the ConfigMaps and Secrets of the revision under verification exist and agree
+// with each other: the mesh config and sidecar injector ConfigMaps are labeled for the revision, the injector
+// renders the revision, the discovery address of the mesh config is a Service of the cluster, and the root
+// certificate istiod publishes is that of its CA. Partial deletions leave a revision which looks installed but
+// cannot inject or sign certificates.
+func (v *StatusVerifier) verifyRevisionConfig(ctx context.Context) error {
+	revision := revisions.Normalize(v.controlPlaneOpts.Revision)
+	suffix := ""
+	if revision != revisions.Normalize("") {
+		suffix = "-" + revision
+	}
+	multiErr := &multierror.Error{}
+	report := func(kind, name string, err error) {
+		if err != nil {
+			v.reportFailure(kind, name, v.istioNamespace, err)
+			multiErr = multierror.Append(multiErr, fmt.Errorf("%s %s: %v", strings.ToLower(kind), name, err))
+			return
+		}
+		v.reportSuccess(kind, name, v.istioNamespace)
+	}
+	meshName := istioctlutil.DefaultMeshConfigMapName + suffix
+	report("ConfigMap", meshName, v.verifyRevisionMeshConfig(ctx, meshName, revision))
+	injectorName := injectorConfigMapName + suffix
+	report("ConfigMap", injectorName, v.verifyRevisionInjectorConfig(ctx, injectorName, revision))
+	if name, err := v.verifyRevisionCA(ctx); name != "" {
+		report("Secret", name, err)
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// revisionConfigMap reads a ConfigMap of the revision, which must have the keys and the revision label.
+func (v *StatusVerifier) revisionConfigMap(ctx context.Context, name, revision string, keys ...string) (*corev1.ConfigMap, error) {
+	cm, err := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("the configmap of revision %s is missing: %w", revision, err)
+	}
+	for _, key := range keys {
+		if _, f := cm.Data[key]; !f {
+			return nil, withCode(CodeRevisionInconsistent, fmt.Errorf("missing key %q", key))
+		}
+	}
+	if rev, f := cm.Labels[label.IoIstioRev.Name]; f && rev != revision {
+		return nil, withCode(CodeRevisionInconsistent, fmt.Errorf("labeled for revision %s, not %s", rev, revision))
+	}
+	return cm, nil
+}
+
+// verifyRevisionMeshConfig checks the mesh config ConfigMap of the revision, and that the discovery address of its
+// proxies is a Service which exists, when it is one of the cluster.
+func (v *StatusVerifier) verifyRevisionMeshConfig(ctx context.Context, name, revision string) error {
+	cm, err := v.revisionConfigMap(ctx, name, revision, istioctlutil.ConfigMapKey)
+	if err != nil {
+		return err
+	}
+	mc, err := mesh.ApplyMeshConfigDefaults(cm.Data[istioctlutil.ConfigMapKey])
+	if err != nil {
+		return withCode(CodeRevisionInconsistent, fmt.Errorf("invalid mesh config: %v", err))
+	}
+	address := mc.GetDefaultConfig().GetDiscoveryAddress()
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" {
+		return nil
+	}
+	_, err = v.client.Kube().CoreV1().Services(parts[1]).Get(ctx, parts[0], metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return withCode(CodeRevisionInconsistent, fmt.Errorf("discovery address %s of the proxies is not a Service of the cluster", address))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get the discovery service: %v", err)
+	}
+	return nil
+}
+
+// verifyRevisionInjectorConfig checks the sidecar injector ConfigMap of the revision renders the revision.
+func (v *StatusVerifier) verifyRevisionInjectorConfig(ctx context.Context, name, revision string) error {
+	cm, err := v.revisionConfigMap(ctx, name, revision, "config", istioctlutil.ValuesConfigMapKey)
+	if err != nil {
+		return err
+	}
+	var values struct {
+		Revision string `json:"revision"`
+	}
+	if err := json.Unmarshal([]byte(cm.Data[istioctlutil.ValuesConfigMapKey]), &values); err != nil {
+		return withCode(CodeRevisionInconsistent, fmt.Errorf("invalid values: %v", err))
+	}
+	if got := revisions.Normalize(values.Revision); got != revision {
+		return withCode(CodeRevisionInconsistent, fmt.Errorf("injects the proxies of revision %s, not %s", got, revision))
+	}
+	return nil
+}
+
+// verifyRevisionCA checks that the CA of istiod has its Secret, either plugged in cacerts or self-signed, and that
+// the root certificate istiod publishes is that of the Secret. It returns the name of the Secret checked, or none
+// if istiod is not the CA of the mesh.
+func (v *StatusVerifier) verifyRevisionCA(ctx context.Context) (string, error) {
+	deployment, err := v.client.Kube().AppsV1().Deployments(v.istioNamespace).Get(ctx, v.istiodDeploymentName(), metav1.GetOptions{})
+	if err != nil {
+		// Without istiod, as in remote clusters, there is no CA to check.
+		return "", nil
+	}
+	if container := findContainer(deployment.Spec.Template.Spec.Containers, istiodContainer); container != nil {
+		for _, env := range container.Env {
+			if env.Name == "EXTERNAL_CA" && env.Value != "" {
+				return "", nil
+			}
+			if enabled, err := strconv.ParseBool(env.Value); env.Name == "ENABLE_CA_SERVER" && err == nil && !enabled {
+				return "", nil
+			}
+		}
+	}
+	secrets := v.client.Kube().CoreV1().Secrets(v.istioNamespace)
+	name, rootKey := ca.ExternalCASecret, ca.RootCertFile
+	secret, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		name, rootKey = ca.CASecret, ca.CACertFile
+		secret, err = secrets.Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return name, fmt.Errorf("neither %s nor %s exists, istiod cannot sign the certificates of the workloads: %w",
+				ca.ExternalCASecret, ca.CASecret, err)
+		}
+	}
+	if err != nil {
+		return name, err
+	}
+	root := bytes.TrimSpace(secret.Data[rootKey])
+	if len(root) == 0 {
+		return name, withCode(CodeRevisionInconsistent, fmt.Errorf("missing key %q", rootKey))
+	}
+	cm, err := v.client.Kube().CoreV1().ConfigMaps(v.istioNamespace).Get(ctx, rootCertConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return name, fmt.Errorf("the root certificate of the mesh is not published: %w", err)
+	}
+	if !strings.Contains(cm.Data[constants.CACertNamespaceConfigMapDataName], string(root)) {
+		return name, withCode(CodeRevisionInconsistent, fmt.Errorf("the root certificate in configmap %s is not that of the CA", rootCertConfigMapName))
+	}
+	return name, nil
+}
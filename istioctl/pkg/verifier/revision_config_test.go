@@ -0,0 +1,162 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const testRootCert = "-----BEGIN CERTIFICATE-----\nroot\n-----END CERTIFICATE-----\n"
+
+// revisionObjects are the ConfigMaps, Secrets and Services of a healthy canary revision.
+func revisionObjects() map[string]runtime.Object {
+	ns := "istio-system"
+	return map[string]runtime.Object{
+		"mesh": &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-canary", Namespace: ns, Labels: map[string]string{"istio.io/rev": "canary"}},
+			Data:       map[string]string{"mesh": "defaultConfig:\n  discoveryAddress: istiod-canary.istio-system.svc:15012\n"},
+		},
+		"injector": &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector-canary", Namespace: ns, Labels: map[string]string{"istio.io/rev": "canary"}},
+			Data:       map[string]string{"config": "policy: enabled", "values": `{"revision": "canary"}`},
+		},
+		"istiod":  &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "istiod-canary", Namespace: ns}},
+		"service": &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "istiod-canary", Namespace: ns}},
+		"ca": &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-ca-secret", Namespace: ns},
+			Data:       map[string][]byte{"ca-cert.pem": []byte(testRootCert)},
+		},
+		"root": &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-ca-root-cert", Namespace: ns},
+			Data:       map[string]string{"root-cert.pem": testRootCert},
+		},
+	}
+}
+
+func TestVerifyRevisionConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(objects map[string]runtime.Object)
+		want   []string
+		code   FailureCode
+	}{
+		{
+			name: "consistent",
+		},
+		{
+			name: "injector deleted",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "injector")
+			},
+			want: []string{`configmap istio-sidecar-injector-canary: the configmap of revision canary is missing`},
+			code: CodeResourceMissing,
+		},
+		{
+			name: "injector of another revision",
+			modify: func(objects map[string]runtime.Object) {
+				objects["injector"].(*corev1.ConfigMap).Data["values"] = `{"revision": ""}`
+			},
+			want: []string{"injects the proxies of revision default, not canary"},
+			code: CodeRevisionInconsistent,
+		},
+		{
+			name: "mesh config of another revision",
+			modify: func(objects map[string]runtime.Object) {
+				objects["mesh"].(*corev1.ConfigMap).Labels["istio.io/rev"] = "stable"
+			},
+			want: []string{"configmap istio-canary: labeled for revision stable, not canary"},
+			code: CodeRevisionInconsistent,
+		},
+		{
+			name: "discovery service deleted",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "service")
+			},
+			want: []string{"discovery address istiod-canary.istio-system.svc:15012 of the proxies is not a Service of the cluster"},
+			code: CodeRevisionInconsistent,
+		},
+		{
+			name: "CA secret deleted",
+			modify: func(objects map[string]runtime.Object) {
+				delete(objects, "ca")
+			},
+			want: []string{"neither cacerts nor istio-ca-secret exists"},
+			code: CodeResourceMissing,
+		},
+		{
+			name: "root certificate of another CA",
+			modify: func(objects map[string]runtime.Object) {
+				objects["root"].(*corev1.ConfigMap).Data["root-cert.pem"] = "other"
+			},
+			want: []string{"the root certificate in configmap istio-ca-root-cert is not that of the CA"},
+			code: CodeRevisionInconsistent,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objects := revisionObjects()
+			if c.modify != nil {
+				c.modify(objects)
+			}
+			var objs []runtime.Object
+			for _, o := range objects {
+				objs = append(objs, o)
+			}
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client:           kube.NewFakeClient(objs...),
+				istioNamespace:   "istio-system",
+				controlPlaneOpts: clioptions.ControlPlaneOptions{Revision: "canary"},
+				logger:           clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:    "✔",
+				failureMarker:    "✘",
+			}
+			err := v.verifyRevisionConfig(context.Background())
+			if len(c.want) == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, len(v.results), 3)
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %v", c.want)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Fatalf("expected error containing %q, got %v", want, err)
+				}
+			}
+			var failed []FailureCode
+			for _, r := range v.results {
+				if r.status == checkFailed {
+					failed = append(failed, r.code)
+				}
+			}
+			assert.Equal(t, failed, []FailureCode{c.code})
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"fmt"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Severity is the severity of a problem found by a check.
+type Severity int
+
+const (
+	// SeverityWarning problems are reported, but do not fail the verification unless it fails on warnings.
+	SeverityWarning Severity = iota + 1
+	// SeverityError problems fail the verification.
+	SeverityError
+)
+
+// Severities are the names of the severities, as accepted by ParseSeverity.
+var Severities = []string{SeverityWarning.String(), SeverityError.String()}
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// ParseSeverity parses the name of a severity.
+func ParseSeverity(name string) (Severity, error) {
+	switch name {
+	case "warning":
+		return SeverityWarning, nil
+	case "error":
+		return SeverityError, nil
+	}
+	return 0, fmt.Errorf("invalid severity %q, expected one of %v", name, Severities)
+}
+
+// WithFailOn sets the severity from which problems fail the verification. It defaults to SeverityError.
+func WithFailOn(s Severity) StatusVerifierOptions {
+	return func(v *StatusVerifier) {
+		v.failOn = s
+	}
+}
+
+// warningKinds are the kinds of the installed resources which the mesh works without, such as autoscalers.
+var warningKinds = sets.New("HorizontalPodAutoscaler", "PodDisruptionBudget")
+
+// addonNames are the names of the workloads of the optional addons, which the mesh works without.
+var addonNames = sets.New("prometheus", "grafana", "kiali", "jaeger", "zipkin", "loki")
+
+// problemSeverity returns the severity of a problem found with an installed resource.
+func problemSeverity(kind, name string) Severity {
+	if warningKinds.Contains(kind) || addonNames.Contains(name) {
+		return SeverityWarning
+	}
+	return SeverityError
+}
+
+// reportProblem reports a problem found with an installed resource according to its severity. Errors are
+// returned, to stop the verification of the installed resources, while warnings let it go on.
+func (v *StatusVerifier) reportProblem(kind, name, namespace string, err error) error {
+	err = timeoutError(err)
+	if problemSeverity(kind, name) == SeverityWarning {
+		v.reportWarning(kind, name, namespace, err)
+		return nil
+	}
+	v.reportFailure(kind, name, namespace, err)
+	return err
+}
+
+// failsOnWarnings returns whether warnings fail the verification.
+func (v *StatusVerifier) failsOnWarnings() bool {
+	return v.failOn == SeverityWarning
+}
+
+// warningCount returns the number of checks which warned.
+func (v *StatusVerifier) warningCount() int {
+	n := 0
+	for _, r := range v.results {
+		if r.status == checkWarning {
+			n++
+		}
+	}
+	return n
+}
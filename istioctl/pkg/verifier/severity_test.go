@@ -0,0 +1,84 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []Severity{SeverityWarning, SeverityError} {
+		got, err := ParseSeverity(s.String())
+		assert.NoError(t, err)
+		assert.Equal(t, got, s)
+	}
+	if _, err := ParseSeverity("info"); err == nil {
+		t.Fatal("expected an error for an unknown severity")
+	}
+}
+
+func TestReportProblem(t *testing.T) {
+	v := &StatusVerifier{}
+	WithOutput(io.Discard)(v)
+	notReady := errors.New("not ready")
+
+	assert.NoError(t, v.reportProblem("HorizontalPodAutoscaler", "istiod", "istio-system", notReady))
+	assert.NoError(t, v.reportProblem("Deployment", "kiali", "istio-system", notReady))
+	assert.Equal(t, v.reportProblem("Deployment", "istiod", "istio-system", notReady), notReady)
+
+	statuses := []checkStatus{}
+	for _, r := range v.results {
+		statuses = append(statuses, r.status)
+	}
+	assert.Equal(t, statuses, []checkStatus{checkWarning, checkWarning, checkFailed})
+}
+
+func TestReportStatusFailOn(t *testing.T) {
+	cases := []struct {
+		name    string
+		failOn  Severity
+		wantErr bool
+	}{
+		{name: "default", wantErr: false},
+		{name: "error", failOn: SeverityError, wantErr: false},
+		{name: "warning", failOn: SeverityWarning, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &StatusVerifier{istioNamespace: "istio-system", client: kube.NewFakeClient()}
+			WithOutput(io.Discard)(v)
+			WithFailOn(tc.failOn)(v)
+			v.reportSuccess("Deployment", "istiod", "istio-system")
+			assert.NoError(t, v.reportProblem("Deployment", "prometheus", "istio-system", errors.New("not ready")))
+
+			err := v.reportStatus(context.Background(), 0, 2, 0, nil)
+			if !tc.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var verr *VerificationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a VerificationError, got %v", err)
+			}
+			assert.Equal(t, verr.Class, FailureUnhealthy)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"istio.io/api/label"
+	securityv1beta1 "istio.io/api/security/v1beta1"
+	clientsecurityv1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	"istio.io/istio/pkg/ptr"
+)
+
+const (
+	// DefaultSmokeTestImage runs both the server and the client of the smoke test.
+	DefaultSmokeTestImage = "fortio/fortio:latest_release"
+	// DefaultSmokeTestTimeout is how long the smoke test is given to complete by default.
+	DefaultSmokeTestTimeout = 3 * time.Minute
+
+	// smokeTestLabel marks the namespaces created for the smoke test.
+	smokeTestLabel    = "istio.io/verify-smoke-test"
+	smokeTestServer   = "smoke-server"
+	smokeTestClient   = "smoke-client"
+	smokeTestPort     = 8080
+	smokePollInterval = 2 * time.Second
+)
+
+// xfccURI extracts the client identity from the X-Forwarded-Client-Cert header the server sidecar
+// adds to requests received over mTLS, as echoed back by the server.
+var xfccURI = regexp.MustCompile(`(?i)x-forwarded-client-cert:.*URI=(spiffe://[^;,\s"]+)`)
+
+// WithSmokeTest sets the image and timeout of the smoke-test check.
+func WithSmokeTest(image string, timeout time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.smokeTestImage = image
+		s.checks.smokeTestTimeout = timeout
+	}
+}
+
+// verifySmokeTest deploys a client and a server Job into a fresh injection enabled namespace requiring
+// mTLS, and checks that both got a sidecar and that the request of the client reached the server over
+// mTLS with the client's identity. The namespace is deleted afterwards.
+func (v *StatusVerifier) verifySmokeTest(ctx context.Context) error {
+	timeout := v.checks.smokeTestTimeout
+	if timeout == 0 {
+		timeout = DefaultSmokeTestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ns, err := v.client.Kube().CoreV1().Namespaces().Create(ctx, v.smokeTestNamespace(), metav1.CreateOptions{})
+	if err != nil {
+		return v.reportSmokeTest(fmt.Errorf("failed to create test namespace: %v", err))
+	}
+	defer func() {
+		// The test context may have expired or been canceled already, tear down with a fresh one.
+		err := v.client.Kube().CoreV1().Namespaces().Delete(context.Background(), ns.Name, metav1.DeleteOptions{})
+		if err != nil {
+			v.logger.LogAndErrorf("failed to delete smoke test namespace %s: %v", ns.Name, err)
+		}
+	}()
+
+	identity, err := v.runSmokeTest(ctx, ns.Name)
+	if err != nil {
+		return v.reportSmokeTest(err)
+	}
+	v.record("Smoke test", identity, "", checkPassed, "")
+	v.logf(VerbosityNormal, "%s Smoke test: request from %s received over mTLS checked successfully", v.successMarker, identity)
+	return nil
+}
+
+func (v *StatusVerifier) reportSmokeTest(err error) error {
+	v.record("Smoke test", "", "", checkFailed, err.Error())
+	v.logger.LogAndPrintf("%s Smoke test: %v", v.failureMarker, err)
+	return fmt.Errorf("smoke test failed: %v", err)
+}
+
+func (v *StatusVerifier) smokeTestNamespace() *corev1.Namespace {
+	labels := map[string]string{smokeTestLabel: "true"}
+	if rev := v.controlPlaneOpts.Revision; rev != "" && rev != "default" {
+		labels[label.IoIstioRev.Name] = rev
+	} else {
+		labels["istio-injection"] = "enabled"
+	}
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "istio-verify-smoke-", Labels: labels}}
+}
+
+// runSmokeTest runs the test in the namespace and returns the identity the server saw the client with.
+func (v *StatusVerifier) runSmokeTest(ctx context.Context, ns string) (string, error) {
+	image := v.checks.smokeTestImage
+	if image == "" {
+		image = DefaultSmokeTestImage
+	}
+	pa := &clientsecurityv1beta1.PeerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: ns},
+		Spec: securityv1beta1.PeerAuthentication{
+			Mtls: &securityv1beta1.PeerAuthentication_MutualTLS{Mode: securityv1beta1.PeerAuthentication_MutualTLS_STRICT},
+		},
+	}
+	if _, err := v.client.Istio().SecurityV1beta1().PeerAuthentications(ns).Create(ctx, pa, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to require mTLS: %v", err)
+	}
+	for _, sa := range []string{smokeTestServer, smokeTestClient} {
+		_, err := v.client.Kube().CoreV1().ServiceAccounts(ns).Create(ctx,
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: sa, Namespace: ns}}, metav1.CreateOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to create service account %s: %v", sa, err)
+		}
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: smokeTestServer, Namespace: ns},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": smokeTestServer},
+			Ports:    []corev1.ServicePort{{Name: "http", Port: smokeTestPort, TargetPort: intstr.FromInt(smokeTestPort)}},
+		},
+	}
+	if _, err := v.client.Kube().CoreV1().Services(ns).Create(ctx, svc, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create server service: %v", err)
+	}
+
+	server := smokeTestJob(ns, smokeTestServer, image, "server", "-http-port", fmt.Sprint(smokeTestPort))
+	if _, err := v.client.Kube().BatchV1().Jobs(ns).Create(ctx, server, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create server job: %v", err)
+	}
+	if _, err := v.waitForSmokePod(ctx, ns, smokeTestServer, smokeServerReady); err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("http://%s.%s:%d/debug", smokeTestServer, ns, smokeTestPort)
+	client := smokeTestJob(ns, smokeTestClient, image, "curl", url)
+	if _, err := v.client.Kube().BatchV1().Jobs(ns).Create(ctx, client, metav1.CreateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to create client job: %v", err)
+	}
+	pod, err := v.waitForSmokePod(ctx, ns, smokeTestClient, smokeClientDone)
+	if err != nil {
+		return "", err
+	}
+	logs, err := v.client.PodLogs(ctx, pod.Name, ns, smokeTestClient, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to read client logs: %v", err)
+	}
+	if code := smokeClientExitCode(pod); code != 0 {
+		return "", fmt.Errorf("client exited with code %d: %s", code, strings.TrimSpace(logs))
+	}
+	return smokeClientIdentity(logs, ns)
+}
+
+func smokeTestJob(ns, name, image string, args ...string) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Of(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: name,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers:         []corev1.Container{{Name: name, Image: image, Args: args}},
+				},
+			},
+		},
+	}
+}
+
+// waitForSmokePod waits for the pod of the Job to have a sidecar and to reach the state checked by done.
+func (v *StatusVerifier) waitForSmokePod(ctx context.Context, ns, job string, done func(*corev1.Pod) bool) (*corev1.Pod, error) {
+	for {
+		pods, err := v.client.Kube().CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: "app=" + job})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s pods: %v", job, err)
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !hasSidecar(pod) {
+				return nil, fmt.Errorf("pod %s of %s was not injected with a sidecar", pod.Name, job)
+			}
+			if done(pod) {
+				return pod, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s did not complete in time", job)
+		case <-time.After(smokePollInterval):
+		}
+	}
+}
+
+// hasSidecar returns whether the pod has an istio-proxy container, either a regular container or a
+// native sidecar.
+func hasSidecar(pod *corev1.Pod) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			if c.Name == "istio-proxy" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func smokeServerReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// smokeClientDone returns whether the client container terminated. The sidecar keeps running unless
+// it is a native sidecar, so the Job itself does not necessarily complete.
+func smokeClientDone(pod *corev1.Pod) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == smokeTestClient && cs.State.Terminated != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func smokeClientExitCode(pod *corev1.Pod) int32 {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == smokeTestClient && cs.State.Terminated != nil {
+			return cs.State.Terminated.ExitCode
+		}
+	}
+	return 0
+}
+
+// smokeClientIdentity returns the client identity echoed back by the server, which must be the
+// identity of the client service account.
+func smokeClientIdentity(logs, ns string) (string, error) {
+	m := xfccURI.FindStringSubmatch(logs)
+	if m == nil {
+		return "", fmt.Errorf("server did not receive the request over mTLS, no client certificate was forwarded")
+	}
+	identity := m[1]
+	if want := fmt.Sprintf("/ns/%s/sa/%s", ns, smokeTestClient); !strings.HasSuffix(identity, want) {
+		return "", fmt.Errorf("server received the request from %s, expected an identity ending with %s", identity, want)
+	}
+	return identity, nil
+}
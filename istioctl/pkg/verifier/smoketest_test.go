@@ -0,0 +1,122 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestSmokeClientIdentity(t *testing.T) {
+	cases := []struct {
+		name    string
+		logs    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "mTLS",
+			logs: "GET /debug HTTP/1.1\nheaders:\nX-Forwarded-Client-Cert: By=spiffe://cluster.local/ns/test/sa/smoke-server;" +
+				"Hash=abc;Subject=\"\";URI=spiffe://cluster.local/ns/test/sa/smoke-client\n",
+			want: "spiffe://cluster.local/ns/test/sa/smoke-client",
+		},
+		{
+			name:    "plaintext",
+			logs:    "GET /debug HTTP/1.1\nheaders:\nHost: smoke-server\n",
+			wantErr: "no client certificate was forwarded",
+		},
+		{
+			name:    "unexpected identity",
+			logs:    "x-forwarded-client-cert: URI=spiffe://cluster.local/ns/other/sa/default\n",
+			wantErr: "expected an identity ending with /ns/test/sa/smoke-client",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := smokeClientIdentity(c.logs, "test")
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, c.want)
+		})
+	}
+}
+
+func TestSmokePodStates(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "istio-proxy"}},
+			Containers:     []corev1.Container{{Name: smokeTestClient}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: smokeTestClient, State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 7}}},
+			},
+		},
+	}
+	assert.Equal(t, hasSidecar(pod), true)
+	assert.Equal(t, smokeClientDone(pod), true)
+	assert.Equal(t, smokeClientExitCode(pod), int32(7))
+	assert.Equal(t, smokeServerReady(pod), false)
+	assert.Equal(t, hasSidecar(&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}), false)
+}
+
+func TestVerifySmokeTestCleansUp(t *testing.T) {
+	var out bytes.Buffer
+	client := kube.NewFakeClient()
+	v := &StatusVerifier{
+		client:           client,
+		logger:           clog.NewConsoleLogger(&out, &out, nil),
+		failureMarker:    "✘",
+		controlPlaneOpts: clioptions.ControlPlaneOptions{Revision: "canary"},
+		checks:           checkSettings{smokeTestTimeout: 10 * time.Millisecond},
+	}
+	// No pods are ever scheduled by the fake client, so the test times out waiting for the server.
+	err := v.verifySmokeTest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "smoke-server did not complete in time") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	namespaces, err := client.Kube().CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, len(namespaces.Items), 0)
+	if !strings.Contains(out.String(), "✘ Smoke test:") {
+		t.Fatalf("missing failure output:\n%s", out.String())
+	}
+}
+
+func TestSmokeTestNamespace(t *testing.T) {
+	v := &StatusVerifier{}
+	ns := v.smokeTestNamespace()
+	assert.Equal(t, ns.Labels["istio-injection"], "enabled")
+	v.controlPlaneOpts.Revision = "canary"
+	ns = v.smokeTestNamespace()
+	assert.Equal(t, ns.Labels["istio.io/rev"], "canary")
+	assert.Equal(t, ns.Labels[smokeTestLabel], "true")
+}
@@ -0,0 +1,161 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultStorageBindTimeout is how long PersistentVolumeClaims are given to bind by default.
+	DefaultStorageBindTimeout = 2 * time.Minute
+
+	storagePollInterval = 2 * time.Second
+
+	defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+)
+
+// WithStorageBindTimeout sets how long the verifier waits for the PersistentVolumeClaims of
+// installed components to bind.
+func WithStorageBindTimeout(timeout time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.checks.storageBindTimeout = timeout
+	}
+}
+
+// verifyStatefulSetStorage checks the PersistentVolumeClaims created from the volume claim
+// templates of every replica of the StatefulSet.
+func (v *StatusVerifier) verifyStatefulSetStorage(ctx context.Context, sts *appsv1.StatefulSet) error {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	multiErr := &multierror.Error{}
+	for _, tmpl := range sts.Spec.VolumeClaimTemplates {
+		for i := int32(0); i < replicas; i++ {
+			name := fmt.Sprintf("%s-%s-%d", tmpl.Name, sts.Name, i)
+			if err := v.verifyPVC(ctx, sts.Namespace, name); err != nil {
+				multiErr = multierror.Append(multiErr, err)
+			}
+		}
+	}
+	return multiErr.ErrorOrNil()
+}
+
+// verifyPVC checks that the StorageClass of the PersistentVolumeClaim exists and waits up to the
+// configured timeout for the claim to bind. Claims which stay pending are reported with the
+// warning events recorded for them.
+func (v *StatusVerifier) verifyPVC(ctx context.Context, namespace, name string) error {
+	timeout := v.checks.storageBindTimeout
+	if timeout == 0 {
+		timeout = DefaultStorageBindTimeout
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var class *storagev1.StorageClass
+	for {
+		pvc, err := v.client.Kube().CoreV1().PersistentVolumeClaims(namespace).Get(waitCtx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("PersistentVolumeClaim %s/%s: %v", namespace, name, err)
+		}
+		if class == nil {
+			if class, err = v.storageClassForPVC(waitCtx, pvc); err != nil {
+				return fmt.Errorf("PersistentVolumeClaim %s/%s: %v", namespace, name, err)
+			}
+		}
+		switch pvc.Status.Phase {
+		case corev1.ClaimBound:
+			return nil
+		case corev1.ClaimLost:
+			return fmt.Errorf("PersistentVolumeClaim %s/%s lost its volume %q", namespace, name, pvc.Spec.VolumeName)
+		}
+		select {
+		case <-waitCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return v.reportPendingPVC(ctx, pvc, class, timeout)
+		case <-time.After(storagePollInterval):
+		}
+	}
+}
+
+// storageClassForPVC returns the StorageClass the claim is provisioned from, which is the default
+// StorageClass if the claim does not name one. Claims binding to pre-provisioned volumes have no
+// StorageClass, in which case an empty StorageClass is returned.
+func (v *StatusVerifier) storageClassForPVC(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (*storagev1.StorageClass, error) {
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName == "" {
+		return &storagev1.StorageClass{}, nil
+	}
+	if pvc.Spec.StorageClassName != nil {
+		name := *pvc.Spec.StorageClassName
+		class, err := v.client.Kube().StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			return nil, fmt.Errorf("StorageClass %q does not exist", name)
+		}
+		return class, err
+	}
+	classes, err := v.client.Kube().StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[defaultStorageClassAnnotation] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	if pvc.Spec.VolumeName != "" {
+		return &storagev1.StorageClass{}, nil
+	}
+	return nil, fmt.Errorf("no StorageClass requested and the cluster has no default StorageClass")
+}
+
+func (v *StatusVerifier) reportPendingPVC(ctx context.Context, pvc *corev1.PersistentVolumeClaim, class *storagev1.StorageClass, timeout time.Duration) error {
+	events, err := v.client.Kube().CoreV1().Events(pvc.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=" + pvc.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("PersistentVolumeClaim %s/%s not bound within %v, failed to list its events: %v",
+			pvc.Namespace, pvc.Name, timeout, err)
+	}
+	var reasons []string
+	for _, e := range events.Items {
+		if e.Type == corev1.EventTypeWarning && e.InvolvedObject.Kind == "PersistentVolumeClaim" && e.InvolvedObject.Name == pvc.Name {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", e.Reason, e.Message))
+		}
+	}
+	if class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer && len(reasons) == 0 {
+		// The claim is only provisioned once a pod using it is scheduled.
+		v.reportWarning("PersistentVolumeClaim", pvc.Name, pvc.Namespace,
+			fmt.Errorf("not bound within %v, StorageClass %q waits for the first consumer", timeout, class.Name))
+		return nil
+	}
+	err = fmt.Errorf("PersistentVolumeClaim %s/%s not bound within %v", pvc.Namespace, pvc.Name, timeout)
+	if len(reasons) > 0 {
+		err = fmt.Errorf("%v: %s", err, strings.Join(reasons, "; "))
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func pvc(name string, class *string, phase corev1.PersistentVolumeClaimPhase) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system"},
+		Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: class},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func storageClass(name string, isDefault bool, mode storagev1.VolumeBindingMode) *storagev1.StorageClass {
+	sc := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, VolumeBindingMode: &mode}
+	if isDefault {
+		sc.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+	}
+	return sc
+}
+
+func TestVerifyPVC(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		wantErr string
+		wantOut string
+	}{
+		{
+			name: "bound with named class",
+			objects: []runtime.Object{
+				storageClass("fast", false, storagev1.VolumeBindingImmediate),
+				pvc("data", ptr.Of("fast"), corev1.ClaimBound),
+			},
+		},
+		{
+			name:    "missing class",
+			objects: []runtime.Object{pvc("data", ptr.Of("fast"), corev1.ClaimPending)},
+			wantErr: `StorageClass "fast" does not exist`,
+		},
+		{
+			name:    "no default class",
+			objects: []runtime.Object{pvc("data", nil, corev1.ClaimPending)},
+			wantErr: "no default StorageClass",
+		},
+		{
+			name: "pending with events",
+			objects: []runtime.Object{
+				storageClass("standard", true, storagev1.VolumeBindingImmediate),
+				pvc("data", nil, corev1.ClaimPending),
+				&corev1.Event{
+					ObjectMeta:     metav1.ObjectMeta{Name: "data.1", Namespace: "istio-system"},
+					InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Name: "data", Namespace: "istio-system"},
+					Type:           corev1.EventTypeWarning,
+					Reason:         "ProvisioningFailed",
+					Message:        "quota exceeded",
+				},
+			},
+			wantErr: "not bound within 10ms: ProvisioningFailed: quota exceeded",
+		},
+		{
+			name: "pending until first consumer",
+			objects: []runtime.Object{
+				storageClass("standard", true, storagev1.VolumeBindingWaitForFirstConsumer),
+				pvc("data", nil, corev1.ClaimPending),
+			},
+			wantOut: `StorageClass "standard" waits for the first consumer`,
+		},
+		{
+			name:    "pre-provisioned volume",
+			objects: []runtime.Object{pvc("data", ptr.Of(""), corev1.ClaimBound)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				client: kube.NewFakeClient(c.objects...),
+				logger: clog.NewConsoleLogger(&out, &out, nil),
+				checks: checkSettings{storageBindTimeout: 10 * time.Millisecond},
+			}
+			err := v.verifyPVC(context.Background(), "istio-system", "data")
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			if !strings.Contains(out.String(), c.wantOut) {
+				t.Fatalf("expected output containing %q, got:\n%s", c.wantOut, out.String())
+			}
+		})
+	}
+}
+
+func TestVerifyPVCCanceled(t *testing.T) {
+	v := &StatusVerifier{
+		client: kube.NewFakeClient(
+			storageClass("standard", true, storagev1.VolumeBindingImmediate),
+			pvc("data", nil, corev1.ClaimPending),
+		),
+		logger: clog.NewDefaultLogger(),
+		checks: checkSettings{storageBindTimeout: time.Minute},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := v.verifyPVC(ctx, "istio-system", "data")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the verification to stop waiting once canceled, got %v", err)
+	}
+}
+
+func TestVerifyStatefulSetStorage(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "istio-system"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.Of(int32(2)),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+	v := &StatusVerifier{
+		client: kube.NewFakeClient(
+			storageClass("standard", true, storagev1.VolumeBindingImmediate),
+			pvc("data-prometheus-0", nil, corev1.ClaimBound),
+		),
+		logger: clog.NewDefaultLogger(),
+		checks: checkSettings{storageBindTimeout: 10 * time.Millisecond},
+	}
+	err := v.verifyStatefulSetStorage(context.Background(), sts)
+	if err == nil || !strings.Contains(err.Error(), `"data-prometheus-1" not found`) {
+		t.Fatalf("expected missing claim of second replica, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/pkg/slices"
+)
+
+// otherComponent groups the checks not attributed to an Istio component, such as most optional checks.
+const otherComponent = "other"
+
+// componentNames are the names the components are summarized under, in the order of the summary.
+var componentNames = []struct {
+	component name.ComponentName
+	name      string
+}{
+	{name.PilotComponentName, "istiod"},
+	{name.IstiodRemoteComponentName, "istiod remote"},
+	{name.IngressComponentName, "ingress gateways"},
+	{name.EgressComponentName, "egress gateways"},
+	{name.CNIComponentName, "CNI"},
+	{name.ZtunnelComponentName, "ztunnel"},
+	{name.IstioBaseComponentName, "base"},
+}
+
+// componentName returns the name a component is summarized under.
+func componentName(component string) string {
+	if component == "" {
+		return otherComponent
+	}
+	for _, c := range componentNames {
+		if string(c.component) == component {
+			return c.name
+		}
+	}
+	return component
+}
+
+// componentOrder returns the rank of a summarized component: known components first, in the order of
+// componentNames, then the others.
+func componentOrder(n string) int {
+	for i, c := range componentNames {
+		if c.name == n {
+			return i
+		}
+	}
+	if n == otherComponent {
+		return len(componentNames) + 1
+	}
+	return len(componentNames)
+}
+
+// ComponentSummary counts the checks of an Istio component.
+type ComponentSummary struct {
+	Component string `json:"component"`
+	Checked   int    `json:"checked"`
+	Passed    int    `json:"passed"`
+	Warnings  int    `json:"warnings"`
+	Failed    int    `json:"failed"`
+}
+
+// manifestSourceFormat names the streams of rendered manifests the resources are read from by component and
+// source of the installation, as the rendered resources do not carry the component label the installation adds
+// to them.
+const manifestSourceFormat = "%s: generated from %s"
+
+// manifestComponent returns the component of a resource read from a rendered manifest, from the name of the
+// manifest. It returns an empty string for the resources of other sources, such as installation files.
+func manifestComponent(source string) string {
+	c, _, found := strings.Cut(source, ":")
+	if !found || !slices.Contains(name.AllComponentNames, name.ComponentName(c)) {
+		return ""
+	}
+	return c
+}
+
+// attributeResults attributes the results recorded from first on, which have no component yet, to the
+// component.
+func (v *StatusVerifier) attributeResults(first int, component string) {
+	if component == "" {
+		return
+	}
+	for i := first; i < len(v.results); i++ {
+		if v.results[i].component == "" {
+			v.results[i].component = component
+		}
+	}
+}
+
+// componentSummaries counts the results by component, ordered by component.
+func componentSummaries(results []checkResult) []ComponentSummary {
+	byName := map[string]*ComponentSummary{}
+	for _, r := range results {
+		n := componentName(r.component)
+		s, f := byName[n]
+		if !f {
+			s = &ComponentSummary{Component: n}
+			byName[n] = s
+		}
+		s.Checked++
+		switch r.status {
+		case checkPassed:
+			s.Passed++
+		case checkWarning:
+			s.Warnings++
+		case checkFailed:
+			s.Failed++
+		}
+	}
+	summaries := make([]ComponentSummary, 0, len(byName))
+	for _, s := range byName {
+		summaries = append(summaries, *s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if oa, ob := componentOrder(a.Component), componentOrder(b.Component); oa != ob {
+			return oa < ob
+		}
+		return a.Component < b.Component
+	})
+	return summaries
+}
+
+// reportComponentSummary prints the counts of the checks of each component.
+func (v *StatusVerifier) reportComponentSummary() {
+	summaries := componentSummaries(v.results)
+	if len(summaries) == 0 {
+		return
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 8, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COMPONENT\tCHECKED\tPASSED\tWARNINGS\tFAILED")
+	for _, s := range summaries {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", s.Component, s.Checked, s.Passed, s.Warnings, s.Failed)
+	}
+	_ = w.Flush()
+	v.logf(VerbosityNormal, "%s", strings.TrimSuffix(b.String(), "\n"))
+}
@@ -0,0 +1,74 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestAttributeComponents(t *testing.T) {
+	assert.Equal(t, manifestComponent(fmt.Sprintf(manifestSourceFormat, name.IngressComponentName, "default profile")),
+		"IngressGateways")
+	assert.Equal(t, manifestComponent("install.yaml"), "")
+	assert.Equal(t, manifestComponent(`C:\istio\install.yaml`), "")
+
+	v := &StatusVerifier{}
+	v.record("Deployment", "istiod", "istio-system", checkPassed, "")
+	first := len(v.results)
+	v.record("Deployment", "istio-ingressgateway", "istio-system", checkPassed, "")
+	v.attributeResults(first, "IngressGateways")
+	assert.Equal(t, v.results[0].component, "")
+	assert.Equal(t, v.results[1].component, "IngressGateways")
+}
+
+func TestComponentSummary(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{
+		logger:        clog.NewConsoleLogger(&out, &out, nil),
+		successMarker: "✔",
+		failureMarker: "✘",
+	}
+	v.reportSuccess("Gateway", "ingress", "istio-system")
+	v.reportSuccess("Deployment", "istio-cni-node", "kube-system")
+	v.results[1].component = "Cni"
+	v.reportFailure("Deployment", "istio-ingressgateway", "istio-system", fmt.Errorf("not ready"))
+	v.results[2].component = "IngressGateways"
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("ConfigMap", "istio", "istio-system", fmt.Errorf("outdated"))
+	v.results[3].component, v.results[4].component = "Pilot", "Pilot"
+
+	assert.Equal(t, componentSummaries(v.results), []ComponentSummary{
+		{Component: "istiod", Checked: 2, Passed: 1, Warnings: 1},
+		{Component: "ingress gateways", Checked: 1, Failed: 1},
+		{Component: "CNI", Checked: 1, Passed: 1},
+		{Component: "other", Checked: 1, Passed: 1},
+	})
+
+	out.Reset()
+	v.reportComponentSummary()
+	assert.Equal(t, strings.TrimSpace(out.String()), strings.TrimSpace(`
+COMPONENT        CHECKED PASSED WARNINGS FAILED
+istiod           2       1      1        0
+ingress gateways 1       0      0        1
+CNI              1       1      0        0
+other            1       1      0        0`))
+}
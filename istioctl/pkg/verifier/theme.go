@@ -0,0 +1,107 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Theme is how the results of the checks are marked in the output of the verifier.
+type Theme struct {
+	SuccessMarker string
+	FailureMarker string
+	WarningMarker string
+	// Color colors the markers, green for successes, red for failures and yellow for warnings, unless NO_COLOR is
+	// set.
+	Color bool
+}
+
+var (
+	// DefaultTheme marks the results with Unicode symbols, without color.
+	DefaultTheme = Theme{SuccessMarker: "✔", FailureMarker: "✘", WarningMarker: "!"}
+	// ASCIITheme marks the results with ASCII only, for terminals which cannot display Unicode.
+	ASCIITheme = Theme{SuccessMarker: "[OK]", FailureMarker: "[FAIL]", WarningMarker: "[WARN]"}
+)
+
+// NoColor returns whether colors are disabled by the NO_COLOR environment variable, see https://no-color.org.
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// TerminalTheme returns the theme of the output of the verifier to a terminal: ASCIITheme if TERM is dumb, and
+// DefaultTheme otherwise, colored if colored is set.
+func TerminalTheme(colored bool) Theme {
+	theme := DefaultTheme
+	if strings.EqualFold(os.Getenv("TERM"), "dumb") {
+		theme = ASCIITheme
+		colored = false
+	}
+	theme.Color = colored
+	return theme
+}
+
+// WithTheme sets the markers of the results of the checks, and whether they are colored.
+func WithTheme(t Theme) StatusVerifierOptions {
+	return func(v *StatusVerifier) {
+		v.successMarker, v.failureMarker, v.warningMarker = t.SuccessMarker, t.FailureMarker, t.WarningMarker
+		if t.Color {
+			v.Colorize()
+		}
+	}
+}
+
+// WithMarkers sets the markers of the results of the checks, without changing their color. Empty markers are left
+// unchanged.
+func WithMarkers(success, failure, warning string) StatusVerifierOptions {
+	return func(v *StatusVerifier) {
+		if success != "" {
+			v.successMarker = success
+		}
+		if failure != "" {
+			v.failureMarker = failure
+		}
+		if warning != "" {
+			v.warningMarker = warning
+		}
+	}
+}
+
+// WithASCII marks the results of the checks with the markers of ASCIITheme, for terminals which cannot display
+// Unicode.
+func WithASCII() StatusVerifierOptions {
+	return WithMarkers(ASCIITheme.SuccessMarker, ASCIITheme.FailureMarker, ASCIITheme.WarningMarker)
+}
+
+// Colorize colors the markers of the results of the checks, unless NO_COLOR is set. Markers set afterwards are not
+// colored.
+func (v *StatusVerifier) Colorize() {
+	if NoColor() {
+		return
+	}
+	v.successMarker = color.New(color.FgGreen).Sprint(v.successMarker)
+	v.failureMarker = color.New(color.FgRed).Sprint(v.failureMarker)
+	v.warningMarker = color.New(color.FgYellow).Sprint(v.warningMark())
+}
+
+// warningMark returns the marker of warnings, "!" unless set.
+func (v *StatusVerifier) warningMark() string {
+	if v.warningMarker == "" {
+		return DefaultTheme.WarningMarker
+	}
+	return v.warningMarker
+}
@@ -0,0 +1,98 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func themedOutput(options ...StatusVerifierOptions) string {
+	var out bytes.Buffer
+	v := &StatusVerifier{}
+	WithOutput(&out)(v)
+	WithTheme(DefaultTheme)(v)
+	for _, opt := range options {
+		opt(v)
+	}
+	v.reportSuccess("Deployment", "istiod", "istio-system")
+	v.reportWarning("PodDisruptionBudget", "istiod", "istio-system", errors.New("not found"))
+	v.reportFailure("Deployment", "istio-ingressgateway", "istio-system", errors.New("not ready"))
+	return out.String()
+}
+
+func TestThemes(t *testing.T) {
+	cases := []struct {
+		name    string
+		options []StatusVerifierOptions
+		want    []string
+	}{
+		{
+			name: "default",
+			want: []string{"✔ Deployment: istiod", "! PodDisruptionBudget: istiod", "✘ Deployment: istio-ingressgateway"},
+		},
+		{
+			name:    "ascii",
+			options: []StatusVerifierOptions{WithASCII()},
+			want:    []string{"[OK] Deployment: istiod", "[WARN] PodDisruptionBudget: istiod", "[FAIL] Deployment: istio-ingressgateway"},
+		},
+		{
+			name:    "custom markers",
+			options: []StatusVerifierOptions{WithMarkers("PASS", "", "WARN")},
+			want:    []string{"PASS Deployment: istiod", "WARN PodDisruptionBudget: istiod", "✘ Deployment: istio-ingressgateway"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out := themedOutput(tt.options...)
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("missing %q in output:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestThemeColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	t.Setenv("NO_COLOR", "")
+	colored := DefaultTheme
+	colored.Color = true
+	if out := themedOutput(WithTheme(colored)); !strings.Contains(out, "\x1b[33m!\x1b[0m PodDisruptionBudget") {
+		t.Errorf("expected colored markers in output:\n%s", out)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if out := themedOutput(WithTheme(colored)); strings.Contains(out, "\x1b[") {
+		t.Errorf("expected no colors with NO_COLOR in output:\n%s", out)
+	}
+}
+
+func TestTerminalTheme(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	assert.Equal(t, TerminalTheme(true), Theme{SuccessMarker: "✔", FailureMarker: "✘", WarningMarker: "!", Color: true})
+	t.Setenv("TERM", "dumb")
+	assert.Equal(t, TerminalTheme(true), ASCIITheme)
+}
@@ -0,0 +1,67 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithTimeout bounds the whole verification, including the optional checks. Zero, the default, does not bound it.
+func WithTimeout(timeout time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.timeout = timeout
+	}
+}
+
+// WithPerResourceTimeout bounds the check of every installed resource, so that a resource which cannot be read,
+// such as one of an unresponsive API group, does not hang the verification. Zero, the default, does not bound it.
+func WithPerResourceTimeout(timeout time.Duration) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.perResourceTimeout = timeout
+	}
+}
+
+// verificationContext returns the context of a verification, bounded by the timeout of the verifier.
+func (v *StatusVerifier) verificationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if v.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, v.timeout)
+}
+
+// resourceContext returns the context of the check of an installed resource of kind, bounded by the per resource
+// timeout. IstioOperators are not bounded, as their check verifies all the resources they install.
+func (v *StatusVerifier) resourceContext(ctx context.Context, kind string) (context.Context, context.CancelFunc) {
+	if v.perResourceTimeout <= 0 || kind == "IstioOperator" {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, v.perResourceTimeout)
+}
+
+// isTimeout reports whether err is the expiry of the timeout of the verification or of a check.
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// timeoutError marks the timeouts of the checks, so that they are reported apart from the other failures.
+func timeoutError(err error) error {
+	if err == nil || !isTimeout(err) {
+		return err
+	}
+	return withCode(CodeTimeout, fmt.Errorf("timed out: %w", err))
+}
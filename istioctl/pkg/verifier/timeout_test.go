@@ -0,0 +1,54 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestResourceContext(t *testing.T) {
+	v := &StatusVerifier{}
+	WithPerResourceTimeout(time.Minute)(v)
+	ctx, cancel := v.resourceContext(context.Background(), "Deployment")
+	defer cancel()
+	if _, f := ctx.Deadline(); !f {
+		t.Errorf("expected the check of a Deployment to be bounded")
+	}
+	ctx, cancel = v.resourceContext(context.Background(), "IstioOperator")
+	defer cancel()
+	if _, f := ctx.Deadline(); f {
+		t.Errorf("expected the check of an IstioOperator not to be bounded")
+	}
+}
+
+func TestReportProblemTimeout(t *testing.T) {
+	var out bytes.Buffer
+	v := &StatusVerifier{}
+	WithOutput(&out)(v)
+	err := v.reportProblem("Deployment", "istiod", "istio-system",
+		&url.Error{Op: "Get", URL: "https://cluster/apis/apps/v1", Err: context.DeadlineExceeded})
+	assert.Equal(t, classifyFailure(err), FailureTimeout)
+
+	results := v.Results()
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Code, string(CodeTimeout))
+	assert.Equal(t, results[0].Reason, `timed out: Get "https://cluster/apis/apps/v1": context deadline exceeded`)
+}
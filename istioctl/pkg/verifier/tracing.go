@@ -0,0 +1,195 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"istio.io/istio/pkg/file"
+	"istio.io/istio/pkg/tracing"
+)
+
+// TraceExporterFormats are the formats of the trace exporters of NewTraceExporter.
+var TraceExporterFormats = []string{"otlp", "file=<path>"}
+
+// NewTraceExporter returns the exporter of the spans of verifications described by spec: "otlp" sends them to the
+// OTLP gRPC collector configured by the standard OTEL_EXPORTER_OTLP_* environment variables, while "file=<path>"
+// writes them to a JSON file, for instance to attach to a support case.
+func NewTraceExporter(spec string) (sdktrace.SpanExporter, error) {
+	if spec == "otlp" {
+		return otlptrace.New(context.Background(), otlptracegrpc.NewClient())
+	}
+	if path, ok := strings.CutPrefix(spec, "file="); ok && path != "" {
+		return &fileExporter{path: path}, nil
+	}
+	return nil, fmt.Errorf("invalid trace exporter %q, expected one of %v", spec, TraceExporterFormats)
+}
+
+// StartTracing makes the exporter export the spans of verifications, and returns the function flushing the spans
+// and shutting the exporter down, to call once verified.
+func StartTracing(exporter sdktrace.SpanExporter) func() error {
+	r, _ := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("istioctl")))
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(r))
+	otel.SetTracerProvider(tp)
+	return func() error {
+		return tp.Shutdown(context.Background())
+	}
+}
+
+// fileExporter writes the spans to a JSON file when shut down.
+type fileExporter struct {
+	path  string
+	mu    sync.Mutex
+	spans []tracetest.SpanStub
+}
+
+func (e *fileExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, tracetest.SpanStubsFromReadOnlySpans(spans)...)
+	return nil
+}
+
+func (e *fileExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out, err := json.MarshalIndent(e.spans, "", "  ")
+	if err != nil {
+		return err
+	}
+	return file.AtomicWrite(e.path, append(out, '\n'), 0o644)
+}
+
+// traceContext returns the context of the span of the verification in progress, the parent of the spans of the
+// checks and API calls.
+func (v *StatusVerifier) traceContext() context.Context {
+	if v.traceCtx == nil {
+		return context.Background()
+	}
+	return v.traceCtx
+}
+
+// startSpan starts a span, child of the span in progress, which becomes the parent of the spans started until the
+// returned function is called. The function ends the span, as failed if a check failed meanwhile.
+func (v *StatusVerifier) startSpan(name string, attrs ...attribute.KeyValue) func() {
+	parent := v.traceCtx
+	ctx, span := tracing.Start(v.traceContext(), name)
+	span.SetAttributes(attrs...)
+	v.traceCtx = ctx
+	first := len(v.results)
+	return func() {
+		for _, r := range v.results[first:] {
+			if r.status == checkFailed {
+				span.SetStatus(codes.Error, r.reason)
+				break
+			}
+		}
+		span.End()
+		v.traceCtx = parent
+	}
+}
+
+// tracingClientConfig wraps the transport of the clients of the verifier, to trace every API call.
+type tracingClientConfig struct {
+	config clientcmd.ClientConfig
+	v      *StatusVerifier
+}
+
+var _ clientcmd.ClientConfig = &tracingClientConfig{}
+
+// newTracingClientConfig wraps the client config of the verifier.
+func newTracingClientConfig(config clientcmd.ClientConfig, v *StatusVerifier) clientcmd.ClientConfig {
+	return &tracingClientConfig{config: config, v: v}
+}
+
+func (c *tracingClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.config.RawConfig()
+}
+
+func (c *tracingClientConfig) Namespace() (string, bool, error) {
+	return c.config.Namespace()
+}
+
+func (c *tracingClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.config.ConfigAccess()
+}
+
+func (c *tracingClientConfig) ClientConfig() (*rest.Config, error) {
+	config, err := c.config.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &tracingTransport{rt: rt, parent: c.v.traceContext, log: c.v.logAPICall}
+	})
+	return config, nil
+}
+
+// tracingTransport records a span for every request. As the verifier does not pass contexts to its API calls,
+// requests without span are children of the span in progress of the verifier. Requests are also passed to log,
+// if set, once done.
+type tracingTransport struct {
+	rt     http.RoundTripper
+	parent func() context.Context
+	log    func(req *http.Request, resp *http.Response, err error, took time.Duration)
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = t.parent()
+	}
+	_, span := tracing.Start(ctx, "verifier.APICall")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("http.method", req.Method),
+		attribute.String("http.target", req.URL.Path),
+	)
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	if t.log != nil {
+		t.log(req, resp, err, time.Since(start))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
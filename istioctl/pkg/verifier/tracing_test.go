@@ -0,0 +1,120 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/tracing"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestCheckSpans(t *testing.T) {
+	recorder := recordSpans(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	v := &StatusVerifier{}
+	WithOutput(io.Discard)(v)
+	client := &http.Client{Transport: &tracingTransport{rt: http.DefaultTransport, parent: v.traceContext}}
+	ctx, root := tracing.Start(context.Background(), "verifier.Verify")
+	v.traceCtx = ctx
+
+	end := v.startSpan("verifier.CheckResource", attribute.String("kind", "Deployment"))
+	resp, err := client.Get(server.URL + "/apis/apps/v1/namespaces/istio-system/deployments/istiod")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	v.reportFailure("Deployment", "istiod", "istio-system", errors.New("not found"))
+	end()
+	if v.traceCtx != ctx {
+		t.Fatal("expected the span of the verification to be in progress again")
+	}
+
+	end = v.startSpan("verifier.Check", attribute.String("check", "smoke-test"))
+	v.reportSuccess("Smoke test", "mtls", "")
+	end()
+	root.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, len(spans), 4)
+	call, check, optional := spans[0], spans[1], spans[2]
+	assert.Equal(t, call.Name(), "verifier.APICall")
+	assert.Equal(t, call.Parent().SpanID(), check.SpanContext().SpanID())
+	assert.Equal(t, check.Name(), "verifier.CheckResource")
+	assert.Equal(t, check.Parent().SpanID(), root.SpanContext().SpanID())
+	assert.Equal(t, check.Status().Code, codes.Error)
+	assert.Equal(t, check.Status().Description, "not found")
+	assert.Equal(t, optional.Name(), "verifier.Check")
+	assert.Equal(t, optional.Status().Code, codes.Unset)
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range call.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, attrs["http.method"].AsString(), "GET")
+	assert.Equal(t, attrs["http.target"].AsString(), "/apis/apps/v1/namespaces/istio-system/deployments/istiod")
+	assert.Equal(t, attrs["http.status_code"].AsInt64(), int64(http.StatusNotFound))
+}
+
+func TestFileTraceExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	exporter, err := NewTraceExporter("file=" + path)
+	assert.NoError(t, err)
+	stop := StartTracing(exporter)
+	_, span := tracing.Start(context.Background(), "verifier.Verify")
+	span.End()
+	assert.NoError(t, stop())
+
+	out, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var spans []map[string]any
+	assert.NoError(t, json.Unmarshal(out, &spans))
+	assert.Equal(t, len(spans), 1)
+	assert.Equal(t, spans[0]["Name"], "verifier.Verify")
+}
+
+func TestNewTraceExporterInvalid(t *testing.T) {
+	for _, spec := range []string{"", "jaeger", "file="} {
+		if _, err := NewTraceExporter(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/api/label"
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/istioctl/pkg/revisions"
+)
+
+const (
+	// validationProbe is the name of the VirtualServices the validation webhook is asked to validate.
+	validationProbe = "istioctl-verify-validation"
+	// validationWebhookKind is the kind the result of the validation-webhook check is reported with.
+	validationWebhookKind = "Validation webhook"
+)
+
+// verifyValidationWebhook checks that the validation webhook of istiod is reachable and does not fail open, by
+// creating VirtualServices with a server-side dry-run: a valid one must be accepted, in a fraction of the timeout
+// of the webhook, and an invalid one must be denied by the webhook. The latency of the validation is reported.
+func (v *StatusVerifier) verifyValidationWebhook(ctx context.Context) error {
+	hooks, err := v.client.Kube().AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list validating webhook configurations: %v", err)
+	}
+	timeout := DefaultWebhookCallTimeout
+	var names, failOpen []string
+	for i := range hooks.Items {
+		hook := &hooks.Items[i]
+		if _, f := hook.Labels[label.IoIstioRev.Name]; !f {
+			continue
+		}
+		names = append(names, hook.Name)
+		ignored := false
+		for _, wh := range hook.Webhooks {
+			// The webhook timing out first fails the validation.
+			if wh.TimeoutSeconds != nil && time.Duration(*wh.TimeoutSeconds)*time.Second < timeout {
+				timeout = time.Duration(*wh.TimeoutSeconds) * time.Second
+			}
+			ignored = ignored || (wh.FailurePolicy != nil && *wh.FailurePolicy == admitv1.Ignore)
+		}
+		if ignored {
+			failOpen = append(failOpen, hook.Name)
+		}
+	}
+	if len(names) == 0 {
+		v.logf(VerbosityNormal, "No Istio validating webhooks found, skipping the validation webhook checks")
+		return nil
+	}
+	sort.Strings(failOpen)
+
+	revision := revisions.Normalize(v.controlPlaneOpts.Revision)
+	latency, err := v.validateProbe(ctx, true)
+	if err != nil {
+		err = fmt.Errorf("the dry-run creation of a valid VirtualService failed: %v", err)
+		v.reportFailure(validationWebhookKind, revision, "", withCode(CodeValidationWebhook, err))
+		return err
+	}
+	v.logf(VerbosityNormal, "The validation of a VirtualService by %s took %v", strings.Join(names, ", "), latency.Round(time.Millisecond))
+
+	_, err = v.validateProbe(ctx, false)
+	if err == nil {
+		err = fmt.Errorf("an invalid VirtualService was accepted, the validation webhook is not called or fails open")
+		if len(failOpen) > 0 {
+			err = fmt.Errorf("%v: the failure policy of %s is Ignore", err, strings.Join(failOpen, ", "))
+		}
+		v.reportFailure(validationWebhookKind, revision, "", withCode(CodeValidationWebhook, err))
+		return err
+	}
+	if !strings.Contains(err.Error(), "admission webhook") {
+		v.reportWarning(validationWebhookKind, revision, "", withCode(CodeValidationWebhook,
+			fmt.Errorf("an invalid VirtualService was denied by the API server, not by the validation webhook: %v", err)))
+		return nil
+	}
+
+	if latency > timeout/2 {
+		v.reportWarning(validationWebhookKind, revision, "", withCode(CodeValidationWebhook,
+			fmt.Errorf("the validation took %v, more than half of the timeout of the webhook (%v)", latency.Round(time.Millisecond), timeout)))
+		return nil
+	}
+	v.reportSuccess(validationWebhookKind, revision, "")
+	return nil
+}
+
+// validateProbe creates a valid or an invalid VirtualService with a server-side dry-run, for the validation
+// webhook of the verified revision, and returns how long the API server took to answer.
+func (v *StatusVerifier) validateProbe(ctx context.Context, valid bool) (time.Duration, error) {
+	vs := &clientnetworking.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: validationProbe, Namespace: v.istioNamespace},
+		Spec: networking.VirtualService{
+			Http: []*networking.HTTPRoute{{
+				Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: validationProbe + ".invalid"}}},
+			}},
+		},
+	}
+	// A VirtualService without hosts is only denied by the validation of istiod, its schema allows it.
+	if valid {
+		vs.Spec.Hosts = []string{validationProbe + ".invalid"}
+	}
+	if rev := v.controlPlaneOpts.Revision; rev != "" && rev != revisions.Normalize("") {
+		vs.Labels = map[string]string{label.IoIstioRev.Name: rev}
+	}
+	start := time.Now()
+	_, err := v.client.Istio().NetworkingV1alpha3().VirtualServices(v.istioNamespace).Create(ctx, vs,
+		metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+	return time.Since(start), err
+}
@@ -0,0 +1,145 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	admitv1 "k8s.io/api/admissionregistration/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"istio.io/api/label"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istiofake "istio.io/client-go/pkg/clientset/versioned/fake"
+	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/ptr"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func validatorHook(name string, policy admitv1.FailurePolicyType, timeoutSeconds int32) *admitv1.ValidatingWebhookConfiguration {
+	return &admitv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{label.IoIstioRev.Name: "default"}},
+		Webhooks: []admitv1.ValidatingWebhook{{
+			Name:           "validation.istio.io",
+			FailurePolicy:  &policy,
+			TimeoutSeconds: ptr.Of(timeoutSeconds),
+		}},
+	}
+}
+
+func TestVerifyValidationWebhook(t *testing.T) {
+	denied := fmt.Errorf(`admission webhook "validation.istio.io" denied the request: configuration is invalid: ` +
+		`virtual service must have at least one host`)
+	cases := []struct {
+		name    string
+		hooks   []runtime.Object
+		delay   time.Duration
+		valid   error
+		invalid error
+		wantErr string
+		want    []string
+	}{
+		{
+			name:    "validating webhook",
+			hooks:   []runtime.Object{validatorHook("istio-validator-istio-system", admitv1.Fail, 10)},
+			invalid: denied,
+			want: []string{
+				"The validation of a VirtualService by istio-validator-istio-system took",
+				"✔ Validation webhook: default. checked successfully",
+			},
+		},
+		{
+			name:    "unreachable webhook",
+			hooks:   []runtime.Object{validatorHook("istio-validator-istio-system", admitv1.Fail, 10)},
+			valid:   fmt.Errorf(`Internal error occurred: failed calling webhook "validation.istio.io": dial tcp 10.96.0.10:443: connect: connection refused`),
+			wantErr: "the dry-run creation of a valid VirtualService failed: Internal error occurred: failed calling webhook",
+		},
+		{
+			name:    "failing open",
+			hooks:   []runtime.Object{validatorHook("istio-validator-istio-system", admitv1.Ignore, 10)},
+			wantErr: "an invalid VirtualService was accepted, the validation webhook is not called or fails open: the failure policy of istio-validator-istio-system is Ignore",
+		},
+		{
+			name:    "denied by the schema",
+			hooks:   []runtime.Object{validatorHook("istio-validator-istio-system", admitv1.Fail, 10)},
+			invalid: kerrors.NewBadRequest("spec.hosts: Invalid value"),
+			want:    []string{"! Validation webhook: default.: an invalid VirtualService was denied by the API server, not by the validation webhook"},
+		},
+		{
+			name:    "slow webhook",
+			hooks:   []runtime.Object{validatorHook("istio-validator-istio-system", admitv1.Fail, 1)},
+			delay:   600 * time.Millisecond,
+			invalid: denied,
+			want:    []string{"more than half of the timeout of the webhook (1s)"},
+		},
+		{
+			name: "no validating webhook",
+			want: []string{"No Istio validating webhooks found"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := kube.NewFakeClient(c.hooks...)
+			var dryRuns int
+			client.Istio().(*istiofake.Clientset).PrependReactor("create", "virtualservices",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					vs := action.(k8stesting.CreateAction).GetObject().(*clientnetworking.VirtualService)
+					dryRuns++
+					time.Sleep(c.delay)
+					if len(vs.Spec.Hosts) == 0 {
+						return true, nil, c.invalid
+					}
+					return true, nil, c.valid
+				})
+			var out bytes.Buffer
+			v := &StatusVerifier{
+				istioNamespace: "istio-system",
+				client:         client,
+				logger:         clog.NewConsoleLogger(&out, &out, nil),
+				successMarker:  "✔",
+				failureMarker:  "✘",
+				warningMarker:  "!",
+			}
+			err := v.verifyValidationWebhook(context.Background())
+			if c.wantErr == "" {
+				assert.NoError(t, err)
+			} else if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("output missing %q:\n%s", want, out.String())
+				}
+			}
+			for _, r := range v.results {
+				if r.status != checkPassed {
+					assert.Equal(t, r.code, CodeValidationWebhook)
+				}
+			}
+			if len(c.hooks) == 0 {
+				assert.Equal(t, dryRuns, 0)
+			}
+		})
+	}
+}
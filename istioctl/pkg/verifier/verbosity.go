@@ -0,0 +1,80 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"net/http"
+	"time"
+
+	"istio.io/istio/pkg/util/sets"
+)
+
+// Verbosity is how much of the verification is printed.
+type Verbosity int
+
+const (
+	// VerbosityQuiet only prints the problems found and the final status.
+	VerbosityQuiet Verbosity = iota - 1
+	// VerbosityNormal also prints a line per checked resource and the summaries of the verification. It is the
+	// default.
+	VerbosityNormal
+	// VerbosityVerbose also prints the optional checks which were skipped.
+	VerbosityVerbose
+	// VerbosityDebug also prints a line per call to the API server.
+	VerbosityDebug
+)
+
+// WithVerbosity sets how much of the verification is printed. It does not change the results nor the reports.
+func WithVerbosity(level Verbosity) StatusVerifierOptions {
+	return func(v *StatusVerifier) {
+		v.verbosity = level
+	}
+}
+
+// logf prints a line if the verbosity is at least level. Problems are printed at any verbosity with the logger
+// instead.
+func (v *StatusVerifier) logf(level Verbosity, format string, args ...any) {
+	if v.verbosity < level {
+		return
+	}
+	v.logMu.Lock()
+	defer v.logMu.Unlock()
+	v.logger.LogAndPrintf(format, args...)
+}
+
+// reportSkippedChecks prints the optional checks which were not selected.
+func (v *StatusVerifier) reportSkippedChecks() {
+	if v.verbosity < VerbosityVerbose {
+		return
+	}
+	selected := sets.New(v.checks.names...)
+	for _, name := range AvailableChecks() {
+		if !selected.Contains(name) {
+			v.logf(VerbosityVerbose, "- Check %s skipped, select it with --checks %s", name, name)
+		}
+	}
+}
+
+// logAPICall prints a call to the API server, in debug verbosity.
+func (v *StatusVerifier) logAPICall(req *http.Request, resp *http.Response, err error, took time.Duration) {
+	if v.verbosity < VerbosityDebug {
+		return
+	}
+	if err != nil {
+		v.logf(VerbosityDebug, "API %s %s failed after %v: %v", req.Method, req.URL.RequestURI(), took.Round(time.Millisecond), err)
+		return
+	}
+	v.logf(VerbosityDebug, "API %s %s %d in %v", req.Method, req.URL.RequestURI(), resp.StatusCode, took.Round(time.Millisecond))
+}
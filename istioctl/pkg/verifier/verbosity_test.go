@@ -0,0 +1,97 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerbosity(t *testing.T) {
+	cases := []struct {
+		name    string
+		level   Verbosity
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "quiet",
+			level:   VerbosityQuiet,
+			want:    []string{"✘ Deployment: istio-ingressgateway.istio-system: not ready", "! PodDisruptionBudget"},
+			notWant: []string{"checked successfully", "Checked 1 Istio Deployments", "skipped"},
+		},
+		{
+			name:    "normal",
+			level:   VerbosityNormal,
+			want:    []string{"✔ Deployment: istiod.istio-system checked successfully", "Checked 1 Istio Deployments"},
+			notWant: []string{"skipped"},
+		},
+		{
+			name:  "verbose",
+			level: VerbosityVerbose,
+			want:  []string{"- Check smoke-test skipped, select it with --checks smoke-test"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			v := &StatusVerifier{successMarker: "✔", failureMarker: "✘"}
+			WithOutput(&out)(v)
+			WithVerbosity(tt.level)(v)
+			v.reportSuccess("Deployment", "istiod", "istio-system")
+			v.reportWarning("PodDisruptionBudget", "istiod", "istio-system", errors.New("not found"))
+			v.reportFailure("Deployment", "istio-ingressgateway", "istio-system", errors.New("not ready"))
+			v.reportSkippedChecks()
+			v.logf(VerbosityNormal, "Checked %v Istio Deployments", 1)
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("missing %q in output:\n%s", want, out.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("unexpected %q in output:\n%s", notWant, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestVerbosityAPICalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	for _, level := range []Verbosity{VerbosityVerbose, VerbosityDebug} {
+		var out bytes.Buffer
+		v := &StatusVerifier{}
+		WithOutput(&out)(v)
+		WithVerbosity(level)(v)
+		client := &http.Client{Transport: &tracingTransport{rt: http.DefaultTransport, parent: v.traceContext, log: v.logAPICall}}
+		resp, err := client.Get(server.URL + "/api/v1/namespaces/istio-system/configmaps/istio")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		logged := strings.Contains(out.String(), "API GET /api/v1/namespaces/istio-system/configmaps/istio 404 in")
+		if logged != (level == VerbosityDebug) {
+			t.Errorf("verbosity %d: unexpected API call output:\n%s", level, out.String())
+		}
+	}
+}
@@ -16,49 +16,51 @@ package verifier
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/hashicorp/go-multierror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	admitv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	v1batch "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	apimachinery_schema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/yaml"
 
-	"istio.io/api/label"
 	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/revisions"
 	operator_istio "istio.io/istio/operator/pkg/apis/istio"
 	"istio.io/istio/operator/pkg/apis/istio/v1alpha1"
 	"istio.io/istio/operator/pkg/controlplane"
 	"istio.io/istio/operator/pkg/manifest"
+	"istio.io/istio/operator/pkg/name"
 	"istio.io/istio/operator/pkg/translate"
 	"istio.io/istio/operator/pkg/util"
 	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/tracing"
 )
 
-var (
-	istioOperatorGVR = apimachinery_schema.GroupVersionResource{
-		Group:    v1alpha1.SchemeGroupVersion.Group,
-		Version:  v1alpha1.SchemeGroupVersion.Version,
-		Resource: "istiooperators",
-	}
+// yamlSeparator separates the documents of a streamed manifest.
+const yamlSeparator = "\n---\n"
 
-	// specialKinds is a map of special kinds to their corresponding kind names, which do not follow the
-	// standard convention of pluralizing the kind name.
-	specialKinds = map[string]string{
-		"NetworkAttachmentDefinition": "network-attachment-definitions",
-	}
-)
+// specialKinds is a map of special kinds to their corresponding kind names, which do not follow the
+// standard convention of pluralizing the kind name.
+var specialKinds = map[string]string{
+	"NetworkAttachmentDefinition": "network-attachment-definitions",
+}
 
 // StatusVerifier checks status of certain resources like deployment,
 // jobs and also verifies count of certain resource types.
@@ -71,7 +73,92 @@ type StatusVerifier struct {
 	iop              *v1alpha1.IstioOperator
 	successMarker    string
 	failureMarker    string
+	warningMarker    string
 	client           kube.CLIClient
+	// checks configures the checks of the verification.
+	checks checkSettings
+	// results are the results of the checks of the last verification, for the health score.
+	results []checkResult
+	// sharedClients is shared by the verification of all the IstioOperators of a run.
+	sharedClients *sharedClientGetter
+	// renderer, if set, renders the result of every check instead of the logger.
+	renderer Renderer
+	// hooks are notified of the progress of the verification.
+	hooks []Hooks
+	// metrics, if set, record the outcome of every verification.
+	metrics *Metrics
+	// events records Events on the resources which failed their checks.
+	events bool
+	// history appends the result of every verification to the history of the cluster.
+	history bool
+	// operators are the IstioOperators of the cluster the last verification verified the installation against.
+	operators []*v1alpha1.IstioOperator
+	// progress, if set, is notified of the progress of the verification of the installed resources, as tracked
+	// by progressState.
+	progress      ProgressReporter
+	progressState Progress
+	// failOn is the severity from which problems fail the verification, errors if unset.
+	failOn Severity
+	// traceCtx is the context of the span in progress of the verification.
+	traceCtx context.Context
+	// reports are written once the verification is done.
+	reports []Report
+	// crdCount, istioDeploymentCount and daemonSetCount are the installed resources found by the last verification.
+	crdCount             int
+	istioDeploymentCount int
+	daemonSetCount       int
+	// checkDurations are how long each optional check of the last verification took.
+	checkDurations map[string]time.Duration
+	// sources are what the last verification verified the installation against.
+	sources []string
+	// coverage is how much of the data plane was found in the mesh by the last verification.
+	coverage *InjectionCoverage
+	// kubeconfig and kubeContext select the cluster to verify.
+	kubeconfig  string
+	kubeContext string
+	// verbosity is how much of the verification is printed.
+	verbosity Verbosity
+	// timeout bounds the whole verification, and perResourceTimeout the check of every installed resource.
+	timeout            time.Duration
+	perResourceTimeout time.Duration
+	// retries and retryBackoff configure the retries of the reads of the installed resources failing with a
+	// transient error.
+	retries      int
+	retryBackoff time.Duration
+	// concurrency is how many installed resources are read from the cluster at once.
+	concurrency int
+	// qps and burst are the client-side rate limit of the requests to the API server.
+	qps   float32
+	burst int
+	// logMu serializes the lines printed by the reads of installed resources running at once.
+	logMu sync.Mutex
+}
+
+// checkSettings are the settings specific to some of the checks of the verification.
+type checkSettings struct {
+	// names are the optional checks to run.
+	names []string
+	// helmChart is the chart of the Helm values the installation is verified against, the istiod chart if unset.
+	helmChart HelmChart
+	// valuesFiles and setValues are the Helm values the installation is verified against.
+	valuesFiles []string
+	setValues   []string
+	// gatewaySyncTimeout bounds how long the gateway-config-sync check waits for proxies to ACK config.
+	gatewaySyncTimeout time.Duration
+	// gatewayProber, if set, probes gateway LoadBalancer addresses in the gateway-load-balancer check.
+	gatewayProber GatewayProber
+	// webhookCaller, if set, calls the injector webhooks in the injector-webhook check instead of a port-forward.
+	webhookCaller WebhookCaller
+	// imageInspector, if set, reads the platforms of the images of the node data plane in the image-architectures
+	// check.
+	imageInspector ImageInspector
+	// storageBindTimeout bounds how long the PersistentVolumeClaims of installed components are given to bind.
+	storageBindTimeout time.Duration
+	// smokeTestImage and smokeTestTimeout configure the smoke-test check.
+	smokeTestImage   string
+	smokeTestTimeout time.Duration
+	// namespaces restricts the checks of the data plane to these namespaces, if any.
+	namespaces []string
 }
 
 type StatusVerifierOptions func(*StatusVerifier)
@@ -88,68 +175,203 @@ func WithIOP(iop *v1alpha1.IstioOperator) StatusVerifierOptions {
 	}
 }
 
-// NewStatusVerifier creates a new instance of post-install verifier
-// which checks the status of various resources from the manifest.
-func NewStatusVerifier(istioNamespace, manifestsPath, kubeconfig, context string,
-	filenames []string, controlPlaneOpts clioptions.ControlPlaneOptions,
-	options ...StatusVerifierOptions,
-) (*StatusVerifier, error) {
-	client, err := kube.NewCLIClient(kube.BuildClientCmd(kubeconfig, context), "")
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect Kubernetes API server, error: %v", err)
+// WithIstioNamespace sets the namespace of the control plane, istio-system by default.
+func WithIstioNamespace(namespace string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.istioNamespace = namespace
+	}
+}
+
+// WithManifestsPath sets the path of the charts and profiles the installation is rendered from, instead of
+// those compiled into the binary.
+func WithManifestsPath(path string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.manifestsPath = path
 	}
+}
 
+// WithFilenames verifies the installation against the resources of the files, which may contain
+// IstioOperators, instead of the IstioOperators of the cluster.
+func WithFilenames(filenames ...string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.filenames = append(s.filenames, filenames...)
+	}
+}
+
+// WithRevision verifies the control plane of the revision. By default, the revision with running istiod pods
+// is picked, preferring a non-default one.
+func WithRevision(revision string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.controlPlaneOpts.Revision = revision
+	}
+}
+
+// WithClient verifies the cluster of client, such as the in-cluster client of a controller or a fake client in
+// tests, instead of building a client from the kubeconfig. The API calls of an injected client are neither traced
+// nor limited by WithClientRateLimit, which configure the clients built by the verifier only.
+func WithClient(client kube.CLIClient) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.client = client
+	}
+}
+
+// WithKubeConfig selects the cluster to verify by kubeconfig file and context. By default, the kubeconfig is
+// loaded with the usual rules of kubectl and its current context is used.
+func WithKubeConfig(kubeconfig, context string) StatusVerifierOptions {
+	return func(s *StatusVerifier) {
+		s.kubeconfig = kubeconfig
+		s.kubeContext = context
+	}
+}
+
+// NewVerifier creates a new instance of post-install verifier configured
+// only by options, for programs embedding the verification.
+func NewVerifier(options ...StatusVerifierOptions) (*StatusVerifier, error) {
 	verifier := StatusVerifier{
-		logger:           clog.NewDefaultLogger(),
-		successMarker:    "✔",
-		failureMarker:    "✘",
-		istioNamespace:   istioNamespace,
-		manifestsPath:    manifestsPath,
-		filenames:        filenames,
-		controlPlaneOpts: controlPlaneOpts,
-		client:           client,
+		logger:         clog.NewDefaultLogger(),
+		successMarker:  DefaultTheme.SuccessMarker,
+		failureMarker:  DefaultTheme.FailureMarker,
+		warningMarker:  DefaultTheme.WarningMarker,
+		istioNamespace: constants.IstioSystemNamespace,
+		concurrency:    DefaultConcurrency,
 	}
 
 	for _, opt := range options {
 		opt(&verifier)
 	}
+	if verifier.client != nil {
+		return &verifier, nil
+	}
+
+	clientConfig := newRateLimitedClientConfig(kube.BuildClientCmd(verifier.kubeconfig, verifier.kubeContext), &verifier)
+	client, err := kube.NewCLIClient(newTracingClientConfig(clientConfig, &verifier), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect Kubernetes API server, error: %v", err)
+	}
+	verifier.client = client
 
 	return &verifier, nil
 }
 
-func (v *StatusVerifier) Colorize() {
-	v.successMarker = color.New(color.FgGreen).Sprint(v.successMarker)
-	v.failureMarker = color.New(color.FgRed).Sprint(v.failureMarker)
+// NewStatusVerifier creates a new instance of post-install verifier
+// which checks the status of various resources from the manifest.
+// It takes the flags of istioctl verify-install; NewVerifier takes
+// options only.
+func NewStatusVerifier(istioNamespace, manifestsPath, kubeconfig, context string,
+	filenames []string, controlPlaneOpts clioptions.ControlPlaneOptions,
+	options ...StatusVerifierOptions,
+) (*StatusVerifier, error) {
+	return NewVerifier(append([]StatusVerifierOptions{
+		WithIstioNamespace(istioNamespace),
+		WithManifestsPath(manifestsPath),
+		WithKubeConfig(kubeconfig, context),
+		WithFilenames(filenames...),
+		func(s *StatusVerifier) {
+			s.controlPlaneOpts = controlPlaneOpts
+		},
+	}, options...)...)
+}
+
+// NewStatusVerifierWithClient is NewStatusVerifier verifying the cluster of client, rather than that of a
+// kubeconfig, for controllers running in the cluster and tests with fake clients.
+func NewStatusVerifierWithClient(client kube.CLIClient, istioNamespace, manifestsPath string,
+	filenames []string, controlPlaneOpts clioptions.ControlPlaneOptions,
+	options ...StatusVerifierOptions,
+) (*StatusVerifier, error) {
+	return NewStatusVerifier(istioNamespace, manifestsPath, "", "", filenames, controlPlaneOpts,
+		append([]StatusVerifierOptions{WithClient(client)}, options...)...)
 }
 
 // Verify implements Verifier interface. Here we check status of deployment
-// and jobs, count various resources for verification.
-func (v *StatusVerifier) Verify() error {
+// and jobs, count various resources for verification. The result is returned
+// whether the verification passed or not.
+func (v *StatusVerifier) Verify() (*VerificationResult, error) {
+	return v.VerifyContext(context.Background())
+}
+
+// VerifyContext is Verify with a context bounding all the API calls of the verification, so that it can be
+// canceled or given a deadline. Checks waiting for resources stop waiting once ctx is done.
+func (v *StatusVerifier) VerifyContext(ctx context.Context) (*VerificationResult, error) {
+	start := time.Now()
+	v.results = nil
+	v.crdCount, v.istioDeploymentCount, v.daemonSetCount = 0, 0, 0
+	v.checkDurations = nil
+	v.coverage = nil
+	v.sources = nil
+	v.operators = nil
+	v.progressState = Progress{}
+	// Events and history are recorded with ctx rather than verifyCtx, so that timed out verifications are too.
+	verifyCtx, cancel := v.verificationContext(ctx)
+	defer cancel()
+	verifyCtx, span := tracing.Start(verifyCtx, "verifier.Verify")
+	span.SetAttributes(
+		attribute.String("istio_namespace", v.istioNamespace),
+		attribute.String("revision", revisions.Normalize(v.controlPlaneOpts.Revision)),
+	)
+	v.traceCtx = verifyCtx
+	defer func() {
+		v.traceCtx = nil
+	}()
+	err := v.verify(verifyCtx)
+	if err != nil && ctx.Err() == nil && isTimeout(verifyCtx.Err()) {
+		err = &VerificationError{Class: FailureTimeout, msg: fmt.Sprintf("the verification did not complete within %v", v.timeout), err: err}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	var verr *VerificationError
+	if err != nil && !errors.As(err, &verr) && isAPIAccessError(err) {
+		err = &VerificationError{Class: FailureAPIAccess, msg: fmt.Sprintf("failed to access the API server: %v", err), err: err}
+	}
+	if v.events {
+		v.recordEvents(ctx)
+	}
+	if rerr := v.writeReports(err); rerr != nil {
+		err = multierror.Append(err, rerr).ErrorOrNil()
+	}
+	result := v.result(start)
+	if v.metrics != nil {
+		v.metrics.observe(result, err)
+	}
+	if v.history {
+		if herr := v.recordHistory(ctx, newHistoryRecord(result, err)); herr != nil {
+			v.logger.LogAndErrorf("failed to record the verification in configmap %s/%s: %v", v.istioNamespace, HistoryConfigMap, herr)
+		}
+	}
+	return result, err
+}
+
+func (v *StatusVerifier) verify(ctx context.Context) error {
 	if v.iop != nil {
-		return v.verifyFinalIOP()
+		return v.verifyFinalIOP(ctx)
+	}
+	if len(v.checks.valuesFiles) > 0 || len(v.checks.setValues) > 0 {
+		return v.verifyHelmValues(ctx)
 	}
 	if len(v.filenames) == 0 {
-		return v.verifyInstallIOPRevision()
+		return v.verifyInstallIOPRevision(ctx)
 	}
-	return v.verifyInstall()
+	return v.verifyInstall(ctx)
 }
 
-func (v *StatusVerifier) verifyInstallIOPRevision() error {
-	var err error
+func (v *StatusVerifier) verifyInstallIOPRevision(ctx context.Context) error {
+	revs, err := revisions.Discover(ctx, v.client, v.istioNamespace)
+	if err != nil {
+		return err
+	}
 	if v.controlPlaneOpts.Revision == "" {
-		v.controlPlaneOpts.Revision, err = v.getRevision()
-		if err != nil {
-			return err
-		}
+		v.controlPlaneOpts.Revision = v.getRevision(revs)
 	} else if v.controlPlaneOpts.Revision == "default" {
 		v.controlPlaneOpts.Revision = ""
 	}
-	iops, err := v.operatorsFromCluster(v.controlPlaneOpts.Revision)
+	iops, err := v.operatorsFromCluster(revs, v.controlPlaneOpts.Revision)
 	if err != nil {
 		// At this point we know there is no IstioOperator defining a control plane.  This may
 		// be the case in a Istio cluster with external control plane.
 		v.logger.LogAndErrorf("error while fetching revision %s: %v", v.controlPlaneOpts.Revision, err.Error())
-		injector, err2 := v.injectorFromCluster(v.controlPlaneOpts.Revision)
+		injector, err2 := v.injectorFromCluster(revs, v.controlPlaneOpts.Revision)
 		if err2 == nil && injector != nil {
 			// The cluster *is* configured for Istio, but no IOP is present.  This could mean
 			// - the user followed our remote control plane instructions
@@ -161,6 +383,7 @@ func (v *StatusVerifier) verifyInstallIOPRevision() error {
 	}
 	var crdTotal, istioDeploymentTotal, daemonSetTotal int
 	multiErr := &multierror.Error{}
+	v.operators = iops
 	for _, iop := range iops {
 		if v.manifestsPath != "" {
 			iop.Spec.InstallPackagePath = v.manifestsPath
@@ -175,7 +398,7 @@ func (v *StatusVerifier) verifyInstallIOPRevision() error {
 			return err
 		}
 		crdCount, istioDeploymentCount, daemonSetCount, err := v.verifyPostInstallIstioOperator(
-			mergedIOP, fmt.Sprintf("in cluster operator %s", mergedIOP.GetName()))
+			ctx, mergedIOP, fmt.Sprintf("in cluster operator %s", mergedIOP.GetName()))
 		if err != nil {
 			multiErr = multierror.Append(multiErr, err)
 		}
@@ -183,43 +406,34 @@ func (v *StatusVerifier) verifyInstallIOPRevision() error {
 		istioDeploymentTotal += istioDeploymentCount
 		daemonSetTotal += daemonSetCount
 	}
-	return v.reportStatus(crdTotal, istioDeploymentTotal, daemonSetTotal, multiErr.ErrorOrNil())
+	return v.reportStatus(ctx, crdTotal, istioDeploymentTotal, daemonSetTotal, multiErr.ErrorOrNil())
 }
 
-func (v *StatusVerifier) getRevision() (string, error) {
+// getRevision picks the revision to verify when none was specified: a non-default revision with
+// running istiod pods if there is one, otherwise the default revision.
+func (v *StatusVerifier) getRevision(revs revisions.Revisions) string {
 	var revision string
-	var revs string
-	revCount := 0
-	pods, err := v.client.PodsForSelector(context.TODO(), v.istioNamespace, "app=istiod")
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch istiod pod, error: %v", err)
-	}
-	for _, pod := range pods.Items {
-		rev := pod.ObjectMeta.GetLabels()[label.IoIstioRev.Name]
-		revCount++
-		if rev == "default" {
-			continue
+	podCount := 0
+	for _, rev := range revs {
+		podCount += len(rev.Pods)
+		if len(rev.Pods) > 0 && rev.Name != "default" {
+			revision = rev.Name
 		}
-		revision = rev
-	}
-	if revision == "" {
-		revs = "default"
-	} else {
-		revs = revision
 	}
-	v.logger.LogAndPrintf("%d Istio control planes detected, checking --revision %q only", revCount, revs)
-	return revision, nil
+	checked := revisions.Normalize(revision)
+	v.logf(VerbosityNormal, "%d Istio control planes detected, checking --revision %q only", podCount, checked)
+	return revision
 }
 
-func (v *StatusVerifier) verifyFinalIOP() error {
+func (v *StatusVerifier) verifyFinalIOP(ctx context.Context) error {
 	crdCount, istioDeploymentCount, daemonSetCount, err := v.verifyPostInstallIstioOperator(
-		v.iop, fmt.Sprintf("IOP:%s", v.iop.GetName()))
-	return v.reportStatus(crdCount, istioDeploymentCount, daemonSetCount, err)
+		ctx, v.iop, fmt.Sprintf("IOP:%s", v.iop.GetName()))
+	return v.reportStatus(ctx, crdCount, istioDeploymentCount, daemonSetCount, err)
 }
 
-func (v *StatusVerifier) verifyInstall() error {
+func (v *StatusVerifier) verifyInstall(ctx context.Context) error {
 	// This is not a pre-check.  Check that the supplied resources exist in the cluster
-	r := resource.NewBuilder(v.client.UtilFactory()).
+	r := resource.NewBuilder(v.clientGetter()).
 		Unstructured().
 		FilenameParam(false, &resource.FilenameOptions{Filenames: v.filenames}).
 		Flatten().
@@ -228,12 +442,14 @@ func (v *StatusVerifier) verifyInstall() error {
 		return r.Err()
 	}
 	visitor := genericclioptions.ResourceFinderForResult(r).Do()
+	v.sources = append(v.sources, v.filenames...)
 	crdCount, istioDeploymentCount, generatedDaemonsets, err := v.verifyPostInstall(
-		visitor, strings.Join(v.filenames, ","))
-	return v.reportStatus(crdCount, istioDeploymentCount, generatedDaemonsets, err)
+		ctx, visitor, strings.Join(v.filenames, ","))
+	return v.reportStatus(ctx, crdCount, istioDeploymentCount, generatedDaemonsets, err)
 }
 
-func (v *StatusVerifier) verifyPostInstallIstioOperator(iop *v1alpha1.IstioOperator, filename string) (int, int, int, error) {
+func (v *StatusVerifier) verifyPostInstallIstioOperator(ctx context.Context, iop *v1alpha1.IstioOperator, filename string) (int, int, int, error) {
+	v.sources = append(v.sources, filename)
 	t := translate.NewTranslator()
 	ver, err := v.client.GetKubernetesVersion()
 	if err != nil {
@@ -251,14 +467,34 @@ func (v *StatusVerifier) verifyPostInstallIstioOperator(iop *v1alpha1.IstioOpera
 	if len(errs) > 0 {
 		return 0, 0, 0, errs.ToError()
 	}
+	return v.verifyManifests(ctx, manifests, filename)
+}
 
-	builder := resource.NewBuilder(v.client.UtilFactory()).ContinueOnError().Unstructured()
-	for cat, manifest := range manifests {
-		for i, manitem := range manifest {
-			reader := strings.NewReader(manitem)
-			pseudoFilename := fmt.Sprintf("%s:%d generated from %s", cat, i, filename)
-			builder = builder.Stream(reader, pseudoFilename)
+// verifyManifests checks the installed resources of the rendered manifests. The manifests of each component are
+// streamed to the builder through a pipe, named after the component which the resources read from it are
+// attributed to, so that the builder decodes the resources as they are checked instead of all at once.
+func (v *StatusVerifier) verifyManifests(ctx context.Context, manifests name.ManifestMap, filename string) (int, int, int, error) {
+	// The manifests are handed to the builder in a stable component order, so that the resources are checked
+	// in the same order on every run.
+	components := make([]string, 0, len(manifests))
+	for c := range manifests {
+		components = append(components, string(c))
+	}
+	sort.Strings(components)
+	v.resourcesDiscovered(manifestResourceCount(manifests))
+	builder := resource.NewBuilder(v.clientGetter()).ContinueOnError().Unstructured()
+	readers := make([]*io.PipeReader, 0, len(components))
+	// Closing the readers unblocks the writers of the streams the builder did not read to the end.
+	defer func() {
+		for _, pr := range readers {
+			_ = pr.Close()
 		}
+	}()
+	for _, c := range components {
+		pr, pw := io.Pipe()
+		readers = append(readers, pr)
+		go streamManifests(pw, manifests[name.ComponentName(c)])
+		builder = builder.Stream(pr, fmt.Sprintf(manifestSourceFormat, c, filename))
 	}
 	r := builder.Flatten().Do()
 	if r.Err() != nil {
@@ -266,162 +502,183 @@ func (v *StatusVerifier) verifyPostInstallIstioOperator(iop *v1alpha1.IstioOpera
 	}
 	visitor := genericclioptions.ResourceFinderForResult(r).Do()
 	// Indirectly RECURSE back into verifyPostInstall with the manifest we just generated
-	generatedCrds, generatedDeployments, generatedDaemonSets, err := v.verifyPostInstall(
-		visitor,
-		fmt.Sprintf("generated from %s", filename))
-	if err != nil {
-		return generatedCrds, generatedDeployments, generatedDaemonSets, err
+	return v.verifyPostInstall(ctx, visitor, fmt.Sprintf("generated from %s", filename))
+}
+
+// streamManifests writes the manifests as a single multi-document YAML stream, and closes w once done or once
+// the reading side has gone away.
+func streamManifests(w *io.PipeWriter, manifests []string) {
+	for _, m := range manifests {
+		if _, err := io.WriteString(w, m); err != nil {
+			_ = w.CloseWithError(err)
+			return
+		}
+		if _, err := io.WriteString(w, yamlSeparator); err != nil {
+			_ = w.CloseWithError(err)
+			return
+		}
 	}
+	_ = w.Close()
+}
 
-	return generatedCrds, generatedDeployments, generatedDaemonSets, nil
+// installedCounts are the installed resources found by verifyPostInstall.
+type installedCounts struct {
+	crds             int
+	istioDeployments int
+	daemonSets       int
 }
 
-func (v *StatusVerifier) verifyPostInstall(visitor resource.Visitor, filename string) (int, int, int, error) {
-	crdCount := 0
-	istioDeploymentCount := 0
-	daemonSetCount := 0
-	err := visitor.Visit(func(info *resource.Info, err error) error {
+func (v *StatusVerifier) verifyPostInstall(ctx context.Context, visitor resource.Visitor, filename string) (int, int, int, error) {
+	counts := &installedCounts{}
+	var err error
+	if v.concurrency > 1 {
+		err = v.checkResourcesConcurrently(ctx, visitor, filename, counts)
+	} else {
+		err = visitor.Visit(func(info *resource.Info, err error) error {
+			if err != nil {
+				return err
+			}
+			return v.checkResource(ctx, info, nil, filename, counts)
+		})
+	}
+	return counts.crds, counts.istioDeployments, counts.daemonSets, err
+}
+
+// checkResource checks an installed resource and records the result. The resource is read from the cluster,
+// unless the outcome of reading it beforehand is passed as fetched.
+func (v *StatusVerifier) checkResource(ctx context.Context, info *resource.Info, fetched *fetchedResource, filename string,
+	counts *installedCounts,
+) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+	if err != nil {
+		return err
+	}
+	un := &unstructured.Unstructured{Object: content}
+	kind := un.GetKind()
+	name := un.GetName()
+	namespace := un.GetNamespace()
+	// The check of the resource, and the API calls it makes, are bounded by the per resource timeout.
+	ctx, cancel := v.resourceContext(ctx, kind)
+	defer cancel()
+	component := un.GetLabels()[componentLabel]
+	if component == "" {
+		component = manifestComponent(info.Source)
+	}
+	defer v.attributeResults(len(v.results), component)
+	if namespace == "" {
+		namespace = v.istioNamespace
+	}
+	v.checkStarted(kind, name, namespace)
+	v.resourceStarted(kind, name, namespace)
+	defer v.resourceChecked()
+	defer v.startSpan("verifier.CheckResource",
+		attribute.String("kind", kind), attribute.String("name", name), attribute.String("namespace", namespace))()
+	if fetched == nil {
+		f := v.fetchResource(ctx, info, un)
+		fetched = &f
+	}
+	switch kind {
+	case "Deployment":
+		if fetched.err != nil {
+			return v.reportProblem(kind, name, namespace, fetched.err)
+		}
+		deployment := fetched.obj.(*appsv1.Deployment)
+		if err = verifyDeploymentStatus(deployment); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeDeploymentUnavailable, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+		if err = verifyIstiodEnv(un, deployment); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeDeploymentEnvDrift, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+		if namespace == v.istioNamespace && strings.HasPrefix(name, "istio") {
+			counts.istioDeployments++
+		}
+	case "Job":
+		if fetched.err != nil {
+			return v.reportProblem(kind, name, namespace, fetched.err)
+		}
+		if err := verifyJobPostInstall(fetched.obj.(*v1batch.Job)); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeJobFailed, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+	case "PersistentVolumeClaim":
+		if err := v.verifyPVC(ctx, namespace, name); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeStorageUnbound, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+	case "StatefulSet":
+		if fetched.err != nil {
+			return v.reportProblem(kind, name, namespace, fetched.err)
+		}
+		if err := v.verifyStatefulSetStorage(ctx, fetched.obj.(*appsv1.StatefulSet)); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeStorageUnbound, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+	case "IstioOperator":
+		// It is not a problem if the cluster does not include the IstioOperator
+		// we are checking.  Instead, verify the cluster has the things the
+		// IstioOperator specifies it should have.
+
+		// IstioOperator isn't part of pkg/config/schema/collections,
+		// usual conversion not available.  Convert unstructured to string
+		// and ask operator code to unmarshal.
+		fixTimestampRelatedUnmarshalIssues(un)
+
+		by := util.ToYAML(un)
+		unmergedIOP, err := operator_istio.UnmarshalIstioOperator(by, true)
 		if err != nil {
-			return err
+			return v.reportProblem(kind, name, namespace, err)
 		}
-		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+		profile := manifest.GetProfile(unmergedIOP)
+		iop, err := manifest.GetMergedIOP(by, profile, v.manifestsPath, v.controlPlaneOpts.Revision,
+			v.client, v.logger)
+		if err != nil {
+			return v.reportProblem(kind, name, namespace, err)
+		}
+		if v.manifestsPath != "" {
+			iop.Spec.InstallPackagePath = v.manifestsPath
+		}
+		if v1alpha1.Namespace(iop.Spec) == "" {
+			v1alpha1.SetNamespace(iop.Spec, v.istioNamespace)
+		}
+		generatedCrds, generatedDeployments, generatedDaemonSets, err := v.verifyPostInstallIstioOperator(ctx, iop, filename)
+		counts.crds += generatedCrds
+		counts.istioDeployments += generatedDeployments
+		counts.daemonSets += generatedDaemonSets
 		if err != nil {
 			return err
 		}
-		un := &unstructured.Unstructured{Object: content}
-		kind := un.GetKind()
-		name := un.GetName()
-		namespace := un.GetNamespace()
-		kinds := resourceKinds(un)
-		if namespace == "" {
-			namespace = v.istioNamespace
-		}
-		switch kind {
-		case "Deployment":
-			deployment := &appsv1.Deployment{}
-			err = info.Client.
-				Get().
-				Resource(kinds).
-				Namespace(namespace).
-				Name(name).
-				VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
-				Do(context.TODO()).
-				Into(deployment)
-			if err != nil {
-				v.reportFailure(kind, name, namespace, err)
-				return err
-			}
-			if err = verifyDeploymentStatus(deployment); err != nil {
-				ivf := istioVerificationFailureError(filename, err)
-				v.reportFailure(kind, name, namespace, ivf)
-				return ivf
-			}
-			if namespace == v.istioNamespace && strings.HasPrefix(name, "istio") {
-				istioDeploymentCount++
-			}
-		case "Job":
-			job := &v1batch.Job{}
-			err = info.Client.
-				Get().
-				Resource(kinds).
-				Namespace(namespace).
-				Name(name).
-				VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
-				Do(context.TODO()).
-				Into(job)
-			if err != nil {
-				v.reportFailure(kind, name, namespace, err)
-				return err
-			}
-			if err := verifyJobPostInstall(job); err != nil {
-				ivf := istioVerificationFailureError(filename, err)
-				v.reportFailure(kind, name, namespace, ivf)
-				return ivf
-			}
-		case "IstioOperator":
-			// It is not a problem if the cluster does not include the IstioOperator
-			// we are checking.  Instead, verify the cluster has the things the
-			// IstioOperator specifies it should have.
-
-			// IstioOperator isn't part of pkg/config/schema/collections,
-			// usual conversion not available.  Convert unstructured to string
-			// and ask operator code to unmarshal.
-			fixTimestampRelatedUnmarshalIssues(un)
-
-			by := util.ToYAML(un)
-			unmergedIOP, err := operator_istio.UnmarshalIstioOperator(by, true)
-			if err != nil {
-				v.reportFailure(kind, name, namespace, err)
-				return err
-			}
-			profile := manifest.GetProfile(unmergedIOP)
-			iop, err := manifest.GetMergedIOP(by, profile, v.manifestsPath, v.controlPlaneOpts.Revision,
-				v.client, v.logger)
-			if err != nil {
-				v.reportFailure(kind, name, namespace, err)
-				return err
-			}
-			if v.manifestsPath != "" {
-				iop.Spec.InstallPackagePath = v.manifestsPath
-			}
-			if v1alpha1.Namespace(iop.Spec) == "" {
-				v1alpha1.SetNamespace(iop.Spec, v.istioNamespace)
-			}
-			generatedCrds, generatedDeployments, generatedDaemonSets, err := v.verifyPostInstallIstioOperator(iop, filename)
-			crdCount += generatedCrds
-			istioDeploymentCount += generatedDeployments
-			daemonSetCount += generatedDaemonSets
-			if err != nil {
-				return err
-			}
-		case "DaemonSet":
-			ds := &appsv1.DaemonSet{}
-			err = info.Client.
-				Get().
-				Resource(kinds).
-				Namespace(namespace).
-				Name(name).
-				VersionedParams(&metav1.GetOptions{}, scheme.ParameterCodec).
-				Do(context.TODO()).
-				Into(ds)
-			if err != nil {
-				v.reportFailure(kind, name, namespace, err)
-				return err
-			}
-			daemonSetCount++
-			if err = verifyDaemonSetStatus(ds); err != nil {
-				ivf := istioVerificationFailureError(filename, err)
-				v.reportFailure(kind, name, namespace, ivf)
-				return ivf
-			}
-		default:
-			result := info.Client.
-				Get().
-				Resource(kinds).
-				Name(name).
-				Do(context.TODO())
-			if result.Error() != nil {
-				result = info.Client.
-					Get().
-					Resource(kinds).
-					Namespace(namespace).
-					Name(name).
-					Do(context.TODO())
-				if result.Error() != nil {
-					v.reportFailure(kind, name, namespace, result.Error())
-					return istioVerificationFailureError(filename,
-						fmt.Errorf("the required %s:%s is not ready due to: %v",
-							kind, name, result.Error()))
-				}
+	case "DaemonSet":
+		if fetched.err != nil {
+			return v.reportProblem(kind, name, namespace, fetched.err)
+		}
+		counts.daemonSets++
+		if err = verifyDaemonSetStatus(fetched.obj.(*appsv1.DaemonSet)); err != nil {
+			ivf := istioVerificationFailureError(filename, withCode(CodeDaemonSetUnavailable, err))
+			return v.reportProblem(kind, name, namespace, ivf)
+		}
+	default:
+		if fetched.err != nil {
+			if err := v.reportProblem(kind, name, namespace, fetched.err); err != nil {
+				return istioVerificationFailureError(filename,
+					fmt.Errorf("the required %s:%s is not ready due to: %w",
+						kind, name, err))
 			}
-			if kind == "CustomResourceDefinition" {
-				crdCount++
+			return nil
+		}
+		if kind == "CustomResourceDefinition" {
+			counts.crds++
+		}
+		if kind == "ConfigMap" && name == cniConfigMapName {
+			if err := v.verifyCNIExclusions(ctx, un, namespace); err != nil {
+				ivf := istioVerificationFailureError(filename, withCode(CodeCNIExclusions, err))
+				return v.reportProblem(kind, name, namespace, ivf)
 			}
 		}
-		v.logger.LogAndPrintf("%s %s: %s.%s checked successfully", v.successMarker, kind, name, namespace)
-		return nil
-	})
-	return crdCount, istioDeploymentCount, daemonSetCount, err
+	}
+	v.reportSuccess(kind, name, namespace)
+	return nil
 }
 
 func resourceKinds(un *unstructured.Unstructured) string {
@@ -437,26 +694,17 @@ func resourceKinds(un *unstructured.Unstructured) string {
 }
 
 // Find Istio injector matching revision.  ("" matches any revision.)
-func (v *StatusVerifier) injectorFromCluster(revision string) (*admitv1.MutatingWebhookConfiguration, error) {
-	hooks, err := v.client.Kube().AdmissionregistrationV1().MutatingWebhookConfigurations().List(context.Background(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-
+func (v *StatusVerifier) injectorFromCluster(revs revisions.Revisions, revision string) (*admitv1.MutatingWebhookConfiguration, error) {
 	revCount := 0
 	var hookmatch *admitv1.MutatingWebhookConfiguration
-	for i, hook := range hooks.Items {
-		rev := hook.ObjectMeta.GetLabels()[label.IoIstioRev.Name]
-		if rev != "" {
-			revCount++
-			revision = rev
-			if revision == "" || revision == rev {
-				hookmatch = &hooks.Items[i]
-			}
+	for _, rev := range revs {
+		revCount += len(rev.Webhooks) + len(rev.TagWebhooks)
+		if len(rev.Webhooks) > 0 && hookmatch == nil && (revision == "" || rev.Name == revisions.Normalize(revision)) {
+			hookmatch = &rev.Webhooks[0]
 		}
 	}
 
-	v.logger.LogAndPrintf("%d Istio injectors detected", revCount)
+	v.logf(VerbosityNormal, "%d Istio injectors detected", revCount)
 	if hookmatch != nil {
 		return hookmatch, nil
 	}
@@ -464,44 +712,50 @@ func (v *StatusVerifier) injectorFromCluster(revision string) (*admitv1.Mutating
 	return nil, fmt.Errorf("Istio injector revision %q not found", revision) // nolint: stylecheck
 }
 
-// Find an IstioOperator matching revision in the cluster.  The IstioOperators
-// don't have a label for their revision, so we parse them and check .Spec.Revision
-func (v *StatusVerifier) operatorsFromCluster(revision string) ([]*v1alpha1.IstioOperator, error) {
-	iops, err := AllOperatorsInCluster(v.client.Dynamic())
-	if err != nil {
-		return nil, err
-	}
-	iopsMatch := make([]*v1alpha1.IstioOperator, 0)
-	for _, iop := range iops {
-		if iop.Spec.Revision == revision ||
-			(iop.Spec.Revision == "default" && revision == "") ||
-			(iop.Spec.Revision == "" && revision == "default") {
-			iopsMatch = append(iopsMatch, iop)
-		}
-	}
-	if len(iopsMatch) > 0 {
-		return iopsMatch, nil
+// Find the IstioOperators installing revision in the cluster.
+func (v *StatusVerifier) operatorsFromCluster(revs revisions.Revisions, revision string) ([]*v1alpha1.IstioOperator, error) {
+	if rev := revs.Get(revision); rev != nil && len(rev.IstioOperators) > 0 {
+		return rev.IstioOperators, nil
 	}
 	return nil, fmt.Errorf("control plane revision %q not found", revision)
 }
 
-func (v *StatusVerifier) reportStatus(crdCount, istioDeploymentCount, daemonSetCount int, err error) error {
-	v.logger.LogAndPrintf("Checked %v custom resource definitions", crdCount)
-	v.logger.LogAndPrintf("Checked %v Istio Deployments", istioDeploymentCount)
+func (v *StatusVerifier) reportStatus(ctx context.Context, crdCount, istioDeploymentCount, daemonSetCount int, err error) error {
+	v.crdCount, v.istioDeploymentCount, v.daemonSetCount = crdCount, istioDeploymentCount, daemonSetCount
+	if checkErr := v.runChecks(ctx); checkErr != nil {
+		err = multierror.Append(err, checkErr)
+	}
+	v.reportComponentImages(ctx)
+	v.reportHealthScore()
+	v.reportComponentSummary()
+	if istioDeploymentCount > 0 {
+		v.reportInjectionCoverage(ctx)
+	}
+	v.reportSkippedChecks()
+	v.logf(VerbosityNormal, "Checked %v custom resource definitions", crdCount)
+	v.logf(VerbosityNormal, "Checked %v Istio Deployments", istioDeploymentCount)
 	if daemonSetCount > 0 {
-		v.logger.LogAndPrintf("Checked %v Istio Daemonsets", daemonSetCount)
+		v.logf(VerbosityNormal, "Checked %v Istio Daemonsets", daemonSetCount)
 	}
 	if istioDeploymentCount == 0 {
 		if err != nil {
-			v.logger.LogAndPrintf("! No Istio installation found: %v", err)
+			v.logger.LogAndPrintf("%s No Istio installation found: %v", v.warningMark(), err)
 		} else {
-			v.logger.LogAndPrintf("! No Istio installation found")
+			v.logger.LogAndPrintf("%s No Istio installation found", v.warningMark())
+		}
+		class := FailureNoInstallation
+		if c := classifyFailure(err); err != nil && (c == FailureAPIAccess || c == FailureTimeout) {
+			class = c
 		}
-		return fmt.Errorf("no Istio installation found")
+		return &VerificationError{Class: class, msg: "no Istio installation found", err: err}
 	}
 	if err != nil {
 		// Don't return full error; it is usually an unwieldy aggregate
-		return fmt.Errorf("Istio installation failed") // nolint
+		return &VerificationError{Class: classifyFailure(err), msg: "Istio installation failed", err: err}
+	}
+	if warnings := v.warningCount(); warnings > 0 && v.failsOnWarnings() {
+		v.logger.LogAndPrintf("%s Istio is installed, but %d checks warned", v.failureMarker, warnings)
+		return &VerificationError{Class: FailureUnhealthy, msg: fmt.Sprintf("Istio installation has %d warnings", warnings)}
 	}
 	v.logger.LogAndPrintf("%s Istio is installed and verified successfully", v.successMarker)
 	return nil
@@ -516,31 +770,36 @@ func fixTimestampRelatedUnmarshalIssues(un *unstructured.Unstructured) {
 	un.SetManagedFields([]metav1.ManagedFieldsEntry{})
 }
 
-// Find all IstioOperator in the cluster.
-func AllOperatorsInCluster(client dynamic.Interface) ([]*v1alpha1.IstioOperator, error) {
-	ul, err := client.
-		Resource(istioOperatorGVR).
-		List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return nil, err
-	}
-	retval := make([]*v1alpha1.IstioOperator, 0)
-	for _, un := range ul.Items {
-		fixTimestampRelatedUnmarshalIssues(&un)
-		by := util.ToYAML(un.Object)
-		iop, err := operator_istio.UnmarshalIstioOperator(by, true)
-		if err != nil {
-			return nil, err
-		}
-		retval = append(retval, iop)
-	}
-	return retval, nil
+// AllOperatorsInCluster finds all IstioOperators in the cluster.
+func AllOperatorsInCluster(ctx context.Context, client dynamic.Interface) ([]*v1alpha1.IstioOperator, error) {
+	return revisions.IstioOperators(ctx, client)
 }
 
 func istioVerificationFailureError(filename string, reason error) error {
-	return fmt.Errorf("Istio installation failed, incomplete or does not match \"%s\": %v", filename, reason) // nolint
+	return fmt.Errorf("Istio installation failed, incomplete or does not match \"%s\": %w", filename, reason) // nolint
 }
 
 func (v *StatusVerifier) reportFailure(kind, name, namespace string, err error) {
+	v.recordError(kind, name, namespace, checkFailed, err)
+	if v.renderCheck() {
+		return
+	}
 	v.logger.LogAndPrintf("%s %s: %s.%s: %v", v.failureMarker, kind, name, namespace, err)
 }
+
+// reportWarning reports a problem which does not fail the verification.
+func (v *StatusVerifier) reportWarning(kind, name, namespace string, err error) {
+	v.recordError(kind, name, namespace, checkWarning, err)
+	if v.renderCheck() {
+		return
+	}
+	v.logger.LogAndPrintf("%s %s: %s.%s: %v", v.warningMark(), kind, name, namespace, err)
+}
+
+func (v *StatusVerifier) reportSuccess(kind, name, namespace string) {
+	v.record(kind, name, namespace, checkPassed, "")
+	if v.renderCheck() {
+		return
+	}
+	v.logf(VerbosityNormal, "%s %s: %s.%s checked successfully", v.successMarker, kind, name, namespace)
+}
@@ -0,0 +1,81 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`
+
+func TestNewVerifier(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, []byte(testKubeConfig), 0o600))
+
+	v, err := NewVerifier(WithKubeConfig(kubeconfig, ""))
+	assert.NoError(t, err)
+	assert.Equal(t, v.istioNamespace, "istio-system")
+	assert.Equal(t, v.controlPlaneOpts.Revision, "")
+	assert.Equal(t, len(v.filenames), 0)
+
+	v, err = NewVerifier(
+		WithKubeConfig(kubeconfig, "test"),
+		WithIstioNamespace("istio-control"),
+		WithManifestsPath("manifests"),
+		WithFilenames("istio.yaml"),
+		WithRevision("canary"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, v.istioNamespace, "istio-control")
+	assert.Equal(t, v.manifestsPath, "manifests")
+	assert.Equal(t, v.filenames, []string{"istio.yaml"})
+	assert.Equal(t, v.controlPlaneOpts.Revision, "canary")
+}
+
+func TestNewVerifierWithClient(t *testing.T) {
+	client := kube.NewFakeClient()
+	v, err := NewVerifier(WithClient(client), WithIstioNamespace("istio-control"))
+	assert.NoError(t, err)
+	if v.client != client {
+		t.Fatalf("expected the verifier to use the injected client")
+	}
+	assert.Equal(t, v.istioNamespace, "istio-control")
+
+	v, err = NewStatusVerifierWithClient(client, "istio-system", "", []string{"istio.yaml"},
+		clioptions.ControlPlaneOptions{Revision: "canary"})
+	assert.NoError(t, err)
+	if v.client != client {
+		t.Fatalf("expected the verifier to use the injected client")
+	}
+	assert.Equal(t, v.filenames, []string{"istio.yaml"})
+	assert.Equal(t, v.controlPlaneOpts.Revision, "canary")
+}
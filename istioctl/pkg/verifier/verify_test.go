@@ -15,13 +15,25 @@
 package verifier
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 
+	"istio.io/istio/operator/pkg/name"
+	"istio.io/istio/operator/pkg/util/clog"
 	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/test/util/assert"
 )
 
 var (
@@ -200,3 +212,87 @@ func TestFindResourceInSpec(t *testing.T) {
 		})
 	}
 }
+
+// serverFactory builds the clients of the resource builders for a test server, mapping ConfigMaps only.
+type serverFactory struct {
+	kube.PartialFactory
+	host string
+}
+
+func (f serverFactory) ToRESTMapper() (meta.RESTMapper, error) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	return mapper, nil
+}
+
+func (f serverFactory) ToRESTConfig() (*rest.Config, error) {
+	return &rest.Config{Host: f.host}, nil
+}
+
+func TestVerifyManifestsSequentially(t *testing.T) {
+	// Only the istio ConfigMap is installed.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path != "/api/v1/namespaces/istio-system/configmaps/istio" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"apiVersion":"v1","kind":"Status","status":"Failure","message":"not found","reason":"NotFound","code":404}`)
+			return
+		}
+		fmt.Fprint(w, `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"istio","namespace":"istio-system"}}`)
+	}))
+	defer server.Close()
+	configMap := func(name string) string {
+		return fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: istio-system\n", name)
+	}
+	manifests := name.ManifestMap{
+		name.PilotComponentName:   {"# Source: empty.yaml\n---\n" + configMap("istio-sidecar-injector") + "---\n" + configMap("istio")},
+		name.IngressComponentName: {configMap("istio-ingressgateway")},
+	}
+
+	var out bytes.Buffer
+	var progress Progress
+	v := &StatusVerifier{
+		istioNamespace: "istio-system",
+		client:         fakeClientWithFactory{CLIClient: kube.NewFakeClient(), factory: serverFactory{host: server.URL}},
+		logger:         clog.NewConsoleLogger(&out, &out, nil),
+		successMarker:  "✔",
+		failureMarker:  "✘",
+		concurrency:    1,
+		progress:       ProgressReporterFunc(func(p Progress) { progress = p }),
+	}
+	_, _, _, err := v.verifyManifests(context.Background(), manifests, "default profile")
+
+	// Both missing ConfigMaps are reported, the first failure does not stop the check of the others.
+	if err == nil || !strings.Contains(err.Error(), "istio-ingressgateway") || !strings.Contains(err.Error(), "istio-sidecar-injector") {
+		t.Fatalf("expected both missing ConfigMaps to fail the verification, got %v", err)
+	}
+	var got []string
+	for _, r := range v.Results() {
+		got = append(got, r.Name+": "+r.Status+" "+r.Component)
+	}
+	assert.Equal(t, got, []string{
+		"istio-ingressgateway: failed IngressGateways",
+		"istio-sidecar-injector: failed Pilot",
+		"istio: passed Pilot",
+	})
+	assert.Equal(t, progress, Progress{Discovered: 3, Checked: 3})
+}
+
+func TestStreamManifests(t *testing.T) {
+	manifests := []string{"kind: Deployment\nmetadata:\n  name: istiod", "kind: Service\nmetadata:\n  name: istiod"}
+	pr, pw := io.Pipe()
+	go streamManifests(pw, manifests)
+	got, err := io.ReadAll(pr)
+	assert.NoError(t, err)
+	assert.Equal(t, string(got), "kind: Deployment\nmetadata:\n  name: istiod\n---\nkind: Service\nmetadata:\n  name: istiod\n---\n")
+
+	// The writer must not block once the reader goes away.
+	pr, pw = io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		streamManifests(pw, manifests)
+		close(done)
+	}()
+	_ = pr.Close()
+	<-done
+}
@@ -0,0 +1,239 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmplugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	clientextensions "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/istio/istioctl/pkg/cli"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/pkg/kube"
+)
+
+// Cmd returns the wasm command.
+func Cmd(ctx cli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wasm",
+		Short: "Lists, validates and diagnoses WasmPlugins",
+		Long: `Lists the WasmPlugins of the mesh, validates their modules and selectors, and diagnoses the proxies
+failing to load them.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("unknown subcommand %q", args[0])
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.HelpFunc()(cmd, args)
+			return nil
+		},
+	}
+	cmd.AddCommand(listCmd(ctx))
+	cmd.AddCommand(validateCmd(ctx))
+	cmd.AddCommand(diagnoseCmd(ctx))
+	return cmd
+}
+
+func listCmd(ctx cli.Context) *cobra.Command {
+	var allNamespaces bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the WasmPlugins of a namespace",
+		Example: `  # List the WasmPlugins of the default namespace
+  istioctl x wasm list
+
+  # List the WasmPlugins of all namespaces
+  istioctl x wasm list -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := ctx.CLIClient()
+			if err != nil {
+				return fmt.Errorf("failed to create Kubernetes client: %v", err)
+			}
+			ns := ctx.NamespaceOrDefault(ctx.Namespace())
+			if allNamespaces {
+				ns = metav1.NamespaceAll
+			}
+			plugins, err := client.Istio().ExtensionsV1alpha1().WasmPlugins(ns).List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			writer := cmd.OutOrStdout()
+			if len(plugins.Items) == 0 {
+				fmt.Fprintln(writer, "No WasmPlugins found.")
+				return nil
+			}
+			sort.Slice(plugins.Items, func(i, j int) bool {
+				if plugins.Items[i].Namespace != plugins.Items[j].Namespace {
+					return plugins.Items[i].Namespace < plugins.Items[j].Namespace
+				}
+				return plugins.Items[i].Name < plugins.Items[j].Name
+			})
+			w := new(tabwriter.Writer).Init(writer, 0, 8, 5, ' ', 0)
+			if allNamespaces {
+				fmt.Fprint(w, "NAMESPACE\t")
+			}
+			fmt.Fprintln(w, "NAME\tURL\tPHASE\tPRIORITY\tSELECTOR")
+			for _, p := range plugins.Items {
+				if allNamespaces {
+					fmt.Fprintf(w, "%s\t", p.Namespace)
+				}
+				priority := "-"
+				if p.Spec.GetPriority() != nil {
+					priority = fmt.Sprint(p.Spec.GetPriority().GetValue())
+				}
+				selector := klabels.SelectorFromSet(p.Spec.GetSelector().GetMatchLabels()).String()
+				if selector == "" {
+					selector = "<all>"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Spec.GetUrl(), p.Spec.GetPhase(), priority, selector)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "List the WasmPlugins of all namespaces")
+	return cmd
+}
+
+func validateCmd(ctx cli.Context) *cobra.Command {
+	var insecure bool
+	cmd := &cobra.Command{
+		Use:   "validate <name>[.<namespace>]",
+		Short: "Validates the module and the selector of a WasmPlugin",
+		Long: `Fetches the module of a WasmPlugin as its proxies do, from an OCI registry with the image pull secret
+of the plugin or over HTTP, checks it is a Wasm binary module matching the sha256 checksum of the plugin, and
+checks the selector of the plugin selects at least one pod with a proxy. Modules of local files are not fetched.`,
+		Example: `  # Validate the WasmPlugin openid-connect of the istio-system namespace
+  istioctl x wasm validate openid-connect.istio-system
+
+  # Validate a WasmPlugin whose module is served by a registry with a self-signed certificate
+  istioctl x wasm validate basic-auth -n bookinfo --insecure`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, plugin, err := getPlugin(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			v := Validate(context.Background(), client, plugin, ctx.IstioNamespace(), insecure)
+			w := cmd.OutOrStdout()
+			if v.Checksum != "" {
+				fmt.Fprintf(w, "Module checksum: %s\n", v.Checksum)
+			}
+			fmt.Fprintf(w, "Selected proxies: %d\n", len(v.Pods))
+			for _, pod := range v.Pods {
+				fmt.Fprintf(w, "  %s\n", pod)
+			}
+			if !v.Valid() {
+				for _, problem := range v.Problems {
+					fmt.Fprintf(w, "Error: %s\n", problem)
+				}
+				return fmt.Errorf("WasmPlugin %s is not valid", v.Plugin)
+			}
+			fmt.Fprintf(w, "WasmPlugin %s is valid\n", v.Plugin)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "Skip the verification of the certificate of the server of the module")
+	return cmd
+}
+
+func diagnoseCmd(ctx cli.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diagnose <name>[.<namespace>]",
+		Short: "Reports the failures of the proxies to fetch and load the module of a WasmPlugin",
+		Long: `Reads the metrics of the Wasm cache of the istio-agent of every proxy selected by a WasmPlugin, and reports
+its failed fetches, such as download failures and checksum mismatches, and its failed conversions of the Wasm
+config of the proxy. The metrics count the fetches of all the modules of the proxy, not only of the plugin.`,
+		Example: `  # Diagnose the proxies of the WasmPlugin basic-auth of the bookinfo namespace
+  istioctl x wasm diagnose basic-auth.bookinfo`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, plugin, err := getPlugin(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			pods, err := SelectedPods(context.Background(), client, plugin, ctx.IstioNamespace())
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if len(pods) == 0 {
+				fmt.Fprintf(w, "WasmPlugin %s.%s selects no pod with a proxy.\n", plugin.Name, plugin.Namespace)
+				return nil
+			}
+			tw := new(tabwriter.Writer).Init(w, 0, 8, 5, ' ', 0)
+			fmt.Fprintln(tw, "POD\tCACHED MODULES\tFETCHES\tFETCH FAILURES\tCONVERSION FAILURES")
+			failing := 0
+			for _, d := range Diagnose(context.Background(), client, pods) {
+				if d.Err != nil {
+					failing++
+					fmt.Fprintf(tw, "%s\t-\t-\t%v\t-\n", d.Pod, d.Err)
+					continue
+				}
+				if d.Failures() > 0 {
+					failing++
+				}
+				fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", d.Pod, d.CacheEntries, d.Fetches[successResult],
+					formatFailures(d.Fetches), formatFailures(d.Conversions))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+			if failing > 0 {
+				return fmt.Errorf("%d of the %d proxies of WasmPlugin %s.%s report failures", failing, len(pods), plugin.Name, plugin.Namespace)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// getPlugin reads the WasmPlugin named by an argument of the form <name>[.<namespace>].
+func getPlugin(ctx cli.Context, arg string) (kube.CLIClient, *clientextensions.WasmPlugin, error) {
+	client, err := ctx.CLIClient()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+	name, ns := handlers.InferPodInfo(arg, ctx.NamespaceOrDefault(ctx.Namespace()))
+	plugin, err := client.Istio().ExtensionsV1alpha1().WasmPlugins(ns).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get WasmPlugin %s.%s: %v", name, ns, err)
+	}
+	return client, plugin, nil
+}
+
+// formatFailures formats the counts of the results other than success, such as download_failure=2.
+func formatFailures(counts map[string]int) string {
+	var failures []string
+	for result, count := range counts {
+		if result != successResult && count > 0 {
+			failures = append(failures, fmt.Sprintf("%s=%d", result, count))
+		}
+	}
+	if len(failures) == 0 {
+		return "0"
+	}
+	sort.Strings(failures)
+	return strings.Join(failures, ",")
+}
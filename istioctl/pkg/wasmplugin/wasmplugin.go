@@ -0,0 +1,259 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmplugin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+
+	clientextensions "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/wasm"
+)
+
+const (
+	// agentStatusPort is the port of the status server of the istio-agent, which serves its metrics.
+	agentStatusPort = 15020
+
+	// The metrics of the Wasm cache of the istio-agent.
+	remoteFetchMetric      = "istio_agent_wasm_remote_fetch_count"
+	configConversionMetric = "istio_agent_wasm_config_conversion_count"
+	cacheEntriesMetric     = "istio_agent_wasm_cache_entries"
+
+	// successResult is the result label of the successful fetches and conversions.
+	successResult = "success"
+
+	// fetchTimeout and fetchRetries bound the download of modules over HTTP.
+	fetchTimeout = 30 * time.Second
+	fetchRetries = 2
+)
+
+// wasmMagic starts every Wasm binary module.
+var wasmMagic = []byte("\x00asm")
+
+// Validation is the outcome of validating a WasmPlugin.
+type Validation struct {
+	// Plugin is the WasmPlugin, as <name>.<namespace>.
+	Plugin string
+	// Checksum is the sha256 checksum of the module fetched, empty if it could not be fetched.
+	Checksum string
+	// Pods are the pods, as <name>.<namespace>, of the proxies the plugin applies to.
+	Pods []string
+	// Problems are what was found wrong with the plugin.
+	Problems []string
+}
+
+// Valid reports whether no problem was found with the plugin.
+func (v *Validation) Valid() bool {
+	return len(v.Problems) == 0
+}
+
+// ProxyDiagnosis is what the Wasm cache of the istio-agent of a proxy reports.
+type ProxyDiagnosis struct {
+	// Pod is the pod of the proxy, as <name>.<namespace>.
+	Pod string
+	// CacheEntries is the number of modules in the cache.
+	CacheEntries int
+	// Fetches and Conversions count the remote fetches of modules and the conversions of the Wasm config of the
+	// proxy which loads them, by result such as "success" or "download_failure".
+	Fetches     map[string]int
+	Conversions map[string]int
+	// Err is the failure to read the metrics of the agent, if any.
+	Err error
+}
+
+// Failures returns the number of failed fetches and conversions.
+func (d *ProxyDiagnosis) Failures() int {
+	n := 0
+	for _, counts := range []map[string]int{d.Fetches, d.Conversions} {
+		for result, count := range counts {
+			if result != successResult {
+				n += count
+			}
+		}
+	}
+	return n
+}
+
+// SelectedPods returns the pods with a proxy the plugin applies to: the pods of its namespace matching its
+// selector, or of all namespaces when the plugin is in the root namespace of the mesh.
+func SelectedPods(ctx context.Context, client kube.CLIClient, plugin *clientextensions.WasmPlugin,
+	rootNamespace string,
+) ([]corev1.Pod, error) {
+	namespace := plugin.Namespace
+	if namespace == rootNamespace {
+		namespace = metav1.NamespaceAll
+	}
+	selector := klabels.SelectorFromSet(plugin.Spec.GetSelector().GetMatchLabels())
+	pods, err := client.Kube().CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods selected by WasmPlugin %s.%s: %v", plugin.Name, plugin.Namespace, err)
+	}
+	var selected []corev1.Pod
+	for _, pod := range pods.Items {
+		if hasProxy(&pod) {
+			selected = append(selected, pod)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return podName(&selected[i]) < podName(&selected[j])
+	})
+	return selected, nil
+}
+
+// Validate fetches the module of the plugin, checks it is a Wasm module matching the checksum of the plugin, and
+// that the plugin applies to at least one proxy.
+func Validate(ctx context.Context, client kube.CLIClient, plugin *clientextensions.WasmPlugin, rootNamespace string,
+	insecure bool,
+) *Validation {
+	v := &Validation{Plugin: plugin.Name + "." + plugin.Namespace}
+	module, err := fetchModule(ctx, client, plugin, insecure)
+	switch {
+	case err != nil:
+		v.Problems = append(v.Problems, err.Error())
+	case module != nil:
+		sum := sha256.Sum256(module)
+		v.Checksum = hex.EncodeToString(sum[:])
+		if want := plugin.Spec.GetSha256(); want != "" && !strings.EqualFold(want, v.Checksum) {
+			v.Problems = append(v.Problems, fmt.Sprintf("the checksum of the module is %s, but the plugin expects %s", v.Checksum, want))
+		}
+		if !bytes.HasPrefix(module, wasmMagic) {
+			v.Problems = append(v.Problems, "the module is not a Wasm binary module")
+		}
+	}
+
+	pods, err := SelectedPods(ctx, client, plugin, rootNamespace)
+	if err != nil {
+		v.Problems = append(v.Problems, err.Error())
+		return v
+	}
+	for i := range pods {
+		v.Pods = append(v.Pods, podName(&pods[i]))
+	}
+	if len(v.Pods) == 0 {
+		v.Problems = append(v.Problems, fmt.Sprintf("the selector %v of the plugin selects no pod with a proxy",
+			plugin.Spec.GetSelector().GetMatchLabels()))
+	}
+	return v
+}
+
+// fetchModule fetches the module of the plugin as its proxies do. Modules of local files, which only exist on
+// the proxies, are not fetched.
+func fetchModule(ctx context.Context, client kube.CLIClient, plugin *clientextensions.WasmPlugin, insecure bool) ([]byte, error) {
+	u, err := url.Parse(plugin.Spec.GetUrl())
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %v", plugin.Spec.GetUrl(), err)
+	}
+	switch u.Scheme {
+	case "file":
+		return nil, nil
+	case "http", "https":
+		module, err := wasm.NewHTTPFetcher(fetchTimeout, fetchRetries).Fetch(ctx, u.String(), insecure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download the module: %v", err)
+		}
+		return module, nil
+	case "oci", "":
+		opt := wasm.ImageFetcherOption{Insecure: insecure}
+		if name := plugin.Spec.GetImagePullSecret(); name != "" {
+			secret, err := client.Kube().CoreV1().Secrets(plugin.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the image pull secret %s: %v", name, err)
+			}
+			opt.PullSecret = secret.Data[corev1.DockerConfigJsonKey]
+		}
+		binaryFetcher, _, err := wasm.NewImageFetcher(ctx, opt).PrepareFetch(strings.TrimPrefix(u.String(), "oci://"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the image: %v", err)
+		}
+		module, err := binaryFetcher()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract the module from the image: %v", err)
+		}
+		return module, nil
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q of url %q", u.Scheme, plugin.Spec.GetUrl())
+	}
+}
+
+// Diagnose reads the metrics of the Wasm cache of the istio-agent of every pod.
+func Diagnose(ctx context.Context, client kube.CLIClient, pods []corev1.Pod) []ProxyDiagnosis {
+	diagnoses := make([]ProxyDiagnosis, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		metrics, err := client.EnvoyDoWithPort(ctx, pod.Name, pod.Namespace, "GET", "stats/prometheus", agentStatusPort)
+		if err != nil {
+			diagnoses = append(diagnoses, ProxyDiagnosis{Pod: podName(pod), Err: err})
+			continue
+		}
+		d, err := ParseAgentMetrics(metrics)
+		if err != nil {
+			d.Err = err
+		}
+		d.Pod = podName(pod)
+		diagnoses = append(diagnoses, d)
+	}
+	return diagnoses
+}
+
+// ParseAgentMetrics reads the metrics of the Wasm cache from the metrics of an istio-agent, in the Prometheus
+// text format.
+func ParseAgentMetrics(metrics []byte) (ProxyDiagnosis, error) {
+	d := ProxyDiagnosis{Fetches: map[string]int{}, Conversions: map[string]int{}}
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(metrics))
+	if err != nil {
+		return d, fmt.Errorf("failed to parse the metrics of the agent: %v", err)
+	}
+	for name, counts := range map[string]map[string]int{remoteFetchMetric: d.Fetches, configConversionMetric: d.Conversions} {
+		for _, m := range families[name].GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "result" {
+					counts[l.GetValue()] += int(m.GetCounter().GetValue() + m.GetUntyped().GetValue())
+				}
+			}
+		}
+	}
+	for _, m := range families[cacheEntriesMetric].GetMetric() {
+		d.CacheEntries += int(m.GetGauge().GetValue() + m.GetUntyped().GetValue())
+	}
+	return d, nil
+}
+
+func hasProxy(pod *corev1.Pod) bool {
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if c.Name == inject.ProxyContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+func podName(pod *corev1.Pod) string {
+	return pod.Name + "." + pod.Namespace
+}
@@ -0,0 +1,154 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasmplugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	extensions "istio.io/api/extensions/v1alpha1"
+	typev1beta1 "istio.io/api/type/v1beta1"
+	clientextensions "istio.io/client-go/pkg/apis/extensions/v1alpha1"
+	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func pod(name, namespace string, labels map[string]string, containers ...string) *corev1.Pod {
+	p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	for _, c := range containers {
+		p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+	}
+	return p
+}
+
+func plugin(namespace, url, sha string, labels map[string]string) *clientextensions.WasmPlugin {
+	return &clientextensions.WasmPlugin{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: namespace},
+		Spec: extensions.WasmPlugin{
+			Url:      url,
+			Sha256:   sha,
+			Selector: &typev1beta1.WorkloadSelector{MatchLabels: labels},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	module := append([]byte("\x00asm"), 0x01, 0x00, 0x00, 0x00)
+	sum := sha256.Sum256(module)
+	checksum := hex.EncodeToString(sum[:])
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plugin.wasm" {
+			_, _ = w.Write(module)
+			return
+		}
+		_, _ = w.Write([]byte("not a module"))
+	}))
+	defer srv.Close()
+
+	client := kube.NewFakeClient(
+		pod("reviews", "bookinfo", map[string]string{"app": "reviews"}, "reviews", inject.ProxyContainerName),
+		pod("reviews-no-proxy", "bookinfo", map[string]string{"app": "reviews"}, "reviews"),
+		pod("ratings", "other", map[string]string{"app": "reviews"}, inject.ProxyContainerName),
+	)
+	cases := []struct {
+		name     string
+		plugin   *clientextensions.WasmPlugin
+		pods     []string
+		checksum string
+		problems int
+	}{
+		{
+			name:     "valid",
+			plugin:   plugin("bookinfo", srv.URL+"/plugin.wasm", checksum, map[string]string{"app": "reviews"}),
+			pods:     []string{"reviews.bookinfo"},
+			checksum: checksum,
+		},
+		{
+			name:     "root namespace selects all namespaces",
+			plugin:   plugin("istio-system", srv.URL+"/plugin.wasm", "", map[string]string{"app": "reviews"}),
+			pods:     []string{"ratings.other", "reviews.bookinfo"},
+			checksum: checksum,
+		},
+		{
+			name:     "checksum mismatch",
+			plugin:   plugin("bookinfo", srv.URL+"/plugin.wasm", "0123", map[string]string{"app": "reviews"}),
+			pods:     []string{"reviews.bookinfo"},
+			checksum: checksum,
+			problems: 1,
+		},
+		{
+			name:     "not a module and no pod selected",
+			plugin:   plugin("bookinfo", srv.URL+"/other", "", map[string]string{"app": "details"}),
+			checksum: func() string { s := sha256.Sum256([]byte("not a module")); return hex.EncodeToString(s[:]) }(),
+			problems: 2,
+		},
+		{
+			name:     "local files are not fetched",
+			plugin:   plugin("bookinfo", "file:///opt/plugin.wasm", "", map[string]string{"app": "reviews"}),
+			pods:     []string{"reviews.bookinfo"},
+			checksum: "",
+		},
+		{
+			name:     "unsupported scheme",
+			plugin:   plugin("bookinfo", "ftp://example.com/plugin.wasm", "", map[string]string{"app": "reviews"}),
+			pods:     []string{"reviews.bookinfo"},
+			problems: 1,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Validate(context.Background(), client, tt.plugin, "istio-system", false)
+			assert.Equal(t, v.Pods, tt.pods)
+			assert.Equal(t, v.Checksum, tt.checksum)
+			if len(v.Problems) != tt.problems {
+				t.Fatalf("got problems %v, want %d", v.Problems, tt.problems)
+			}
+		})
+	}
+}
+
+func TestParseAgentMetrics(t *testing.T) {
+	metrics := `# TYPE istio_agent_wasm_cache_entries gauge
+istio_agent_wasm_cache_entries 2
+# TYPE istio_agent_wasm_remote_fetch_count counter
+istio_agent_wasm_remote_fetch_count{result="success"} 3
+istio_agent_wasm_remote_fetch_count{result="download_failure"} 2
+istio_agent_wasm_remote_fetch_count{result="checksum_mismatched"} 1
+# TYPE istio_agent_wasm_config_conversion_count counter
+istio_agent_wasm_config_conversion_count{result="success"} 4
+istio_agent_wasm_config_conversion_count{result="fetch_failure"} 3
+# TYPE istio_agent_go_goroutines gauge
+istio_agent_go_goroutines 42
+`
+	d, err := ParseAgentMetrics([]byte(metrics))
+	assert.NoError(t, err)
+	assert.Equal(t, d.CacheEntries, 2)
+	assert.Equal(t, d.Fetches, map[string]int{"success": 3, "download_failure": 2, "checksum_mismatched": 1})
+	assert.Equal(t, d.Conversions, map[string]int{"success": 4, "fetch_failure": 3})
+	assert.Equal(t, d.Failures(), 6)
+	assert.Equal(t, formatFailures(d.Fetches), "checksum_mismatched=1,download_failure=2")
+
+	if _, err := ParseAgentMetrics([]byte("not metrics {")); err == nil {
+		t.Fatal("expected an error parsing invalid metrics")
+	}
+}
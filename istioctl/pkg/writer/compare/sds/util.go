@@ -43,6 +43,8 @@ type SecretItem struct {
 	Destination string `json:"destination"`
 	State       string `json:"state"`
 	SecretMeta
+	// Chain holds every certificate of Data, in the order they appear in.
+	Chain []CertInfo `json:"chain,omitempty"`
 }
 
 // SecretMeta holds selected fields which can be extracted from parsed x509 cert
@@ -54,6 +56,39 @@ type SecretMeta struct {
 	Type         string `json:"type"`
 }
 
+// CertInfo holds the fields of a single certificate of a chain
+type CertInfo struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	SANs         []string  `json:"sans,omitempty"`
+	TrustDomain  string    `json:"trust_domain,omitempty"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	IsCA         bool      `json:"is_ca"`
+}
+
+// Role returns whether the certificate at the given position of its chain is the leaf,
+// an intermediate or a (self-signed) root.
+func (c CertInfo) Role(index int) string {
+	switch {
+	case c.IsCA && c.Subject == c.Issuer:
+		return "root"
+	case index == 0 && !c.IsCA:
+		return "leaf"
+	default:
+		return "intermediate"
+	}
+}
+
+// ExpiringCert is a certificate of a secret which expires within the window checked by ExpiringCerts
+type ExpiringCert struct {
+	Secret string
+	CertInfo
+	// Remaining is the lifetime left, negative if the certificate expired already.
+	Remaining time.Duration
+}
+
 // NewSecretItemBuilder returns a new builder to create a secret item
 func NewSecretItemBuilder() SecretItemBuilder {
 	return &secretItemBuilder{}
@@ -133,6 +168,11 @@ func (s *secretItemBuilder) Build() (SecretItem, error) {
 		}
 		result.SecretMeta = meta
 		result.Valid = meta.Valid
+		// The first certificate parsed already, failing to decode the rest is not fatal.
+		if result.Chain, err = ParseCertChain([]byte(s.data)); err != nil {
+			log.Debugf("failed to parse certificate chain of secret resource %s from source %s: %v",
+				s.name, s.source, err)
+		}
 		return result, nil
 	}
 	result.Valid = false
@@ -231,3 +271,64 @@ func secretMetaFromCert(rawCert []byte) (SecretMeta, error) {
 		Valid:        today.After(cert.NotBefore) && today.Before(cert.NotAfter),
 	}, nil
 }
+
+// ParseCertChain decodes every PEM encoded certificate of the data
+func ParseCertChain(data []byte) ([]CertInfo, error) {
+	var chain []CertInfo
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return chain, fmt.Errorf("certificate %d: %v", len(chain), err)
+		}
+		chain = append(chain, certInfo(cert))
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("failed to parse certificate PEM")
+	}
+	return chain, nil
+}
+
+func certInfo(cert *x509.Certificate) CertInfo {
+	info := CertInfo{
+		Subject:      cert.Subject.String(),
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: fmt.Sprintf("%x", cert.SerialNumber),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		IsCA:         cert.IsCA,
+	}
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, u := range cert.URIs {
+		info.SANs = append(info.SANs, u.String())
+		if u.Scheme == "spiffe" && info.TrustDomain == "" {
+			info.TrustDomain = u.Host
+		}
+	}
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.SANs = append(info.SANs, cert.EmailAddresses...)
+	return info
+}
+
+// ExpiringCerts returns the certificates of the secrets which expire within the given duration
+// from now, including those expired already.
+func ExpiringCerts(secrets []SecretItem, now time.Time, within time.Duration) []ExpiringCert {
+	var expiring []ExpiringCert
+	for _, s := range secrets {
+		for _, c := range s.Chain {
+			if remaining := c.NotAfter.Sub(now); remaining < within {
+				expiring = append(expiring, ExpiringCert{Secret: s.Name, CertInfo: c, Remaining: remaining})
+			}
+		}
+	}
+	return expiring
+}
@@ -0,0 +1,140 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sdscompare
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testChain returns a PEM encoded leaf for a SPIFFE identity followed by the self-signed root that issued it.
+func testChain(t *testing.T, now time.Time, leafLifetime time.Duration) string {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"cluster.local"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, root, root, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafLifetime),
+		URIs:         []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/default/sa/sleep"}},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, root, &leafKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER}))
+}
+
+func TestParseCertChain(t *testing.T) {
+	now := time.Now()
+	chain, err := ParseCertChain([]byte(testChain(t, now, 24*time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(chain))
+	}
+	leaf, root := chain[0], chain[1]
+	if leaf.Role(0) != "leaf" || root.Role(1) != "root" {
+		t.Fatalf("unexpected roles %q and %q", leaf.Role(0), root.Role(1))
+	}
+	if len(leaf.SANs) != 1 || leaf.SANs[0] != "spiffe://cluster.local/ns/default/sa/sleep" {
+		t.Fatalf("unexpected SANs %v", leaf.SANs)
+	}
+	if leaf.TrustDomain != "cluster.local" {
+		t.Fatalf("unexpected trust domain %q", leaf.TrustDomain)
+	}
+	if leaf.Issuer != root.Subject {
+		t.Fatalf("leaf issuer %q does not match root subject %q", leaf.Issuer, root.Subject)
+	}
+
+	if _, err := ParseCertChain([]byte("not a certificate")); err == nil {
+		t.Fatal("expected an error parsing invalid PEM")
+	}
+}
+
+func TestExpiringCerts(t *testing.T) {
+	now := time.Now()
+	item, err := NewSecretItemBuilder().Name("default").Data(testChain(t, now, 48*time.Hour)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := []SecretItem{item}
+
+	if expiring := ExpiringCerts(secrets, now, 24*time.Hour); len(expiring) != 0 {
+		t.Fatalf("expected no certificates expiring within 24h, got %v", expiring)
+	}
+	expiring := ExpiringCerts(secrets, now, 72*time.Hour)
+	if len(expiring) != 1 {
+		t.Fatalf("expected the leaf to expire within 72h, got %v", expiring)
+	}
+	if expiring[0].Secret != "default" || expiring[0].SerialNumber != "2" {
+		t.Fatalf("unexpected expiring certificate %+v", expiring[0])
+	}
+	// A week later the leaf expired already and the root is still valid.
+	expiring = ExpiringCerts(secrets, now.Add(7*24*time.Hour), time.Hour)
+	if len(expiring) != 1 || RemainingLifetime(expiring[0].Remaining) != "expired" {
+		t.Fatalf("expected the leaf to have expired, got %v", expiring)
+	}
+}
+
+func TestSDSWriterSecretChains(t *testing.T) {
+	now := time.Now()
+	item, err := NewSecretItemBuilder().Name("default").Data(testChain(t, now, 48*time.Hour)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &bytes.Buffer{}
+	if err := NewSDSWriter(w, TABULAR).PrintSecretChains([]SecretItem{item}, now); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range append([]string{
+		"leaf", "root", "spiffe://cluster.local/ns/default/sa/sleep", "O=cluster.local", "47h",
+	}, secretChainColumns...) {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("expected output to contain %q:\n%s", want, w.String())
+		}
+	}
+}
@@ -20,12 +20,16 @@ import (
 	"io"
 	"strings"
 	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 // SDSWriter takes lists of SecretItem or SecretItemDiff and prints them through supplied output writer
 type SDSWriter interface {
 	PrintSecretItems([]SecretItem) error
 	PrintDiffs([]SecretItemDiff) error
+	PrintSecretChains([]SecretItem, time.Time) error
 }
 
 type Format int
@@ -69,8 +73,9 @@ func (w *sdsWriter) PrintSecretItems(secrets []SecretItem) error {
 }
 
 var (
-	secretItemColumns = []string{"RESOURCE NAME", "TYPE", "STATUS", "VALID CERT", "SERIAL NUMBER", "NOT AFTER", "NOT BEFORE"}
-	secretDiffColumns = []string{"RESOURCE NAME", "TYPE", "VALID CERT", "NODE AGENT", "PROXY", "SERIAL NUMBER", "NOT AFTER", "NOT BEFORE"}
+	secretItemColumns  = []string{"RESOURCE NAME", "TYPE", "STATUS", "VALID CERT", "SERIAL NUMBER", "NOT AFTER", "NOT BEFORE"}
+	secretChainColumns = []string{"RESOURCE NAME", "CERT", "SUBJECT", "SANS", "TRUST DOMAIN", "SERIAL NUMBER", "NOT AFTER", "REMAINING"}
+	secretDiffColumns  = []string{"RESOURCE NAME", "TYPE", "VALID CERT", "NODE AGENT", "PROXY", "SERIAL NUMBER", "NOT AFTER", "NOT BEFORE"}
 )
 
 // printSecretItemsTabular prints the secret in table format
@@ -91,6 +96,46 @@ func (w *sdsWriter) printSecretItemsTabular(secrets []SecretItem) error {
 	return tw.Flush()
 }
 
+// PrintSecretChains prints every certificate of the chain of each secret, with the lifetime
+// remaining at the given time
+func (w *sdsWriter) PrintSecretChains(secrets []SecretItem, now time.Time) error {
+	if w.output == JSON {
+		return w.printSecretItemsJSON(secrets)
+	}
+	if len(secrets) == 0 {
+		fmt.Fprintln(w.w, "No secret items to show.")
+		return nil
+	}
+	tw := new(tabwriter.Writer).Init(w.w, 0, 5, 5, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(secretChainColumns, "\t"))
+	for _, s := range secrets {
+		if includeConfigType {
+			s.Name = fmt.Sprintf("secret/%s", s.Name)
+		}
+		for i, c := range s.Chain {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				s.Name, c.Role(i), valueOrNone(c.Subject), valueOrNone(strings.Join(c.SANs, ",")), valueOrNone(c.TrustDomain),
+				c.SerialNumber, c.NotAfter.Format(time.RFC3339), RemainingLifetime(c.NotAfter.Sub(now)))
+		}
+	}
+	return tw.Flush()
+}
+
+// RemainingLifetime formats the lifetime left of a certificate
+func RemainingLifetime(d time.Duration) string {
+	if d <= 0 {
+		return "expired"
+	}
+	return duration.HumanDuration(d)
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
 // printSecretItemsJSON prints secret in JSON format, and dumps the raw certificate data with the output
 func (w *sdsWriter) printSecretItemsJSON(secrets []SecretItem) error {
 	out, err := json.MarshalIndent(secrets, "", " ")
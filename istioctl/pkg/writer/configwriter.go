@@ -14,7 +14,13 @@
 
 package writer
 
+import (
+	sdscompare "istio.io/istio/istioctl/pkg/writer/compare/sds"
+)
+
 type ConfigDumpWriter interface {
 	PrintSecretSummary() error
 	PrintSecretDump(outputFormat string) error
+	// SecretItems returns the secrets of the config dump with their decoded certificate chains.
+	SecretItems() ([]sdscompare.SecretItem, error)
 }
@@ -122,6 +122,14 @@ func (c *ConfigWriter) PrintSecretSummary() error {
 	return secretWriter.PrintSecretItems(secretItems)
 }
 
+// SecretItems returns the dynamic active and warming secrets from the config dump
+func (c *ConfigWriter) SecretItems() ([]sdscompare.SecretItem, error) {
+	if c.configDump == nil {
+		return nil, fmt.Errorf("config writer has not been primed")
+	}
+	return sdscompare.GetEnvoySecrets(c.configDump)
+}
+
 func (c *ConfigWriter) PrintFullSummary(cf ClusterFilter, lf ListenerFilter, rf RouteFilter, epf EndpointFilter) error {
 	if err := c.PrintClusterSummary(cf); err != nil {
 		return err
@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output prints the results of istioctl commands in the format selected with the global
+// --output-format flag. Machine-readable formats wrap the results in the same envelope for all commands,
+// so that scripts parse the output of every command the same way.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats
+const (
+	TableFormat = "table"
+	JSONFormat  = "json"
+	YAMLFormat  = "yaml"
+)
+
+// APIVersion is the API version of the envelope.
+const APIVersion = "istioctl.istio.io/v1alpha1"
+
+// Formats are the supported output formats.
+var Formats = []string{TableFormat, JSONFormat, YAMLFormat}
+
+// List is the envelope of machine-readable output. Its kind is the kind of its items suffixed with List,
+// following the convention of Kubernetes lists.
+type List struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Items      any    `json:"items"`
+}
+
+// Validate returns an error if the format is not supported.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, expected one of %v", format, Formats)
+}
+
+// IsMachineReadable returns whether the format is printed with the envelope rather than as a table.
+func IsMachineReadable(format string) bool {
+	return format == JSONFormat || format == YAMLFormat
+}
+
+// Print prints the items in the envelope, in a machine-readable format. items must be a slice, which may be nil.
+func Print(w io.Writer, format, kind string, items any) error {
+	if v := reflect.ValueOf(items); items == nil || (v.Kind() == reflect.Slice && v.IsNil()) {
+		// Scripts iterate over the items, so they are always a list.
+		items = []any{}
+	}
+	list := List{APIVersion: APIVersion, Kind: kind + "List", Items: items}
+	var out []byte
+	var err error
+	switch format {
+	case JSONFormat:
+		out, err = json.MarshalIndent(list, "", "  ")
+		out = append(out, '\n')
+	case YAMLFormat:
+		out, err = yaml.Marshal(list)
+	default:
+		return fmt.Errorf("output format %q is not machine-readable, expected %s or %s", format, JSONFormat, YAMLFormat)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
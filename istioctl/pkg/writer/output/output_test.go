@@ -0,0 +1,98 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func TestPrint(t *testing.T) {
+	cases := []struct {
+		name   string
+		format string
+		items  any
+		want   string
+	}{
+		{
+			name:   "json",
+			format: JSONFormat,
+			items:  []item{{Name: "a"}},
+			want: `{
+  "apiVersion": "istioctl.istio.io/v1alpha1",
+  "kind": "ItemList",
+  "items": [
+    {
+      "name": "a"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:   "yaml",
+			format: YAMLFormat,
+			items:  []item{{Name: "a"}},
+			want: `apiVersion: istioctl.istio.io/v1alpha1
+items:
+- name: a
+kind: ItemList
+`,
+		},
+		{
+			name:   "nil items",
+			format: JSONFormat,
+			items:  []item(nil),
+			want: `{
+  "apiVersion": "istioctl.istio.io/v1alpha1",
+  "kind": "ItemList",
+  "items": []
+}
+`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Print(&out, tt.format, "Item", tt.items); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	if err := Print(&bytes.Buffer{}, TableFormat, "Item", nil); err == nil {
+		t.Fatal("expected an error printing a table")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, f := range Formats {
+		if err := Validate(f); err != nil {
+			t.Errorf("format %q: %v", f, err)
+		}
+	}
+	if err := Validate("xml"); err == nil {
+		t.Error("expected an error for format xml")
+	}
+}
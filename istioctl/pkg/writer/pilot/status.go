@@ -26,6 +26,7 @@ import (
 	xdsstatus "github.com/envoyproxy/go-control-plane/envoy/service/status/v3"
 
 	"istio.io/istio/istioctl/pkg/multixds"
+	"istio.io/istio/istioctl/pkg/writer/output"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/xds"
 	xdsresource "istio.io/istio/pilot/pkg/xds/v3"
@@ -35,6 +36,8 @@ import (
 // StatusWriter enables printing of sync status using multiple []byte Istiod responses
 type StatusWriter struct {
 	Writer io.Writer
+	// OutputFormat is one of the output formats of the output package, a table if empty.
+	OutputFormat string
 }
 
 type writerStatus struct {
@@ -47,8 +50,26 @@ type XdsStatusWriter struct {
 	Writer                 io.Writer
 	Namespace              string
 	InternalDebugAllIstiod bool
+	// OutputFormat is one of the output formats of the output package, a table if empty.
+	OutputFormat string
 }
 
+// ProxyStatus is the sync status of a proxy, as printed by the machine-readable output formats.
+type ProxyStatus struct {
+	Name    string `json:"name"`
+	Cluster string `json:"cluster"`
+	CDS     string `json:"cds"`
+	LDS     string `json:"lds"`
+	EDS     string `json:"eds"`
+	RDS     string `json:"rds"`
+	ECDS    string `json:"ecds"`
+	Istiod  string `json:"istiod"`
+	Version string `json:"version"`
+}
+
+// proxyStatusKind is the kind of the items printed by the machine-readable output formats.
+const proxyStatusKind = "ProxyStatus"
+
 type xdsWriterStatus struct {
 	proxyID              string
 	clusterID            string
@@ -67,6 +88,13 @@ func (s *StatusWriter) PrintAll(statuses map[string][]byte) error {
 	if err != nil {
 		return err
 	}
+	if output.IsMachineReadable(s.OutputFormat) {
+		items := make([]ProxyStatus, 0, len(fullStatus))
+		for _, status := range fullStatus {
+			items = append(items, status.proxyStatus())
+		}
+		return output.Print(s.Writer, s.OutputFormat, proxyStatusKind, items)
+	}
 	for _, status := range fullStatus {
 		if err := statusPrintln(w, status); err != nil {
 			return err
@@ -81,13 +109,21 @@ func (s *StatusWriter) PrintSingle(statuses map[string][]byte, proxyName string)
 	if err != nil {
 		return err
 	}
+	items := make([]ProxyStatus, 0)
 	for _, status := range fullStatus {
 		if strings.Contains(status.ProxyID, proxyName) {
+			if output.IsMachineReadable(s.OutputFormat) {
+				items = append(items, status.proxyStatus())
+				continue
+			}
 			if err := statusPrintln(w, status); err != nil {
 				return err
 			}
 		}
 	}
+	if output.IsMachineReadable(s.OutputFormat) {
+		return output.Print(s.Writer, s.OutputFormat, proxyStatusKind, items)
+	}
 	return w.Flush()
 }
 
@@ -116,6 +152,13 @@ func (s *StatusWriter) setupStatusPrint(statuses map[string][]byte) (*tabwriter.
 }
 
 func statusPrintln(w io.Writer, status *writerStatus) error {
+	ps := status.proxyStatus()
+	_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+		ps.Name, ps.Cluster, ps.CDS, ps.LDS, ps.EDS, ps.RDS, ps.ECDS, ps.Istiod, ps.Version)
+	return nil
+}
+
+func (status *writerStatus) proxyStatus() ProxyStatus {
 	clusterSynced := xdsStatus(status.ClusterSent, status.ClusterAcked, status.ProxyType)
 	listenerSynced := xdsStatus(status.ListenerSent, status.ListenerAcked, status.ProxyType)
 	routeSynced := xdsStatus(status.RouteSent, status.RouteAcked, status.ProxyType)
@@ -128,11 +171,17 @@ func statusPrintln(w io.Writer, status *writerStatus) error {
 		// but it is better than not providing any information.
 		version = status.ProxyVersion + "*"
 	}
-	_, _ = fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
-		status.ProxyID, status.ClusterID,
-		clusterSynced, listenerSynced, endpointSynced, routeSynced, extensionconfigSynced,
-		status.pilot, version)
-	return nil
+	return ProxyStatus{
+		Name:    status.ProxyID,
+		Cluster: status.ClusterID,
+		CDS:     clusterSynced,
+		LDS:     listenerSynced,
+		EDS:     endpointSynced,
+		RDS:     routeSynced,
+		ECDS:    extensionconfigSynced,
+		Istiod:  status.pilot,
+		Version: version,
+	}
 }
 
 const ignoredStatus = "IGNORED"
@@ -161,6 +210,13 @@ func (s *XdsStatusWriter) PrintAll(statuses map[string]*discovery.DiscoveryRespo
 	if err != nil {
 		return err
 	}
+	if output.IsMachineReadable(s.OutputFormat) {
+		items := make([]ProxyStatus, 0, len(fullStatus))
+		for _, status := range fullStatus {
+			items = append(items, status.proxyStatus())
+		}
+		return output.Print(s.Writer, s.OutputFormat, proxyStatusKind, items)
+	}
 	for _, status := range fullStatus {
 		if err := xdsStatusPrintln(w, status); err != nil {
 			return err
@@ -241,6 +297,20 @@ func (s *XdsStatusWriter) setupStatusPrint(drs map[string]*discovery.DiscoveryRe
 	return w, fullStatus, nil
 }
 
+func (status *xdsWriterStatus) proxyStatus() ProxyStatus {
+	return ProxyStatus{
+		Name:    status.proxyID,
+		Cluster: status.clusterID,
+		CDS:     status.clusterStatus,
+		LDS:     status.listenerStatus,
+		EDS:     status.endpointStatus,
+		RDS:     status.routeStatus,
+		ECDS:    status.extensionconfigStaus,
+		Istiod:  status.istiodID,
+		Version: status.istiodVersion,
+	}
+}
+
 func xdsStatusPrintln(w io.Writer, status *xdsWriterStatus) error {
 	_, err := fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
 		status.proxyID, status.clusterID,
@@ -26,6 +26,7 @@ import (
 	"github.com/google/uuid"
 	anypb "google.golang.org/protobuf/types/known/anypb"
 
+	"istio.io/istio/istioctl/pkg/writer/output"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/util/protoconv"
 	"istio.io/istio/pilot/pkg/xds"
@@ -172,6 +173,32 @@ func TestStatusWriter_PrintSingle(t *testing.T) {
 	}
 }
 
+func TestStatusWriter_PrintSingleJSON(t *testing.T) {
+	got := &bytes.Buffer{}
+	sw := StatusWriter{Writer: got, OutputFormat: output.JSONFormat}
+	b, _ := json.Marshal(append(statusInput1(), statusInput2()...))
+	assert.NoError(t, sw.PrintSingle(map[string][]byte{"istiod2": b}, "proxy2"))
+	var list struct {
+		APIVersion string        `json:"apiVersion"`
+		Kind       string        `json:"kind"`
+		Items      []ProxyStatus `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(got.Bytes(), &list))
+	assert.Equal(t, list.APIVersion, output.APIVersion)
+	assert.Equal(t, list.Kind, "ProxyStatusList")
+	assert.Equal(t, list.Items, []ProxyStatus{{
+		Name:    "proxy2",
+		Cluster: "cluster2",
+		CDS:     "STALE",
+		LDS:     "SYNCED",
+		EDS:     "STALE",
+		RDS:     "SYNCED",
+		ECDS:    "NOT SENT",
+		Istiod:  "istiod2",
+		Version: "1.1",
+	}})
+}
+
 func statusInput1() []xds.SyncStatus {
 	return []xds.SyncStatus{
 		{
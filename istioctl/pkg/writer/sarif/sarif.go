@@ -0,0 +1,141 @@
+// Copyright Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sarif writes findings of istioctl commands as SARIF 2.1.0 logs, the format ingested by the code
+// scanning UIs of GitHub and GitLab and by security tooling. Only the subset of SARIF used by istioctl is
+// modeled.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+const (
+	// Version is the version of SARIF written.
+	Version = "2.1.0"
+	// Schema is the JSON schema of the SARIF version written.
+	Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Levels of results.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Log is a SARIF log.
+type Log struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run is a run of a tool.
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Tool is the tool which produced the results of a run.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver describes the tool and the rules its results refer to.
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules,omitempty"`
+}
+
+// Rule is a rule results are reported against, such as an analyzer message code.
+type Rule struct {
+	ID                   string         `json:"id"`
+	ShortDescription     *Message       `json:"shortDescription,omitempty"`
+	HelpURI              string         `json:"helpUri,omitempty"`
+	DefaultConfiguration *Configuration `json:"defaultConfiguration,omitempty"`
+}
+
+// Configuration is the default configuration of a rule.
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+// Result is a finding.
+type Result struct {
+	RuleID    string     `json:"ruleId"`
+	Level     string     `json:"level"`
+	Message   Message    `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+// Message is a text message.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Location is where a finding was found: a position in a file, a resource of a cluster, or both.
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// PhysicalLocation is a position in a file.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is the location of a file, relative to the root of the repository when relative.
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a region of a file.
+type Region struct {
+	StartLine int `json:"startLine"`
+}
+
+// LogicalLocation is a location which is not a file, such as a resource of a cluster.
+type LogicalLocation struct {
+	Name               string `json:"name,omitempty"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// NewLog returns a log of a single run of the driver, with the rules sorted by id.
+func NewLog(driver Driver, results []Result) *Log {
+	sort.Slice(driver.Rules, func(i, j int) bool {
+		return driver.Rules[i].ID < driver.Rules[j].ID
+	})
+	if results == nil {
+		// Consumers expect the results of a clean run to be an empty list.
+		results = []Result{}
+	}
+	return &Log{
+		Version: Version,
+		Schema:  Schema,
+		Runs:    []Run{{Tool: Tool{Driver: driver}, Results: results}},
+	}
+}
+
+// Write writes the log as indented JSON.
+func (l *Log) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
@@ -26,6 +26,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"istio.io/istio/istioctl/pkg/util/configdump"
+	sdscompare "istio.io/istio/istioctl/pkg/writer/compare/sds"
 	"istio.io/istio/pkg/log"
 )
 
@@ -106,6 +107,30 @@ func (c *ConfigWriter) PrintSecretSummary() error {
 	return w.Flush()
 }
 
+// SecretItems returns the certificates of each identity, the cert chain followed by the CA certs
+func (c *ConfigWriter) SecretItems() ([]sdscompare.SecretItem, error) {
+	if c.ztunnelDump == nil {
+		return nil, fmt.Errorf("config writer has not been primed")
+	}
+	items := make([]sdscompare.SecretItem, 0, len(c.ztunnelDump.Certificates))
+	for _, secret := range c.ztunnelDump.Certificates {
+		var pems []string
+		for _, cert := range append(append([]*configdump.Cert{}, secret.CertChain...), secret.CaCert...) {
+			pems = append(pems, cert.Pem)
+		}
+		item, err := sdscompare.NewSecretItemBuilder().
+			Name(secret.Identity).
+			State(secret.State).
+			Data(strings.Join(pems, "\n")).
+			Build()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 func valueOrNA(value string) string {
 	if value == "" {
 		return "NA"
@@ -116,3 +116,19 @@ func TestConfigWriter_PrintSecretSummary(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigWriter_SecretItems(t *testing.T) {
+	cw := &ConfigWriter{Stdout: &bytes.Buffer{}}
+	if _, err := cw.SecretItems(); err == nil {
+		t.Fatal("expected error when the config dump is not primed")
+	}
+	cd, _ := os.ReadFile("testdata/dump.json")
+	assert.NoError(t, cw.Prime(cd))
+	items, err := cw.SecretItems()
+	assert.NoError(t, err)
+	assert.Equal(t, len(items), len(cw.ztunnelDump.Certificates))
+	for i, item := range items {
+		certs := cw.ztunnelDump.Certificates[i]
+		assert.Equal(t, len(item.Chain), len(certs.CertChain)+len(certs.CaCert))
+	}
+}
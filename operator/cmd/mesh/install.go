@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"istio.io/api/operator/v1alpha1"
+	"istio.io/istio/istioctl/pkg/bundle"
 	"istio.io/istio/istioctl/pkg/clioptions"
 	revtag "istio.io/istio/istioctl/pkg/tag"
 	"istio.io/istio/istioctl/pkg/util"
@@ -72,6 +73,8 @@ type InstallArgs struct {
 	Set []string
 	// ManifestsPath is a path to a ManifestsPath and profiles directory in the local filesystem with a release tgz.
 	ManifestsPath string
+	// Bundle is the path of an offline installation bundle whose charts and profiles are used instead of ManifestsPath.
+	Bundle string
 	// Revision is the Istio control plane revision the command targets.
 	Revision string
 }
@@ -87,6 +90,7 @@ func (a *InstallArgs) String() string {
 	b.WriteString("Verify:           " + fmt.Sprint(a.Verify) + "\n")
 	b.WriteString("Set:              " + fmt.Sprint(a.Set) + "\n")
 	b.WriteString("ManifestsPath:    " + a.ManifestsPath + "\n")
+	b.WriteString("Bundle:           " + a.Bundle + "\n")
 	b.WriteString("Revision:         " + a.Revision + "\n")
 	return b.String()
 }
@@ -104,6 +108,7 @@ func addInstallFlags(cmd *cobra.Command, args *InstallArgs) {
 	cmd.PersistentFlags().StringVarP(&args.ManifestsPath, "charts", "", "", ChartsDeprecatedStr)
 	cmd.PersistentFlags().StringVarP(&args.ManifestsPath, "manifests", "d", "", ManifestsFlagHelpStr)
 	cmd.PersistentFlags().StringVarP(&args.Revision, "revision", "r", "", revisionFlagHelpStr)
+	cmd.PersistentFlags().StringVar(&args.Bundle, "bundle", "", BundleFlagHelpStr)
 }
 
 // InstallCmdWithArgs generates an Istio install manifest and applies it to a cluster
@@ -123,6 +128,9 @@ func InstallCmdWithArgs(rootArgs *RootArgs, iArgs *InstallArgs, logOpts *log.Opt
   # Generate the demo profile and don't wait for confirmation
   istioctl install --set profile=demo --skip-confirmation
 
+  # Install without network access from a bundle created with 'istioctl x bundle create', using mirrored images
+  istioctl install --bundle istio-1.20.1-bundle.tar.gz --set hub=registry.example.com/istio
+
   # To override a setting that includes dots, escape them with a backslash (\).  Your shell may require enclosing quotes.
   istioctl install --set "values.sidecarInjectorWebhook.injectedAnnotations.container\.apparmor\.security\.beta\.kubernetes\.io/istio-proxy=runtime/default"
 `,
@@ -168,6 +176,19 @@ func Install(rootArgs *RootArgs, iArgs *InstallArgs, logOpts *log.Options, stdOu
 			warnMarker, operatorVer.OperatorCodeBaseVersion)
 	}
 
+	if iArgs.Bundle != "" {
+		if iArgs.ManifestsPath != "" {
+			return fmt.Errorf("--bundle and --manifests cannot be used together")
+		}
+		manifestsPath, m, cleanup, err := bundle.Open(iArgs.Bundle)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		l.LogAndPrintf("Using charts and profiles of Istio %s from bundle %s", m.Version, iArgs.Bundle)
+		iArgs.ManifestsPath = manifestsPath
+	}
+
 	setFlags := applyFlagAliases(iArgs.Set, iArgs.ManifestsPath, iArgs.Revision)
 
 	_, iop, err := manifest.GenerateConfig(iArgs.InFilenames, setFlags, iArgs.Force, kubeClient, l)
@@ -232,7 +253,7 @@ func Install(rootArgs *RootArgs, iArgs *InstallArgs, logOpts *log.Options, stdOu
 		if err != nil {
 			return fmt.Errorf("failed to setup verifier: %v", err)
 		}
-		if err := installationVerifier.Verify(); err != nil {
+		if _, err := installationVerifier.Verify(); err != nil {
 			return fmt.Errorf("verification failed with the following error: %v", err)
 		}
 	}
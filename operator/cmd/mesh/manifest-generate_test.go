@@ -514,6 +514,16 @@ func TestManifestGenerateZtunnel(t *testing.T) {
 	})
 }
 
+func TestManifestGenerateNetworkPolicy(t *testing.T) {
+	runTestGroup(t, testGroup{
+		{
+			desc:        "networkpolicy",
+			diffSelect:  "NetworkPolicy:*:*",
+			chartSource: liveCharts,
+		},
+	})
+}
+
 // TestManifestGenerateHelmValues tests whether enabling components through the values passthrough interface works as
 // expected i.e. without requiring enablement also in IstioOperator API.
 func TestManifestGenerateHelmValues(t *testing.T) {
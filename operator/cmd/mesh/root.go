@@ -33,6 +33,10 @@ settings (--set meshConfig.enableTracing=true). See documentation for more info:
 	// ManifestsFlagHelpStr is the command line description for --manifests
 	ManifestsFlagHelpStr = `Specify a path to a directory of charts and profiles
 (e.g. ~/Downloads/istio-` + baseVersion + `/manifests).
+`
+	// BundleFlagHelpStr is the command line description for --bundle
+	BundleFlagHelpStr = `Specify the path of an offline installation bundle created with
+'istioctl x bundle create' to use its charts and profiles.
 `
 )
 
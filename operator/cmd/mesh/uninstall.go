@@ -15,6 +15,7 @@
 package mesh
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -24,7 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"istio.io/api/operator/v1alpha1"
-	"istio.io/istio/istioctl/pkg/tag"
+	"istio.io/istio/istioctl/pkg/revisions"
 	iopv1alpha1 "istio.io/istio/operator/pkg/apis/istio/v1alpha1"
 	"istio.io/istio/operator/pkg/cache"
 	"istio.io/istio/operator/pkg/helmreconciler"
@@ -146,11 +147,11 @@ func uninstall(cmd *cobra.Command, rootArgs *RootArgs, uiArgs *uninstallArgs, lo
 	}
 
 	if uiArgs.revision != "" {
-		revisions, err := tag.ListRevisionDescriptions(kubeClient)
+		revs, err := revisions.Discover(context.Background(), kubeClient, uiArgs.istioNamespace)
 		if err != nil {
 			return fmt.Errorf("could not list revisions: %s", err)
 		}
-		if _, exists := revisions[uiArgs.revision]; !exists {
+		if revs.Get(uiArgs.revision) == nil {
 			return errors.New("could not find target revision")
 		}
 	}
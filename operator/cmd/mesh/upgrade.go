@@ -15,11 +15,17 @@
 package mesh
 
 import (
+	"context"
+	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
+	"istio.io/istio/istioctl/pkg/revisions"
+	"istio.io/istio/operator/pkg/manifest"
 	"istio.io/istio/operator/pkg/util/clog"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/log"
 )
 
@@ -55,6 +61,9 @@ func UpgradeCmd(logOpts *log.Options) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) (e error) {
 			l := clog.NewConsoleLogger(cmd.OutOrStdout(), cmd.ErrOrStderr(), installerScope)
 			p := NewPrinterForWriter(cmd.OutOrStderr())
+			if err := checkUpgradeRevision(upgradeArgs.InstallArgs, l, p); err != nil {
+				return err
+			}
 			return Install(rootArgs, upgradeArgs.InstallArgs, logOpts, cmd.OutOrStdout(), l, p)
 		},
 	}
@@ -62,3 +71,28 @@ func UpgradeCmd(logOpts *log.Options) *cobra.Command {
 	addUpgradeFlags(cmd, upgradeArgs)
 	return cmd
 }
+
+// checkUpgradeRevision warns when the revision targeted by the in-place upgrade is not installed in the
+// cluster, in which case the upgrade installs a new control plane next to the existing ones.
+func checkUpgradeRevision(iArgs *InstallArgs, l clog.Logger, p Printer) error {
+	kubeClient, _, err := KubernetesClients(iArgs.KubeConfigPath, iArgs.Context, l)
+	if err != nil {
+		return err
+	}
+	ns := manifest.GetValueForSetFlag(iArgs.Set, "values.global.istioNamespace")
+	if ns == "" {
+		ns = constants.IstioSystemNamespace
+	}
+	revs, err := revisions.Discover(context.Background(), kubeClient, ns)
+	if err != nil {
+		return err
+	}
+	revision := manifest.GetValueForSetFlag(iArgs.Set, "revision")
+	if len(revs) == 0 || revs.Get(revision) != nil {
+		return nil
+	}
+	warnMarker := color.New(color.FgYellow).Add(color.Italic).Sprint("WARNING:")
+	p.Printf("%s control plane revision %q is not installed, upgrade will install it alongside the installed revisions: %s\n",
+		warnMarker, revisions.Normalize(revision), strings.Join(revs.Names(), ", "))
+	return nil
+}
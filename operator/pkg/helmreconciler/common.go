@@ -40,6 +40,11 @@ const (
 	// OwningResourceNotPruned indicates that the resource should not be pruned during reconciliation cycles,
 	// note this will not prevent the resource from being deleted if the owning resource is deleted.
 	OwningResourceNotPruned = MetadataNamespace + "/owning-resource-not-pruned"
+	// PruneAnnotation, when set to "false" on a rendered or live resource, prevents the resource from being pruned
+	// during reconciliation cycles. This allows users to take over management of a resource, such as a gateway
+	// Deployment, without the operator deleting it. Like OwningResourceNotPruned, it does not prevent deletion
+	// when all resources of the owner are removed.
+	PruneAnnotation = "install.istio.io/prune"
 	// operatorLabelStr indicates Istio operator is managing this resource.
 	operatorLabelStr = name.OperatorAPINamespace + "/managed"
 	// operatorReconcileStr indicates that the operator will reconcile the resource.
@@ -17,6 +17,7 @@ package helmreconciler
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -444,6 +445,10 @@ func (h *HelmReconciler) deleteResources(excluded map[string]bool, coreLabels ma
 			if o.GetLabels()[OwningResourceNotPruned] == "true" {
 				continue
 			}
+			if pruneDisabled(o.GetAnnotations()) {
+				h.opts.Log.LogAndPrintf("  Not pruning %s because it is annotated with %s=false.", oh, PruneAnnotation)
+				continue
+			}
 		}
 		if err := h.deleteResource(obj, componentName, oh); err != nil {
 			errs = append(errs, err)
@@ -456,6 +461,39 @@ func (h *HelmReconciler) deleteResources(excluded map[string]bool, coreLabels ma
 	return errs.ToError()
 }
 
+// pruneDisabled reports whether the given annotations opt a resource out of pruning.
+func pruneDisabled(annotations map[string]string) bool {
+	v, ok := annotations[PruneAnnotation]
+	if !ok {
+		return false
+	}
+	prune, err := strconv.ParseBool(v)
+	return err == nil && !prune
+}
+
+// validatePruneAnnotations returns an error for each rendered resource whose prune annotation is not a boolean.
+func validatePruneAnnotations(manifests name.ManifestMap) error {
+	var errs util.Errors
+	for _, manifest := range manifests.Consolidated() {
+		objects, err := object.ParseK8sObjectsFromYAMLManifest(manifest)
+		if err != nil {
+			errs = util.AppendErr(errs, err)
+			continue
+		}
+		for _, obj := range objects {
+			v, ok := obj.UnstructuredObject().GetAnnotations()[PruneAnnotation]
+			if !ok {
+				continue
+			}
+			if _, err := strconv.ParseBool(v); err != nil {
+				errs = util.AppendErr(errs, fmt.Errorf("%s: invalid value %q for annotation %s, must be true or false",
+					obj.Hash(), v, PruneAnnotation))
+			}
+		}
+	}
+	return errs.ToError()
+}
+
 func (h *HelmReconciler) deleteResource(obj *object.K8sObject, componentName, oh string) error {
 	if h.opts.DryRun {
 		h.opts.Log.LogAndPrintf("Not pruning object %s because of dry run.", oh)
@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/yaml"
 
 	"istio.io/api/label"
+	apiv1alpha1 "istio.io/api/operator/v1alpha1"
 	"istio.io/istio/operator/pkg/apis/istio/v1alpha1"
 	"istio.io/istio/operator/pkg/name"
 	"istio.io/istio/operator/pkg/object"
@@ -225,3 +226,68 @@ func TestPilotExist(t *testing.T) {
 		}
 	})
 }
+
+func TestHelmReconciler_DeleteResourcesRespectsPruneAnnotation(t *testing.T) {
+	cl := fake.NewClientBuilder().Build()
+	h := &HelmReconciler{
+		client:     cl,
+		kubeClient: kube.NewFakeClientWithVersion("24"),
+		opts: &Options{
+			ProgressLog: progress.NewLog(),
+			Log:         clog.NewDefaultLogger(),
+		},
+		iop:           &v1alpha1.IstioOperator{Spec: &apiv1alpha1.IstioOperatorSpec{}},
+		countLock:     &sync.Mutex{},
+		prunedKindSet: map[schema.GroupKind]struct{}{},
+	}
+	componentName := string(name.IngressComponentName)
+	coreLabels := map[string]string{OwningResourceName: "installed-state"}
+	objects := &unstructured.UnstructuredList{}
+	for _, tc := range []struct {
+		name       string
+		annotation string
+	}{
+		{name: "managed"},
+		{name: "adopted", annotation: "false"},
+		{name: "explicit", annotation: "true"},
+	} {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: name.DeploymentStr})
+		u.SetNamespace("istio-system")
+		u.SetName(tc.name)
+		u.SetLabels(h.addComponentLabels(coreLabels, componentName))
+		if tc.annotation != "" {
+			u.SetAnnotations(map[string]string{PruneAnnotation: tc.annotation})
+		}
+		assert.NoError(t, cl.Create(context.TODO(), u))
+		objects.Items = append(objects.Items, *u)
+	}
+
+	assert.NoError(t, h.deleteResources(map[string]bool{}, coreLabels, componentName, objects, false))
+
+	for objName, wantExists := range map[string]bool{"managed": false, "adopted": true, "explicit": false} {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: name.DeploymentStr})
+		err := cl.Get(context.TODO(), client.ObjectKey{Namespace: "istio-system", Name: objName}, u)
+		assert.Equal(t, wantExists, err == nil, objName)
+	}
+}
+
+func TestValidatePruneAnnotations(t *testing.T) {
+	deployment := func(value string) string {
+		return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: istio-ingressgateway
+  namespace: istio-system
+  annotations:
+    install.istio.io/prune: "` + value + `"
+`
+	}
+	assert.NoError(t, validatePruneAnnotations(name.ManifestMap{
+		name.IngressComponentName: {deployment("false")},
+	}))
+	assert.Error(t, validatePruneAnnotations(name.ManifestMap{
+		name.IngressComponentName: {deployment("never")},
+	}))
+}
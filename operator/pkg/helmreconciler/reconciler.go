@@ -162,6 +162,9 @@ func (h *HelmReconciler) Reconcile() (*v1alpha1.InstallStatus, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := validatePruneAnnotations(manifestMap); err != nil {
+		return nil, err
+	}
 
 	err = h.analyzeWebhooks(manifestMap[name.PilotComponentName])
 	if err != nil {
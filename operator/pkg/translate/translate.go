@@ -655,9 +655,30 @@ func applyGatewayTranslations(iop []byte, componentName name.ComponentName, comp
 			setYAMLNodeByMapPath(iopt, util.PathFromString("gateways.istio-egressgateway.ports"), k8s.Service.Ports)
 		}
 	}
+	applyGatewayInjectionOverride(iopt, componentName, gwSpec.Name)
 	return yaml.Marshal(iopt)
 }
 
+// applyGatewayInjectionOverride writes the override of gateway gwName in values.gateways.injectionOverrides, if
+// any, to the values of the gateway chart, so that it is rendered into that gateway only. The template of the
+// override replaces the injectionTemplate of the gateways of its kind.
+func applyGatewayInjectionOverride(iopt map[string]any, componentName name.ComponentName, gwName string) {
+	gateways, _ := iopt["gateways"].(map[string]any)
+	overrides, _ := gateways["injectionOverrides"].(map[string]any)
+	override, ok := overrides[gwName].(map[string]any)
+	if !ok {
+		return
+	}
+	root := "gateways.istio-ingressgateway."
+	if componentName == name.EgressComponentName {
+		root = "gateways.istio-egressgateway."
+	}
+	if template, _ := override["template"].(string); template != "" {
+		setYAMLNodeByMapPath(iopt, util.PathFromString(root+"injectionTemplate"), template)
+	}
+	setYAMLNodeByMapPath(iopt, util.PathFromString(root+"injection"), override)
+}
+
 // setYAMLNodeByMapPath sets the value at the given path to val in treeNode. The path cannot traverse lists and
 // treeNode must be a YAML tree unmarshaled into a plain map data structure.
 func setYAMLNodeByMapPath(treeNode any, path util.Path, val any) {
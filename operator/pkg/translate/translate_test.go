@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"testing"
 
+	"sigs.k8s.io/yaml"
+
 	"istio.io/api/operator/v1alpha1"
 	"istio.io/istio/operator/pkg/name"
 	"istio.io/istio/operator/pkg/object"
@@ -295,3 +297,79 @@ components:
 		})
 	}
 }
+
+func Test_applyGatewayInjectionOverride(t *testing.T) {
+	const values = `
+gateways:
+  injectionOverrides:
+    edge-gateway:
+      template: custom
+      concurrency: 4
+  istio-egressgateway: {}
+  istio-ingressgateway: {}
+`
+	cases := []struct {
+		name          string
+		componentName name.ComponentName
+		gwName        string
+		want          string
+	}{
+		{
+			name:          "ingress override",
+			componentName: name.IngressComponentName,
+			gwName:        "edge-gateway",
+			want: `gateways:
+  injectionOverrides:
+    edge-gateway:
+      concurrency: 4
+      template: custom
+  istio-egressgateway: {}
+  istio-ingressgateway:
+    injection:
+      concurrency: 4
+      template: custom
+    injectionTemplate: custom
+`,
+		},
+		{
+			name:          "egress override",
+			componentName: name.EgressComponentName,
+			gwName:        "edge-gateway",
+			want: `gateways:
+  injectionOverrides:
+    edge-gateway:
+      concurrency: 4
+      template: custom
+  istio-egressgateway:
+    injection:
+      concurrency: 4
+      template: custom
+    injectionTemplate: custom
+  istio-ingressgateway: {}
+`,
+		},
+		{
+			name:          "no override",
+			componentName: name.IngressComponentName,
+			gwName:        "istio-ingressgateway",
+			want: `gateways:
+  injectionOverrides:
+    edge-gateway:
+      concurrency: 4
+      template: custom
+  istio-egressgateway: {}
+  istio-ingressgateway: {}
+`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			iopt := make(map[string]any)
+			assert.NoError(t, yaml.Unmarshal([]byte(values), &iopt))
+			applyGatewayInjectionOverride(iopt, tt.componentName, tt.gwName)
+			got, err := yaml.Marshal(iopt)
+			assert.NoError(t, err)
+			assert.Equal(t, string(got), tt.want)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"istio.io/api/operator/v1alpha1"
@@ -28,6 +29,8 @@ import (
 	"istio.io/istio/operator/pkg/util"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/slices"
 	"istio.io/istio/pkg/util/protomarshal"
 )
 
@@ -66,7 +69,8 @@ func CheckIstioOperatorSpec(is *v1alpha1.IstioOperatorSpec, checkRequiredFields
 		return util.Errors{}
 	}
 
-	return Validate2(DefaultValidations, is)
+	errs = Validate2(DefaultValidations, is)
+	return util.AppendErrs(errs, validateGatewayInjectionOverrides(is))
 }
 
 func Validate2(validations map[string]ValidatorFunc, iop *v1alpha1.IstioOperatorSpec) (errs util.Errors) {
@@ -231,3 +235,45 @@ func validateGatewayName(path util.Path, val any) (errs util.Errors) {
 	}
 	return
 }
+
+// validateGatewayInjectionOverrides validates values.gateways.injectionOverrides: each override must name a gateway
+// component of the IstioOperator, its proxy image must be an image reference, and its volumes and volume mounts
+// must be named. Overlays without gateway components, which get them from their profile, are only checked once
+// merged.
+func validateGatewayInjectionOverrides(is *v1alpha1.IstioOperatorSpec) (errs util.Errors) {
+	gateways, _ := is.GetValues().AsMap()["gateways"].(map[string]any)
+	overrides, _ := gateways["injectionOverrides"].(map[string]any)
+	if len(overrides) == 0 {
+		return nil
+	}
+	names := map[string]bool{}
+	for _, gw := range append(is.GetComponents().GetIngressGateways(), is.GetComponents().GetEgressGateways()...) {
+		names[gw.GetName()] = true
+	}
+	for _, gwName := range slices.Sort(maps.Keys(overrides)) {
+		path := "values.gateways.injectionOverrides." + gwName
+		if len(names) > 0 && !names[gwName] {
+			errs = append(errs, fmt.Errorf("%s: no ingress or egress gateway is named %s", path, gwName))
+		}
+		override, _ := overrides[gwName].(map[string]any)
+		if image, _ := override["proxyImage"].(string); image != "" {
+			if _, err := name.ParseReference(image); err != nil {
+				errs = append(errs, fmt.Errorf("%s.proxyImage: invalid image %q: %v", path, image, err))
+			}
+		}
+		volumes, _ := override["volumes"].([]any)
+		for i, v := range volumes {
+			if volume, _ := v.(map[string]any); volume["name"] == nil || volume["name"] == "" {
+				errs = append(errs, fmt.Errorf("%s.volumes[%d]: name is required", path, i))
+			}
+		}
+		mounts, _ := override["volumeMounts"].([]any)
+		for i, m := range mounts {
+			mount, _ := m.(map[string]any)
+			if mount["name"] == nil || mount["name"] == "" || mount["mountPath"] == nil || mount["mountPath"] == "" {
+				errs = append(errs, fmt.Errorf("%s.volumeMounts[%d]: name and mountPath are required", path, i))
+			}
+		}
+	}
+	return errs
+}
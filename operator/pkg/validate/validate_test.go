@@ -94,6 +94,68 @@ components:
 `,
 			wantErrs: makeErrors([]string{`invalid value Components.IngressGateways: istio@ingress-1`}),
 		},
+		{
+			desc: "GatewayInjectionOverrides",
+			yamlStr: `
+components:
+  ingressGateways:
+  - name: istio-ingressgateway
+    enabled: true
+  - name: edge-gateway
+    enabled: true
+values:
+  gateways:
+    injectionOverrides:
+      edge-gateway:
+        template: gateway
+        proxyImage: docker.io/istio/proxyv2:1.20.0-distroless
+        concurrency: 4
+        volumes:
+        - name: lua
+          configMap:
+            name: edge-lua
+        volumeMounts:
+        - name: lua
+          mountPath: /etc/lua
+`,
+		},
+		{
+			desc: "GatewayInjectionOverridesWithoutGateways",
+			yamlStr: `
+values:
+  gateways:
+    injectionOverrides:
+      istio-ingressgateway:
+        concurrency: 2
+`,
+		},
+		{
+			desc: "BadGatewayInjectionOverrides",
+			yamlStr: `
+components:
+  egressGateways:
+  - name: istio-egressgateway
+    enabled: true
+values:
+  gateways:
+    injectionOverrides:
+      istio-egressgateway:
+        proxyImage: docker.io/istio/proxyv2:@bad
+        volumes:
+        - emptyDir: {}
+        volumeMounts:
+        - name: data
+      edge-gateway:
+        concurrency: 4
+`,
+			wantErrs: makeErrors([]string{
+				`values.gateways.injectionOverrides.edge-gateway: no ingress or egress gateway is named edge-gateway`,
+				`values.gateways.injectionOverrides.istio-egressgateway.proxyImage: invalid image "docker.io/istio/proxyv2:@bad": ` +
+					`could not parse reference: docker.io/istio/proxyv2:@bad`,
+				`values.gateways.injectionOverrides.istio-egressgateway.volumes[0]: name is required`,
+				`values.gateways.injectionOverrides.istio-egressgateway.volumeMounts[0]: name and mountPath are required`,
+			}),
+		},
 		{
 			desc: "BadValuesIP",
 			yamlStr: `
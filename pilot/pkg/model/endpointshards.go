@@ -147,6 +147,23 @@ func (e *EndpointIndex) Shardz() map[string]map[string]*EndpointShards {
 	return out
 }
 
+// EndpointCountsByCluster returns the number of endpoints held for each cluster, across all services.
+func (e *EndpointIndex) EndpointCountsByCluster() map[cluster.ID]int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	out := map[cluster.ID]int{}
+	for _, byNs := range e.shardsBySvc {
+		for _, shards := range byNs {
+			shards.RLock()
+			for k, eps := range shards.Shards {
+				out[k.Cluster] += len(eps)
+			}
+			shards.RUnlock()
+		}
+	}
+	return out
+}
+
 // ShardsForService returns the shards and true if they are found, or returns nil, false.
 func (e *EndpointIndex) ShardsForService(serviceName, namespace string) (*EndpointShards, bool) {
 	e.mu.RLock()
@@ -1128,7 +1128,12 @@ func (s *DiscoveryServer) clusterz(w http.ResponseWriter, req *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	writeJSON(w, s.ListRemoteClusters(), req)
+	clusters := s.ListRemoteClusters()
+	endpoints := s.Env.EndpointIndex.EndpointCountsByCluster()
+	for i := range clusters {
+		clusters[i].Endpoints = endpoints[clusters[i].ID]
+	}
+	writeJSON(w, clusters, req)
 }
 
 // handlePushRequest handles a ?push=true query param and triggers a push.
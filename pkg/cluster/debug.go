@@ -14,6 +14,8 @@
 
 package cluster
 
+import "time"
+
 // DebugInfo contains minimal information about remote clusters.
 // This struct is defined here, in a package that avoids many imports, since xds/debug usually
 // affects agent binary size. We avoid embedding other parts of a "remote cluster" struct like kube clients.
@@ -21,4 +23,10 @@ type DebugInfo struct {
 	ID         ID     `json:"id"`
 	SecretName string `json:"secretName"`
 	SyncStatus string `json:"syncStatus"`
+	// Endpoints is the number of endpoints istiod currently holds for the cluster.
+	Endpoints int `json:"endpoints"`
+	// LastSyncTime is when the informers for the cluster completed their initial sync, if they did.
+	LastSyncTime *time.Time `json:"lastSyncTime,omitempty"`
+	// SyncErrors counts failed attempts to sync the cluster, such as timed out syncs or unusable kubeconfig updates.
+	SyncErrors int64 `json:"syncErrors"`
 }
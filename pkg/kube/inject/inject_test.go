@@ -333,6 +333,10 @@ func TestInjection(t *testing.T) {
 				m.DefaultConfig.Tracing = &meshapi.Tracing{}
 			},
 		},
+		{
+			in:            "traffic-annotations-bad-outboundcapturepercent.yaml",
+			expectedError: "outboundcapturepercent",
+		},
 	}
 	// Keep track of tests we add options above
 	// We will search for all test files and skip these ones
@@ -48,9 +48,21 @@ var (
 		annotation.SidecarTrafficExcludeOutboundPorts.Name:        ValidateExcludeOutboundPorts,
 		annotation.PrometheusMergeMetrics.Name:                    validateBool,
 		annotation.ProxyConfig.Name:                               validateProxyConfig,
+		OutboundCapturePercentAnnotation:                          validateOutboundCapturePercent,
+		IncludeOutboundUDPPortsAnnotation:                         ValidateIncludeOutboundUDPPorts,
 	}
 )
 
+// OutboundCapturePercentAnnotation redirects only a percentage of the new outbound connections of a pod to the
+// proxy, to move a workload into the mesh gradually, 0 redirecting none of them to roll it back. It is
+// experimental, so not part of the API annotations yet.
+const OutboundCapturePercentAnnotation = "traffic.sidecar.istio.io/outboundCapturePercent"
+
+// IncludeOutboundUDPPortsAnnotation redirects the outbound UDP traffic of a pod to the listed ports to the UDP
+// listener of the proxy. It is only applied to proxies which advertise such a listener in their
+// ISTIO_META_PROXY_UDP_PORT metadata.
+const IncludeOutboundUDPPortsAnnotation = "traffic.sidecar.istio.io/includeOutboundUDPPorts"
+
 func validateProxyConfig(value string) error {
 	config := mesh.DefaultProxyConfig()
 	if err := protomarshal.ApplyYAML(value, config); err != nil {
@@ -125,6 +137,11 @@ func ValidateExcludeOutboundPorts(ports string) error {
 	return validatePortList("excludeOutboundPorts", ports)
 }
 
+// ValidateIncludeOutboundUDPPorts validates the includeOutboundUDPPorts parameter
+func ValidateIncludeOutboundUDPPorts(ports string) error {
+	return validatePortList("includeOutboundUDPPorts", ports)
+}
+
 // validateStatusPort validates the statusPort parameter
 func validateStatusPort(port string) error {
 	if _, e := parsePort(port); e != nil {
@@ -139,6 +156,18 @@ func validateUInt32(value string) error {
 	return err
 }
 
+// validateOutboundCapturePercent validates the outboundCapturePercent parameter
+func validateOutboundCapturePercent(value string) error {
+	percent, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return fmt.Errorf("outboundCapturePercent invalid: %v", err)
+	}
+	if percent > 100 {
+		return fmt.Errorf("outboundCapturePercent invalid: %d is not between 0 and 100", percent)
+	}
+	return nil
+}
+
 // validateBool validates that the given annotation value is a boolean.
 func validateBool(value string) error {
 	_, err := strconv.ParseBool(value)
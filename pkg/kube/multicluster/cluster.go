@@ -40,6 +40,10 @@ type Cluster struct {
 	initialSync *atomic.Bool
 	// initialSyncTimeout is set when RunAndWait timed out
 	initialSyncTimeout *atomic.Bool
+	// lastSyncTime is set when RunAndWait completes
+	lastSyncTime *atomic.Time
+	// syncErrors counts failed attempts to sync the cluster
+	syncErrors *atomic.Int64
 }
 
 // Run starts the cluster's informers and waits for caches to sync. Once caches are synced, we mark the cluster synced.
@@ -50,12 +54,14 @@ func (r *Cluster) Run() {
 			if !r.initialSync.Load() {
 				log.Errorf("remote cluster %s failed to sync after %v", r.ID, features.RemoteClusterTimeout)
 				timeouts.With(clusterLabel.Value(string(r.ID))).Increment()
+				r.syncErrors.Inc()
 			}
 			r.initialSyncTimeout.Store(true)
 		})
 	}
 
 	r.Client.RunAndWait(r.stop)
+	r.lastSyncTime.Store(time.Now())
 	r.initialSync.Store(true)
 }
 
@@ -91,3 +97,16 @@ func (r *Cluster) Closed() bool {
 func (r *Cluster) SyncDidTimeout() bool {
 	return !r.initialSync.Load() && r.initialSyncTimeout.Load()
 }
+
+// LastSyncTime returns when the cluster completed its initial sync, or the zero time if it has not.
+func (r *Cluster) LastSyncTime() time.Time {
+	if !r.initialSync.Load() {
+		return time.Time{}
+	}
+	return r.lastSyncTime.Load()
+}
+
+// SyncErrors returns the number of failed attempts to sync the cluster.
+func (r *Cluster) SyncErrors() int64 {
+	return r.syncErrors.Load()
+}
@@ -303,6 +303,8 @@ func (c *Controller) createRemoteCluster(kubeConfig []byte, clusterID string) (*
 		// for use inside the package, to close on cleanup
 		initialSync:        atomic.NewBool(false),
 		initialSyncTimeout: atomic.NewBool(false),
+		lastSyncTime:       atomic.NewTime(time.Time{}),
+		syncErrors:         atomic.NewInt64(0),
 		kubeConfigSha:      sha256.Sum256(kubeConfig),
 	}, nil
 }
@@ -326,7 +328,8 @@ func (c *Controller) addSecret(name types.NamespacedName, s *corev1.Secret) erro
 		}
 
 		action, callback := "Adding", c.handleAdd
-		if prev := c.cs.Get(secretKey, cluster.ID(clusterID)); prev != nil {
+		prev := c.cs.Get(secretKey, cluster.ID(clusterID))
+		if prev != nil {
 			action, callback = "Updating", c.handleUpdate
 			// clusterID must be unique even across multiple secrets
 			kubeConfigSha := sha256.Sum256(kubeConfig)
@@ -346,6 +349,9 @@ func (c *Controller) addSecret(name types.NamespacedName, s *corev1.Secret) erro
 		remoteCluster, err := c.createRemoteCluster(kubeConfig, clusterID)
 		if err != nil {
 			logger.Errorf("%s cluster: create remote cluster failed: %v", action, err)
+			if prev != nil {
+				prev.syncErrors.Inc()
+			}
 			errs = multierror.Append(errs, err)
 			continue
 		}
@@ -412,11 +418,16 @@ func (c *Controller) ListRemoteClusters() []cluster.DebugInfo {
 			} else if c.HasSynced() {
 				syncStatus = "synced"
 			}
-			out = append(out, cluster.DebugInfo{
+			info := cluster.DebugInfo{
 				ID:         clusterID,
 				SecretName: secretName,
 				SyncStatus: syncStatus,
-			})
+				SyncErrors: c.SyncErrors(),
+			}
+			if t := c.LastSyncTime(); !t.IsZero() {
+				info.LastSyncTime = &t
+			}
+			out = append(out, info)
 		}
 	}
 	return out
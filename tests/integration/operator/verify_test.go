@@ -56,7 +56,7 @@ func TestPostInstallControlPlaneVerification(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			if err := statusVerifier.Verify(); err != nil {
+			if _, err := statusVerifier.Verify(); err != nil {
 				t.Fatal(err)
 			}
 		})
@@ -73,6 +73,11 @@ func removeOldChains(cfg *config.Config, ext dep.Dependencies, cmd string) {
 	// Flush and delete the istio chains from NAT table.
 	chains := []string{constants.ISTIOOUTPUT, constants.ISTIOINBOUND}
 	flushAndDeleteChains(ext, cmd, constants.NAT, chains)
+	// The chain of the outbound UDP traffic only exists if outbound UDP ports were captured
+	if cfg.OutboundUDPPortsInclude != "" {
+		ext.RunQuietlyAndIgnore(cmd, nil, "-t", constants.NAT, "-D", constants.OUTPUT, "-p", constants.UDP, "-j", constants.ISTIOOUTPUTUDP)
+		flushAndDeleteChains(ext, cmd, constants.NAT, []string{constants.ISTIOOUTPUTUDP})
+	}
 	// Flush and delete the istio chains from MANGLE table.
 	chains = []string{constants.ISTIOINBOUND, constants.ISTIODIVERT, constants.ISTIOTPROXY}
 	flushAndDeleteChains(ext, cmd, constants.MANGLE, chains)
@@ -81,6 +81,12 @@ func TestIptables(t *testing.T) {
 				cfg.InboundTProxyMark = "1337"
 			},
 		},
+		{
+			"outbound-udp-ports",
+			func(cfg *config.Config) {
+				cfg.OutboundUDPPortsInclude = "5060,9000"
+			},
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -131,6 +137,11 @@ func (s *DependenciesStub) RunQuietlyAndIgnore(cmd string, stdin io.ReadSeeker,
 	s.execute(true /*quietly*/, cmd, args...)
 }
 
+func (s *DependenciesStub) RunWithOutput(cmd string, stdin io.ReadSeeker, args ...string) (string, error) {
+	s.execute(false /*quietly*/, cmd, args...)
+	return "", nil
+}
+
 func (s *DependenciesStub) execute(quietly bool, cmd string, args ...string) {
 	cmdline := strings.Join(append([]string{cmd}, args...), " ")
 	s.ExecutedAll = append(s.ExecutedAll, cmdline)
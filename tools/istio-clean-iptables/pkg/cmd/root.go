@@ -44,6 +44,10 @@ func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
 		&cfg.InboundInterceptionMode)
 
 	flag.BindEnv(fs, constants.InboundTProxyMark, "t", "", &cfg.InboundTProxyMark)
+
+	flag.BindEnv(fs, constants.OutboundUDPPorts, "",
+		"Comma separated list of outbound UDP ports which were redirected to the proxy UDP port.",
+		&cfg.OutboundUDPPortsInclude)
 }
 
 func GetCommand() *cobra.Command {
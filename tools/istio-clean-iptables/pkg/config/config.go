@@ -49,6 +49,7 @@ type Config struct {
 	OwnerGroupsExclude      string   `json:"OUTBOUND_OWNER_GROUPS_EXCLUDE"`
 	InboundInterceptionMode string   `json:"INBOUND_INTERCEPTION_MODE"`
 	InboundTProxyMark       string   `json:"INBOUND_TPROXY_MARK"`
+	OutboundUDPPortsInclude string   `json:"OUTBOUND_UDP_PORTS_INCLUDE"`
 }
 
 func (c *Config) String() string {
@@ -69,6 +70,7 @@ func (c *Config) Print() {
 	fmt.Printf("DNS_SERVERS=%s,%s\n", c.DNSServersV4, c.DNSServersV6)
 	fmt.Printf("OUTBOUND_OWNER_GROUPS_INCLUDE=%s\n", c.OwnerGroupsInclude)
 	fmt.Printf("OUTBOUND_OWNER_GROUPS_EXCLUDE=%s\n", c.OwnerGroupsExclude)
+	fmt.Printf("OUTBOUND_UDP_PORTS_INCLUDE=%s\n", c.OutboundUDPPortsInclude)
 	fmt.Println("")
 }
 
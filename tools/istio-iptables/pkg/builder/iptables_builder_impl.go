@@ -16,6 +16,7 @@ package builder
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"istio.io/istio/pkg/util/sets"
@@ -37,10 +38,16 @@ type Rules struct {
 	rulesv6 []*Rule
 }
 
+// ChainPositions holds the 1-based positions at which the rules of Istio are inserted in built-in chains, by table
+// and chain. The rules of built-in chains without a position are appended.
+type ChainPositions map[string]map[string]int
+
 // IptablesBuilder is an implementation for IptablesBuilder interface
 type IptablesBuilder struct {
-	rules Rules
-	cfg   *config.Config
+	rules       Rules
+	cfg         *config.Config
+	positionsv4 ChainPositions
+	positionsv6 ChainPositions
 }
 
 // NewIptablesBuilders creates a new IptablesBuilder
@@ -169,12 +176,63 @@ func (rb *IptablesBuilder) buildRules(command string, rules []*Rule) [][]string
 	return output
 }
 
+// SetChainPositions places the rules of built-in chains at the given positions instead of appending them. The rules
+// of a chain are inserted as a block, in the order they were added. Rules inserted at an explicit position are
+// placed relative to the start of the block.
+func (rb *IptablesBuilder) SetChainPositions(v4, v6 ChainPositions) *IptablesBuilder {
+	rb.positionsv4 = v4
+	rb.positionsv6 = v6
+	return rb
+}
+
+func positionRules(rules []*Rule, positions ChainPositions) []*Rule {
+	if len(positions) == 0 {
+		return rules
+	}
+	next := map[string]int{}
+	out := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		start := positions[r.table][r.chain]
+		if _, builtIn := constants.BuiltInChainsMap[r.chain]; !builtIn || start <= 0 || len(r.params) < 2 {
+			out = append(out, r)
+			continue
+		}
+		key := r.table + ":" + r.chain
+		if _, f := next[key]; !f {
+			next[key] = start
+		}
+		var params []string
+		switch r.params[0] {
+		case "-A":
+			params = append([]string{"-I", r.chain, strconv.Itoa(next[key])}, r.params[2:]...)
+		case "-I":
+			pos, err := strconv.Atoi(r.params[2])
+			if err != nil {
+				out = append(out, r)
+				continue
+			}
+			params = append([]string{"-I", r.chain, strconv.Itoa(start + pos - 1)}, r.params[3:]...)
+		default:
+			out = append(out, r)
+			continue
+		}
+		next[key]++
+		out = append(out, &Rule{chain: r.chain, table: r.table, params: params})
+	}
+	return out
+}
+
+// RuleCounts returns the numbers of IPv4 and IPv6 rules added to the builder, not counting the creation of chains.
+func (rb *IptablesBuilder) RuleCounts() (int, int) {
+	return len(rb.rules.rulesv4), len(rb.rules.rulesv6)
+}
+
 func (rb *IptablesBuilder) BuildV4() [][]string {
-	return rb.buildRules(constants.IPTABLES, rb.rules.rulesv4)
+	return rb.buildRules(constants.IPTABLES, positionRules(rb.rules.rulesv4, rb.positionsv4))
 }
 
 func (rb *IptablesBuilder) BuildV6() [][]string {
-	return rb.buildRules(constants.IP6TABLES, rb.rules.rulesv6)
+	return rb.buildRules(constants.IP6TABLES, positionRules(rb.rules.rulesv6, rb.positionsv6))
 }
 
 func (rb *IptablesBuilder) constructIptablesRestoreContents(tableRulesMap map[string][]string) string {
@@ -218,11 +276,11 @@ func (rb *IptablesBuilder) buildRestore(rules []*Rule) string {
 }
 
 func (rb *IptablesBuilder) BuildV4Restore() string {
-	return rb.buildRestore(rb.rules.rulesv4)
+	return rb.buildRestore(positionRules(rb.rules.rulesv4, rb.positionsv4))
 }
 
 func (rb *IptablesBuilder) BuildV6Restore() string {
-	return rb.buildRestore(rb.rules.rulesv6)
+	return rb.buildRestore(positionRules(rb.rules.rulesv6, rb.positionsv6))
 }
 
 // AppendVersionedRule is a wrapper around AppendRule that substitutes an ipv4/ipv6 specific value
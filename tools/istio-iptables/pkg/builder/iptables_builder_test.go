@@ -312,3 +312,29 @@ func TestBuildV4V6MultipleRulesWithNewChain(t *testing.T) {
 		t.Errorf("Actual and expected output mismatch; but instead got Actual: %#v ; Expected: %#v", actualV6, expectedV6)
 	}
 }
+
+func TestBuildV4ChainPositions(t *testing.T) {
+	iptables := NewIptablesBuilder(nil)
+	iptables.AppendRuleV4(iptableslog.UndefinedCommand, constants.OUTPUT, constants.NAT, "-o", "eth1", "-j", "RETURN")
+	iptables.AppendRuleV4(iptableslog.UndefinedCommand, "ISTIO_OUTPUT", constants.NAT, "-j", "RETURN")
+	iptables.AppendRuleV4(iptableslog.UndefinedCommand, constants.OUTPUT, constants.NAT, "-p", "tcp", "-j", "ISTIO_OUTPUT")
+	iptables.InsertRuleV4(iptableslog.UndefinedCommand, constants.PREROUTING, constants.NAT, 1, "-i", "eth1", "-j", "RETURN")
+	iptables.AppendRuleV4(iptableslog.UndefinedCommand, constants.PREROUTING, constants.NAT, "-p", "tcp", "-j", "ISTIO_INBOUND")
+	iptables.AppendRuleV4(iptableslog.UndefinedCommand, constants.OUTPUT, constants.MANGLE, "-j", "MARK", "--set-mark", "1")
+	iptables.SetChainPositions(ChainPositions{
+		constants.NAT: {constants.OUTPUT: 3, constants.PREROUTING: 1},
+	}, nil)
+	actual := iptables.BuildV4()
+	expected := [][]string{
+		{"iptables", "-t", "nat", "-N", "ISTIO_OUTPUT"},
+		{"iptables", "-t", "nat", "-I", "OUTPUT", "3", "-o", "eth1", "-j", "RETURN"},
+		{"iptables", "-t", "nat", "-A", "ISTIO_OUTPUT", "-j", "RETURN"},
+		{"iptables", "-t", "nat", "-I", "OUTPUT", "4", "-p", "tcp", "-j", "ISTIO_OUTPUT"},
+		{"iptables", "-t", "nat", "-I", "PREROUTING", "1", "-i", "eth1", "-j", "RETURN"},
+		{"iptables", "-t", "nat", "-I", "PREROUTING", "2", "-p", "tcp", "-j", "ISTIO_INBOUND"},
+		{"iptables", "-t", "mangle", "-A", "OUTPUT", "-j", "MARK", "--set-mark", "1"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual and expected output mismatch; but instead got Actual: %#v ; Expected: %#v", actual, expected)
+	}
+}
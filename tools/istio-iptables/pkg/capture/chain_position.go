@@ -0,0 +1,249 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"fmt"
+	"strings"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/maps"
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/tools/istio-iptables/pkg/builder"
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
+// istioChainPrefix is the prefix of the chains created by Istio.
+const istioChainPrefix = "ISTIO_"
+
+// savedTable is a table as listed by iptables-save.
+type savedTable struct {
+	// chains are the user-defined chains of the table.
+	chains sets.String
+	// rules are the rules of each chain, in order.
+	rules map[string][]string
+}
+
+// parseSave parses the output of iptables-save into its tables.
+func parseSave(out string) map[string]*savedTable {
+	tables := map[string]*savedTable{}
+	var current *savedTable
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "*"):
+			current = &savedTable{chains: sets.New[string](), rules: map[string][]string{}}
+			tables[strings.TrimPrefix(line, "*")] = current
+		case current == nil:
+		case strings.HasPrefix(line, ":"):
+			chain, _, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+			if _, builtIn := constants.BuiltInChainsMap[chain]; !builtIn {
+				current.chains.Insert(chain)
+			}
+		case strings.HasPrefix(line, "-A "):
+			fields := strings.Fields(line)
+			if len(fields) > 1 {
+				current.rules[fields[1]] = append(current.rules[fields[1]], line)
+			}
+		}
+	}
+	return tables
+}
+
+// ruleTarget returns the chain or target a rule jumps to, if any.
+func ruleTarget(rule string) string {
+	fields := strings.Fields(rule)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "-j" || fields[i] == "-g" {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// chainPositions computes the positions of the rules of Istio in the built-in chains from the current rules listed
+// by iptables-save. Chains the rules are appended to are left out.
+func chainPositions(position config.ChainPosition, tables map[string]*savedTable) builder.ChainPositions {
+	positions := builder.ChainPositions{}
+	referenced := false
+	for _, table := range []string{constants.NAT, constants.MANGLE, constants.RAW, constants.FILTER} {
+		positions[table] = map[string]int{}
+		for chain := range constants.BuiltInChainsMap {
+			var rules []string
+			if t := tables[table]; t != nil {
+				rules = t.rules[chain]
+			}
+			switch position.Mode {
+			case config.ChainPositionHead:
+				positions[table][chain] = 1
+			case config.ChainPositionBefore:
+				for i, r := range rules {
+					if ruleTarget(r) == position.Chain {
+						positions[table][chain] = i + 1
+						referenced = true
+						break
+					}
+				}
+			case config.ChainPositionAfter:
+				for i := len(rules) - 1; i >= 0; i-- {
+					if ruleTarget(rules[i]) == position.Chain {
+						positions[table][chain] = i + 2
+						referenced = true
+						break
+					}
+				}
+			}
+		}
+	}
+	if (position.Mode == config.ChainPositionBefore || position.Mode == config.ChainPositionAfter) && !referenced {
+		log.Warnf("Chain %s is not jumped to from any built-in chain, appending the rules of Istio instead of placing them %s it",
+			position.Chain, position.Mode)
+	}
+	return positions
+}
+
+// chainPositionWarnings reports the built-in chains in which the rules of other agents are placed ahead of the jumps
+// of Istio to its own chains, contrary to the configured position. This happens if another agent inserted its rules
+// after Istio did, in which case the traffic may not be captured as expected.
+func chainPositionWarnings(position config.ChainPosition, tables map[string]*savedTable) []string {
+	if position.Mode == config.ChainPositionAppend {
+		return nil
+	}
+	var warnings []string
+	for _, name := range sets.SortedList(sets.New(maps.Keys(tables)...)) {
+		table := tables[name]
+		for _, chain := range sets.SortedList(sets.New(maps.Keys(constants.BuiltInChainsMap)...)) {
+			rules := table.rules[chain]
+			istio := -1
+			for i, r := range rules {
+				if strings.HasPrefix(ruleTarget(r), istioChainPrefix) {
+					istio = i
+					break
+				}
+			}
+			if istio < 0 {
+				continue
+			}
+			var ahead []string
+			for _, r := range rules[:istio] {
+				if target := ruleTarget(r); table.chains.Contains(target) && !strings.HasPrefix(target, istioChainPrefix) {
+					ahead = append(ahead, target)
+				}
+			}
+			var problem string
+			switch position.Mode {
+			case config.ChainPositionHead:
+				if len(ahead) > 0 {
+					problem = fmt.Sprintf("jumps to %s precede", strings.Join(ahead, ", "))
+				}
+			case config.ChainPositionBefore:
+				for _, target := range ahead {
+					if target == position.Chain {
+						problem = fmt.Sprintf("a jump to %s precedes", target)
+						break
+					}
+				}
+			case config.ChainPositionAfter:
+				if rulesJumpTo(rules[istio+1:], position.Chain) && !rulesJumpTo(rules[:istio], position.Chain) {
+					problem = fmt.Sprintf("a jump to %s follows instead of preceding", position.Chain)
+				}
+			}
+			if problem != "" {
+				warnings = append(warnings, fmt.Sprintf("%s chain of the %s table: %s the rules of Istio, contrary to chain position %q",
+					chain, name, problem, position))
+			}
+		}
+	}
+	return warnings
+}
+
+func rulesJumpTo(rules []string, chain string) bool {
+	for _, r := range rules {
+		if ruleTarget(r) == chain {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckChainPosition lists the current rules with the save command, such as iptables-save, and returns a warning for
+// each built-in chain in which the rules of another agent are placed ahead of the rules of Istio, contrary to the
+// chain position of the configuration. It can be run again later to detect agents that inserted their rules since.
+func CheckChainPosition(ext dep.Dependencies, saveCmd string, cfg *config.Config) ([]string, error) {
+	position, err := config.ParseChainPosition(cfg.ChainPosition)
+	if err != nil {
+		return nil, err
+	}
+	if position.Mode == config.ChainPositionAppend {
+		return nil, nil
+	}
+	out, err := ext.RunWithOutput(saveCmd, nil)
+	if err != nil {
+		return nil, err
+	}
+	return chainPositionWarnings(position, parseSave(out)), nil
+}
+
+// setChainPositions sets the positions of the rules of Istio in the built-in chains, according to the current rules
+// of other agents.
+func (cfg *IptablesConfigurator) setChainPositions() error {
+	position, err := config.ParseChainPosition(cfg.cfg.ChainPosition)
+	if err != nil {
+		return err
+	}
+	if position.Mode == config.ChainPositionAppend {
+		return nil
+	}
+	positions := func(saveCmd string) (builder.ChainPositions, error) {
+		out, err := cfg.ext.RunWithOutput(saveCmd, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the current rules to place the rules of Istio %s: %v", position, err)
+		}
+		return chainPositions(position, parseSave(out)), nil
+	}
+	v4, err := positions(constants.IPTABLESSAVE)
+	if err != nil {
+		return err
+	}
+	var v6 builder.ChainPositions
+	if cfg.cfg.EnableInboundIPv6 {
+		if v6, err = positions(constants.IP6TABLESSAVE); err != nil {
+			return err
+		}
+	}
+	cfg.iptables.SetChainPositions(v4, v6)
+	return nil
+}
+
+// warnChainPosition logs the built-in chains in which the rules of other agents precede those of Istio.
+func (cfg *IptablesConfigurator) warnChainPosition() {
+	saveCmds := []string{constants.IPTABLESSAVE}
+	if cfg.cfg.EnableInboundIPv6 {
+		saveCmds = append(saveCmds, constants.IP6TABLESSAVE)
+	}
+	for _, saveCmd := range saveCmds {
+		warnings, err := CheckChainPosition(cfg.ext, saveCmd, cfg.cfg)
+		if err != nil {
+			log.Warnf("Failed to check the position of the rules of Istio: %v", err)
+			continue
+		}
+		for _, w := range warnings {
+			log.Warnf("%s: %s", saveCmd, w)
+			cfg.result.Warnings = append(cfg.result.Warnings, fmt.Sprintf("%s: %s", saveCmd, w))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"strings"
+	"testing"
+
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+)
+
+const savedRules = `# Generated by iptables-save v1.8.9 on Mon Jan  1 00:00:00 2024
+*nat
+:PREROUTING ACCEPT [0:0]
+:OUTPUT ACCEPT [0:0]
+:KUBE-SERVICES - [0:0]
+:CNI-HOSTPORT-DNAT - [0:0]
+:ISTIO_OUTPUT - [0:0]
+-A PREROUTING -m comment --comment "kubernetes service portals" -j KUBE-SERVICES
+-A OUTPUT -m comment --comment "kubernetes service portals" -j KUBE-SERVICES
+-A OUTPUT -j CNI-HOSTPORT-DNAT
+-A OUTPUT -p tcp -j ISTIO_OUTPUT
+-A OUTPUT -j RETURN
+COMMIT
+`
+
+func TestChainPositions(t *testing.T) {
+	tables := parseSave(savedRules)
+	cases := []struct {
+		position   config.ChainPosition
+		prerouting int
+		output     int
+	}{
+		{position: config.ChainPosition{Mode: config.ChainPositionHead}, prerouting: 1, output: 1},
+		{position: config.ChainPosition{Mode: config.ChainPositionBefore, Chain: "CNI-HOSTPORT-DNAT"}, output: 2},
+		{position: config.ChainPosition{Mode: config.ChainPositionAfter, Chain: "KUBE-SERVICES"}, prerouting: 2, output: 2},
+		{position: config.ChainPosition{Mode: config.ChainPositionAfter, Chain: "MISSING"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.position.String(), func(t *testing.T) {
+			positions := chainPositions(tc.position, tables)
+			if got := positions[constants.NAT][constants.PREROUTING]; got != tc.prerouting {
+				t.Errorf("expected PREROUTING position %d, got %d", tc.prerouting, got)
+			}
+			if got := positions[constants.NAT][constants.OUTPUT]; got != tc.output {
+				t.Errorf("expected OUTPUT position %d, got %d", tc.output, got)
+			}
+		})
+	}
+}
+
+func TestChainPositionWarnings(t *testing.T) {
+	tables := parseSave(savedRules)
+	cases := []struct {
+		position string
+		want     string
+	}{
+		{position: "append"},
+		{position: "head", want: "OUTPUT chain of the nat table: jumps to KUBE-SERVICES, CNI-HOSTPORT-DNAT precede the rules of Istio"},
+		{position: "before:CNI-HOSTPORT-DNAT", want: "a jump to CNI-HOSTPORT-DNAT precedes the rules of Istio"},
+		{position: "before:OTHER"},
+		{position: "after:KUBE-SERVICES"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.position, func(t *testing.T) {
+			position, err := config.ParseChainPosition(tc.position)
+			if err != nil {
+				t.Fatal(err)
+			}
+			warnings := chainPositionWarnings(position, tables)
+			if tc.want == "" {
+				if len(warnings) != 0 {
+					t.Fatalf("expected no warnings, got %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tc.want) {
+				t.Fatalf("expected a warning containing %q, got %v", tc.want, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"istio.io/istio/tools/istio-iptables/pkg/config"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
+// FixtureSuffix is the suffix of the files of the rule plans written by WriteFixtures.
+const FixtureSuffix = ".golden"
+
+// Fixture is a configuration of the matrix of fixtures whose rule plan is rendered into a golden file.
+type Fixture struct {
+	// Name is the name of the golden file, without FixtureSuffix.
+	Name string
+	// Config sets the fixture on top of the base configuration.
+	Config func(cfg *config.Config)
+}
+
+// Fixtures returns the matrix of fixtures: every inbound interception mode, with and without exclusions, for IPv4
+// only and dual-stack. The names, such as "tproxy-exclusions-dual-stack", are stable across Istio versions so that
+// the fixtures of two versions can be diffed.
+func Fixtures() []Fixture {
+	modes := []string{constants.REDIRECT, constants.TPROXY}
+	exclusions := []struct {
+		name   string
+		config func(cfg *config.Config)
+	}{
+		{"", func(cfg *config.Config) {}},
+		{"exclusions", func(cfg *config.Config) {
+			cfg.InboundPortsExclude = "15020,15021"
+			cfg.OutboundPortsExclude = "25,587"
+			cfg.OutboundIPRangesExclude = "169.254.169.254/32,fd00:ec2::254/128"
+			cfg.ExcludeInterfaces = "eth1"
+		}},
+	}
+	stacks := []struct {
+		name   string
+		config func(cfg *config.Config)
+	}{
+		{"ipv4", func(cfg *config.Config) {}},
+		{"dual-stack", func(cfg *config.Config) {
+			cfg.EnableInboundIPv6 = true
+			cfg.DualStack = true
+		}},
+	}
+
+	var fixtures []Fixture
+	for _, mode := range modes {
+		for _, exclusion := range exclusions {
+			for _, stack := range stacks {
+				mode, exclusion, stack := mode, exclusion, stack
+				parts := []string{strings.ToLower(mode)}
+				if exclusion.name != "" {
+					parts = append(parts, exclusion.name)
+				}
+				parts = append(parts, stack.name)
+				fixtures = append(fixtures, Fixture{
+					Name: strings.Join(parts, "-"),
+					Config: func(cfg *config.Config) {
+						cfg.InboundInterceptionMode = mode
+						exclusion.config(cfg)
+						stack.config(cfg)
+					},
+				})
+			}
+		}
+	}
+	return fixtures
+}
+
+// FixtureBaseConfig returns the configuration the fixtures are set on: the defaults, capturing all inbound ports and
+// outbound addresses of a proxy running as the default UID and GID.
+func FixtureBaseConfig() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.ProxyUID = constants.DefaultProxyUID
+	cfg.ProxyGID = constants.DefaultProxyUID
+	cfg.InboundPortsInclude = "*"
+	cfg.OutboundIPRangesInclude = "*"
+	return cfg
+}
+
+// RenderFixture returns the rule plan of a fixture set on the base configuration, without applying it.
+func RenderFixture(base *config.Config, fixture Fixture) ([]string, error) {
+	cfg := *base
+	fixture.Config(&cfg)
+	iptConfigurator := NewIptablesConfigurator(&cfg, &dep.StdoutStubDependencies{})
+	if err := iptConfigurator.Run(); err != nil {
+		return nil, fmt.Errorf("failed to render fixture %s: %v", fixture.Name, err)
+	}
+	return iptConfigurator.Plan(), nil
+}
+
+// WriteFixtures renders the rule plan of every fixture into a golden file of the directory, which is created if it
+// does not exist, and returns the paths of the files written.
+func WriteFixtures(dir string, base *config.Config, fixtures []Fixture) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the fixtures directory: %v", err)
+	}
+	paths := make([]string, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		plan, err := RenderFixture(base, fixture)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, fixture.Name+FixtureSuffix)
+		if err := os.WriteFile(path, []byte(strings.Join(plan, "\n")+"\n"), 0o644); err != nil {
+			return paths, fmt.Errorf("failed to write fixture %s: %v", fixture.Name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,47 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testutil "istio.io/istio/pilot/test/util"
+)
+
+func TestWriteFixtures(t *testing.T) {
+	fixtures := Fixtures()
+	if len(fixtures) != 8 {
+		t.Fatalf("got %d fixtures, want 8", len(fixtures))
+	}
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	paths, err := WriteFixtures(dir, FixtureBaseConfig(), fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != len(fixtures) {
+		t.Fatalf("got %d files, want %d", len(paths), len(fixtures))
+	}
+	for i, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			got, err := os.ReadFile(paths[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			testutil.CompareContent(t, got, filepath.Join("testdata", "fixtures", fixture.Name+FixtureSuffix))
+		})
+	}
+}
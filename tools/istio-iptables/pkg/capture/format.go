@@ -23,3 +23,9 @@ func FormatIptablesCommands(commands [][]string) []string {
 	}
 	return output
 }
+
+// Plan returns the commands of the rules of the last run of the configurator, IPv4 first, such as
+// "iptables -t nat -N ISTIO_INBOUND", whether they were applied or not.
+func (cfg *IptablesConfigurator) Plan() []string {
+	return FormatIptablesCommands(append(cfg.iptables.BuildV4(), cfg.iptables.BuildV6()...))
+}
@@ -0,0 +1,73 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capture
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IP families of the rules programmed by the configurator.
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
+// Result describes what a run of the configurator programmed, for callers such as the CNI plugin to include in
+// their own logs.
+type Result struct {
+	// RulesV4 and RulesV6 are the numbers of IPv4 and IPv6 rules applied.
+	RulesV4 int
+	RulesV6 int
+	// Families are the IP families rules were applied for.
+	Families []string
+	// Warnings are the problems found which did not fail the run, such as rules of other agents placed ahead of
+	// the rules of Istio.
+	Warnings []string
+	// Duration is how long the run took.
+	Duration time.Duration
+}
+
+// Rules returns the number of rules applied for all IP families.
+func (r *Result) Rules() int {
+	return r.RulesV4 + r.RulesV6
+}
+
+func (r *Result) String() string {
+	families := strings.Join(r.Families, ",")
+	if families == "" {
+		families = "none"
+	}
+	return fmt.Sprintf("applied %d rules (%d IPv4, %d IPv6) for families %s in %v with %d warnings",
+		r.Rules(), r.RulesV4, r.RulesV6, families, r.Duration.Round(time.Millisecond), len(r.Warnings))
+}
+
+// Result returns what the last run of the configurator programmed.
+func (cfg *IptablesConfigurator) Result() *Result {
+	return &cfg.result
+}
+
+// recordRules records the rules applied by the commands of the run.
+func (cfg *IptablesConfigurator) recordRules() {
+	cfg.result.RulesV4, cfg.result.RulesV6 = cfg.iptables.RuleCounts()
+	cfg.result.Families = nil
+	if cfg.result.RulesV4 > 0 {
+		cfg.result.Families = append(cfg.result.Families, FamilyIPv4)
+	}
+	if cfg.result.RulesV6 > 0 {
+		cfg.result.Families = append(cfg.result.Families, FamilyIPv6)
+	}
+}
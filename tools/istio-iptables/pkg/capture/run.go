@@ -18,7 +18,9 @@ import (
 	"net"
 	"net/netip"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vishvananda/netlink"
 
@@ -53,6 +55,8 @@ type IptablesConfigurator struct {
 	// TODO(abhide): Fix dep.Dependencies with better interface
 	ext dep.Dependencies
 	cfg *config.Config
+	// result describes what the last run programmed.
+	result Result
 }
 
 func NewIptablesConfigurator(cfg *config.Config, ext dep.Dependencies) *IptablesConfigurator {
@@ -153,6 +157,9 @@ func (cfg *IptablesConfigurator) handleInboundPortsInclude() {
 		cfg.iptables.AppendRule(iptableslog.JumpInbound, constants.PREROUTING, table, "-p", constants.TCP,
 			"-j", constants.ISTIOINBOUND)
 
+		cfg.handleHostPortHairpin(table)
+		cfg.handleKubeletProbes(table)
+
 		if cfg.cfg.InboundPortsInclude == "*" {
 			// Apply any user-specified port exclusions.
 			if cfg.cfg.InboundPortsExclude != "" {
@@ -191,6 +198,40 @@ func (cfg *IptablesConfigurator) handleInboundPortsInclude() {
 	}
 }
 
+// handleHostPortHairpin skips inbound capture of hostPort hairpin traffic: connections the pod opens to
+// its own hostPort, which the node DNATs back to the pod. The outbound capture already sent them through
+// Envoy, which forwards them in plaintext as passthrough traffic; capturing them again on the inbound
+// path would proxy them twice, and reject them when the workload requires mTLS.
+// Hairpin traffic which is not masqueraded on the node arrives from the pod's own address on a
+// non-loopback interface, which is what the rules match on. Masqueraded hairpin traffic arrives from
+// the node and is captured like any other inbound traffic.
+func (cfg *IptablesConfigurator) handleHostPortHairpin(table string) {
+	if cfg.cfg.HostPortHairpinPorts == "" {
+		return
+	}
+	if cfg.cfg.HostPortHairpinPorts == "*" {
+		cfg.iptables.AppendRule(iptableslog.HostPortHairpin, constants.ISTIOINBOUND, table, "-p", constants.TCP,
+			"!", "-i", "lo", "-m", "addrtype", "--src-type", "LOCAL", "-j", constants.RETURN)
+		return
+	}
+	for _, port := range split(cfg.cfg.HostPortHairpinPorts) {
+		cfg.iptables.AppendRule(iptableslog.HostPortHairpin, constants.ISTIOINBOUND, table, "-p", constants.TCP,
+			"--dport", port, "!", "-i", "lo", "-m", "addrtype", "--src-type", "LOCAL", "-j", constants.RETURN)
+	}
+}
+
+// handleKubeletProbes skips inbound capture of the health probes of the kubelet. The node agent connmarks
+// the connections the host opens to the pods of the node and translates their source to the kubelet probe
+// source addresses, as the mark itself does not cross into the network namespace of the pod.
+func (cfg *IptablesConfigurator) handleKubeletProbes(table string) {
+	if !cfg.cfg.ExemptKubeletProbes {
+		return
+	}
+	cfg.iptables.AppendVersionedRule(constants.KubeletProbeSourceV4+"/32", constants.KubeletProbeSourceV6+"/128",
+		iptableslog.KubeletProbe, constants.ISTIOINBOUND, table, "-p", constants.TCP,
+		"-s", constants.IPVersionSpecific, "-j", constants.RETURN)
+}
+
 func (cfg *IptablesConfigurator) handleOutboundIncludeRules(
 	rangeInclude NetworkRange,
 	appendRule func(command iptableslog.Command, chain string, table string, params ...string) *builder.IptablesBuilder,
@@ -279,7 +320,10 @@ func configureIPv6Addresses(cfg *config.Config) error {
 }
 
 func (cfg *IptablesConfigurator) Run() error {
+	start := time.Now()
+	cfg.result = Result{}
 	defer func() {
+		cfg.result.Duration = time.Since(start)
 		// Best effort since we don't know if the commands exist
 		_ = cfg.ext.Run(constants.IPTABLESSAVE, nil)
 		if cfg.cfg.EnableInboundIPv6 {
@@ -287,6 +331,13 @@ func (cfg *IptablesConfigurator) Run() error {
 		}
 	}()
 
+	if err := cfg.cfg.ApplyCapturePreset(); err != nil {
+		return err
+	}
+	if err := cfg.cfg.ApplyPlatformQuirks(); err != nil {
+		return err
+	}
+
 	// Since OUTBOUND_IP_RANGES_EXCLUDE could carry ipv4 and ipv6 ranges
 	// need to split them in different arrays one for ipv4 and one for ipv6
 	// in order to not to fail
@@ -493,6 +544,8 @@ func (cfg *IptablesConfigurator) Run() error {
 		cfg.iptables.AppendRuleV6(iptableslog.UndefinedCommand, constants.ISTIOOUTPUT, constants.NAT, "-d", cidr.String(), "-j", constants.RETURN)
 	}
 
+	cfg.handleOutboundCapturePercent()
+
 	cfg.handleOutboundPortsInclude()
 
 	cfg.handleOutboundIncludeRules(ipv4RangesInclude, cfg.iptables.AppendRuleV4, cfg.iptables.InsertRuleV4)
@@ -506,6 +559,8 @@ func (cfg *IptablesConfigurator) Run() error {
 			ownerGroupsFilter)
 	}
 
+	cfg.handleOutboundUDPPortsInclude()
+
 	if cfg.cfg.InboundInterceptionMode == constants.TPROXY {
 		// save packet mark set by envoy.filters.listener.original_src as connection mark
 		cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.PREROUTING, constants.MANGLE,
@@ -542,7 +597,18 @@ func (cfg *IptablesConfigurator) Run() error {
 		cfg.iptables.InsertRule(iptableslog.UndefinedCommand, constants.ISTIOINBOUND, constants.MANGLE, 3,
 			"-p", constants.TCP, "-i", "lo", "-m", "mark", "!", "--mark", outboundMark, "-j", constants.RETURN)
 	}
-	return cfg.executeCommands()
+	if err := cfg.handleRuleTemplates(); err != nil {
+		return err
+	}
+	if err := cfg.setChainPositions(); err != nil {
+		return err
+	}
+	if err := cfg.executeCommands(); err != nil {
+		return err
+	}
+	cfg.recordRules()
+	cfg.warnChainPosition()
+	return nil
 }
 
 type UDPRuleApplier struct {
@@ -703,6 +769,25 @@ func addConntrackZoneDNSUDP(
 	}
 }
 
+// handleOutboundCapturePercent lets a random share of outbound connections bypass Envoy.
+// The nat table is only traversed by the first packet of a connection, so the
+// selection is made once per connection and holds for its lifetime. An unset percentage captures
+// every connection, and 0 none, to roll a workload back out of the mesh.
+func (cfg *IptablesConfigurator) handleOutboundCapturePercent() {
+	// The percentage was validated with the config.
+	percent, _ := config.ParseOutboundCapturePercent(cfg.cfg.OutboundCapturePercent)
+	if percent >= 100 {
+		return
+	}
+	if percent == 0 {
+		cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.ISTIOOUTPUT, constants.NAT, "-j", constants.RETURN)
+		return
+	}
+	bypass := strconv.FormatFloat(float64(100-percent)/100, 'f', 2, 64)
+	cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.ISTIOOUTPUT, constants.NAT,
+		"-m", "statistic", "--mode", "random", "--probability", bypass, "-j", constants.RETURN)
+}
+
 func (cfg *IptablesConfigurator) handleOutboundPortsInclude() {
 	if cfg.cfg.OutboundPortsInclude != "" {
 		for _, port := range split(cfg.cfg.OutboundPortsInclude) {
@@ -712,6 +797,31 @@ func (cfg *IptablesConfigurator) handleOutboundPortsInclude() {
 	}
 }
 
+// handleOutboundUDPPortsInclude redirects outbound UDP traffic to the configured ports to the proxy UDP port.
+// UDP traffic is not captured by ISTIO_OUTPUT, so it goes through its own chain, with the same exclusions for
+// traffic from the proxy itself and to localhost.
+func (cfg *IptablesConfigurator) handleOutboundUDPPortsInclude() {
+	if cfg.cfg.OutboundUDPPortsInclude == "" || cfg.cfg.ProxyUDPPort == "" {
+		return
+	}
+	cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.OUTPUT, constants.NAT,
+		"-p", constants.UDP, "-j", constants.ISTIOOUTPUTUDP)
+	for _, uid := range split(cfg.cfg.ProxyUID) {
+		cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.ISTIOOUTPUTUDP, constants.NAT,
+			"-m", "owner", "--uid-owner", uid, "-j", constants.RETURN)
+	}
+	for _, gid := range split(cfg.cfg.ProxyGID) {
+		cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.ISTIOOUTPUTUDP, constants.NAT,
+			"-m", "owner", "--gid-owner", gid, "-j", constants.RETURN)
+	}
+	cfg.iptables.AppendVersionedRule("127.0.0.1/32", "::1/128", iptableslog.UndefinedCommand, constants.ISTIOOUTPUTUDP, constants.NAT,
+		"-d", constants.IPVersionSpecific, "-j", constants.RETURN)
+	for _, port := range split(cfg.cfg.OutboundUDPPortsInclude) {
+		cfg.iptables.AppendRule(iptableslog.UndefinedCommand, constants.ISTIOOUTPUTUDP, constants.NAT,
+			"-p", constants.UDP, "--dport", port, "-j", constants.REDIRECT, "--to-ports", cfg.cfg.ProxyUDPPort)
+	}
+}
+
 func (cfg *IptablesConfigurator) handleCaptureByOwnerGroup(filter config.InterceptFilter) {
 	if filter.Except {
 		for _, group := range filter.Values {
@@ -727,6 +837,29 @@ func (cfg *IptablesConfigurator) handleCaptureByOwnerGroup(filter config.Interce
 	}
 }
 
+// handleRuleTemplates applies the rules of the rule templates, after the rules of Istio.
+func (cfg *IptablesConfigurator) handleRuleTemplates() error {
+	rules, err := cfg.cfg.RenderRuleTemplates()
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		switch {
+		case r.Insert && r.IPv4:
+			cfg.iptables.InsertRuleV4(iptableslog.UndefinedCommand, r.Chain, r.Table, r.Position, r.Params...)
+		case r.IPv4:
+			cfg.iptables.AppendRuleV4(iptableslog.UndefinedCommand, r.Chain, r.Table, r.Params...)
+		}
+		switch {
+		case r.Insert && r.IPv6:
+			cfg.iptables.InsertRuleV6(iptableslog.UndefinedCommand, r.Chain, r.Table, r.Position, r.Params...)
+		case r.IPv6:
+			cfg.iptables.AppendRuleV6(iptableslog.UndefinedCommand, r.Chain, r.Table, r.Params...)
+		}
+	}
+	return nil
+}
+
 func (cfg *IptablesConfigurator) executeIptablesCommands(commands [][]string) error {
 	for _, cmd := range commands {
 		if len(cmd) > 1 {
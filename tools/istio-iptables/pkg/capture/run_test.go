@@ -66,6 +66,95 @@ func TestIptables(t *testing.T) {
 				cfg.DNSServersV4 = []string{"127.0.0.53"}
 			},
 		},
+		{
+			"outbound-capture-percent",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.OutboundPortsExclude = "32000,31000"
+				cfg.OutboundCapturePercent = "25"
+			},
+		},
+		{
+			"outbound-capture-percent-zero",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.OutboundCapturePercent = "0"
+			},
+		},
+		{
+			"outbound-udp-ports",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.OutboundUDPPortsInclude = "5060,9000"
+				cfg.ProxyUDPPort = "15009"
+				cfg.ProxyGID = "1,2"
+				cfg.ProxyUID = "3,4"
+			},
+		},
+		{
+			"hostport-hairpin",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.InboundPortsExclude = "4000"
+				cfg.HostPortHairpinPorts = "8080,8443"
+				cfg.OutboundIPRangesInclude = "*"
+			},
+		},
+		{
+			"platform-quirks",
+			func(cfg *config.Config) {
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.OutboundIPRangesExclude = "169.254.169.254/32"
+				cfg.OutboundPortsExclude = "9000"
+				cfg.PlatformQuirks = "gke-metadata-server,containerd-stream-server"
+			},
+		},
+		{
+			"capture-preset-outbound-only",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.CapturePreset = "outbound-only"
+			},
+		},
+		{
+			"capture-preset-inbound-only",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.RedirectDNS = true
+				cfg.CapturePreset = "inbound-only"
+			},
+		},
+		{
+			"capture-preset-dns-only",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.CapturePreset = "dns-only"
+			},
+		},
+		{
+			"kubelet-probes",
+			func(cfg *config.Config) {
+				cfg.InboundPortsInclude = "*"
+				cfg.ExemptKubeletProbes = true
+				cfg.OutboundIPRangesInclude = "*"
+				cfg.EnableInboundIPv6 = true
+			},
+		},
+		{
+			"hostport-hairpin-tproxy",
+			func(cfg *config.Config) {
+				cfg.InboundInterceptionMode = constants.TPROXY
+				cfg.InboundPortsInclude = "8080"
+				cfg.HostPortHairpinPorts = "*"
+				cfg.OutboundIPRangesInclude = "*"
+			},
+		},
 		{
 			"tproxy",
 			func(cfg *config.Config) {
@@ -263,6 +352,22 @@ func TestIptables(t *testing.T) {
 				cfg.DropInvalid = true
 			},
 		},
+		{
+			"rule-templates",
+			func(cfg *config.Config) {
+				cfg.EnableInboundIPv6 = true
+				cfg.OutboundPortsExclude = "25,587"
+				cfg.RuleTemplatesDir = filepath.Join("testdata", "rule-templates")
+			},
+		},
+		{
+			"chain-position-head",
+			func(cfg *config.Config) {
+				cfg.ExcludeInterfaces = "not-istio-nic"
+				cfg.InboundPortsInclude = "*"
+				cfg.ChainPosition = config.ChainPositionHead
+			},
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -279,6 +384,52 @@ func TestIptables(t *testing.T) {
 	}
 }
 
+func TestRunResult(t *testing.T) {
+	for _, ipv6 := range []bool{false, true} {
+		cfg := constructTestConfig()
+		cfg.EnableInboundIPv6 = ipv6
+		iptConfigurator := NewIptablesConfigurator(cfg, &dep.StdoutStubDependencies{})
+		if err := iptConfigurator.Run(); err != nil {
+			t.Fatal(err)
+		}
+		result := iptConfigurator.Result()
+		v4, v6 := iptConfigurator.iptables.RuleCounts()
+		if result.RulesV4 != v4 || result.RulesV6 != v6 || result.Rules() != v4+v6 || v4 == 0 {
+			t.Errorf("ipv6 %t: got %d IPv4 and %d IPv6 rules, want %d and %d", ipv6, result.RulesV4, result.RulesV6, v4, v6)
+		}
+		wantFamilies := []string{FamilyIPv4}
+		if ipv6 {
+			wantFamilies = append(wantFamilies, FamilyIPv6)
+		}
+		if !reflect.DeepEqual(result.Families, wantFamilies) {
+			t.Errorf("ipv6 %t: got families %v, want %v", ipv6, result.Families, wantFamilies)
+		}
+		if result.Duration <= 0 {
+			t.Errorf("ipv6 %t: expected the duration of the run", ipv6)
+		}
+	}
+}
+
+func TestOutboundCapturePercentUnset(t *testing.T) {
+	// A config built without DefaultConfig leaves the percentage unset.
+	for _, cfg := range []*config.Config{{}, config.DefaultConfig(), constructTestConfig()} {
+		cfg.OutboundIPRangesInclude = "*"
+		iptConfigurator := NewIptablesConfigurator(cfg, &dep.StdoutStubDependencies{})
+		if err := iptConfigurator.Run(); err != nil {
+			t.Fatal(err)
+		}
+		rules := FormatIptablesCommands(append(iptConfigurator.iptables.BuildV4(), iptConfigurator.iptables.BuildV6()...))
+		if len(rules) == 0 {
+			t.Fatal("expected rules capturing the outbound traffic")
+		}
+		for _, rule := range rules {
+			if strings.Contains(rule, "statistic") {
+				t.Fatalf("expected every outbound connection to be captured, got %q", rule)
+			}
+		}
+	}
+}
+
 func TestSeparateV4V6(t *testing.T) {
 	mkIPList := func(ips ...string) []netip.Prefix {
 		ret := []netip.Prefix{}
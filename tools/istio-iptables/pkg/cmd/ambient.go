@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
+	"istio.io/istio/tools/istio-iptables/pkg/hostrules"
+)
+
+func ambientCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ambient",
+		Short: "Manage the host-level rules of ambient mode",
+	}
+	cmd.AddCommand(ambientVerifyCommand())
+	return cmd
+}
+
+func ambientVerifyCommand() *cobra.Command {
+	var (
+		statePath string
+		heal      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the host-level rules of ambient mode against the desired state of the node agent",
+		Long: `Verify the ztunnel chains of the host and the ipset of the pods enrolled in ambient mode against the
+desired state written by the node agent of the Istio CNI plugin, printing every discrepancy.
+
+With --heal, the chains with discrepancies are rewritten from the desired state in a single iptables-restore,
+and the ipset is reconciled with the enrolled pods. It must run in the network namespace of the host, such as
+in the container of the node agent.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := hostrules.ReadState(statePath)
+			if err != nil {
+				return err
+			}
+			ipv, err := dep.DetectIptablesVersion("")
+			if err != nil {
+				return err
+			}
+			deps := &dep.RealDependencies{IptablesVersion: ipv, Context: context.Background()}
+			v := hostrules.NewVerifier(state, deps, hostrules.NetlinkIPSet{Name: state.IPSet})
+
+			verify, verb := v.Verify, "Found"
+			if heal {
+				verify, verb = v.Heal, "Healed"
+			}
+			discrepancies, err := verify()
+			if err != nil {
+				return err
+			}
+			for _, d := range discrepancies {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %v\n", verb, d)
+			}
+			if len(discrepancies) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "The host-level ambient rules match the desired state of the node agent")
+				return nil
+			}
+			if !heal {
+				return fmt.Errorf("found %d discrepancies with the desired state of the node agent, run with --heal to repair them",
+					len(discrepancies))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&statePath, "state", hostrules.DefaultStatePath, "Path of the desired state written by the node agent.")
+	cmd.Flags().BoolVar(&heal, "heal", false, "Repair the discrepancies with the desired state.")
+	return cmd
+}
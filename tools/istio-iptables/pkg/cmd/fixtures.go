@@ -0,0 +1,50 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/tools/istio-iptables/pkg/capture"
+)
+
+func generateFixturesCommand() *cobra.Command {
+	var outputDir string
+	cmd := &cobra.Command{
+		Use:   "generate-fixtures",
+		Short: "Render the rule plans of a matrix of configurations into golden files",
+		Long: `Render the rules istio-iptables would program for a matrix of configurations, every inbound interception
+mode with and without port, address and interface exclusions, for IPv4 only and dual-stack, into a golden file
+per configuration of the output directory. No rules are applied.
+
+The names of the files are stable across Istio versions, so that the fixtures generated by two versions can be
+diffed before upgrading the node agents, such as with "diff -r old/ new/".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths, err := capture.WriteFixtures(outputDir, capture.FixtureBaseConfig(), capture.Fixtures())
+			if err != nil {
+				return err
+			}
+			for _, path := range paths {
+				fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", path)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "fixtures", "Directory the golden files are written to.")
+	return cmd
+}
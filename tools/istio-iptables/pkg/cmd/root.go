@@ -15,13 +15,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"reflect"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/codes"
 
 	"istio.io/istio/pkg/flag"
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/tracing"
 	"istio.io/istio/tools/istio-iptables/pkg/capture"
 	"istio.io/istio/tools/istio-iptables/pkg/config"
 	"istio.io/istio/tools/istio-iptables/pkg/constants"
@@ -31,12 +38,30 @@ import (
 
 const InvalidDropByIptables = "INVALID_DROP"
 
+// ProxyUDPPortMetadata is the proxy metadata a proxy with a UDP listener advertises the port of the listener in.
+// Outbound UDP traffic is only captured for such proxies, as it would be dropped by the others.
+const ProxyUDPPortMetadata = "ISTIO_META_PROXY_UDP_PORT"
+
+// additionalEnvs are the environment variables flags are also set from, by flag name.
+var additionalEnvs = map[string]string{
+	// Allow binding to a different var, for consistency with other components
+	constants.RedirectDNS: "ISTIO_META_DNS_CAPTURE",
+	// This could have just used the default but for backwards compat we support the old env.
+	constants.DropInvalid: InvalidDropByIptables,
+	// Allow binding to a different var, for consistency with other components
+	constants.DualStack: "ISTIO_DUAL_STACK",
+	// The injector sets the proxy metadata in the environment of istio-init
+	constants.ProxyUDPPort: ProxyUDPPortMetadata,
+}
+
 func handleErrorWithCode(err error, code int) {
 	log.Error(err)
 	os.Exit(code)
 }
 
-func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
+// bindCmdlineFlags binds the flags of the config, and returns the values of the flags set from the environment, by
+// flag name.
+func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) map[string]string {
 	fs := cmd.Flags()
 	flag.Bind(fs, constants.EnvoyPort, "p", "Specify the envoy port to which redirect all TCP traffic.", &cfg.ProxyPort)
 
@@ -70,6 +95,42 @@ func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
 			"Only applies when all inbound traffic (i.e. \"*\") is being redirected.",
 		&cfg.InboundPortsExclude)
 
+	flag.BindEnv(fs, constants.HostPortHairpinPorts, "",
+		"Comma separated list of container ports exposed with a hostPort whose hairpin traffic, sent by the pod "+
+			"to its own hostPort and not masqueraded on the node, is not redirected to Envoy again on the inbound path (optional). "+
+			"The wildcard character \"*\" can be used for all ports.",
+		&cfg.HostPortHairpinPorts)
+
+	flag.BindEnv(fs, constants.ExemptKubeletProbes, "",
+		"Whether connections from the kubelet probe source addresses, which the node agent translates the connections of "+
+			"the host to the pods of the node to, are not redirected to Envoy on the inbound path (optional). "+
+			"It lets plain HTTP probes reach the application without being rewritten by the injector.",
+		&cfg.ExemptKubeletProbes)
+
+	flag.BindEnv(fs, constants.ChainPosition, "",
+		"Where the rules of Istio in built-in chains, such as the jumps to its own chains, are placed relative to the rules "+
+			"of other agents on the node: \"append\" (default), \"head\", \"before:<chain>\" to insert them right before the first "+
+			"jump to the chain of another agent, or \"after:<chain>\" right after its last jump. "+
+			"A warning is logged if the rules of another agent end up ahead of those of Istio contrary to this setting.",
+		&cfg.ChainPosition)
+
+	flag.BindEnv(fs, constants.PlatformQuirks, "",
+		"Comma separated list of known platform services whose addresses and ports are excluded from outbound capture (optional), "+
+			"one of: "+strings.Join(config.PlatformQuirkNames(), ", ")+".",
+		&cfg.PlatformQuirks)
+
+	flag.BindEnv(fs, constants.CapturePreset, "",
+		"Named partial capture of the traffic of the pod, overriding the settings capturing the traffic it leaves untouched "+
+			"(optional), one of: "+strings.Join(config.CapturePresetNames(), ", ")+". \"outbound-only\" captures the outbound "+
+			"traffic and DNS queries, \"inbound-only\" the inbound traffic and \"dns-only\" the DNS queries.",
+		&cfg.CapturePreset)
+
+	flag.BindEnv(fs, constants.RuleTemplatesDir, "",
+		"Directory of templates of additional rules, such as a mounted ConfigMap, rendered with the configuration and applied "+
+			"after the rules of Istio, in the order of their file names (optional). Each line renders a rule in iptables "+
+			"syntax, such as \"-t nat -A OUTPUT -p tcp --dport 443 -j REDIRECT --to-ports {{ .ProxyPort }}\".",
+		&cfg.RuleTemplatesDir)
+
 	flag.BindEnv(fs, constants.ExcludeInterfaces, "c",
 		"Comma separated list of NIC (optional). Neither inbound nor outbound traffic will be captured.",
 		&cfg.ExcludeInterfaces)
@@ -92,6 +153,22 @@ func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
 		"Comma separated list of outbound ports to be excluded from redirection to Envoy.",
 		&cfg.OutboundPortsExclude)
 
+	flag.BindEnv(fs, constants.OutboundCapturePercent, "",
+		"Experimental: percentage of new outbound connections to redirect to Envoy, from 0 to 100. "+
+			"Connections that are not selected bypass Envoy. Intended for gradual migration of a workload into the mesh. "+
+			"Unset redirects every connection, and 0 none.",
+		&cfg.OutboundCapturePercent)
+
+	flag.BindEnv(fs, constants.OutboundUDPPorts, "",
+		"Comma separated list of outbound UDP ports for which traffic is to be redirected to the proxy UDP port. "+
+			"Requires a proxy UDP port. DNS traffic is captured separately with DNS redirection.",
+		&cfg.OutboundUDPPortsInclude)
+
+	flag.BindEnv(fs, constants.ProxyUDPPort, "",
+		"Port on which the proxy accepts redirected outbound UDP traffic. Set from the "+ProxyUDPPortMetadata+
+			" metadata of proxies with a UDP listener, which capturing outbound UDP ports requires.",
+		&cfg.ProxyUDPPort)
+
 	flag.BindEnv(fs, constants.KubeVirtInterfaces, "k",
 		"Comma separated list of virtual interfaces whose inbound traffic (from VM) will be treated as outbound.",
 		&cfg.KubeVirtInterfaces)
@@ -117,16 +194,14 @@ func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
 	flag.BindEnv(fs, constants.RunValidation, "", "Validate iptables.", &cfg.RunValidation)
 
 	flag.BindEnv(fs, constants.RedirectDNS, "", "Enable capture of dns traffic by istio-agent.", &cfg.RedirectDNS)
-	// Allow binding to a different var, for consistency with other components
-	flag.AdditionalEnv(fs, constants.RedirectDNS, "ISTIO_META_DNS_CAPTURE")
 
 	flag.BindEnv(fs, constants.DropInvalid, "", "Enable invalid drop in the iptables rules.", &cfg.DropInvalid)
-	// This could have just used the default but for backwards compat we support the old env.
-	flag.AdditionalEnv(fs, constants.DropInvalid, InvalidDropByIptables)
 
 	flag.BindEnv(fs, constants.DualStack, "", "Enable ipv4/ipv6 redirects for dual-stack.", &cfg.DualStack)
-	// Allow binding to a different var, for consistency with other components
-	flag.AdditionalEnv(fs, constants.DualStack, "ISTIO_DUAL_STACK")
+
+	for name, env := range additionalEnvs {
+		flag.AdditionalEnv(fs, name, env)
+	}
 
 	flag.BindEnv(fs, constants.CaptureAllDNS, "",
 		"Instead of only capturing DNS traffic to DNS server IP, capture all DNS traffic at port 53. This setting is only effective when redirect dns is enabled.",
@@ -137,21 +212,102 @@ func bindCmdlineFlags(cfg *config.Config, cmd *cobra.Command) {
 
 	flag.BindEnv(fs, constants.CNIMode, "", "Whether to run as CNI plugin.", &cfg.CNIMode)
 
+	flag.BindEnv(fs, constants.NsenterTarget, "",
+		"Run the iptables commands with nsenter in the namespaces of a target, given as a PID, whose network and mount namespaces "+
+			"are entered, or as the path of a network namespace.",
+		&cfg.NsenterTarget)
+
+	flag.BindEnv(fs, constants.NsenterMountNamespace, "",
+		"Path of the mount namespace to enter along with the network namespace given to --nsenter-target.",
+		&cfg.NsenterMountNamespace)
+
 	flag.BindEnv(fs, constants.IptablesVersion, "", "version of iptables command. If not set, this is automatically detected.", &cfg.IPTablesVersion)
+
+	// Before the command line is parsed, the flags which are set were set from the environment.
+	envValues := map[string]string{}
+	fs.Visit(func(f *pflag.Flag) {
+		envValues[f.Name] = f.Value.String()
+	})
+	return envValues
+}
+
+// recordSources records the flags and environment variables which set the values of the config. A flag set from
+// the environment whose value the command line did not change is attributed to the environment variable.
+func recordSources(cfg *config.Config, fs *pflag.FlagSet, envValues map[string]string) {
+	keys := configKeys(cfg)
+	fs.Visit(func(f *pflag.Flag) {
+		key, ok := keys[reflect.ValueOf(f.Value).Pointer()]
+		if !ok {
+			return
+		}
+		if v, fromEnv := envValues[f.Name]; fromEnv && v == f.Value.String() {
+			cfg.SetSource(key, config.SourceEnv, flagEnv(f.Name))
+			return
+		}
+		cfg.SetSource(key, config.SourceFlag, "--"+f.Name)
+	})
+}
+
+// configKeys returns the keys of the values of the config by the address of their field, which is that of the value
+// of the flag bound to the field.
+func configKeys(cfg *config.Config) map[uintptr]string {
+	v := reflect.ValueOf(cfg).Elem()
+	keys := make(map[uintptr]string, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		keys[v.Field(i).Addr().Pointer()] = key
+	}
+	return keys
+}
+
+// flagEnv returns the environment variable which set the flag.
+func flagEnv(name string) string {
+	if env, f := additionalEnvs[name]; f {
+		if _, set := os.LookupEnv(env); set {
+			return env
+		}
+	}
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// explainConfig prints the value of the config the query names and where it was set, once the capture preset and
+// the platform quirks are applied.
+func explainConfig(w io.Writer, cfg *config.Config, query string) error {
+	if err := cfg.ApplyCapturePreset(); err != nil {
+		return err
+	}
+	if err := cfg.ApplyPlatformQuirks(); err != nil {
+		return err
+	}
+	explanation, err := cfg.Explain(query)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, explanation)
+	return err
 }
 
 func GetCommand() *cobra.Command {
 	cfg := config.DefaultConfig()
+	var envValues map[string]string
+	var explain string
 	cmd := &cobra.Command{
 		Use:   "istio-iptables",
 		Short: "Set up iptables rules for Istio Sidecar",
 		Long:  "istio-iptables is responsible for setting up port forwarding for Istio Sidecar.",
 		Run: func(cmd *cobra.Command, args []string) {
 			cfg.FillConfigFromEnvironment()
+			recordSources(cfg, cmd.Flags(), envValues)
+			if explain != "" {
+				if err := explainConfig(cmd.OutOrStdout(), cfg, explain); err != nil {
+					handleErrorWithCode(err, 1)
+				}
+				return
+			}
 			if err := cfg.Validate(); err != nil {
 				handleErrorWithCode(err, 1)
 			}
-			if err := ProgramIptables(cfg); err != nil {
+			if _, err := ProgramIptables(context.Background(), cfg); err != nil {
 				handleErrorWithCode(err, 1)
 			}
 
@@ -171,7 +327,12 @@ Otherwise, this pod will need to be manually removed so that it is scheduled on
 			}
 		},
 	}
-	bindCmdlineFlags(cfg, cmd)
+	envValues = bindCmdlineFlags(cfg, cmd)
+	cmd.Flags().StringVar(&explain, "explain", "",
+		"Print the value of the config named, such as \"excludeInboundPorts\" or \"INBOUND_PORTS_EXCLUDE\", and the flag, "+
+			"environment variable, capture preset or platform quirks it was set by, without applying any rule.")
+	cmd.AddCommand(ambientCommand())
+	cmd.AddCommand(generateFixturesCommand())
 	return cmd
 }
 
@@ -180,19 +341,35 @@ type IptablesError struct {
 	ExitCode int
 }
 
-func ProgramIptables(cfg *config.Config) error {
+// ProgramIptables programs the rules of the config and returns what was programmed, which is empty if the rules
+// are not applied. The commands run are traced as children of the context.
+func ProgramIptables(ctx context.Context, cfg *config.Config) (result *capture.Result, err error) {
+	ctx, span := tracing.Start(ctx, "ProgramIptables")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 	var ext dep.Dependencies
 	if cfg.DryRun {
 		ext = &dep.StdoutStubDependencies{}
 	} else {
 		ipv, err := dep.DetectIptablesVersion(cfg.IPTablesVersion)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		nsenter, err := dep.ParseNsenterTarget(cfg.NsenterTarget, cfg.NsenterMountNamespace)
+		if err != nil {
+			return nil, err
 		}
 		ext = &dep.RealDependencies{
 			CNIMode:          cfg.CNIMode,
 			NetworkNamespace: cfg.NetworkNamespace,
+			Nsenter:          nsenter,
 			IptablesVersion:  ipv,
+			Context:          ctx,
 		}
 	}
 
@@ -200,11 +377,11 @@ func ProgramIptables(cfg *config.Config) error {
 
 	if !cfg.SkipRuleApply {
 		if err := iptConfigurator.Run(); err != nil {
-			return err
+			return iptConfigurator.Result(), err
 		}
 		if err := capture.ConfigureRoutes(cfg); err != nil {
-			return fmt.Errorf("failed to configure routes: %v", err)
+			return iptConfigurator.Result(), fmt.Errorf("failed to configure routes: %v", err)
 		}
 	}
-	return nil
+	return iptConfigurator.Result(), nil
 }
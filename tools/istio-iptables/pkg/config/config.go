@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pkg/log"
 	netutil "istio.io/istio/pkg/util/net"
 	"istio.io/istio/tools/istio-iptables/pkg/constants"
+	dep "istio.io/istio/tools/istio-iptables/pkg/dependencies"
 )
 
 func DefaultConfig() *Config {
@@ -60,12 +61,21 @@ type Config struct {
 	InboundTProxyRouteTable string        `json:"INBOUND_TPROXY_ROUTE_TABLE"`
 	InboundPortsInclude     string        `json:"INBOUND_PORTS_INCLUDE"`
 	InboundPortsExclude     string        `json:"INBOUND_PORTS_EXCLUDE"`
+	HostPortHairpinPorts    string        `json:"HOSTPORT_HAIRPIN_PORTS"`
+	ExemptKubeletProbes     bool          `json:"EXEMPT_KUBELET_PROBES"`
+	ChainPosition           string        `json:"CHAIN_POSITION"`
+	PlatformQuirks          string        `json:"PLATFORM_QUIRKS"`
+	CapturePreset           string        `json:"CAPTURE_PRESET"`
+	RuleTemplatesDir        string        `json:"RULE_TEMPLATES_DIR"`
 	OwnerGroupsInclude      string        `json:"OUTBOUND_OWNER_GROUPS_INCLUDE"`
 	OwnerGroupsExclude      string        `json:"OUTBOUND_OWNER_GROUPS_EXCLUDE"`
 	OutboundPortsInclude    string        `json:"OUTBOUND_PORTS_INCLUDE"`
 	OutboundPortsExclude    string        `json:"OUTBOUND_PORTS_EXCLUDE"`
 	OutboundIPRangesInclude string        `json:"OUTBOUND_IPRANGES_INCLUDE"`
 	OutboundIPRangesExclude string        `json:"OUTBOUND_IPRANGES_EXCLUDE"`
+	OutboundCapturePercent  string        `json:"OUTBOUND_CAPTURE_PERCENT"` // empty is unset and captures everything
+	OutboundUDPPortsInclude string        `json:"OUTBOUND_UDP_PORTS_INCLUDE"`
+	ProxyUDPPort            string        `json:"PROXY_UDP_PORT"`
 	KubeVirtInterfaces      string        `json:"KUBE_VIRT_INTERFACES"`
 	ExcludeInterfaces       string        `json:"EXCLUDE_INTERFACES"`
 	IptablesProbePort       uint16        `json:"IPTABLES_PROBE_PORT"`
@@ -82,10 +92,14 @@ type Config struct {
 	DNSServersV6            []string      `json:"DNS_SERVERS_V6"`
 	NetworkNamespace        string        `json:"NETWORK_NAMESPACE"`
 	CNIMode                 bool          `json:"CNI_MODE"`
+	NsenterTarget           string        `json:"NSENTER_TARGET"`
+	NsenterMountNamespace   string        `json:"NSENTER_MOUNT_NAMESPACE"`
 	IPTablesVersion         string        `json:"IPTABLES_VERSION"`
 	TraceLogging            bool          `json:"IPTABLES_TRACE_LOGGING"`
 	DualStack               bool          `json:"DUAL_STACK"`
 	HostIP                  netip.Addr    `json:"HOST_IP"`
+	// Sources record where the values of the config not set by default were set, by key.
+	Sources map[string]ValueSource `json:"SOURCES,omitempty"`
 }
 
 func (c *Config) String() string {
@@ -109,12 +123,17 @@ func (c *Config) Print() {
 	b.WriteString(fmt.Sprintf("INBOUND_TPROXY_ROUTE_TABLE=%s\n", c.InboundTProxyRouteTable))
 	b.WriteString(fmt.Sprintf("INBOUND_PORTS_INCLUDE=%s\n", c.InboundPortsInclude))
 	b.WriteString(fmt.Sprintf("INBOUND_PORTS_EXCLUDE=%s\n", c.InboundPortsExclude))
+	b.WriteString(fmt.Sprintf("HOSTPORT_HAIRPIN_PORTS=%s\n", c.HostPortHairpinPorts))
+	b.WriteString(fmt.Sprintf("EXEMPT_KUBELET_PROBES=%t\n", c.ExemptKubeletProbes))
 	b.WriteString(fmt.Sprintf("OUTBOUND_OWNER_GROUPS_INCLUDE=%s\n", c.OwnerGroupsInclude))
 	b.WriteString(fmt.Sprintf("OUTBOUND_OWNER_GROUPS_EXCLUDE=%s\n", c.OwnerGroupsExclude))
 	b.WriteString(fmt.Sprintf("OUTBOUND_IP_RANGES_INCLUDE=%s\n", c.OutboundIPRangesInclude))
 	b.WriteString(fmt.Sprintf("OUTBOUND_IP_RANGES_EXCLUDE=%s\n", c.OutboundIPRangesExclude))
 	b.WriteString(fmt.Sprintf("OUTBOUND_PORTS_INCLUDE=%s\n", c.OutboundPortsInclude))
 	b.WriteString(fmt.Sprintf("OUTBOUND_PORTS_EXCLUDE=%s\n", c.OutboundPortsExclude))
+	b.WriteString(fmt.Sprintf("OUTBOUND_CAPTURE_PERCENT=%s\n", c.OutboundCapturePercent))
+	b.WriteString(fmt.Sprintf("OUTBOUND_UDP_PORTS_INCLUDE=%s\n", c.OutboundUDPPortsInclude))
+	b.WriteString(fmt.Sprintf("PROXY_UDP_PORT=%s\n", c.ProxyUDPPort))
 	b.WriteString(fmt.Sprintf("KUBE_VIRT_INTERFACES=%s\n", c.KubeVirtInterfaces))
 	b.WriteString(fmt.Sprintf("ENABLE_INBOUND_IPV6=%t\n", c.EnableInboundIPv6))
 	b.WriteString(fmt.Sprintf("DUAL_STACK=%t\n", c.DualStack))
@@ -124,12 +143,45 @@ func (c *Config) Print() {
 	b.WriteString(fmt.Sprintf("DNS_SERVERS=%s,%s\n", c.DNSServersV4, c.DNSServersV6))
 	b.WriteString(fmt.Sprintf("NETWORK_NAMESPACE=%s\n", c.NetworkNamespace))
 	b.WriteString(fmt.Sprintf("CNI_MODE=%s\n", strconv.FormatBool(c.CNIMode)))
+	b.WriteString(fmt.Sprintf("NSENTER_TARGET=%s\n", c.NsenterTarget))
+	b.WriteString(fmt.Sprintf("NSENTER_MOUNT_NAMESPACE=%s\n", c.NsenterMountNamespace))
 	b.WriteString(fmt.Sprintf("EXCLUDE_INTERFACES=%s\n", c.ExcludeInterfaces))
+	b.WriteString(fmt.Sprintf("CHAIN_POSITION=%s\n", c.ChainPosition))
+	b.WriteString(fmt.Sprintf("PLATFORM_QUIRKS=%s\n", c.PlatformQuirks))
+	b.WriteString(fmt.Sprintf("CAPTURE_PRESET=%s\n", c.CapturePreset))
+	b.WriteString(fmt.Sprintf("RULE_TEMPLATES_DIR=%s\n", c.RuleTemplatesDir))
 	log.Infof("Istio iptables variables:\n%s", b.String())
+	if sources := c.printSources(); sources != "" {
+		log.Infof("Istio iptables variable sources:\n%s", sources)
+	}
 }
 
 func (c *Config) Validate() error {
-	return ValidateOwnerGroups(c.OwnerGroupsInclude, c.OwnerGroupsExclude)
+	if err := ValidateOwnerGroups(c.OwnerGroupsInclude, c.OwnerGroupsExclude); err != nil {
+		return err
+	}
+	if err := ValidateOutboundCapturePercent(c.OutboundCapturePercent); err != nil {
+		return err
+	}
+	if err := ValidateHostPortHairpinPorts(c.HostPortHairpinPorts); err != nil {
+		return err
+	}
+	if err := ValidateChainPosition(c.ChainPosition); err != nil {
+		return err
+	}
+	if _, err := ParsePlatformQuirks(c.PlatformQuirks); err != nil {
+		return err
+	}
+	if err := ValidateCapturePreset(c.CapturePreset); err != nil {
+		return err
+	}
+	if _, err := dep.ParseNsenterTarget(c.NsenterTarget, c.NsenterMountNamespace); err != nil {
+		return err
+	}
+	if _, err := c.RenderRuleTemplates(); err != nil {
+		return err
+	}
+	return ValidateOutboundUDPPorts(c.OutboundUDPPortsInclude, c.ProxyUDPPort, c.RedirectDNS)
 }
 
 var envoyUserVar = env.Register(constants.EnvoyUser, "istio-proxy", "Envoy proxy username")
@@ -138,6 +190,12 @@ func (c *Config) FillConfigFromEnvironment() {
 	// Fill in env-var only options
 	c.OwnerGroupsInclude = constants.OwnerGroupsInclude.Get()
 	c.OwnerGroupsExclude = constants.OwnerGroupsExclude.Get()
+	if _, f := constants.OwnerGroupsInclude.Lookup(); f {
+		c.SetSource(KeyOwnerGroupsInclude, SourceEnv, constants.OwnerGroupsInclude.Name)
+	}
+	if _, f := constants.OwnerGroupsExclude.Lookup(); f {
+		c.SetSource(KeyOwnerGroupsExclude, SourceEnv, constants.OwnerGroupsExclude.Name)
+	}
 
 	// TODO: Make this more configurable, maybe with an allowlist of users to be captured for output instead of a denylist.
 	if c.ProxyUID == "" {
@@ -0,0 +1,117 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/istio/pkg/log"
+)
+
+// CapturePreset is a common partial capture of the traffic of a pod, so that users select it by name rather than
+// combining the inbound, outbound and DNS settings which disable the capture of the rest of the traffic.
+type CapturePreset struct {
+	Description string
+	// Inbound, Outbound and DNS tell whether the inbound traffic, the outbound TCP traffic and the DNS queries are
+	// captured. The traffic which is not captured overrides the settings capturing it, while the traffic which is
+	// captured is captured as configured.
+	Inbound  bool
+	Outbound bool
+	DNS      bool
+}
+
+// CapturePresets are the known capture presets, by name.
+var CapturePresets = map[string]CapturePreset{
+	"outbound-only": {
+		Description: "capture of the outbound traffic only, leaving the inbound traffic to reach the application directly",
+		Outbound:    true,
+		DNS:         true,
+	},
+	"inbound-only": {
+		Description: "capture of the inbound traffic only, leaving the outbound traffic and DNS queries of the pod untouched",
+		Inbound:     true,
+	},
+	"dns-only": {
+		Description: "capture of the DNS queries only, answered by the istio-agent, leaving all other traffic untouched",
+		DNS:         true,
+	},
+}
+
+// CapturePresetNames returns the sorted names of the known capture presets.
+func CapturePresetNames() []string {
+	names := make([]string, 0, len(CapturePresets))
+	for name := range CapturePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateCapturePreset validates the name of a capture preset, empty if none is selected.
+func ValidateCapturePreset(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := CapturePresets[name]; !ok {
+		return fmt.Errorf("unknown capture preset %q, expected one of %s", name, strings.Join(CapturePresetNames(), ", "))
+	}
+	return nil
+}
+
+// ApplyCapturePreset disables the capture of the traffic the selected capture preset does not capture, and enables
+// the capture of DNS queries for the dns-only preset.
+func (c *Config) ApplyCapturePreset() error {
+	if err := ValidateCapturePreset(c.CapturePreset); err != nil {
+		return err
+	}
+	if c.CapturePreset == "" {
+		return nil
+	}
+	p := CapturePresets[c.CapturePreset]
+	log.Infof("Applying capture preset %s: %s", c.CapturePreset, p.Description)
+	if !p.Inbound {
+		c.overrideString(KeyInboundPortsInclude, &c.InboundPortsInclude, "")
+	}
+	if !p.Outbound {
+		c.overrideString(KeyOutboundIPRangesInclude, &c.OutboundIPRangesInclude, "")
+		c.overrideString(KeyOutboundPortsInclude, &c.OutboundPortsInclude, "")
+		c.overrideString(KeyOutboundUDPPortsInclude, &c.OutboundUDPPortsInclude, "")
+	}
+	if !p.DNS {
+		c.overrideBool(KeyRedirectDNS, &c.RedirectDNS, false)
+		c.overrideBool(KeyCaptureAllDNS, &c.CaptureAllDNS, false)
+	} else if !p.Inbound && !p.Outbound {
+		c.overrideBool(KeyRedirectDNS, &c.RedirectDNS, true)
+	}
+	return nil
+}
+
+// overrideString sets the value of the key for the capture preset, recording the preset as its source if it changed.
+func (c *Config) overrideString(key string, value *string, preset string) {
+	if *value != preset {
+		*value = preset
+		c.SetSource(key, SourcePreset, c.CapturePreset)
+	}
+}
+
+// overrideBool sets the value of the key for the capture preset, recording the preset as its source if it changed.
+func (c *Config) overrideBool(key string, value *bool, preset bool) {
+	if *value != preset {
+		*value = preset
+		c.SetSource(key, SourcePreset, c.CapturePreset)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/util/sets"
+)
+
+// PlatformQuirk is a known platform service which must not be captured, with the addresses and ports it uses,
+// so that users enable it by name rather than discovering and excluding the addresses and ports themselves.
+type PlatformQuirk struct {
+	Description string
+	// OutboundIPRangesExclude are excluded from outbound capture.
+	OutboundIPRangesExclude []string
+	// OutboundPortsExclude are excluded from outbound capture, whatever the destination address.
+	OutboundPortsExclude []string
+}
+
+// PlatformQuirks are the known platform quirks, by name.
+var PlatformQuirks = map[string]PlatformQuirk{
+	"gke-metadata-server": {
+		Description:             "GKE metadata server of Workload Identity, and the metadata proxy of the node it is served by",
+		OutboundIPRangesExclude: []string{"169.254.169.254/32", "169.254.169.252/32"},
+	},
+	"aws-imds": {
+		Description:             "AWS instance metadata service, over IPv4 and IPv6",
+		OutboundIPRangesExclude: []string{"169.254.169.254/32", "fd00:ec2::254/128"},
+	},
+	"azure-imds": {
+		Description:             "Azure instance metadata service and the WireServer of the host",
+		OutboundIPRangesExclude: []string{"169.254.169.254/32", "168.63.129.16/32"},
+	},
+	"containerd-stream-server": {
+		Description: "containerd streaming server on its legacy port, reached by clients following the kubelet " +
+			"redirects of exec, attach and port-forward",
+		OutboundPortsExclude: []string{"10010"},
+	},
+}
+
+// PlatformQuirkNames returns the sorted names of the known platform quirks.
+func PlatformQuirkNames() []string {
+	names := make([]string, 0, len(PlatformQuirks))
+	for name := range PlatformQuirks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParsePlatformQuirks parses a comma separated list of platform quirk names.
+func ParsePlatformQuirks(s string) ([]string, error) {
+	var names []string
+	for _, name := range Split(s) {
+		name = strings.TrimSpace(name)
+		if _, ok := PlatformQuirks[name]; !ok {
+			return nil, fmt.Errorf("unknown platform quirk %q, expected one of %s", name, strings.Join(PlatformQuirkNames(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ApplyPlatformQuirks adds the addresses and ports of the enabled platform quirks to the exclusions of the config.
+func (c *Config) ApplyPlatformQuirks() error {
+	names, err := ParsePlatformQuirks(c.PlatformQuirks)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		q := PlatformQuirks[name]
+		log.Infof("Excluding the %s from capture for platform quirk %s", q.Description, name)
+		var added []string
+		c.OutboundIPRangesExclude, added = appendMissing(c.OutboundIPRangesExclude, q.OutboundIPRangesExclude)
+		c.AddSourceValues(KeyOutboundIPRangesExclude, "platform quirk "+name, added)
+		c.OutboundPortsExclude, added = appendMissing(c.OutboundPortsExclude, q.OutboundPortsExclude)
+		c.AddSourceValues(KeyOutboundPortsExclude, "platform quirk "+name, added)
+	}
+	return nil
+}
+
+// appendMissing appends the values missing from the comma separated list, and returns them.
+func appendMissing(list string, values []string) (string, []string) {
+	existing := Split(list)
+	seen := sets.New(existing...)
+	var added []string
+	for _, v := range values {
+		if !seen.InsertContains(v) {
+			existing = append(existing, v)
+			added = append(added, v)
+		}
+	}
+	return strings.Join(existing, ","), added
+}
@@ -0,0 +1,233 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/api/annotation"
+)
+
+// Source is where a value of the config was set.
+type Source string
+
+const (
+	// SourceDefault is the default value of the config.
+	SourceDefault Source = "default"
+	// SourceFlag is a flag of the command line, such as the flags the sidecar injector renders for istio-init.
+	SourceFlag Source = "flag"
+	// SourceEnv is an environment variable, of istio-iptables or of the proxy of the pod for the CNI plugin.
+	SourceEnv Source = "env"
+	// SourceAnnotation is an annotation of the pod, read by the CNI plugin.
+	SourceAnnotation Source = "annotation"
+	// SourcePreset is the capture preset, which overrides the settings capturing the traffic it leaves untouched.
+	SourcePreset Source = "preset"
+)
+
+// Keys of the values of the config whose source is recorded by the callers, as in the JSON of the config.
+const (
+	KeyInboundInterceptionMode = "INBOUND_INTERCEPTION_MODE"
+	KeyInboundPortsInclude     = "INBOUND_PORTS_INCLUDE"
+	KeyInboundPortsExclude     = "INBOUND_PORTS_EXCLUDE"
+	KeyOutboundPortsInclude    = "OUTBOUND_PORTS_INCLUDE"
+	KeyOutboundPortsExclude    = "OUTBOUND_PORTS_EXCLUDE"
+	KeyOutboundIPRangesInclude = "OUTBOUND_IPRANGES_INCLUDE"
+	KeyOutboundIPRangesExclude = "OUTBOUND_IPRANGES_EXCLUDE"
+	KeyOutboundUDPPortsInclude = "OUTBOUND_UDP_PORTS_INCLUDE"
+	KeyProxyUDPPort            = "PROXY_UDP_PORT"
+	KeyOutboundCapturePercent  = "OUTBOUND_CAPTURE_PERCENT"
+	KeyOwnerGroupsInclude      = "OUTBOUND_OWNER_GROUPS_INCLUDE"
+	KeyOwnerGroupsExclude      = "OUTBOUND_OWNER_GROUPS_EXCLUDE"
+	KeyExcludeInterfaces       = "EXCLUDE_INTERFACES"
+	KeyKubeVirtInterfaces      = "KUBE_VIRT_INTERFACES"
+	KeyCapturePreset           = "CAPTURE_PRESET"
+	KeyRedirectDNS             = "REDIRECT_DNS"
+	KeyCaptureAllDNS           = "CAPTURE_ALL_DNS"
+	KeyDropInvalid             = "DROP_INVALID"
+	KeyDualStack               = "DUAL_STACK"
+)
+
+// keyAnnotations are the annotations of pods the values of the config are set from, by the CNI plugin or by the
+// sidecar injector in the flags of istio-init.
+var keyAnnotations = map[string]string{
+	KeyInboundInterceptionMode: annotation.SidecarInterceptionMode.Name,
+	KeyInboundPortsInclude:     annotation.SidecarTrafficIncludeInboundPorts.Name,
+	KeyInboundPortsExclude:     annotation.SidecarTrafficExcludeInboundPorts.Name,
+	KeyOutboundPortsInclude:    annotation.SidecarTrafficIncludeOutboundPorts.Name,
+	KeyOutboundPortsExclude:    annotation.SidecarTrafficExcludeOutboundPorts.Name,
+	KeyOutboundIPRangesInclude: annotation.SidecarTrafficIncludeOutboundIPRanges.Name,
+	KeyOutboundIPRangesExclude: annotation.SidecarTrafficExcludeOutboundIPRanges.Name,
+	KeyExcludeInterfaces:       annotation.SidecarTrafficExcludeInterfaces.Name,
+	KeyKubeVirtInterfaces:      annotation.SidecarTrafficKubevirtInterfaces.Name,
+	KeyCapturePreset:           "traffic.sidecar.istio.io/capturePreset",
+	KeyOutboundCapturePercent:  "traffic.sidecar.istio.io/outboundCapturePercent",
+	KeyOutboundUDPPortsInclude: "traffic.sidecar.istio.io/includeOutboundUDPPorts",
+}
+
+// ValueSource records where a value of the config was set, and the values other settings added to it.
+type ValueSource struct {
+	Source Source `json:"source"`
+	// Name is the flag, environment variable, annotation or capture preset which set the value.
+	Name string `json:"name,omitempty"`
+	// Additions are the values other settings, such as platform quirks, added to the list.
+	Additions []Addition `json:"additions,omitempty"`
+}
+
+// Addition is values added to a list of the config by another setting.
+type Addition struct {
+	Values []string `json:"values"`
+	By     string   `json:"by"`
+}
+
+func (s ValueSource) String() string {
+	var b strings.Builder
+	b.WriteString(string(s.Source))
+	if s.Name != "" {
+		b.WriteString(" " + s.Name)
+	}
+	for _, a := range s.Additions {
+		fmt.Fprintf(&b, ", plus %s by %s", strings.Join(a.Values, ","), a.By)
+	}
+	return b.String()
+}
+
+// SourceOf returns where the value of the key was set, the default unless a source was recorded.
+func (c *Config) SourceOf(key string) ValueSource {
+	if s, f := c.Sources[key]; f {
+		return s
+	}
+	return ValueSource{Source: SourceDefault}
+}
+
+// SetSource records where the value of the key was set, replacing the values added to it.
+func (c *Config) SetSource(key string, source Source, name string) {
+	c.updateSource(key, ValueSource{Source: source, Name: name})
+}
+
+// AddSourceValues records the values another setting added to the list of the key.
+func (c *Config) AddSourceValues(key, by string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	s := c.SourceOf(key)
+	s.Additions = append(append([]Addition{}, s.Additions...), Addition{Values: values, By: by})
+	c.updateSource(key, s)
+}
+
+// updateSource sets the source of the key in a copy of the sources, which copies of the config share.
+func (c *Config) updateSource(key string, s ValueSource) {
+	sources := make(map[string]ValueSource, len(c.Sources)+1)
+	for k, v := range c.Sources {
+		sources[k] = v
+	}
+	sources[key] = s
+	c.Sources = sources
+}
+
+// Explain describes the value of the config the query names and where it was set. The query is the key of the
+// value, such as INBOUND_PORTS_EXCLUDE, or the name of its annotation, such as excludeInboundPorts.
+func (c *Config) Explain(query string) (string, error) {
+	key, err := c.resolveKey(query)
+	if err != nil {
+		return "", err
+	}
+	values, err := c.values()
+	if err != nil {
+		return "", err
+	}
+	s := c.SourceOf(key)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s=%s\n", key, values[key])
+	switch s.Source {
+	case SourceDefault:
+		b.WriteString("  set by: default\n")
+	case SourcePreset:
+		fmt.Fprintf(&b, "  set by: capture preset %s\n", s.Name)
+	default:
+		fmt.Fprintf(&b, "  set by: %s %s\n", s.Source, s.Name)
+	}
+	for _, a := range s.Additions {
+		fmt.Fprintf(&b, "  added: %s by %s\n", strings.Join(a.Values, ","), a.By)
+	}
+	if a, f := keyAnnotations[key]; f && s.Source == SourceFlag {
+		fmt.Fprintf(&b, "  note: the sidecar injector renders this flag of istio-init from the annotation %s of the pod\n", a)
+	}
+	return b.String(), nil
+}
+
+// resolveKey returns the key of the value of the config the query names, ignoring case, dashes and underscores.
+func (c *Config) resolveKey(query string) (string, error) {
+	normalize := strings.NewReplacer("-", "", "_", "").Replace
+	q := strings.ToLower(normalize(query))
+	values, err := c.values()
+	if err != nil {
+		return "", err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+		if strings.ToLower(normalize(key)) == q {
+			return key, nil
+		}
+	}
+	for key, a := range keyAnnotations {
+		_, name, _ := strings.Cut(a, "/")
+		if strings.EqualFold(query, a) || strings.ToLower(name) == q {
+			return key, nil
+		}
+	}
+	sort.Strings(keys)
+	return "", fmt.Errorf("unknown config value %q, expected an annotation such as excludeInboundPorts or one of %s",
+		query, strings.Join(keys, ", "))
+}
+
+// values returns the values of the config by key, as in its JSON.
+func (c *Config) values() (map[string]string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	delete(raw, "SOURCES")
+	values := make(map[string]string, len(raw))
+	for key, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			s = string(v)
+		}
+		values[key] = s
+	}
+	return values, nil
+}
+
+// printSources returns where the values of the config not set by default were set, one per line.
+func (c *Config) printSources() string {
+	keys := make([]string, 0, len(c.Sources))
+	for key := range c.Sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", key, c.Sources[key])
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestResolveKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cases := []struct {
+		query string
+		key   string
+	}{
+		{query: "INBOUND_PORTS_EXCLUDE", key: KeyInboundPortsExclude},
+		{query: "inbound-ports-exclude", key: KeyInboundPortsExclude},
+		{query: "excludeInboundPorts", key: KeyInboundPortsExclude},
+		{query: "traffic.sidecar.istio.io/excludeOutboundIPRanges", key: KeyOutboundIPRangesExclude},
+		{query: "interceptionMode", key: KeyInboundInterceptionMode},
+		{query: "capturePreset", key: KeyCapturePreset},
+		{query: "redirect-dns", key: KeyRedirectDNS},
+	}
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			key, err := cfg.resolveKey(tc.query)
+			assert.NoError(t, err)
+			assert.Equal(t, key, tc.key)
+		})
+	}
+
+	_, err := cfg.resolveKey("excludeEverything")
+	assert.Error(t, err)
+	_, err = cfg.resolveKey("sources")
+	assert.Error(t, err)
+}
+
+func TestExplain(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.InboundPortsExclude = "8080,15020"
+	cfg.SetSource(KeyInboundPortsExclude, SourceFlag, "--inbound-ports-exclude")
+	cfg.AddSourceValues(KeyInboundPortsExclude, "the CNI plugin", []string{"15021", "15090"})
+	cfg.InboundPortsExclude += ",15021,15090"
+
+	explanation, err := cfg.Explain("excludeInboundPorts")
+	assert.NoError(t, err)
+	assert.Equal(t, explanation, `INBOUND_PORTS_EXCLUDE=8080,15020,15021,15090
+  set by: flag --inbound-ports-exclude
+  added: 15021,15090 by the CNI plugin
+  note: the sidecar injector renders this flag of istio-init from the annotation traffic.sidecar.istio.io/excludeInboundPorts of the pod
+`)
+
+	explanation, err = cfg.Explain("OUTBOUND_PORTS_EXCLUDE")
+	assert.NoError(t, err)
+	assert.Equal(t, explanation, "OUTBOUND_PORTS_EXCLUDE=\n  set by: default\n")
+
+	cfg.SetSource(KeyRedirectDNS, SourceEnv, "ISTIO_META_DNS_CAPTURE")
+	cfg.RedirectDNS = true
+	explanation, err = cfg.Explain("REDIRECT_DNS")
+	assert.NoError(t, err)
+	assert.Equal(t, explanation, "REDIRECT_DNS=true\n  set by: env ISTIO_META_DNS_CAPTURE\n")
+}
+
+func TestPresetAndQuirkSources(t *testing.T) {
+	cfg := &Config{
+		InboundPortsInclude:     "*",
+		OutboundIPRangesInclude: "*",
+		OutboundIPRangesExclude: "169.254.169.254/32",
+		CapturePreset:           "outbound-only",
+		PlatformQuirks:          "aws-imds",
+	}
+	cfg.SetSource(KeyOutboundIPRangesExclude, SourceAnnotation, "traffic.sidecar.istio.io/excludeOutboundIPRanges")
+	assert.NoError(t, cfg.ApplyCapturePreset())
+	assert.NoError(t, cfg.ApplyPlatformQuirks())
+
+	assert.Equal(t, cfg.SourceOf(KeyInboundPortsInclude), ValueSource{Source: SourcePreset, Name: "outbound-only"})
+	// The preset leaves the outbound capture and the DNS capture, disabled, untouched.
+	assert.Equal(t, cfg.SourceOf(KeyOutboundIPRangesInclude), ValueSource{Source: SourceDefault})
+	assert.Equal(t, cfg.SourceOf(KeyOutboundIPRangesExclude), ValueSource{
+		Source:    SourceAnnotation,
+		Name:      "traffic.sidecar.istio.io/excludeOutboundIPRanges",
+		Additions: []Addition{{Values: []string{"fd00:ec2::254/128"}, By: "platform quirk aws-imds"}},
+	})
+	assert.Equal(t, cfg.SourceOf(KeyOutboundPortsExclude), ValueSource{Source: SourceDefault})
+}
+
+func TestSourcesCopy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.SetSource(KeyDualStack, SourceEnv, "ISTIO_DUAL_STACK")
+	cp := *cfg
+	cp.SetSource(KeyDualStack, SourceFlag, "--dual-stack")
+	assert.Equal(t, cfg.SourceOf(KeyDualStack), ValueSource{Source: SourceEnv, Name: "ISTIO_DUAL_STACK"})
+
+	data, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	decoded := &Config{}
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, decoded.Sources, cfg.Sources)
+}
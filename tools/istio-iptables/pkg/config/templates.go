@@ -0,0 +1,192 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+)
+
+// RuleTemplate is a named template of additional rules, rendered with the config and applied after the rules of
+// Istio, for custom interception such as transparent egress gateways on the node.
+//
+// The template renders one rule per line in the syntax of the iptables command, without the command itself:
+//
+//	-t nat -A OUTPUT -p tcp -d 10.10.0.0/16 --dport 443 -j REDIRECT --to-ports {{ .ProxyPort }}
+//
+// Rules append to a chain with -A, or insert with -I at an optional position, in the table given with -t, or
+// filter. Chains which are not built-in are created. Rules apply to IPv4 and, when enabled, IPv6, unless marked
+// with -4 or -6. Empty lines and lines starting with # are ignored. Arguments are separated by spaces and cannot
+// be quoted.
+type RuleTemplate struct {
+	Name string
+	tmpl *template.Template
+}
+
+// TemplateRule is a rule rendered from a RuleTemplate.
+type TemplateRule struct {
+	Table string
+	Chain string
+	// Insert is whether the rule is inserted at Position rather than appended.
+	Insert   bool
+	Position int
+	Params   []string
+	IPv4     bool
+	IPv6     bool
+}
+
+var ruleTemplateFuncs = template.FuncMap{
+	"split": Split,
+	"join":  strings.Join,
+}
+
+var templateTables = sets.New(constants.FILTER, constants.NAT, constants.MANGLE, constants.RAW)
+
+// LoadRuleTemplates loads the rule templates of the files of a directory, sorted by name, which is also the
+// order they are applied in. Hidden files are skipped, so that a mounted ConfigMap can be loaded with one
+// template per key.
+func LoadRuleTemplates(dir string) ([]*RuleTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule templates: %v", err)
+	}
+	var templates []*RuleTemplate
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		// Follow the symbolic links of ConfigMap keys.
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rule template %s: %v", e.Name(), err)
+		}
+		tmpl, err := template.New(e.Name()).Funcs(ruleTemplateFuncs).Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule template %s: %v", e.Name(), err)
+		}
+		templates = append(templates, &RuleTemplate{Name: e.Name(), tmpl: tmpl})
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// Render renders the rules of the template with the config.
+func (t *RuleTemplate) Render(c *Config) ([]TemplateRule, error) {
+	var out bytes.Buffer
+	if err := t.tmpl.Execute(&out, c); err != nil {
+		return nil, fmt.Errorf("failed to render rule template %s: %v", t.Name, err)
+	}
+	var rules []TemplateRule
+	for i, line := range strings.Split(out.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseTemplateRule(strings.Fields(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule on line %d of rule template %s: %v", i+1, t.Name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// parseTemplateRule parses the arguments of a rendered rule.
+func parseTemplateRule(args []string) (TemplateRule, error) {
+	r := TemplateRule{Table: constants.FILTER}
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-4":
+			r.IPv4 = true
+		case "-6":
+			r.IPv6 = true
+		case "-t", "--table":
+			if i+1 == len(args) {
+				return r, fmt.Errorf("%s needs a table", args[i])
+			}
+			i++
+			r.Table = args[i]
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	if !templateTables.Contains(r.Table) {
+		return r, fmt.Errorf("unknown table %q, expected one of %v", r.Table, sets.SortedList(templateTables))
+	}
+	if !r.IPv4 && !r.IPv6 {
+		r.IPv4, r.IPv6 = true, true
+	}
+	if len(rest) < 2 {
+		return r, fmt.Errorf("expected -A <chain> or -I <chain> [position]")
+	}
+	switch rest[0] {
+	case "-A", "--append":
+	case "-I", "--insert":
+		r.Insert, r.Position = true, 1
+		if len(rest) > 2 {
+			if pos, err := strconv.Atoi(rest[2]); err == nil {
+				if pos < 1 {
+					return r, fmt.Errorf("invalid position %d", pos)
+				}
+				r.Position = pos
+				rest = append(rest[:2:2], rest[3:]...)
+			}
+		}
+	default:
+		return r, fmt.Errorf("unsupported command %q, expected -A <chain> or -I <chain> [position]", rest[0])
+	}
+	r.Chain = rest[1]
+	r.Params = rest[2:]
+	if len(r.Params) == 0 {
+		return r, fmt.Errorf("rule of chain %s has no arguments", r.Chain)
+	}
+	return r, nil
+}
+
+// RenderRuleTemplates loads the rule templates of RuleTemplatesDir, if set, and renders them with the config.
+func (c *Config) RenderRuleTemplates() ([]TemplateRule, error) {
+	if c.RuleTemplatesDir == "" {
+		return nil, nil
+	}
+	templates, err := LoadRuleTemplates(c.RuleTemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	var rules []TemplateRule
+	for _, t := range templates {
+		r, err := t.Render(c)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r...)
+	}
+	return rules, nil
+}
@@ -0,0 +1,92 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestParseTemplateRule(t *testing.T) {
+	cases := []struct {
+		name string
+		rule string
+		want TemplateRule
+		err  string
+	}{
+		{
+			name: "append",
+			rule: "-A OUTPUT -p tcp -j DROP",
+			want: TemplateRule{Table: "filter", Chain: "OUTPUT", Params: []string{"-p", "tcp", "-j", "DROP"}, IPv4: true, IPv6: true},
+		},
+		{
+			name: "insert at position",
+			rule: "-t nat -6 -I OUTPUT 2 -j ISTIO_EGRESS_GW",
+			want: TemplateRule{Table: "nat", Chain: "OUTPUT", Insert: true, Position: 2, Params: []string{"-j", "ISTIO_EGRESS_GW"}, IPv6: true},
+		},
+		{
+			name: "insert",
+			rule: "-I PREROUTING -t mangle -4 -j MARK --set-mark 7",
+			want: TemplateRule{
+				Table: "mangle", Chain: "PREROUTING", Insert: true, Position: 1,
+				Params: []string{"-j", "MARK", "--set-mark", "7"}, IPv4: true,
+			},
+		},
+		{name: "unknown table", rule: "-t security -A OUTPUT -j DROP", err: "unknown table"},
+		{name: "delete", rule: "-D OUTPUT -j DROP", err: "unsupported command"},
+		{name: "no arguments", rule: "-A OUTPUT", err: "has no arguments"},
+		{name: "invalid position", rule: "-I OUTPUT 0 -j DROP", err: "invalid position"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseTemplateRule(strings.Fields(tc.rule))
+			if tc.err != "" {
+				assert.Error(t, err)
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, tc.want)
+		})
+	}
+}
+
+func TestRenderRuleTemplates(t *testing.T) {
+	dir := t.TempDir()
+	// ConfigMap mounts hold their data in hidden entries, which are skipped.
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b"), []byte("-A OUTPUT -j DROP\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a"),
+		[]byte("# redirect\n\n-t nat -A OUTPUT -p tcp -j REDIRECT --to-ports {{ .ProxyPort }}\n"), 0o644))
+
+	cfg := DefaultConfig()
+	cfg.ProxyPort = "15001"
+	cfg.RuleTemplatesDir = dir
+	rules, err := cfg.RenderRuleTemplates()
+	assert.NoError(t, err)
+	assert.Equal(t, len(rules), 2)
+	assert.Equal(t, rules[0].Params, []string{"-p", "tcp", "-j", "REDIRECT", "--to-ports", "15001"})
+	assert.Equal(t, rules[1].Table, "filter")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c"), []byte("-A OUTPUT -j {{ .Unknown }}\n"), 0o644))
+	_, err = cfg.RenderRuleTemplates()
+	assert.Error(t, err)
+}
@@ -15,6 +15,7 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -63,3 +64,53 @@ func InterceptOnly(values ...string) InterceptFilter {
 		Except: false,
 	}
 }
+
+const (
+	// ChainPositionAppend appends the rules of Istio to the built-in chains, behind the rules of other agents.
+	ChainPositionAppend = "append"
+	// ChainPositionHead inserts the rules of Istio at the head of the built-in chains.
+	ChainPositionHead = "head"
+	// ChainPositionBefore inserts the rules of Istio right before the first jump to the chain of another agent.
+	ChainPositionBefore = "before"
+	// ChainPositionAfter inserts the rules of Istio right after the last jump to the chain of another agent.
+	ChainPositionAfter = "after"
+)
+
+// ChainPosition is where the rules of Istio in built-in chains, such as the jumps to its own chains, are placed
+// relative to the rules of other agents sharing the node, such as CNI plugins or kube-proxy.
+type ChainPosition struct {
+	// Mode is one of ChainPositionAppend, ChainPositionHead, ChainPositionBefore or ChainPositionAfter.
+	Mode string
+	// Chain is the chain of the other agent for ChainPositionBefore and ChainPositionAfter.
+	Chain string
+}
+
+func (p ChainPosition) String() string {
+	if p.Chain == "" {
+		return p.Mode
+	}
+	return p.Mode + ":" + p.Chain
+}
+
+// ParseChainPosition parses "append", "head", "before:<chain>" or "after:<chain>". The empty string is "append".
+func ParseChainPosition(s string) (ChainPosition, error) {
+	mode, chain, _ := strings.Cut(strings.TrimSpace(s), ":")
+	switch mode {
+	case "", ChainPositionAppend, ChainPositionHead:
+		if chain != "" {
+			return ChainPosition{}, fmt.Errorf("chain position %q does not take a chain", mode)
+		}
+		if mode == "" {
+			mode = ChainPositionAppend
+		}
+		return ChainPosition{Mode: mode}, nil
+	case ChainPositionBefore, ChainPositionAfter:
+		if chain == "" || strings.ContainsAny(chain, " \t") {
+			return ChainPosition{}, fmt.Errorf("chain position %q requires a chain, e.g. %s:KUBE-SERVICES", mode, mode)
+		}
+		return ChainPosition{Mode: mode, Chain: chain}, nil
+	default:
+		return ChainPosition{}, fmt.Errorf("invalid chain position %q, expected one of %s, %s, %s:<chain> or %s:<chain>",
+			s, ChainPositionAppend, ChainPositionHead, ChainPositionBefore, ChainPositionAfter)
+	}
+}
@@ -16,6 +16,8 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -41,3 +43,78 @@ func ValidateOwnerGroups(include, exclude string) error {
 	}
 	return nil
 }
+
+// ValidateOutboundCapturePercent checks that the share of outbound connections to capture is a percentage, empty
+// meaning unset. Zero captures no connection.
+func ValidateOutboundCapturePercent(percent string) error {
+	_, err := ParseOutboundCapturePercent(percent)
+	return err
+}
+
+// ParseOutboundCapturePercent parses the share of outbound connections to capture, 100 if unset.
+func ParseOutboundCapturePercent(percent string) (uint16, error) {
+	if percent == "" {
+		return 100, nil
+	}
+	p, err := strconv.ParseUint(strings.TrimSpace(percent), 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("outbound capture percentage %q invalid: %v", percent, err)
+	}
+	if p > 100 {
+		return 0, fmt.Errorf("outbound capture percentage must be between 0 and 100, got %d", p)
+	}
+	return uint16(p), nil
+}
+
+// ValidateOutboundUDPPorts checks that the outbound UDP ports to capture are valid, and that the proxy
+// is able to accept them: UDP traffic redirected to a proxy without a UDP listener would be dropped.
+func ValidateOutboundUDPPorts(ports, proxyUDPPort string, redirectDNS bool) error {
+	if ports == "" {
+		return nil
+	}
+	if proxyUDPPort == "" {
+		return fmt.Errorf("capturing outbound UDP ports %s requires a proxy with a UDP listener, "+
+			"but no proxy UDP port is configured", ports)
+	}
+	if err := validatePort(proxyUDPPort); err != nil {
+		return fmt.Errorf("invalid proxy UDP port: %v", err)
+	}
+	for _, port := range strings.Split(ports, ",") {
+		port = strings.TrimSpace(port)
+		if err := validatePort(port); err != nil {
+			return fmt.Errorf("invalid outbound UDP port: %v", err)
+		}
+		if port == "53" && redirectDNS {
+			return fmt.Errorf("outbound UDP port 53 is already captured by DNS redirection")
+		}
+	}
+	return nil
+}
+
+// ValidateHostPortHairpinPorts checks that the hostPort hairpin ports are either valid ports or the
+// wildcard "*".
+func ValidateHostPortHairpinPorts(ports string) error {
+	if ports == "" || ports == "*" {
+		return nil
+	}
+	for _, port := range strings.Split(ports, ",") {
+		if err := validatePort(strings.TrimSpace(port)); err != nil {
+			return fmt.Errorf("invalid hostPort hairpin port: %v", err)
+		}
+	}
+	return nil
+}
+
+// ValidateChainPosition checks that the position of the rules of Istio in built-in chains can be parsed.
+func ValidateChainPosition(position string) error {
+	_, err := ParseChainPosition(position)
+	return err
+}
+
+func validatePort(port string) error {
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return fmt.Errorf("%q is not a valid port", port)
+	}
+	return nil
+}
@@ -91,3 +91,165 @@ func TestValidateOwnerGroups_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOutboundCapturePercent(t *testing.T) {
+	cases := []struct {
+		percent string
+		valid   bool
+	}{
+		{percent: "", valid: true},
+		{percent: "0", valid: true},
+		{percent: "25", valid: true},
+		{percent: "100", valid: true},
+		{percent: "101", valid: false},
+		{percent: "-1", valid: false},
+		{percent: "half", valid: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.percent, func(t *testing.T) {
+			err := ValidateOutboundCapturePercent(tc.percent)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateOutboundUDPPorts(t *testing.T) {
+	cases := []struct {
+		name         string
+		ports        string
+		proxyUDPPort string
+		redirectDNS  bool
+		valid        bool
+	}{
+		{name: "disabled", valid: true},
+		{name: "valid ports", ports: "5060, 9000", proxyUDPPort: "15009", valid: true},
+		{name: "proxy without UDP listener", ports: "5060"},
+		{name: "invalid port", ports: "5060,abc", proxyUDPPort: "15009"},
+		{name: "port out of range", ports: "70000", proxyUDPPort: "15009"},
+		{name: "invalid proxy UDP port", ports: "5060", proxyUDPPort: "0"},
+		{name: "DNS port without DNS redirection", ports: "53", proxyUDPPort: "15009", valid: true},
+		{name: "DNS port with DNS redirection", ports: "53", proxyUDPPort: "15009", redirectDNS: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateOutboundUDPPorts(tc.ports, tc.proxyUDPPort, tc.redirectDNS)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateHostPortHairpinPorts(t *testing.T) {
+	cases := []struct {
+		ports string
+		valid bool
+	}{
+		{ports: "", valid: true},
+		{ports: "*", valid: true},
+		{ports: "8080, 8443", valid: true},
+		{ports: "8080,*"},
+		{ports: "0"},
+		{ports: "http"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.ports, func(t *testing.T) {
+			err := ValidateHostPortHairpinPorts(tc.ports)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateChainPosition(t *testing.T) {
+	cases := []struct {
+		position string
+		valid    bool
+	}{
+		{position: "", valid: true},
+		{position: "append", valid: true},
+		{position: "head", valid: true},
+		{position: "before:KUBE-SERVICES", valid: true},
+		{position: "after:CILIUM_OUTPUT_nat", valid: true},
+		{position: "head:KUBE-SERVICES"},
+		{position: "before:"},
+		{position: "first"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.position, func(t *testing.T) {
+			err := ValidateChainPosition(tc.position)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyPlatformQuirks(t *testing.T) {
+	cfg := &Config{
+		OutboundIPRangesExclude: "10.0.0.0/8,169.254.169.254/32",
+		PlatformQuirks:          "aws-imds, containerd-stream-server",
+	}
+	assert.NoError(t, cfg.Validate())
+	assert.NoError(t, cfg.ApplyPlatformQuirks())
+	assert.Equal(t, cfg.OutboundIPRangesExclude, "10.0.0.0/8,169.254.169.254/32,fd00:ec2::254/128")
+	assert.Equal(t, cfg.OutboundPortsExclude, "10010")
+
+	cfg = &Config{PlatformQuirks: "gke"}
+	assert.Error(t, cfg.Validate())
+	assert.Error(t, cfg.ApplyPlatformQuirks())
+}
+
+func TestApplyCapturePreset(t *testing.T) {
+	capturing := func(preset string) *Config {
+		return &Config{
+			InboundPortsInclude:     "*",
+			OutboundIPRangesInclude: "*",
+			OutboundPortsInclude:    "5432",
+			RedirectDNS:             true,
+			CaptureAllDNS:           true,
+			CapturePreset:           preset,
+		}
+	}
+	cases := []struct {
+		preset   string
+		inbound  string
+		outbound string
+		dns      bool
+	}{
+		{preset: "", inbound: "*", outbound: "*", dns: true},
+		{preset: "outbound-only", inbound: "", outbound: "*", dns: true},
+		{preset: "inbound-only", inbound: "*", outbound: "", dns: false},
+		{preset: "dns-only", inbound: "", outbound: "", dns: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.preset, func(t *testing.T) {
+			cfg := capturing(tc.preset)
+			assert.NoError(t, cfg.Validate())
+			assert.NoError(t, cfg.ApplyCapturePreset())
+			assert.Equal(t, cfg.InboundPortsInclude, tc.inbound)
+			assert.Equal(t, cfg.OutboundIPRangesInclude, tc.outbound)
+			assert.Equal(t, cfg.RedirectDNS, tc.dns)
+		})
+	}
+
+	// The dns-only preset captures the DNS queries even if their capture is not enabled.
+	cfg := &Config{CapturePreset: "dns-only"}
+	assert.NoError(t, cfg.ApplyCapturePreset())
+	assert.Equal(t, cfg.RedirectDNS, true)
+
+	cfg = &Config{CapturePreset: "outbound"}
+	assert.Error(t, cfg.Validate())
+	assert.Error(t, cfg.ApplyCapturePreset())
+}
@@ -74,6 +74,7 @@ const (
 	ISTIOTPROXY     = "ISTIO_TPROXY"
 	ISTIOREDIRECT   = "ISTIO_REDIRECT"
 	ISTIOINREDIRECT = "ISTIO_IN_REDIRECT"
+	ISTIOOUTPUTUDP  = "ISTIO_OUTPUT_UDP"
 )
 
 // Constants used in cobra/viper CLI
@@ -83,11 +84,16 @@ const (
 	InboundTProxyRouteTable   = "istio-inbound-tproxy-route-table"
 	InboundPorts              = "istio-inbound-ports"
 	LocalExcludePorts         = "istio-local-exclude-ports"
+	HostPortHairpinPorts      = "istio-hostport-hairpin-ports"
+	ExemptKubeletProbes       = "istio-exempt-kubelet-probes"
 	ExcludeInterfaces         = "istio-exclude-interfaces"
 	ServiceCidr               = "istio-service-cidr"
 	ServiceExcludeCidr        = "istio-service-exclude-cidr"
 	OutboundPorts             = "istio-outbound-ports"
 	LocalOutboundPortsExclude = "istio-local-outbound-ports-exclude"
+	OutboundCapturePercent    = "istio-outbound-capture-percent"
+	OutboundUDPPorts          = "istio-outbound-udp-ports"
+	ProxyUDPPort              = "proxy-udp-port"
 	EnvoyPort                 = "envoy-port"
 	InboundCapturePort        = "inbound-capture-port"
 	InboundTunnelPort         = "inbound-tunnel-port"
@@ -107,7 +113,13 @@ const (
 	CaptureAllDNS             = "capture-all-dns"
 	NetworkNamespace          = "network-namespace"
 	CNIMode                   = "cni-mode"
+	NsenterTarget             = "nsenter-target"
+	NsenterMountNamespace     = "nsenter-mount-namespace"
 	IptablesVersion           = "iptables-version"
+	ChainPosition             = "chain-position"
+	PlatformQuirks            = "platform-quirks"
+	CapturePreset             = "capture-preset"
+	RuleTemplatesDir          = "rule-templates-dir"
 )
 
 // Environment variables that deliberately have no equivalent command-line flags.
@@ -150,6 +162,17 @@ const (
 	IP6TABLES        = "ip6tables"
 	IP6TABLESRESTORE = "ip6tables-restore"
 	IP6TABLESSAVE    = "ip6tables-save"
+
+	// The commands of a given iptables backend, which the ambient node agent selects on the host.
+	IPTABLESLEGACY        = "iptables-legacy"
+	IPTABLESLEGACYRESTORE = "iptables-legacy-restore"
+	IPTABLESLEGACYSAVE    = "iptables-legacy-save"
+	IPTABLESNFT           = "iptables-nft"
+	IPTABLESNFTRESTORE    = "iptables-nft-restore"
+	IPTABLESNFTSAVE       = "iptables-nft-save"
+
+	// NSENTER runs the xtables commands in the namespaces of another process.
+	NSENTER = "nsenter"
 )
 
 // Constants for syscall
@@ -164,6 +187,14 @@ const (
 	DefaultProbeTimeout          = 5 * time.Second
 )
 
+// Source addresses of the kubelet probes exempted from inbound capture. The node agent translates the source of
+// the connections the host opens to the pods of the node to them. They are a link-local IPv4 address and a
+// unique local IPv6 address chosen not to collide with the addresses of pods or nodes.
+const (
+	KubeletProbeSourceV4 = "169.254.7.127"
+	KubeletProbeSourceV6 = "fd16:9254:7127:1337:ffff:ffff:ffff:ffff"
+)
+
 const (
 	ValidationContainerName = "istio-validation"
 	ValidationErrorCode     = 126
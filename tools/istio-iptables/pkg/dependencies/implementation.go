@@ -15,14 +15,22 @@
 package dependencies
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os/exec"
 	"regexp"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	utilversion "k8s.io/apimachinery/pkg/util/version"
 
+	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/tracing"
 	"istio.io/istio/pkg/util/sets"
 	"istio.io/istio/tools/istio-iptables/pkg/constants"
 )
@@ -59,6 +67,12 @@ var XTablesCmds = sets.New(
 	constants.IP6TABLESRESTORE,
 	constants.IPTABLESSAVE,
 	constants.IP6TABLESSAVE,
+	constants.IPTABLESLEGACY,
+	constants.IPTABLESLEGACYRESTORE,
+	constants.IPTABLESLEGACYSAVE,
+	constants.IPTABLESNFT,
+	constants.IPTABLESNFTRESTORE,
+	constants.IPTABLESNFTSAVE,
 )
 
 // XTablesWriteCmds contains all xtables commands that do write actions (and thus need a lock)
@@ -67,6 +81,10 @@ var XTablesWriteCmds = sets.New(
 	constants.IP6TABLES,
 	constants.IPTABLESRESTORE,
 	constants.IP6TABLESRESTORE,
+	constants.IPTABLESLEGACY,
+	constants.IPTABLESLEGACYRESTORE,
+	constants.IPTABLESNFT,
+	constants.IPTABLESNFTRESTORE,
 )
 
 // RealDependencies implementation of interface Dependencies, which is used in production
@@ -74,6 +92,12 @@ type RealDependencies struct {
 	IptablesVersion  IptablesVersion
 	NetworkNamespace string
 	CNIMode          bool
+	// Nsenter, if set, runs the xtables commands in the namespaces of the target with nsenter. Other commands
+	// still run in the namespaces of the caller.
+	Nsenter *NsenterTarget
+	// Context is the parent of the tracing spans recorded for each command run, if set.
+	// Spans are only exported when a tracer provider was initialized, see pkg/tracing.
+	Context context.Context
 }
 
 const iptablesVersionPattern = `v([0-9]+(\.[0-9]+)+)`
@@ -143,10 +167,147 @@ func transformToXTablesErrorMessage(stderr string, err error) string {
 	return stderr
 }
 
+// maxCommandOutputBytes bounds how much of each output stream of a single command is logged, and how much of its
+// error output is kept. Some commands, such as iptables-save on a busy node, can produce very large outputs.
+const maxCommandOutputBytes = 256 * 1024
+
+// maxRetainedOutputBytes bounds how much of the standard output of a command is kept for callers parsing it.
+// A command producing more output fails rather than returning part of it.
+const maxRetainedOutputBytes = 32 * 1024 * 1024
+
+// lineWriter is an io.Writer that logs the output of a command line by line as it is produced,
+// instead of buffering the whole output. Output past the limit is not logged.
+type lineWriter struct {
+	prefix  string
+	logf    func(template string, args ...any)
+	limit   int
+	written int
+	partial []byte
+}
+
+func newLineWriter(prefix string, logf func(template string, args ...any), limit int) *lineWriter {
+	return &lineWriter{prefix: prefix, logf: logf, limit: limit}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if remaining := w.limit - w.written; remaining <= 0 {
+		p = nil
+	} else if remaining < len(p) {
+		p = p[:remaining]
+	}
+	w.written += n
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.logf("%s%s", w.prefix, w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	return n, nil
+}
+
+// Close logs any trailing incomplete line, and reports how much output was dropped, if any.
+func (w *lineWriter) Close() error {
+	if len(w.partial) > 0 {
+		w.logf("%s%s", w.prefix, w.partial)
+		w.partial = nil
+	}
+	if w.written > w.limit {
+		w.logf("%soutput truncated, did not log %d of %d bytes", w.prefix, w.written-w.limit, w.written)
+	}
+	return nil
+}
+
+// cappedBuffer is an io.Writer that keeps the output written to it up to its limit, and counts the bytes dropped
+// past it. Writes never fail, so that the command writing to it is not interrupted.
+type cappedBuffer struct {
+	limit   int
+	buf     bytes.Buffer
+	dropped int
+}
+
+func newCappedBuffer(limit int) *cappedBuffer {
+	return &cappedBuffer{limit: limit}
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	remaining := b.limit - b.buf.Len()
+	if remaining < 0 {
+		remaining = 0
+	}
+	if remaining < len(p) {
+		b.dropped += len(p) - remaining
+		p = p[:remaining]
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+// String returns the kept output.
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// logCommandErrorOutput logs the error output of a command once it is done: as an error if the command failed
+// or complained, at debug level if its errors are ignored. dropped is the number of bytes of error output that
+// were not kept.
+func logCommandErrorOutput(ignoreErrors bool, stderr string, dropped int, err error) {
+	if stderr == "" {
+		if err == nil {
+			return
+		}
+		stderr = err.Error()
+	}
+	if dropped > 0 {
+		stderr = fmt.Sprintf("%s... (truncated, dropped %d bytes)", stderr, dropped)
+	}
+	if ignoreErrors {
+		log.Debugf("Command error output: %v", stderr)
+		return
+	}
+	log.Errorf("Command error output: %v", stderr)
+}
+
+// startCommandSpan starts the tracing span of a single command.
+func (r *RealDependencies) startCommandSpan(cmd string, ignoreErrors bool, args []string) (context.Context, trace.Span) {
+	ctx := r.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Start(ctx, "dependencies.RunCommand")
+	span.SetAttributes(
+		attribute.String("command", cmd),
+		attribute.StringSlice("args", args),
+		attribute.Bool("cni_mode", r.CNIMode),
+		attribute.Bool("ignore_errors", ignoreErrors),
+	)
+	return ctx, span
+}
+
+// endCommandSpan records the outcome of the command on its span and ends it. Errors of commands whose
+// errors are ignored are recorded as events only.
+func endCommandSpan(span trace.Span, ignoreErrors bool, err error) {
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			span.SetAttributes(attribute.Int("exit_code", ee.ExitCode()))
+		}
+		span.RecordError(err)
+		if !ignoreErrors {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
 // Run runs a command
 func (r *RealDependencies) Run(cmd string, stdin io.ReadSeeker, args ...string) (err error) {
 	if XTablesCmds.Contains(cmd) {
-		err = r.executeXTables(cmd, false, stdin, args...)
+		_, err = r.executeXTables(cmd, false, false, stdin, args...)
 	} else {
 		err = r.execute(cmd, false, stdin, args...)
 	}
@@ -156,8 +317,17 @@ func (r *RealDependencies) Run(cmd string, stdin io.ReadSeeker, args ...string)
 // RunQuietlyAndIgnore runs a command quietly and ignores errors
 func (r *RealDependencies) RunQuietlyAndIgnore(cmd string, stdin io.ReadSeeker, args ...string) {
 	if XTablesCmds.Contains(cmd) {
-		_ = r.executeXTables(cmd, true, stdin, args...)
+		_, _ = r.executeXTables(cmd, true, false, stdin, args...)
 	} else {
 		_ = r.execute(cmd, true, stdin, args...)
 	}
 }
+
+// RunWithOutput runs an xtables command, such as iptables-save, and returns its standard output. The command fails
+// if its output exceeds maxRetainedOutputBytes.
+func (r *RealDependencies) RunWithOutput(cmd string, stdin io.ReadSeeker, args ...string) (string, error) {
+	if !XTablesCmds.Contains(cmd) {
+		return "", fmt.Errorf("output of %s is not supported, only xtables commands are", cmd)
+	}
+	return r.executeXTables(cmd, false, true, stdin, args...)
+}
@@ -15,7 +15,7 @@
 package dependencies
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os/exec"
@@ -25,18 +25,23 @@ import (
 
 	netns "github.com/containernetworking/plugins/pkg/ns"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sys/unix"
 	utilversion "k8s.io/apimachinery/pkg/util/version"
 
 	"istio.io/istio/pkg/log"
+	"istio.io/istio/pkg/tracing"
 )
 
-func (r *RealDependencies) execute(cmd string, ignoreErrors bool, stdin io.Reader, args ...string) error {
+func (r *RealDependencies) execute(cmd string, ignoreErrors bool, stdin io.Reader, args ...string) (err error) {
 	log.Infof("Running command: %s %s", cmd, strings.Join(args, " "))
+	_, span := r.startCommandSpan(cmd, ignoreErrors, args)
+	defer func() { endCommandSpan(span, ignoreErrors, err) }()
 
 	externalCommand := exec.Command(cmd, args...)
-	stdout := &bytes.Buffer{}
-	stderr := &bytes.Buffer{}
+	stdout := newLineWriter(cmd+" stdout: ", log.Debugf, maxCommandOutputBytes)
+	stderr := newCappedBuffer(maxCommandOutputBytes)
 	externalCommand.Stdout = stdout
 	externalCommand.Stderr = stderr
 	externalCommand.Stdin = stdin
@@ -50,14 +55,9 @@ func (r *RealDependencies) execute(cmd string, ignoreErrors bool, stdin io.Reade
 		}
 		externalCommand.Env = append(externalCommand.Env, fmt.Sprintf("%s=%v", strings.ToUpper(repl.Replace(k)), v))
 	}
-	err := externalCommand.Run()
-	if len(stdout.String()) != 0 {
-		log.Infof("Command output: \n%v", stdout.String())
-	}
-
-	if !ignoreErrors && len(stderr.Bytes()) != 0 {
-		log.Errorf("Command error output: \n%v", stderr.String())
-	}
+	err = externalCommand.Run()
+	_ = stdout.Close()
+	logCommandErrorOutput(ignoreErrors, stderr.String(), stderr.dropped, err)
 
 	return err
 }
@@ -72,25 +72,37 @@ var (
 // runInSandbox builds a lightweight sandbox ("container") to build a suitable environment to run iptables commands in.
 // This is used in CNI, where commands are executed from the host but from within the container network namespace.
 // This puts us in somewhat unconventionally territory.
-func runInSandbox(lockFile string, f func() error) error {
+func runInSandbox(ctx context.Context, lockFile string, f func() error) error {
 	chErr := make(chan error, 1)
 	n, nerr := netns.GetCurrentNS()
 	if nerr != nil {
 		return fmt.Errorf("failed to get current namespace: %v", nerr)
 	}
 	// setupSandbox builds the sandbox.
-	setupSandbox := func() error {
+	setupSandbox := func() (err error) {
+		_, span := tracing.Start(ctx, "dependencies.SetupSandbox")
+		span.SetAttributes(attribute.Bool("lock_file_mount", lockFile != ""))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
 		// First, unshare the mount namespace. This allows us to create custom mounts without impacting the host
 		if err := unix.Unshare(unix.CLONE_NEWNS); err != nil {
-			return fmt.Errorf("failed to unshare to new mount namespace: %v", err)
+			return fmt.Errorf("failed to unshare to new mount namespace: %w",
+				withSELinuxHint(DeniedOperation{Description: "unshare the mount namespace", Class: "capability", Permission: "sys_admin"}, err))
 		}
 		if err := n.Set(); err != nil {
-			return fmt.Errorf("failed to reset network namespace: %v", err)
+			return fmt.Errorf("failed to reset network namespace: %w",
+				withSELinuxHint(DeniedOperation{Description: "enter the network namespace", Class: "capability", Permission: "sys_admin"}, err))
 		}
 		// Remount / as a private mount so that our mounts do not impact outside the namespace
 		// (see https://unix.stackexchange.com/questions/246312/why-is-my-bind-mount-visible-outside-its-mount-namespace).
 		if err := unix.Mount("", "/", "", unix.MS_PRIVATE|unix.MS_REC, ""); err != nil {
-			return fmt.Errorf("failed to remount /: %v", err)
+			return fmt.Errorf("failed to remount /: %w",
+				withSELinuxHint(DeniedOperation{Description: "remount / as private", Path: "/", Class: "dir", Permission: "mounton"}, err))
 		}
 		// In CNI, we are running the pod network namespace, but the host filesystem. Locking the host is both useless and harmful,
 		// as it opens the risk of lock contention with other node actors (such as kube-proxy), and isn't actually needed at all.
@@ -151,10 +163,25 @@ func runInSandbox(lockFile string, f func() error) error {
 }
 
 func mount(src, dst string) error {
-	return syscall.Mount(src, dst, "", syscall.MS_BIND|syscall.MS_RDONLY, "")
+	err := syscall.Mount(src, dst, "", syscall.MS_BIND|syscall.MS_RDONLY, "")
+	return withSELinuxHint(DeniedOperation{Description: "bind mount over " + dst, Path: dst, Class: "file", Permission: "mounton"}, err)
 }
 
-func (r *RealDependencies) executeXTables(cmd string, ignoreErrors bool, stdin io.ReadSeeker, args ...string) error {
+// fileLabel returns the SELinux label of the file.
+func fileLabel(path string) (string, error) {
+	buf := make([]byte, 256)
+	n, err := unix.Lgetxattr(path, "security.selinux", buf)
+	if err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
+
+// executeXTables runs an xtables command. The standard output is returned only if retainOutput is set, in which case
+// the command fails if its output exceeds maxRetainedOutputBytes.
+func (r *RealDependencies) executeXTables(cmd string, ignoreErrors, retainOutput bool, stdin io.ReadSeeker, args ...string) (out string, err error) {
+	ctx, span := r.startCommandSpan(cmd, ignoreErrors, args)
+	defer func() { endCommandSpan(span, ignoreErrors, err) }()
 	mode := "without lock"
 	var c *exec.Cmd
 	_, isWriteCommand := XTablesWriteCmds[cmd]
@@ -162,8 +189,16 @@ func (r *RealDependencies) executeXTables(cmd string, ignoreErrors bool, stdin i
 	run := func(c *exec.Cmd) error {
 		return c.Run()
 	}
-	if r.CNIMode {
-		c = exec.Command(cmd, args...)
+	// Commands run in the network namespace of a pod but on the filesystem of the host need the sandbox. When nsenter
+	// enters the mount namespace of the target as well, the filesystem of the target keeps them apart from the host.
+	sandboxed := r.CNIMode
+	lockNamespace := r.NetworkNamespace
+	if r.Nsenter != nil {
+		sandboxed = !r.Nsenter.entersMountNamespace()
+		lockNamespace = r.Nsenter.networkNamespace()
+	}
+	if sandboxed {
+		c = r.xtablesCommand(cmd, args...)
 		// In CNI, we are running the pod network namespace, but the host filesystem, so we need to do some tricks
 		// Call our binary again, but with <original binary> "unshare (subcommand to trigger mounts)" --lock-file=<network namespace> <original command...>
 		// We do not shell out and call `mount` since this and sh are not available on all systems
@@ -171,17 +206,17 @@ func (r *RealDependencies) executeXTables(cmd string, ignoreErrors bool, stdin i
 		if needLock {
 			if r.IptablesVersion.version.LessThan(IptablesLockfileEnv) {
 				mode = "without lock by mount and nss"
-				lockFile = r.NetworkNamespace
+				lockFile = lockNamespace
 			} else {
 				mode = "without lock by env and nss"
-				c.Env = append(c.Env, "XTABLES_LOCKFILE="+r.NetworkNamespace)
+				c.Env = append(c.Env, "XTABLES_LOCKFILE="+lockNamespace)
 			}
 		} else {
 			mode = "without nss"
 		}
 
 		run = func(c *exec.Cmd) error {
-			return runInSandbox(lockFile, func() error {
+			return runInSandbox(ctx, lockFile, func() error {
 				return c.Run()
 			})
 		}
@@ -189,37 +224,47 @@ func (r *RealDependencies) executeXTables(cmd string, ignoreErrors bool, stdin i
 		if needLock {
 			// We want the lock. Wait up to 30s for it.
 			args = append(args, "--wait=30")
-			c = exec.Command(cmd, args...)
+			c = r.xtablesCommand(cmd, args...)
 			log.Debugf("running with lock")
 			mode = "with wait lock"
 		} else {
 			// No locking supported/needed, just run as is. Nothing special
-			c = exec.Command(cmd, args...)
+			c = r.xtablesCommand(cmd, args...)
 		}
 	}
 
+	if r.Nsenter != nil {
+		mode += " in namespaces of " + strings.Join(r.Nsenter.Args(), " ")
+	}
 	log.Infof("Running command (%s): %s %s", mode, cmd, strings.Join(args, " "))
-	stdout := &bytes.Buffer{}
-	stderr := &bytes.Buffer{}
+	// The lock is shared by every actor on the node, record how it was handled to tell lock contention apart.
+	span.SetAttributes(attribute.String("lock_mode", mode), attribute.Bool("lock_needed", needLock))
+	stdout := newLineWriter(cmd+" stdout: ", log.Debugf, maxCommandOutputBytes)
 	c.Stdout = stdout
+	var retained *cappedBuffer
+	if retainOutput {
+		retained = newCappedBuffer(maxRetainedOutputBytes)
+		c.Stdout = io.MultiWriter(stdout, retained)
+	}
+	// stderr is buffered so that failures are reported once, with xtables-specific hints.
+	stderr := newCappedBuffer(maxCommandOutputBytes)
 	c.Stderr = stderr
 	c.Stdin = stdin
-	err := run(c)
-	if len(stdout.String()) != 0 {
-		log.Infof("Command output: \n%v", stdout.String())
-	}
-
-	// TODO Check naming and redirection logic
-	if (err != nil || len(stderr.String()) != 0) && !ignoreErrors {
-		stderrStr := stderr.String()
+	err = run(c)
+	_ = stdout.Close()
 
+	stderrStr := stderr.String()
+	if err != nil {
 		// Transform to xtables-specific error messages with more useful and actionable hints.
-		if err != nil {
-			stderrStr = transformToXTablesErrorMessage(stderrStr, err)
-		}
-
-		log.Errorf("Command error output: %v", stderrStr)
+		stderrStr = transformToXTablesErrorMessage(stderrStr, err)
 	}
+	logCommandErrorOutput(ignoreErrors, stderrStr, stderr.dropped, err)
 
-	return err
+	if retained == nil || err != nil {
+		return "", err
+	}
+	if retained.dropped > 0 {
+		return "", fmt.Errorf("output of %s %s is truncated, it exceeds %d bytes", cmd, strings.Join(args, " "), maxRetainedOutputBytes)
+	}
+	return retained.String(), nil
 }
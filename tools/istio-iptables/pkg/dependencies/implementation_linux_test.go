@@ -0,0 +1,67 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dependencies
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"istio.io/istio/pkg/test/util/assert"
+	"istio.io/istio/pkg/tracing"
+)
+
+func TestCommandSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	r := &RealDependencies{Context: ctx}
+	assert.NoError(t, r.Run("true", nil, "arg"))
+	if err := r.Run("false", nil); err == nil {
+		t.Fatal("expected false to fail")
+	}
+	r.RunQuietlyAndIgnore("false", nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Equal(t, len(spans), 4)
+	for _, s := range spans[:3] {
+		assert.Equal(t, s.Name(), "dependencies.RunCommand")
+		assert.Equal(t, s.Parent().SpanID(), parent.SpanContext().SpanID())
+	}
+	attrs := func(i int) map[attribute.Key]attribute.Value {
+		m := map[attribute.Key]attribute.Value{}
+		for _, kv := range spans[i].Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+	assert.Equal(t, attrs(0)["command"].AsString(), "true")
+	assert.Equal(t, attrs(0)["args"].AsStringSlice(), []string{"arg"})
+	assert.Equal(t, spans[0].Status().Code, codes.Unset)
+	assert.Equal(t, attrs(1)["exit_code"].AsInt64(), int64(1))
+	assert.Equal(t, spans[1].Status().Code, codes.Error)
+	// Ignored failures are recorded, but do not mark the span as failed.
+	assert.Equal(t, attrs(2)["exit_code"].AsInt64(), int64(1))
+	assert.Equal(t, spans[2].Status().Code, codes.Unset)
+}
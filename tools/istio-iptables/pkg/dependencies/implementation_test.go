@@ -15,6 +15,8 @@
 package dependencies
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	utilversion "k8s.io/apimachinery/pkg/util/version"
@@ -81,3 +83,46 @@ func TestDetectIptablesVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	logf := func(template string, args ...any) {
+		lines = append(lines, fmt.Sprintf(template, args...))
+	}
+
+	w := newLineWriter("cmd: ", logf, 1024)
+	for _, chunk := range []string{"first li", "ne\nsecond line\nthi", "rd"} {
+		n, err := w.Write([]byte(chunk))
+		assert.NoError(t, err)
+		assert.Equal(t, n, len(chunk))
+	}
+	assert.Equal(t, lines, []string{"cmd: first line", "cmd: second line"})
+	assert.NoError(t, w.Close())
+	assert.Equal(t, lines, []string{"cmd: first line", "cmd: second line", "cmd: third"})
+
+	lines = nil
+	w = newLineWriter("", logf, 8)
+	big := strings.Repeat("a\n", 10)
+	n, err := w.Write([]byte(big))
+	assert.NoError(t, err)
+	assert.Equal(t, n, len(big))
+	_, _ = w.Write([]byte("dropped\n"))
+	assert.NoError(t, w.Close())
+	assert.Equal(t, lines, []string{"a", "a", "a", "a", "output truncated, did not log 20 of 28 bytes"})
+}
+
+func TestCappedBuffer(t *testing.T) {
+	b := newCappedBuffer(8)
+	for _, chunk := range []string{"abc", "defgh", "ijk", "lm"} {
+		n, err := b.Write([]byte(chunk))
+		assert.NoError(t, err)
+		assert.Equal(t, n, len(chunk))
+	}
+	assert.Equal(t, b.String(), "abcdefgh")
+	assert.Equal(t, b.dropped, 5)
+
+	b = newCappedBuffer(8)
+	_, _ = b.Write([]byte("abcdefgh"))
+	assert.Equal(t, b.String(), "abcdefgh")
+	assert.Equal(t, b.dropped, 0)
+}
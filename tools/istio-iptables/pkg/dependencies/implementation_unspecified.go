@@ -29,6 +29,10 @@ func (r *RealDependencies) execute(cmd string, ignoreErrors bool, stdin io.Reade
 	return ErrNotImplemented
 }
 
-func (r *RealDependencies) executeXTables(cmd string, ignoreErrors bool, stdin io.Reader, args ...string) error {
-	return ErrNotImplemented
+func (r *RealDependencies) executeXTables(cmd string, ignoreErrors, retainOutput bool, stdin io.Reader, args ...string) (string, error) {
+	return "", ErrNotImplemented
+}
+
+func fileLabel(path string) (string, error) {
+	return "", ErrNotImplemented
 }
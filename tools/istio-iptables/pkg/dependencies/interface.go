@@ -22,4 +22,6 @@ type Dependencies interface {
 	Run(cmd string, stdin io.ReadSeeker, args ...string) error
 	// RunQuietlyAndIgnore runs a command quietly and ignores errors
 	RunQuietlyAndIgnore(cmd string, stdin io.ReadSeeker, args ...string)
+	// RunWithOutput runs an xtables command and returns its standard output
+	RunWithOutput(cmd string, stdin io.ReadSeeker, args ...string) (string, error)
 }
@@ -0,0 +1,103 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+)
+
+// NsenterTarget selects the namespaces xtables commands are run in with nsenter, rather than in the namespaces of
+// the caller. This lets the node agent manage the rules of a pod from the host, without exec'ing inside the pod.
+type NsenterTarget struct {
+	// PID is the process whose network and mount namespaces are entered.
+	PID int
+	// NetworkNamespace is the path of the network namespace to enter, such as /var/run/netns/<name>, when PID is
+	// not set.
+	NetworkNamespace string
+	// MountNamespace is the path of the mount namespace to enter along with NetworkNamespace, if any. Without
+	// it, the commands run on the filesystem of the caller.
+	MountNamespace string
+}
+
+// ParseNsenterTarget parses a target given as a PID or as the path of a network namespace, with the path of a
+// mount namespace to enter along with it, if any. It returns nil without target.
+func ParseNsenterTarget(target, mountNamespace string) (*NsenterTarget, error) {
+	if target == "" {
+		if mountNamespace != "" {
+			return nil, errors.New("nsenter mount namespace needs an nsenter target")
+		}
+		return nil, nil
+	}
+	t := &NsenterTarget{NetworkNamespace: target, MountNamespace: mountNamespace}
+	if pid, err := strconv.Atoi(target); err == nil {
+		t = &NsenterTarget{PID: pid, MountNamespace: mountNamespace}
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Validate returns an error if the target does not select a network namespace, or selects it twice.
+func (t *NsenterTarget) Validate() error {
+	switch {
+	case t.PID < 0:
+		return fmt.Errorf("invalid nsenter target PID %d", t.PID)
+	case t.PID > 0 && (t.NetworkNamespace != "" || t.MountNamespace != ""):
+		return errors.New("nsenter target takes either a PID or namespace paths, not both")
+	case t.PID == 0 && t.NetworkNamespace == "":
+		return errors.New("nsenter target needs a PID or a network namespace")
+	}
+	return nil
+}
+
+// Args returns the arguments of nsenter entering the namespaces of the target.
+func (t *NsenterTarget) Args() []string {
+	if t.PID > 0 {
+		return []string{"--target", strconv.Itoa(t.PID), "--net", "--mount"}
+	}
+	args := []string{"--net=" + t.NetworkNamespace}
+	if t.MountNamespace != "" {
+		args = append(args, "--mount="+t.MountNamespace)
+	}
+	return args
+}
+
+// entersMountNamespace returns whether the commands run on the filesystem of the target.
+func (t *NsenterTarget) entersMountNamespace() bool {
+	return t.PID > 0 || t.MountNamespace != ""
+}
+
+// networkNamespace returns the path of the network namespace of the target, which is unique to it.
+func (t *NsenterTarget) networkNamespace() string {
+	if t.PID > 0 {
+		return fmt.Sprintf("/proc/%d/ns/net", t.PID)
+	}
+	return t.NetworkNamespace
+}
+
+// xtablesCommand returns the command running an xtables command, through nsenter when a target is set.
+func (r *RealDependencies) xtablesCommand(cmd string, args ...string) *exec.Cmd {
+	if r.Nsenter == nil {
+		return exec.Command(cmd, args...)
+	}
+	nsenterArgs := append(r.Nsenter.Args(), "--", cmd)
+	return exec.Command(constants.NSENTER, append(nsenterArgs, args...)...)
+}
@@ -0,0 +1,89 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dependencies
+
+import (
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestParseNsenterTarget(t *testing.T) {
+	cases := []struct {
+		name           string
+		target         string
+		mountNamespace string
+		want           *NsenterTarget
+		args           []string
+		netns          string
+		entersMount    bool
+		wantErr        bool
+	}{
+		{name: "none"},
+		{
+			name:        "pid",
+			target:      "1234",
+			want:        &NsenterTarget{PID: 1234},
+			args:        []string{"--target", "1234", "--net", "--mount"},
+			netns:       "/proc/1234/ns/net",
+			entersMount: true,
+		},
+		{
+			name:   "network namespace",
+			target: "/var/run/netns/cni-1234",
+			want:   &NsenterTarget{NetworkNamespace: "/var/run/netns/cni-1234"},
+			args:   []string{"--net=/var/run/netns/cni-1234"},
+			netns:  "/var/run/netns/cni-1234",
+		},
+		{
+			name:           "network and mount namespaces",
+			target:         "/var/run/netns/cni-1234",
+			mountNamespace: "/proc/42/ns/mnt",
+			want:           &NsenterTarget{NetworkNamespace: "/var/run/netns/cni-1234", MountNamespace: "/proc/42/ns/mnt"},
+			args:           []string{"--net=/var/run/netns/cni-1234", "--mount=/proc/42/ns/mnt"},
+			netns:          "/var/run/netns/cni-1234",
+			entersMount:    true,
+		},
+		{name: "pid and mount namespace", target: "1234", mountNamespace: "/proc/42/ns/mnt", wantErr: true},
+		{name: "mount namespace only", mountNamespace: "/proc/42/ns/mnt", wantErr: true},
+		{name: "negative pid", target: "-1", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseNsenterTarget(tc.target, tc.mountNamespace)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, got, tc.want)
+			if got == nil {
+				return
+			}
+			assert.Equal(t, got.Args(), tc.args)
+			assert.Equal(t, got.networkNamespace(), tc.netns)
+			assert.Equal(t, got.entersMountNamespace(), tc.entersMount)
+		})
+	}
+}
+
+func TestXTablesCommand(t *testing.T) {
+	r := &RealDependencies{}
+	assert.Equal(t, r.xtablesCommand("iptables-save", "-t", "nat").Args, []string{"iptables-save", "-t", "nat"})
+
+	r.Nsenter = &NsenterTarget{PID: 1234}
+	assert.Equal(t, r.xtablesCommand("iptables-save", "-t", "nat").Args,
+		[]string{"nsenter", "--target", "1234", "--net", "--mount", "--", "iptables-save", "-t", "nat"})
+}
@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+var (
+	// selinuxEnforceFile holds 1 while SELinux enforces its policy.
+	selinuxEnforceFile = "/sys/fs/selinux/enforce"
+	// selinuxContextFile holds the SELinux context of the current process.
+	selinuxContextFile = "/proc/self/attr/current"
+)
+
+// selinuxPolicyModule is the name of the policy module suggested to allow denied operations.
+const selinuxPolicyModule = "istio_cni_local"
+
+// DeniedOperation describes an operation SELinux may deny, to explain a failure of the operation.
+type DeniedOperation struct {
+	// Description describes the operation, such as "bind mount over /etc/nsswitch.conf".
+	Description string
+	// Path is the file the operation acts on, whose SELinux type is the target of the permission.
+	// Operations on capabilities have no path, their target is the process itself.
+	Path string
+	// Class and Permission are the SELinux object class and the permission the operation needs,
+	// such as "file" and "mounton".
+	Class      string
+	Permission string
+}
+
+// isPermissionDenied reports whether err is an EACCES or EPERM failure. Some libraries flatten errors into
+// strings, so the messages of both errors are matched as well.
+func isPermissionDenied(err error) bool {
+	if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.EPERM) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, syscall.EACCES.Error()) || strings.Contains(msg, syscall.EPERM.Error())
+}
+
+func selinuxEnforcing() bool {
+	b, err := os.ReadFile(selinuxEnforceFile)
+	return err == nil && strings.TrimSpace(string(b)) == "1"
+}
+
+// selinuxType returns the type of an SELinux context such as system_u:system_r:spc_t:s0.
+func selinuxType(context string) string {
+	parts := strings.Split(strings.TrimRight(strings.TrimSpace(context), "\x00"), ":")
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
+
+// SELinuxHint explains a failure of the operation if it was denied with EACCES or EPERM while SELinux enforces
+// its policy: it names the denied operation and suggests a policy module allowing it. It returns an empty string
+// otherwise.
+func SELinuxHint(op DeniedOperation, err error) string {
+	if err == nil || !isPermissionDenied(err) || !selinuxEnforcing() {
+		return ""
+	}
+	source := "<domain of this process>"
+	if b, err := os.ReadFile(selinuxContextFile); err == nil {
+		if t := selinuxType(string(b)); t != "" {
+			source = t
+		}
+	}
+	target := "self"
+	if op.Path != "" {
+		target = fmt.Sprintf("<type of %s>", op.Path)
+		if label, err := fileLabel(op.Path); err == nil {
+			if t := selinuxType(label); t != "" {
+				target = t
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "SELinux is enforcing and may have denied the %s permission on %s to %s, which is needed to %s. ",
+		op.Permission, op.Class, source, op.Description)
+	b.WriteString("Confirm the denial with `ausearch -m AVC -ts recent`. ")
+	fmt.Fprintf(&b, "If it was denied, allow the operation with a local policy module such as %s.te:\n", selinuxPolicyModule)
+	fmt.Fprintf(&b, "module %s 1.0;\n", selinuxPolicyModule)
+	b.WriteString("require {\n")
+	fmt.Fprintf(&b, "\ttype %s;\n", source)
+	if target != "self" && target != source {
+		fmt.Fprintf(&b, "\ttype %s;\n", target)
+	}
+	fmt.Fprintf(&b, "\tclass %s { %s };\n", op.Class, op.Permission)
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "allow %s %s:%s %s;\n", source, target, op.Class, op.Permission)
+	fmt.Fprintf(&b, "Load it with `checkmodule -M -m -o %[1]s.mod %[1]s.te && semodule_package -o %[1]s.pp -m %[1]s.mod && semodule -i %[1]s.pp`.",
+		selinuxPolicyModule)
+	return b.String()
+}
+
+// withSELinuxHint appends the SELinux hint for the operation to err, if any.
+func withSELinuxHint(op DeniedOperation, err error) error {
+	if hint := SELinuxHint(op, err); hint != "" {
+		return fmt.Errorf("%w\n%s", err, hint)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dependencies
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+func TestSELinuxHint(t *testing.T) {
+	op := DeniedOperation{Description: "unshare the mount namespace", Class: "capability", Permission: "sys_admin"}
+	cases := []struct {
+		name    string
+		enforce string
+		err     error
+		want    []string
+	}{
+		{
+			name:    "enforcing",
+			enforce: "1\n",
+			err:     fmt.Errorf("unshare: %w", syscall.EPERM),
+			want: []string{
+				"may have denied the sys_admin permission on capability to spc_t, which is needed to unshare the mount namespace",
+				"module istio_cni_local 1.0;",
+				"\tclass capability { sys_admin };",
+				"allow spc_t self:capability sys_admin;",
+				"semodule -i istio_cni_local.pp",
+			},
+		},
+		{
+			name:    "flattened error",
+			enforce: "1",
+			err:     errors.New("mount failed: permission denied"),
+			want:    []string{"allow spc_t self:capability sys_admin;"},
+		},
+		{
+			name:    "permissive",
+			enforce: "0",
+			err:     syscall.EACCES,
+		},
+		{
+			name:    "not a denial",
+			enforce: "1",
+			err:     syscall.ENOENT,
+		},
+		{
+			name: "selinux disabled",
+			err:  syscall.EACCES,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			enforce, context := selinuxEnforceFile, selinuxContextFile
+			t.Cleanup(func() {
+				selinuxEnforceFile, selinuxContextFile = enforce, context
+			})
+			selinuxEnforceFile = filepath.Join(dir, "enforce")
+			selinuxContextFile = filepath.Join(dir, "current")
+			if tt.enforce != "" {
+				assert.NoError(t, os.WriteFile(selinuxEnforceFile, []byte(tt.enforce), 0o644))
+			}
+			assert.NoError(t, os.WriteFile(selinuxContextFile, []byte("system_u:system_r:spc_t:s0\x00"), 0o644))
+
+			hint := SELinuxHint(op, tt.err)
+			if len(tt.want) == 0 {
+				assert.Equal(t, hint, "")
+				return
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(hint, w) {
+					t.Errorf("hint %q does not contain %q", hint, w)
+				}
+			}
+		})
+	}
+}
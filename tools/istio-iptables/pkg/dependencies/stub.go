@@ -55,3 +55,8 @@ func (s *StdoutStubDependencies) Run(cmd string, stdin io.ReadSeeker, args ...st
 func (s *StdoutStubDependencies) RunQuietlyAndIgnore(cmd string, stdin io.ReadSeeker, args ...string) {
 	_ = s.Run(cmd, stdin, args...)
 }
+
+// RunWithOutput runs a command and returns an empty output
+func (s *StdoutStubDependencies) RunWithOutput(cmd string, stdin io.ReadSeeker, args ...string) (string, error) {
+	return "", s.Run(cmd, stdin, args...)
+}
@@ -0,0 +1,53 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostrules
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// NetlinkIPSet is the ipset of the host, managed over netlink.
+type NetlinkIPSet struct {
+	Name string
+}
+
+func (s NetlinkIPSet) Members() (map[string]string, error) {
+	res, err := netlink.IpsetList(s.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ipset %s: %w", s.Name, err)
+	}
+	members := make(map[string]string, len(res.Entries))
+	for _, e := range res.Entries {
+		members[e.IP.String()] = e.Comment
+	}
+	return members, nil
+}
+
+func (s NetlinkIPSet) Add(ip, comment string) error {
+	if err := netlink.IpsetAdd(s.Name, &netlink.IPSetEntry{IP: net.ParseIP(ip).To4(), Comment: comment}); err != nil {
+		return fmt.Errorf("failed to add IP %s to ipset %s: %w", ip, s.Name, err)
+	}
+	return nil
+}
+
+func (s NetlinkIPSet) Delete(ip string) error {
+	if err := netlink.IpsetDel(s.Name, &netlink.IPSetEntry{IP: net.ParseIP(ip).To4()}); err != nil {
+		return fmt.Errorf("failed to delete IP %s from ipset %s: %w", ip, s.Name, err)
+	}
+	return nil
+}
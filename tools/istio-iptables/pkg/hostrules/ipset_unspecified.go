@@ -0,0 +1,41 @@
+//go:build !linux
+// +build !linux
+
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostrules
+
+import (
+	"errors"
+)
+
+var ErrNotImplemented = errors.New("not implemented")
+
+// NetlinkIPSet is the ipset of the host, managed over netlink.
+type NetlinkIPSet struct {
+	Name string
+}
+
+func (s NetlinkIPSet) Members() (map[string]string, error) {
+	return nil, ErrNotImplemented
+}
+
+func (s NetlinkIPSet) Add(ip, comment string) error {
+	return ErrNotImplemented
+}
+
+func (s NetlinkIPSet) Delete(ip string) error {
+	return ErrNotImplemented
+}
@@ -0,0 +1,79 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostrules verifies the host-level rules of ambient mode against the desired state of the node agent,
+// and repairs them. The node agent writes its desired state, the ztunnel chains, their rules and the ipset of
+// the enrolled pods, to a file, so that the rules can be verified by a separate process.
+package hostrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"istio.io/istio/pkg/file"
+)
+
+// DefaultStatePath is where the node agent writes its desired state, on a host path mounted in its container.
+const DefaultStatePath = "/var/run/istio-cni/ambient-host-rules.json"
+
+// Rule is an iptables rule of the host.
+type Rule struct {
+	Table string   `json:"table"`
+	Chain string   `json:"chain"`
+	Spec  []string `json:"spec"`
+}
+
+// Jump hooks a chain of the node agent into a built-in chain.
+type Jump struct {
+	Table string `json:"table"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// State is the desired state of the host-level rules of ambient mode.
+type State struct {
+	// IptablesCommand is the iptables command the rules are programmed with, such as iptables-nft.
+	IptablesCommand string `json:"iptablesCommand"`
+	// Jumps create the chains of the node agent and hook them into the built-in chains, in order.
+	Jumps []Jump `json:"jumps"`
+	// Rules are the rules of the chains of the node agent, in order.
+	Rules []Rule `json:"rules"`
+	// IPSet is the name of the ipset of the enrolled pods.
+	IPSet string `json:"ipset"`
+	// Members are the IPs of the enrolled pods, with the UID of their pod as comment.
+	Members map[string]string `json:"members"`
+}
+
+// ReadState reads the desired state written by the node agent.
+func ReadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the desired state of the node agent: %v", err)
+	}
+	s := &State{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid desired state %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// WriteState writes the desired state atomically, so that it is never read half written.
+func WriteState(path string, s *State) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return file.AtomicWrite(path, data, os.FileMode(0o644))
+}
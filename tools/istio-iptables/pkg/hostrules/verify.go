@@ -0,0 +1,270 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostrules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"istio.io/istio/pkg/util/sets"
+	"istio.io/istio/tools/istio-iptables/pkg/constants"
+	"istio.io/istio/tools/istio-iptables/pkg/dependencies"
+)
+
+// IPSet is the ipset of the enrolled pods.
+type IPSet interface {
+	// Members returns the IPs of the set, with their comment.
+	Members() (map[string]string, error)
+	Add(ip, comment string) error
+	Delete(ip string) error
+}
+
+// Discrepancy is a difference between the rules of the host and the desired state.
+type Discrepancy struct {
+	// Table and Chain are the chain the discrepancy is in, empty for the ipset.
+	Table string
+	Chain string
+	// Problem describes the discrepancy.
+	Problem string
+}
+
+func (d Discrepancy) String() string {
+	if d.Chain == "" {
+		return d.Problem
+	}
+	return fmt.Sprintf("%s/%s: %s", d.Table, d.Chain, d.Problem)
+}
+
+// Verifier verifies, and heals, the host-level rules of ambient mode.
+type Verifier struct {
+	state *State
+	deps  dependencies.Dependencies
+	ipset IPSet
+}
+
+// NewVerifier returns a verifier of the rules of the host against the state, running the xtables commands with
+// deps.
+func NewVerifier(state *State, deps dependencies.Dependencies, ipset IPSet) *Verifier {
+	return &Verifier{state: state, deps: deps, ipset: ipset}
+}
+
+// iptables returns the iptables command of the state, and the matching restore command.
+func (v *Verifier) iptables() (string, string) {
+	cmd := v.state.IptablesCommand
+	if cmd == "" {
+		cmd = constants.IPTABLES
+	}
+	return cmd, cmd + "-restore"
+}
+
+// Verify returns the discrepancies between the rules of the host and the desired state.
+func (v *Verifier) Verify() ([]Discrepancy, error) {
+	chains := v.verifyChains()
+	members, err := v.verifyIPSet()
+	if err != nil {
+		return nil, err
+	}
+	return append(chains.discrepancies, members...), nil
+}
+
+// Heal repairs the discrepancies between the rules of the host and the desired state, returning the
+// discrepancies it repaired. The chains with discrepancies are rewritten from the desired state in a single
+// iptables-restore, so that the host never runs a partial rule set. Missing members are added to the ipset
+// before stale ones are removed, so that no enrolled pod is left out of it meanwhile.
+func (v *Verifier) Heal() ([]Discrepancy, error) {
+	chains := v.verifyChains()
+	if len(chains.broken) > 0 {
+		_, restore := v.iptables()
+		if err := v.deps.Run(restore, strings.NewReader(v.restoreInput(chains)), "--noflush"); err != nil {
+			return nil, fmt.Errorf("failed to restore the ambient chains: %v", err)
+		}
+	}
+	members, err := v.healIPSet()
+	if err != nil {
+		return nil, err
+	}
+	return append(chains.discrepancies, members...), nil
+}
+
+// chainKey identifies a chain in a table.
+type chainKey struct {
+	table string
+	chain string
+}
+
+// chains returns the chains of the node agent, in the order they are hooked into the built-in chains.
+func (v *Verifier) chains() []chainKey {
+	var chains []chainKey
+	seen := sets.New[chainKey]()
+	for _, j := range v.state.Jumps {
+		if c := (chainKey{j.Table, j.To}); !seen.InsertContains(c) {
+			chains = append(chains, c)
+		}
+	}
+	for _, r := range v.state.Rules {
+		if c := (chainKey{r.Table, r.Chain}); !seen.InsertContains(c) {
+			chains = append(chains, c)
+		}
+	}
+	return chains
+}
+
+// chainsVerification is the result of the verification of the chains of the node agent.
+type chainsVerification struct {
+	discrepancies []Discrepancy
+	// broken are the chains with discrepancies.
+	broken sets.Set[chainKey]
+	// missingJumps are the jumps missing from the built-in chains.
+	missingJumps sets.Set[Jump]
+}
+
+// verifyChains verifies the jumps to the chains of the node agent and their rules. A rule is looked up with
+// iptables -C rather than compared to the output of iptables-save, which normalizes rules.
+func (v *Verifier) verifyChains() chainsVerification {
+	cmd, _ := v.iptables()
+	var discrepancies []Discrepancy
+	broken := sets.New[chainKey]()
+	missingJumps := sets.New[Jump]()
+	for _, j := range v.state.Jumps {
+		if err := v.deps.Run(cmd, nil, "-t", j.Table, "-C", j.From, "-j", j.To); err != nil {
+			discrepancies = append(discrepancies, Discrepancy{Table: j.Table, Chain: j.From, Problem: "missing jump to " + j.To})
+			broken.Insert(chainKey{j.Table, j.To})
+			missingJumps.Insert(j)
+		}
+	}
+	for _, c := range v.chains() {
+		out, err := v.deps.RunWithOutput(cmd, nil, "-t", c.table, "-S", c.chain)
+		if err != nil {
+			discrepancies = append(discrepancies, Discrepancy{Table: c.table, Chain: c.chain, Problem: "missing chain"})
+			broken.Insert(c)
+			continue
+		}
+		actual := 0
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "-A ") {
+				actual++
+			}
+		}
+		desired, missing := 0, 0
+		for _, r := range v.state.Rules {
+			if r.Table != c.table || r.Chain != c.chain {
+				continue
+			}
+			desired++
+			if err := v.deps.Run(cmd, nil, append([]string{"-t", r.Table, "-C", r.Chain}, r.Spec...)...); err != nil {
+				missing++
+				discrepancies = append(discrepancies, Discrepancy{Table: c.table, Chain: c.chain, Problem: "missing rule " + strings.Join(r.Spec, " ")})
+			}
+		}
+		if extra := actual - (desired - missing); extra > 0 {
+			discrepancies = append(discrepancies, Discrepancy{Table: c.table, Chain: c.chain, Problem: fmt.Sprintf("%d unexpected rules", extra)})
+		}
+		if missing > 0 || actual != desired {
+			broken.Insert(c)
+		}
+	}
+	return chainsVerification{discrepancies: discrepancies, broken: broken, missingJumps: missingJumps}
+}
+
+// restoreInput returns the iptables-restore input rewriting the broken chains. Declaring a chain flushes it, or
+// creates it if it does not exist.
+func (v *Verifier) restoreInput(chains chainsVerification) string {
+	broken := chains.broken
+	tables := sets.New[string]()
+	for c := range broken {
+		tables.Insert(c.table)
+	}
+	var b strings.Builder
+	for _, table := range sets.SortedList(tables) {
+		fmt.Fprintf(&b, "*%s\n", table)
+		for _, c := range v.chains() {
+			if c.table == table && broken.Contains(c) {
+				fmt.Fprintf(&b, ":%s - [0:0]\n", c.chain)
+			}
+		}
+		for _, j := range v.state.Jumps {
+			if j.Table == table && chains.missingJumps.Contains(j) {
+				fmt.Fprintf(&b, "-I %s 1 -j %s\n", j.From, j.To)
+			}
+		}
+		for _, r := range v.state.Rules {
+			if r.Table == table && broken.Contains(chainKey{r.Table, r.Chain}) {
+				fmt.Fprintf(&b, "-A %s %s\n", r.Chain, strings.Join(r.Spec, " "))
+			}
+		}
+		b.WriteString("COMMIT\n")
+	}
+	return b.String()
+}
+
+// diffIPSet returns the desired members missing from the ipset, and the members of the ipset not desired.
+func (v *Verifier) diffIPSet() ([]string, []string, error) {
+	actual, err := v.ipset.Members()
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing, stale []string
+	for ip := range v.state.Members {
+		if _, f := actual[ip]; !f {
+			missing = append(missing, ip)
+		}
+	}
+	for ip := range actual {
+		if _, f := v.state.Members[ip]; !f {
+			stale = append(stale, ip)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return missing, stale, nil
+}
+
+func (v *Verifier) verifyIPSet() ([]Discrepancy, error) {
+	missing, stale, err := v.diffIPSet()
+	if err != nil {
+		return nil, err
+	}
+	return v.ipsetDiscrepancies(missing, stale), nil
+}
+
+func (v *Verifier) healIPSet() ([]Discrepancy, error) {
+	missing, stale, err := v.diffIPSet()
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range missing {
+		if err := v.ipset.Add(ip, v.state.Members[ip]); err != nil {
+			return nil, err
+		}
+	}
+	for _, ip := range stale {
+		if err := v.ipset.Delete(ip); err != nil {
+			return nil, err
+		}
+	}
+	return v.ipsetDiscrepancies(missing, stale), nil
+}
+
+func (v *Verifier) ipsetDiscrepancies(missing, stale []string) []Discrepancy {
+	var discrepancies []Discrepancy
+	for _, ip := range missing {
+		discrepancies = append(discrepancies, Discrepancy{Problem: fmt.Sprintf("ipset %s: missing enrolled pod %s", v.state.IPSet, ip)})
+	}
+	for _, ip := range stale {
+		discrepancies = append(discrepancies, Discrepancy{Problem: fmt.Sprintf("ipset %s: stale pod %s", v.state.IPSet, ip)})
+	}
+	return discrepancies
+}
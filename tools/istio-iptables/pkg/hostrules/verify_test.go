@@ -0,0 +1,211 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostrules
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/util/assert"
+)
+
+// fakeHost is an iptables of the host, holding the rules of each chain as in iptables -S, keyed by table and
+// chain.
+type fakeHost struct {
+	chains   map[string][]string
+	restores []string
+}
+
+func (h *fakeHost) key(table, chain string) string {
+	return table + "/" + chain
+}
+
+func (h *fakeHost) Run(cmd string, stdin io.ReadSeeker, args ...string) error {
+	if strings.HasSuffix(cmd, "-restore") {
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		h.restores = append(h.restores, string(input))
+		table := ""
+		for _, line := range strings.Split(string(input), "\n") {
+			switch {
+			case strings.HasPrefix(line, "*"):
+				table = line[1:]
+			case strings.HasPrefix(line, ":"):
+				h.chains[h.key(table, strings.Fields(line[1:])[0])] = []string{}
+			case strings.HasPrefix(line, "-A "), strings.HasPrefix(line, "-I "):
+				fields := strings.Fields(line)
+				k := h.key(table, fields[1])
+				rule := strings.Join(append([]string{fields[1]}, fields[2:]...), " ")
+				if fields[0] == "-I" {
+					rule = strings.Join(append([]string{fields[1]}, fields[3:]...), " ")
+					h.chains[k] = append([]string{rule}, h.chains[k]...)
+				} else {
+					h.chains[k] = append(h.chains[k], rule)
+				}
+			}
+		}
+		return nil
+	}
+	// -t <table> -C <chain> <spec...>
+	table, chain, rule := args[1], args[3], strings.Join(args[3:], " ")
+	for _, r := range h.chains[h.key(table, chain)] {
+		if r == rule {
+			return nil
+		}
+	}
+	return fmt.Errorf("iptables: Bad rule (does a matching rule exist in that chain?)")
+}
+
+func (h *fakeHost) RunQuietlyAndIgnore(cmd string, stdin io.ReadSeeker, args ...string) {
+	_ = h.Run(cmd, stdin, args...)
+}
+
+func (h *fakeHost) RunWithOutput(_ string, _ io.ReadSeeker, args ...string) (string, error) {
+	// -t <table> -S <chain>
+	rules, f := h.chains[h.key(args[1], args[3])]
+	if !f {
+		return "", fmt.Errorf("iptables: No chain/target/match by that name")
+	}
+	out := []string{"-N " + args[3]}
+	for _, r := range rules {
+		out = append(out, "-A "+r)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+type fakeIPSet map[string]string
+
+func (s fakeIPSet) Members() (map[string]string, error) {
+	return s, nil
+}
+
+func (s fakeIPSet) Add(ip, comment string) error {
+	s[ip] = comment
+	return nil
+}
+
+func (s fakeIPSet) Delete(ip string) error {
+	delete(s, ip)
+	return nil
+}
+
+func desiredState() *State {
+	return &State{
+		IptablesCommand: "iptables-nft",
+		Jumps: []Jump{
+			{Table: "mangle", From: "PREROUTING", To: "ztunnel-PREROUTING"},
+			{Table: "nat", From: "PREROUTING", To: "ztunnel-PREROUTING"},
+		},
+		Rules: []Rule{
+			{Table: "mangle", Chain: "ztunnel-PREROUTING", Spec: []string{"-i", "istioin", "-j", "RETURN"}},
+			{Table: "mangle", Chain: "ztunnel-PREROUTING", Spec: []string{"-i", "istioout", "-j", "RETURN"}},
+			{Table: "nat", Chain: "ztunnel-PREROUTING", Spec: []string{"-m", "mark", "--mark", "0x100/0x100", "-j", "ACCEPT"}},
+		},
+		IPSet:   "ztunnel-pods-ips",
+		Members: map[string]string{"10.244.1.5": "uid-1", "10.244.1.6": "uid-2"},
+	}
+}
+
+func healthyHost() *fakeHost {
+	return &fakeHost{chains: map[string][]string{
+		"mangle/PREROUTING":         {"PREROUTING -j ztunnel-PREROUTING"},
+		"mangle/ztunnel-PREROUTING": {"ztunnel-PREROUTING -i istioin -j RETURN", "ztunnel-PREROUTING -i istioout -j RETURN"},
+		"nat/PREROUTING":            {"PREROUTING -j ztunnel-PREROUTING", "PREROUTING -j KUBE-SERVICES"},
+		"nat/ztunnel-PREROUTING":    {"ztunnel-PREROUTING -m mark --mark 0x100/0x100 -j ACCEPT"},
+	}}
+}
+
+func TestVerifyHealthy(t *testing.T) {
+	v := NewVerifier(desiredState(), healthyHost(), fakeIPSet{"10.244.1.5": "uid-1", "10.244.1.6": "uid-2"})
+	discrepancies, err := v.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, len(discrepancies), 0)
+}
+
+func TestVerifyAndHeal(t *testing.T) {
+	host := healthyHost()
+	// A rule was deleted, a foreign one inserted and the nat chain unhooked.
+	host.chains["mangle/ztunnel-PREROUTING"] = []string{"ztunnel-PREROUTING -i istioin -j RETURN", "ztunnel-PREROUTING -j DROP"}
+	host.chains["nat/PREROUTING"] = []string{"PREROUTING -j KUBE-SERVICES"}
+	ipset := fakeIPSet{"10.244.1.5": "uid-1", "10.244.1.9": "uid-old"}
+	v := NewVerifier(desiredState(), host, ipset)
+
+	want := []string{
+		"nat/PREROUTING: missing jump to ztunnel-PREROUTING",
+		"mangle/ztunnel-PREROUTING: missing rule -i istioout -j RETURN",
+		"mangle/ztunnel-PREROUTING: 1 unexpected rules",
+		"ipset ztunnel-pods-ips: missing enrolled pod 10.244.1.6",
+		"ipset ztunnel-pods-ips: stale pod 10.244.1.9",
+	}
+	discrepancies, err := v.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, describe(discrepancies), want)
+	assert.Equal(t, len(host.restores), 0)
+
+	healed, err := v.Heal()
+	assert.NoError(t, err)
+	assert.Equal(t, describe(healed), want)
+	assert.Equal(t, host.restores, []string{`*mangle
+:ztunnel-PREROUTING - [0:0]
+-A ztunnel-PREROUTING -i istioin -j RETURN
+-A ztunnel-PREROUTING -i istioout -j RETURN
+COMMIT
+*nat
+:ztunnel-PREROUTING - [0:0]
+-I PREROUTING 1 -j ztunnel-PREROUTING
+-A ztunnel-PREROUTING -m mark --mark 0x100/0x100 -j ACCEPT
+COMMIT
+`})
+	assert.Equal(t, ipset, fakeIPSet{"10.244.1.5": "uid-1", "10.244.1.6": "uid-2"})
+
+	discrepancies, err = v.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, len(discrepancies), 0)
+}
+
+func TestVerifyMissingChain(t *testing.T) {
+	host := healthyHost()
+	// A chain can only be deleted once unhooked.
+	delete(host.chains, "nat/ztunnel-PREROUTING")
+	host.chains["nat/PREROUTING"] = []string{"PREROUTING -j KUBE-SERVICES"}
+	v := NewVerifier(desiredState(), host, fakeIPSet{"10.244.1.5": "uid-1", "10.244.1.6": "uid-2"})
+	discrepancies, err := v.Verify()
+	assert.NoError(t, err)
+	assert.Equal(t, describe(discrepancies), []string{
+		"nat/PREROUTING: missing jump to ztunnel-PREROUTING",
+		"nat/ztunnel-PREROUTING: missing chain",
+	})
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	assert.NoError(t, WriteState(path, desiredState()))
+	s, err := ReadState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, s, desiredState())
+}
+
+func describe(discrepancies []Discrepancy) []string {
+	out := make([]string, 0, len(discrepancies))
+	for _, d := range discrepancies {
+		out = append(out, d.String())
+	}
+	return out
+}
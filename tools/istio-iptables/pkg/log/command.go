@@ -26,6 +26,8 @@ var (
 	ExcludeInboundPort      = Command{"ExcludeInboundPort", "exclude inbound port from capture"}
 	IncludeInboundPort      = Command{"IncludeInboundPort", "include inbound port for capture"}
 	InboundCapture          = Command{"InboundCapture", "redirect inbound request to proxy"}
+	HostPortHairpin         = Command{"HostPortHairpin", "skip inbound capture of hostPort hairpin traffic"}
+	KubeletProbe            = Command{"KubeletProbe", "skip inbound capture of kubelet probes"}
 	KubevirtCommand         = Command{"KubevirtCommand", "Kubevirt outbound redirect"}
 	ExcludeInterfaceCommand = Command{"ExcludeInterfaceCommand", "Excluded interface"}
 	UndefinedCommand        = Command{"UndefinedCommand", ""}
@@ -37,6 +39,8 @@ var IDToCommand = map[string]Command{
 	"ExcludeInboundPort":      ExcludeInboundPort,
 	"IncludeInboundPort":      IncludeInboundPort,
 	"InboundCapture":          InboundCapture,
+	"HostPortHairpin":         HostPortHairpin,
+	"KubeletProbe":            KubeletProbe,
 	"KubevirtCommand":         KubevirtCommand,
 	"ExcludeInterfaceCommand": ExcludeInterfaceCommand,
 	"UndefinedCommand":        UndefinedCommand,